@@ -0,0 +1,256 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/gitmeta"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/graph"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// runAffectedTests implementa "codeanalyzer-go affected-tests [flags]":
+// seleziona i package di test da eseguire dopo una modifica, cioè i package
+// modificati e quelli che li importano (anche transitivamente, test esterni
+// compresi) che contengono test. La granularità è il package: i test non
+// sono filtrati per raggiungibilità dalle funzioni modificate.
+func runAffectedTests(args []string) int {
+	var qc queryConfig
+	fs := flag.NewFlagSet("affected-tests", flag.ContinueOnError)
+	fs.StringVar(&qc.input, "input", ".", "Path to the root of the Go project to analyze")
+	fs.StringVar(&qc.input, "i", ".", "Path to the root of the Go project (shorthand)")
+	fs.StringVar(&qc.excludeDirs, "exclude-dirs", "", "Comma-separated directory basenames to exclude")
+	registerPkgFilterFlags(fs, &qc)
+	since := fs.String("changed-since", "HEAD", "Git revision to diff against; uncommitted and untracked files are included")
+	changedPkgs := fs.String("changed-pkgs", "", "Comma-separated changed import paths, used instead of --changed-since")
+	asJSON := fs.Bool("json", false, "Print changed files, changed packages and every test function of the selected packages as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: codeanalyzer-go affected-tests [flags]")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	absInput, err := filepath.Abs(qc.input)
+	if err != nil {
+		logError("invalid input path: %v", err)
+		return exitUsage
+	}
+	filter, err := qc.packageFilter()
+	if err != nil {
+		logError("%v", err)
+		return exitUsage
+	}
+
+	res := &schema.CLDKAffectedTests{ChangedFiles: []string{}}
+	if *changedPkgs == "" {
+		res.Since = *since
+		if res.ChangedFiles, err = gitmeta.ChangedFiles(absInput, *since); err != nil {
+			logError("%v", err)
+			return exitLoad
+		}
+		if len(res.ChangedFiles) == 0 {
+			res.ChangedPackages = []string{}
+			res.Packages = []schema.CLDKAffectedPackage{}
+			return emitAffected(res, *asJSON)
+		}
+	}
+
+	result, err := loader.Load(absInput, loader.Options{
+		IncludeTest: true,
+		NeedSyntax:  true,
+		ExcludeDirs: splitCSV(qc.excludeDirs),
+		Packages:    filter,
+	})
+	if err != nil {
+		logError("load packages: %v", err)
+		return exitLoad
+	}
+
+	if *changedPkgs != "" {
+		res.ChangedPackages = splitCSV(*changedPkgs)
+	} else {
+		res.ChangedPackages = changedPackages(result, res.ChangedFiles)
+	}
+	res.Packages = affectedPackages(result, res.ChangedPackages)
+	return emitAffected(res, *asJSON)
+}
+
+// emitAffected stampa il risultato: in testo un package per riga, pronto
+// per "go test".
+func emitAffected(res *schema.CLDKAffectedTests, asJSON bool) int {
+	if asJSON {
+		return emitQuery(res)
+	}
+	for _, p := range res.Packages {
+		fmt.Println(p.Package)
+	}
+	return 0
+}
+
+// basePkgPath riconduce il package di test esterno (p_test) al package p
+// della stessa directory; false per i main generati da go test (p.test).
+func basePkgPath(pkgPath string) (string, bool) {
+	if strings.HasSuffix(pkgPath, ".test") {
+		return "", false
+	}
+	return strings.TrimSuffix(pkgPath, "_test"), true
+}
+
+// changedPackages mappa i file modificati (relativi alla root) sui package
+// del progetto. Un file che non appartiene a nessun package caricato (file
+// rimosso, testdata, risorse embed) è attribuito al package della directory
+// più vicina che lo contiene; una modifica a go.mod, go.sum o go.work
+// coinvolge tutti i package.
+func changedPackages(result *loader.LoadResult, files []string) []string {
+	byFile := make(map[string]string)
+	byDir := make(map[string]string)
+	all := make(map[string]bool)
+	for _, pkg := range result.Packages {
+		base, ok := basePkgPath(pkg.PkgPath)
+		if !ok {
+			continue
+		}
+		all[base] = true
+		for _, list := range [][]string{pkg.GoFiles, pkg.OtherFiles} {
+			for _, f := range list {
+				rel, err := filepath.Rel(result.Root, f)
+				if err != nil || strings.HasPrefix(rel, "..") {
+					continue
+				}
+				rel = filepath.ToSlash(rel)
+				byFile[rel] = base
+				byDir[path.Dir(rel)] = base
+			}
+		}
+	}
+
+	changed := make(map[string]bool)
+	for _, f := range files {
+		switch path.Base(f) {
+		case "go.mod", "go.sum", "go.work", "go.work.sum":
+			for p := range all {
+				changed[p] = true
+			}
+			continue
+		}
+		if p, ok := byFile[f]; ok {
+			changed[p] = true
+			continue
+		}
+		for dir := path.Dir(f); ; dir = path.Dir(dir) {
+			if p, ok := byDir[dir]; ok {
+				changed[p] = true
+				break
+			}
+			if dir == "." || dir == "/" {
+				break
+			}
+		}
+	}
+
+	out := make([]string, 0, len(changed))
+	for p := range changed {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// affectedPackages restituisce i package con test raggiunti dalla chiusura
+// inversa degli import dei package modificati, ordinati per distanza. Le
+// varianti di test di un package confluiscono nel package stesso.
+func affectedPackages(result *loader.LoadResult, changed []string) []schema.CLDKAffectedPackage {
+	dist := make(map[string]int)
+	for p, d := range graph.ReverseClosure(result.ImportGraph(), changed) {
+		base, ok := basePkgPath(p)
+		if !ok {
+			continue
+		}
+		if old, seen := dist[base]; !seen || d < old {
+			dist[base] = d
+		}
+	}
+
+	tests := testFunctions(result)
+	out := []schema.CLDKAffectedPackage{}
+	for p, d := range dist {
+		if len(tests[p]) > 0 {
+			out = append(out, schema.CLDKAffectedPackage{Package: p, Distance: d, PackageTests: tests[p]})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Distance != out[j].Distance {
+			return out[i].Distance < out[j].Distance
+		}
+		return out[i].Package < out[j].Package
+	})
+	return out
+}
+
+// testFunctions restituisce, per package, le funzioni eseguite da go test
+// dichiarate nei suoi file _test.go, ordinate e senza duplicati.
+func testFunctions(result *loader.LoadResult) map[string][]string {
+	seen := make(map[string]map[string]bool)
+	for _, pkg := range result.Packages {
+		base, ok := basePkgPath(pkg.PkgPath)
+		if !ok {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if !strings.HasSuffix(result.Fset.File(file.Pos()).Name(), "_test.go") {
+				continue
+			}
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil || !isTestFunc(fn.Name.Name) {
+					continue
+				}
+				if seen[base] == nil {
+					seen[base] = make(map[string]bool)
+				}
+				seen[base][fn.Name.Name] = true
+			}
+		}
+	}
+
+	out := make(map[string][]string, len(seen))
+	for p, names := range seen {
+		for n := range names {
+			out[p] = append(out[p], n)
+		}
+		sort.Strings(out[p])
+	}
+	return out
+}
+
+// isTestFunc riconosce i nomi delle funzioni di test secondo le regole di
+// go test: prefisso seguito da fine nome o da un carattere non minuscolo.
+func isTestFunc(name string) bool {
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := name[len(prefix):]
+		if rest == "" || rest[0] < 'a' || rest[0] > 'z' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/apiusage"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/output"
+)
+
+// runAPIUsage implementa "codeanalyzer-go api-usage --target lib.json
+// [analysis.json...]": quante volte le analisi del corpus referenziano
+// ciascun simbolo esportato del modulo analizzato in lib.json.
+func runAPIUsage(args []string) int {
+	flags := flag.NewFlagSet("api-usage", flag.ContinueOnError)
+	target := flags.String("target", "", "Analysis of the module whose exported API is counted (required)")
+	module := flags.String("module", "", "Only count symbols of target packages under this import path prefix")
+	corpus := flags.String("corpus", "", "Directory searched recursively for analysis.json(.gz|.zst) files of the consuming modules")
+	unused := flags.Bool("unused", false, "Only list exported symbols that no analysis references")
+	asJSON := flags.Bool("json", false, "Print the usage counts per symbol and per consumer as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: codeanalyzer-go api-usage --target lib.json [--corpus dir] [analysis.json...]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Count references to the exported functions, methods and types of a module in other analyses.")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	if *target == "" || (*corpus == "" && flags.NArg() == 0) {
+		flags.Usage()
+		return exitUsage
+	}
+
+	lib, err := output.ReadFile(*target)
+	if err != nil {
+		logError("%s: %v", *target, err)
+		return exitLoad
+	}
+	if lib.SymbolTable == nil {
+		logError("%s: no symbol table", *target)
+		return exitLoad
+	}
+
+	// consumatori: file indicati e analisi trovate nel corpus
+	type input struct{ name, path string }
+	var inputs []input
+	for _, path := range flags.Args() {
+		inputs = append(inputs, input{path, path})
+	}
+	if *corpus != "" {
+		err := filepath.WalkDir(*corpus, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			switch d.Name() {
+			case output.FileName(output.CompressNone), output.FileName(output.CompressGzip), output.FileName(output.CompressZstd):
+				name, _ := filepath.Rel(*corpus, path)
+				inputs = append(inputs, input{filepath.ToSlash(name), path})
+			}
+			return nil
+		})
+		if err != nil {
+			logError("corpus: %v", err)
+			return exitLoad
+		}
+	}
+	self, _ := filepath.Abs(*target)
+
+	counter := apiusage.New(lib, *target, *module)
+	for _, in := range inputs {
+		if abs, _ := filepath.Abs(in.path); abs == self {
+			continue
+		}
+		a, err := output.ReadFile(in.path)
+		if err != nil {
+			logError("%s: %v", in.path, err)
+			return exitLoad
+		}
+		counter.Add(in.name, a)
+	}
+	report := counter.Report()
+	if *unused {
+		kept := report.Symbols[:0]
+		for _, s := range report.Symbols {
+			if s.References == 0 {
+				kept = append(kept, s)
+			}
+		}
+		report.Symbols = kept
+	}
+
+	if *asJSON {
+		return emitQuery(report)
+	}
+	fmt.Printf("%8s %8s %8s %9s  %-8s %s\n", "REFS", "CALLS", "TYPES", "CONSUMERS", "KIND", "SYMBOL")
+	for _, s := range report.Symbols {
+		fmt.Printf("%8d %8d %8d %9d  %-8s %s\n", s.References, s.Calls, s.TypeUses, len(s.Consumers), s.Kind, s.ID)
+	}
+	return 0
+}
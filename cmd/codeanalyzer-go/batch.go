@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/modproxy"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// batchManifest è il nome del manifest scritto nella radice dell'output.
+const batchManifest = "batch.json"
+
+// runBatch implementa "codeanalyzer-go batch": scarica dal module proxy i
+// moduli elencati (path@version per riga) e li analizza con un pool di
+// worker, un processo "analyze --root-archive" per modulo, così un crash
+// o un eccesso di memoria resta confinato al modulo. I flag dopo "--" sono
+// passati ad analyze; l'output di ogni modulo va in
+// <output>/<path>@<version>/ (path e versione in forma escaped).
+func runBatch(args []string) int {
+	fs := flag.NewFlagSet("codeanalyzer-go batch", flag.ContinueOnError)
+	list := fs.String("list", "-", "File with one module@version per line (- = stdin; no version = latest)")
+	outputDir := fs.String("output", "", "Root directory for the per-module outputs and batch.json (required)")
+	fs.StringVar(outputDir, "o", "", "Output root directory (shorthand)")
+	workers := fs.Int("workers", 4, "Modules analyzed in parallel")
+	goproxy := fs.String("proxy", os.Getenv("GOPROXY"), "Module proxy list in GOPROXY format (default: $GOPROXY, else "+modproxy.DefaultProxy+")")
+	timeout := fs.Duration("timeout", 0, "Per-module time limit for download and analysis (0 = none)")
+	verbose := fs.Bool("verbose", false, "Log each module on stderr")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: codeanalyzer-go batch [flags] [-- analyze flags]\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	analyzeArgs := fs.Args()
+	if len(analyzeArgs) > 0 && analyzeArgs[0] == "--" {
+		analyzeArgs = analyzeArgs[1:]
+	}
+
+	if err := setupLogging(config{verbose: *verbose}); err != nil {
+		logError("configuration error: %v", err)
+		return exitUsage
+	}
+	if *outputDir == "" {
+		logError("configuration error: --output is required")
+		return exitUsage
+	}
+	if *workers < 1 {
+		logError("configuration error: --workers must be at least 1")
+		return exitUsage
+	}
+	client, err := modproxy.NewClient(*goproxy)
+	if err != nil {
+		logError("configuration error: %v", err)
+		return exitUsage
+	}
+
+	var r io.Reader = os.Stdin
+	if *list != "-" {
+		f, err := os.Open(*list)
+		if err != nil {
+			logError("%v", err)
+			return exitUsage
+		}
+		defer f.Close()
+		r = f
+	}
+	mods, err := modproxy.ParseList(r)
+	if err != nil {
+		logError("module list: %v", err)
+		return exitUsage
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		logError("%v", err)
+		return exitAnalysis
+	}
+	if err := os.MkdirAll(*outputDir, 0o755); err != nil {
+		logError("%v", err)
+		return exitOutput
+	}
+
+	b := &batchRunner{
+		client:  client,
+		self:    self,
+		root:    *outputDir,
+		args:    analyzeArgs,
+		timeout: *timeout,
+	}
+	entries := make([]schema.CLDKBatchEntry, len(mods))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				entries[i] = b.run(mods[i])
+				logInfo("[%d/%d] %s@%s: %s (%dms)", i+1, len(mods), entries[i].Module, entries[i].Version, entries[i].Status, entries[i].DurationMs)
+			}
+		}()
+	}
+	for i := range mods {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	manifest := schema.CLDKBatchManifest{Proxy: *goproxy, Args: analyzeArgs, Modules: entries}
+	if manifest.Proxy == "" {
+		manifest.Proxy = modproxy.DefaultProxy
+	}
+	if manifest.Args == nil {
+		manifest.Args = []string{}
+	}
+	for _, e := range entries {
+		if e.Status == "ok" {
+			manifest.Succeeded++
+		} else {
+			manifest.Failed++
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(*outputDir, batchManifest), append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		logError("write manifest: %v", err)
+		return exitOutput
+	}
+
+	fmt.Fprintf(os.Stderr, "%d modules analyzed, %d failed (see %s)\n",
+		manifest.Succeeded, manifest.Failed, filepath.Join(*outputDir, batchManifest))
+	if manifest.Failed > 0 {
+		return exitAnalysis
+	}
+	return exitOK
+}
+
+// batchRunner analizza un modulo alla volta per worker.
+type batchRunner struct {
+	client  *modproxy.Client
+	self    string   // eseguibile corrente, rilanciato come "analyze"
+	root    string   // radice dell'output
+	args    []string // flag passati ad analyze
+	timeout time.Duration
+}
+
+func (b *batchRunner) run(m modproxy.Module) schema.CLDKBatchEntry {
+	start := time.Now()
+	entry := schema.CLDKBatchEntry{Module: m.Path, Version: m.Version}
+
+	ctx := context.Background()
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	fail := func(status string, err error) schema.CLDKBatchEntry {
+		entry.Status, entry.Error = status, err.Error()
+		entry.DurationMs = time.Since(start).Milliseconds()
+		return entry
+	}
+
+	m, err := b.client.Resolve(ctx, m)
+	if err != nil {
+		return fail("fetch_error", err)
+	}
+	entry.Version = m.Version
+
+	zipFile, err := os.CreateTemp("", "codeanalyzer-batch-*.zip")
+	if err != nil {
+		return fail("fetch_error", err)
+	}
+	defer os.Remove(zipFile.Name())
+	err = b.client.Download(ctx, m, zipFile)
+	if cerr := zipFile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fail("fetch_error", err)
+	}
+
+	ep, _ := module.EscapePath(m.Path) // già validati da ParseList e Download
+	ev, _ := module.EscapeVersion(m.Version)
+	rel := ep + "@" + ev
+	outDir := filepath.Join(b.root, filepath.FromSlash(rel))
+
+	cmdArgs := append([]string{"analyze", "--root-archive", zipFile.Name(), "--output", outDir, "--quiet"}, b.args...)
+	cmd := exec.CommandContext(ctx, b.self, cmdArgs...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		entry.ExitCode = -1
+		if ee, ok := err.(*exec.ExitError); ok {
+			entry.ExitCode = ee.ExitCode()
+		}
+		if msg := failureMessage(stderr.String()); msg != "" {
+			err = fmt.Errorf("%s", msg)
+		}
+		return fail("failed", err)
+	}
+	entry.Status, entry.Output = "ok", rel
+	entry.DurationMs = time.Since(start).Milliseconds()
+	return entry
+}
+
+// failureMessage riassume lo stderr di un'analisi fallita: la riga del
+// panic se presente (lo stack trace segue), altrimenti le ultime righe.
+func failureMessage(stderr string) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "panic: ") {
+			return line
+		}
+	}
+	if len(lines) > 5 {
+		lines = lines[len(lines)-5:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// runBench implementa "codeanalyzer-go bench": analizza ogni repo del corpus
+// (sottodirectory o archivi .zip/.tar/.tar.gz) in ogni modalità, un processo
+// "analyze" per misura, e registra durata, picco di RSS e dimensione
+// dell'output. Con --baseline confronta le misure con un report precedente
+// ed esce con 1 se una supera la soglia della sua metrica. Le misure sono
+// sequenziali, per non falsarsi a vicenda.
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("codeanalyzer-go bench", flag.ContinueOnError)
+	corpus := fs.String("corpus", "", "Directory whose subdirectories and source archives are the benchmark repos (required)")
+	modes := fs.String("modes", "fast,standard", "Comma-separated modes: --profile presets (fast|standard|deep) or analysis levels")
+	runs := fs.Int("runs", 1, "Runs per measurement; the median is reported")
+	reportFile := fs.String("output", "", "Write the report to this file (omit for stdout)")
+	fs.StringVar(reportFile, "o", "", "Report file (shorthand)")
+	baseline := fs.String("baseline", "", "Previous report to compare against")
+	update := fs.Bool("update-baseline", false, "Write the report to --baseline instead of comparing")
+	timeThreshold := fs.Float64("time-threshold", 20, "Allowed duration increase over the baseline, in percent")
+	memThreshold := fs.Float64("mem-threshold", 20, "Allowed peak RSS increase over the baseline, in percent")
+	sizeThreshold := fs.Float64("size-threshold", 10, "Allowed output size increase over the baseline, in percent")
+	minDelta := fs.Int64("min-delta-ms", 100, "Duration increases below this many milliseconds are never regressions")
+	verbose := fs.Bool("verbose", false, "Log each measurement on stderr")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: codeanalyzer-go bench --corpus dir [flags] [-- analyze flags]\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	analyzeArgs := fs.Args()
+	if len(analyzeArgs) > 0 && analyzeArgs[0] == "--" {
+		analyzeArgs = analyzeArgs[1:]
+	}
+
+	if err := setupLogging(config{verbose: *verbose}); err != nil {
+		logError("configuration error: %v", err)
+		return exitUsage
+	}
+	switch {
+	case *corpus == "":
+		logError("configuration error: --corpus is required")
+		return exitUsage
+	case *runs < 1:
+		logError("configuration error: --runs must be at least 1")
+		return exitUsage
+	case *update && *baseline == "":
+		logError("configuration error: --update-baseline requires --baseline")
+		return exitUsage
+	}
+	modeList := splitCSV(*modes)
+	if len(modeList) == 0 {
+		logError("configuration error: --modes is empty")
+		return exitUsage
+	}
+	for _, m := range modeList {
+		if _, ok := profiles[m]; !ok && !analysisLevels[m] {
+			logError("configuration error: invalid mode %q (valid: %s, or an analysis level)", m, strings.Join(profileNames(), ", "))
+			return exitUsage
+		}
+	}
+
+	repos, err := benchRepos(*corpus)
+	if err != nil {
+		logError("corpus: %v", err)
+		return exitUsage
+	}
+	if len(repos) == 0 {
+		logError("corpus: no repositories or archives in %s", *corpus)
+		return exitUsage
+	}
+
+	var base *schema.CLDKBenchReport
+	if *baseline != "" && !*update {
+		if base, err = readBenchReport(*baseline); err != nil {
+			logError("baseline: %v", err)
+			return exitUsage
+		}
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		logError("%v", err)
+		return exitAnalysis
+	}
+	tmp, err := os.MkdirTemp("", "codeanalyzer-bench-*")
+	if err != nil {
+		logError("%v", err)
+		return exitAnalysis
+	}
+	defer os.RemoveAll(tmp)
+
+	report := schema.CLDKBenchReport{Version: version, Modes: modeList, Runs: *runs, Args: analyzeArgs}
+	if report.Args == nil {
+		report.Args = []string{}
+	}
+	failed := 0
+	for _, repo := range repos {
+		for _, mode := range modeList {
+			res := benchMeasure(self, filepath.Join(*corpus, repo), mode, *runs, analyzeArgs, tmp)
+			res.Repo = repo
+			if res.Status != "ok" {
+				failed++
+			}
+			logInfo("%s [%s]: %s %dms, peak RSS %d, output %d bytes", repo, mode, res.Status, res.DurationMs, res.PeakRSSBytes, res.OutputBytes)
+			report.Results = append(report.Results, res)
+		}
+	}
+
+	if base != nil {
+		report.Baseline = *baseline
+		report.Regressions = compareBench(base, report.Results, benchThresholds{
+			time: *timeThreshold, mem: *memThreshold, size: *sizeThreshold, minDeltaMs: *minDelta,
+		})
+	}
+
+	dest := *reportFile
+	if *update {
+		dest = *baseline
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		data = append(data, '\n')
+		if dest == "" {
+			_, err = os.Stdout.Write(data)
+		} else {
+			err = os.WriteFile(dest, data, 0o644)
+		}
+	}
+	if err != nil {
+		logError("write report: %v", err)
+		return exitOutput
+	}
+
+	for _, r := range report.Regressions {
+		fmt.Fprintf(os.Stderr, "regression: %s [%s] %s %d -> %d (%+.1f%%)\n", r.Repo, r.Mode, r.Metric, r.Baseline, r.Current, r.ChangePct)
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d measurements failed\n", failed)
+	}
+	if failed > 0 || len(report.Regressions) > 0 {
+		return exitAnalysis
+	}
+	return exitOK
+}
+
+// benchRepos elenca i repo del corpus: sottodirectory non nascoste e
+// archivi di sorgenti, in ordine di nome.
+func benchRepos(corpus string) ([]string, error) {
+	entries, err := os.ReadDir(corpus)
+	if err != nil {
+		return nil, err
+	}
+	var repos []string
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if e.IsDir() || isSourceArchive(name) {
+			repos = append(repos, name)
+		}
+	}
+	return repos, nil
+}
+
+func isSourceArchive(name string) bool {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// benchMeasure esegue runs volte l'analisi di un repo in una modalità e
+// riporta la mediana di durata e picco di RSS.
+func benchMeasure(self, repo, mode string, runs int, extra []string, tmp string) schema.CLDKBenchResult {
+	res := schema.CLDKBenchResult{Mode: mode}
+	outDir := filepath.Join(tmp, "out")
+	timingsFile := filepath.Join(tmp, "timings.json")
+
+	cmdArgs := []string{"analyze", "--output", outDir, "--quiet", "--timings-file", timingsFile}
+	if isSourceArchive(repo) {
+		cmdArgs = append(cmdArgs, "--root-archive", repo)
+	} else {
+		cmdArgs = append(cmdArgs, "--input", repo)
+	}
+	if _, ok := profiles[mode]; ok {
+		cmdArgs = append(cmdArgs, "--profile", mode)
+	} else {
+		cmdArgs = append(cmdArgs, "--analysis-level", mode)
+	}
+	cmdArgs = append(cmdArgs, extra...)
+
+	type sample struct {
+		durationMs int64
+		timings    schema.CLDKTimings
+		size       int64
+	}
+	samples := make([]sample, 0, runs)
+	for i := 0; i < runs; i++ {
+		os.RemoveAll(outDir)
+		os.Remove(timingsFile)
+		cmd := exec.Command(self, cmdArgs...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		start := time.Now()
+		err := cmd.Run()
+		elapsed := time.Since(start).Milliseconds()
+		if err != nil {
+			res.Status, res.Error = "failed", err.Error()
+			if msg := failureMessage(stderr.String()); msg != "" {
+				res.Error = msg
+			}
+			return res
+		}
+
+		// analysis.json, o .gz/.zst se tra i flag c'è --compress
+		matches, _ := filepath.Glob(filepath.Join(outDir, "analysis.json*"))
+		if len(matches) == 0 {
+			res.Status, res.Error = "failed", "no analysis.json written"
+			return res
+		}
+		info, err := os.Stat(matches[0])
+		if err != nil {
+			res.Status, res.Error = "failed", err.Error()
+			return res
+		}
+		// Tempi per fase (con "serialize") e risorse da --timings-file:
+		// con --deterministic tra i flag i metadati non li contengono
+		var timings schema.CLDKTimings
+		data, err := os.ReadFile(timingsFile)
+		if err == nil {
+			err = json.Unmarshal(data, &timings)
+		}
+		if err != nil {
+			res.Status, res.Error = "failed", fmt.Sprintf("read timings: %v", err)
+			return res
+		}
+		samples = append(samples, sample{durationMs: elapsed, timings: timings, size: info.Size()})
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].durationMs < samples[j].durationMs })
+	mid := samples[len(samples)/2]
+	res.Status = "ok"
+	res.DurationMs = mid.durationMs
+	res.OutputBytes = mid.size
+	res.PhaseTimingsMs = mid.timings.PhaseTimingsMs
+
+	rss := make([]int64, 0, len(samples))
+	for _, s := range samples {
+		if s.timings.Resources != nil {
+			rss = append(rss, s.timings.Resources.PeakRSSBytes)
+		}
+	}
+	if len(rss) > 0 {
+		sort.Slice(rss, func(i, j int) bool { return rss[i] < rss[j] })
+		res.PeakRSSBytes = rss[len(rss)/2]
+	}
+	return res
+}
+
+// benchThresholds sono le soglie di regressione in percentuale per metrica.
+type benchThresholds struct {
+	time, mem, size float64
+	minDeltaMs      int64
+}
+
+// compareBench confronta le misure riuscite con quelle della baseline per
+// lo stesso repo e la stessa modalità; le coppie senza baseline sono nuove e
+// non sono confrontate.
+func compareBench(base *schema.CLDKBenchReport, results []schema.CLDKBenchResult, th benchThresholds) []schema.CLDKBenchRegression {
+	prev := make(map[[2]string]schema.CLDKBenchResult)
+	for _, r := range base.Results {
+		if r.Status == "ok" {
+			prev[[2]string{r.Repo, r.Mode}] = r
+		}
+	}
+
+	var regs []schema.CLDKBenchRegression
+	for _, cur := range results {
+		old, ok := prev[[2]string{cur.Repo, cur.Mode}]
+		if !ok || cur.Status != "ok" {
+			continue
+		}
+		check := func(metric string, was, now int64, threshold float64, minDelta int64) {
+			if was <= 0 || now-was <= minDelta {
+				return
+			}
+			if pct := float64(now-was) / float64(was) * 100; pct > threshold {
+				regs = append(regs, schema.CLDKBenchRegression{
+					Repo: cur.Repo, Mode: cur.Mode, Metric: metric,
+					Baseline: was, Current: now, ChangePct: pct,
+				})
+			}
+		}
+		check("duration_ms", old.DurationMs, cur.DurationMs, th.time, th.minDeltaMs)
+		check("peak_rss_bytes", old.PeakRSSBytes, cur.PeakRSSBytes, th.mem, 0)
+		check("output_bytes", old.OutputBytes, cur.OutputBytes, th.size, 0)
+	}
+	return regs
+}
+
+func readBenchReport(name string) (*schema.CLDKBenchReport, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var r schema.CLDKBenchReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &r, nil
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/frames"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/graph"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/search"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// maxRequestBytes limita la lunghezza di una riga di richiesta.
+const maxRequestBytes = 16 << 20
+
+// bridgeMethods sono i metodi accettati dal bridge, annunciati nel frame
+// hello iniziale.
+var bridgeMethods = []string{
+	"hello", "metadata", "analysis", "symbols", "callgraph", "issues",
+	"query.path", "query.dominators", "search", "schema", "shutdown",
+}
+
+// bridge serve le richieste di "codeanalyzer-go bridge". L'analisi è
+// caricata alla prima richiesta che la usa, una sola volta: anche un
+// errore di caricamento resta valido per le richieste successive.
+type bridge struct {
+	qc       queryConfig
+	enc      *frames.Encoder
+	analysis *schema.CLDKAnalysis
+	idx      *graph.Index
+	loadErr  error
+}
+
+// bridgeError è un errore con il codice di CLDKFrameError.
+type bridgeError struct {
+	code string
+	err  error
+}
+
+func (e *bridgeError) Error() string { return e.err.Error() }
+
+// runBridge implementa "codeanalyzer-go bridge": legge richieste
+// CLDKFrameRequest, una per riga, da stdin e risponde su stdout con frame
+// NDJSON. Ogni risposta è formata da zero o più frame con l'id della
+// richiesta chiusi da un frame end (con i conteggi) o error.
+func runBridge(args []string) int {
+	var qc queryConfig
+	fs := flag.NewFlagSet("bridge", flag.ContinueOnError)
+	registerQueryFlags(fs, &qc)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: codeanalyzer-go bridge [flags]\n\nServe %s v%d requests on stdin/stdout for subprocess clients.\n\n", schema.FramesProtocol, schema.FramesVersion)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	qc.symbols = true
+
+	out := bufio.NewWriter(os.Stdout)
+	b := &bridge{qc: qc, enc: frames.NewEncoder(out)}
+	if err := b.serve(os.Stdin, out); err != nil {
+		logError("bridge: %v", err)
+		return exitOutput
+	}
+	return 0
+}
+
+// serve risponde alle richieste fino a EOF o a "shutdown".
+func (b *bridge) serve(in io.Reader, out *bufio.Writer) error {
+	if err := b.enc.Open(version, bridgeMethods); err != nil {
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), maxRequestBytes)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req schema.CLDKFrameRequest
+		err := json.Unmarshal(line, &req)
+		switch {
+		case err != nil:
+			err = b.enc.Fail(nil, "bad_request", "invalid request: "+err.Error())
+		case req.Method == "":
+			err = b.enc.Fail(req.ID, "bad_request", "missing method")
+		default:
+			err = b.respond(req)
+		}
+		if err != nil {
+			return err
+		}
+		if err := out.Flush(); err != nil {
+			return err
+		}
+		if req.Method == "shutdown" {
+			return nil
+		}
+	}
+	return sc.Err()
+}
+
+// respond esegue una richiesta e ne scrive la risposta. L'errore
+// restituito è solo quello di scrittura.
+func (b *bridge) respond(req schema.CLDKFrameRequest) error {
+	err := b.call(req)
+	if err == nil {
+		return b.enc.End(req.ID)
+	}
+	var be *bridgeError
+	if errors.As(err, &be) {
+		return b.enc.Fail(req.ID, be.code, be.err.Error())
+	}
+	return err
+}
+
+// paramless sono i metodi senza parametri: params, se presente, deve essere
+// un oggetto vuoto.
+var paramless = map[string]bool{"hello": true, "shutdown": true, "metadata": true, "analysis": true, "callgraph": true, "issues": true}
+
+func (b *bridge) call(req schema.CLDKFrameRequest) error {
+	id := req.ID
+	if paramless[req.Method] {
+		if err := decodeParams(req.Params, &struct{}{}); err != nil {
+			return err
+		}
+	}
+	switch req.Method {
+	case "hello":
+		return b.enc.Emit(withID(frames.Hello(version, bridgeMethods), id))
+	case "shutdown":
+		return nil
+	case "schema":
+		var p struct {
+			Frames bool `json:"frames"` // schema del protocollo invece che dell'analisi
+		}
+		if err := decodeParams(req.Params, &p); err != nil {
+			return err
+		}
+		s := schema.JSONSchema()
+		if p.Frames {
+			s = schema.FramesJSONSchema()
+		}
+		return b.enc.Emit(schema.CLDKFrame{Frame: schema.FrameResult, ID: id, Data: s})
+	}
+
+	method, ok := bridgeHandlers[req.Method]
+	if !ok {
+		return &bridgeError{"unknown_method", fmt.Errorf("unknown method %q", req.Method)}
+	}
+	if err := b.load(); err != nil {
+		return err
+	}
+	return method(b, id, req.Params)
+}
+
+// bridgeHandlers sono i metodi che richiedono l'analisi caricata.
+var bridgeHandlers = map[string]func(b *bridge, id, params json.RawMessage) error{
+	"metadata": func(b *bridge, id, params json.RawMessage) error {
+		return b.enc.Emit(schema.CLDKFrame{Frame: schema.FrameMetadata, ID: id, Data: b.analysis.Metadata})
+	},
+	"analysis": func(b *bridge, id, params json.RawMessage) error {
+		a := b.analysis
+		if err := b.enc.Emit(schema.CLDKFrame{Frame: schema.FrameMetadata, ID: id, Data: a.Metadata}); err != nil {
+			return err
+		}
+		if err := frames.Packages(b.enc, id, a.SymbolTable, nil); err != nil {
+			return err
+		}
+		if err := frames.CallGraph(b.enc, id, a.CallGraph); err != nil {
+			return err
+		}
+		if err := frames.Sections(b.enc, id, a); err != nil {
+			return err
+		}
+		return frames.Issues(b.enc, id, a.Issues)
+	},
+	"symbols": func(b *bridge, id, params json.RawMessage) error {
+		var p struct {
+			Packages []string `json:"packages"` // import path esatti (vuoto = tutti)
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return err
+		}
+		var keep func(string) bool
+		if len(p.Packages) > 0 {
+			want := make(map[string]bool, len(p.Packages))
+			for _, path := range p.Packages {
+				if b.analysis.SymbolTable.Packages[path] == nil {
+					return &bridgeError{"not_found", fmt.Errorf("package %q not in symbol table", path)}
+				}
+				want[path] = true
+			}
+			keep = func(path string) bool { return want[path] }
+		}
+		return frames.Packages(b.enc, id, b.analysis.SymbolTable, keep)
+	},
+	"callgraph": func(b *bridge, id, params json.RawMessage) error {
+		return frames.CallGraph(b.enc, id, b.analysis.CallGraph)
+	},
+	"issues": func(b *bridge, id, params json.RawMessage) error {
+		return frames.Issues(b.enc, id, b.analysis.Issues)
+	},
+	"query.path": func(b *bridge, id, params json.RawMessage) error {
+		p := struct {
+			From     string `json:"from"`
+			To       string `json:"to"`
+			K        int    `json:"k"`
+			MaxDepth int    `json:"max_depth"`
+		}{K: 5, MaxDepth: 20}
+		if err := decodeParams(params, &p); err != nil {
+			return err
+		}
+		if p.From == "" || p.To == "" {
+			return &bridgeError{"invalid_params", fmt.Errorf("from and to are required")}
+		}
+		res, err := pathQuery(b.idx, p.From, p.To, p.K, p.MaxDepth)
+		if err != nil {
+			return &bridgeError{"not_found", err}
+		}
+		return b.enc.Emit(schema.CLDKFrame{Frame: schema.FrameResult, ID: id, Data: res})
+	},
+	"query.dominators": func(b *bridge, id, params json.RawMessage) error {
+		var p struct {
+			Roots []string `json:"roots"` // default: entry point main/init
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return err
+		}
+		res, err := dominatorQuery(b.idx, p.Roots)
+		if err != nil {
+			return &bridgeError{"not_found", err}
+		}
+		return b.enc.Emit(schema.CLDKFrame{Frame: schema.FrameResult, ID: id, Data: res})
+	},
+	"search": func(b *bridge, id, params json.RawMessage) error {
+		var p struct {
+			Kind      string `json:"kind"`
+			Name      string `json:"name"`
+			Exported  bool   `json:"exported"`
+			Receiver  string `json:"receiver"`
+			Signature string `json:"signature"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return err
+		}
+		matches, err := search.Run(b.analysis.SymbolTable, search.Query(p))
+		if err != nil {
+			return &bridgeError{"invalid_params", err}
+		}
+		if matches == nil {
+			matches = []schema.CLDKSymbolMatch{}
+		}
+		return b.enc.Emit(schema.CLDKFrame{Frame: schema.FrameResult, ID: id, Data: matches})
+	},
+}
+
+// load carica l'analisi alla prima chiamata.
+func (b *bridge) load() error {
+	if b.analysis == nil && b.loadErr == nil {
+		analysis, err := loadQueryAnalysis(b.qc)
+		if err != nil {
+			b.loadErr = &bridgeError{"load_failed", err}
+		} else {
+			b.analysis = analysis
+			b.idx = graph.NewIndex(analysis.CallGraph)
+		}
+	}
+	return b.loadErr
+}
+
+// decodeParams decodifica i parametri in v rifiutando i campi sconosciuti;
+// parametri assenti lasciano v ai default.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &bridgeError{"invalid_params", fmt.Errorf("invalid params: %w", err)}
+	}
+	return nil
+}
+
+func withID(f schema.CLDKFrame, id json.RawMessage) schema.CLDKFrame {
+	f.ID = id
+	return f
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/clones"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+)
+
+// runClones implementa "codeanalyzer-go clones [flags]": gruppi di funzioni
+// duplicate o quasi duplicate, confrontando i corpi normalizzati.
+func runClones(args []string) int {
+	var qc queryConfig
+	var cfg clones.Config
+	fs := flag.NewFlagSet("clones", flag.ContinueOnError)
+	fs.StringVar(&qc.input, "input", ".", "Path to the root of the Go project to analyze")
+	fs.StringVar(&qc.input, "i", ".", "Path to the root of the Go project (shorthand)")
+	fs.BoolVar(&qc.includeTests, "include-tests", false, "Include *_test.go files in analysis")
+	fs.StringVar(&qc.excludeDirs, "exclude-dirs", "", "Comma-separated directory basenames to exclude")
+	registerPkgFilterFlags(fs, &qc)
+	fs.StringVar(&qc.overlay, "overlay", "", "JSON overlay in 'go build -overlay' format replacing file contents (- = stdin)")
+	fs.IntVar(&cfg.MinTokens, "min-tokens", clones.DefaultMinTokens, "Ignore functions whose body has fewer AST nodes")
+	fs.Float64Var(&cfg.Threshold, "threshold", clones.DefaultThreshold, "Minimum similarity (0-1] for near duplicates; 1 = exact clones only")
+	fs.BoolVar(&cfg.IgnoreIdentifiers, "ignore-identifiers", false, "Ignore identifier names and literal values (finds renamed copies)")
+	asJSON := fs.Bool("json", false, "Print the clone groups with similarity scores as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: codeanalyzer-go clones [flags]")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	if cfg.Threshold <= 0 || cfg.Threshold > 1 {
+		logError("invalid --threshold %v (must be in (0, 1])", cfg.Threshold)
+		return exitUsage
+	}
+	if cfg.MinTokens <= 0 {
+		logError("invalid --min-tokens %d (must be positive)", cfg.MinTokens)
+		return exitUsage
+	}
+
+	absInput, err := filepath.Abs(qc.input)
+	if err != nil {
+		logError("invalid input path: %v", err)
+		return exitUsage
+	}
+	filter, err := qc.packageFilter()
+	if err != nil {
+		logError("%v", err)
+		return exitUsage
+	}
+	opts := loader.Options{
+		NeedSyntax:  true,
+		NeedTypes:   true,
+		IncludeTest: qc.includeTests,
+		ExcludeDirs: splitCSV(qc.excludeDirs),
+		Packages:    filter,
+	}
+	if qc.overlay != "" {
+		if opts.Overlay, err = loader.ReadOverlay(qc.overlay); err != nil {
+			logError("%v", err)
+			return exitLoad
+		}
+	}
+	result, err := loader.Load(absInput, opts)
+	if err != nil {
+		logError("load packages: %v", err)
+		return exitLoad
+	}
+
+	report := clones.Detect(result.Packages, result.Fset, result.Root, cfg)
+	if *asJSON {
+		return emitQuery(report)
+	}
+	for _, g := range report.Groups {
+		fmt.Printf("%s clone group (similarity %.3f, %d functions)\n", g.Kind, g.Similarity, len(g.Members))
+		for _, m := range g.Members {
+			fmt.Printf("  %s:%d: %s (%d lines, %.3f)\n", m.Position.File, m.Position.StartLine, m.Callable, m.Lines, m.Similarity)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// command è un sottocomando della CLI.
+type command struct {
+	name    string
+	args    string // argomenti mostrati nella riga di uso
+	summary string
+	run     func(args []string) int
+}
+
+// commands restituisce i sottocomandi nell'ordine mostrato dall'help.
+func commands() []*command {
+	return []*command{
+		{"analyze", "[flags]", "Run the analysis selected by --analysis-level (default: full)", func(args []string) int {
+			return runAnalyze("analyze", args, "", flagsAll)
+		}},
+		{"symbols", "[flags]", "Extract the symbol table only", func(args []string) int {
+			return runAnalyze("symbols", args, levelSymbolTable, flagsCommon|flagsSymbols)
+		}},
+		{"callgraph", "[flags]", "Build the call graph only", func(args []string) int {
+			return runAnalyze("callgraph", args, levelCallGraph, flagsCommon|flagsCallGraph)
+		}},
+		{"batch", "[flags] [-- analyze flags]", "Fetch modules from a module proxy and analyze each one", runBatch},
+		{"bench", "--corpus dir [flags] [-- analyze flags]", "Measure analysis time, memory and output size over a corpus against a baseline", runBench},
+		{"query", "<path|dominators> [flags]", "Query call paths or dominators of a call graph", runQuery},
+		{"rdeps", "--pkg path [flags]", "List project packages that import the given packages, directly or transitively", runRdeps},
+		{"affected-tests", "[flags]", "List the test packages affected by changes since a git revision", runAffectedTests},
+		{"strings", "[flags]", "Inventory string literals: user-facing messages, format strings, i18n keys and duplicates", runStrings},
+		{"clones", "[flags]", "Find duplicate and near-duplicate functions by normalized AST", runClones},
+		{"search", "[flags]", "Find symbols by kind, name, receiver or signature", runSearch},
+		{"pack", "--focus symbol [flags]", "Pack a symbol, its call graph neighborhood and related types into one document within a token budget", runPack},
+		{"impact", "--symbol id [flags]", "Report references, touched packages, API boundary and affected tests of a symbol before renaming or changing it", runImpact},
+		{"serve", "[flags]", "Serve queries over HTTP for a saved analysis or a project", runServe},
+		{"bridge", "[flags]", "Answer NDJSON frame requests on stdin/stdout for subprocess clients such as CLDK Python", runBridge},
+		{"graph", "load [flags] analysis.json", "Write a binary cache of a saved analysis for fast reloading by query, serve, search and diff", runGraph},
+		{"diff", "[flags] old.json new.json", "Compare two analysis files", runDiff},
+		{"api-usage", "--target lib.json [analysis.json...]", "Count references to a module's exported API across other analyses", runAPIUsage},
+		{"validate", "[flags] analysis.json", "Check an analysis file against the schema", runValidate},
+		{"schema", "[--frames]", "Print the JSON Schema of the analysis output or of the frame protocol", runSchema},
+		{"version", "", "Show version", func([]string) int {
+			fmt.Printf("codeanalyzer-go %s\n", version)
+			return 0
+		}},
+	}
+}
+
+// dispatch esegue il sottocomando indicato dal primo argomento. Senza
+// sottocomando (nessun argomento o un flag) esegue "analyze" con tutti i
+// flag, compresi gli alias legacy, per retrocompatibilità.
+func dispatch(args []string) int {
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && !isHelp(args[0])) {
+		return runAnalyze("", args, "", flagsAll)
+	}
+
+	name := args[0]
+	if isHelp(name) || name == "help" {
+		if len(args) > 1 {
+			if cmd := findCommand(args[1]); cmd != nil {
+				return cmd.run([]string{"-h"})
+			}
+		}
+		usage(os.Stdout)
+		return 0
+	}
+
+	cmd := findCommand(name)
+	if cmd == nil {
+		logError("unknown command %q", name)
+		usage(os.Stderr)
+		return exitUsage
+	}
+	return cmd.run(args[1:])
+}
+
+func findCommand(name string) *command {
+	for _, c := range commands() {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func isHelp(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// usage stampa l'elenco dei sottocomandi.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: codeanalyzer-go <command> [flags]\n\nCommands:\n")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-14s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nRun 'codeanalyzer-go help <command>' for the flags of a command.\n")
+	fmt.Fprintf(w, "Flags without a command run 'analyze' (legacy form, also accepts --mode).\n")
+}
+
+// runAnalyze esegue un sottocomando di analisi. level, se non vuoto, fissa
+// il livello di analisi; groups seleziona i flag accettati.
+func runAnalyze(name string, args []string, level string, groups int) int {
+	cfg := defaultConfig()
+	if level != "" {
+		cfg.analysisLevel = level
+	}
+
+	fsName := "codeanalyzer-go"
+	if name != "" {
+		fsName += " " + name
+	}
+	fs := flag.NewFlagSet(fsName, flag.ContinueOnError)
+	registerFlags(fs, &cfg, groups)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] [file.go ...]\n\n", fsName)
+		fs.PrintDefaults()
+		if name == "" {
+			fmt.Fprintln(os.Stderr)
+			usage(os.Stderr)
+		}
+	}
+	// Argomenti posizionali: file .go da analizzare (come --files),
+	// ammessi anche prima o in mezzo ai flag
+	for rest := args; ; {
+		if err := fs.Parse(rest); err != nil {
+			if err == flag.ErrHelp {
+				return 0
+			}
+			return exitUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		arg := fs.Arg(0)
+		if !strings.HasSuffix(arg, ".go") {
+			logError("unexpected argument %q (only .go files are accepted)", arg)
+			return exitUsage
+		}
+		if cfg.files != "" {
+			cfg.files += ","
+		}
+		cfg.files += arg
+		rest = fs.Args()[1:]
+	}
+
+	if cfg.profile != "" {
+		if err := applyProfile(fs, cfg.profile); err != nil {
+			logError("configuration error: %v", err)
+			return exitUsage
+		}
+	}
+
+	// Gestisci --version
+	if cfg.showVersion {
+		fmt.Printf("codeanalyzer-go %s\n", version)
+		return 0
+	}
+
+	if err := setupLogging(cfg); err != nil {
+		logError("configuration error: %v", err)
+		return exitUsage
+	}
+
+	// Retrocompatibilità: mappa flag legacy a nuovi flag
+	cfg = handleLegacyFlags(cfg)
+
+	// Valida configurazione
+	if err := validateConfig(&cfg); err != nil {
+		logError("configuration error: %v", err)
+		return exitUsage
+	}
+
+	stopDiagnostics, err := startDiagnostics(cfg)
+	if err != nil {
+		logError("configuration error: %v", err)
+		return exitUsage
+	}
+	defer stopDiagnostics()
+
+	// Esegui analisi
+	if err := runAnalysis(cfg); err != nil {
+		logError("analysis error: %v", err)
+		return exitCode(err)
+	}
+	return exitOK
+}
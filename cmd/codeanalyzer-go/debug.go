@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime/trace"
+)
+
+// startDiagnostics avvia gli strumenti di profiling richiesti: il server
+// pprof su cfg.pprofAddr (attivo per tutta l'analisi) e il trace di
+// esecuzione in cfg.traceFile. La funzione restituita chiude il trace e il
+// server; va chiamata anche se l'analisi fallisce.
+func startDiagnostics(cfg config) (func(), error) {
+	var stops []func()
+	stop := func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if cfg.pprofAddr != "" {
+		ln, err := net.Listen("tcp", cfg.pprofAddr)
+		if err != nil {
+			return nil, fmt.Errorf("--pprof: %w", err)
+		}
+		// Mux dedicato: gli handler non finiscono su http.DefaultServeMux
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		srv := &http.Server{Handler: mux}
+		go srv.Serve(ln)
+		stops = append(stops, func() { srv.Close() })
+		// Sempre su stderr: con ":0" la porta è nota solo qui
+		fmt.Fprintf(os.Stderr, "pprof: serving on http://%s/debug/pprof/\n", ln.Addr())
+	}
+
+	if cfg.traceFile != "" {
+		f, err := os.Create(cfg.traceFile)
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("--trace: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			stop()
+			return nil, fmt.Errorf("--trace: %w", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			if err := f.Close(); err != nil {
+				logError("--trace: %v", err)
+			}
+		})
+	}
+
+	return stop, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/diff"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/output"
+)
+
+// runDiff implementa "codeanalyzer-go diff old.json new.json": stampa su
+// stdout package, tipi, callable e archi del call graph aggiunti o rimossi.
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: codeanalyzer-go diff old.json new.json\n\nCompare two analysis files of the same project.\n")
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	old, err := output.ReadFile(fs.Arg(0))
+	if err != nil {
+		logError("%s: %v", fs.Arg(0), err)
+		return 2
+	}
+	cur, err := output.ReadFile(fs.Arg(1))
+	if err != nil {
+		logError("%s: %v", fs.Arg(1), err)
+		return 2
+	}
+	return emitQuery(diff.Compare(old, cur))
+}
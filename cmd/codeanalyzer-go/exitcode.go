@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Exit code del processo.
+const (
+	exitOK       = 0 // analisi completata
+	exitAnalysis = 1 // fase di analisi fallita o soglia --fail-on raggiunta
+	exitUsage    = 2 // flag o configurazione non validi
+	exitLoad     = 3 // caricamento dei package fallito
+	exitOutput   = 4 // scrittura dell'output fallita
+)
+
+// exitError associa un errore all'exit code con cui terminare.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// exitCode restituisce l'exit code associato a err (exitAnalysis se non
+// specificato).
+func exitCode(err error) int {
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	return exitAnalysis
+}
+
+// severityRank ordina le severity delle issue; -1 per valori sconosciuti.
+func severityRank(severity string) int {
+	switch severity {
+	case "info":
+		return 0
+	case "warning":
+		return 1
+	case "error":
+		return 2
+	}
+	return -1
+}
+
+// checkFailOn restituisce un errore se almeno una issue ha severity pari o
+// superiore a failOn (vuoto = nessuna soglia).
+func checkFailOn(issues []schema.Issue, failOn string) error {
+	if failOn == "" {
+		return nil
+	}
+	threshold := severityRank(failOn)
+	n := 0
+	for _, iss := range issues {
+		if severityRank(iss.Severity) >= threshold {
+			n++
+		}
+	}
+	if n > 0 {
+		return &exitError{exitAnalysis, fmt.Errorf("%d issue(s) at or above severity %q (--fail-on)", n, failOn)}
+	}
+	return nil
+}
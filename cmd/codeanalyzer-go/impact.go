@@ -0,0 +1,581 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// runImpact implementa "codeanalyzer-go impact --symbol pkg.Foo": i
+// riferimenti al simbolo, i package e i file che toccano, se il simbolo fa
+// parte dell'API pubblica e i test che lo referenziano o lo raggiungono.
+// È il controllo preliminare di una rinomina o di un cambio di firma.
+func runImpact(args []string) int {
+	var qc queryConfig
+	fs := flag.NewFlagSet("impact", flag.ContinueOnError)
+	fs.StringVar(&qc.input, "input", ".", "Path to the root of the Go project to analyze")
+	fs.StringVar(&qc.input, "i", ".", "Path to the root of the Go project (shorthand)")
+	fs.StringVar(&qc.excludeDirs, "exclude-dirs", "", "Comma-separated directory basenames to exclude")
+	registerPkgFilterFlags(fs, &qc)
+	fs.StringVar(&qc.overlay, "overlay", "", "JSON overlay in 'go build -overlay' format replacing file contents (- = stdin)")
+	symbol := fs.String("symbol", "", "Function, method, type, field, variable or constant ID, or a unique suffix (e.g. pkg.Foo, (*Server).Start, Config.Timeout)")
+	del := fs.Bool("delete", false, "Decide whether the symbol can be removed: blocking references and interfaces that types would stop implementing")
+	asJSON := fs.Bool("json", false, "Print references, touched packages and files, and affected tests as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: codeanalyzer-go impact --symbol id [flags]")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	if *symbol == "" || fs.NArg() > 0 {
+		logError("impact requires --symbol")
+		return exitUsage
+	}
+
+	absInput, err := filepath.Abs(qc.input)
+	if err != nil {
+		logError("invalid input path: %v", err)
+		return exitUsage
+	}
+	filter, err := qc.packageFilter()
+	if err != nil {
+		logError("%v", err)
+		return exitUsage
+	}
+	opts := loader.Options{
+		NeedSyntax:  true,
+		NeedTypes:   true,
+		IncludeTest: true,
+		ExcludeDirs: splitCSV(qc.excludeDirs),
+		Packages:    filter,
+	}
+	if qc.overlay != "" {
+		if opts.Overlay, err = loader.ReadOverlay(qc.overlay); err != nil {
+			logError("%v", err)
+			return exitLoad
+		}
+	}
+	result, err := loader.Load(absInput, opts)
+	if err != nil {
+		logError("load packages: %v", err)
+		return exitLoad
+	}
+
+	res, err := symbolImpact(result, *symbol, *del)
+	if err != nil {
+		logError("%v", err)
+		return exitUsage
+	}
+	if *asJSON {
+		return emitQuery(res)
+	}
+	scope := "unexported"
+	switch {
+	case res.PublicAPI:
+		scope = "public API"
+	case res.Exported:
+		scope = "exported, not visible outside the module"
+	}
+	fmt.Printf("%s (%s, %s)\n", res.Symbol, res.Kind, scope)
+	fmt.Printf("declared at %s:%d:%d\n", res.Declaration.File, res.Declaration.StartLine, res.Declaration.StartColumn)
+	fmt.Printf("%d references in %d files, %d packages\n", len(res.References), len(res.Files), len(res.Packages))
+	for _, r := range res.References {
+		where := r.Function
+		if where == "" {
+			where = r.Package
+		}
+		fmt.Printf("  %s:%d:%d: %s\n", r.Position.File, r.Position.StartLine, r.Position.StartColumn, where)
+	}
+	if len(res.Tests) > 0 {
+		fmt.Printf("%d affected tests\n", len(res.Tests))
+		for _, t := range res.Tests {
+			fmt.Printf("  %s %s (distance %d)\n", t.Package, t.Name, t.Distance)
+		}
+	}
+	if d := res.Delete; d != nil {
+		if d.Safe {
+			fmt.Printf("safe to delete (%d test references to remove)\n", d.TestReferences)
+		} else {
+			fmt.Printf("not safe to delete: %d blocking references, %d interface obligations\n", len(d.Blocking), len(d.Obligations))
+		}
+		for _, r := range d.Blocking {
+			fmt.Printf("  %s:%d:%d: referenced\n", r.Position.File, r.Position.StartLine, r.Position.StartColumn)
+		}
+		for _, o := range d.Obligations {
+			fmt.Printf("  %s would no longer implement %s\n", o.Type, o.Interface)
+		}
+	}
+	return 0
+}
+
+// impactTarget è un simbolo dichiarato nel progetto.
+type impactTarget struct {
+	obj   types.Object
+	kind  string
+	owner *types.TypeName // tipo contenitore di metodi e campi
+}
+
+// symbolImpact risolve symbol tra i simboli dichiarati nei package del
+// progetto e ne raccoglie i riferimenti in tutti i file caricati, test
+// compresi. Un riferimento è un uso dell'oggetto nell'AST tipato (le
+// chiamate tramite interfaccia non contano); i test influenzati sono quelli
+// che contengono un riferimento o che raggiungono, con chiamate statiche a
+// funzioni del progetto, una funzione che lo contiene. Con del aggiunge il
+// verdetto sulla rimozione del simbolo.
+func symbolImpact(result *loader.LoadResult, symbol string, del bool) (*schema.CLDKImpact, error) {
+	fset := result.Fset
+	targets := impactTargets(result.Packages)
+	id, err := resolveImpactSymbol(targets, symbol)
+	if err != nil {
+		return nil, err
+	}
+	t := targets[id]
+	decl := fset.Position(t.obj.Pos())
+	declPkg := t.obj.Pkg().Path()
+
+	res := &schema.CLDKImpact{
+		Symbol:      id,
+		Kind:        t.kind,
+		Exported:    t.obj.Exported(),
+		Declaration: loader.Position(decl, result.Root),
+		References:  []schema.CLDKImpactRef{},
+		Tests:       []schema.CLDKImpactTest{},
+	}
+	res.PublicAPI = res.Exported && !strings.HasSuffix(decl.Filename, "_test.go") &&
+		t.obj.Pkg().Name() != "main" && !isInternalPath(declPkg) && (t.owner == nil || t.owner.Exported())
+
+	// funzioni del progetto, indicizzate per posizione della dichiarazione:
+	// le varianti di test hanno oggetti diversi per la stessa funzione
+	type fnInfo struct {
+		pkg, name string
+		test      bool
+		callees   map[token.Position]bool
+	}
+	funcs := make(map[token.Position]*fnInfo)
+	direct := make(map[token.Position]bool) // funzioni che contengono un riferimento
+	seen := make(map[token.Position]bool)
+	isTarget := func(obj types.Object) bool {
+		switch o := obj.(type) {
+		case *types.Func:
+			obj = o.Origin()
+		case *types.Var:
+			obj = o.Origin()
+		}
+		return obj.Name() == t.obj.Name() && fset.Position(obj.Pos()) == decl
+	}
+
+	for _, pkg := range result.Packages {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		if _, ok := basePkgPath(pkg.PkgPath); !ok {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			filename := fset.Position(file.Pos()).Filename
+			if !loader.UnderRoot(filename, result.Root) {
+				continue
+			}
+			inTest := strings.HasSuffix(filename, "_test.go")
+			ownStart, ownEnd := -1, -1
+			if filename == decl.Filename {
+				ownStart, ownEnd = declarationSpan(file, fset, decl)
+			}
+			for _, d := range file.Decls {
+				var fn *fnInfo
+				var fnPos token.Position
+				enclosing := ""
+				if fd, ok := d.(*ast.FuncDecl); ok {
+					if obj, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func); ok {
+						fnPos = fset.Position(obj.Pos())
+						enclosing = ids.Object(obj)
+						if fn = funcs[fnPos]; fn == nil {
+							fn = &fnInfo{
+								pkg:     pkg.PkgPath,
+								name:    fd.Name.Name,
+								test:    inTest && fd.Recv == nil && isTestFunc(fd.Name.Name),
+								callees: make(map[token.Position]bool),
+							}
+							funcs[fnPos] = fn
+						}
+					}
+				}
+				ast.Inspect(d, func(n ast.Node) bool {
+					ident, ok := n.(*ast.Ident)
+					if !ok {
+						return true
+					}
+					obj := pkg.TypesInfo.Uses[ident]
+					if obj == nil {
+						return true
+					}
+					if f, ok := obj.(*types.Func); ok && fn != nil {
+						fn.callees[fset.Position(f.Origin().Pos())] = true
+					}
+					if !isTarget(obj) {
+						return true
+					}
+					if fn != nil {
+						direct[fnPos] = true
+					}
+					pos := fset.Position(ident.Pos())
+					if seen[pos] {
+						return true
+					}
+					seen[pos] = true
+					res.References = append(res.References, schema.CLDKImpactRef{
+						Package:  pkg.PkgPath,
+						Function: enclosing,
+						Test:     inTest,
+						Own:      pos.Offset >= ownStart && pos.Offset < ownEnd,
+						Position: loader.Position(pos, result.Root),
+					})
+					return true
+				})
+			}
+		}
+	}
+	sort.Slice(res.References, func(i, j int) bool {
+		a, b := res.References[i].Position, res.References[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.StartColumn < b.StartColumn
+	})
+
+	// package e file toccati, dichiarazione compresa
+	byPkg := make(map[string]*schema.CLDKImpactPackage)
+	files := make(map[string]bool)
+	touch := func(pkg, file string, refs int) {
+		p := byPkg[pkg]
+		if p == nil {
+			p = &schema.CLDKImpactPackage{Package: pkg, Files: []string{}}
+			byPkg[pkg] = p
+		}
+		p.References += refs
+		if !slices.Contains(p.Files, file) {
+			p.Files = append(p.Files, file)
+		}
+		files[file] = true
+	}
+	touch(declPkg, res.Declaration.File, 0)
+	for _, r := range res.References {
+		touch(r.Package, r.Position.File, 1)
+		if base, _ := basePkgPath(r.Package); base != declPkg {
+			res.CrossPackage = true
+		}
+	}
+	for _, p := range byPkg {
+		sort.Strings(p.Files)
+		res.Packages = append(res.Packages, *p)
+	}
+	sort.Slice(res.Packages, func(i, j int) bool { return res.Packages[i].Package < res.Packages[j].Package })
+	for f := range files {
+		res.Files = append(res.Files, f)
+	}
+	sort.Strings(res.Files)
+
+	// test: visita all'indietro delle chiamate dalle funzioni con riferimenti
+	callers := make(map[token.Position][]token.Position)
+	for pos, fn := range funcs {
+		for callee := range fn.callees {
+			if callee != pos {
+				callers[callee] = append(callers[callee], pos)
+			}
+		}
+	}
+	dist := make(map[token.Position]int)
+	var queue []token.Position
+	for pos := range direct {
+		dist[pos] = 0
+		queue = append(queue, pos)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, caller := range callers[cur] {
+			if _, ok := dist[caller]; !ok {
+				dist[caller] = dist[cur] + 1
+				queue = append(queue, caller)
+			}
+		}
+	}
+	for pos, d := range dist {
+		if fn := funcs[pos]; fn != nil && fn.test {
+			res.Tests = append(res.Tests, schema.CLDKImpactTest{Package: fn.pkg, Name: fn.name, Distance: d})
+		}
+	}
+	sort.Slice(res.Tests, func(i, j int) bool {
+		a, b := res.Tests[i], res.Tests[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Name < b.Name
+	})
+	if del {
+		res.Delete = safeDelete(result, res, t)
+	}
+	return res, nil
+}
+
+// safeDelete decide se il simbolo si può rimuovere. Bloccano la rimozione i
+// riferimenti fuori dalla sua dichiarazione e dai file di test e, per un
+// metodo concreto, le interfacce che un tipo del progetto implementa solo
+// grazie a quel metodo (anche promosso da un campo incorporato): quelle
+// dichiarate nel progetto, quelle nominate nel codice del progetto ed
+// error. Le conversioni implicite verso un'interfaccia non sono tracciate,
+// quindi ogni interfaccia perduta conta come bloccante.
+func safeDelete(result *loader.LoadResult, res *schema.CLDKImpact, t impactTarget) *schema.CLDKSafeDelete {
+	d := &schema.CLDKSafeDelete{Blocking: []schema.CLDKImpactRef{}, Obligations: []schema.CLDKInterfaceObligation{}}
+	for _, r := range res.References {
+		switch {
+		case r.Own:
+		case r.Test:
+			d.TestReferences++
+		default:
+			d.Blocking = append(d.Blocking, r)
+		}
+	}
+	if m, ok := t.obj.(*types.Func); ok && t.owner != nil && !types.IsInterface(t.owner.Type()) {
+		d.Obligations = append(d.Obligations, interfaceObligations(result, m)...)
+	}
+	d.Safe = len(d.Blocking) == 0 && len(d.Obligations) == 0
+	return d
+}
+
+// interfaceObligations restituisce le coppie tipo/interfaccia in cui il tipo
+// (T o, se T non basta, *T) ha m nel method set e implementa un'interfaccia
+// con un metodo dello stesso nome.
+func interfaceObligations(result *loader.LoadResult, m *types.Func) []schema.CLDKInterfaceObligation {
+	fset := result.Fset
+	mPos := fset.Position(m.Pos())
+
+	ifaces := map[string]*types.Interface{"error": types.Universe.Lookup("error").Type().Underlying().(*types.Interface)}
+	addIface := func(tn *types.TypeName) {
+		if tn == nil || tn.Pkg() == nil || tn.IsAlias() {
+			return
+		}
+		named, ok := tn.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			return
+		}
+		if it, ok := named.Underlying().(*types.Interface); ok {
+			ifaces[ids.Type(tn.Pkg().Path(), tn.Name())] = it
+		}
+	}
+	var named []*types.TypeName
+	for _, pkg := range result.Packages {
+		if pkg == nil || pkg.Types == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			if tn, ok := scope.Lookup(name).(*types.TypeName); ok {
+				addIface(tn)
+				named = append(named, tn)
+			}
+		}
+		for _, obj := range pkg.TypesInfo.Uses {
+			if tn, ok := obj.(*types.TypeName); ok {
+				addIface(tn)
+			}
+		}
+	}
+	names := make([]string, 0, len(ifaces))
+	for id, it := range ifaces {
+		for i := 0; i < it.NumMethods(); i++ {
+			if it.Method(i).Name() == m.Name() {
+				names = append(names, id)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var out []schema.CLDKInterfaceObligation
+	seen := make(map[[2]string]bool)
+	for _, tn := range named {
+		nt, ok := tn.Type().(*types.Named)
+		if !ok || tn.IsAlias() || nt.TypeParams().Len() > 0 || types.IsInterface(nt) {
+			continue
+		}
+		for _, typ := range []types.Type{nt, types.NewPointer(nt)} {
+			obj, _, _ := types.LookupFieldOrMethod(typ, true, tn.Pkg(), m.Name())
+			f, ok := obj.(*types.Func)
+			if !ok || fset.Position(f.Origin().Pos()) != mPos {
+				continue
+			}
+			typeID := ids.Type(tn.Pkg().Path(), tn.Name())
+			if _, ptr := typ.(*types.Pointer); ptr {
+				typeID = "*" + typeID
+			}
+			found := false
+			for _, id := range names {
+				key := [2]string{ids.Type(tn.Pkg().Path(), tn.Name()), id}
+				if seen[key] || !types.Implements(typ, ifaces[id]) {
+					continue
+				}
+				seen[key] = true
+				found = true
+				out = append(out, schema.CLDKInterfaceObligation{
+					Type:      typeID,
+					Interface: id,
+					Position:  loader.Position(fset.Position(tn.Pos()), result.Root),
+				})
+			}
+			if found {
+				break
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Interface < out[j].Interface
+	})
+	return out
+}
+
+// declarationSpan restituisce gli offset di inizio e fine, nel file, della
+// dichiarazione il cui nome è in decl: funzione o metodo, tipo, specifica di
+// variabile o costante, campo o metodo di interfaccia. -1, -1 se non c'è.
+func declarationSpan(file *ast.File, fset *token.FileSet, decl token.Position) (int, int) {
+	start, end := -1, -1
+	named := func(idents ...*ast.Ident) bool {
+		for _, id := range idents {
+			if id != nil && fset.Position(id.Pos()).Offset == decl.Offset {
+				return true
+			}
+		}
+		return false
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if start >= 0 || n == nil {
+			return false
+		}
+		var hit bool
+		switch x := n.(type) {
+		case *ast.FuncDecl:
+			hit = named(x.Name)
+		case *ast.TypeSpec:
+			hit = named(x.Name)
+		case *ast.ValueSpec:
+			hit = named(x.Names...)
+		case *ast.Field:
+			hit = named(x.Names...)
+		}
+		if hit {
+			start, end = fset.Position(n.Pos()).Offset, fset.Position(n.End()).Offset
+			return false
+		}
+		return true
+	})
+	return start, end
+}
+
+// impactTargets indicizza per ID le funzioni, i metodi, i tipi, i campi
+// delle struct, le variabili e le costanti package-level dei package.
+func impactTargets(pkgs []*packages.Package) map[string]impactTarget {
+	out := make(map[string]impactTarget)
+	add := func(id string, t impactTarget) {
+		if _, ok := out[id]; !ok {
+			out[id] = t
+		}
+	}
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.Types == nil {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			switch obj := scope.Lookup(name).(type) {
+			case *types.Func:
+				add(ids.Object(obj), impactTarget{obj: obj, kind: "function"})
+			case *types.Var:
+				add(ids.Type(pkg.PkgPath, name), impactTarget{obj: obj, kind: "variable"})
+			case *types.Const:
+				add(ids.Type(pkg.PkgPath, name), impactTarget{obj: obj, kind: "constant"})
+			case *types.TypeName:
+				typeID := ids.Type(pkg.PkgPath, name)
+				add(typeID, impactTarget{obj: obj, kind: "type"})
+				named, ok := obj.Type().(*types.Named)
+				if !ok || obj.IsAlias() {
+					continue
+				}
+				for i := 0; i < named.NumMethods(); i++ {
+					m := named.Method(i)
+					add(ids.Object(m), impactTarget{obj: m, kind: "method", owner: obj})
+				}
+				switch u := named.Underlying().(type) {
+				case *types.Struct:
+					for i := 0; i < u.NumFields(); i++ {
+						f := u.Field(i)
+						add(typeID+"."+f.Name(), impactTarget{obj: f, kind: "field", owner: obj})
+					}
+				case *types.Interface:
+					for i := 0; i < u.NumExplicitMethods(); i++ {
+						m := u.ExplicitMethod(i)
+						add(typeID+"."+m.Name(), impactTarget{obj: m, kind: "method", owner: obj})
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// resolveImpactSymbol trova il simbolo per ID esatto o per suffisso
+// univoco dopo "/" o ".".
+func resolveImpactSymbol(targets map[string]impactTarget, name string) (string, error) {
+	if _, ok := targets[name]; ok {
+		return name, nil
+	}
+	var matches []string
+	for id := range targets {
+		if strings.HasSuffix(id, "."+name) || strings.HasSuffix(id, "/"+name) {
+			matches = append(matches, id)
+		}
+	}
+	sort.Strings(matches)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("symbol %q not found", name)
+	case 1:
+		return matches[0], nil
+	default:
+		if len(matches) > 5 {
+			matches = append(matches[:5], "...")
+		}
+		return "", fmt.Errorf("symbol %q is ambiguous: %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// isInternalPath riporta se l'import path è sotto una directory internal.
+func isInternalPath(path string) bool {
+	return strings.HasPrefix(path, "internal/") || strings.Contains(path, "/internal/") || strings.HasSuffix(path, "/internal")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"github.com/codellm-devkit/codeanalyzer-go/internal/callgraph"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/leaks"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pdg"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/perf"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/purity"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/spill"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/summary"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Le funzioni build* scelgono tra costruzione whole-program e costruzione
+// per-package con spill su disco (--max-memory-mb). In modalità per-package
+// ogni fase ricostruisce l'SSA: più tempo in cambio di RSS limitata.
+
+func buildCallGraph(result *loader.LoadResult, cfg callgraph.Config, spillDir string) (*schema.CLDKCallGraph, error) {
+	if !result.PerPackageSSA {
+		return callgraph.Build(result, cfg)
+	}
+
+	var out *schema.CLDKCallGraph
+	err := spill.Run(result, spillDir,
+		func(part *loader.LoadResult) (*schema.CLDKCallGraph, error) {
+			return callgraph.Build(part, cfg)
+		},
+		func(cg *schema.CLDKCallGraph) {
+			if out == nil {
+				out = &schema.CLDKCallGraph{Algorithm: cg.Algorithm + "-per-package"}
+			}
+			callgraph.Merge(out, cg)
+		})
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = &schema.CLDKCallGraph{Algorithm: cfg.Algorithm + "-per-package"}
+	}
+	callgraph.AnnotateDegrees(out, cfg.Reach)
+	return out, nil
+}
+
+func buildPDG(result *loader.LoadResult, cfg pdg.Config, spillDir string) (*schema.CLDKPDG, error) {
+	if !result.PerPackageSSA {
+		return pdg.Build(result, cfg)
+	}
+
+	out := &schema.CLDKPDG{Packages: make(map[string]*schema.CLDKPackagePDG)}
+	err := spill.Run(result, spillDir,
+		func(part *loader.LoadResult) (*schema.CLDKPDG, error) {
+			return pdg.Build(part, cfg)
+		},
+		func(p *schema.CLDKPDG) {
+			for path, pkg := range p.Packages {
+				out.Packages[path] = pkg
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func buildSummaries(result *loader.LoadResult, cfg summary.Config, spillDir string) (*schema.CLDKSummaries, error) {
+	if !result.PerPackageSSA {
+		return summary.Build(result, cfg)
+	}
+
+	out := &schema.CLDKSummaries{Packages: make(map[string]*schema.CLDKPackageSummaries)}
+	err := spill.Run(result, spillDir,
+		func(part *loader.LoadResult) (*schema.CLDKSummaries, error) {
+			return summary.Build(part, cfg)
+		},
+		func(s *schema.CLDKSummaries) {
+			for path, pkg := range s.Packages {
+				out.Packages[path] = pkg
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func buildHotspots(result *loader.LoadResult, cfg perf.Config, spillDir string) ([]schema.Issue, error) {
+	if !result.PerPackageSSA {
+		return perf.Hotspots(result, cfg)
+	}
+
+	var out []schema.Issue
+	err := spill.Run(result, spillDir,
+		func(part *loader.LoadResult) ([]schema.Issue, error) {
+			return perf.Hotspots(part, cfg)
+		},
+		func(issues []schema.Issue) {
+			out = append(out, issues...)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func buildLeaks(result *loader.LoadResult, cfg leaks.Config, spillDir string) ([]schema.Issue, error) {
+	if !result.PerPackageSSA {
+		return leaks.Check(result, cfg)
+	}
+
+	var out []schema.Issue
+	err := spill.Run(result, spillDir,
+		func(part *loader.LoadResult) ([]schema.Issue, error) {
+			return leaks.Check(part, cfg)
+		},
+		func(issues []schema.Issue) {
+			out = append(out, issues...)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func buildPurity(result *loader.LoadResult, cfg purity.Config, spillDir string) (map[string]*schema.CLDKPurity, error) {
+	if !result.PerPackageSSA {
+		return purity.Analyze(result, cfg)
+	}
+
+	out := make(map[string]*schema.CLDKPurity)
+	err := spill.Run(result, spillDir,
+		func(part *loader.LoadResult) (map[string]*schema.CLDKPurity, error) {
+			return purity.Analyze(part, cfg)
+		},
+		func(m map[string]*schema.CLDKPurity) {
+			for id, p := range m {
+				out[id] = p
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
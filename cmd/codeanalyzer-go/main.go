@@ -1,56 +1,170 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
 	"strings"
 	"time"
 
+	"github.com/codellm-devkit/codeanalyzer-go/internal/archrules"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/binaries"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/buildmatrix"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/callgraph"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/callhierarchy"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/clock"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/dataaccess"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/di"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/diag"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/effects"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/embeds"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/errtaxonomy"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/gitmeta"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/globals"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/graph"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/initeffects"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/kube"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/layout"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/leaks"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/lint"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/logging"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/metrics"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/obfuscation"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/openapi"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/output"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/owners"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/passes"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/pdg"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/perf"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/purity"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/reproducible"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/sdg"
 	gostrings "github.com/codellm-devkit/codeanalyzer-go/internal/strings"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/summarize"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/summary"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/supplychain"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/symbols"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/treemap"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/typegraph"
 	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
 )
 
 const (
-	version = "2.1.0"
-
 	// Analysis levels
 	levelSymbolTable = "symbol_table"
 	levelCallGraph   = "call_graph"
 	levelPDG         = "pdg"
 	levelSDG         = "sdg"
 	levelFull        = "full"
+	levelSummaries   = "summaries"
 )
 
+// analysisLevels sono i valori ammessi di --analysis-level.
+var analysisLevels = map[string]bool{
+	levelSymbolTable: true,
+	levelCallGraph:   true,
+	levelPDG:         true,
+	levelSDG:         true,
+	levelFull:        true,
+	levelSummaries:   true,
+}
+
 type config struct {
 	// Flag principali CLDK
 	input         string
 	outputDir     string
 	format        string
 	analysisLevel string
+	profile       string // fast|standard|deep: preset per i flag non indicati
 
 	// Flag avanzati
 	cgAlgo        string
+	cgReach       bool
+	effects       bool   // classify callables by the stdlib side effects they reach
+	cgGranularity string // func|pkg
+	cgWrappers    bool   // collapse thin wrapper functions into their callers' edges
+	cgCallSites   int    // max call-site positions listed per edge
+	updateFrom    string // previous analysis whose call graph is patched
+	changedPkgs   string // comma-separated packages changed since updateFrom
 	includeTests  bool
 	excludeDirs   string
 	onlyPkg       string
+	matchPkg      string            // glob/regex package filters
+	excludePkg    string            // glob/regex package exclusions
+	packages      *pkgfilter.Filter // filtro risultante, costruito da validateConfig
 	emitPositions string
 	includeBody   bool
+	bodyComments  bool // --include-comments: commenti nei body associati agli statement (implica includeBody)
+	exSnippets    bool // --call-example-snippets: righe attorno ai call examples (implica includeBody)
+	flatDocs      bool // --flat-docs: documentazione su una riga invece del Markdown
+	examples      bool // --examples: funzioni ExampleXxx dei file _test.go
+	fingerprints  bool // --fingerprints: impronta strutturale dei callable
 	compact       bool
+	skipBoiler    bool   // --compact-skip-boilerplate: niente getter, setter, stringer e boilerplate nell'output compatto
+	treemapSize   string // sloc|complexity: value dei nodi con --format treemap
+	neo4jMode     string // csv|cypher con --format neo4j
+	factsCorpus   string // corpus dei VName con --format facts (vuoto = module path)
+	compress      string // gzip|zstd (vuoto = nessuna compressione)
+	deterministic bool   // --deterministic: output identico byte per byte a parità di input
 	verbose       bool
 	quiet         bool
+	logLevel      string // debug|info|warn|error (overrides --verbose/--quiet)
+	logFormat     string // text|json
+	progress      bool   // show progress on stderr
 	showVersion   bool
-	security      bool // enable security analysis (strings, supply chain, obfuscation)
+	security      bool   // enable security analysis (strings, supply chain, obfuscation)
+	gitMetadata   bool   // annotate symbols with git blame metadata
+	ownersFile    string // CODEOWNERS or YAML ownership map joined onto symbols
+	components    string // "go.work" or name=dir-prefix list grouping packages into components
+	binary        string // comma-separated main package dirs the analysis is scoped to
+	binaries      bool   // inventory main packages: constraints, flags, imported project packages
+	shutdown      bool   // trace signal handling and graceful shutdown of each binary (implies binaries)
+	layers        bool   // infer architectural layers from the import graph
+	buildMatrix   string // "default" or goos/goarch[+tag...] list evaluated against build constraints
+	configs       string // goos/goarch[+tag...] list whose symbol tables are merged
+	lint          bool   // run the built-in lint checks
+	deadSymbols   bool   // report unreferenced package-level vars, consts and types
+	unexportable  bool   // report exported identifiers used only in their own package
+	recvIssues    bool   // report lost writes and large copies of value receivers
+	locks         bool   // report copied mutexes, unreleased locks and lock-order inversions
+	resourceLeaks bool   // report files, response bodies and sql.Rows not closed on every path (SSA)
+	archRules     string // rules file of allowed/forbidden imports between packages
+	lifecycle     bool   // attach constructors, closers and goroutine escapes to types
+	clockUsage    bool   // inventory time and rand call sites per package
+	httpAPI       bool   // endpoint net/http e gin con schemi di richiesta e risposta
+	dataAccess    bool   // file .sql, configurazioni sqlc e SQL nel codice Go
+	errTaxonomy   bool   // inventory error types, sentinels and errors.Is/As targets per module
+	typeGraph     bool   // emit type-to-type references (fields, embeddings, methods, constraints)
+	globals       bool   // record the functions that read, write or take the address of each global
+	initEffects   bool   // inventory blank imports and what their init functions do
+	purity        bool   // mark pure and constant-foldable callables (SSA)
+	purityDepth   int    // call levels followed by --purity
+	summarizerCmd string // external summarizer command (JSON request on stdin)
+	summarizerURL string // HTTP summarizer endpoint (JSON request via POST)
+	summaryScope  string // packages|callables|all
+	summaryCache  string // JSON file caching generated summaries
+	sumWorkers    int    // concurrent summarizer requests
+	passes        string // go/analysis passes, comma-separated
+	reportCycles  bool   // emit SCC-based recursion groups and import cycles
+	allocHotspots bool   // emit info issues for allocation-heavy patterns
+	structLayout  bool   // emit struct field offsets, size and padding
+	layoutSavings int    // minimum bytes saved by reordering to emit an issue
+	failOn        string // error|warning: exit non-zero on issues at or above
+	overlay       string // go build -overlay JSON file ("-" = stdin)
+	files         string // comma-separated .go files to scope the analysis to
+	rootArchive   string // .zip/.tar(.gz) of sources analyzed without extraction
+	maxMemoryMB   int    // soft memory limit; > 0 enables per-package SSA
+	spillDir      string // parent directory for spilled partial results
+	pprofAddr     string // address of the live pprof server (empty = off)
+	traceFile     string // runtime/trace output file (empty = off)
+	timingsFile   string // JSON file with phase timings including serialize (empty = off)
 
 	// Flag legacy (retrocompatibilità)
 	root string
@@ -59,68 +173,158 @@ type config struct {
 }
 
 func main() {
-	cfg := parseFlags()
+	// Logger di default finché i flag non sono stati letti
+	setupLogging(config{})
 
-	// Gestisci --version
-	if cfg.showVersion {
-		fmt.Printf("codeanalyzer-go %s\n", version)
-		os.Exit(0)
-	}
+	os.Exit(dispatch(os.Args[1:]))
+}
 
-	// Retrocompatibilità: mappa flag legacy a nuovi flag
-	cfg = handleLegacyFlags(cfg)
+// Gruppi di flag: ogni sottocomando registra solo quelli che usa.
+const (
+	flagsCommon    = 1 << iota // input, output, filtri, logging, memoria
+	flagsSymbols               // estrazione della symbol table
+	flagsCallGraph             // costruzione del call graph
+	flagsAnalyze               // livello di analisi e fasi SSA opzionali
+	flagsLegacy                // alias deprecati (--root, --mode, --out)
 
-	// Valida configurazione
-	if err := validateConfig(&cfg); err != nil {
-		logError("configuration error: %v", err)
-		os.Exit(2)
-	}
+	flagsAll = flagsCommon | flagsSymbols | flagsCallGraph | flagsAnalyze | flagsLegacy
+)
 
-	// Esegui analisi
-	if err := runAnalysis(cfg); err != nil {
-		logError("analysis error: %v", err)
-		os.Exit(1)
+// defaultConfig restituisce i valori di default, validi anche per i flag
+// che un sottocomando non registra.
+func defaultConfig() config {
+	return config{
+		input:         ".",
+		format:        "json",
+		analysisLevel: levelFull,
+		cgAlgo:        "rta",
+		cgGranularity: callgraph.GranularityFunc,
+		cgCallSites:   8,
+		emitPositions: "detailed",
+		logFormat:     "text",
+		layoutSavings: layout.DefaultMinSavings,
+		purityDepth:   purity.DefaultDepth,
+		summaryScope:  summarize.ScopePackages,
+		sumWorkers:    4,
+		treemapSize:   treemap.SizeSLOC,
+		neo4jMode:     output.Neo4jCSV,
 	}
 }
 
-func parseFlags() config {
-	var cfg config
+// registerFlags registra su fs i gruppi di flag richiesti, usando i valori
+// correnti di cfg come default.
+func registerFlags(fs *flag.FlagSet, cfg *config, groups int) {
+	if groups&flagsCommon != 0 {
+		fs.StringVar(&cfg.input, "input", cfg.input, "Path to the root of the Go project to analyze")
+		fs.StringVar(&cfg.input, "i", cfg.input, "Path to the root of the Go project to analyze (shorthand)")
+		fs.StringVar(&cfg.outputDir, "output", cfg.outputDir, "Output directory (omit for stdout)")
+		fs.StringVar(&cfg.outputDir, "o", cfg.outputDir, "Output directory (shorthand)")
+		fs.StringVar(&cfg.format, "format", cfg.format, "Output format: json, csv|tsv for the callables, edges, metrics and issues tables (one file each with --output), html for a self-contained report.html, treemap for a package/file/function treemap.json, ndjson-frames for the line-delimited frame protocol, call-hierarchy for LSP call hierarchies in call_hierarchy.json, neo4j for a graph database import, facts for Kythe-style entries in facts.ndjson, or openapi for an OpenAPI skeleton of the HTTP endpoints in openapi.json")
+		fs.StringVar(&cfg.format, "f", cfg.format, "Output format (shorthand)")
+		fs.BoolVar(&cfg.compact, "compact", cfg.compact, "Compact JSON output for LLM (reduces size ~70%)")
+		fs.StringVar(&cfg.treemapSize, "treemap-size", cfg.treemapSize, "Node value with --format treemap: sloc (lines of code) or complexity (cyclomatic, implies --include-body)")
+		fs.StringVar(&cfg.factsCorpus, "facts-corpus", cfg.factsCorpus, "With --format facts: corpus of the entry VNames (default: module path from go.mod, else the project directory name)")
+		fs.StringVar(&cfg.neo4jMode, "neo4j-mode", cfg.neo4jMode, "With --format neo4j: csv (node and relationship files for neo4j-admin import) or cypher (idempotent MERGE statements in neo4j.cypher)")
+		fs.BoolVar(&cfg.compact, "c", cfg.compact, "Compact output (shorthand)")
+		fs.BoolVar(&cfg.skipBoiler, "compact-skip-boilerplate", cfg.skipBoiler, "With --compact: leave out callables classified as getter, setter, stringer or boilerplate")
+		fs.StringVar(&cfg.compress, "compress", cfg.compress, "Compress the output: gzip|zstd (writes analysis.json.gz/.zst)")
+		fs.BoolVar(&cfg.deterministic, "deterministic", cfg.deterministic, "Reproducible output: zero timestamp and duration, no phase timings or process resources, issues sorted by position, paths relative to the project root ($GOROOT/$GOMODCACHE for files outside it), so identical inputs give byte-identical output")
+		fs.BoolVar(&cfg.includeTests, "include-tests", cfg.includeTests, "Include *_test.go files in analysis")
+		fs.StringVar(&cfg.excludeDirs, "exclude-dirs", cfg.excludeDirs, "Comma-separated directory basenames to exclude (e.g., vendor,.git)")
+		fs.StringVar(&cfg.onlyPkg, "only-pkg", cfg.onlyPkg, "Comma-separated package path filters (substring match)")
+		fs.StringVar(&cfg.matchPkg, "match-pkg", cfg.matchPkg, "Comma-separated import path patterns to include: globs (* within a path element, ** or ... across) or regexps prefixed with re:")
+		fs.StringVar(&cfg.excludePkg, "exclude-pkg", cfg.excludePkg, "Comma-separated import path patterns to exclude (same syntax as --match-pkg)")
+		fs.StringVar(&cfg.files, "files", cfg.files, "Comma-separated .go files to analyze, type-checked against their packages (also accepted as positional arguments)")
+		fs.StringVar(&cfg.rootArchive, "root-archive", cfg.rootArchive, "Analyze the sources in a .zip (e.g. a GOPROXY module zip), .tar or .tar.gz without extracting it; replaces --input")
+		fs.StringVar(&cfg.overlay, "overlay", cfg.overlay, "JSON overlay in 'go build -overlay' format replacing file contents, e.g. unsaved editor buffers (- = stdin)")
+		fs.StringVar(&cfg.emitPositions, "emit-positions", cfg.emitPositions, "Position verbosity: detailed|minimal")
+		fs.BoolVar(&cfg.verbose, "verbose", cfg.verbose, "Enable verbose logging to stderr")
+		fs.BoolVar(&cfg.verbose, "v", cfg.verbose, "Enable verbose logging (shorthand)")
+		fs.BoolVar(&cfg.quiet, "quiet", cfg.quiet, "Suppress all non-error output")
+		fs.BoolVar(&cfg.quiet, "q", cfg.quiet, "Suppress non-error output (shorthand)")
+		fs.StringVar(&cfg.logLevel, "log-level", cfg.logLevel, "Log level: debug|info|warn|error (default: warn, info with --verbose, error with --quiet)")
+		fs.StringVar(&cfg.logFormat, "log-format", cfg.logFormat, "Log format on stderr: text|json")
+		fs.BoolVar(&cfg.progress, "progress", cfg.progress, "Show analysis progress on stderr (bar on a TTY)")
+		fs.BoolVar(&cfg.showVersion, "version", cfg.showVersion, "Show version and exit")
+		fs.StringVar(&cfg.failOn, "fail-on", cfg.failOn, "Exit with code 1 if issues at or above this severity were produced: error|warning")
+		fs.IntVar(&cfg.maxMemoryMB, "max-memory-mb", cfg.maxMemoryMB, "Soft memory limit in MB; builds SSA one package at a time, spilling partial results to disk (0 = unlimited)")
+		fs.StringVar(&cfg.spillDir, "spill-dir", cfg.spillDir, "Directory for spilled partial results with --max-memory-mb (default: system temp dir)")
+		fs.StringVar(&cfg.pprofAddr, "pprof", cfg.pprofAddr, "Serve net/http/pprof on this address during the analysis (e.g. localhost:6060)")
+		fs.StringVar(&cfg.components, "components", cfg.components, "Group packages into components: 'go.work' (one per workspace module) or comma-separated name=dir-prefix entries; reports cross-component imports and calls")
+		fs.BoolVar(&cfg.layers, "layers", cfg.layers, "Infer architectural layers from the package import graph; annotate each package with its layer and the imports that close a cycle (back edges)")
+		fs.StringVar(&cfg.binary, "binary", cfg.binary, "Scope the analysis to the main package in this directory (e.g. ./cmd/foo, relative to --input; comma-separated for several) and the project packages it imports")
+		fs.BoolVar(&cfg.binaries, "binaries", cfg.binaries, "Inventory the main packages: directory, build constraints, flags defined with the flag package and imported project packages")
+		fs.BoolVar(&cfg.shutdown, "shutdown", cfg.shutdown, "Trace signal handling and the shutdown path (context cancellation, Shutdown calls, WaitGroup waits) of each binary; servers without graceful shutdown are NO_GRACEFUL_SHUTDOWN warnings (implies --binaries)")
+		fs.StringVar(&cfg.traceFile, "trace", cfg.traceFile, "Write a runtime execution trace to this file (view with 'go tool trace')")
+		fs.StringVar(&cfg.timingsFile, "timings-file", cfg.timingsFile, "After the output, write the phase timings including 'serialize', the total duration and the process resources to this JSON file")
+	}
 
-	// Flag principali CLDK
-	flag.StringVar(&cfg.input, "input", ".", "Path to the root of the Go project to analyze")
-	flag.StringVar(&cfg.input, "i", ".", "Path to the root of the Go project to analyze (shorthand)")
-	flag.StringVar(&cfg.outputDir, "output", "", "Output directory (omit for stdout)")
-	flag.StringVar(&cfg.outputDir, "o", "", "Output directory (shorthand)")
-	flag.StringVar(&cfg.format, "format", "json", "Output format: json|msgpack")
-	flag.StringVar(&cfg.format, "f", "json", "Output format (shorthand)")
-	flag.StringVar(&cfg.analysisLevel, "analysis-level", "full", "Analysis level: symbol_table|call_graph|pdg|sdg|full")
-	flag.StringVar(&cfg.analysisLevel, "a", "full", "Analysis level (shorthand)")
+	if groups&flagsSymbols != 0 {
+		fs.BoolVar(&cfg.includeBody, "include-body", cfg.includeBody, "Include function body information")
+		fs.BoolVar(&cfg.bodyComments, "include-comments", cfg.bodyComments, "Attach comments inside function bodies to their nearest statement (implies --include-body)")
+		fs.BoolVar(&cfg.flatDocs, "flat-docs", cfg.flatDocs, "Collapse doc comments to a single line (legacy form) instead of Markdown with resolved doc links")
+		fs.BoolVar(&cfg.examples, "examples", cfg.examples, "Attach ExampleXxx functions from _test.go files (source and expected output) to the package, type or callable they document")
+		fs.BoolVar(&cfg.exSnippets, "call-example-snippets", cfg.exSnippets, "Add the source lines around each call example, 2 lines of context per side (implies --include-body)")
+		fs.BoolVar(&cfg.fingerprints, "fingerprints", cfg.fingerprints, "Add to each callable a structural fingerprint of its body (normalized AST hash and winnowed k-gram hashes) for clone search across repositories")
+		fs.BoolVar(&cfg.security, "security", cfg.security, "Enable security analysis: string extraction, supply chain vectors, obfuscation metrics")
+		fs.BoolVar(&cfg.structLayout, "struct-layout", cfg.structLayout, "Emit struct memory layout (field offsets, size, alignment, padding)")
+		fs.IntVar(&cfg.layoutSavings, "layout-min-savings", cfg.layoutSavings, "Minimum bytes saved by field reordering to emit a STRUCT_PADDING issue")
+		fs.BoolVar(&cfg.gitMetadata, "with-git-metadata", cfg.gitMetadata, "Annotate callables and types with last commit, author and age (git blame)")
+		fs.StringVar(&cfg.ownersFile, "owners", cfg.ownersFile, "CODEOWNERS file or YAML map (pattern: owners/tags) whose owners and tags are added to packages, types and callables")
+		fs.BoolVar(&cfg.lint, "lint", cfg.lint, "Report shadowed err, ignored errors, functions compared to nil and unreachable code as warnings")
+		fs.BoolVar(&cfg.deadSymbols, "dead-symbols", cfg.deadSymbols, "Report package-level variables, constants and types never referenced in the module as GO-DEAD-SYMBOL warnings")
+		fs.BoolVar(&cfg.unexportable, "unexport-candidates", cfg.unexportable, "Report exported functions, types, variables and constants used only in their own package as UNEXPORT_CANDIDATE info issues (modules imported by another workspace module are skipped)")
+		fs.BoolVar(&cfg.purity, "purity", cfg.purity, "Mark callables that are pure (no globals, no I/O, no writes outside their own allocations, deterministic) and constant-foldable, using SSA")
+		fs.IntVar(&cfg.purityDepth, "purity-depth", cfg.purityDepth, "Levels of calls to project and dependency functions followed by --purity; deeper calls make the caller impure")
+		fs.StringVar(&cfg.summarizerCmd, "summarizer-cmd", cfg.summarizerCmd, "Command run once per package/callable to generate a one-paragraph summary: the JSON request is written to its stdin, the summary (text or {\"summary\": ...}) is read from stdout")
+		fs.StringVar(&cfg.summarizerURL, "summarizer-url", cfg.summarizerURL, "HTTP endpoint receiving each summary request as a JSON POST; the response body is the summary (text or {\"summary\": ...})")
+		fs.StringVar(&cfg.summaryScope, "summarize", cfg.summaryScope, "What --summarizer-cmd/--summarizer-url summarize: packages, callables or all")
+		fs.StringVar(&cfg.summaryCache, "summary-cache", cfg.summaryCache, "JSON file caching summaries by request content, so unchanged packages and callables are not summarized again")
+		fs.IntVar(&cfg.sumWorkers, "summary-workers", cfg.sumWorkers, "Concurrent summarizer requests")
+		fs.BoolVar(&cfg.httpAPI, "http-api", cfg.httpAPI, "Detect net/http and gin endpoints and reconstruct their parameters, request and response schemas from the handler code")
+		fs.BoolVar(&cfg.dataAccess, "data-access", cfg.dataAccess, "Inventory .sql files and sqlc configs in the tree and link SQL string constants, sqlc-generated functions and embedded .sql files to their SQL source")
+		fs.BoolVar(&cfg.errTaxonomy, "error-taxonomy", cfg.errTaxonomy, "Inventory error types, sentinel error variables and errors.Is/errors.As targets per module")
+		fs.BoolVar(&cfg.typeGraph, "type-graph", cfg.typeGraph, "Emit the graph of type-to-type references: struct fields, embeddings, interface method signatures, type parameter constraints and underlying types")
+		fs.BoolVar(&cfg.globals, "globals", cfg.globals, "Record, for each package-level variable, the functions that read it, write it or take its address")
+		fs.BoolVar(&cfg.initEffects, "init-effects", cfg.initEffects, "Inventory blank imports (_ \"pkg\") with the init chain they trigger and what those init functions do: calls to other packages and globals they set")
+		fs.BoolVar(&cfg.clockUsage, "clock-usage", cfg.clockUsage, "Inventory time.Now/Since/Until, time.Sleep, timers and tickers, math/rand and crypto/rand call sites per package")
+		fs.BoolVar(&cfg.lifecycle, "lifecycle", cfg.lifecycle, "Attach to each type its New*/Must* constructors, Close/Shutdown/Stop methods and the go statements its instances are passed to")
+		fs.BoolVar(&cfg.locks, "locks", cfg.locks, "Report mutexes copied by value (LOCK_COPY), locks not released on every path (LOCK_NOT_RELEASED) and mutex pairs acquired in opposite orders (LOCK_ORDER)")
+		fs.BoolVar(&cfg.resourceLeaks, "resource-leaks", cfg.resourceLeaks, "Report files (os.Open, os.Create), HTTP response bodies and sql.Rows not closed on every path as RESOURCE_NOT_CLOSED warnings, using SSA")
+		fs.StringVar(&cfg.archRules, "arch-rules", cfg.archRules, "Rules file of 'deny FROM -> TO' and 'allow FROM -> TO' lines over package globs; imports that break them are ARCH_VIOLATION errors")
+		fs.BoolVar(&cfg.recvIssues, "receiver-issues", cfg.recvIssues, "Report methods that modify a value receiver (LOST_RECEIVER_WRITE) or copy a receiver larger than 80 bytes (LARGE_VALUE_RECEIVER)")
+		fs.StringVar(&cfg.buildMatrix, "build-matrix", cfg.buildMatrix, "Evaluate build constraints on these goos/goarch[+tag...] platforms (comma-separated, or 'default' for linux, darwin and windows on amd64 and arm64) and report the files, packages and symbols each one includes")
+		fs.StringVar(&cfg.configs, "configs", cfg.configs, "Load and extract symbols for each goos/goarch[+tag...] configuration (comma-separated) and merge them, tagging symbols with the configurations where they exist; the other phases use the first one")
+		fs.StringVar(&cfg.passes, "passes", cfg.passes, "Comma-separated go/analysis passes to run, reported as warnings (e.g. nilness,shadow)")
+	}
 
-	// Flag avanzati
-	flag.StringVar(&cfg.cgAlgo, "cg", "rta", "Call graph algorithm: cha|rta")
-	flag.BoolVar(&cfg.includeTests, "include-tests", false, "Include *_test.go files in analysis")
-	flag.StringVar(&cfg.excludeDirs, "exclude-dirs", "", "Comma-separated directory basenames to exclude (e.g., vendor,.git)")
-	flag.StringVar(&cfg.onlyPkg, "only-pkg", "", "Comma-separated package path filters (substring match)")
-	flag.StringVar(&cfg.emitPositions, "emit-positions", "detailed", "Position verbosity: detailed|minimal")
-	flag.BoolVar(&cfg.includeBody, "include-body", false, "Include function body information")
-	flag.BoolVar(&cfg.compact, "compact", false, "Compact JSON output for LLM (reduces size ~70%)")
-	flag.BoolVar(&cfg.compact, "c", false, "Compact output (shorthand)")
-	flag.BoolVar(&cfg.verbose, "verbose", false, "Enable verbose logging to stderr")
-	flag.BoolVar(&cfg.verbose, "v", false, "Enable verbose logging (shorthand)")
-	flag.BoolVar(&cfg.quiet, "quiet", false, "Suppress all non-error output")
-	flag.BoolVar(&cfg.quiet, "q", false, "Suppress non-error output (shorthand)")
-	flag.BoolVar(&cfg.showVersion, "version", false, "Show version and exit")
-	flag.BoolVar(&cfg.security, "security", false, "Enable security analysis: string extraction, supply chain vectors, obfuscation metrics")
-
-	// Flag legacy (retrocompatibilità deprecata)
-	flag.StringVar(&cfg.root, "root", "", "[DEPRECATED] Use --input instead")
-	flag.StringVar(&cfg.mode, "mode", "", "[DEPRECATED] Use --analysis-level instead")
-	flag.StringVar(&cfg.out, "out", "", "[DEPRECATED] Use --output instead")
-	// Alias per retrocompatibilità con vecchio flag
-	flag.BoolVar(&cfg.includeTests, "include-test", false, "[DEPRECATED] Use --include-tests instead")
-
-	flag.Parse()
-	return cfg
+	if groups&flagsCallGraph != 0 {
+		fs.StringVar(&cfg.cgAlgo, "cg", cfg.cgAlgo, "Call graph algorithm: cha|rta|vta|static-approx (typed AST only, no SSA)")
+		fs.BoolVar(&cfg.cgReach, "cg-reach", cfg.cgReach, "Annotate call graph nodes with transitive reach size")
+		fs.BoolVar(&cfg.effects, "effects", cfg.effects, "Annotate call graph nodes and callables with the side effects (reads_fs, writes_fs, network, exec, env) of the standard library calls they reach")
+		fs.StringVar(&cfg.cgGranularity, "cg-granularity", cfg.cgGranularity, "Call graph granularity: func, or pkg to collapse nodes to packages with aggregated edge counts")
+		fs.BoolVar(&cfg.cgWrappers, "cg-collapse-wrappers", cfg.cgWrappers, "Remove thin wrappers (a single call forwarding all parameters) from the call graph, connecting their callers to the wrapped callees")
+		fs.IntVar(&cfg.cgCallSites, "cg-max-call-sites", cfg.cgCallSites, "Maximum call-site positions listed per edge when a caller calls the same callee more than once (0 = count only)")
+		fs.BoolVar(&cfg.reportCycles, "report-cycles", cfg.reportCycles, "Report recursion groups (call graph SCCs) and import cycles")
+		fs.StringVar(&cfg.updateFrom, "update-from", cfg.updateFrom, "Previous analysis.json whose call graph is updated instead of rebuilt (with --changed-pkgs)")
+		fs.StringVar(&cfg.changedPkgs, "changed-pkgs", cfg.changedPkgs, "Comma-separated import paths changed since --update-from; only they and their importers are rebuilt")
+	}
+
+	if groups&flagsAnalyze != 0 {
+		fs.StringVar(&cfg.analysisLevel, "analysis-level", cfg.analysisLevel, "Analysis level: symbol_table|call_graph|pdg|sdg|full|summaries")
+		fs.StringVar(&cfg.analysisLevel, "a", cfg.analysisLevel, "Analysis level (shorthand)")
+		fs.StringVar(&cfg.profile, "profile", cfg.profile, "Preset for the flags not given explicitly: fast (symbols only), standard (call graph with CHA), deep (full analysis, VTA call graph, bodies and call sites)")
+		fs.BoolVar(&cfg.allocHotspots, "alloc-hotspots", cfg.allocHotspots, "Report allocation hotspots (allocations/concatenation in loops, capturing closures) as info issues")
+	}
+
+	if groups&flagsLegacy != 0 {
+		// Flag legacy (retrocompatibilità deprecata, rimossi nella prossima release)
+		fs.StringVar(&cfg.root, "root", "", "[DEPRECATED] Use --input instead")
+		fs.StringVar(&cfg.mode, "mode", "", "[DEPRECATED] Use a subcommand or --analysis-level instead")
+		fs.StringVar(&cfg.out, "out", "", "[DEPRECATED] Use --output instead")
+		// Alias per retrocompatibilità con vecchio flag
+		fs.BoolVar(&cfg.includeTests, "include-test", false, "[DEPRECATED] Use --include-tests instead")
+	}
 }
 
 func handleLegacyFlags(cfg config) config {
@@ -134,7 +338,7 @@ func handleLegacyFlags(cfg config) config {
 
 	// --mode → --analysis-level
 	if cfg.mode != "" {
-		logWarning("--mode is deprecated, use --analysis-level instead")
+		logWarning("--mode is deprecated and will be removed in the next release, use a subcommand (symbols, callgraph) or --analysis-level instead")
 		if cfg.analysisLevel == "full" {
 			// Mappa vecchi mode a nuovi
 			switch cfg.mode {
@@ -144,6 +348,8 @@ func handleLegacyFlags(cfg config) config {
 				cfg.analysisLevel = levelCallGraph
 			case "full":
 				cfg.analysisLevel = levelFull
+			case "summaries":
+				cfg.analysisLevel = levelSummaries
 			default:
 				cfg.analysisLevel = cfg.mode
 			}
@@ -177,98 +383,400 @@ func validateConfig(cfg *config) error {
 		return fmt.Errorf("input path does not exist: %s", cfg.input)
 	}
 
-	// Valida analysis level
-	validLevels := map[string]bool{
-		levelSymbolTable: true,
-		levelCallGraph:   true,
-		levelPDG:         true,
-		levelSDG:         true,
-		levelFull:        true,
+	if cfg.rootArchive != "" {
+		if _, err := os.Stat(cfg.rootArchive); err != nil {
+			return fmt.Errorf("root archive does not exist: %s", cfg.rootArchive)
+		}
+		if cfg.files != "" {
+			return fmt.Errorf("--files cannot be combined with --root-archive")
+		}
 	}
-	if !validLevels[cfg.analysisLevel] {
-		return fmt.Errorf("invalid analysis-level: %s (valid: symbol_table, call_graph, pdg, sdg, full)", cfg.analysisLevel)
+
+	// Valida analysis level
+	if !analysisLevels[cfg.analysisLevel] {
+		return fmt.Errorf("invalid analysis-level: %s (valid: symbol_table, call_graph, pdg, sdg, full, summaries)", cfg.analysisLevel)
 	}
 
 	// Valida format
-	if cfg.format != "json" && cfg.format != "msgpack" {
-		return fmt.Errorf("invalid format: %s (valid: json, msgpack)", cfg.format)
+	switch output.Format(cfg.format) {
+	case output.FormatJSON, output.FormatMsgpack:
+	case output.FormatCSV, output.FormatTSV, output.FormatHTML, output.FormatTreemap, output.FormatFrames, output.FormatCallHierarchy, output.FormatNeo4j, output.FormatFacts, output.FormatOpenAPI:
+		if cfg.compact {
+			return fmt.Errorf("--compact cannot be combined with --format %s", cfg.format)
+		}
+	default:
+		return fmt.Errorf("invalid format: %s (valid: json, csv, tsv, html, treemap, ndjson-frames, call-hierarchy, neo4j, facts, openapi, msgpack)", cfg.format)
+	}
+	if cfg.skipBoiler && !cfg.compact {
+		return fmt.Errorf("--compact-skip-boilerplate requires --compact")
+	}
+	switch cfg.treemapSize {
+	case treemap.SizeSLOC:
+	case treemap.SizeComplexity:
+		// La complessità è calcolata sui body
+		if cfg.format == string(output.FormatTreemap) {
+			cfg.includeBody = true
+		}
+	default:
+		return fmt.Errorf("invalid treemap-size: %s (valid: sloc, complexity)", cfg.treemapSize)
+	}
+	if cfg.neo4jMode != output.Neo4jCSV && cfg.neo4jMode != output.Neo4jCypher {
+		return fmt.Errorf("invalid neo4j-mode: %s (valid: csv, cypher)", cfg.neo4jMode)
+	}
+	if cfg.format == string(output.FormatTreemap) && cfg.analysisLevel != levelSymbolTable && cfg.analysisLevel != levelFull {
+		return fmt.Errorf("--format treemap needs the symbol table (analysis level symbol_table or full)")
+	}
+	if cfg.format == string(output.FormatOpenAPI) {
+		if cfg.analysisLevel != levelSymbolTable && cfg.analysisLevel != levelFull {
+			return fmt.Errorf("--format openapi needs the symbol table (analysis level symbol_table or full)")
+		}
+		cfg.httpAPI = true
+	}
+	if cfg.format == string(output.FormatFacts) && cfg.emitPositions == "minimal" {
+		return fmt.Errorf("--format facts needs column positions for its anchors (--emit-positions detailed)")
+	}
+	if cfg.format == string(output.FormatCallHierarchy) {
+		if cfg.analysisLevel != levelCallGraph && cfg.analysisLevel != levelSDG && cfg.analysisLevel != levelFull {
+			return fmt.Errorf("--format call-hierarchy needs the call graph (analysis level call_graph, sdg or full)")
+		}
+		if cfg.cgGranularity != callgraph.GranularityFunc || cfg.emitPositions == "minimal" {
+			return fmt.Errorf("--format call-hierarchy needs function nodes with positions (--cg-granularity func, --emit-positions detailed)")
+		}
+	}
+
+	if _, err := output.ParseCompression(cfg.compress); err != nil {
+		return err
+	}
+	if cfg.deterministic {
+		switch output.Format(cfg.format) {
+		case output.FormatTreemap, output.FormatOpenAPI, output.FormatCallHierarchy, output.FormatFacts:
+			// leggono i sorgenti o scrivono URI a partire dal percorso assoluto del progetto
+			return fmt.Errorf("--deterministic cannot be combined with --format %s", cfg.format)
+		}
+	}
+
+	if _, err := passes.Lookup(splitCSV(cfg.passes)); err != nil {
+		return err
+	}
+
+	if cfg.packages, err = pkgfilter.New(splitCSV(cfg.onlyPkg), splitCSV(cfg.matchPkg), splitCSV(cfg.excludePkg)); err != nil {
+		return err
 	}
 
 	// Valida cg algorithm
 	cgAlgo := strings.ToLower(cfg.cgAlgo)
-	if cgAlgo != "cha" && cgAlgo != "rta" {
-		return fmt.Errorf("invalid cg algorithm: %s (valid: cha, rta)", cfg.cgAlgo)
+	if cgAlgo != "cha" && cgAlgo != "rta" && cgAlgo != "vta" && cgAlgo != callgraph.AlgorithmStaticApprox {
+		return fmt.Errorf("invalid cg algorithm: %s (valid: cha, rta, vta, static-approx)", cfg.cgAlgo)
 	}
 	cfg.cgAlgo = cgAlgo
 
+	if cfg.cgGranularity != callgraph.GranularityFunc && cfg.cgGranularity != callgraph.GranularityPkg {
+		return fmt.Errorf("invalid cg granularity: %s (valid: func, pkg)", cfg.cgGranularity)
+	}
+
+	if cfg.cgCallSites < 0 {
+		return fmt.Errorf("invalid cg-max-call-sites: %d (must be >= 0)", cfg.cgCallSites)
+	}
+
+	if cfg.purityDepth < 0 {
+		return fmt.Errorf("invalid purity-depth: %d (must be >= 0)", cfg.purityDepth)
+	}
+
+	if cfg.summarizerCmd != "" && cfg.summarizerURL != "" {
+		return fmt.Errorf("--summarizer-cmd and --summarizer-url are mutually exclusive")
+	}
+	switch cfg.summaryScope {
+	case summarize.ScopePackages, summarize.ScopeCallables, summarize.ScopeAll:
+	default:
+		return fmt.Errorf("invalid summarize: %s (valid: packages, callables, all)", cfg.summaryScope)
+	}
+	if cfg.sumWorkers < 1 {
+		return fmt.Errorf("invalid summary-workers: %d (must be >= 1)", cfg.sumWorkers)
+	}
+
+	// Valida fail-on
+	if cfg.failOn != "" && cfg.failOn != "error" && cfg.failOn != "warning" {
+		return fmt.Errorf("invalid fail-on: %s (valid: error, warning)", cfg.failOn)
+	}
+
 	// Valida emit-positions
 	if cfg.emitPositions != "detailed" && cfg.emitPositions != "minimal" {
 		return fmt.Errorf("invalid emit-positions: %s (valid: detailed, minimal)", cfg.emitPositions)
 	}
 
+	// Aggiornamento incrementale: solo il call graph, senza SSA completo
+	if (cfg.updateFrom == "") != (cfg.changedPkgs == "") {
+		return fmt.Errorf("--update-from and --changed-pkgs must be used together")
+	}
+	if cfg.updateFrom != "" && cfg.analysisLevel != levelCallGraph {
+		return fmt.Errorf("--update-from requires --analysis-level call_graph")
+	}
+
 	return nil
 }
 
 func runAnalysis(cfg config) error {
 	startTime := time.Now()
 
-	logVerbose(cfg, "Starting analysis...")
-	logVerbose(cfg, "  Input: %s", cfg.input)
-	logVerbose(cfg, "  Level: %s", cfg.analysisLevel)
-	logVerbose(cfg, "  Algorithm: %s", cfg.cgAlgo)
-	logVerbose(cfg, "  Go version: %s", runtime.Version())
+	logInfo("Starting analysis...")
+	logInfo("  Input: %s", cfg.input)
+	logInfo("  Level: %s", cfg.analysisLevel)
+	logInfo("  Algorithm: %s", cfg.cgAlgo)
+	logInfo("  Go version: %s", runtime.Version())
 
 	// Determina se serve SSA
-	needSSA := cfg.analysisLevel == levelCallGraph || cfg.analysisLevel == levelPDG ||
-		cfg.analysisLevel == levelSDG || cfg.analysisLevel == levelFull
+	needSSA := cfg.analysisLevel == levelPDG || cfg.analysisLevel == levelSDG ||
+		cfg.analysisLevel == levelFull || cfg.analysisLevel == levelSummaries ||
+		(cfg.analysisLevel == levelCallGraph && cfg.cgAlgo != callgraph.AlgorithmStaticApprox) ||
+		(cfg.purity && (cfg.analysisLevel == levelSymbolTable || cfg.analysisLevel == levelFull)) ||
+		cfg.resourceLeaks
+
+	// Con --update-from l'SSA è costruito solo per i package da ricostruire
+	var prevCallGraph *schema.CLDKCallGraph
+	if cfg.updateFrom != "" {
+		prev, err := output.ReadFile(cfg.updateFrom)
+		if err != nil {
+			return &exitError{exitLoad, fmt.Errorf("--update-from: %w", err)}
+		}
+		if prev.CallGraph == nil {
+			return &exitError{exitLoad, fmt.Errorf("--update-from: %s has no call graph", cfg.updateFrom)}
+		}
+		if prev.CallGraph.Granularity == callgraph.GranularityPkg {
+			return &exitError{exitUsage, fmt.Errorf("--update-from: %s has a package-level call graph", cfg.updateFrom)}
+		}
+		if prev.CallGraph.CollapsedWrappers > 0 {
+			return &exitError{exitUsage, fmt.Errorf("--update-from: %s has a call graph with collapsed wrappers", cfg.updateFrom)}
+		}
+		prevCallGraph = prev.CallGraph
+		cfg.cgAlgo = callgraph.BaseAlgorithm(prevCallGraph.Algorithm)
+		needSSA = false
+	}
+
+	// Progress reporter (opt-in, soppresso da --quiet)
+	var progress *logging.Progress
+	if cfg.progress && !cfg.quiet {
+		progress = logging.NewProgress(os.Stderr)
+		defer progress.Done()
+	}
+
+	var configs []buildmatrix.Platform
+	if cfg.configs != "" {
+		var err error
+		if configs, err = buildmatrix.ParsePlatforms(cfg.configs); err != nil {
+			return &exitError{exitUsage, fmt.Errorf("--configs: %w", err)}
+		}
+	}
 
 	// Carica pacchetti
 	loaderOpts := loader.Options{
+		Progress:    progress,
 		IncludeTest: cfg.includeTests,
 		ExcludeDirs: splitCSV(cfg.excludeDirs),
-		OnlyPkg:     splitCSV(cfg.onlyPkg),
+		Packages:    cfg.packages,
+		NeedSyntax:  true,
+		NeedTypes:   true,
 		NeedSSA:     needSSA,
+		MaxMemoryMB: cfg.maxMemoryMB,
+		Files:       splitCSV(cfg.files),
+		Binaries:    splitCSV(cfg.binary),
+	}
+	if configs != nil {
+		loaderOpts.Env = configs[0].Env()
+	}
+	if cfg.overlay != "" {
+		overlay, err := loader.ReadOverlay(cfg.overlay)
+		if err != nil {
+			return &exitError{exitLoad, err}
+		}
+		loaderOpts.Overlay = overlay
+		logInfo("Using overlay with %d files", len(overlay))
+	}
+	var platforms []buildmatrix.Platform
+	if cfg.buildMatrix != "" {
+		var err error
+		if platforms, err = buildmatrix.ParsePlatforms(cfg.buildMatrix); err != nil {
+			return &exitError{exitUsage, fmt.Errorf("--build-matrix: %w", err)}
+		}
+	}
+	var ownerMap *owners.Map
+	if cfg.ownersFile != "" {
+		var err error
+		if ownerMap, err = owners.Load(cfg.ownersFile); err != nil {
+			return &exitError{exitUsage, fmt.Errorf("read owners: %w", err)}
+		}
+	}
+	var archRules *archrules.Rules
+	if cfg.archRules != "" {
+		var err error
+		if archRules, err = archrules.Load(cfg.archRules); err != nil {
+			return &exitError{exitUsage, fmt.Errorf("read architecture rules: %w", err)}
+		}
+	}
+
+	// Archivio di sorgenti: montato come overlay su una root vuota, senza
+	// estrarlo
+	root, projectPath := cfg.input, cfg.input
+	if cfg.rootArchive != "" {
+		fsys, closer, err := loader.OpenArchive(cfg.rootArchive)
+		if err != nil {
+			return &exitError{exitLoad, err}
+		}
+		defer closer.Close()
+		if root, err = os.MkdirTemp("", "codeanalyzer-archive-"); err != nil {
+			return &exitError{exitLoad, err}
+		}
+		defer os.RemoveAll(root)
+		loaderOpts.FS = fsys
+		projectPath, _ = filepath.Abs(cfg.rootArchive)
+		logInfo("Analyzing archive %s", projectPath)
+	}
+
+	// Memory budget: limite soft per il GC, che vale già per il caricamento.
+	// Il loader costruisce poi l'SSA un package alla volta.
+	if needSSA && cfg.maxMemoryMB > 0 {
+		debug.SetMemoryLimit(int64(cfg.maxMemoryMB) << 20)
 	}
 
-	logVerbose(cfg, "Loading packages...")
-	result, err := loader.LoadWithSSA(cfg.input, loaderOpts)
+	logInfo("Loading packages...")
+	result, err := loader.Load(root, loaderOpts)
 	if err != nil {
-		return fmt.Errorf("load packages: %w", err)
+		return &exitError{exitLoad, fmt.Errorf("load packages: %w", err)}
+	}
+	logInfo("Loaded %d packages", len(result.Packages))
+	var components []metrics.Component
+	if cfg.components != "" {
+		// go.work e go.mod sono letti dai sorgenti del loader
+		if components, err = metrics.ParseComponents(cfg.components, result.FS); err != nil {
+			return &exitError{exitUsage, fmt.Errorf("--components: %w", err)}
+		}
+	}
+
+	// load è la risoluzione dei package, typecheck parsing e type checking
+	timings := phaseTimings{
+		"load":      (result.LoadDuration - result.TypeCheckDuration).Milliseconds(),
+		"typecheck": result.TypeCheckDuration.Milliseconds(),
+	}
+	if result.SSAProgram != nil {
+		timings["ssa"] = result.SSADuration.Milliseconds()
 	}
-	logVerbose(cfg, "Loaded %d packages", len(result.Packages))
 
 	// Inizializza analisi CLDK
 	analysis := &schema.CLDKAnalysis{
 		Metadata: schema.Metadata{
-			Analyzer:      "codeanalyzer-go",
-			Version:       version,
-			Language:      "go",
-			AnalysisLevel: cfg.analysisLevel,
-			Timestamp:     time.Now().UTC().Format(time.RFC3339),
-			ProjectPath:   cfg.input,
-			GoVersion:     runtime.Version(),
+			Analyzer:       "codeanalyzer-go",
+			Version:        version,
+			SchemaVersion:  schema.SchemaVersion,
+			Language:       "go",
+			AnalysisLevel:  cfg.analysisLevel,
+			Timestamp:      time.Now().UTC().Format(time.RFC3339),
+			ProjectPath:    projectPath,
+			GoVersion:      runtime.Version(),
+			Profile:        cfg.profile,
+			ScopedFiles:    result.ScopedFiles(),
+			ScopedBinaries: result.Binaries,
+			Configs:        platformNames(configs),
 		},
 		PDG:    nil,
 		SDG:    nil,
 		Issues: []schema.Issue{},
 	}
 
+	// Errori di caricamento/parsing/type checking: l'analisi prosegue sui
+	// package validi, ma il risultato è degradato
+	analysis.Issues = append(analysis.Issues, diag.LoadIssues(result.Errors)...)
+	if len(result.Errors) > 0 {
+		logWarning("%d load/parse/type errors, results are partial", len(result.Errors))
+	}
+	// Con l'SSA per-package gli altri package del progetto non hanno body:
+	// le fasi SSA perdono precisione tra un package e l'altro
+	if result.PerPackageSSA {
+		analysis.Issues = append(analysis.Issues, schema.Issue{
+			Severity: "info",
+			Code:     "PER_PACKAGE_SSA",
+			Message:  fmt.Sprintf("SSA is built one package at a time (--max-memory-mb %d): other project packages have no bodies, so call graph, summaries, purity, hotspots and leak checks miss what crosses package boundaries", cfg.maxMemoryMB),
+		})
+	}
+
 	// Estrai symbol table se richiesto
 	if cfg.analysisLevel == levelSymbolTable || cfg.analysisLevel == levelFull {
-		logVerbose(cfg, "Extracting symbols...")
+		logInfo("Extracting symbols...")
+		progress.Phase("Extracting symbols")
 		symbolCfg := symbols.ExtractConfig{
-			IncludeBody:      cfg.includeBody,
+			OnPackage:        progress.Step,
+			IncludeBody:      cfg.includeBody || cfg.bodyComments || cfg.exSnippets,
 			EmitPositions:    cfg.emitPositions,
-			IncludeCallSites: cfg.includeBody,
+			IncludeCallSites: cfg.includeBody || cfg.bodyComments || cfg.exSnippets,
+			IncludeComments:  cfg.bodyComments,
+			ExampleSnippets:  cfg.exSnippets,
+			FlatDocs:         cfg.flatDocs,
+			Examples:         cfg.examples,
+			Fingerprints:     cfg.fingerprints,
+			OnConflict: func(iss schema.Issue) {
+				analysis.Issues = append(analysis.Issues, iss)
+			},
 		}
+		stop := timings.start("extract")
 		analysis.SymbolTable = symbols.Extract(result, symbolCfg)
-		logVerbose(cfg, "Extracted %d packages", len(analysis.SymbolTable.Packages))
+		stop()
+
+		// Configurazioni aggiuntive (--configs): solo caricamento ed estrazione
+		if len(configs) > 1 {
+			stop := timings.start("configs")
+			tables := []*schema.CLDKSymbolTable{analysis.SymbolTable}
+			names := []string{configs[0].String()}
+			for _, c := range configs[1:] {
+				logInfo("Extracting symbols for %s...", c)
+				opts := loaderOpts
+				opts.Env, opts.NeedSSA, opts.Progress = c.Env(), false, nil
+				res, err := loader.Load(root, opts)
+				if err != nil {
+					analysis.Issues = append(analysis.Issues, schema.Issue{
+						Severity: "warning",
+						Code:     "CONFIG_LOAD_ERROR",
+						Message:  fmt.Sprintf("Failed to load packages for %s: %v", c, err),
+					})
+					logWarning("load for %s failed: %v", c, err)
+					continue
+				}
+				analysis.Issues = append(analysis.Issues, diag.LoadIssues(res.Errors)...)
+				extra := symbolCfg
+				extra.OnPackage = nil
+				tables = append(tables, symbols.Extract(res, extra))
+				names = append(names, c.String())
+			}
+			analysis.SymbolTable = symbols.MergeConfigs(tables, names)
+			stop()
+		}
+		logInfo("Extracted %d packages", len(analysis.SymbolTable.Packages))
+
+		// Struct memory layout (opt-in via --struct-layout)
+		if cfg.structLayout {
+			logInfo("Computing struct layouts...")
+			stop := timings.start("layout")
+			for _, pkg := range result.ScopedPackages() {
+				if pkg == nil {
+					continue
+				}
+				if cldkPkg, ok := analysis.SymbolTable.Packages[pkg.PkgPath]; ok {
+					analysis.Issues = append(analysis.Issues, layout.Annotate(pkg, cldkPkg, cfg.layoutSavings)...)
+				}
+			}
+			stop()
+		}
+
+		// Ciclo di vita dei tipi (opt-in via --lifecycle)
+		if cfg.lifecycle {
+			stop := timings.start("lifecycle")
+			symbols.PopulateLifecycle(analysis.SymbolTable, result)
+			stop()
+		}
 
 		// Security analysis (opt-in via --security flag)
 		if cfg.security {
-			logVerbose(cfg, "Running security analysis...")
+			logInfo("Running security analysis...")
+			stop := timings.start("security")
 			strCfg := gostrings.DefaultConfig()
-			for _, pkg := range result.Packages {
+			for _, pkg := range result.ScopedPackages() {
 				if pkg == nil {
 					continue
 				}
@@ -300,122 +808,506 @@ func runAnalysis(cfg config) error {
 					cldkPkg.ObfuscationMetrics.HighEntropyStrings = highEntropy
 				}
 			}
-			logVerbose(cfg, "Security analysis completed")
+			stop()
+			logInfo("Security analysis completed")
+		}
+
+		// Git ownership metadata (opt-in via --with-git-metadata)
+		if cfg.gitMetadata {
+			logInfo("Collecting git metadata...")
+			stop := timings.start("git_metadata")
+			err := gitmeta.Enrich(analysis.SymbolTable, result.Root)
+			stop()
+			if err != nil {
+				analysis.Issues = append(analysis.Issues, schema.Issue{
+					Severity: "warning",
+					Code:     "GIT_METADATA_ERROR",
+					Message:  fmt.Sprintf("Failed to collect git metadata: %v", err),
+				})
+				logWarning("git metadata failed: %v", err)
+			}
 		}
+
+		// Owners e tag dal file sidecar (opt-in via --owners)
+		owners.Apply(analysis.SymbolTable, ownerMap)
+
+		// Risorse incluse con //go:embed
+		analysis.Resources = embeds.Inventory(result.Packages, result.Fset, result.Root, result.FS)
+
+		// Grafo dei provider di wire, fx e dig
+		analysis.DI = di.Build(result.Packages, result.Fset, result.Root)
+
+		// Tipi delle API Kubernetes e CRD
+		analysis.Kubernetes = kube.Inventory(result.ScopedPackages(), result.Fset, result.Root)
+
+		// Chiamate a time e rand (opt-in via --clock-usage)
+		if cfg.clockUsage {
+			stop := timings.start("clock_usage")
+			analysis.Clock = clock.Inventory(result.ScopedPackages(), result.Fset, result.Root)
+			stop()
+		}
+
+		// Endpoint HTTP e schemi degli handler (opt-in via --http-api)
+		if cfg.httpAPI {
+			stop := timings.start("http_api")
+			analysis.HTTPAPI = openapi.Extract(result.ScopedPackages(), result.Fset, result.Root)
+			stop()
+		}
+
+		// File SQL e query del codice Go (opt-in via --data-access)
+		if cfg.dataAccess {
+			stop := timings.start("data_access")
+			var issues []schema.Issue
+			analysis.DataAccess, issues = dataaccess.Inventory(result.ScopedPackages(), result.Fset, result.Root, result.FS, splitCSV(cfg.excludeDirs), analysis.Resources)
+			analysis.Issues = append(analysis.Issues, issues...)
+			stop()
+		}
+
+		// Tipi di errore, sentinelle e target di errors.Is/As (opt-in via --error-taxonomy)
+		if cfg.errTaxonomy {
+			stop := timings.start("error_taxonomy")
+			analysis.Errors = errtaxonomy.Inventory(result.ScopedPackages(), result.Fset, result.Root, result.FS)
+			stop()
+		}
+
+		// Riferimenti tra tipi (opt-in via --type-graph)
+		if cfg.typeGraph {
+			stop := timings.start("type_graph")
+			analysis.TypeGraph = typegraph.Build(result.ScopedPackages(), result.Fset, result.Root)
+			stop()
+		}
+
+		// Accessi alle variabili globali (opt-in via --globals)
+		if cfg.globals {
+			stop := timings.start("globals")
+			analysis.Globals = globals.Inventory(result.ScopedPackages(), result.Fset, result.Root)
+			stop()
+		}
+
+		// Import vuoti ed effetti delle init (opt-in via --init-effects)
+		if cfg.initEffects {
+			stop := timings.start("init_effects")
+			analysis.InitEffects = initeffects.Inventory(result.ScopedPackages(), result.Fset, result.Root)
+			stop()
+		}
+	}
+
+	// Matrice dei vincoli di build (opt-in via --build-matrix)
+	if platforms != nil {
+		logInfo("Evaluating build constraints on %d platforms...", len(platforms))
+		stop := timings.start("build_matrix")
+		analysis.BuildMatrix = buildmatrix.Compute(result.Packages, result.Root, result.FS, platforms, cfg.packages, splitCSV(cfg.excludeDirs))
+		stop()
+	}
+
+	// Controlli lint sull'AST tipato (opt-in via --lint)
+	if cfg.lint {
+		logInfo("Running lint checks...")
+		stop := timings.start("lint")
+		found := lint.Check(result)
+		stop()
+		analysis.Issues = append(analysis.Issues, found...)
+		logInfo("Found %d lint issues", len(found))
+	}
+
+	// Simboli package-level mai referenziati (opt-in via --dead-symbols)
+	if cfg.deadSymbols {
+		logInfo("Looking for unreferenced package-level symbols...")
+		stop := timings.start("dead_symbols")
+		found := lint.DeadSymbols(result)
+		stop()
+		analysis.Issues = append(analysis.Issues, found...)
+		logInfo("Found %d unreferenced symbols", len(found))
+	}
+
+	// Esportati usati solo nel proprio package (opt-in via --unexport-candidates)
+	if cfg.unexportable {
+		logInfo("Looking for exported symbols used only in their package...")
+		stop := timings.start("unexport")
+		found := lint.UnexportCandidates(result)
+		stop()
+		analysis.Issues = append(analysis.Issues, found...)
+		logInfo("Found %d unexport candidates", len(found))
+	}
+
+	// Receiver per valore modificati o costosi da copiare (opt-in via --receiver-issues)
+	if cfg.recvIssues {
+		stop := timings.start("receivers")
+		found := symbols.ReceiverIssues(result)
+		stop()
+		analysis.Issues = append(analysis.Issues, found...)
+		logInfo("Found %d receiver issues", len(found))
+	}
+
+	// Mutex copiati, lock non rilasciati e ordine dei lock (opt-in via --locks)
+	if cfg.locks {
+		logInfo("Checking mutex usage...")
+		stop := timings.start("locks")
+		found := lint.Locks(result)
+		stop()
+		analysis.Issues = append(analysis.Issues, found...)
+		logInfo("Found %d lock issues", len(found))
+	}
+
+	// Regole di architettura sugli import (opt-in via --arch-rules)
+	if archRules != nil {
+		logInfo("Checking architecture rules...")
+		stop := timings.start("arch_rules")
+		found := archrules.Check(archRules, result)
+		stop()
+		analysis.Issues = append(analysis.Issues, found...)
+		logInfo("Found %d architecture violations", len(found))
 	}
 
+	// Analyzer go/analysis selezionati con --passes
+	if cfg.passes != "" {
+		logInfo("Running analysis passes: %s...", cfg.passes)
+		stop := timings.start("passes")
+		analyzers, _ := passes.Lookup(splitCSV(cfg.passes)) // validati in validateConfig
+		found, err := passes.Run(result, analyzers)
+		stop()
+		if err != nil {
+			analysis.Issues = append(analysis.Issues, schema.Issue{
+				Severity: "warning",
+				Code:     passes.CodeError,
+				Message:  fmt.Sprintf("Failed to run analysis passes: %v", err),
+			})
+			logWarning("analysis passes failed: %v", err)
+		}
+		analysis.Issues = append(analysis.Issues, found...)
+		logInfo("Found %d pass diagnostics", len(found))
+	}
+
+	// Fasi richieste dal livello di analisi che non è stato possibile
+	// costruire: l'output è scritto comunque, poi il processo fallisce
+	var failed []string
+
 	// Costruisci call graph se richiesto (SDG lo richiede)
 	if cfg.analysisLevel == levelCallGraph || cfg.analysisLevel == levelSDG || cfg.analysisLevel == levelFull {
-		logVerbose(cfg, "Building call graph with %s...", cfg.cgAlgo)
+		logInfo("Building call graph with %s...", cfg.cgAlgo)
+		progress.Phase("Building call graph")
 		cgCfg := callgraph.Config{
 			Algorithm:     cfg.cgAlgo,
 			EmitPositions: cfg.emitPositions,
-			OnlyPkg:       splitCSV(cfg.onlyPkg),
+			Packages:      cfg.packages,
+			Reach:         cfg.cgReach,
+			MaxCallSites:  cfg.cgCallSites,
+			OnFallback: func(iss schema.Issue) {
+				analysis.Issues = append(analysis.Issues, iss)
+			},
 		}
-		cg, err := callgraph.Build(result, cgCfg)
+		stop := timings.start("callgraph")
+		var cg *schema.CLDKCallGraph
+		if prevCallGraph != nil {
+			affected := result.Dependents(splitCSV(cfg.changedPkgs))
+			logInfo("Updating call graph from %s: rebuilding %d packages", cfg.updateFrom, len(affected))
+			cg, err = callgraph.Update(prevCallGraph, result, affected, cgCfg)
+		} else {
+			cg, err = buildCallGraph(result, cgCfg, cfg.spillDir)
+		}
+		stop()
 		if err != nil {
 			// Non bloccare, aggiungi issue
 			analysis.Issues = append(analysis.Issues, schema.Issue{
-				Severity: "warning",
+				Severity: "error",
 				Code:     "CALLGRAPH_ERROR",
 				Message:  fmt.Sprintf("Failed to build call graph: %v", err),
 			})
 			logWarning("call graph build failed: %v", err)
+			failed = append(failed, "callgraph")
 		} else {
 			analysis.CallGraph = cg
-			logVerbose(cfg, "Call graph: %d nodes, %d edges", len(cg.Nodes), len(cg.Edges))
+			logInfo("Call graph: %d nodes, %d edges", len(cg.Nodes), len(cg.Edges))
+			// Funzioni in assembly o //go:linkname: archi uscenti mancanti
+			analysis.Issues = append(analysis.Issues, symbols.StubIssues(result)...)
 		}
 	}
 
 	// Costruisci PDG se richiesto (SDG lo richiede)
 	if cfg.analysisLevel == levelPDG || cfg.analysisLevel == levelSDG || cfg.analysisLevel == levelFull {
-		logVerbose(cfg, "Building PDG...")
+		logInfo("Building PDG...")
+		progress.Phase("Building PDG")
 		pdgCfg := pdg.Config{
 			EmitPositions: cfg.emitPositions,
-			OnlyPkg:       splitCSV(cfg.onlyPkg),
+			Packages:      cfg.packages,
 		}
-		pdgResult, err := pdg.Build(result, pdgCfg)
+		stop := timings.start("pdg")
+		pdgResult, err := buildPDG(result, pdgCfg, cfg.spillDir)
+		stop()
 		if err != nil {
 			analysis.Issues = append(analysis.Issues, schema.Issue{
-				Severity: "warning",
+				Severity: "error",
 				Code:     "PDG_ERROR",
 				Message:  fmt.Sprintf("Failed to build PDG: %v", err),
 			})
 			logWarning("PDG build failed: %v", err)
+			failed = append(failed, "pdg")
 		} else {
 			analysis.PDG = pdgResult
 			fnCount := 0
 			for _, pkg := range pdgResult.Packages {
 				fnCount += len(pkg.Functions)
 			}
-			logVerbose(cfg, "PDG: %d functions analyzed", fnCount)
+			logInfo("PDG: %d functions analyzed", fnCount)
 		}
 	}
 
 	// Costruisci SDG se richiesto (richiede PDG + call graph)
 	if cfg.analysisLevel == levelSDG || cfg.analysisLevel == levelFull {
 		if analysis.PDG != nil && analysis.CallGraph != nil {
-			logVerbose(cfg, "Building SDG...")
+			logInfo("Building SDG...")
+			progress.Phase("Building SDG")
 			sdgCfg := sdg.Config{}
+			stop := timings.start("sdg")
 			sdgResult, err := sdg.Build(analysis.PDG, analysis.CallGraph, sdgCfg)
+			stop()
 			if err != nil {
 				analysis.Issues = append(analysis.Issues, schema.Issue{
-					Severity: "warning",
+					Severity: "error",
 					Code:     "SDG_ERROR",
 					Message:  fmt.Sprintf("Failed to build SDG: %v", err),
 				})
 				logWarning("SDG build failed: %v", err)
+				failed = append(failed, "sdg")
 			} else {
 				analysis.SDG = sdgResult
-				logVerbose(cfg, "SDG: %d packages with inter-procedural edges", len(sdgResult.Packages))
+				logInfo("SDG: %d packages with inter-procedural edges", len(sdgResult.Packages))
 			}
 		} else {
 			logWarning("SDG requires both PDG and call graph to be built")
 		}
 	}
 
+	// Riassunti data-flow per funzione
+	if cfg.analysisLevel == levelSummaries {
+		logInfo("Building function summaries...")
+		stop := timings.start("summaries")
+		sumResult, err := buildSummaries(result, summary.Config{Packages: cfg.packages}, cfg.spillDir)
+		stop()
+		if err != nil {
+			analysis.Issues = append(analysis.Issues, schema.Issue{
+				Severity: "error",
+				Code:     "SUMMARY_ERROR",
+				Message:  fmt.Sprintf("Failed to build function summaries: %v", err),
+			})
+			logWarning("summaries build failed: %v", err)
+			failed = append(failed, "summaries")
+		} else {
+			analysis.Summaries = sumResult
+		}
+	}
+
+	// Funzioni pure e piegabili a costante (opt-in via --purity)
+	if cfg.purity && analysis.SymbolTable != nil && (result.SSAProgram != nil || result.PerPackageSSA) {
+		logInfo("Analyzing purity (depth %d)...", cfg.purityDepth)
+		stop := timings.start("purity")
+		pure, err := buildPurity(result, purity.Config{Depth: cfg.purityDepth, Packages: cfg.packages}, cfg.spillDir)
+		stop()
+		if err != nil {
+			logWarning("purity analysis failed: %v", err)
+		} else {
+			purity.Apply(analysis.SymbolTable, pure)
+		}
+	}
+
+	// Allocation hotspots (euristiche SSA, emessi come issue info)
+	if cfg.allocHotspots && (result.SSAProgram != nil || result.PerPackageSSA) {
+		logInfo("Looking for allocation hotspots...")
+		stop := timings.start("hotspots")
+		hot, err := buildHotspots(result, perf.Config{Packages: cfg.packages}, cfg.spillDir)
+		stop()
+		if err != nil {
+			logWarning("allocation hotspot analysis failed: %v", err)
+		} else {
+			analysis.Issues = append(analysis.Issues, hot...)
+			logInfo("Found %d allocation hotspots", len(hot))
+		}
+	}
+
+	// Risorse non chiuse su tutti i percorsi (opt-in via --resource-leaks)
+	if cfg.resourceLeaks && (result.SSAProgram != nil || result.PerPackageSSA) {
+		logInfo("Looking for resources not closed...")
+		stop := timings.start("resource_leaks")
+		found, err := buildLeaks(result, leaks.Config{Packages: cfg.packages}, cfg.spillDir)
+		stop()
+		if err != nil {
+			logWarning("resource leak analysis failed: %v", err)
+		} else {
+			analysis.Issues = append(analysis.Issues, found...)
+			logInfo("Found %d resources not closed", len(found))
+		}
+	}
+
 	// ──────────────────────────────────────────────────────────────────
 	// Post-processing: package-level metadata enrichment
 	// ──────────────────────────────────────────────────────────────────
 
+	stopPost := timings.start("postprocess")
 	if analysis.SymbolTable != nil {
 		// B5: Reverse import lookup (used_by_packages)
-		logVerbose(cfg, "Computing reverse imports...")
+		logInfo("Computing reverse imports...")
 		symbols.PopulateUsedByPackages(analysis.SymbolTable)
 
+		// Package coupling metrics (afferent/efferent, instability, abstractness)
+		logInfo("Computing package coupling metrics...")
+		analysis.Metrics = metrics.ComputeCoupling(analysis.SymbolTable)
+
+		// Layer architetturali dal grafo degli import (opt-in via --layers)
+		if cfg.layers {
+			logInfo("Inferring package layers...")
+			analysis.Layers = metrics.InferLayers(analysis.SymbolTable)
+		}
+
 		// B6: Reachable from main/init flow
 		if analysis.CallGraph != nil {
-			logVerbose(cfg, "Computing main/init reachability...")
+			logInfo("Computing main/init reachability...")
 			populateReachableFromMain(analysis.SymbolTable, analysis.CallGraph)
 		}
 	}
 
-	// Calcola durata
+	// Effetti collaterali dei callable (opt-in via --effects)
+	if cfg.effects && analysis.CallGraph != nil {
+		logInfo("Classifying side effects...")
+		effects.Annotate(analysis.CallGraph, analysis.SymbolTable, result.ProjectPackages())
+	}
+
+	// Cycle report (SCC su call graph e grafo degli import)
+	if cfg.reportCycles {
+		logInfo("Computing cycle report...")
+		analysis.Cycles = graph.CycleReport(analysis.SymbolTable, analysis.CallGraph)
+	}
+
+	// Vista per componenti (monorepo, go.work)
+	if components != nil {
+		logInfo("Grouping packages into %d components...", len(components))
+		analysis.Components = metrics.ComputeComponents(components, result.PackageDirs(), result.ImportGraph(), analysis.CallGraph)
+	}
+
+	// Inventario dei package main (opt-in via --binaries, o --shutdown)
+	if cfg.binaries || cfg.shutdown {
+		logInfo("Inventorying main packages...")
+		analysis.Binaries = binaries.Inventory(result)
+		if cfg.shutdown {
+			analysis.Issues = append(analysis.Issues, binaries.Shutdown(result, analysis.Binaries)...)
+		}
+	}
+	stopPost()
+
+	// Riassunti in linguaggio naturale (summarizer esterno, sull'analisi già calcolata)
+	if s := newSummarizer(cfg); s != nil && analysis.SymbolTable != nil {
+		logInfo("Generating %s summaries...", cfg.summaryScope)
+		stop := timings.start("nl_summaries")
+		issues, err := summarize.Annotate(context.Background(), s, analysis.SymbolTable, analysis.CallGraph, summarize.Config{
+			Scope:   cfg.summaryScope,
+			Root:    analysis.Metadata.ProjectPath,
+			FS:      result.FS,
+			Cache:   cfg.summaryCache,
+			Workers: cfg.sumWorkers,
+		})
+		stop()
+		analysis.Issues = append(analysis.Issues, issues...)
+		if err != nil {
+			logWarning("summaries: %v", err)
+		}
+	}
+
+	// Wrapper collassati nei chiamanti: dopo le fasi che usano il grafo completo
+	if cfg.cgWrappers && analysis.CallGraph != nil {
+		wrappers := symbols.Wrappers(result.Packages)
+		analysis.CallGraph = callgraph.CollapseWrappers(analysis.CallGraph, wrappers, cfg.cgCallSites, cfg.cgReach)
+		logInfo("Collapsed %d wrappers in the call graph", analysis.CallGraph.CollapsedWrappers)
+	}
+
+	// Call graph per package: dopo le fasi che usano i nodi funzione
+	if cfg.cgGranularity == callgraph.GranularityPkg && analysis.CallGraph != nil {
+		analysis.CallGraph = callgraph.CollapseToPackages(analysis.CallGraph, cfg.cgReach)
+		logInfo("Package call graph: %d nodes, %d edges", len(analysis.CallGraph.Nodes), len(analysis.CallGraph.Edges))
+	}
+
+	// Calcola durata, tempi per fase e riepilogo issue
 	analysis.Metadata.AnalysisDurationMs = time.Since(startTime).Milliseconds()
+	analysis.Metadata.PhaseTimingsMs = timings
+	analysis.Metadata.Resources = resourceUsage()
+	diag.Summarize(&analysis.Metadata, analysis.Issues)
+	duration := analysis.Metadata.AnalysisDurationMs
 
-	// Scrivi output
-	logVerbose(cfg, "Writing output...")
+	// Output riproducibile: dopo il riepilogo, che non dipende dall'ordine
+	if cfg.deterministic {
+		tc, err := reproducible.GoEnv(result.Root, loaderOpts.Env)
+		if err != nil {
+			logWarning("toolchain directories unavailable, external paths stay relative to the project root: %v", err)
+		}
+		reproducible.Normalize(analysis, result.Root, tc)
+	}
+
+	// Scrivi output: la serializzazione avviene in streaming, la sua durata
+	// è nel log e in --timings-file
+	logInfo("Writing output...")
+	progress.Phase("Writing output")
+	writeStart := time.Now()
 	outCfg := output.Config{
 		OutputDir: cfg.outputDir,
 		Format:    output.Format(cfg.format),
 		Indent:    true,
+		Neo4jMode: cfg.neo4jMode,
+		Corpus:    cfg.factsCorpus,
+		Sources:   result.FS,
+
+		Deterministic: cfg.deterministic,
 	}
+	outCfg.Compress, _ = output.ParseCompression(cfg.compress)
 
 	// Output compatto per LLM
-	if cfg.compact {
-		logVerbose(cfg, "Using compact output format for LLM")
-		compactOutput := schema.ToCompact(analysis)
+	if output.Format(cfg.format) == output.FormatTreemap {
+		if err := output.WriteTreemap(treemap.Build(analysis, cfg.treemapSize, result.FS), outCfg); err != nil {
+			return &exitError{exitOutput, fmt.Errorf("write treemap: %w", err)}
+		}
+	} else if output.Format(cfg.format) == output.FormatOpenAPI {
+		if err := output.WriteOpenAPI(openapi.Document(analysis, result.FS), outCfg); err != nil {
+			return &exitError{exitOutput, fmt.Errorf("write openapi: %w", err)}
+		}
+	} else if output.Format(cfg.format) == output.FormatCallHierarchy {
+		if err := output.WriteCallHierarchy(callhierarchy.Build(analysis, result.FS), outCfg); err != nil {
+			return &exitError{exitOutput, fmt.Errorf("write call hierarchy: %w", err)}
+		}
+	} else if cfg.compact {
+		logInfo("Using compact output format for LLM")
+		compactOutput := schema.ToCompactWith(analysis, schema.CompactOptions{SkipBoilerplate: cfg.skipBoiler})
 		if err := output.WriteCompact(compactOutput, outCfg); err != nil {
-			return fmt.Errorf("write compact output: %w", err)
+			return &exitError{exitOutput, fmt.Errorf("write compact output: %w", err)}
 		}
 	} else {
 		if err := output.Write(analysis, outCfg); err != nil {
-			return fmt.Errorf("write output: %w", err)
+			return &exitError{exitOutput, fmt.Errorf("write output: %w", err)}
 		}
 	}
 
-	logVerbose(cfg, "Analysis completed in %dms", analysis.Metadata.AnalysisDurationMs)
+	timings["serialize"] = time.Since(writeStart).Milliseconds()
+	logInfo("Output written in %dms", timings["serialize"])
+	logInfo("Analysis completed in %dms", duration)
+	if cfg.timingsFile != "" {
+		if err := writeTimings(cfg.timingsFile, time.Since(startTime).Milliseconds(), timings); err != nil {
+			return &exitError{exitOutput, fmt.Errorf("write timings: %w", err)}
+		}
+	}
 
+	// Una fase richiesta e non costruita fallisce il processo, anche senza
+	// --fail-on: l'output (parziale) è già scritto
+	if len(failed) > 0 {
+		return &exitError{exitAnalysis, fmt.Errorf("analysis failed: %s (see the *_ERROR issues)", strings.Join(failed, ", "))}
+	}
+	return checkFailOn(analysis.Issues, cfg.failOn)
+}
+
+// newSummarizer restituisce il summarizer configurato da --summarizer-cmd o
+// --summarizer-url, nil se nessuno dei due è impostato.
+func newSummarizer(cfg config) summarize.Summarizer {
+	if argv := strings.Fields(cfg.summarizerCmd); len(argv) > 0 {
+		return &summarize.Command{Argv: argv}
+	}
+	if cfg.summarizerURL != "" {
+		return &summarize.HTTP{URL: cfg.summarizerURL}
+	}
 	return nil
 }
 
@@ -492,16 +1384,50 @@ func splitCSV(s string) []string {
 	return out
 }
 
-func logVerbose(cfg config, format string, args ...interface{}) {
-	if cfg.verbose && !cfg.quiet {
-		fmt.Fprintf(os.Stderr, "[info] "+format+"\n", args...)
+// setupLogging configura il logger di default (slog) secondo i flag.
+// Il livello predefinito è warn; --verbose lo abbassa a info, --quiet lo
+// alza a error, --log-level ha la precedenza su entrambi.
+func setupLogging(cfg config) error {
+	level := slog.LevelWarn
+	if cfg.verbose {
+		level = slog.LevelInfo
 	}
+	if cfg.quiet {
+		level = slog.LevelError
+	}
+	if cfg.logLevel != "" {
+		l, err := logging.ParseLevel(cfg.logLevel)
+		if err != nil {
+			return err
+		}
+		level = l
+	}
+
+	logger, err := logging.New(os.Stderr, level, cfg.logFormat)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+func logInfo(format string, args ...interface{}) {
+	slog.Info(fmt.Sprintf(format, args...))
 }
 
 func logWarning(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "[warning] "+format+"\n", args...)
+	slog.Warn(fmt.Sprintf(format, args...))
 }
 
 func logError(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "[error] "+format+"\n", args...)
+	slog.Error(fmt.Sprintf(format, args...))
+}
+
+// platformNames restituisce i nomi goos/goarch[+tag...] delle piattaforme.
+func platformNames(platforms []buildmatrix.Platform) []string {
+	var out []string
+	for _, p := range platforms {
+		out = append(out, p.String())
+	}
+	return out
 }
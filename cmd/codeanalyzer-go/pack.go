@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pack"
+)
+
+// runPack implementa "codeanalyzer-go pack --focus sym [--budget N]":
+// stampa il simbolo, il suo intorno nel call graph e i tipi collegati come
+// un unico documento ordinato per rilevanza entro il budget di token.
+func runPack(args []string) int {
+	var qc queryConfig
+	cfg := pack.Config{Budget: pack.DefaultBudget, Depth: pack.DefaultDepth}
+	fs := flag.NewFlagSet("pack", flag.ContinueOnError)
+	registerQueryFlags(fs, &qc)
+	fs.StringVar(&cfg.Focus, "focus", "", "Focus symbol: function, method or type ID, or a unique suffix (e.g. pkg/foo.Bar, (*Server).Start)")
+	fs.IntVar(&cfg.Budget, "budget", cfg.Budget, "Token budget of the document (estimated as 4 bytes per token)")
+	fs.IntVar(&cfg.Depth, "depth", cfg.Depth, "Maximum call graph distance of callers and callees")
+	noSource := fs.Bool("no-source", false, "Emit signatures and docs only, without declaration source")
+	asJSON := fs.Bool("json", false, "Print the pack as JSON instead of a text document")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: codeanalyzer-go pack --focus symbol [flags]")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	if cfg.Focus == "" || fs.NArg() > 0 {
+		logError("pack requires --focus")
+		return exitUsage
+	}
+	if cfg.Budget <= 0 || cfg.Depth < 0 {
+		logError("--budget must be positive and --depth non-negative")
+		return exitUsage
+	}
+
+	qc.symbols = true
+	analysis, sources, err := loadQuery(qc)
+	if err != nil {
+		logError("%v", err)
+		return exitCode(err)
+	}
+	if !*noSource {
+		cfg.Root, cfg.FS = analysis.Metadata.ProjectPath, sources
+	}
+	p, err := pack.Build(analysis.SymbolTable, analysis.CallGraph, cfg)
+	if err != nil {
+		logError("%v", err)
+		return exitUsage
+	}
+	if *asJSON {
+		return emitQuery(p)
+	}
+	fmt.Print(pack.Text(p))
+	return 0
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// profiles sono i preset di --profile: valori di flag applicati solo ai flag
+// non indicati esplicitamente, in ordine di costo crescente.
+var profiles = map[string]map[string]string{
+	// Solo dichiarazioni: niente corpi né SSA
+	"fast": {
+		"analysis-level": levelSymbolTable,
+		"include-body":   "false",
+		"emit-positions": "minimal",
+	},
+	// Simboli tipati e call graph CHA (economico, sovra-approssimato)
+	"standard": {
+		"analysis-level": levelCallGraph,
+		"cg":             "cha",
+	},
+	// Tutte le fasi, call graph VTA, corpi con call site e reach
+	"deep": {
+		"analysis-level": levelFull,
+		"cg":             "vta",
+		"include-body":   "true",
+		"cg-reach":       "true",
+	},
+}
+
+// flagAliases collega i flag ai loro shorthand, che condividono la variabile.
+var flagAliases = map[string]string{
+	"analysis-level": "a",
+}
+
+// applyProfile imposta su fs i valori del profilo indicato per i flag che
+// l'utente non ha passato.
+func applyProfile(fs *flag.FlagSet, profile string) error {
+	preset, ok := profiles[profile]
+	if !ok {
+		return fmt.Errorf("invalid profile: %s (valid: %s)", profile, strings.Join(profileNames(), ", "))
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	names := make([]string, 0, len(preset))
+	for name := range preset {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if explicit[name] || explicit[flagAliases[name]] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, preset[name]); err != nil {
+			return fmt.Errorf("profile %s: %s: %w", profile, name, err)
+		}
+	}
+	return nil
+}
+
+// profileNames restituisce i nomi dei profili in ordine alfabetico.
+func profileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
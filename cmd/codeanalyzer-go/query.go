@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/callgraph"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/graph"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/output"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/symbols"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// queryConfig contiene i flag comuni ai sottocomandi "query".
+type queryConfig struct {
+	input        string
+	graphFile    string
+	cgAlgo       string
+	includeTests bool
+	excludeDirs  string
+	onlyPkg      string
+	matchPkg     string
+	excludePkg   string
+	overlay      string
+	symbols      bool // estrae anche la symbol table (pack)
+}
+
+// registerQueryFlags registra i flag comuni a "query" e "serve".
+func registerQueryFlags(fs *flag.FlagSet, qc *queryConfig) {
+	fs.StringVar(&qc.input, "input", ".", "Path to the root of the Go project to analyze")
+	fs.StringVar(&qc.input, "i", ".", "Path to the root of the Go project (shorthand)")
+	fs.StringVar(&qc.graphFile, "graph", "", "Previously saved analysis.json (or its graph load cache) to query instead of building the call graph")
+	fs.StringVar(&qc.cgAlgo, "cg", "rta", "Call graph algorithm: cha|rta|vta|static-approx")
+	fs.BoolVar(&qc.includeTests, "include-tests", false, "Include *_test.go files in analysis")
+	fs.StringVar(&qc.excludeDirs, "exclude-dirs", "", "Comma-separated directory basenames to exclude")
+	registerPkgFilterFlags(fs, qc)
+	fs.StringVar(&qc.overlay, "overlay", "", "JSON overlay in 'go build -overlay' format replacing file contents (- = stdin)")
+}
+
+// registerPkgFilterFlags registra i filtri sui package condivisi da
+// "query", "serve" e "search".
+func registerPkgFilterFlags(fs *flag.FlagSet, qc *queryConfig) {
+	fs.StringVar(&qc.onlyPkg, "only-pkg", "", "Comma-separated package path filters (substring match)")
+	fs.StringVar(&qc.matchPkg, "match-pkg", "", "Comma-separated import path patterns to include: globs (* within a path element, ** or ... across) or regexps prefixed with re:")
+	fs.StringVar(&qc.excludePkg, "exclude-pkg", "", "Comma-separated import path patterns to exclude (same syntax as --match-pkg)")
+}
+
+// packageFilter costruisce il filtro sui package dai flag.
+func (qc queryConfig) packageFilter() (*pkgfilter.Filter, error) {
+	return pkgfilter.New(splitCSV(qc.onlyPkg), splitCSV(qc.matchPkg), splitCSV(qc.excludePkg))
+}
+
+// runQuery implementa "codeanalyzer-go query <path|dominators> [flags]".
+// Restituisce l'exit code del processo.
+func runQuery(args []string) int {
+	if len(args) == 0 || isHelp(args[0]) {
+		fmt.Fprintln(os.Stderr, "Usage: codeanalyzer-go query <path|dominators> [flags]")
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	var qc queryConfig
+	fs := flag.NewFlagSet("query "+args[0], flag.ContinueOnError)
+	registerQueryFlags(fs, &qc)
+
+	switch args[0] {
+	case "path":
+		from := fs.String("from", "", "Source function (qualified name or unique suffix)")
+		to := fs.String("to", "", "Target function (qualified name or unique suffix)")
+		k := fs.Int("k", 5, "Maximum number of paths to return")
+		maxDepth := fs.Int("max-depth", 20, "Maximum path length in edges")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if *from == "" || *to == "" {
+			logError("query path requires --from and --to")
+			return 2
+		}
+		idx, err := loadQueryGraph(qc)
+		if err != nil {
+			logError("%v", err)
+			return exitCode(err)
+		}
+		res, err := pathQuery(idx, *from, *to, *k, *maxDepth)
+		if err != nil {
+			logError("%v", err)
+			return 2
+		}
+		return emitQuery(res)
+
+	case "dominators":
+		rootsFlag := fs.String("root", "", "Comma-separated root functions (default: all main/init)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		idx, err := loadQueryGraph(qc)
+		if err != nil {
+			logError("%v", err)
+			return exitCode(err)
+		}
+		res, err := dominatorQuery(idx, splitCSV(*rootsFlag))
+		if err != nil {
+			logError("%v", err)
+			return 2
+		}
+		return emitQuery(res)
+
+	default:
+		logError("unknown query %q (valid: path, dominators)", args[0])
+		return 2
+	}
+}
+
+// pathQuery risolve from/to e calcola fino a k cammini.
+func pathQuery(idx *graph.Index, from, to string, k, maxDepth int) (*schema.CLDKPathQuery, error) {
+	src, err := idx.Resolve(from)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := idx.Resolve(to)
+	if err != nil {
+		return nil, err
+	}
+	return &schema.CLDKPathQuery{
+		From:     src,
+		To:       dst,
+		MaxPaths: k,
+		Paths:    idx.Paths(src, dst, k, maxDepth),
+	}, nil
+}
+
+// dominatorQuery risolve le radici (default: entry point main/init) e
+// calcola il dominator tree.
+func dominatorQuery(idx *graph.Index, rootNames []string) (*schema.CLDKDominatorTree, error) {
+	var roots []string
+	for _, r := range rootNames {
+		id, err := idx.Resolve(r)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, id)
+	}
+	if len(roots) == 0 {
+		roots = idx.EntryPoints()
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no main/init entry points found, use --root")
+	}
+	return &schema.CLDKDominatorTree{
+		Roots: roots,
+		Idom:  idx.Dominators(roots),
+	}, nil
+}
+
+// loadQueryGraph carica il call graph da un'analisi salvata o lo costruisce.
+func loadQueryGraph(qc queryConfig) (*graph.Index, error) {
+	analysis, err := loadQueryAnalysis(qc)
+	if err != nil {
+		return nil, err
+	}
+	return graph.NewIndex(analysis.CallGraph), nil
+}
+
+// loadQueryAnalysis legge un'analisi salvata (--graph) oppure ne costruisce
+// una con il solo call graph, più la symbol table se qc.symbols.
+// L'analisi restituita ha sempre CallGraph (e SymbolTable se richiesta).
+func loadQueryAnalysis(qc queryConfig) (*schema.CLDKAnalysis, error) {
+	analysis, _, err := loadQuery(qc)
+	return analysis, err
+}
+
+// loadQuery è loadQueryAnalysis che restituisce anche i sorgenti visti dal
+// loader (LoadResult.FS, overlay compreso), con radice in
+// Metadata.ProjectPath; nil per un'analisi salvata, i cui sorgenti si
+// leggono dal disco.
+func loadQuery(qc queryConfig) (*schema.CLDKAnalysis, fs.FS, error) {
+	if qc.graphFile != "" {
+		analysis, err := output.ReadFile(qc.graphFile)
+		if err != nil {
+			return nil, nil, &exitError{exitLoad, err}
+		}
+		if analysis.CallGraph == nil {
+			return nil, nil, fmt.Errorf("%s does not contain a call graph", qc.graphFile)
+		}
+		if qc.symbols && analysis.SymbolTable == nil {
+			return nil, nil, fmt.Errorf("%s does not contain a symbol table", qc.graphFile)
+		}
+		return analysis, nil, nil
+	}
+
+	absInput, err := filepath.Abs(qc.input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid input path: %w", err)
+	}
+	filter, err := qc.packageFilter()
+	if err != nil {
+		return nil, nil, &exitError{exitUsage, err}
+	}
+	opts := loader.Options{
+		IncludeTest: qc.includeTests,
+		ExcludeDirs: splitCSV(qc.excludeDirs),
+		Packages:    filter,
+		NeedSyntax:  true,
+		NeedTypes:   true,
+		NeedSSA:     !strings.EqualFold(qc.cgAlgo, callgraph.AlgorithmStaticApprox),
+	}
+	if qc.overlay != "" {
+		if opts.Overlay, err = loader.ReadOverlay(qc.overlay); err != nil {
+			return nil, nil, &exitError{exitLoad, err}
+		}
+	}
+	start := time.Now()
+	result, err := loader.Load(absInput, opts)
+	if err != nil {
+		return nil, nil, &exitError{exitLoad, fmt.Errorf("load packages: %w", err)}
+	}
+	cg, err := callgraph.Build(result, callgraph.Config{
+		Algorithm:     strings.ToLower(qc.cgAlgo),
+		EmitPositions: "minimal",
+		Packages:      filter,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("build call graph: %w", err)
+	}
+	level := levelCallGraph
+	var st *schema.CLDKSymbolTable
+	if qc.symbols {
+		level = levelFull
+		st = symbols.Extract(result, symbols.ExtractConfig{EmitPositions: "detailed"})
+	}
+	return &schema.CLDKAnalysis{
+		Metadata: schema.Metadata{
+			Analyzer:      "codeanalyzer-go",
+			Version:       version,
+			SchemaVersion: schema.SchemaVersion,
+			Language:      "go",
+			AnalysisLevel: level,
+			Timestamp:     start.UTC().Format(time.RFC3339),
+			ProjectPath:   absInput,
+			GoVersion:     runtime.Version(),
+
+			AnalysisDurationMs: time.Since(start).Milliseconds(),
+		},
+		SymbolTable: st,
+		CallGraph:   cg,
+		Issues:      []schema.Issue{},
+	}, result.FS, nil
+}
+
+// emitQuery scrive il risultato di una query in JSON su stdout.
+func emitQuery(v interface{}) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		logError("encode json: %v", err)
+		return exitOutput
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/graph"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/output"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// runRdeps implementa "codeanalyzer-go rdeps --pkg path [flags]": elenca i
+// package del progetto che importano, anche transitivamente, i package
+// indicati. Usa solo il grafo degli import, senza SSA.
+func runRdeps(args []string) int {
+	var qc queryConfig
+	fs := flag.NewFlagSet("rdeps", flag.ContinueOnError)
+	fs.StringVar(&qc.input, "input", ".", "Path to the root of the Go project to analyze")
+	fs.StringVar(&qc.input, "i", ".", "Path to the root of the Go project (shorthand)")
+	fs.StringVar(&qc.graphFile, "symbols", "", "Previously saved analysis.json whose symbol table provides the import graph")
+	fs.BoolVar(&qc.includeTests, "include-tests", false, "Include *_test.go files in analysis")
+	fs.StringVar(&qc.excludeDirs, "exclude-dirs", "", "Comma-separated directory basenames to exclude")
+	registerPkgFilterFlags(fs, &qc)
+	fs.StringVar(&qc.overlay, "overlay", "", "JSON overlay in 'go build -overlay' format replacing file contents (- = stdin)")
+	pkgs := fs.String("pkg", "", "Comma-separated import paths whose dependents are listed (required)")
+	asJSON := fs.Bool("json", false, "Print the dependents with their distance as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: codeanalyzer-go rdeps --pkg path [flags]")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	seeds := splitCSV(*pkgs)
+	if fs.NArg() > 0 || len(seeds) == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	adj, err := loadImportGraph(qc)
+	if err != nil {
+		logError("%v", err)
+		return exitCode(err)
+	}
+	res := reverseDeps(adj, seeds)
+
+	if *asJSON {
+		return emitQuery(res)
+	}
+	for _, d := range res.Dependents {
+		fmt.Println(d.Package)
+	}
+	return 0
+}
+
+// reverseDeps calcola la chiusura inversa dei seeds su adj, senza i seeds
+// stessi, ordinata per distanza e poi per package path. Le varianti di test
+// (--include-tests) confluiscono nel package della loro directory, quindi
+// il package di test esterno di un seed non lo riporta tra i dipendenti.
+func reverseDeps(adj map[string][]string, seeds []string) *schema.CLDKReverseDeps {
+	res := &schema.CLDKReverseDeps{Packages: seeds, Dependents: []schema.CLDKDependentPackage{}}
+	dist := make(map[string]int)
+	for p, d := range graph.ReverseClosure(adj, seeds) {
+		base, ok := basePkgPath(p)
+		if !ok {
+			continue
+		}
+		if old, seen := dist[base]; !seen || d < old {
+			dist[base] = d
+		}
+	}
+	for pkg, d := range dist {
+		if d == 0 {
+			continue // un seed
+		}
+		res.Dependents = append(res.Dependents, schema.CLDKDependentPackage{Package: pkg, Distance: d})
+	}
+	sort.Slice(res.Dependents, func(i, j int) bool {
+		a, b := res.Dependents[i], res.Dependents[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return a.Package < b.Package
+	})
+	return res
+}
+
+// loadImportGraph legge il grafo degli import dalla symbol table di
+// un'analisi salvata (--symbols) oppure carica del progetto solo nomi e
+// import, senza parsing né type checking.
+func loadImportGraph(qc queryConfig) (map[string][]string, error) {
+	if qc.graphFile != "" {
+		analysis, err := output.ReadFile(qc.graphFile)
+		if err != nil {
+			return nil, &exitError{exitLoad, err}
+		}
+		if analysis.SymbolTable == nil {
+			return nil, fmt.Errorf("%s does not contain a symbol table", qc.graphFile)
+		}
+		return graph.ImportGraph(analysis.SymbolTable), nil
+	}
+
+	absInput, err := filepath.Abs(qc.input)
+	if err != nil {
+		return nil, &exitError{exitUsage, fmt.Errorf("invalid input path: %w", err)}
+	}
+	filter, err := qc.packageFilter()
+	if err != nil {
+		return nil, &exitError{exitUsage, err}
+	}
+	opts := loader.Options{
+		IncludeTest: qc.includeTests,
+		ExcludeDirs: splitCSV(qc.excludeDirs),
+		Packages:    filter,
+	}
+	if qc.overlay != "" {
+		if opts.Overlay, err = loader.ReadOverlay(qc.overlay); err != nil {
+			return nil, &exitError{exitLoad, err}
+		}
+	}
+	result, err := loader.Load(absInput, opts)
+	if err != nil {
+		return nil, &exitError{exitLoad, fmt.Errorf("load packages: %w", err)}
+	}
+	return result.ImportGraph(), nil
+}
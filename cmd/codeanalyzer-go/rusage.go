@@ -0,0 +1,20 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// resourceUsage rileva le risorse usate finora dal processo.
+func resourceUsage() *schema.ResourceUsage {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return &schema.ResourceUsage{
+		GoMaxProcs:      runtime.GOMAXPROCS(0),
+		NumCPU:          runtime.NumCPU(),
+		PeakRSSBytes:    peakRSS(),
+		TotalAllocBytes: ms.TotalAlloc,
+		GCCycles:        ms.NumGC,
+	}
+}
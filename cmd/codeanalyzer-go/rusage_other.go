@@ -0,0 +1,6 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+// peakRSS non è disponibile su questo sistema.
+func peakRSS() int64 { return 0 }
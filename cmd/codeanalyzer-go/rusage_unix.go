@@ -0,0 +1,20 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package main
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// peakRSS restituisce il picco di memoria residente del processo in byte.
+func peakRSS() int64 {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0
+	}
+	if runtime.GOOS == "darwin" {
+		return int64(ru.Maxrss) // già in byte
+	}
+	return int64(ru.Maxrss) << 10 // KiB
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// runSchema implementa "codeanalyzer-go schema": stampa su stdout il JSON
+// Schema di CLDKAnalysis per la versione corrente del formato, o con
+// --frames quello del protocollo a frame.
+func runSchema(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	frames := fs.Bool("frames", false, "Print the schema of the NDJSON frame protocol (--format ndjson-frames, bridge) instead")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: codeanalyzer-go schema [--frames]\n\nPrint the JSON Schema (draft 2020-12) of the analysis output, version %s.\n\n", schema.SchemaVersion)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if *frames {
+		return emitQuery(schema.FramesJSONSchema())
+	}
+	return emitQuery(schema.JSONSchema())
+}
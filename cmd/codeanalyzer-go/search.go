@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/output"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/search"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/symbols"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// runSearch implementa "codeanalyzer-go search [flags]".
+// Exit code: 0 almeno un risultato, 1 nessun risultato (come grep),
+// 2 errore di uso, 3 errore di caricamento.
+func runSearch(args []string) int {
+	var qc queryConfig
+	var q search.Query
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.StringVar(&qc.input, "input", ".", "Path to the root of the Go project to analyze")
+	fs.StringVar(&qc.input, "i", ".", "Path to the root of the Go project (shorthand)")
+	fs.StringVar(&qc.graphFile, "symbols", "", "Previously saved analysis.json to search instead of extracting symbols")
+	fs.BoolVar(&qc.includeTests, "include-tests", false, "Include *_test.go files in analysis")
+	fs.StringVar(&qc.excludeDirs, "exclude-dirs", "", "Comma-separated directory basenames to exclude")
+	registerPkgFilterFlags(fs, &qc)
+	fs.StringVar(&qc.overlay, "overlay", "", "JSON overlay in 'go build -overlay' format replacing file contents (- = stdin)")
+	fs.StringVar(&q.Kind, "kind", "", "Symbol kind: func|method|type|struct|interface|alias|named|var|const")
+	fs.StringVar(&q.Name, "name", "", "Exact symbol name, or a regular expression when prefixed with ~ (e.g. '~Handler$')")
+	fs.BoolVar(&q.Exported, "exported", false, "Only exported symbols")
+	fs.StringVar(&q.Receiver, "receiver", "", "Only methods on this receiver type (T or *T, without package)")
+	fs.StringVar(&q.Signature, "signature", "", "Substring of the signature (type for variables and constants)")
+	asJSON := fs.Bool("json", false, "Print matches as a JSON array")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: codeanalyzer-go search [flags]")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	st, err := loadSearchSymbols(qc)
+	if err != nil {
+		logError("%v", err)
+		return exitCode(err)
+	}
+	matches, err := search.Run(st, q)
+	if err != nil {
+		logError("%v", err)
+		return exitUsage
+	}
+
+	if *asJSON {
+		if rc := emitQuery(matches); rc != 0 {
+			return rc
+		}
+	} else {
+		for _, m := range matches {
+			fmt.Println(formatMatch(m))
+		}
+	}
+	if len(matches) == 0 {
+		return 1
+	}
+	return 0
+}
+
+// formatMatch restituisce "file:riga:colonna: kind qualified_name [firma]".
+func formatMatch(m schema.CLDKSymbolMatch) string {
+	loc := m.Package
+	if p := m.Position; p != nil {
+		loc = fmt.Sprintf("%s:%d:%d", p.File, p.StartLine, p.StartColumn)
+	}
+	s := fmt.Sprintf("%s: %s %s", loc, m.Kind, m.QualifiedName)
+	if m.Signature != "" {
+		s += " " + m.Signature
+	}
+	return s
+}
+
+// loadSearchSymbols legge la symbol table da un'analisi salvata (--symbols)
+// oppure la estrae dal progetto senza costruire SSA.
+func loadSearchSymbols(qc queryConfig) (*schema.CLDKSymbolTable, error) {
+	if qc.graphFile != "" {
+		analysis, err := output.ReadFile(qc.graphFile)
+		if err != nil {
+			return nil, &exitError{exitLoad, err}
+		}
+		if analysis.SymbolTable == nil {
+			return nil, fmt.Errorf("%s does not contain a symbol table", qc.graphFile)
+		}
+		return analysis.SymbolTable, nil
+	}
+
+	absInput, err := filepath.Abs(qc.input)
+	if err != nil {
+		return nil, &exitError{exitUsage, fmt.Errorf("invalid input path: %w", err)}
+	}
+	filter, err := qc.packageFilter()
+	if err != nil {
+		return nil, &exitError{exitUsage, err}
+	}
+	opts := loader.Options{
+		IncludeTest: qc.includeTests,
+		ExcludeDirs: splitCSV(qc.excludeDirs),
+		Packages:    filter,
+		NeedSyntax:  true,
+		NeedTypes:   true,
+	}
+	if qc.overlay != "" {
+		if opts.Overlay, err = loader.ReadOverlay(qc.overlay); err != nil {
+			return nil, &exitError{exitLoad, err}
+		}
+	}
+	result, err := loader.Load(absInput, opts)
+	if err != nil {
+		return nil, &exitError{exitLoad, fmt.Errorf("load packages: %w", err)}
+	}
+	return symbols.Extract(result, symbols.ExtractConfig{EmitPositions: "detailed"}), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/graph"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// runServe implementa "codeanalyzer-go serve": carica (o costruisce) un
+// call graph una sola volta e risponde alle query via HTTP in JSON.
+//
+//	GET /analysis                              analisi caricata
+//	GET /schema                                JSON Schema dell'output
+//	GET /query/path?from=&to=[&k=&max_depth=]  come "query path"
+//	GET /query/dominators[?root=a,b]           come "query dominators"
+func runServe(args []string) int {
+	var qc queryConfig
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	registerQueryFlags(fs, &qc)
+	addr := fs.String("addr", "127.0.0.1:8080", "Address to listen on")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	analysis, err := loadQueryAnalysis(qc)
+	if err != nil {
+		logError("%v", err)
+		return exitCode(err)
+	}
+	idx := graph.NewIndex(analysis.CallGraph)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /analysis", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, analysis)
+	})
+	mux.HandleFunc("GET /schema", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, schema.JSONSchema())
+	})
+	mux.HandleFunc("GET /query/path", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		k, err1 := intParam(q.Get("k"), 5)
+		maxDepth, err2 := intParam(q.Get("max_depth"), 20)
+		if err1 != nil || err2 != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("k and max_depth must be integers"))
+			return
+		}
+		if q.Get("from") == "" || q.Get("to") == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("from and to are required"))
+			return
+		}
+		res, err := pathQuery(idx, q.Get("from"), q.Get("to"), k, maxDepth)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	})
+	mux.HandleFunc("GET /query/dominators", func(w http.ResponseWriter, r *http.Request) {
+		res, err := dominatorQuery(idx, splitCSV(r.URL.Query().Get("root")))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	})
+
+	fmt.Fprintf(os.Stderr, "codeanalyzer-go serving %d call graph nodes on http://%s\n", len(analysis.CallGraph.Nodes), *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		logError("serve: %v", err)
+		return 1
+	}
+	return 0
+}
+
+func intParam(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		logWarning("serve: encode response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
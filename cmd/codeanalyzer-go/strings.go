@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	gostrings "github.com/codellm-devkit/codeanalyzer-go/internal/strings"
+)
+
+// runStrings implementa "codeanalyzer-go strings [flags]": inventario delle
+// string literal con funzione contenitrice, messaggi per l'utente, format
+// string printf-style, chiavi i18n e valori duplicati.
+func runStrings(args []string) int {
+	var qc queryConfig
+	var cfg gostrings.InventoryConfig
+	fs := flag.NewFlagSet("strings", flag.ContinueOnError)
+	fs.StringVar(&qc.input, "input", ".", "Path to the root of the Go project to analyze")
+	fs.StringVar(&qc.input, "i", ".", "Path to the root of the Go project (shorthand)")
+	fs.BoolVar(&qc.includeTests, "include-tests", false, "Include *_test.go files in analysis")
+	fs.StringVar(&qc.excludeDirs, "exclude-dirs", "", "Comma-separated directory basenames to exclude")
+	registerPkgFilterFlags(fs, &qc)
+	fs.StringVar(&qc.overlay, "overlay", "", "JSON overlay in 'go build -overlay' format replacing file contents (- = stdin)")
+	fs.IntVar(&cfg.MinLength, "min-length", gostrings.DefaultInventoryMinLength, "Minimum string length in bytes (i18n keys are always listed)")
+	fs.StringVar(&cfg.Only, "only", "", "Only strings of this kind: message|format|i18n_key")
+	asJSON := fs.Bool("json", false, "Print the inventory with kind counts and duplicates as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: codeanalyzer-go strings [flags]")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	switch cfg.Only {
+	case "", gostrings.KindMessage, gostrings.KindFormat, gostrings.KindI18nKey:
+	default:
+		logError("invalid --only %q (valid: message, format, i18n_key)", cfg.Only)
+		return exitUsage
+	}
+
+	absInput, err := filepath.Abs(qc.input)
+	if err != nil {
+		logError("invalid input path: %v", err)
+		return exitUsage
+	}
+	filter, err := qc.packageFilter()
+	if err != nil {
+		logError("%v", err)
+		return exitUsage
+	}
+	opts := loader.Options{
+		NeedSyntax:  true,
+		NeedTypes:   true,
+		IncludeTest: qc.includeTests,
+		ExcludeDirs: splitCSV(qc.excludeDirs),
+		Packages:    filter,
+	}
+	if qc.overlay != "" {
+		if opts.Overlay, err = loader.ReadOverlay(qc.overlay); err != nil {
+			logError("%v", err)
+			return exitLoad
+		}
+	}
+	result, err := loader.Load(absInput, opts)
+	if err != nil {
+		logError("load packages: %v", err)
+		return exitLoad
+	}
+
+	inv := gostrings.Inventory(result.Packages, result.Fset, result.Root, cfg)
+	if *asJSON {
+		return emitQuery(inv)
+	}
+	for _, s := range inv.Strings {
+		kind := s.Kind
+		if kind == "" {
+			kind = "-"
+		}
+		fmt.Printf("%s:%d:%d: %s %s\n", s.Position.File, s.Position.StartLine, s.Position.StartColumn, kind, strconv.Quote(s.Value))
+	}
+	return 0
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// phaseTimings accumula la durata delle fasi di analisi in millisecondi.
+type phaseTimings map[string]int64
+
+// start avvia la misura della fase name; la funzione restituita la conclude.
+func (t phaseTimings) start(name string) func() {
+	begin := time.Now()
+	return func() {
+		t[name] += time.Since(begin).Milliseconds()
+	}
+}
+
+// writeTimings scrive in name la durata totale, i tempi per fase (compresa
+// la serializzazione) e le risorse del processo, per --timings-file.
+func writeTimings(name string, durationMs int64, t phaseTimings) error {
+	data, err := json.MarshalIndent(schema.CLDKTimings{
+		DurationMs:     durationMs,
+		PhaseTimingsMs: t,
+		Resources:      resourceUsage(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0o644)
+}
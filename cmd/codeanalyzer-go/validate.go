@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/validate"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// runValidate implementa "codeanalyzer-go validate analysis.json".
+// Exit code: 0 file valido, 1 problemi trovati, 2 errore di uso o di lettura.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	maxErrors := fs.Int("max-errors", 50, "Maximum number of problems to print (0 = all)")
+	asJSON := fs.Bool("json", false, "Print problems as a JSON array")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: codeanalyzer-go validate [flags] analysis.json\n\nCheck an analysis file against schema %s and verify referential integrity.\n\n", schema.SchemaVersion)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	path := fs.Arg(0)
+	problems, err := validate.File(path)
+	if err != nil {
+		logError("%v", err)
+		return 2
+	}
+
+	if *asJSON {
+		if problems == nil {
+			problems = []validate.Problem{}
+		}
+		if rc := emitQuery(problems); rc != 0 {
+			return rc
+		}
+	} else {
+		for i, p := range problems {
+			if *maxErrors > 0 && i == *maxErrors {
+				fmt.Printf("... %d more problems (use --max-errors 0 to show all)\n", len(problems)-i)
+				break
+			}
+			fmt.Println(p)
+		}
+	}
+
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%s: %d problem(s)\n", path, len(problems))
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%s: valid (schema %s)\n", path, schema.SchemaVersion)
+	return 0
+}
@@ -0,0 +1,14 @@
+package main
+
+import "github.com/codellm-devkit/codeanalyzer-go/internal/buildinfo"
+
+// version è la versione dell'analyzer, iniettata in fase di build con
+// -ldflags "-X main.version=<versione>" (vedi Makefile). Se assente si usa
+// la versione del modulo (go install ...@vX.Y.Z), altrimenti "dev".
+var version = ""
+
+func init() {
+	if version == "" {
+		version = buildinfo.Version()
+	}
+}
@@ -0,0 +1,103 @@
+//go:build js && wasm
+
+// Command codeanalyzer-wasm espone l'estrazione dei simboli (senza SSA) a
+// JavaScript, per playground che girano interamente nel browser:
+//
+//	GOOS=js GOARCH=wasm go build -o codeanalyzer.wasm ./cmd/codeanalyzer-wasm
+//
+// Dopo l'avvio registra in globalThis la funzione
+// analyzeSource(files, options), dove files mappa path relativi (go.mod
+// compreso, opzionale) sul sorgente. Restituisce l'analisi CLDK come
+// stringa JSON, oppure un Error se non c'è niente da analizzare.
+package main
+
+import (
+	"encoding/json"
+	"path"
+	"runtime"
+	"strings"
+	"syscall/js"
+	"testing/fstest"
+	"time"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/buildinfo"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/diag"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/symbols"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+func main() {
+	js.Global().Set("analyzeSource", js.FuncOf(analyzeSource))
+	select {} // resta in vita per le chiamate da JavaScript
+}
+
+// analyzeSource è il binding JavaScript: analyzeSource(files, options?)
+// con options.includeBody e options.includeTests booleani.
+func analyzeSource(_ js.Value, args []js.Value) any {
+	if len(args) == 0 || args[0].Type() != js.TypeObject {
+		return jsError("analyzeSource: expected an object mapping file paths to sources")
+	}
+	files := args[0]
+	fsys := fstest.MapFS{}
+	keys := js.Global().Get("Object").Call("keys", files)
+	for i := 0; i < keys.Length(); i++ {
+		key := keys.Index(i).String()
+		name := strings.TrimPrefix(path.Clean("/"+key), "/") // "./a.go", "/a.go" → "a.go"
+		fsys[name] = &fstest.MapFile{Data: []byte(files.Get(key).String())}
+	}
+
+	var includeBody, includeTests bool
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		includeBody = args[1].Get("includeBody").Truthy()
+		includeTests = args[1].Get("includeTests").Truthy()
+	}
+
+	out, err := analyze(fsys, includeBody, includeTests)
+	if err != nil {
+		return jsError(err.Error())
+	}
+	return out
+}
+
+// analyze produce l'analisi a livello symbol_table dei file in fsys.
+func analyze(fsys fstest.MapFS, includeBody, includeTests bool) (string, error) {
+	start := time.Now()
+	result, err := loader.LoadFS(fsys, "/", loader.Options{IncludeTest: includeTests, NeedSyntax: true, NeedTypes: true})
+	if err != nil {
+		return "", err
+	}
+
+	analysis := &schema.CLDKAnalysis{
+		Metadata: schema.Metadata{
+			Analyzer:      "codeanalyzer-go",
+			Version:       buildinfo.Version(),
+			SchemaVersion: schema.SchemaVersion,
+			Language:      "go",
+			AnalysisLevel: "symbol_table",
+			Timestamp:     start.UTC().Format(time.RFC3339),
+			GoVersion:     runtime.Version(),
+		},
+		Issues: diag.LoadIssues(result.Errors),
+	}
+
+	analysis.SymbolTable = symbols.Extract(result, symbols.ExtractConfig{
+		IncludeBody:      includeBody,
+		IncludeCallSites: includeBody,
+		OnConflict: func(iss schema.Issue) {
+			analysis.Issues = append(analysis.Issues, iss)
+		},
+	})
+	diag.Summarize(&analysis.Metadata, analysis.Issues)
+	analysis.Metadata.AnalysisDurationMs = time.Since(start).Milliseconds()
+
+	data, err := json.Marshal(analysis)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func jsError(msg string) js.Value {
+	return js.Global().Get("Error").New(msg)
+}
@@ -0,0 +1,13 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "codeanalyzer-wasm must be built with GOOS=js GOARCH=wasm")
+	os.Exit(2)
+}
@@ -2,9 +2,10 @@ module github.com/codellm-devkit/codeanalyzer-go
 
 go 1.24.0
 
-require golang.org/x/tools v0.41.0
-
 require (
-	golang.org/x/mod v0.32.0 // indirect
-	golang.org/x/sync v0.19.0 // indirect
+	github.com/klauspost/compress v1.18.4
+	golang.org/x/mod v0.32.0
+	golang.org/x/tools v0.41.0
 )
+
+require golang.org/x/sync v0.19.0 // indirect
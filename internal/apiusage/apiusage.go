@@ -0,0 +1,219 @@
+// Package apiusage conta, in un corpus di analisi CLDK, i riferimenti
+// all'API esportata di un modulo: chiamate a funzioni e metodi dal call
+// graph, uso dei tipi nelle firme, nei campi e nelle variabili dalla
+// symbol table.
+package apiusage
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Kind dei simboli.
+const (
+	KindFunction = "function"
+	KindMethod   = "method"
+	KindType     = "type"
+)
+
+// qualified riconosce i riferimenti "pkg.Nome" nelle stringhe di tipo.
+var qualified = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\.([A-Z][A-Za-z0-9_]*)`)
+
+// Counter accumula gli usi dell'API di un modulo, un'analisi alla volta.
+type Counter struct {
+	report   *schema.CLDKAPIUsage
+	symbols  map[string]*schema.CLDKAPISymbol
+	packages map[string]string // package del modulo → nome del package
+}
+
+// New prepara il conteggio per i simboli esportati dei package di target
+// il cui import path inizia con module (tutti se module è vuoto): funzioni,
+// metodi di tipi esportati e tipi.
+func New(target *schema.CLDKAnalysis, name, module string) *Counter {
+	c := &Counter{
+		report:   &schema.CLDKAPIUsage{Target: name, Module: module, Consumers: []schema.CLDKAPIConsumer{}},
+		symbols:  make(map[string]*schema.CLDKAPISymbol),
+		packages: make(map[string]string),
+	}
+	if target.SymbolTable == nil {
+		return c
+	}
+	add := func(id, kind, pkg string) {
+		c.symbols[id] = &schema.CLDKAPISymbol{ID: id, Kind: kind, Package: pkg}
+	}
+	for path, pkg := range target.SymbolTable.Packages {
+		if pkg == nil || !strings.HasPrefix(path, module) || isInternal(path) {
+			continue
+		}
+		c.packages[path] = pkg.Name
+		for id, fn := range pkg.CallableDeclarations {
+			switch {
+			case fn.Kind == KindFunction && exported(fn.Name):
+				add(id, KindFunction, path)
+			case fn.Kind == KindMethod && exported(fn.Name) && exported(fn.ReceiverType):
+				add(id, KindMethod, path)
+			}
+		}
+		for id, t := range pkg.TypeDeclarations {
+			if !exported(t.Name) {
+				continue
+			}
+			add(id, KindType, path)
+			for mid, m := range t.Methods {
+				if exported(m.Name) {
+					add(mid, KindMethod, path)
+				}
+			}
+		}
+	}
+	return c
+}
+
+// Add conta i riferimenti dell'analisi a, di nome name. I package del
+// modulo stesso non contano.
+func (c *Counter) Add(name string, a *schema.CLDKAnalysis) {
+	consumer := schema.CLDKAPIConsumer{Name: name}
+	used := make(map[string]bool)
+	hit := func(id string, call bool, n int) {
+		s := c.symbols[id]
+		if s == nil {
+			return
+		}
+		if call {
+			s.Calls += n
+		} else {
+			s.TypeUses += n
+		}
+		s.References += n
+		consumer.References += n
+		if !used[id] {
+			used[id] = true
+			s.Consumers = append(s.Consumers, name)
+		}
+	}
+
+	if a.CallGraph != nil {
+		pkgOf := make(map[string]string, len(a.CallGraph.Nodes))
+		for _, n := range a.CallGraph.Nodes {
+			pkgOf[n.ID] = n.Package
+		}
+		for _, e := range a.CallGraph.Edges {
+			if _, own := c.packages[pkgOf[e.Source]]; own {
+				continue
+			}
+			hit(ids.StripTypeArgs(e.Target), true, max(e.Count, 1))
+		}
+	}
+
+	if a.SymbolTable != nil {
+		for path, pkg := range a.SymbolTable.Packages {
+			if _, own := c.packages[path]; own || pkg == nil {
+				continue
+			}
+			// nome locale → package del modulo, dagli import
+			local := make(map[string]string)
+			for _, imp := range pkg.Imports {
+				name, ok := c.packages[imp.Path]
+				if !ok {
+					continue
+				}
+				if imp.Alias != "" {
+					name = imp.Alias
+				}
+				if name != "_" && name != "." {
+					local[name] = imp.Path
+				}
+			}
+			if len(local) == 0 {
+				continue
+			}
+			for _, typ := range typeStrings(pkg) {
+				for _, m := range qualified.FindAllStringSubmatch(typ, -1) {
+					if target, ok := local[m[1]]; ok {
+						hit(ids.Type(target, m[2]), false, 1)
+					}
+				}
+			}
+		}
+	}
+
+	consumer.Symbols = len(used)
+	c.report.Consumers = append(c.report.Consumers, consumer)
+}
+
+// Report restituisce i simboli per numero di riferimenti decrescente.
+func (c *Counter) Report() *schema.CLDKAPIUsage {
+	r := c.report
+	r.Symbols = make([]schema.CLDKAPISymbol, 0, len(c.symbols))
+	r.Used, r.Unused = 0, 0
+	for _, s := range c.symbols {
+		sort.Strings(s.Consumers)
+		r.Symbols = append(r.Symbols, *s)
+		if s.References > 0 {
+			r.Used++
+		} else {
+			r.Unused++
+		}
+	}
+	sort.Slice(r.Symbols, func(i, j int) bool {
+		a, b := r.Symbols[i], r.Symbols[j]
+		if a.References != b.References {
+			return a.References > b.References
+		}
+		return a.ID < b.ID
+	})
+	return r
+}
+
+// typeStrings raccoglie i tipi scritti nelle dichiarazioni del package:
+// parametri e risultati di funzioni e metodi, campi, tipi incorporati e
+// sottostanti, variabili e costanti.
+func typeStrings(pkg *schema.CLDKPackage) []string {
+	var out []string
+	params := func(ps []schema.CLDKParameter) {
+		for _, p := range ps {
+			out = append(out, p.Type)
+		}
+	}
+	for _, fn := range pkg.CallableDeclarations {
+		params(fn.Parameters)
+		params(fn.Results)
+	}
+	for _, t := range pkg.TypeDeclarations {
+		for _, f := range t.Fields {
+			out = append(out, f.Type)
+		}
+		for _, m := range t.InterfaceMethods {
+			params(m.Parameters)
+			params(m.Results)
+		}
+		out = append(out, t.EmbeddedTypes...)
+		if t.UnderlyingType != "" {
+			out = append(out, t.UnderlyingType)
+		}
+	}
+	for _, v := range pkg.Variables {
+		out = append(out, v.Type)
+	}
+	for _, k := range pkg.Constants {
+		out = append(out, k.Type)
+	}
+	return out
+}
+
+func exported(name string) bool {
+	r, _ := utf8.DecodeRuneInString(strings.TrimPrefix(name, "*"))
+	return unicode.IsUpper(r)
+}
+
+// isInternal riporta se path è sotto una directory internal, non
+// importabile da altri moduli.
+func isInternal(path string) bool {
+	return strings.HasPrefix(path, "internal/") || strings.Contains(path, "/internal/") || strings.HasSuffix(path, "/internal")
+}
@@ -0,0 +1,222 @@
+// Package archrules verifica le regole di architettura sugli import: un
+// file di regole dichiara quali package possono o non possono importarne
+// altri, e ogni import che le viola diventa un errore tra le Issues.
+package archrules
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// CodeViolation identifica un import vietato dalle regole di architettura.
+const CodeViolation = "ARCH_VIOLATION"
+
+// Azioni delle regole.
+const (
+	Deny  = "deny"
+	Allow = "allow"
+)
+
+// Rule è una riga del file di regole: "deny FROM -> TO" o
+// "allow FROM -> TO, TO...".
+type Rule struct {
+	Action string
+	From   []string // pattern dei package importatori
+	To     []string // pattern dei package importati
+	Line   int
+
+	from, to []*regexp.Regexp
+}
+
+// Rules è l'insieme ordinato delle regole.
+type Rules struct {
+	Rules []*Rule
+}
+
+// Load legge un file di regole. Ogni riga non vuota è
+//
+//	deny  FROM[, FROM...] -> TO[, TO...]
+//	allow FROM[, FROM...] -> TO[, TO...]
+//
+// e "#" inizia un commento. I pattern sono quelli di --match-pkg (glob
+// sull'import path, "re:" per le espressioni regolari); "x/..." comprende
+// anche x.
+func Load(name string) (*Rules, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rules, err := parse(bufio.NewScanner(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return rules, nil
+}
+
+func parse(sc *bufio.Scanner) (*Rules, error) {
+	rules := &Rules{}
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		action := strings.Fields(line)[0]
+		rest := line[len(action):]
+		if action != Deny && action != Allow {
+			return nil, fmt.Errorf("line %d: unknown action %q (valid: deny, allow)", n, action)
+		}
+		from, to, ok := strings.Cut(rest, "->")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing \"->\"", n)
+		}
+		r := &Rule{Action: action, From: patterns(from), To: patterns(to), Line: n}
+		if len(r.From) == 0 || len(r.To) == 0 {
+			return nil, fmt.Errorf("line %d: empty package pattern", n)
+		}
+		var err error
+		if r.from, err = compileAll(r.From); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if r.to, err = compileAll(r.To); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		rules.Rules = append(rules.Rules, r)
+	}
+	return rules, sc.Err()
+}
+
+func patterns(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// compileAll compila i pattern; per "x/..." aggiunge x stesso.
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	var out []*regexp.Regexp
+	for _, p := range patterns {
+		variants := []string{p}
+		if base, ok := strings.CutSuffix(p, "/..."); ok && !strings.HasPrefix(p, "re:") {
+			variants = append(variants, base)
+		}
+		for _, v := range variants {
+			re, err := pkgfilter.Compile(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", p, err)
+			}
+			out = append(out, re)
+		}
+	}
+	return out, nil
+}
+
+// Check valuta le regole su ogni import dei file del progetto (ristretti
+// a --files se indicato). Un package corrisponde a un pattern se vi
+// corrisponde il suo import path o, per i package del progetto, la sua
+// directory relativa alla root. Per ogni import decide l'ultima regola i
+// cui FROM e TO corrispondono entrambi: deny è una violazione, allow no.
+// Se nessuna regola corrisponde, l'import di un altro package del progetto
+// è una violazione quando una regola allow ha un FROM che corrisponde
+// all'importatore: le regole allow sono l'elenco completo delle sue
+// dipendenze interne. Gli import della libreria standard e dei moduli
+// esterni senza regole sono sempre ammessi.
+func Check(rules *Rules, result *loader.LoadResult) []schema.Issue {
+	if rules == nil || len(rules.Rules) == 0 {
+		return nil
+	}
+	dirs := result.PackageDirs()
+	project := result.ProjectPackages()
+	matches := func(res []*regexp.Regexp, pkgPath string) bool {
+		dir, inProject := dirs[pkgPath]
+		for _, re := range res {
+			if re.MatchString(pkgPath) || inProject && re.MatchString(dir) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var issues []schema.Issue
+	seen := make(map[token.Position]bool) // le varianti di test ripetono i file
+	for _, pkg := range result.ScopedPackages() {
+		if pkg == nil {
+			continue
+		}
+		// le varianti di test condividono la directory del package
+		from := strings.TrimSuffix(pkg.PkgPath, "_test")
+		if _, ok := dirs[from]; !ok {
+			from = pkg.PkgPath
+		}
+		restricted := false
+		for _, r := range rules.Rules {
+			if r.Action == Allow && matches(r.from, from) {
+				restricted = true
+				break
+			}
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil {
+				continue
+			}
+			for _, spec := range file.Imports {
+				pos := result.Fset.Position(spec.Pos())
+				if seen[pos] {
+					continue
+				}
+				seen[pos] = true
+				to, err := strconv.Unquote(spec.Path.Value)
+				if err != nil || to == "C" || to == from {
+					continue
+				}
+				var decided *Rule
+				for _, r := range rules.Rules {
+					if matches(r.from, from) && matches(r.to, to) {
+						decided = r
+					}
+				}
+				var msg string
+				switch {
+				case decided != nil && decided.Action == Deny:
+					msg = fmt.Sprintf("%s must not import %s (rule at line %d: deny %s -> %s)",
+						from, to, decided.Line, strings.Join(decided.From, ", "), strings.Join(decided.To, ", "))
+				case decided == nil && restricted && project[to]:
+					msg = fmt.Sprintf("%s imports %s, which no allow rule permits", from, to)
+				default:
+					continue
+				}
+				issues = append(issues, schema.Issue{
+					Severity: "error",
+					Code:     CodeViolation,
+					Message:  msg,
+					Position: loader.Position(pos, result.Root),
+				})
+			}
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Position, issues[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.StartLine < b.StartLine
+	})
+	return issues
+}
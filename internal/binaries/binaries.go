@@ -0,0 +1,230 @@
+// Package binaries inventaria i package main del progetto: per ogni binario
+// la directory, i vincoli di build dei suoi file, i flag definiti con il
+// package flag (dal main e dai package del progetto che importa) e i
+// package del progetto da cui dipende.
+package binaries
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// flagArgs descrive la firma delle funzioni di definizione dei flag: indici
+// di nome, default e usage (-1 se assenti) e tipo riportato.
+type flagArgs struct {
+	name, value, usage int
+	kind               string
+}
+
+var flagFuncs = map[string]flagArgs{
+	"Func":     {0, -1, 1, "func"},
+	"BoolFunc": {0, -1, 1, "bool_func"},
+	"TextVar":  {1, 2, 3, "text"},
+	"Var":      {1, -1, 2, "value"},
+}
+
+func init() {
+	for _, t := range []string{"String", "Int", "Bool", "Duration", "Float64", "Int64", "Uint", "Uint64"} {
+		flagFuncs[t] = flagArgs{0, 1, 2, strings.ToLower(t)}
+		flagFuncs[t+"Var"] = flagArgs{1, 2, 3, strings.ToLower(t)}
+	}
+}
+
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// Inventory restituisce i package main del progetto, nil se non ce ne sono.
+// I file di test non contano per i flag.
+func Inventory(result *loader.LoadResult) *schema.CLDKBinaries {
+	byPath := make(map[string]*packages.Package, len(result.Packages))
+	for _, pkg := range result.Packages {
+		if pkg != nil && (pkg.ID == pkg.PkgPath || byPath[pkg.PkgPath] == nil) {
+			byPath[pkg.PkgPath] = pkg
+		}
+	}
+	dirs := result.PackageDirs()
+	flags := make(map[string][]schema.CLDKBinaryFlag)
+	flagsOf := func(pkg *packages.Package) []schema.CLDKBinaryFlag {
+		if f, ok := flags[pkg.PkgPath]; ok {
+			return f
+		}
+		f := definedFlags(pkg, result.Fset, result.Root)
+		flags[pkg.PkgPath] = f
+		return f
+	}
+
+	out := &schema.CLDKBinaries{Binaries: []schema.CLDKBinary{}}
+	for path, pkg := range byPath {
+		if pkg.Name != "main" || pkg.ID != pkg.PkgPath {
+			continue
+		}
+		b := schema.CLDKBinary{
+			Name:    binaryName(path),
+			Package: path,
+			Dir:     dirs[path],
+			Flags:   []schema.CLDKBinaryFlag{},
+			Imports: []string{},
+		}
+		if pkg.Types != nil {
+			if obj, ok := pkg.Types.Scope().Lookup("main").(*types.Func); ok {
+				b.Main = result.Position(obj.Pos())
+			}
+		}
+		b.BuildConstraints = buildConstraints(append(append([]string(nil), pkg.GoFiles...), pkg.IgnoredFiles...))
+		for _, f := range pkg.IgnoredFiles {
+			if rel, err := filepath.Rel(result.Root, f); err == nil {
+				b.IgnoredFiles = append(b.IgnoredFiles, filepath.ToSlash(rel))
+			}
+		}
+		sort.Strings(b.IgnoredFiles)
+
+		// package del progetto importati, anche transitivamente
+		seen := map[string]bool{path: true}
+		queue := []*packages.Package{pkg}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			b.Flags = append(b.Flags, flagsOf(cur)...)
+			for imp := range cur.Imports {
+				if dep, ok := byPath[imp]; ok && !seen[imp] {
+					seen[imp] = true
+					b.Imports = append(b.Imports, imp)
+					queue = append(queue, dep)
+				}
+			}
+		}
+		sort.Strings(b.Imports)
+		// prima i flag del main, poi per package
+		sort.SliceStable(b.Flags, func(i, j int) bool {
+			x, y := b.Flags[i].Package, b.Flags[j].Package
+			if (x == path) != (y == path) {
+				return x == path
+			}
+			return x != path && x < y
+		})
+		out.Binaries = append(out.Binaries, b)
+	}
+	if len(out.Binaries) == 0 {
+		return nil
+	}
+	sort.Slice(out.Binaries, func(i, j int) bool {
+		if out.Binaries[i].Dir != out.Binaries[j].Dir {
+			return out.Binaries[i].Dir < out.Binaries[j].Dir
+		}
+		return out.Binaries[i].Package < out.Binaries[j].Package
+	})
+	return out
+}
+
+// binaryName restituisce il nome dato da go build: l'ultimo elemento
+// dell'import path, o il precedente se è un suffisso di versione (/v2).
+func binaryName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if majorVersion.MatchString(name) && len(elems) > 1 {
+		name = elems[len(elems)-2]
+	}
+	return name
+}
+
+// definedFlags restituisce i flag definiti nei file non di test di pkg, in
+// ordine di sorgente.
+func definedFlags(pkg *packages.Package, fset *token.FileSet, root string) []schema.CLDKBinaryFlag {
+	var out []schema.CLDKBinaryFlag
+	if pkg.TypesInfo == nil {
+		return out
+	}
+	info := pkg.TypesInfo
+	for _, file := range pkg.Syntax {
+		if file == nil || strings.HasSuffix(fset.Position(file.Pos()).Filename, "_test.go") {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			fn, ok := typeutil.Callee(info, call).(*types.Func)
+			if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "flag" {
+				return true
+			}
+			args, ok := flagFuncs[fn.Name()]
+			if !ok || len(call.Args) <= max(args.name, args.value, args.usage) {
+				return true
+			}
+			f := schema.CLDKBinaryFlag{
+				Name:     text(call.Args[args.name], info),
+				Type:     args.kind,
+				Package:  pkg.PkgPath,
+				Position: loader.Position(fset.Position(call.Pos()), root),
+			}
+			if args.value >= 0 {
+				f.Default = text(call.Args[args.value], info)
+			}
+			if args.usage >= 0 {
+				f.Usage = text(call.Args[args.usage], info)
+			}
+			if sig := fn.Type().(*types.Signature); sig.Recv() != nil {
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+					f.FlagSet = types.ExprString(sel.X)
+				}
+			}
+			out = append(out, f)
+			return true
+		})
+	}
+	return out
+}
+
+// text restituisce il valore di una stringa costante, altrimenti
+// l'espressione sorgente.
+func text(e ast.Expr, info *types.Info) string {
+	if tv, ok := info.Types[e]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value)
+	}
+	return types.ExprString(e)
+}
+
+// buildConstraints restituisce le espressioni //go:build distinte dei file,
+// normalizzate e ordinate.
+func buildConstraints(files []string) []string {
+	set := make(map[string]bool)
+	for _, name := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), name, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, group := range f.Comments {
+			if group.Pos() >= f.Package {
+				break
+			}
+			for _, c := range group.List {
+				if !constraint.IsGoBuild(c.Text) {
+					continue
+				}
+				if expr, err := constraint.Parse(c.Text); err == nil {
+					set[expr.String()] = true
+				}
+			}
+		}
+	}
+	out := make([]string, 0, len(set))
+	for c := range set {
+		out = append(out, c)
+	}
+	sort.Strings(out)
+	return out
+}
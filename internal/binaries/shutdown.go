@@ -0,0 +1,244 @@
+package binaries
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// CodeNoGracefulShutdown segnala un binario che avvia un server ma non lo
+// spegne in modo ordinato alla ricezione di SIGINT/SIGTERM.
+const CodeNoGracefulShutdown = "NO_GRACEFUL_SHUTDOWN"
+
+// Kind dei passi di spegnimento.
+const (
+	StepSignal   = "signal"
+	StepServe    = "serve"
+	StepCancel   = "cancel"
+	StepShutdown = "shutdown"
+	StepWait     = "wait"
+)
+
+// serveFuncs sono le chiamate che avviano un server, come funzioni del
+// package o metodi del suo tipo Server.
+var serveFuncs = map[string]map[string]bool{
+	"net/http":               {"ListenAndServe": true, "ListenAndServeTLS": true, "Serve": true, "ServeTLS": true},
+	"google.golang.org/grpc": {"Serve": true},
+}
+
+// waitTypes sono i tipi il cui metodo Wait attende la fine delle goroutine.
+var waitTypes = map[string]bool{
+	"sync.WaitGroup":                   true,
+	"golang.org/x/sync/errgroup.Group": true,
+}
+
+// Shutdown aggiunge a ogni binario di bins i passi del suo spegnimento,
+// cercati nei file non di test del main e dei package del progetto che
+// importa: signal.Notify e signal.NotifyContext, l'avvio di server HTTP e
+// gRPC, le chiamate a context.CancelFunc, i metodi Shutdown e GracefulStop
+// e le attese su sync.WaitGroup ed errgroup.Group. Restituisce un warning
+// per ogni binario che avvia un server senza gestire i segnali o senza
+// alcun passo di spegnimento.
+func Shutdown(result *loader.LoadResult, bins *schema.CLDKBinaries) []schema.Issue {
+	if bins == nil {
+		return nil
+	}
+	byPath := make(map[string]*packages.Package, len(result.Packages))
+	for _, pkg := range result.Packages {
+		if pkg != nil && (pkg.ID == pkg.PkgPath || byPath[pkg.PkgPath] == nil) {
+			byPath[pkg.PkgPath] = pkg
+		}
+	}
+	steps := make(map[string][]schema.CLDKShutdownStep)
+	stepsOf := func(path string) []schema.CLDKShutdownStep {
+		if s, ok := steps[path]; ok {
+			return s
+		}
+		var s []schema.CLDKShutdownStep
+		if pkg := byPath[path]; pkg != nil {
+			s = shutdownSteps(pkg, result.Fset, result.Root)
+		}
+		steps[path] = s
+		return s
+	}
+
+	var issues []schema.Issue
+	for i := range bins.Binaries {
+		b := &bins.Binaries[i]
+		sd := &schema.CLDKShutdown{Signals: []string{}, Steps: []schema.CLDKShutdownStep{}}
+		for _, path := range append([]string{b.Package}, b.Imports...) {
+			sd.Steps = append(sd.Steps, stepsOf(path)...)
+		}
+		sort.SliceStable(sd.Steps, func(i, j int) bool {
+			a, b := sd.Steps[i].Position, sd.Steps[j].Position
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			if a.StartLine != b.StartLine {
+				return a.StartLine < b.StartLine
+			}
+			return a.StartColumn < b.StartColumn
+		})
+
+		kinds := make(map[string]bool)
+		signals := make(map[string]bool)
+		for _, s := range sd.Steps {
+			kinds[s.Kind] = true
+			for _, sig := range s.Signals {
+				signals[sig] = true
+			}
+		}
+		for sig := range signals {
+			sd.Signals = append(sd.Signals, sig)
+		}
+		sort.Strings(sd.Signals)
+		sd.Server = kinds[StepServe]
+		sd.Graceful = kinds[StepSignal] && (kinds[StepCancel] || kinds[StepShutdown] || kinds[StepWait])
+		b.Shutdown = sd
+
+		if !sd.Server || sd.Graceful {
+			continue
+		}
+		msg := fmt.Sprintf("binary %s starts a server but does not handle SIGINT/SIGTERM", b.Name)
+		if kinds[StepSignal] {
+			msg = fmt.Sprintf("binary %s handles signals but never shuts down its servers, cancels a context or waits for goroutines", b.Name)
+		}
+		issues = append(issues, schema.Issue{
+			Severity: "warning",
+			Code:     CodeNoGracefulShutdown,
+			Message:  msg,
+			Position: b.Main,
+		})
+	}
+	return issues
+}
+
+// shutdownSteps restituisce i passi di spegnimento nei file non di test di
+// pkg. Le chiamate nelle closure sono della funzione che le contiene.
+func shutdownSteps(pkg *packages.Package, fset *token.FileSet, root string) []schema.CLDKShutdownStep {
+	var out []schema.CLDKShutdownStep
+	if pkg.TypesInfo == nil {
+		return out
+	}
+	info := pkg.TypesInfo
+	for _, file := range pkg.Syntax {
+		if file == nil || strings.HasSuffix(fset.Position(file.Pos()).Filename, "_test.go") {
+			continue
+		}
+		for _, decl := range file.Decls {
+			function := ""
+			if fd, ok := decl.(*ast.FuncDecl); ok {
+				if obj, ok := info.Defs[fd.Name].(*types.Func); ok {
+					function = ids.Object(obj)
+				}
+			}
+			ast.Inspect(decl, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				kind, callee := stepKind(call, info)
+				if kind == "" {
+					return true
+				}
+				s := schema.CLDKShutdownStep{
+					Kind:     kind,
+					Call:     callee,
+					Function: function,
+					Package:  pkg.PkgPath,
+					Position: loader.Position(fset.Position(call.Pos()), root),
+				}
+				if kind == StepSignal {
+					s.Signals = signalArgs(call, info)
+				}
+				out = append(out, s)
+				return true
+			})
+		}
+	}
+	return out
+}
+
+// stepKind classifica call e restituisce l'ID della funzione chiamata; kind
+// vuoto se la chiamata non riguarda lo spegnimento.
+func stepKind(call *ast.CallExpr, info *types.Info) (string, string) {
+	switch obj := typeutil.Callee(info, call).(type) {
+	case *types.Var:
+		// cancel() e stop() di context.WithCancel e signal.NotifyContext
+		if named, ok := types.Unalias(obj.Type()).(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" {
+			if name := named.Obj().Name(); name == "CancelFunc" || name == "CancelCauseFunc" {
+				return StepCancel, ids.Type("context", name)
+			}
+		}
+	case *types.Func:
+		if obj.Pkg() == nil {
+			break
+		}
+		id := ids.Object(obj)
+		path, name := obj.Pkg().Path(), obj.Name()
+		recv := obj.Type().(*types.Signature).Recv()
+		switch {
+		case path == "os/signal" && (name == "Notify" || name == "NotifyContext"):
+			return StepSignal, id
+		case serveFuncs[path][name] && (recv == nil || receiverIs(recv.Type(), path, "Server")):
+			return StepServe, id
+		case recv != nil && (name == "Shutdown" || name == "GracefulStop"):
+			return StepShutdown, id
+		case recv != nil && name == "Wait" && waitTypes[receiverID(recv.Type())]:
+			return StepWait, id
+		}
+	}
+	return "", ""
+}
+
+// signalArgs restituisce i segnali passati a signal.Notify o NotifyContext
+// (dal secondo argomento), "all" se non ce ne sono.
+func signalArgs(call *ast.CallExpr, info *types.Info) []string {
+	if len(call.Args) < 2 {
+		return []string{"all"}
+	}
+	var out []string
+	for _, arg := range call.Args[1:] {
+		var id *ast.Ident
+		switch x := ast.Unparen(arg).(type) {
+		case *ast.Ident:
+			id = x
+		case *ast.SelectorExpr:
+			id = x.Sel
+		}
+		if id != nil {
+			if obj := info.Uses[id]; obj != nil && obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+				out = append(out, ids.Type(obj.Pkg().Path(), obj.Name()))
+				continue
+			}
+		}
+		out = append(out, types.ExprString(arg))
+	}
+	return out
+}
+
+// receiverID restituisce l'ID del tipo con nome del receiver, senza "*".
+func receiverID(t types.Type) string {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+	return ids.Type(named.Obj().Pkg().Path(), named.Obj().Name())
+}
+
+func receiverIs(t types.Type, path, name string) bool {
+	return receiverID(t) == ids.Type(path, name)
+}
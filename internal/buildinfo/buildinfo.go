@@ -0,0 +1,13 @@
+// Package buildinfo legge le informazioni di build dei comandi.
+package buildinfo
+
+import "runtime/debug"
+
+// Version è la versione del modulo principale (go install ...@vX.Y.Z),
+// "dev" per build locali.
+func Version() string {
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+	return "dev"
+}
@@ -0,0 +1,417 @@
+// Package buildmatrix valuta i vincoli di build dei file del progetto
+// (//go:build e suffissi _GOOS/_GOARCH del nome) su un insieme di
+// piattaforme, senza ricaricare i package per ciascuna: per ogni
+// piattaforma dice quali file, package e simboli top-level esistono.
+package buildmatrix
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// DefaultPlatforms è l'insieme usato con --build-matrix default.
+const DefaultPlatforms = "linux/amd64,linux/arm64,darwin/amd64,darwin/arm64,windows/amd64,windows/arm64"
+
+// Platform è una combinazione GOOS/GOARCH con tag di build aggiuntivi
+// ("cgo" abilita cgo).
+type Platform struct {
+	GOOS   string
+	GOARCH string
+	Tags   []string
+}
+
+// String restituisce la forma "goos/goarch[+tag...]" usata in output.
+func (p Platform) String() string {
+	s := p.GOOS + "/" + p.GOARCH
+	for _, t := range p.Tags {
+		s += "+" + t
+	}
+	return s
+}
+
+// Da go/build (syslist.go): valori riconosciuti nei suffissi dei nomi file.
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+	"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+	"windows": true, "zos": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+	"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+	"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+	"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+	"sparc": true, "sparc64": true, "wasm": true,
+}
+
+// ParsePlatforms interpreta --build-matrix: "default" (DefaultPlatforms)
+// oppure voci "goos/goarch[+tag...]" separate da virgola.
+func ParsePlatforms(spec string) ([]Platform, error) {
+	if strings.TrimSpace(spec) == "default" {
+		spec = DefaultPlatforms
+	}
+	var out []Platform
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, "+")
+		goos, goarch, ok := strings.Cut(parts[0], "/")
+		if !ok || !knownOS[goos] || !knownArch[goarch] {
+			return nil, fmt.Errorf("invalid platform %q (want goos/goarch[+tag...], e.g. linux/amd64)", entry)
+		}
+		p := Platform{GOOS: goos, GOARCH: goarch}
+		for _, t := range parts[1:] {
+			if t == "" {
+				return nil, fmt.Errorf("invalid platform %q: empty tag", entry)
+			}
+			p.Tags = append(p.Tags, t)
+		}
+		if !seen[p.String()] {
+			seen[p.String()] = true
+			out = append(out, p)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no platforms in %q", spec)
+	}
+	return out, nil
+}
+
+// Env restituisce le variabili d'ambiente che fanno caricare i package per
+// p (vedi loader.Options.Env). I tag si aggiungono a GOFLAGS.
+func (p Platform) Env() []string {
+	cgo := "0"
+	var tags []string
+	for _, t := range p.Tags {
+		if t == "cgo" {
+			cgo = "1"
+			continue
+		}
+		tags = append(tags, t)
+	}
+	env := []string{"GOOS=" + p.GOOS, "GOARCH=" + p.GOARCH, "CGO_ENABLED=" + cgo}
+	if len(tags) > 0 {
+		env = append(env, "GOFLAGS="+strings.TrimSpace(os.Getenv("GOFLAGS")+" -tags="+strings.Join(tags, ",")))
+	}
+	return env
+}
+
+// context restituisce il build.Context che valuta i file per p.
+func (p Platform) context() build.Context {
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH = p.GOOS, p.GOARCH
+	ctx.CgoEnabled = false
+	ctx.BuildTags = nil
+	for _, t := range p.Tags {
+		if t == "cgo" {
+			ctx.CgoEnabled = true
+			continue
+		}
+		ctx.BuildTags = append(ctx.BuildTags, t)
+	}
+	return ctx
+}
+
+// Compute valuta su platforms i file Go dei package (compilati e ignorati
+// dal loader per la piattaforma corrente, esclusi quelli generati fuori da
+// root) e delle directory sotto root che il loader ha scartato perché
+// nessun file è compilato sulla piattaforma corrente; filter ed excludeDirs
+// si applicano a queste ultime come al loader. Elenca i file con vincoli e
+// i package che ne contengono, ciascuno con le piattaforme in cui è incluso.
+// I file e le directory sotto root sono letti da fsys, i sorgenti visti dal
+// loader (LoadResult.FS).
+func Compute(pkgs []*packages.Package, root string, fsys fs.FS, platforms []Platform, filter *pkgfilter.Filter, excludeDirs []string) *schema.CLDKBuildMatrix {
+	m := &schema.CLDKBuildMatrix{Files: []schema.CLDKBuildFile{}, Packages: []schema.CLDKBuildPackage{}}
+	open := func(name string) (io.ReadCloser, error) {
+		data, err := loader.ReadFile(fsys, root, name)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	contexts := make([]build.Context, len(platforms))
+	for i, p := range platforms {
+		m.Platforms = append(m.Platforms, p.String())
+		contexts[i] = p.context()
+		contexts[i].OpenFile = open
+	}
+
+	fset := token.NewFileSet()
+	seen := make(map[string]bool)
+	byPkg := make(map[string]*schema.CLDKBuildPackage)
+	var order []string
+	pkgs = append(pkgs, unloaded(pkgs, root, fsys, filter, excludeDirs)...)
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		files := append(append([]string{}, pkg.GoFiles...), pkg.IgnoredFiles...)
+		for _, file := range files {
+			rel, err := filepath.Rel(root, file)
+			if err != nil || seen[file] || !strings.HasSuffix(file, ".go") || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			seen[file] = true
+			// I _test.go contano solo se caricati (--include-tests)
+			if strings.HasSuffix(file, "_test.go") && !slices.Contains(pkg.GoFiles, file) {
+				continue
+			}
+
+			bf := schema.CLDKBuildFile{File: filepath.ToSlash(rel), Package: pkg.PkgPath, FileSuffix: fileSuffix(filepath.Base(file))}
+			src, err := loader.ReadFile(fsys, root, file)
+			var f *ast.File
+			if err == nil {
+				f, err = parser.ParseFile(fset, file, src, parser.ParseComments|parser.SkipObjectResolution)
+			}
+			if err == nil {
+				bf.Constraint = goBuild(f)
+				if pkg.Name == "" {
+					pkg.Name = f.Name.Name
+				}
+				if f.Name.Name == pkg.Name {
+					bf.Symbols = symbols(pkg.PkgPath, f)
+				}
+			}
+			var included []int
+			for i := range contexts {
+				if ok, err := contexts[i].MatchFile(filepath.Dir(file), filepath.Base(file)); err == nil && ok {
+					included = append(included, i)
+				}
+			}
+
+			bp := byPkg[pkg.PkgPath]
+			if bp == nil {
+				bp = &schema.CLDKBuildPackage{Package: pkg.PkgPath}
+				byPkg[pkg.PkgPath] = bp
+				order = append(order, pkg.PkgPath)
+			}
+			for _, i := range included {
+				if !slices.Contains(bp.Platforms, m.Platforms[i]) {
+					bp.Platforms = append(bp.Platforms, m.Platforms[i])
+				}
+			}
+			if bf.Constraint == "" && bf.FileSuffix == "" {
+				continue
+			}
+			bp.ConstrainedFiles++
+			bf.Platforms = []string{}
+			for _, i := range included {
+				bf.Platforms = append(bf.Platforms, m.Platforms[i])
+			}
+			m.Files = append(m.Files, bf)
+		}
+	}
+
+	for _, path := range order {
+		bp := byPkg[path]
+		if bp.ConstrainedFiles == 0 {
+			continue
+		}
+		// Piattaforme nell'ordine di --build-matrix
+		sorted := []string{}
+		for _, p := range m.Platforms {
+			if slices.Contains(bp.Platforms, p) {
+				sorted = append(sorted, p)
+			}
+		}
+		bp.Platforms = sorted
+		m.Packages = append(m.Packages, *bp)
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].File < m.Files[j].File })
+	sort.Slice(m.Packages, func(i, j int) bool { return m.Packages[i].Package < m.Packages[j].Package })
+	return m
+}
+
+// goBuild restituisce l'espressione //go:build del file, vuota se assente.
+func goBuild(f *ast.File) string {
+	for _, cg := range f.Comments {
+		if cg.Pos() >= f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if constraint.IsGoBuild(c.Text) {
+				if expr, err := constraint.Parse(c.Text); err == nil {
+					return expr.String()
+				}
+				return strings.TrimSpace(strings.TrimPrefix(c.Text, "//go:build"))
+			}
+		}
+	}
+	return ""
+}
+
+// fileSuffix restituisce il vincolo implicito nel nome del file ("windows",
+// "linux_arm64", "amd64"), vuoto se assente. Segue le regole di go/build.
+func fileSuffix(name string) string {
+	name = strings.TrimSuffix(name, ".go")
+	name = strings.TrimSuffix(name, "_test")
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return ""
+	}
+	parts := strings.Split(name[i:], "_")
+	n := len(parts)
+	if n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return parts[n-2] + "_" + parts[n-1]
+	}
+	if n >= 1 && (knownOS[parts[n-1]] || knownArch[parts[n-1]]) {
+		return parts[n-1]
+	}
+	return ""
+}
+
+// symbols restituisce gli ID delle dichiarazioni top-level di f.
+func symbols(pkgPath string, f *ast.File) []string {
+	var out []string
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				out = append(out, ids.Func(pkgPath, d.Name.Name))
+				continue
+			}
+			recv, ptr := d.Recv.List[0].Type, false
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv, ptr = star.X, true
+			}
+			out = append(out, ids.Method(pkgPath, recvName(recv), ptr, d.Name.Name))
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					out = append(out, ids.Type(pkgPath, s.Name.Name))
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.Name != "_" {
+							out = append(out, ids.Type(pkgPath, n.Name))
+						}
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+func recvName(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return recvName(t.X)
+	case *ast.IndexListExpr:
+		return recvName(t.X)
+	}
+	return ""
+}
+
+// unloaded restituisce, come package con soli IgnoredFiles, le directory
+// sotto root (letta da fsys) con file .go (non di test) che non appartengono
+// a nessun package caricato: "go list ./..." le omette quando i vincoli
+// escludono tutti i loro file. L'import path deriva dal go.mod più vicino; i
+// moduli annidati sono saltati come fa "./..." (salvo workspace go.work).
+func unloaded(pkgs []*packages.Package, root string, fsys fs.FS, filter *pkgfilter.Filter, excludeDirs []string) []*packages.Package {
+	loaded := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, f := range append(append([]string{}, pkg.GoFiles...), pkg.IgnoredFiles...) {
+			loaded[filepath.Dir(f)] = true
+		}
+	}
+	excluded := make(map[string]bool)
+	for _, d := range excludeDirs {
+		excluded[strings.TrimSpace(d)] = true
+	}
+
+	_, err := fs.Stat(fsys, "go.work")
+	workspace := err == nil
+
+	var out []*packages.Package
+	fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if p != "." && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" || excluded[name]) {
+			return fs.SkipDir
+		}
+		if p != "." && !workspace {
+			if _, err := fs.Stat(fsys, path.Join(p, "go.mod")); err == nil {
+				return fs.SkipDir
+			}
+		}
+		dir := filepath.Join(root, filepath.FromSlash(p))
+		if loaded[dir] {
+			return nil
+		}
+		entries, err := fs.ReadDir(fsys, p)
+		if err != nil {
+			return nil
+		}
+		var files []string
+		for _, e := range entries {
+			if n := e.Name(); !e.IsDir() && strings.HasSuffix(n, ".go") && !strings.HasSuffix(n, "_test.go") {
+				files = append(files, filepath.Join(dir, n))
+			}
+		}
+		if len(files) == 0 {
+			return nil
+		}
+		pkgPath := importPath(fsys, p)
+		if pkgPath == "" || !filter.Allows(pkgPath) {
+			return nil
+		}
+		out = append(out, &packages.Package{PkgPath: pkgPath, IgnoredFiles: files})
+		return nil
+	})
+	return out
+}
+
+// importPath deriva l'import path di dir (relativa alla radice di fsys)
+// dal go.mod più vicino, senza risalire oltre la radice. Restituisce "" se
+// non c'è un go.mod.
+func importPath(fsys fs.FS, dir string) string {
+	for d := dir; ; d = path.Dir(d) {
+		if data, err := fs.ReadFile(fsys, path.Join(d, "go.mod")); err == nil {
+			mod := modfile.ModulePath(data)
+			if mod == "" {
+				return ""
+			}
+			rel := "."
+			if d != dir {
+				rel = strings.TrimPrefix(dir, strings.TrimPrefix(d+"/", "./"))
+			}
+			return path.Join(mod, rel)
+		}
+		if d == "." {
+			return ""
+		}
+	}
+}
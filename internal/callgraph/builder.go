@@ -4,7 +4,7 @@ package callgraph
 import (
 	"fmt"
 	"go/token"
-	"os"
+	"log/slog"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -12,24 +12,43 @@ import (
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/callgraph/cha"
 	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/callgraph/vta"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
 
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
 	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
 )
 
 // Config configura la costruzione del call graph.
 type Config struct {
-	Algorithm     string   // cha|rta (default: rta)
-	EmitPositions string   // detailed|minimal
-	OnlyPkg       []string // filtra a questi package path (substring match)
+	Algorithm     string            // cha|rta|vta|static-approx (default: rta)
+	EmitPositions string            // detailed|minimal
+	Packages      *pkgfilter.Filter // filtra i package (--only-pkg, --match-pkg, --exclude-pkg)
+	Reach         bool              // calcola anche la transitive reach di ogni nodo
+
+	// MaxCallSites limita le posizioni elencate in CallSites per gli archi
+	// con più call site (0 = nessun elenco, solo Count e CallSite)
+	MaxCallSites int
+
+	OnFallback func(schema.Issue) // callback opzionale quando si ripiega su CHA (panic di RTA, Update senza main)
 }
 
-// Build costruisce un call graph CLDK da un LoadResult con SSA.
+// CodeFallback segnala un call graph costruito con CHA perché RTA è andato
+// in panic o, in Update, non ha main tra i package ricostruiti: archi in
+// più, nessun errore.
+const CodeFallback = "CALLGRAPH_FALLBACK"
+
+// Build costruisce un call graph CLDK da un LoadResult con SSA; con
+// static-approx l'SSA non serve (vedi buildStatic).
 func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKCallGraph, error) {
+	if strings.ToLower(cfg.Algorithm) == AlgorithmStaticApprox {
+		return buildStatic(result, cfg)
+	}
 	if result.SSAProgram == nil {
-		return nil, fmt.Errorf("SSAProgram is nil, call LoadWithSSA with NeedSSA=true")
+		return nil, fmt.Errorf("SSAProgram is nil, call loader.Load with NeedSSA=true")
 	}
 
 	prog := result.SSAProgram
@@ -76,9 +95,21 @@ func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKCallGraph, error)
 				cg = res.CallGraph
 			}()
 			if panicMsg != nil {
-				fmt.Fprintf(os.Stderr, "RTA panic (fallback to CHA): %v\n", panicMsg)
+				slog.Warn("RTA panic, falling back to CHA", "panic", fmt.Sprint(panicMsg))
+				if cfg.OnFallback != nil {
+					cfg.OnFallback(schema.Issue{
+						Severity: "warning",
+						Code:     CodeFallback,
+						Message:  fmt.Sprintf("RTA failed (%v), call graph built with CHA instead: less precise, more edges", panicMsg),
+					})
+				}
 			}
 		}
+	case "vta":
+		// VTA raffina il grafo iniziale (nil = CHA interno) con il flusso
+		// dei tipi tra variabili: più preciso di RTA sulle chiamate
+		// dinamiche, non richiede un main
+		cg = vta.CallGraph(ssautil.AllFunctions(prog), nil)
 	default: // "cha"
 		cg = cha.CallGraph(prog)
 		algo = "cha"
@@ -93,31 +124,34 @@ func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKCallGraph, error)
 
 	nodeSet := make(map[string]*schema.CLDKCGNode)
 	edgeSet := make(map[string]schema.CLDKCGEdge)
+	sites := make(map[string][]schema.CLDKPosition) // arco → call site distinti
 	fset := prog.Fset
 
-	// Helper per filtrare per onlyPkg
+	// Helper per filtrare i package
 	inAllowedPkgs := func(f *ssa.Function) bool {
 		if f == nil || f.Pkg == nil || f.Pkg.Pkg == nil {
-			return len(cfg.OnlyPkg) == 0
-		}
-		if len(cfg.OnlyPkg) == 0 {
-			return true
+			return cfg.Packages.Empty()
 		}
-		pp := f.Pkg.Pkg.Path()
-		for _, s := range cfg.OnlyPkg {
-			if s != "" && strings.Contains(pp, s) {
-				return true
-			}
-		}
-		return false
+		return cfg.Packages.Allows(f.Pkg.Pkg.Path())
 	}
 
-	// Itera su tutti i nodi e archi del grafo
+	// Itera su tutti i nodi e archi del grafo, in ordine stabile: più
+	// funzioni SSA possono avere lo stesso ID (wrapper sintetici) e il nodo
+	// emesso è quello della prima incontrata
+	nodes := make([]*callgraph.Node, 0, len(cg.Nodes))
 	for _, n := range cg.Nodes {
-		if n == nil || n.Func == nil {
-			continue
+		if n != nil && n.Func != nil {
+			nodes = append(nodes, n)
 		}
-
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i].Func, nodes[j].Func
+		if a.String() != b.String() {
+			return a.String() < b.String()
+		}
+		return a.Pos() < b.Pos()
+	})
+	for _, n := range nodes {
 		for _, e := range n.Out {
 			if e == nil || e.Caller == nil || e.Callee == nil {
 				continue
@@ -130,46 +164,53 @@ func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKCallGraph, error)
 			}
 
 			// Filtra archi completamente esterni
-			if len(cfg.OnlyPkg) > 0 && !inAllowedPkgs(src) && !inAllowedPkgs(dst) {
+			if !cfg.Packages.Empty() && !inAllowedPkgs(src) && !inAllowedPkgs(dst) {
 				continue
 			}
 
-			srcID := stableFuncID(src)
-			dstID := stableFuncID(dst)
+			srcID := ids.SSAFunc(src)
+			dstID := ids.SSAFunc(dst)
 			if srcID == "" || dstID == "" {
 				continue
 			}
 
 			// Aggiungi nodi
 			if _, ok := nodeSet[srcID]; !ok {
-				nodeSet[srcID] = buildNode(src, fset, result.Root, cfg)
+				nodeSet[srcID] = buildNode(src, fset, result, cfg)
 			}
 			if _, ok := nodeSet[dstID]; !ok {
-				nodeSet[dstID] = buildNode(dst, fset, result.Root, cfg)
+				nodeSet[dstID] = buildNode(dst, fset, result, cfg)
 			}
 
-			// Aggiungi arco
+			// Posizione del call site — sempre emessa (serve per
+			// correlare caller↔callee a livello di sorgente)
+			var site *schema.CLDKPosition
+			if e.Site != nil {
+				pos := fset.Position(e.Site.Pos())
+				if pos.IsValid() {
+					file := pos.Filename
+					if rel, err := filepath.Rel(result.Root, file); err == nil {
+						file = filepath.ToSlash(rel)
+					}
+					site = &schema.CLDKPosition{
+						File:        file,
+						StartLine:   pos.Line,
+						StartColumn: pos.Column,
+					}
+				}
+			}
+
+			// Aggiungi arco: la stessa coppia caller→callee può comparire
+			// in più call site, contati in Count
 			edgeKey := srcID + "→" + dstID
+			if site != nil && !containsPosition(sites[edgeKey], *site) {
+				sites[edgeKey] = append(sites[edgeKey], *site)
+			}
 			if _, ok := edgeSet[edgeKey]; !ok {
 				edge := schema.CLDKCGEdge{
-					Source: srcID,
-					Target: dstID,
-				}
-				// Posizione del call site — sempre emessa (serve per
-				// correlare caller↔callee a livello di sorgente)
-				if e.Site != nil {
-					pos := fset.Position(e.Site.Pos())
-					if pos.IsValid() {
-						file := pos.Filename
-						if rel, err := filepath.Rel(result.Root, file); err == nil {
-							file = filepath.ToSlash(rel)
-						}
-						edge.CallSite = &schema.CLDKPosition{
-							File:        file,
-							StartLine:   pos.Line,
-							StartColumn: pos.Column,
-						}
-					}
+					Source:   srcID,
+					Target:   dstID,
+					CallSite: site,
 				}
 				// Determina il tipo di chiamata
 				if e.Site != nil {
@@ -186,6 +227,14 @@ func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKCallGraph, error)
 				edge.Category = categorizeAPI(dstID)
 				edgeSet[edgeKey] = edge
 			}
+			// Dispatch dinamico: il metodo d'interfaccia chiamato, da
+			// qualunque call site della coppia, anche se il primo è diretto
+			if e.Site != nil && e.Site.Common().IsInvoke() {
+				if edge := edgeSet[edgeKey]; edge.DeclaredTarget == "" {
+					edge.DeclaredTarget = ids.InterfaceMethod(e.Site.Common().Method)
+					edgeSet[edgeKey] = edge
+				}
+			}
 		}
 	}
 
@@ -197,7 +246,8 @@ func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKCallGraph, error)
 		return out.Nodes[i].ID < out.Nodes[j].ID
 	})
 
-	for _, edge := range edgeSet {
+	for key, edge := range edgeSet {
+		setCallSites(&edge, sites[key], cfg.MaxCallSites)
 		out.Edges = append(out.Edges, edge)
 	}
 	sort.Slice(out.Edges, func(i, j int) bool {
@@ -207,12 +257,49 @@ func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKCallGraph, error)
 		return out.Edges[i].Source < out.Edges[j].Source
 	})
 
+	AnnotateDegrees(out, cfg.Reach)
+
 	return out, nil
 }
 
+// setCallSites imposta Count (almeno 1) e, se i call site sono più di uno,
+// CallSites ordinati per posizione e limitati a max.
+func setCallSites(edge *schema.CLDKCGEdge, sites []schema.CLDKPosition, max int) {
+	edge.Count = len(sites)
+	if edge.Count == 0 {
+		edge.Count = 1
+	}
+	if len(sites) < 2 || max <= 0 {
+		return
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		a, b := sites[i], sites[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.StartColumn < b.StartColumn
+	})
+	if len(sites) > max {
+		sites = sites[:max]
+	}
+	edge.CallSites = sites
+}
+
+func containsPosition(list []schema.CLDKPosition, p schema.CLDKPosition) bool {
+	for _, q := range list {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
 // buildNode costruisce un nodo CLDK da una funzione SSA.
-func buildNode(f *ssa.Function, fset *token.FileSet, root string, cfg Config) *schema.CLDKCGNode {
-	id := stableFuncID(f)
+func buildNode(f *ssa.Function, fset *token.FileSet, result *loader.LoadResult, cfg Config) *schema.CLDKCGNode {
+	id := ids.SSAFunc(f)
 
 	node := &schema.CLDKCGNode{
 		ID:            id,
@@ -232,12 +319,19 @@ func buildNode(f *ssa.Function, fset *token.FileSet, root string, cfg Config) *s
 		node.Kind = "function"
 	}
 
+	// Dichiarazione nella symbol table (solo package del progetto)
+	if ref := ids.SymbolRef(f); ref != "" {
+		if pkg := symbolPkg(f); pkg != "" && result.ProjectPackages()[pkg] {
+			node.SymbolRef = ref
+		}
+	}
+
 	// Posizione
 	if cfg.EmitPositions != "minimal" && fset != nil {
 		pos := fset.Position(f.Pos())
 		if pos.IsValid() {
 			file := pos.Filename
-			if rel, err := filepath.Rel(root, file); err == nil {
+			if rel, err := filepath.Rel(result.Root, file); err == nil {
 				file = filepath.ToSlash(rel)
 			}
 			node.Position = &schema.CLDKPosition{
@@ -251,51 +345,16 @@ func buildNode(f *ssa.Function, fset *token.FileSet, root string, cfg Config) *s
 	return node
 }
 
-// stableFuncID genera un ID stabile per una funzione SSA.
-// Formato: pkgpath.Func o pkgpath.(*Type).Method
-func stableFuncID(f *ssa.Function) string {
-	if f == nil {
-		return ""
+// symbolPkg restituisce il package della dichiarazione a cui risale f
+// (vedi ids.SymbolRef): per i metodi promossi è quello del tipo embedded.
+func symbolPkg(f *ssa.Function) string {
+	for f.Parent() != nil {
+		f = f.Parent()
 	}
-
-	// Builtins e funzioni senza package
-	if f.Pkg == nil || f.Pkg.Pkg == nil {
-		if f.Name() != "" {
-			return f.Name()
-		}
-		return f.String()
+	if obj := f.Object(); obj != nil && obj.Pkg() != nil {
+		return obj.Pkg().Path()
 	}
-
-	pkg := f.Pkg.Pkg.Path()
-	name := f.Name()
-
-	// Receiver per metodi
-	if f.Signature != nil && f.Signature.Recv() != nil {
-		r := f.Signature.Recv()
-		t := r.Type().String()
-		// Normalizza il tipo receiver
-		// es: *example.com/pkg.Type → (*Type)
-		t = normalizeReceiverType(t, pkg)
-		return fmt.Sprintf("%s.%s.%s", pkg, t, name)
-	}
-
-	return fmt.Sprintf("%s.%s", pkg, name)
-}
-
-// normalizeReceiverType normalizza il tipo receiver per l'ID.
-func normalizeReceiverType(t, pkg string) string {
-	// Rimuovi il package path se presente
-	if strings.HasPrefix(t, "*") {
-		inner := t[1:]
-		if idx := strings.LastIndex(inner, "."); idx >= 0 {
-			inner = inner[idx+1:]
-		}
-		return "(*" + inner + ")"
-	}
-	if idx := strings.LastIndex(t, "."); idx >= 0 {
-		return t[idx+1:]
-	}
-	return t
+	return ""
 }
 
 // ============================================================================
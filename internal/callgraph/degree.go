@@ -0,0 +1,49 @@
+package callgraph
+
+import (
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// AnnotateDegrees popola FanIn e FanOut di ogni nodo a partire dagli archi.
+// Se withReach è true calcola anche TransitiveReach (numero di nodi distinti
+// raggiungibili dal nodo, escluso il nodo stesso).
+func AnnotateDegrees(cg *schema.CLDKCallGraph, withReach bool) {
+	if cg == nil {
+		return
+	}
+
+	in := make(map[string]int, len(cg.Nodes))
+	out := make(map[string]int, len(cg.Nodes))
+	adj := make(map[string][]string, len(cg.Nodes))
+	for _, e := range cg.Edges {
+		out[e.Source]++
+		in[e.Target]++
+		adj[e.Source] = append(adj[e.Source], e.Target)
+	}
+
+	for i := range cg.Nodes {
+		n := &cg.Nodes[i]
+		n.FanIn = in[n.ID]
+		n.FanOut = out[n.ID]
+		if withReach {
+			n.TransitiveReach = reachSize(n.ID, adj)
+		}
+	}
+}
+
+// reachSize conta i nodi raggiungibili da start con una BFS.
+func reachSize(start string, adj map[string][]string) int {
+	visited := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range adj[cur] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return len(visited) - 1
+}
@@ -0,0 +1,159 @@
+package callgraph
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Update aggiorna prev, costruito su una versione precedente del progetto,
+// ricostruendo solo i package in affected (i package modificati e quelli
+// che li importano, vedi LoadResult.Dependents): l'SSA è costruito per
+// questi soli package, le altre dipendenze dai tipi.
+//
+// Di prev sono rimossi i nodi dei package in affected e gli archi che
+// partono da essi, sostituiti da quelli ricostruiti; gli archi degli altri
+// package restano, salvo quelli verso funzioni che non esistono più. Un
+// package non in affected raggiunge quelli modificati solo con chiamate
+// dinamiche, quindi i nuovi target di quelle chiamate (es. un nuovo metodo
+// che implementa un'interfaccia) non sono aggiunti: per un grafo esatto
+// serve una ricostruzione completa.
+//
+// Se la ricostruzione ripiega su CHA (nessun main tra i package in
+// affected), Algorithm del risultato è quello ricostruito, es.
+// "cha-fallback", e cfg.OnFallback riceve un issue CALLGRAPH_FALLBACK.
+func Update(prev *schema.CLDKCallGraph, result *loader.LoadResult, affected map[string]bool, cfg Config) (*schema.CLDKCallGraph, error) {
+	if prev == nil {
+		return nil, fmt.Errorf("previous analysis has no call graph")
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = BaseAlgorithm(prev.Algorithm)
+	}
+
+	var pkgs []*packages.Package
+	for _, pkg := range result.Packages {
+		if pkg != nil && affected[pkg.PkgPath] {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	// Funzioni ancora esistenti nei package ricostruiti: gli archi verso
+	// di esse dai package invariati restano validi
+	live := make(map[string]*ssa.Function)
+	fresh := &schema.CLDKCallGraph{}
+	var part *loader.LoadResult
+	if len(pkgs) > 0 {
+		part = loader.SubsetSSA(result, pkgs)
+		for f := range ssautil.AllFunctions(part.SSAProgram) {
+			if f.Pkg != nil && f.Pkg.Pkg != nil && affected[f.Pkg.Pkg.Path()] {
+				if id := ids.SSAFunc(f); id != "" {
+					live[id] = f
+				}
+			}
+		}
+		cg, err := Build(part, cfg)
+		if err != nil {
+			return nil, err
+		}
+		fresh = cg
+	}
+
+	pkgOf := make(map[string]string, len(prev.Nodes)+len(fresh.Nodes))
+	for _, n := range prev.Nodes {
+		pkgOf[n.ID] = n.Package
+	}
+	for _, n := range fresh.Nodes {
+		pkgOf[n.ID] = n.Package
+	}
+
+	// Senza main tra i package ricostruiti RTA non ha radici e Build
+	// ripiega su CHA: il grafo ne eredita l'algoritmo, meno preciso
+	algo := prev.Algorithm
+	if fresh.Algorithm != prev.Algorithm && strings.HasPrefix(fresh.Algorithm, "cha-fallback") {
+		algo = fresh.Algorithm
+		// il panic di RTA è già segnalato da Build
+		if fresh.Algorithm == "cha-fallback" && cfg.OnFallback != nil {
+			cfg.OnFallback(schema.Issue{
+				Severity: "warning",
+				Code:     CodeFallback,
+				Message:  fmt.Sprintf("no main package among the %d rebuilt packages, their edges are built with CHA instead of %s: less precise, more edges", len(pkgs), prev.Algorithm),
+			})
+		}
+	}
+
+	out := &schema.CLDKCallGraph{
+		Algorithm: algo,
+		Nodes:     []schema.CLDKCGNode{},
+		Edges:     []schema.CLDKCGEdge{},
+	}
+	for _, n := range prev.Nodes {
+		if !affected[n.Package] {
+			n.FanIn, n.FanOut, n.TransitiveReach = 0, 0, 0
+			out.Nodes = append(out.Nodes, n)
+		}
+	}
+	for _, e := range prev.Edges {
+		if affected[pkgOf[e.Source]] {
+			continue
+		}
+		if affected[pkgOf[e.Target]] && live[e.Target] == nil {
+			continue
+		}
+		out.Edges = append(out.Edges, e)
+	}
+
+	// Dal grafo ricostruito servono solo gli archi uscenti dai package
+	// ricostruiti e i loro estremi
+	patch := &schema.CLDKCallGraph{}
+	used := make(map[string]bool)
+	for _, e := range fresh.Edges {
+		if affected[pkgOf[e.Source]] {
+			patch.Edges = append(patch.Edges, e)
+			used[e.Source], used[e.Target] = true, true
+		}
+	}
+	for _, e := range out.Edges {
+		used[e.Target] = true
+	}
+	for _, n := range fresh.Nodes {
+		if used[n.ID] {
+			patch.Nodes = append(patch.Nodes, n)
+			delete(used, n.ID)
+		}
+	}
+	// Funzioni ricostruite raggiunte solo dagli archi conservati
+	for _, n := range out.Nodes {
+		delete(used, n.ID)
+	}
+	for id := range used {
+		if f := live[id]; f != nil {
+			patch.Nodes = append(patch.Nodes, *buildNode(f, part.Fset, part, cfg))
+		}
+	}
+	Merge(out, patch)
+
+	AnnotateDegrees(out, cfg.Reach)
+	return out, nil
+}
+
+// BaseAlgorithm riduce l'algoritmo registrato in un call graph (es.
+// "cha-fallback", "rta-per-package") al valore di --cg che lo ha prodotto.
+func BaseAlgorithm(algo string) string {
+	// CHA di ripiego: --cg era rta
+	if strings.HasPrefix(algo, "cha-fallback") {
+		return "rta"
+	}
+	for _, a := range []string{"cha", "rta", "vta", AlgorithmStaticApprox} {
+		if algo == a || strings.HasPrefix(algo, a+"-") {
+			return a
+		}
+	}
+	return "rta"
+}
@@ -0,0 +1,53 @@
+package callgraph
+
+import (
+	"sort"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Merge unisce src in dst deduplicando nodi (per ID) e archi (per coppia
+// source→target). Fan-in/fan-out vanno ricalcolati con AnnotateDegrees
+// dopo l'ultimo merge.
+func Merge(dst, src *schema.CLDKCallGraph) {
+	if dst == nil || src == nil {
+		return
+	}
+
+	nodes := make(map[string]bool, len(dst.Nodes))
+	for _, n := range dst.Nodes {
+		nodes[n.ID] = true
+	}
+	edges := make(map[string]int, len(dst.Edges)) // arco → indice in dst.Edges
+	for i, e := range dst.Edges {
+		edges[e.Source+"→"+e.Target] = i
+	}
+
+	for _, n := range src.Nodes {
+		if !nodes[n.ID] {
+			nodes[n.ID] = true
+			dst.Nodes = append(dst.Nodes, n)
+		}
+	}
+	for _, e := range src.Edges {
+		key := e.Source + "→" + e.Target
+		i, ok := edges[key]
+		if !ok {
+			edges[key] = len(dst.Edges)
+			dst.Edges = append(dst.Edges, e)
+		} else if dst.Edges[i].DeclaredTarget == "" {
+			// un call site dinamico in src basta per il metodo d'interfaccia
+			dst.Edges[i].DeclaredTarget = e.DeclaredTarget
+		}
+	}
+
+	sort.Slice(dst.Nodes, func(i, j int) bool {
+		return dst.Nodes[i].ID < dst.Nodes[j].ID
+	})
+	sort.Slice(dst.Edges, func(i, j int) bool {
+		if dst.Edges[i].Source == dst.Edges[j].Source {
+			return dst.Edges[i].Target < dst.Edges[j].Target
+		}
+		return dst.Edges[i].Source < dst.Edges[j].Source
+	})
+}
@@ -0,0 +1,297 @@
+package callgraph
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// AlgorithmStaticApprox costruisce il call graph dall'AST tipato, senza SSA.
+const AlgorithmStaticApprox = "static-approx"
+
+// buildStatic costruisce un call graph approssimato dai soli TypesInfo dei
+// package del progetto: le chiamate a funzioni e metodi concreti sono
+// risolte staticamente, quelle a metodi d'interfaccia verso tutti i tipi
+// del progetto che implementano l'interfaccia (come CHA ristretto al
+// progetto). Rispetto ai grafi SSA mancano le chiamate tramite valori
+// funzione e le closure sono fuse nella funzione che le contiene; le
+// chiamate negli inizializzatori di variabili package-level partono da
+// "pkg.init".
+func buildStatic(result *loader.LoadResult, cfg Config) (*schema.CLDKCallGraph, error) {
+	b := &staticBuilder{
+		result: result,
+		cfg:    cfg,
+		nodes:  make(map[string]*schema.CLDKCGNode),
+		edges:  make(map[string]schema.CLDKCGEdge),
+		sites:  make(map[string][]schema.CLDKPosition),
+		impls:  make(map[*types.Func][]*types.Func),
+	}
+	for _, pkg := range result.Packages {
+		if pkg == nil || pkg.Types == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		b.collectTypes(pkg)
+	}
+	for _, pkg := range result.Packages {
+		if pkg == nil || pkg.Types == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		b.visitPackage(pkg)
+	}
+
+	out := &schema.CLDKCallGraph{
+		Algorithm: AlgorithmStaticApprox,
+		Nodes:     []schema.CLDKCGNode{},
+		Edges:     []schema.CLDKCGEdge{},
+	}
+	for _, n := range b.nodes {
+		out.Nodes = append(out.Nodes, *n)
+	}
+	sort.Slice(out.Nodes, func(i, j int) bool { return out.Nodes[i].ID < out.Nodes[j].ID })
+	for key, e := range b.edges {
+		setCallSites(&e, b.sites[key], cfg.MaxCallSites)
+		out.Edges = append(out.Edges, e)
+	}
+	sort.Slice(out.Edges, func(i, j int) bool {
+		if out.Edges[i].Source == out.Edges[j].Source {
+			return out.Edges[i].Target < out.Edges[j].Target
+		}
+		return out.Edges[i].Source < out.Edges[j].Source
+	})
+	AnnotateDegrees(out, cfg.Reach)
+	return out, nil
+}
+
+type staticBuilder struct {
+	result *loader.LoadResult
+	cfg    Config
+
+	named []*types.Named                // tipi concreti del progetto
+	impls map[*types.Func][]*types.Func // metodo d'interfaccia → implementazioni
+	nodes map[string]*schema.CLDKCGNode
+	edges map[string]schema.CLDKCGEdge
+	sites map[string][]schema.CLDKPosition // arco → call site distinti
+}
+
+// collectTypes registra i tipi concreti dichiarati nel package.
+func (b *staticBuilder) collectTypes(pkg *packages.Package) {
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		if n, ok := tn.Type().(*types.Named); ok && !types.IsInterface(n) && n.TypeParams().Len() == 0 {
+			b.named = append(b.named, n)
+		}
+	}
+}
+
+// visitPackage aggiunge gli archi delle chiamate nei file del package.
+func (b *staticBuilder) visitPackage(pkg *packages.Package) {
+	initID := ids.Func(pkg.PkgPath, "init")
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				fn, ok := pkg.TypesInfo.Defs[d.Name].(*types.Func)
+				if !ok || d.Body == nil {
+					continue
+				}
+				caller := ids.Object(fn)
+				if d.Recv == nil && d.Name.Name == "init" {
+					caller = initID
+				}
+				b.visitBody(pkg, d.Body, caller, func() *schema.CLDKCGNode { return b.funcNode(fn) })
+			case *ast.GenDecl:
+				if d.Tok != token.VAR {
+					continue
+				}
+				b.visitBody(pkg, d, initID, func() *schema.CLDKCGNode {
+					return &schema.CLDKCGNode{ID: initID, QualifiedName: initID, Package: pkg.PkgPath, Name: "init", Kind: "function"}
+				})
+			}
+		}
+	}
+}
+
+// visitBody aggiunge un arco da caller per ogni chiamata risolta in root.
+// newCaller costruisce il nodo del chiamante alla prima chiamata.
+func (b *staticBuilder) visitBody(pkg *packages.Package, root ast.Node, caller string, newCaller func() *schema.CLDKCGNode) {
+	kinds := make(map[*ast.CallExpr]string)
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.GoStmt:
+			kinds[s.Call] = "go"
+		case *ast.DeferStmt:
+			kinds[s.Call] = "defer"
+		case *ast.CallExpr:
+			fn, iface := calledFunc(pkg.TypesInfo, s)
+			if fn == nil {
+				return true
+			}
+			kind := kinds[s]
+			if kind == "" {
+				kind = "call"
+			}
+			site := b.position(s.Lparen)
+			if !iface {
+				b.addEdge(caller, newCaller, fn, "", kind, site)
+				return true
+			}
+			declared := ids.InterfaceMethod(fn)
+			for _, impl := range b.implementations(fn) {
+				b.addEdge(caller, newCaller, impl, declared, kind, site)
+			}
+		}
+		return true
+	})
+}
+
+// calledFunc risolve la funzione chiamata da call; iface è true se è un
+// metodo d'interfaccia. Restituisce nil per conversioni, builtin e
+// chiamate tramite valori funzione.
+func calledFunc(info *types.Info, call *ast.CallExpr) (fn *types.Func, iface bool) {
+	fun := ast.Unparen(call.Fun)
+	switch f := fun.(type) {
+	case *ast.IndexExpr: // istanziazione esplicita f[T]
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	var obj types.Object
+	switch f := fun.(type) {
+	case *ast.Ident:
+		obj = info.Uses[f]
+	case *ast.SelectorExpr:
+		if sel := info.Selections[f]; sel != nil {
+			if sel.Kind() == types.FieldVal {
+				return nil, false
+			}
+			obj = sel.Obj()
+		} else {
+			obj = info.Uses[f.Sel]
+		}
+	}
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return nil, false
+	}
+	return fn, isInterfaceMethod(fn)
+}
+
+// isInterfaceMethod indica se fn è un metodo astratto di un'interfaccia.
+func isInterfaceMethod(fn *types.Func) bool {
+	sig, ok := fn.Type().(*types.Signature)
+	return ok && sig.Recv() != nil && types.IsInterface(sig.Recv().Type())
+}
+
+// implementations restituisce i metodi dei tipi del progetto che
+// implementano l'interfaccia di m (con receiver valore o puntatore).
+func (b *staticBuilder) implementations(m *types.Func) []*types.Func {
+	if out, ok := b.impls[m]; ok {
+		return out
+	}
+	var out []*types.Func
+	recv := m.Type().(*types.Signature).Recv().Type()
+	iface, ok := recv.Underlying().(*types.Interface)
+	if n, isNamed := types.Unalias(recv).(*types.Named); ok && (!isNamed || n.TypeParams().Len() == 0) {
+		seen := make(map[*types.Func]bool)
+		for _, t := range b.named {
+			for _, typ := range []types.Type{t, types.NewPointer(t)} {
+				if !types.Implements(typ, iface) {
+					continue
+				}
+				obj, _, _ := types.LookupFieldOrMethod(typ, true, m.Pkg(), m.Name())
+				if impl, ok := obj.(*types.Func); ok && !seen[impl] {
+					seen[impl] = true
+					out = append(out, impl)
+				}
+				break
+			}
+		}
+	}
+	b.impls[m] = out
+	return out
+}
+
+// addEdge registra l'arco caller→callee e il suo call site.
+func (b *staticBuilder) addEdge(caller string, newCaller func() *schema.CLDKCGNode, callee *types.Func, declared, kind string, site *schema.CLDKPosition) {
+	target := ids.Object(callee)
+	src := b.nodes[caller]
+	if src == nil {
+		src = newCaller()
+	}
+	var dstPkg string
+	if callee.Pkg() != nil {
+		dstPkg = callee.Pkg().Path()
+	}
+	if !b.cfg.Packages.Empty() && !b.cfg.Packages.Allows(src.Package) && !b.cfg.Packages.Allows(dstPkg) {
+		return
+	}
+	b.nodes[caller] = src
+	if b.nodes[target] == nil {
+		b.nodes[target] = b.funcNode(callee)
+	}
+
+	key := caller + "→" + target
+	if site != nil && !containsPosition(b.sites[key], *site) {
+		b.sites[key] = append(b.sites[key], *site)
+	}
+	if _, ok := b.edges[key]; !ok {
+		b.edges[key] = schema.CLDKCGEdge{
+			Source:         caller,
+			Target:         target,
+			CallSite:       site,
+			Kind:           kind,
+			Category:       categorizeAPI(target),
+			DeclaredTarget: declared,
+		}
+	} else if e := b.edges[key]; e.DeclaredTarget == "" && declared != "" {
+		// il primo call site della coppia era una chiamata diretta
+		e.DeclaredTarget = declared
+		b.edges[key] = e
+	}
+}
+
+// funcNode costruisce il nodo di una funzione o di un metodo concreto.
+func (b *staticBuilder) funcNode(fn *types.Func) *schema.CLDKCGNode {
+	fn = fn.Origin()
+	id := ids.Object(fn)
+	node := &schema.CLDKCGNode{ID: id, QualifiedName: id, Name: fn.Name(), Kind: "function"}
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		node.Kind = "method"
+	}
+	if fn.Pkg() != nil {
+		node.Package = fn.Pkg().Path()
+		if b.result.ProjectPackages()[node.Package] {
+			node.SymbolRef = id
+		}
+	}
+	if b.cfg.EmitPositions != "minimal" {
+		node.Position = b.position(fn.Pos())
+	}
+	return node
+}
+
+// position converte pos in una posizione relativa alla root (nil se non
+// valida).
+func (b *staticBuilder) position(pos token.Pos) *schema.CLDKPosition {
+	p := b.result.Fset.Position(pos)
+	if !p.IsValid() {
+		return nil
+	}
+	file := p.Filename
+	if rel, err := filepath.Rel(b.result.Root, file); err == nil {
+		file = filepath.ToSlash(rel)
+	}
+	return &schema.CLDKPosition{File: file, StartLine: p.Line, StartColumn: p.Column}
+}
@@ -0,0 +1,168 @@
+package callgraph
+
+import (
+	"sort"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// CollapseWrappers toglie dal call graph i nodi dei wrapper sottili (ID in
+// wrappers, vedi symbols.Wrappers): ogni arco chiamante→wrapper è
+// sostituito da un arco chiamante→callee per ogni callee del wrapper, con i
+// call site del chiamante e il kind della chiamata originale. Gli archi che
+// diventano uguali sono fusi sommando Count (CallSites fino a
+// maxCallSites). Un wrapper senza callee nel grafo resta. Le catene di
+// wrapper si riducono al primo callee che non è un wrapper. Fan-in e
+// fan-out sono ricalcolati (TransitiveReach se withReach).
+func CollapseWrappers(cg *schema.CLDKCallGraph, wrappers map[string]string, maxCallSites int, withReach bool) *schema.CLDKCallGraph {
+	if cg == nil || len(wrappers) == 0 {
+		return cg
+	}
+
+	type pair struct{ from, to string }
+	edges := make(map[pair]*schema.CLDKCGEdge, len(cg.Edges))
+	out := make(map[string][]string) // source → target, nell'ordine degli archi
+	in := make(map[string][]string)
+	for i := range cg.Edges {
+		e := cg.Edges[i]
+		p := pair{e.Source, e.Target}
+		if prev := edges[p]; prev != nil {
+			merge(prev, &e, maxCallSites)
+			continue
+		}
+		edges[p] = &e
+		out[e.Source] = append(out[e.Source], e.Target)
+		in[e.Target] = append(in[e.Target], e.Source)
+	}
+	remove := func(list []string, id string) []string {
+		kept := list[:0]
+		for _, x := range list {
+			if x != id {
+				kept = append(kept, x)
+			}
+		}
+		return kept
+	}
+
+	var order []string
+	for _, n := range cg.Nodes {
+		if _, ok := wrappers[n.ID]; ok {
+			order = append(order, n.ID)
+		}
+	}
+	sort.Strings(order)
+
+	removed := make(map[string]bool)
+	for _, w := range order {
+		var callees []string
+		for _, t := range out[w] {
+			if t != w {
+				callees = append(callees, t)
+			}
+		}
+		if len(callees) == 0 {
+			continue
+		}
+		for _, caller := range in[w] {
+			if caller == w {
+				continue
+			}
+			call := edges[pair{caller, w}]
+			for _, t := range callees {
+				inner := edges[pair{w, t}]
+				e := *call
+				e.Target, e.Category, e.DeclaredTarget = t, inner.Category, inner.DeclaredTarget
+				if prev := edges[pair{caller, t}]; prev != nil {
+					merge(prev, &e, maxCallSites)
+					continue
+				}
+				edges[pair{caller, t}] = &e
+				out[caller] = append(out[caller], t)
+				in[t] = append(in[t], caller)
+			}
+			delete(edges, pair{caller, w})
+			out[caller] = remove(out[caller], w)
+		}
+		for _, t := range out[w] {
+			delete(edges, pair{w, t})
+			in[t] = remove(in[t], w)
+		}
+		delete(out, w)
+		delete(in, w)
+		removed[w] = true
+	}
+	if len(removed) == 0 {
+		return cg
+	}
+
+	res := &schema.CLDKCallGraph{
+		Algorithm:         cg.Algorithm,
+		Granularity:       cg.Granularity,
+		CollapsedWrappers: len(removed),
+		Nodes:             make([]schema.CLDKCGNode, 0, len(cg.Nodes)-len(removed)),
+		Edges:             make([]schema.CLDKCGEdge, 0, len(edges)),
+	}
+	for _, n := range cg.Nodes {
+		if !removed[n.ID] {
+			res.Nodes = append(res.Nodes, n)
+		}
+	}
+	for _, e := range edges {
+		res.Edges = append(res.Edges, *e)
+	}
+	sort.Slice(res.Edges, func(i, j int) bool {
+		a, b := res.Edges[i], res.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+	AnnotateDegrees(res, withReach)
+	return res
+}
+
+// merge aggiunge a dst i call site di src, con i CallSites ordinati e
+// limitati a max; CallSite resta quello di dst.
+func merge(dst, src *schema.CLDKCGEdge, max int) {
+	count := func(e *schema.CLDKCGEdge) int {
+		if e.Count == 0 {
+			return 1
+		}
+		return e.Count
+	}
+	dst.Count = count(dst) + count(src)
+	if max <= 0 {
+		return
+	}
+	var sites []schema.CLDKPosition
+	for _, e := range []*schema.CLDKCGEdge{dst, src} {
+		if len(e.CallSites) > 0 {
+			sites = append(sites, e.CallSites...)
+		} else if e.CallSite != nil {
+			sites = append(sites, *e.CallSite)
+		}
+	}
+	var uniq []schema.CLDKPosition
+	for _, p := range sites {
+		if !containsPosition(uniq, p) {
+			uniq = append(uniq, p)
+		}
+	}
+	if len(uniq) < 2 {
+		return
+	}
+	sort.Slice(uniq, func(i, j int) bool {
+		a, b := uniq[i], uniq[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.StartColumn < b.StartColumn
+	})
+	if len(uniq) > max {
+		uniq = uniq[:max]
+	}
+	dst.CallSites = uniq
+}
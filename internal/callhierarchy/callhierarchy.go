@@ -0,0 +1,234 @@
+// Package callhierarchy converte il call graph nelle gerarchie di chiamate
+// del Language Server Protocol (vedi schema.CLDKCallHierarchy), così un
+// plugin IDE le legge direttamente dall'output dell'analyzer.
+package callhierarchy
+
+import (
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Build costruisce le gerarchie di tutti i nodi con posizione del call
+// graph di analysis; i nodi senza posizione (es. --emit-positions minimal o
+// granularità pkg) non hanno un URI e sono omessi anche come chiamanti e
+// chiamati. I sorgenti, letti da fsys (i sorgenti visti dal loader, con
+// radice in Metadata.ProjectPath; nil = dal disco), servono a convertire le
+// colonne in unità UTF-16 e a delimitare il nome nei call site; se non sono
+// leggibili le colonne restano in byte.
+func Build(analysis *schema.CLDKAnalysis, fsys fs.FS) *schema.CLDKCallHierarchy {
+	root := analysis.Metadata.ProjectPath
+	ch := &schema.CLDKCallHierarchy{
+		SchemaVersion: schema.SchemaVersion,
+		Project:       root,
+		RootURI:       fileURI(root),
+		Functions:     make(map[string]*schema.CallHierarchyEntry),
+	}
+	cg := analysis.CallGraph
+	if cg == nil {
+		return ch
+	}
+
+	src := &sources{root: root, fsys: fsys, files: make(map[string][]string)}
+	items := make(map[string]schema.LSPCallHierarchyItem, len(cg.Nodes))
+	for _, n := range cg.Nodes {
+		if n.Position == nil || n.Position.File == "" {
+			continue
+		}
+		items[n.ID] = item(n, declaration(analysis.SymbolTable, n), src)
+		ch.Functions[n.ID] = &schema.CallHierarchyEntry{
+			Item:     items[n.ID],
+			Incoming: []schema.LSPCallHierarchyIncomingCall{},
+			Outgoing: []schema.LSPCallHierarchyOutgoingCall{},
+		}
+	}
+
+	// Più archi tra la stessa coppia (call, defer, go) diventano una sola
+	// chiamata con tutti i call site
+	type pair struct{ source, target string }
+	ranges := make(map[pair][]schema.LSPRange)
+	var pairs []pair
+	names := make(map[string]string, len(cg.Nodes))
+	for _, n := range cg.Nodes {
+		names[n.ID] = n.Name
+	}
+	for _, e := range cg.Edges {
+		if _, ok := items[e.Source]; !ok {
+			continue
+		}
+		if _, ok := items[e.Target]; !ok {
+			continue
+		}
+		p := pair{e.Source, e.Target}
+		if _, ok := ranges[p]; !ok {
+			pairs = append(pairs, p)
+			ranges[p] = []schema.LSPRange{}
+		}
+		sites := e.CallSites
+		if len(sites) == 0 && e.CallSite != nil {
+			sites = []schema.CLDKPosition{*e.CallSite}
+		}
+		for _, s := range sites {
+			ranges[p] = append(ranges[p], src.callRange(s, names[e.Target]))
+		}
+	}
+	for _, p := range pairs {
+		rs := sortRanges(ranges[p])
+		ch.Functions[p.target].Incoming = append(ch.Functions[p.target].Incoming, schema.LSPCallHierarchyIncomingCall{From: items[p.source], FromRanges: rs})
+		ch.Functions[p.source].Outgoing = append(ch.Functions[p.source].Outgoing, schema.LSPCallHierarchyOutgoingCall{To: items[p.target], FromRanges: rs})
+	}
+	for _, e := range ch.Functions {
+		sort.SliceStable(e.Incoming, func(i, j int) bool { return e.Incoming[i].From.Data.ID < e.Incoming[j].From.Data.ID })
+		sort.SliceStable(e.Outgoing, func(i, j int) bool { return e.Outgoing[i].To.Data.ID < e.Outgoing[j].To.Data.ID })
+	}
+	return ch
+}
+
+// declaration restituisce la dichiarazione del nodo nella symbol table, se
+// presente.
+func declaration(st *schema.CLDKSymbolTable, n schema.CLDKCGNode) *schema.CLDKCallable {
+	if st == nil || n.SymbolRef == "" {
+		return nil
+	}
+	pkg := st.Packages[n.Package]
+	if pkg == nil {
+		return nil
+	}
+	return pkg.CallableDeclarations[n.SymbolRef]
+}
+
+func item(n schema.CLDKCGNode, decl *schema.CLDKCallable, src *sources) schema.LSPCallHierarchyItem {
+	kind := schema.LSPSymbolKindFunction
+	switch n.Kind {
+	case "method":
+		kind = schema.LSPSymbolKindMethod
+	case "package":
+		kind = schema.LSPSymbolKindPackage
+	}
+	pos := *n.Position
+	name := n.Name
+	if strings.Contains(name, "$") {
+		name = "func" // funzione anonima: la posizione è quella di "func"
+	}
+	start := src.position(pos.File, pos.StartLine, pos.StartColumn)
+	sel := schema.LSPRange{Start: start, End: start}
+	sel.End.Character += utf16Len(name)
+
+	rng := sel
+	if decl != nil && decl.Position != nil && decl.EndPosition != nil && decl.Position.File == pos.File {
+		rng = schema.LSPRange{
+			Start: src.position(pos.File, decl.Position.StartLine, decl.Position.StartColumn),
+			End:   src.position(pos.File, decl.EndPosition.StartLine, decl.EndPosition.StartColumn),
+		}
+	}
+	return schema.LSPCallHierarchyItem{
+		Name:           n.Name,
+		Kind:           kind,
+		Detail:         n.Package + " • " + path.Base(pos.File),
+		URI:            fileURI(filepath.Join(src.root, filepath.FromSlash(pos.File))),
+		Range:          rng,
+		SelectionRange: sel,
+		Data:           &schema.LSPCallHierarchyData{ID: n.ID},
+	}
+}
+
+// sources legge le righe dei sorgenti una sola volta per file.
+type sources struct {
+	root  string
+	fsys  fs.FS
+	files map[string][]string // file relativo → righe (nil = non leggibile)
+}
+
+func (s *sources) line(file string, line int) (string, bool) {
+	lines, ok := s.files[file]
+	if !ok {
+		data, err := loader.ReadFile(s.fsys, s.root, filepath.Join(s.root, filepath.FromSlash(file)))
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		s.files[file] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return "", false
+	}
+	return lines[line-1], true
+}
+
+// position converte riga e colonna in byte (a base 1) in una Position LSP.
+func (s *sources) position(file string, line, col int) schema.LSPPosition {
+	p := schema.LSPPosition{Line: line - 1, Character: col - 1}
+	if text, ok := s.line(file, line); ok && col-1 <= len(text) {
+		p.Character = utf16Len(text[:col-1])
+	}
+	if p.Line < 0 {
+		p.Line = 0
+	}
+	if p.Character < 0 {
+		p.Character = 0
+	}
+	return p
+}
+
+// callRange restituisce il range del nome chiamato in un call site, che
+// punta alla parentesi aperta: "Greet" in "Greet(x)" o "s.Do(x)". Se il
+// nome non precede la parentesi (es. funzioni generiche o valori funzione)
+// il range copre la sola parentesi.
+func (s *sources) callRange(site schema.CLDKPosition, callee string) schema.LSPRange {
+	paren := s.position(site.File, site.StartLine, site.StartColumn)
+	r := schema.LSPRange{Start: paren, End: paren}
+	r.End.Character++
+	text, ok := s.line(site.File, site.StartLine)
+	if !ok || callee == "" || site.StartColumn-1 > len(text) {
+		return r
+	}
+	if before := text[:site.StartColumn-1]; strings.HasSuffix(before, callee) {
+		r.Start.Character = utf16Len(before[:len(before)-len(callee)])
+		r.End = paren
+	}
+	return r
+}
+
+func sortRanges(rs []schema.LSPRange) []schema.LSPRange {
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Start.Line != rs[j].Start.Line {
+			return rs[i].Start.Line < rs[j].Start.Line
+		}
+		return rs[i].Start.Character < rs[j].Start.Character
+	})
+	out := rs[:0]
+	for _, r := range rs {
+		if len(out) == 0 || r != out[len(out)-1] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// utf16Len restituisce la lunghezza di s in unità UTF-16, l'unità delle
+// colonne LSP.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n++
+		if r >= 0x10000 {
+			n++ // coppia surrogata
+		}
+	}
+	return n
+}
+
+// fileURI converte un path assoluto in URI file:// (con la barra iniziale
+// anche per i path Windows, es. file:///C:/src).
+func fileURI(p string) string {
+	p = filepath.ToSlash(p)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
@@ -0,0 +1,164 @@
+// Package clock inventaria le chiamate che rendono il codice dipendente dal
+// tempo o dalla casualità: lettura dell'orologio, attese, timer e ticker,
+// math/rand e crypto/rand.
+package clock
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// timeKinds classifica le funzioni del package time che leggono l'orologio
+// o ne dipendono.
+var timeKinds = map[string]string{
+	"Now":       "now",
+	"Since":     "now",
+	"Until":     "now",
+	"Sleep":     "sleep",
+	"After":     "timer",
+	"AfterFunc": "timer",
+	"NewTimer":  "timer",
+	"NewTicker": "timer",
+	"Tick":      "timer",
+}
+
+// Inventory restituisce le chiamate a time e rand dei package, nil se non
+// ce ne sono. Sono contate solo le chiamate: un riferimento come
+// "var now = time.Now" è già un punto di iniezione. Per math/rand sono
+// incluse le funzioni del package e i metodi di *rand.Rand.
+func Inventory(pkgs []*packages.Package, fset *token.FileSet, root string) *schema.CLDKClockUsage {
+	byPkg := make(map[string]*schema.CLDKClockPackage)
+	seen := make(map[token.Position]bool) // le varianti di test ripetono i file
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil {
+				continue
+			}
+			for _, decl := range file.Decls {
+				function := ""
+				if fn, ok := decl.(*ast.FuncDecl); ok {
+					if obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+						function = ids.Object(obj)
+					}
+				}
+				ast.Inspect(decl, func(n ast.Node) bool {
+					call, ok := n.(*ast.CallExpr)
+					if !ok {
+						return true
+					}
+					fn := callee(call, pkg.TypesInfo)
+					kind := kindOf(fn)
+					if kind == "" {
+						return true
+					}
+					pos := fset.Position(call.Pos())
+					if seen[pos] {
+						return true
+					}
+					seen[pos] = true
+
+					p := byPkg[pkg.PkgPath]
+					if p == nil {
+						p = &schema.CLDKClockPackage{Package: pkg.PkgPath}
+						byPkg[pkg.PkgPath] = p
+					}
+					p.Sites = append(p.Sites, schema.CLDKClockSite{
+						Kind:     kind,
+						Call:     fn.FullName(),
+						Function: function,
+						Position: loader.Position(pos, root),
+					})
+					count(&p.Counts, kind)
+					return true
+				})
+			}
+		}
+	}
+	if len(byPkg) == 0 {
+		return nil
+	}
+
+	usage := &schema.CLDKClockUsage{}
+	for _, p := range byPkg {
+		sort.Slice(p.Sites, func(i, j int) bool {
+			a, b := p.Sites[i].Position, p.Sites[j].Position
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartColumn < b.StartColumn)
+		})
+		for _, s := range p.Sites {
+			count(&usage.Totals, s.Kind)
+		}
+		usage.Packages = append(usage.Packages, *p)
+	}
+	sort.Slice(usage.Packages, func(i, j int) bool { return usage.Packages[i].Package < usage.Packages[j].Package })
+	return usage
+}
+
+// callee restituisce la funzione o il metodo chiamato staticamente, nil per
+// chiamate dinamiche, builtin e conversioni.
+func callee(call *ast.CallExpr, info *types.Info) *types.Func {
+	var id *ast.Ident
+	switch f := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	case *ast.IndexExpr: // funzione generica istanziata
+		if sel, ok := f.X.(*ast.SelectorExpr); ok {
+			id = sel.Sel
+		} else if x, ok := f.X.(*ast.Ident); ok {
+			id = x
+		}
+	}
+	if id == nil {
+		return nil
+	}
+	fn, _ := info.Uses[id].(*types.Func)
+	return fn
+}
+
+// kindOf classifica fn: now, sleep, timer, math_rand, crypto_rand o "".
+func kindOf(fn *types.Func) string {
+	if fn == nil || fn.Pkg() == nil {
+		return ""
+	}
+	switch fn.Pkg().Path() {
+	case "time":
+		if fn.Type().(*types.Signature).Recv() == nil {
+			return timeKinds[fn.Name()]
+		}
+	case "math/rand", "math/rand/v2":
+		return "math_rand"
+	case "crypto/rand":
+		return "crypto_rand"
+	}
+	return ""
+}
+
+func count(c *schema.CLDKClockCounts, kind string) {
+	switch kind {
+	case "now":
+		c.Now++
+	case "sleep":
+		c.Sleep++
+	case "timer":
+		c.Timers++
+	case "math_rand":
+		c.MathRand++
+	case "crypto_rand":
+		c.CryptoRand++
+	}
+}
@@ -0,0 +1,276 @@
+// Package clones trova le funzioni duplicate o quasi duplicate: il corpo di
+// ogni funzione è ridotto a una sequenza di nodi dell'AST normalizzata
+// (commenti e formattazione non contano, identificatori e letterali
+// opzionalmente sì), le sequenze uguali formano gruppi esatti e quelle
+// simili, misurate con la similarità di Jaccard sugli shingle, gruppi di
+// quasi duplicati.
+package clones
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Valori predefiniti di Config.
+const (
+	DefaultMinTokens = 50
+	DefaultThreshold = 0.8
+)
+
+// shingleSize è la lunghezza delle sottosequenze confrontate per i quasi
+// duplicati.
+const shingleSize = 5
+
+// Config regola la ricerca dei cloni.
+type Config struct {
+	MinTokens         int     // corpi con meno nodi sono ignorati
+	Threshold         float64 // similarità minima dei quasi duplicati; 1 = solo cloni esatti
+	IgnoreIdentifiers bool    // identificatori e valori dei letterali non contano
+}
+
+// function è una funzione candidata.
+type function struct {
+	member schema.CLDKCloneMember
+	hash   [sha256.Size]byte
+}
+
+// unit raggruppa le funzioni con la stessa sequenza normalizzata.
+type unit struct {
+	funcs    []*function
+	shingles map[uint64]bool
+}
+
+// Detect restituisce i gruppi di cloni tra le funzioni e i metodi dei
+// package sotto root. I file generati ("Code generated ... DO NOT EDIT.")
+// sono esclusi.
+func Detect(pkgs []*packages.Package, fset *token.FileSet, root string, cfg Config) *schema.CLDKCloneReport {
+	if cfg.MinTokens <= 0 {
+		cfg.MinTokens = DefaultMinTokens
+	}
+	if cfg.Threshold <= 0 || cfg.Threshold > 1 {
+		cfg.Threshold = DefaultThreshold
+	}
+	report := &schema.CLDKCloneReport{
+		IgnoreIdentifiers: cfg.IgnoreIdentifiers,
+		Threshold:         cfg.Threshold,
+		Groups:            []schema.CLDKCloneGroup{},
+	}
+
+	units := make(map[[sha256.Size]byte]*unit)
+	seen := make(map[token.Position]bool) // le varianti di test ripetono i file
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil || ast.IsGenerated(file) || !loader.UnderRoot(fset.Position(file.Pos()).Filename, root) {
+				continue
+			}
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Body == nil || seen[fset.Position(fn.Pos())] {
+					continue
+				}
+				seen[fset.Position(fn.Pos())] = true
+				obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func)
+				if !ok {
+					continue
+				}
+				seq, nodes := normalize(fn, cfg.IgnoreIdentifiers)
+				if nodes < cfg.MinTokens {
+					continue
+				}
+				start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+				f := &function{
+					member: schema.CLDKCloneMember{
+						Callable: ids.Object(obj),
+						Package:  pkg.PkgPath,
+						Tokens:   nodes,
+						Lines:    end.Line - start.Line + 1,
+						Position: loader.Position(start, root),
+					},
+					hash: sha256.Sum256([]byte(strings.Join(seq, "\x00"))),
+				}
+				report.Functions++
+				u := units[f.hash]
+				if u == nil {
+					u = &unit{shingles: shingles(seq)}
+					units[f.hash] = u
+				}
+				u.funcs = append(u.funcs, f)
+			}
+		}
+	}
+
+	list := make([]*unit, 0, len(units))
+	for _, u := range units {
+		sort.Slice(u.funcs, func(i, j int) bool { return memberLess(&u.funcs[i].member, &u.funcs[j].member) })
+		list = append(list, u)
+	}
+	// i riferimenti sono i corpi più grandi, per avere gruppi stabili
+	sort.Slice(list, func(i, j int) bool {
+		if len(list[i].shingles) != len(list[j].shingles) {
+			return len(list[i].shingles) > len(list[j].shingles)
+		}
+		return memberLess(&list[i].funcs[0].member, &list[j].funcs[0].member)
+	})
+
+	assigned := make([]bool, len(list))
+	for i, ref := range list {
+		if assigned[i] {
+			continue
+		}
+		assigned[i] = true
+		group := schema.CLDKCloneGroup{Kind: "exact", Similarity: 1}
+		for _, f := range ref.funcs {
+			m := f.member
+			m.Similarity = 1
+			group.Members = append(group.Members, m)
+		}
+		if cfg.Threshold < 1 {
+			for j := i + 1; j < len(list); j++ {
+				// la similarità di Jaccard non supera il rapporto tra le dimensioni
+				if float64(len(list[j].shingles)) < cfg.Threshold*float64(len(ref.shingles)) {
+					break
+				}
+				if assigned[j] {
+					continue
+				}
+				sim := jaccard(ref.shingles, list[j].shingles)
+				if sim < cfg.Threshold {
+					continue
+				}
+				assigned[j] = true
+				group.Kind = "near"
+				group.Similarity = min(group.Similarity, round(sim))
+				for _, f := range list[j].funcs {
+					m := f.member
+					m.Similarity = round(sim)
+					group.Members = append(group.Members, m)
+				}
+			}
+		}
+		if len(group.Members) < 2 {
+			continue
+		}
+		for _, m := range group.Members[1:] {
+			report.DuplicatedLines += m.Lines
+		}
+		report.Groups = append(report.Groups, group)
+	}
+
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		a, b := duplicated(report.Groups[i]), duplicated(report.Groups[j])
+		if a != b {
+			return a > b
+		}
+		return report.Groups[i].Members[0].Callable < report.Groups[j].Members[0].Callable
+	})
+	return report
+}
+
+// normalize riduce il corpo di fn alla sequenza dei suoi nodi in pre-ordine,
+// con un marcatore di chiusura per la struttura, e conta i nodi. Operatori
+// e parole chiave restano; identificatori e valori dei letterali diventano
+// segnaposto se ignoreIdents.
+func normalize(fn *ast.FuncDecl, ignoreIdents bool) ([]string, int) {
+	var seq []string
+	nodes := 0
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if n == nil {
+			seq = append(seq, ")")
+			return false
+		}
+		nodes++
+		kind := fmt.Sprintf("%T", n)[len("*ast."):]
+		switch x := n.(type) {
+		case *ast.Ident:
+			if ignoreIdents {
+				seq = append(seq, "id")
+			} else {
+				seq = append(seq, "id:"+x.Name)
+			}
+		case *ast.BasicLit:
+			if ignoreIdents {
+				seq = append(seq, "lit:"+x.Kind.String())
+			} else {
+				seq = append(seq, "lit:"+x.Value)
+			}
+		case *ast.BinaryExpr:
+			seq = append(seq, kind+x.Op.String())
+		case *ast.UnaryExpr:
+			seq = append(seq, kind+x.Op.String())
+		case *ast.AssignStmt:
+			seq = append(seq, kind+x.Tok.String())
+		case *ast.IncDecStmt:
+			seq = append(seq, kind+x.Tok.String())
+		case *ast.BranchStmt:
+			seq = append(seq, kind+x.Tok.String())
+		case *ast.RangeStmt:
+			seq = append(seq, kind+x.Tok.String())
+		case *ast.ChanType:
+			seq = append(seq, fmt.Sprintf("%s%d", kind, x.Dir))
+		default:
+			seq = append(seq, kind)
+		}
+		return true
+	})
+	return seq, nodes
+}
+
+// shingles restituisce l'insieme degli hash dei k-gram di seq.
+func shingles(seq []string) map[uint64]bool {
+	out := make(map[uint64]bool)
+	for _, g := range kgrams(seq) {
+		out[g] = true
+	}
+	return out
+}
+
+func jaccard(a, b map[uint64]bool) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	common := 0
+	for s := range a {
+		if b[s] {
+			common++
+		}
+	}
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 1
+	}
+	return float64(common) / float64(union)
+}
+
+func round(f float64) float64 {
+	return float64(int(f*1000+0.5)) / 1000
+}
+
+// duplicated restituisce le righe dei membri di g oltre il primo.
+func duplicated(g schema.CLDKCloneGroup) int {
+	n := 0
+	for _, m := range g.Members[1:] {
+		n += m.Lines
+	}
+	return n
+}
+
+func memberLess(a, b *schema.CLDKCloneMember) bool {
+	if a.Lines != b.Lines {
+		return a.Lines > b.Lines
+	}
+	return a.Callable < b.Callable
+}
@@ -0,0 +1,84 @@
+package clones
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"hash/fnv"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// winnowWindow è il numero di k-gram consecutivi tra cui il winnowing
+// sceglie un hash: due corpi con un tratto comune di almeno
+// shingleSize+winnowWindow-1 elementi della sequenza normalizzata hanno
+// almeno un hash in comune.
+const winnowWindow = 4
+
+// Fingerprint restituisce l'impronta strutturale del corpo di fn, nil per
+// le funzioni senza corpo. La sequenza è quella di Detect con
+// IgnoreIdentifiers: Hash sono i primi 8 byte dello SHA-256 della sequenza,
+// Winnow gli hash FNV-1a a 64 bit dei k-gram di shingleSize nodi scelti dal
+// winnowing (Schleimer et al., 2003), cioè il minimo di ogni finestra di
+// winnowWindow k-gram, il più a destra a parità, senza ripetere la stessa
+// posizione. Le impronte sono stabili tra esecuzioni e versioni dello
+// strumento, finché la normalizzazione non cambia.
+func Fingerprint(fn *ast.FuncDecl) *schema.CLDKFingerprint {
+	if fn == nil || fn.Body == nil {
+		return nil
+	}
+	seq, nodes := normalize(fn, true)
+	sum := sha256.Sum256([]byte(strings.Join(seq, "\x00")))
+	fp := &schema.CLDKFingerprint{Hash: hex.EncodeToString(sum[:8]), Tokens: nodes}
+
+	grams := kgrams(seq)
+	if len(grams) <= winnowWindow {
+		// una sola finestra
+		fp.Winnow = []string{hexHash(minHash(grams))}
+		return fp
+	}
+	last := -1
+	for start := 0; start+winnowWindow <= len(grams); start++ {
+		pick := start
+		for i := start + 1; i < start+winnowWindow; i++ {
+			if grams[i] <= grams[pick] {
+				pick = i
+			}
+		}
+		if pick != last {
+			fp.Winnow = append(fp.Winnow, hexHash(grams[pick]))
+			last = pick
+		}
+	}
+	return fp
+}
+
+// kgrams restituisce gli hash dei k-gram di seq, nell'ordine (uno solo se
+// seq è più corta di shingleSize).
+func kgrams(seq []string) []uint64 {
+	var out []uint64
+	h := fnv.New64a()
+	for i := 0; i+shingleSize <= len(seq) || (i == 0 && len(seq) > 0); i++ {
+		h.Reset()
+		for _, s := range seq[i:min(i+shingleSize, len(seq))] {
+			h.Write([]byte(s))
+			h.Write([]byte{0})
+		}
+		out = append(out, h.Sum64())
+	}
+	return out
+}
+
+func minHash(grams []uint64) uint64 {
+	m := grams[0]
+	for _, g := range grams[1:] {
+		m = min(m, g)
+	}
+	return m
+}
+
+func hexHash(h uint64) string {
+	return fmt.Sprintf("%016x", h)
+}
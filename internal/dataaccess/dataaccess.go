@@ -0,0 +1,378 @@
+// Package dataaccess inventaria l'accesso ai dati del progetto: i file .sql
+// dell'albero (migrazioni, schemi, query con nome di sqlc), le
+// configurazioni di sqlc e i simboli Go che contengono SQL (costanti e
+// variabili stringa, funzioni generate da sqlc, variabili //go:embed di
+// file .sql), collegando ogni simbolo al file e alla query da cui deriva.
+package dataaccess
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Tipi di simbolo Go.
+const (
+	SymbolConstant = "constant"
+	SymbolVariable = "variable"
+	SymbolFunction = "function"
+	SymbolEmbed    = "embed"
+)
+
+// sqlcHeader riconosce l'header dei file generati da sqlc.
+var sqlcHeader = regexp.MustCompile(`^Code generated by sqlc\. DO NOT EDIT\.$`)
+
+// queryRef individua una query con nome in un file .sql.
+type queryRef struct {
+	file  int // indice in SQLFiles
+	query int // indice in Queries
+}
+
+type inventory struct {
+	fset  *token.FileSet
+	root  string
+	fsys  fs.FS // sorgenti del progetto, con radice in root
+	da    *schema.CLDKDataAccess
+	named map[string][]queryRef // nome della query → occorrenze
+	files map[string]int        // path relativo → indice in SQLFiles
+}
+
+// Inventory restituisce l'inventario dei dati del progetto in root, nil se
+// non ci sono file .sql, configurazioni di sqlc né SQL nel codice Go. I
+// file sono letti da fsys, i sorgenti del progetto con radice in root
+// (loader.LoadResult.FS), così da coprire anche --root-archive e overlay.
+// excludeDirs sono nomi di directory da saltare oltre a quelle nascoste,
+// vendor e testdata. Gli errori di lettura delle configurazioni di sqlc
+// sono restituiti come issue.
+func Inventory(pkgs []*packages.Package, fset *token.FileSet, root string, fsys fs.FS, excludeDirs []string, resources *schema.CLDKResources) (*schema.CLDKDataAccess, []schema.Issue) {
+	inv := &inventory{
+		fset:  fset,
+		root:  root,
+		fsys:  fsys,
+		da:    &schema.CLDKDataAccess{SQLFiles: []schema.CLDKSQLFile{}, GoQueries: []schema.CLDKGoQuery{}, Tables: []string{}},
+		named: make(map[string][]queryRef),
+		files: make(map[string]int),
+	}
+	issues := inv.scanTree(excludeDirs)
+	inv.scanGo(pkgs)
+	inv.linkEmbeds(resources)
+
+	da := inv.da
+	if len(da.SQLFiles) == 0 && len(da.SqlcConfigs) == 0 && len(da.GoQueries) == 0 {
+		return nil, issues
+	}
+	var all []string
+	for _, f := range da.SQLFiles {
+		all = append(all, f.Creates...)
+		all = append(all, f.Uses...)
+	}
+	for _, q := range da.GoQueries {
+		all = append(all, q.Tables...)
+	}
+	if len(all) > 0 {
+		slices.Sort(all)
+		da.Tables = slices.Compact(all)
+	}
+	sort.SliceStable(da.GoQueries, func(i, j int) bool { return da.GoQueries[i].Symbol < da.GoQueries[j].Symbol })
+	return da, issues
+}
+
+// scanTree legge i file .sql e le configurazioni di sqlc di fsys.
+func (inv *inventory) scanTree(excludeDirs []string) []schema.Issue {
+	excluded := make(map[string]bool)
+	for _, d := range excludeDirs {
+		excluded[strings.TrimSpace(d)] = true
+	}
+	var sqlFiles []string
+	var issues []schema.Issue
+	fs.WalkDir(inv.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != "." && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" || name == "node_modules" || excluded[name]) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case strings.EqualFold(path.Ext(name), ".sql"):
+			sqlFiles = append(sqlFiles, p)
+		case sqlcConfigNames[name]:
+			data, err := fs.ReadFile(inv.fsys, p)
+			if err == nil {
+				var cfg schema.CLDKSqlcConfig
+				if cfg, err = parseSqlcConfig(p, data); err == nil {
+					inv.da.SqlcConfigs = append(inv.da.SqlcConfigs, cfg)
+					return nil
+				}
+			}
+			issues = append(issues, schema.Issue{
+				Severity: "warning",
+				Code:     "SQLC_CONFIG_ERROR",
+				Message:  err.Error(),
+				Position: &schema.CLDKPosition{File: p},
+			})
+		}
+		return nil
+	})
+
+	schemaPaths := make(map[string]bool)
+	for _, cfg := range inv.da.SqlcConfigs {
+		for _, pkg := range cfg.Packages {
+			for _, s := range pkg.Schema {
+				schemaPaths[s] = true
+			}
+		}
+	}
+	for _, rel := range sqlFiles {
+		data, err := fs.ReadFile(inv.fsys, rel)
+		if err != nil {
+			continue
+		}
+		f := parseSQLFile(rel, string(data), underAny(rel, schemaPaths))
+		inv.files[rel] = len(inv.da.SQLFiles)
+		for qi, q := range f.Queries {
+			inv.named[q.Name] = append(inv.named[q.Name], queryRef{file: len(inv.da.SQLFiles), query: qi})
+		}
+		inv.da.SQLFiles = append(inv.da.SQLFiles, f)
+	}
+	return issues
+}
+
+// scanGo raccoglie le costanti e variabili stringa che contengono SQL e le
+// funzioni generate da sqlc, collegandole alle query con nome.
+func (inv *inventory) scanGo(pkgs []*packages.Package) {
+	seen := make(map[string]bool) // le varianti di test ripetono i simboli
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil {
+				continue
+			}
+			goFile := inv.fset.Position(file.Pos()).Filename
+			if !loader.UnderRoot(goFile, inv.root) {
+				continue
+			}
+			source, sqlc := sqlcSource(file)
+			queries := make(map[string]schema.CLDKGoQuery) // query dichiarate dalle costanti di sqlc
+			for _, decl := range file.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || (gd.Tok != token.CONST && gd.Tok != token.VAR) {
+					continue
+				}
+				kind := SymbolConstant
+				if gd.Tok == token.VAR {
+					kind = SymbolVariable
+				}
+				for _, spec := range gd.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, name := range vs.Names {
+						if i >= len(vs.Values) || name.Name == "_" {
+							continue
+						}
+						tv, ok := pkg.TypesInfo.Types[vs.Values[i]]
+						if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+							continue
+						}
+						id := ids.Type(pkg.PkgPath, name.Name)
+						if seen[id] {
+							continue
+						}
+						q, ok := inv.goQuery(constant.StringVal(tv.Value))
+						if !ok {
+							continue
+						}
+						seen[id] = true
+						q.Symbol, q.Kind, q.Position = id, kind, inv.position(name.Pos())
+						if sqlc {
+							q.Generator = "sqlc"
+							queries[q.Query] = q
+						}
+						inv.link(&q, source, goFile)
+						inv.da.GoQueries = append(inv.da.GoQueries, q)
+					}
+				}
+			}
+			if !sqlc {
+				continue
+			}
+			// I metodi di Queries hanno il nome della query che eseguono.
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil {
+					continue
+				}
+				query, ok := queries[fn.Name.Name]
+				if !ok {
+					continue
+				}
+				obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func)
+				if !ok || seen[ids.Object(obj)] {
+					continue
+				}
+				seen[ids.Object(obj)] = true
+				q := schema.CLDKGoQuery{
+					Symbol:    ids.Object(obj),
+					Kind:      SymbolFunction,
+					Statement: query.Statement,
+					Tables:    query.Tables,
+					Generator: "sqlc",
+					Query:     fn.Name.Name,
+					Position:  inv.position(fn.Name.Pos()),
+				}
+				inv.link(&q, source, goFile)
+				inv.da.GoQueries = append(inv.da.GoQueries, q)
+			}
+		}
+	}
+}
+
+// goQuery analizza il testo di una stringa Go: le query con l'annotazione
+// "-- name:" di sqlc sono sempre SQL, le altre solo se looksLikeSQL.
+func (inv *inventory) goQuery(text string) (schema.CLDKGoQuery, bool) {
+	q := schema.CLDKGoQuery{}
+	if m := nameAnnotation.FindStringSubmatch(text); m != nil {
+		q.Query = m[1]
+	} else if !looksLikeSQL(text) {
+		return q, false
+	}
+	for i, stmt := range statements(lex(text)) {
+		if i == 0 {
+			q.Statement = verb(stmt)
+		}
+		creates, uses := tables(stmt)
+		q.Tables = append(append(q.Tables, creates...), uses...)
+	}
+	slices.Sort(q.Tables)
+	q.Tables = slices.Compact(q.Tables)
+	return q, true
+}
+
+// link collega q alla query con nome q.Query: tra le omonime preferisce
+// quella nel file indicato dall'header di sqlc (source) e nelle query
+// della configurazione che genera il package di goFile.
+func (inv *inventory) link(q *schema.CLDKGoQuery, source, goFile string) {
+	refs := inv.named[q.Query]
+	if q.Query == "" || len(refs) == 0 {
+		return
+	}
+	goDir := path.Dir(inv.rel(goFile))
+	best, bestScore := refs[0], -1
+	for _, r := range refs {
+		file := inv.da.SQLFiles[r.file].File
+		score := 0
+		if source != "" && (file == source || strings.HasSuffix(file, "/"+source)) {
+			score += 2
+		}
+		for _, cfg := range inv.da.SqlcConfigs {
+			for _, pkg := range cfg.Packages {
+				if pkg.Out == goDir && underAny(file, toSet(pkg.Queries)) {
+					score++
+				}
+			}
+		}
+		if score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+	f := &inv.da.SQLFiles[best.file]
+	q.Source = f.File
+	f.Queries[best.query].Go = append(f.Queries[best.query].Go, q.Symbol)
+	sort.Strings(f.Queries[best.query].Go)
+}
+
+// linkEmbeds collega le variabili //go:embed ai file .sql che includono:
+// una variabile string o []byte con un solo file diventa una query Go.
+func (inv *inventory) linkEmbeds(resources *schema.CLDKResources) {
+	if resources == nil {
+		return
+	}
+	for _, e := range resources.Embeds {
+		var sqlFiles []int
+		for _, ef := range e.Files {
+			if i, ok := inv.files[ef.File]; ok {
+				sqlFiles = append(sqlFiles, i)
+				inv.da.SQLFiles[i].EmbeddedBy = append(inv.da.SQLFiles[i].EmbeddedBy, e.Variable)
+			}
+		}
+		if len(sqlFiles) == 1 && len(e.Files) == 1 && e.Type != "embed.FS" {
+			f := inv.da.SQLFiles[sqlFiles[0]]
+			q := schema.CLDKGoQuery{Symbol: e.Variable, Kind: SymbolEmbed, Source: f.File, Position: e.Position}
+			q.Tables = append(append([]string{}, f.Creates...), f.Uses...)
+			slices.Sort(q.Tables)
+			q.Tables = slices.Compact(q.Tables)
+			inv.da.GoQueries = append(inv.da.GoQueries, q)
+		}
+	}
+}
+
+// sqlcSource restituisce il file indicato dal commento "source:" e se il
+// file è generato da sqlc.
+func sqlcSource(file *ast.File) (string, bool) {
+	generated, source := false, ""
+	for _, g := range file.Comments {
+		if g.Pos() >= file.Package {
+			break
+		}
+		for _, c := range g.List {
+			line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+			if sqlcHeader.MatchString(line) {
+				generated = true
+			} else if src, ok := strings.CutPrefix(line, "source: "); ok {
+				source = strings.TrimSpace(src)
+			}
+		}
+	}
+	return source, generated
+}
+
+// underAny riporta se il file rel è uno dei path o si trova in una delle
+// directory di paths.
+func underAny(rel string, paths map[string]bool) bool {
+	for dir := rel; ; dir = path.Dir(dir) {
+		if paths[dir] {
+			return true
+		}
+		if dir == "." || dir == "/" {
+			return false
+		}
+	}
+}
+
+func toSet(list []string) map[string]bool {
+	m := make(map[string]bool, len(list))
+	for _, s := range list {
+		m[s] = true
+	}
+	return m
+}
+
+func (inv *inventory) rel(p string) string {
+	if rel, err := filepath.Rel(inv.root, p); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(p)
+}
+
+func (inv *inventory) position(p token.Pos) *schema.CLDKPosition {
+	pos := inv.fset.Position(p)
+	return &schema.CLDKPosition{File: inv.rel(pos.Filename), StartLine: pos.Line, StartColumn: pos.Column}
+}
@@ -0,0 +1,489 @@
+package dataaccess
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Tipi di file .sql.
+const (
+	KindMigration = "migration"
+	KindSchema    = "schema"
+	KindQueries   = "queries"
+	KindScript    = "script"
+)
+
+// sqlToken è un elemento lessicale SQL: stringhe e commenti sono già scartati.
+type sqlToken struct {
+	text   string // identificatori non quotati in minuscolo; "'" per le stringhe
+	word   bool   // identificatore o parola chiave
+	quoted bool   // identificatore tra "" o ``
+}
+
+// lex divide sql in token saltando commenti, stringhe e dollar quoting.
+func lex(sql string) []sqlToken {
+	var toks []sqlToken
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			if j := strings.IndexByte(sql[i:], '\n'); j >= 0 {
+				i += j + 1
+			} else {
+				i = len(sql)
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			if j := strings.Index(sql[i+2:], "*/"); j >= 0 {
+				i += j + 4
+			} else {
+				i = len(sql)
+			}
+		case c == '\'':
+			i++
+			for i < len(sql) {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+			toks = append(toks, sqlToken{text: "'"})
+		case c == '$':
+			if tag := dollarTag.FindString(sql[i:]); tag != "" {
+				if j := strings.Index(sql[i+len(tag):], tag); j >= 0 {
+					i += len(tag) + j + len(tag)
+				} else {
+					i = len(sql)
+				}
+				toks = append(toks, sqlToken{text: "'"})
+				continue
+			}
+			i++
+			for i < len(sql) && isDigit(sql[i]) {
+				i++
+			}
+			toks = append(toks, sqlToken{text: "$"})
+		case c == '"' || c == '`':
+			j := strings.IndexByte(sql[i+1:], c)
+			if j < 0 {
+				j = len(sql) - i - 1
+			}
+			toks = append(toks, sqlToken{text: sql[i+1 : i+1+j], word: true, quoted: true})
+			i += j + 2
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(sql) && (isIdentStart(sql[j]) || isDigit(sql[j]) || sql[j] == '$') {
+				j++
+			}
+			toks = append(toks, sqlToken{text: strings.ToLower(sql[i:j]), word: true})
+			i = j
+		case isDigit(c):
+			for i < len(sql) && (isDigit(sql[i]) || sql[i] == '.') {
+				i++
+			}
+			toks = append(toks, sqlToken{text: "0"})
+		default:
+			toks = append(toks, sqlToken{text: sql[i : i+1]})
+			i++
+		}
+	}
+	return toks
+}
+
+var dollarTag = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// statements divide i token sulle ";" e scarta le istruzioni vuote.
+func statements(toks []sqlToken) [][]sqlToken {
+	var out [][]sqlToken
+	start := 0
+	for i := 0; i <= len(toks); i++ {
+		if i == len(toks) || toks[i].text == ";" {
+			if i > start {
+				out = append(out, toks[start:i])
+			}
+			start = i + 1
+		}
+	}
+	return out
+}
+
+// verb restituisce il tipo dell'istruzione: la prima parola o, dopo una
+// WITH, la prima select/insert/update/delete/merge fuori dalle CTE.
+func verb(stmt []sqlToken) string {
+	if len(stmt) == 0 || !stmt[0].word || stmt[0].quoted {
+		return ""
+	}
+	if stmt[0].text != "with" {
+		return stmt[0].text
+	}
+	depth := 0
+	for _, t := range stmt[1:] {
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "select", "insert", "update", "delete", "merge":
+			if depth == 0 && t.word && !t.quoted {
+				return t.text
+			}
+		}
+	}
+	return "with"
+}
+
+// ddlVerbs sono le istruzioni che definiscono lo schema.
+var ddlVerbs = map[string]bool{"create": true, "alter": true, "drop": true, "comment": true, "grant": true, "revoke": true}
+
+// keywords non sono mai nomi di tabella o alias.
+var keywords = map[string]bool{}
+
+func init() {
+	for _, k := range strings.Fields(`select from where join inner left right full cross outer natural on using
+		group order by limit offset fetch having union intersect except window for as set values returning
+		into table with recursive lateral only and or not in is null exists when then else case end default
+		insert update delete merge do nothing conflict if create alter drop index view materialized unique
+		concurrently temp temporary unlogged replace truncate references primary key foreign constraint
+		check add column distinct all any some between like ilike array interval cast`) {
+		keywords[k] = true
+	}
+}
+
+// tables restituisce le tabelle definite (DDL) e usate (DML) da stmt,
+// escluse le CTE e le funzioni nella clausola FROM.
+func tables(stmt []sqlToken) (creates, uses []string) {
+	ctes := make(map[string]bool)
+	for i, t := range stmt {
+		if !t.word || i == 0 || !(stmt[i-1].text == "with" || stmt[i-1].text == "recursive" || stmt[i-1].text == ",") {
+			continue
+		}
+		j := i + 1
+		if j < len(stmt) && stmt[j].text == "(" {
+			j = closing(stmt, j) + 1
+		}
+		if j+1 < len(stmt) && stmt[j].text == "as" && stmt[j+1].text == "(" {
+			ctes[t.text] = true
+		}
+	}
+
+	v := verb(stmt)
+	var funcs []bool // per ogni parentesi aperta: è una chiamata di funzione
+	for i := 0; i < len(stmt); i++ {
+		t := stmt[i]
+		switch t.text {
+		case "(":
+			prev := sqlToken{}
+			if i > 0 {
+				prev = stmt[i-1]
+			}
+			funcs = append(funcs, prev.word && !prev.quoted && !keywords[prev.text])
+			continue
+		case ")":
+			if len(funcs) > 0 {
+				funcs = funcs[:len(funcs)-1]
+			}
+			continue
+		}
+		if !t.word || t.quoted {
+			continue
+		}
+		prev := ""
+		if i > 0 {
+			prev = stmt[i-1].text
+		}
+		switch t.text {
+		case "from", "join", "using":
+			if len(funcs) > 0 && funcs[len(funcs)-1] {
+				continue // EXTRACT(x FROM y), SUBSTRING(s FROM n)
+			}
+			uses = append(uses, nameList(stmt, i+1, ctes, true)...)
+		case "into":
+			uses = append(uses, nameList(stmt, i+1, ctes, false)...)
+		case "update":
+			if prev != "for" && prev != "on" && prev != "do" && prev != "key" {
+				uses = append(uses, nameList(stmt, i+1, ctes, false)...)
+			}
+		case "table":
+			switch {
+			case prev == "returns":
+			case v == "truncate" || v == "lock":
+				uses = append(uses, nameList(stmt, i+1, ctes, true)...)
+			case ddlVerbs[v]:
+				creates = append(creates, nameList(stmt, i+1, ctes, v == "drop")...)
+			}
+		case "truncate":
+			if i+1 < len(stmt) && stmt[i+1].text != "table" {
+				uses = append(uses, nameList(stmt, i+1, ctes, true)...)
+			}
+		case "view":
+			if v == "create" || v == "drop" || v == "alter" {
+				creates = append(creates, nameList(stmt, i+1, ctes, false)...)
+			}
+		case "index":
+			if v == "create" {
+				for j := i + 1; j < len(stmt); j++ {
+					if stmt[j].text == "on" {
+						creates = append(creates, nameList(stmt, j+1, ctes, false)...)
+						break
+					}
+				}
+			}
+		}
+	}
+	return creates, uses
+}
+
+// nameList legge il nome di tabella in stmt[i:] (qualificato con lo schema)
+// e, se list è vero, i successivi separati da virgola con i loro alias.
+func nameList(stmt []sqlToken, i int, ctes map[string]bool, list bool) []string {
+	var out []string
+	for {
+		for i < len(stmt) && stmt[i].word && !stmt[i].quoted &&
+			(stmt[i].text == "only" || stmt[i].text == "if" || stmt[i].text == "not" || stmt[i].text == "exists" || stmt[i].text == "lateral") {
+			i++
+		}
+		if i < len(stmt) && stmt[i].text == "(" {
+			i = closing(stmt, i) + 1 // sottoquery
+		} else if i < len(stmt) && stmt[i].word && (stmt[i].quoted || !keywords[stmt[i].text]) {
+			name := stmt[i].text
+			i++
+			for i+1 < len(stmt) && stmt[i].text == "." && stmt[i+1].word {
+				name += "." + stmt[i+1].text
+				i += 2
+			}
+			if i < len(stmt) && stmt[i].text == "(" && !list {
+				// INSERT INTO t (colonne): il nome resta valido
+			} else if i < len(stmt) && stmt[i].text == "(" {
+				name = "" // funzione nella FROM, es. generate_series(...)
+				i = closing(stmt, i) + 1
+			}
+			if name != "" && !ctes[name] {
+				out = append(out, name)
+			}
+		} else {
+			return out
+		}
+		if !list {
+			return out
+		}
+		if i < len(stmt) && stmt[i].text == "as" {
+			i++
+		}
+		if i < len(stmt) && stmt[i].word && (stmt[i].quoted || !keywords[stmt[i].text]) {
+			i++ // alias
+		}
+		if i >= len(stmt) || stmt[i].text != "," {
+			return out
+		}
+		i++
+	}
+}
+
+// closing restituisce l'indice della parentesi che chiude stmt[open].
+func closing(stmt []sqlToken, open int) int {
+	depth := 0
+	for i := open; i < len(stmt); i++ {
+		switch stmt[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(stmt) - 1
+}
+
+// nameAnnotation riconosce l'annotazione "-- name: Nome :comando" di sqlc.
+var nameAnnotation = regexp.MustCompile(`^\s*--\s*name:\s*([A-Za-z_][A-Za-z0-9_]*)\s*(?::([a-z]+))?`)
+
+// Marker e nomi dei file degli strumenti di migrazione.
+var (
+	migrateName = regexp.MustCompile(`^([0-9]+)_.*\.(up|down)\.sql$`)
+	flywayName  = regexp.MustCompile(`^[VU]([0-9][0-9_.]*)__.*\.sql$`)
+	versionName = regexp.MustCompile(`^([0-9]+)[_-]`)
+	toolMarkers = []struct {
+		tool, up, down string
+	}{
+		{"goose", "-- +goose up", "-- +goose down"},
+		{"sql-migrate", "-- +migrate up", "-- +migrate down"},
+		{"dbmate", "-- migrate:up", "-- migrate:down"},
+	}
+)
+
+// parseSQLFile analizza il contenuto di un file .sql; schemaFile indica
+// che una configurazione di sqlc lo usa come schema.
+func parseSQLFile(rel, text string, schemaFile bool) schema.CLDKSQLFile {
+	f := schema.CLDKSQLFile{File: rel}
+	ddl := true
+	for _, stmt := range statements(lex(text)) {
+		f.Statements++
+		creates, uses := tables(stmt)
+		f.Creates = append(f.Creates, creates...)
+		f.Uses = append(f.Uses, uses...)
+		ddl = ddl && ddlVerbs[verb(stmt)]
+	}
+	slices.Sort(f.Creates)
+	slices.Sort(f.Uses)
+	f.Creates, f.Uses = slices.Compact(f.Creates), slices.Compact(f.Uses)
+	f.Queries = namedQueries(text)
+	f.Migration = migration(rel, text)
+
+	switch {
+	case f.Migration != nil:
+		f.Kind = KindMigration
+	case len(f.Queries) > 0:
+		f.Kind = KindQueries
+	case schemaFile || (ddl && f.Statements > 0):
+		f.Kind = KindSchema
+	default:
+		f.Kind = KindScript
+	}
+	return f
+}
+
+// namedQueries restituisce le query annotate con "-- name:"; ognuna va
+// fino all'annotazione successiva.
+func namedQueries(text string) []schema.CLDKSQLNamedQuery {
+	lines := strings.Split(text, "\n")
+	var out []schema.CLDKSQLNamedQuery
+	var body []string
+	flush := func() {
+		if len(out) == 0 {
+			return
+		}
+		q := &out[len(out)-1]
+		toks := lex(strings.Join(body, "\n"))
+		for i, stmt := range statements(toks) {
+			if i == 0 {
+				q.Statement = verb(stmt)
+			}
+			creates, uses := tables(stmt)
+			q.Tables = append(q.Tables, creates...)
+			q.Tables = append(q.Tables, uses...)
+		}
+		slices.Sort(q.Tables)
+		q.Tables = slices.Compact(q.Tables)
+		body = nil
+	}
+	for n, line := range lines {
+		if m := nameAnnotation.FindStringSubmatch(line); m != nil {
+			flush()
+			out = append(out, schema.CLDKSQLNamedQuery{Name: m[1], Command: m[2], Line: n + 1})
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+	return out
+}
+
+// migration riconosce i file di migrazione dai marker di goose,
+// sql-migrate e dbmate, dai nomi di golang-migrate e Flyway o da un nome
+// numerato in una directory migrations; nil per gli altri file.
+func migration(rel, text string) *schema.CLDKSQLMigration {
+	base := path.Base(rel)
+	m := &schema.CLDKSQLMigration{}
+	if v := versionName.FindStringSubmatch(base); v != nil {
+		m.Version = v[1]
+	}
+	lower := strings.ToLower(text)
+	for _, t := range toolMarkers {
+		up, down := strings.Contains(lower, t.up), strings.Contains(lower, t.down)
+		if up || down {
+			m.Tool, m.Direction = t.tool, direction(up, down)
+			return m
+		}
+	}
+	if v := migrateName.FindStringSubmatch(base); v != nil {
+		m.Tool, m.Direction = "golang-migrate", v[2]
+		return m
+	}
+	if v := flywayName.FindStringSubmatch(base); v != nil {
+		m.Tool, m.Version, m.Direction = "flyway", strings.TrimRight(v[1], "_."), "up"
+		return m
+	}
+	if m.Version != "" {
+		for _, dir := range strings.Split(path.Dir(rel), "/") {
+			if strings.HasPrefix(strings.ToLower(dir), "migrat") {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func direction(up, down bool) string {
+	switch {
+	case up && down:
+		return "both"
+	case up:
+		return "up"
+	}
+	return "down"
+}
+
+// looksLikeSQL riconosce una stringa Go che contiene un'istruzione SQL: la
+// prima parola è un verbo SQL seguito dalle parole che lo completano, e il
+// verbo è maiuscolo o il testo ha la punteggiatura di una query (così
+// "select an option from the list" non conta).
+func looksLikeSQL(s string) bool {
+	trimmed := strings.TrimSpace(s)
+	toks := lex(trimmed)
+	if len(toks) < 2 || !toks[0].word || toks[0].quoted {
+		return false
+	}
+	has := func(words ...string) bool {
+		for _, t := range toks[1:] {
+			for _, w := range words {
+				if t.word && !t.quoted && t.text == w {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	ok := false
+	switch toks[0].text {
+	case "select":
+		ok = has("from")
+	case "insert", "merge":
+		ok = toks[1].text == "into"
+	case "update":
+		ok = has("set")
+	case "delete":
+		ok = toks[1].text == "from"
+	case "with":
+		ok = has("select", "insert", "update", "delete")
+	case "create", "alter", "drop":
+		ok = has("table", "index", "view", "schema", "type", "extension", "sequence", "trigger", "function")
+	case "truncate":
+		ok = true
+	}
+	if !ok {
+		return false
+	}
+	at := strings.Index(strings.ToLower(s), toks[0].text)
+	kw := s[at : at+len(toks[0].text)]
+	return strings.ToUpper(kw) == kw || strings.ContainsAny(s, "*=(),?$@:")
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
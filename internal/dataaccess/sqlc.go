@@ -0,0 +1,95 @@
+package dataaccess
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/yamlsubset"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// sqlcConfigNames sono i nomi dei file di configurazione di sqlc.
+var sqlcConfigNames = map[string]bool{"sqlc.yaml": true, "sqlc.yml": true, "sqlc.json": true}
+
+// parseSqlcConfig legge una configurazione di sqlc v1 ("packages") o v2
+// ("sql"); rel è il path del file relativo alla root, a cui sono riportati
+// i path di schema, query e codice generato.
+func parseSqlcConfig(rel string, data []byte) (schema.CLDKSqlcConfig, error) {
+	var doc any
+	var err error
+	if strings.HasSuffix(rel, ".json") {
+		err = json.Unmarshal(data, &doc)
+	} else {
+		doc, err = yamlsubset.Parse(string(data))
+	}
+	if err != nil {
+		return schema.CLDKSqlcConfig{}, fmt.Errorf("%s: %w", rel, err)
+	}
+	root, _ := doc.(map[string]any)
+	cfg := schema.CLDKSqlcConfig{File: rel, Version: scalar(root["version"]), Packages: []schema.CLDKSqlcPackage{}}
+	dir := path.Dir(rel)
+	join := func(p string) string {
+		if p == "" {
+			return ""
+		}
+		return path.Clean(path.Join(dir, p))
+	}
+	paths := func(v any) []string {
+		var out []string
+		switch v := v.(type) {
+		case []any:
+			for _, e := range v {
+				if p := join(scalar(e)); p != "" {
+					out = append(out, p)
+				}
+			}
+		default:
+			if p := join(scalar(v)); p != "" {
+				out = append(out, p)
+			}
+		}
+		return out
+	}
+
+	entries, _ := root["sql"].([]any)
+	v1 := false
+	if entries == nil {
+		entries, _ = root["packages"].([]any)
+		v1 = true
+	}
+	for _, e := range entries {
+		m, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		pkg := schema.CLDKSqlcPackage{
+			Engine:  scalar(m["engine"]),
+			Schema:  paths(m["schema"]),
+			Queries: paths(m["queries"]),
+		}
+		if v1 {
+			pkg.Out, pkg.Package = join(scalar(m["path"])), scalar(m["name"])
+		} else if gen, ok := m["gen"].(map[string]any); ok {
+			if golang, ok := gen["go"].(map[string]any); ok {
+				pkg.Out, pkg.Package = join(scalar(golang["out"])), scalar(golang["package"])
+			}
+		}
+		if pkg.Package == "" && pkg.Out != "" {
+			pkg.Package = path.Base(pkg.Out)
+		}
+		cfg.Packages = append(cfg.Packages, pkg)
+	}
+	return cfg, nil
+}
+
+func scalar(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
@@ -0,0 +1,442 @@
+// Package di ricostruisce il grafo dei provider dei framework di dependency
+// injection (google/wire, uber fx, dig): quali costruttori e valori sono
+// registrati, quali tipi forniscono e richiedono, e chi consuma cosa.
+// L'analisi è sintattica sulle chiamate di registrazione, risolte con le
+// informazioni di tipo: non esegue il codice generato da wire né i
+// container a runtime.
+package di
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Import path dei framework riconosciuti.
+const (
+	pathWire = "github.com/google/wire"
+	pathFx   = "go.uber.org/fx"
+	pathDig  = "go.uber.org/dig"
+)
+
+var frameworkOf = map[string]string{pathWire: "wire", pathFx: "fx", pathDig: "dig"}
+
+// builtins sono i tipi forniti dal framework stesso, mai insoddisfatti.
+var builtins = map[string]bool{
+	pathFx + ".Lifecycle":  true,
+	pathFx + ".Shutdowner": true,
+	pathFx + ".DotGraph":   true,
+}
+
+// Tipi di registrazione.
+const (
+	KindProvide = "provide"
+	KindInvoke  = "invoke"
+	KindValue   = "value"
+	KindBind    = "bind"
+	KindStruct  = "struct"
+	KindFields  = "fields"
+)
+
+type builder struct {
+	fset  *token.FileSet
+	root  string
+	info  *types.Info
+	set   string
+	seen  map[token.Position]bool
+	used  map[string]bool
+	graph *schema.CLDKDIGraph
+}
+
+// Build restituisce il grafo dei provider registrati nei package, nil se il
+// progetto non usa wire, fx o dig.
+func Build(pkgs []*packages.Package, fset *token.FileSet, root string) *schema.CLDKDIGraph {
+	b := &builder{
+		fset:  fset,
+		root:  root,
+		seen:  make(map[token.Position]bool),
+		used:  make(map[string]bool),
+		graph: &schema.CLDKDIGraph{Providers: []schema.CLDKDIProvider{}, Edges: []schema.CLDKDIEdge{}},
+	}
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil || !importsFramework(pkg) {
+			continue
+		}
+		b.info = pkg.TypesInfo
+		for _, file := range pkg.Syntax {
+			if file == nil || !loader.UnderRoot(fset.Position(file.Pos()).Filename, root) {
+				continue
+			}
+			for _, decl := range file.Decls {
+				b.set = declName(pkg.PkgPath, decl, pkg.TypesInfo)
+				ast.Inspect(decl, func(n ast.Node) bool {
+					if call, ok := n.(*ast.CallExpr); ok {
+						b.call(call)
+					}
+					return true
+				})
+			}
+		}
+	}
+	if len(b.graph.Providers) == 0 {
+		return nil
+	}
+	b.link()
+	return b.graph
+}
+
+func importsFramework(pkg *packages.Package) bool {
+	for path := range pkg.Imports {
+		if frameworkOf[path] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// declName restituisce il nome con cui etichettare le registrazioni di
+// decl: la variabile (es. il wire.NewSet) o la funzione che le contiene.
+func declName(pkgPath string, decl ast.Decl, info *types.Info) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if fn, ok := info.Defs[d.Name].(*types.Func); ok {
+			return ids.Object(fn)
+		}
+		return ids.Func(pkgPath, d.Name.Name)
+	case *ast.GenDecl:
+		if len(d.Specs) == 1 {
+			if vs, ok := d.Specs[0].(*ast.ValueSpec); ok && len(vs.Names) == 1 {
+				return ids.Type(pkgPath, vs.Names[0].Name)
+			}
+		}
+	}
+	return ""
+}
+
+// callee restituisce framework e nome della funzione chiamata ("NewSet",
+// "Container.Provide"), "" se non appartiene a un framework.
+func (b *builder) callee(call *ast.CallExpr) (string, string) {
+	var id *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	case *ast.IndexExpr: // funzioni generiche istanziate esplicitamente
+		if sel, ok := fun.X.(*ast.SelectorExpr); ok {
+			id = sel.Sel
+		}
+	}
+	if id == nil {
+		return "", ""
+	}
+	fn, ok := b.info.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return "", ""
+	}
+	fw := frameworkOf[fn.Pkg().Path()]
+	if fw == "" {
+		return "", ""
+	}
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		t := recv.Type()
+		if p, ok := t.(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		if n, ok := t.(*types.Named); ok {
+			return fw, n.Obj().Name() + "." + fn.Name()
+		}
+	}
+	return fw, fn.Name()
+}
+
+// call registra i provider di una chiamata a un framework.
+func (b *builder) call(call *ast.CallExpr) {
+	fw, name := b.callee(call)
+	switch fw + "." + name {
+	case "wire.NewSet", "wire.Build":
+		for _, arg := range call.Args {
+			b.function(arg, fw, KindProvide)
+		}
+	case "wire.Bind":
+		if len(call.Args) == 2 {
+			iface, impl := b.newType(call.Args[0]), b.newType(call.Args[1])
+			b.add(call, fw, KindBind, "wire.Bind("+iface+", "+impl+")", []string{iface}, []string{impl})
+		}
+	case "wire.Struct":
+		if len(call.Args) >= 1 {
+			t := b.newType(call.Args[0])
+			b.add(call, fw, KindStruct, "wire.Struct("+t+")", []string{t, "*" + t}, b.structFields(call.Args[0], call.Args[1:]))
+		}
+	case "wire.FieldsOf":
+		if len(call.Args) >= 1 {
+			t := b.newType(call.Args[0])
+			b.add(call, fw, KindFields, "wire.FieldsOf("+t+")", b.structFields(call.Args[0], call.Args[1:]), []string{t})
+		}
+	case "wire.Value":
+		if len(call.Args) == 1 {
+			b.add(call, fw, KindValue, "wire.Value("+exprString(call.Args[0])+")", []string{typeString(b.info.TypeOf(call.Args[0]))}, nil)
+		}
+	case "wire.InterfaceValue":
+		if len(call.Args) == 2 {
+			b.add(call, fw, KindValue, "wire.InterfaceValue("+exprString(call.Args[1])+")", []string{b.newType(call.Args[0])}, nil)
+		}
+	case "fx.Provide":
+		for _, arg := range call.Args {
+			b.function(arg, fw, KindProvide)
+		}
+	case "fx.Invoke":
+		for _, arg := range call.Args {
+			b.function(arg, fw, KindInvoke)
+		}
+	case "fx.Supply":
+		for _, arg := range call.Args {
+			b.add(arg, fw, KindValue, "fx.Supply("+exprString(arg)+")", []string{typeString(b.info.TypeOf(arg))}, nil)
+		}
+	case "dig.Container.Provide", "dig.Scope.Provide":
+		if len(call.Args) > 0 {
+			b.function(call.Args[0], fw, KindProvide)
+		}
+	case "dig.Container.Invoke", "dig.Scope.Invoke":
+		if len(call.Args) > 0 {
+			b.function(call.Args[0], fw, KindInvoke)
+		}
+	}
+}
+
+// function registra una funzione passata a un framework. Le chiamate
+// annidate a wire (Bind, Struct, ...) sono registrate a parte, i
+// wire.ProviderSet sono già registrati dove sono dichiarati; fx.Annotate è
+// svolto.
+func (b *builder) function(arg ast.Expr, fw, kind string) {
+	arg = ast.Unparen(arg)
+	if call, ok := arg.(*ast.CallExpr); ok {
+		cfw, name := b.callee(call)
+		if cfw == "fx" && name == "Annotate" && len(call.Args) > 0 {
+			arg = ast.Unparen(call.Args[0])
+		} else if cfw != "" {
+			return
+		}
+	}
+	t := b.info.TypeOf(arg)
+	if t == nil {
+		return
+	}
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok {
+		return
+	}
+	var provides, requires []string
+	for i := 0; i < sig.Params().Len(); i++ {
+		requires = append(requires, b.flatten(sig.Params().At(i).Type())...)
+	}
+	if kind != KindInvoke {
+		for i := 0; i < sig.Results().Len(); i++ {
+			t := sig.Results().At(i).Type()
+			if isError(t) || isCleanup(t) {
+				continue
+			}
+			provides = append(provides, b.flatten(t)...)
+		}
+	}
+	b.add(arg, fw, kind, b.funcName(arg), provides, requires)
+}
+
+func (b *builder) add(at ast.Node, fw, kind, name string, provides, requires []string) {
+	pos := b.fset.Position(at.Pos())
+	if b.seen[pos] {
+		return // le varianti di test ripetono i file
+	}
+	b.seen[pos] = true
+	if !b.used[fw] {
+		b.used[fw] = true
+		b.graph.Frameworks = append(b.graph.Frameworks, fw)
+		sort.Strings(b.graph.Frameworks)
+	}
+	b.graph.Providers = append(b.graph.Providers, schema.CLDKDIProvider{
+		Function:  name,
+		Framework: fw,
+		Kind:      kind,
+		Set:       b.set,
+		Provides:  provides,
+		Requires:  requires,
+		Position:  loader.Position(pos, b.root),
+	})
+}
+
+// funcName restituisce l'ID della funzione passata, o una descrizione per
+// literal ed espressioni.
+func (b *builder) funcName(e ast.Expr) string {
+	var id *ast.Ident
+	switch x := e.(type) {
+	case *ast.Ident:
+		id = x
+	case *ast.SelectorExpr:
+		id = x.Sel
+	case *ast.FuncLit:
+		pos := b.fset.Position(x.Pos())
+		return fmt.Sprintf("func literal (%s:%d)", filepath.Base(pos.Filename), pos.Line)
+	}
+	if id != nil {
+		if fn, ok := b.info.Uses[id].(*types.Func); ok {
+			return ids.Object(fn)
+		}
+	}
+	return exprString(e)
+}
+
+// newType restituisce T per un argomento new(T) (tipo *T).
+func (b *builder) newType(e ast.Expr) string {
+	t := b.info.TypeOf(e)
+	if p, ok := t.(*types.Pointer); ok {
+		return typeString(p.Elem())
+	}
+	return typeString(t)
+}
+
+// structFields restituisce i tipi dei campi nominati (o "*" per tutti) della
+// struct puntata da newExpr.
+func (b *builder) structFields(newExpr ast.Expr, names []ast.Expr) []string {
+	t := b.info.TypeOf(newExpr)
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	want := make(map[string]bool)
+	for _, n := range names {
+		if lit, ok := ast.Unparen(n).(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			want[strings.Trim(lit.Value, "\"`")] = true
+		}
+	}
+	var out []string
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if want["*"] && reflect.StructTag(st.Tag(i)).Get("wire") != "-" || want[f.Name()] {
+			out = append(out, typeString(f.Type()))
+		}
+	}
+	return out
+}
+
+// flatten espande le struct parametro/risultato che incorporano fx.In,
+// fx.Out, dig.In o dig.Out nei tipi dei loro campi esportati.
+func (b *builder) flatten(t types.Type) []string {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || !embedsMarker(st) {
+		return []string{typeString(t)}
+	}
+	var out []string
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if f.Embedded() || !f.Exported() {
+			continue
+		}
+		out = append(out, typeString(f.Type()))
+	}
+	return out
+}
+
+func embedsMarker(st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Embedded() {
+			continue
+		}
+		if n, ok := f.Type().(*types.Named); ok && n.Obj().Pkg() != nil {
+			path, name := n.Obj().Pkg().Path(), n.Obj().Name()
+			if (path == pathFx || path == pathDig) && (name == "In" || name == "Out") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// link collega ogni tipo richiesto ai provider dello stesso framework che
+// lo forniscono; i tipi senza provider finiscono in Unsatisfied.
+func (b *builder) link() {
+	g := b.graph
+	sort.SliceStable(g.Providers, func(i, j int) bool {
+		a, c := g.Providers[i].Position, g.Providers[j].Position
+		if a.File != c.File {
+			return a.File < c.File
+		}
+		if a.StartLine != c.StartLine {
+			return a.StartLine < c.StartLine
+		}
+		return a.StartColumn < c.StartColumn
+	})
+
+	// Ogni framework ha il proprio container: i tipi si risolvono solo tra
+	// provider dello stesso framework.
+	by := make(map[string][]string)
+	for _, p := range g.Providers {
+		for _, t := range p.Provides {
+			by[p.Framework+" "+t] = append(by[p.Framework+" "+t], p.Function)
+		}
+	}
+	seen := make(map[schema.CLDKDIEdge]bool)
+	for _, p := range g.Providers {
+		for _, t := range p.Requires {
+			from := by[p.Framework+" "+t]
+			if len(from) == 0 {
+				if !builtins[t] && !builtins[strings.TrimPrefix(t, "*")] {
+					g.Unsatisfied = append(g.Unsatisfied, schema.CLDKDIMissing{Consumer: p.Function, Type: t})
+				}
+				continue
+			}
+			for _, f := range from {
+				e := schema.CLDKDIEdge{From: f, To: p.Function, Type: t}
+				if !seen[e] {
+					seen[e] = true
+					g.Edges = append(g.Edges, e)
+				}
+			}
+		}
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, c := g.Edges[i], g.Edges[j]
+		if a.From != c.From {
+			return a.From < c.From
+		}
+		if a.To != c.To {
+			return a.To < c.To
+		}
+		return a.Type < c.Type
+	})
+}
+
+func typeString(t types.Type) string {
+	if t == nil {
+		return ""
+	}
+	return types.TypeString(t, nil)
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// isCleanup riconosce la funzione di cleanup func() restituita dai provider wire.
+func isCleanup(t types.Type) bool {
+	sig, ok := t.(*types.Signature)
+	return ok && sig.Params().Len() == 0 && sig.Results().Len() == 0
+}
+
+func exprString(e ast.Expr) string {
+	return types.ExprString(e)
+}
@@ -0,0 +1,55 @@
+// Package diag raccoglie le issue comuni ai comandi che producono
+// un'analisi CLDK: gli errori del loader e il riepilogo per severity nei
+// metadata.
+package diag
+
+import (
+	"fmt"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// loadErrorCodes mappa il tipo di errore di go/packages sul codice issue.
+var loadErrorCodes = map[string]string{
+	"list":    "LOAD_ERROR",
+	"parse":   "PARSE_ERROR",
+	"type":    "TYPE_ERROR",
+	"unknown": "LOAD_ERROR",
+}
+
+// LoadIssues converte gli errori del loader in issue con posizione.
+func LoadIssues(errs []loader.PackageError) []schema.Issue {
+	issues := make([]schema.Issue, 0, len(errs))
+	for _, e := range errs {
+		iss := schema.Issue{
+			Severity: "error",
+			Code:     loadErrorCodes[e.Kind],
+			Message:  fmt.Sprintf("%s: %s", e.Package, e.Message),
+		}
+		if e.File != "" {
+			iss.Position = &schema.CLDKPosition{
+				File:        e.File,
+				StartLine:   e.Line,
+				StartColumn: e.Column,
+			}
+		}
+		issues = append(issues, iss)
+	}
+	return issues
+}
+
+// Summarize conta le issue per severity nei metadata; un'analisi con
+// almeno una issue error è marcata come degraded.
+func Summarize(meta *schema.Metadata, issues []schema.Issue) {
+	meta.Errors, meta.Warnings = 0, 0
+	for _, iss := range issues {
+		switch iss.Severity {
+		case "error":
+			meta.Errors++
+		case "warning":
+			meta.Warnings++
+		}
+	}
+	meta.Degraded = meta.Errors > 0
+}
@@ -0,0 +1,80 @@
+// Package diff confronta due analisi CLDK dello stesso progetto.
+package diff
+
+import (
+	"sort"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Compare restituisce package, tipi, callable e archi del call graph
+// aggiunti o rimossi passando da old a new. Le sezioni assenti in una delle
+// due analisi sono trattate come vuote.
+func Compare(old, new *schema.CLDKAnalysis) *schema.CLDKDiff {
+	oldPkgs, oldTypes, oldCallables := symbolSets(old.SymbolTable)
+	newPkgs, newTypes, newCallables := symbolSets(new.SymbolTable)
+
+	return &schema.CLDKDiff{
+		Packages:  compareSets(oldPkgs, newPkgs),
+		Types:     compareSets(oldTypes, newTypes),
+		Callables: compareSets(oldCallables, newCallables),
+		CallEdges: compareSets(edgeSet(old.CallGraph), edgeSet(new.CallGraph)),
+	}
+}
+
+// symbolSets raccoglie package, tipi e callable (metodi compresi).
+func symbolSets(st *schema.CLDKSymbolTable) (pkgs, types, callables map[string]bool) {
+	pkgs = make(map[string]bool)
+	types = make(map[string]bool)
+	callables = make(map[string]bool)
+	if st == nil {
+		return
+	}
+	for path, pkg := range st.Packages {
+		pkgs[path] = true
+		if pkg == nil {
+			continue
+		}
+		for qn := range pkg.CallableDeclarations {
+			callables[qn] = true
+		}
+		for qn, t := range pkg.TypeDeclarations {
+			types[qn] = true
+			if t == nil {
+				continue
+			}
+			for mqn := range t.Methods {
+				callables[mqn] = true
+			}
+		}
+	}
+	return
+}
+
+func edgeSet(cg *schema.CLDKCallGraph) map[string]bool {
+	edges := make(map[string]bool)
+	if cg == nil {
+		return edges
+	}
+	for _, e := range cg.Edges {
+		edges[e.Source+" -> "+e.Target] = true
+	}
+	return edges
+}
+
+func compareSets(old, new map[string]bool) schema.CLDKDiffSet {
+	set := schema.CLDKDiffSet{Added: []string{}, Removed: []string{}}
+	for k := range new {
+		if !old[k] {
+			set.Added = append(set.Added, k)
+		}
+	}
+	for k := range old {
+		if !new[k] {
+			set.Removed = append(set.Removed, k)
+		}
+	}
+	sort.Strings(set.Added)
+	sort.Strings(set.Removed)
+	return set
+}
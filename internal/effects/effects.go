@@ -0,0 +1,303 @@
+// Package effects classifica i callable con un insieme grossolano di effetti
+// collaterali (lettura e scrittura del filesystem, rete, esecuzione di
+// processi, ambiente) a partire dalle chiamate alla libreria standard
+// raggiunte nel call graph.
+package effects
+
+import (
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Effetti riconosciuti.
+const (
+	ReadsFS  = "reads_fs"
+	WritesFS = "writes_fs"
+	Network  = "network"
+	Exec     = "exec"
+	Env      = "env"
+)
+
+// stdlibEffects mappa le funzioni e i metodi della libreria standard ai loro
+// effetti, con gli ID del call graph.
+var stdlibEffects = map[string][]string{
+	// filesystem — lettura
+	"os.Open":                    {ReadsFS},
+	"os.ReadFile":                {ReadsFS},
+	"os.ReadDir":                 {ReadsFS},
+	"os.Stat":                    {ReadsFS},
+	"os.Lstat":                   {ReadsFS},
+	"os.Readlink":                {ReadsFS},
+	"os.DirFS":                   {ReadsFS},
+	"os.(*File).Read":            {ReadsFS},
+	"os.(*File).ReadAt":          {ReadsFS},
+	"os.(*File).ReadDir":         {ReadsFS},
+	"os.(*File).Readdir":         {ReadsFS},
+	"os.(*File).Readdirnames":    {ReadsFS},
+	"os.(*File).Stat":            {ReadsFS},
+	"io/ioutil.ReadFile":         {ReadsFS},
+	"io/ioutil.ReadDir":          {ReadsFS},
+	"path/filepath.Walk":         {ReadsFS},
+	"path/filepath.WalkDir":      {ReadsFS},
+	"path/filepath.Glob":         {ReadsFS},
+	"path/filepath.EvalSymlinks": {ReadsFS},
+
+	// filesystem — scrittura
+	"os.OpenFile":            {ReadsFS, WritesFS}, // dipende dai flag
+	"os.Create":              {WritesFS},
+	"os.CreateTemp":          {WritesFS},
+	"os.MkdirTemp":           {WritesFS},
+	"os.WriteFile":           {WritesFS},
+	"os.Remove":              {WritesFS},
+	"os.RemoveAll":           {WritesFS},
+	"os.Rename":              {WritesFS},
+	"os.Mkdir":               {WritesFS},
+	"os.MkdirAll":            {WritesFS},
+	"os.Chmod":               {WritesFS},
+	"os.Chown":               {WritesFS},
+	"os.Lchown":              {WritesFS},
+	"os.Chtimes":             {WritesFS},
+	"os.Link":                {WritesFS},
+	"os.Symlink":             {WritesFS},
+	"os.Truncate":            {WritesFS},
+	"os.CopyFS":              {WritesFS},
+	"os.(*File).Write":       {WritesFS},
+	"os.(*File).WriteAt":     {WritesFS},
+	"os.(*File).WriteString": {WritesFS},
+	"os.(*File).ReadFrom":    {WritesFS},
+	"os.(*File).Truncate":    {WritesFS},
+	"os.(*File).Chmod":       {WritesFS},
+	"os.(*File).Chown":       {WritesFS},
+	"os.(*File).Sync":        {WritesFS},
+	"io/ioutil.WriteFile":    {WritesFS},
+	"io/ioutil.TempFile":     {WritesFS},
+	"io/ioutil.TempDir":      {WritesFS},
+
+	// rete
+	"net/http.Get":                         {Network},
+	"net/http.Post":                        {Network},
+	"net/http.PostForm":                    {Network},
+	"net/http.Head":                        {Network},
+	"net/http.ListenAndServe":              {Network},
+	"net/http.ListenAndServeTLS":           {Network},
+	"net/http.Serve":                       {Network},
+	"net/http.ServeTLS":                    {Network},
+	"net/http.(*Client).Do":                {Network},
+	"net/http.(*Client).Get":               {Network},
+	"net/http.(*Client).Post":              {Network},
+	"net/http.(*Client).PostForm":          {Network},
+	"net/http.(*Client).Head":              {Network},
+	"net/http.(*Server).ListenAndServe":    {Network},
+	"net/http.(*Server).ListenAndServeTLS": {Network},
+	"net/http.(*Server).Serve":             {Network},
+	"net/http.(*Server).ServeTLS":          {Network},
+	"net/http.(*Transport).RoundTrip":      {Network},
+	"crypto/tls.Dial":                      {Network},
+	"crypto/tls.DialWithDialer":            {Network},
+	"crypto/tls.Listen":                    {Network},
+	"crypto/tls.(*Dialer).Dial":            {Network},
+	"crypto/tls.(*Dialer).DialContext":     {Network},
+	"net/smtp.Dial":                        {Network},
+	"net/smtp.SendMail":                    {Network},
+	"net/rpc.Dial":                         {Network},
+	"net/rpc.DialHTTP":                     {Network},
+	"net/rpc.DialHTTPPath":                 {Network},
+
+	// processi
+	"os/exec.(*Cmd).Run":            {Exec},
+	"os/exec.(*Cmd).Start":          {Exec},
+	"os/exec.(*Cmd).Output":         {Exec},
+	"os/exec.(*Cmd).CombinedOutput": {Exec},
+	"os.StartProcess":               {Exec},
+	"syscall.Exec":                  {Exec},
+	"syscall.ForkExec":              {Exec},
+	"syscall.StartProcess":          {Exec},
+
+	// ambiente
+	"os.Getenv":        {Env},
+	"os.LookupEnv":     {Env},
+	"os.Environ":       {Env},
+	"os.ExpandEnv":     {Env},
+	"os.Setenv":        {Env},
+	"os.Unsetenv":      {Env},
+	"os.Clearenv":      {Env},
+	"os.UserHomeDir":   {Env},
+	"os.UserCacheDir":  {Env},
+	"os.UserConfigDir": {Env},
+	"os.TempDir":       {Env},
+	"syscall.Getenv":   {Env},
+	"syscall.Setenv":   {Env},
+}
+
+// netPrefixes sono i prefissi delle funzioni del package net che aprono
+// connessioni o interrogano il DNS; netMethodPrefixes quelli dei metodi,
+// che comprendono letture e scritture sulle connessioni.
+var (
+	netPrefixes       = []string{"Dial", "Listen", "Lookup", "Resolve"}
+	netMethodPrefixes = []string{"Dial", "Listen", "Lookup", "Resolve", "Read", "Write", "Accept"}
+)
+
+// classify restituisce gli effetti diretti di una funzione della libreria
+// standard, nil se non ne ha di noti.
+func classify(id string) []string {
+	if e, ok := stdlibEffects[id]; ok {
+		return e
+	}
+	// package net: connessioni, listener e DNS; per i metodi anche le
+	// letture e scritture (es. net.(*Dialer).DialContext, net.(*TCPConn).Write)
+	if !strings.HasPrefix(id, "net.") {
+		return nil
+	}
+	name := id[strings.LastIndex(id, ".")+1:]
+	prefixes := netPrefixes
+	if strings.Contains(id, ")") {
+		prefixes = netMethodPrefixes
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(name, p) {
+			return []string{Network}
+		}
+	}
+	return nil
+}
+
+// Annotate assegna a ogni nodo del call graph, e ai callable e metodi della
+// symbol table (se non nil) tramite symbol_ref, gli effetti delle funzioni
+// della libreria standard che raggiunge. La visita attraversa il codice del
+// progetto e delle dipendenze ma si ferma alla libreria standard, che è
+// classificata da una tabella: un nodo della libreria standard ha solo i
+// suoi effetti diretti. Le chiamate d'interfaccia verso la libreria
+// standard (es. io.Writer.Write risolto a os.(*File).Write) sono ignorate,
+// perché il call graph le risolve a ogni implementazione possibile.
+func Annotate(cg *schema.CLDKCallGraph, st *schema.CLDKSymbolTable, project map[string]bool) {
+	if cg == nil {
+		return
+	}
+	pkgOf := make(map[string]string, len(cg.Nodes))
+	for _, n := range cg.Nodes {
+		pkgOf[n.ID] = n.Package
+	}
+	isStdlib := func(pkgPath string) bool { return stdlib(pkgPath) && !project[pkgPath] }
+	adj := make(map[string][]string)
+	for _, e := range cg.Edges {
+		if e.DeclaredTarget != "" && isStdlib(pkgOf[e.Target]) {
+			continue
+		}
+		adj[e.Source] = append(adj[e.Source], e.Target)
+	}
+
+	// Effetti per nodo, propagati sulle SCC con l'algoritmo di Tarjan: i
+	// nodi di una ricorsione condividono gli stessi effetti
+	effects := make(map[string]map[string]bool, len(cg.Nodes))
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = len(index)
+		low[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+		set := make(map[string]bool)
+		effects[id] = set
+		for _, e := range classify(id) {
+			set[e] = true
+		}
+		if !isStdlib(pkgOf[id]) {
+			for _, next := range adj[id] {
+				if _, seen := index[next]; !seen {
+					visit(next)
+					low[id] = min(low[id], low[next])
+				} else if onStack[next] {
+					low[id] = min(low[id], index[next])
+				}
+				if !onStack[next] {
+					for e := range effects[next] {
+						set[e] = true
+					}
+				}
+			}
+		}
+		if low[id] != index[id] {
+			return
+		}
+		// radice della SCC: unione degli effetti dei suoi nodi
+		var scc []string
+		for {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[n] = false
+			scc = append(scc, n)
+			if n == id {
+				break
+			}
+		}
+		if len(scc) > 1 {
+			for _, n := range scc {
+				for e := range effects[n] {
+					set[e] = true
+				}
+			}
+			for _, n := range scc {
+				effects[n] = set
+			}
+		}
+	}
+
+	byRef := make(map[string]map[string]bool)
+	for i := range cg.Nodes {
+		n := &cg.Nodes[i]
+		if _, seen := index[n.ID]; !seen {
+			visit(n.ID)
+		}
+		n.Effects = sortedEffects(effects[n.ID])
+		if n.SymbolRef == "" {
+			continue
+		}
+		// le closure risalgono alla funzione che le contiene
+		if byRef[n.SymbolRef] == nil {
+			byRef[n.SymbolRef] = make(map[string]bool)
+		}
+		for e := range effects[n.ID] {
+			byRef[n.SymbolRef][e] = true
+		}
+	}
+
+	if st == nil {
+		return
+	}
+	for _, pkg := range st.Packages {
+		for id, c := range pkg.CallableDeclarations {
+			c.Effects = sortedEffects(byRef[id])
+		}
+		for _, t := range pkg.TypeDeclarations {
+			for _, m := range t.Methods {
+				m.Effects = sortedEffects(byRef[m.QualifiedName])
+			}
+		}
+	}
+}
+
+// stdlib riporta se il package può appartenere alla libreria standard: il
+// primo elemento del path non contiene un punto.
+func stdlib(pkgPath string) bool {
+	if pkgPath == "" {
+		return false
+	}
+	first, _, _ := strings.Cut(pkgPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// sortedEffects restituisce gli effetti in ordine fisso, nil se non ce ne
+// sono.
+func sortedEffects(set map[string]bool) []string {
+	var out []string
+	for _, e := range []string{ReadsFS, WritesFS, Network, Exec, Env} {
+		if set[e] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+// Package embeds inventaria le direttive //go:embed del progetto: pattern,
+// variabile che riceve il contenuto e file effettivamente inclusi nel
+// binario, con le loro dimensioni.
+package embeds
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Inventory restituisce le direttive //go:embed dei package, nil se non ce
+// ne sono. I file sono risolti in fsys, i sorgenti del progetto con radice
+// in root (loader.LoadResult.FS), come fa il compilatore: una directory
+// include ricorsivamente i suoi file, esclusi quelli che iniziano con "." o
+// "_" (salvo il prefisso "all:") e i moduli annidati.
+func Inventory(pkgs []*packages.Package, fset *token.FileSet, root string, fsys fs.FS) *schema.CLDKResources {
+	res := &schema.CLDKResources{}
+	seen := make(map[token.Position]bool) // le varianti di test ripetono i file
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil {
+				continue
+			}
+			dir, err := filepath.Rel(root, filepath.Dir(fset.Position(file.Package).Filename))
+			if err != nil || !filepath.IsLocal(dir) {
+				continue // fuori dal progetto
+			}
+			dir = filepath.ToSlash(dir)
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					vs := spec.(*ast.ValueSpec)
+					doc := vs.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					patterns := directives(doc)
+					if len(patterns) == 0 || len(vs.Names) == 0 {
+						continue
+					}
+					pos := fset.Position(vs.Pos())
+					if seen[pos] {
+						continue
+					}
+					seen[pos] = true
+
+					name := vs.Names[0]
+					e := schema.CLDKEmbed{
+						Package:  pkg.PkgPath,
+						Variable: ids.Type(pkg.PkgPath, name.Name),
+						Type:     embedType(pkg.TypesInfo, name, vs.Type),
+						Patterns: patterns,
+						Files:    []schema.CLDKEmbeddedFile{},
+						Position: loader.Position(pos, root),
+					}
+					for _, f := range resolve(fsys, dir, patterns) {
+						e.Files = append(e.Files, schema.CLDKEmbeddedFile{File: f.path, Size: f.size})
+						e.TotalBytes += f.size
+					}
+					res.Embeds = append(res.Embeds, e)
+					res.TotalFiles += len(e.Files)
+					res.TotalBytes += e.TotalBytes
+				}
+			}
+		}
+	}
+	if len(res.Embeds) == 0 {
+		return nil
+	}
+	sort.Slice(res.Embeds, func(i, j int) bool { return res.Embeds[i].Variable < res.Embeds[j].Variable })
+	return res
+}
+
+// directives restituisce i pattern delle righe //go:embed di doc.
+func directives(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var out []string
+	for _, c := range doc.List {
+		rest, ok := strings.CutPrefix(c.Text, "//go:embed")
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		out = append(out, splitPatterns(rest)...)
+	}
+	return out
+}
+
+// splitPatterns divide gli argomenti di //go:embed, che possono essere
+// quotati (con virgolette o backtick) per contenere spazi.
+func splitPatterns(s string) []string {
+	var out []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		if s[0] == '"' || s[0] == '`' {
+			if end := strings.IndexByte(s[1:], s[0]); end >= 0 {
+				if p, err := strconv.Unquote(s[:end+2]); err == nil {
+					out = append(out, p)
+				}
+				s = s[end+2:]
+				continue
+			}
+		}
+		field, rest, _ := strings.Cut(s, " ")
+		out = append(out, field)
+		s = rest
+	}
+	return out
+}
+
+// embedType restituisce "string", "[]byte" o "embed.FS".
+func embedType(info *types.Info, name *ast.Ident, typ ast.Expr) string {
+	if info != nil {
+		if obj := info.Defs[name]; obj != nil {
+			return types.TypeString(obj.Type(), nil)
+		}
+	}
+	if typ == nil {
+		return ""
+	}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.ArrayType:
+		return "[]byte"
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			return x.Name + "." + t.Sel.Name
+		}
+	}
+	return ""
+}
+
+type match struct {
+	path string
+	size int64
+}
+
+// resolve espande i pattern relativi a dir (path di fsys) in file ordinati
+// e distinti, con i path di fsys.
+func resolve(fsys fs.FS, dir string, patterns []string) []match {
+	found := make(map[string]int64)
+	for _, p := range patterns {
+		all := strings.HasPrefix(p, "all:")
+		p = strings.TrimPrefix(p, "all:")
+		paths, _ := fs.Glob(fsys, path.Join(dir, p))
+		for _, m := range paths {
+			info, err := fs.Stat(fsys, m)
+			if err != nil {
+				continue
+			}
+			if !info.IsDir() {
+				found[m] = info.Size()
+				continue
+			}
+			fs.WalkDir(fsys, m, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return nil
+				}
+				name := d.Name()
+				if p != m && !all && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					if d.IsDir() {
+						return fs.SkipDir
+					}
+					return nil
+				}
+				if d.IsDir() {
+					if _, err := fs.Stat(fsys, path.Join(p, "go.mod")); err == nil && p != dir {
+						return fs.SkipDir
+					}
+					return nil
+				}
+				if fi, err := d.Info(); err == nil && fi.Mode().IsRegular() {
+					found[p] = fi.Size()
+				}
+				return nil
+			})
+		}
+	}
+	out := make([]match, 0, len(found))
+	for p, size := range found {
+		out = append(out, match{p, size})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].path < out[j].path })
+	return out
+}
@@ -0,0 +1,558 @@
+// Package errtaxonomy costruisce la mappa degli errori del progetto: i tipi
+// che implementano error, le variabili sentinella (var ErrX = errors.New(...))
+// e i target delle chiamate a errors.Is ed errors.As, raggruppati per modulo.
+package errtaxonomy
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// errType è il tipo predichiarato error, errorIface la sua interfaccia.
+var (
+	errType    = types.Universe.Lookup("error").Type()
+	errorIface = errType.Underlying().(*types.Interface)
+)
+
+// checkPkgs sono i package che forniscono Is e As con la semantica di
+// errors.
+var checkPkgs = map[string]bool{
+	"errors":                true,
+	"golang.org/x/xerrors":  true,
+	"github.com/pkg/errors": true,
+}
+
+// module è un modulo Go individuato dal suo go.mod.
+type module struct {
+	path string
+	dir  string // relativa alla root, vuota se il go.mod è fuori dalla root
+}
+
+type builder struct {
+	fset      *token.FileSet
+	root      string
+	fsys      fs.FS             // sorgenti del progetto, per i go.mod
+	modules   map[string]module // directory → modulo che la contiene
+	byModule  map[string]*schema.CLDKErrorModule
+	types     map[string]*schema.CLDKErrorType
+	sentinels map[string]*schema.CLDKSentinel
+	typeMod   map[string]string // ID del tipo o della sentinella → modulo
+	targets   map[string]*schema.CLDKErrorTarget
+	targetMod map[string]string // chiave del target → modulo
+	seen      map[token.Position]bool
+}
+
+// Inventory restituisce la tassonomia degli errori dei package, nil se il
+// progetto non dichiara tipi di errore o sentinelle e non chiama
+// errors.Is o errors.As. Sono riconosciuti anche Is e As di
+// golang.org/x/xerrors e github.com/pkg/errors. I go.mod sotto root sono
+// letti da fsys, i sorgenti visti dal loader.
+func Inventory(pkgs []*packages.Package, fset *token.FileSet, root string, fsys fs.FS) *schema.CLDKErrorTaxonomy {
+	b := &builder{
+		fset:      fset,
+		root:      root,
+		fsys:      fsys,
+		modules:   make(map[string]module),
+		byModule:  make(map[string]*schema.CLDKErrorModule),
+		types:     make(map[string]*schema.CLDKErrorType),
+		sentinels: make(map[string]*schema.CLDKSentinel),
+		typeMod:   make(map[string]string),
+		targets:   make(map[string]*schema.CLDKErrorTarget),
+		targetMod: make(map[string]string),
+		seen:      make(map[token.Position]bool), // le varianti di test ripetono i file
+	}
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil {
+				continue
+			}
+			name := fset.Position(file.Pos()).Filename
+			if !loader.UnderRoot(name, root) {
+				continue
+			}
+			mod := b.moduleOf(filepath.Dir(name))
+			b.declarations(pkg, file, mod)
+			b.checks(pkg, file, mod)
+		}
+	}
+	if len(b.types) == 0 && len(b.sentinels) == 0 && len(b.targets) == 0 {
+		return nil
+	}
+	return b.finish()
+}
+
+// declarations raccoglie i tipi di errore e le sentinelle dichiarati a
+// livello di package in file.
+func (b *builder) declarations(pkg *packages.Package, file *ast.File, mod module) {
+	info := pkg.TypesInfo
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				obj, ok := info.Defs[spec.Name].(*types.TypeName)
+				if !ok || obj.IsAlias() || !b.first(obj.Pos()) {
+					continue
+				}
+				if t := errorType(obj); t != nil {
+					t.Position = loader.Position(b.fset.Position(obj.Pos()), b.root)
+					b.types[t.Type] = t
+					b.typeMod[t.Type] = mod.path
+					b.module(mod)
+				}
+			case *ast.ValueSpec:
+				if gen.Tok != token.VAR || len(spec.Values) != len(spec.Names) {
+					continue
+				}
+				for i, name := range spec.Names {
+					obj, ok := info.Defs[name].(*types.Var)
+					if !ok || name.Name == "_" || !types.Implements(obj.Type(), errorIface) || !b.first(obj.Pos()) {
+						continue
+					}
+					s := sentinel(obj, spec.Values[i], info)
+					if s == nil {
+						continue
+					}
+					s.Position = loader.Position(b.fset.Position(obj.Pos()), b.root)
+					b.sentinels[s.Variable] = s
+					b.typeMod[s.Variable] = mod.path
+					b.module(mod)
+				}
+			}
+		}
+	}
+}
+
+// errorType descrive obj se implementa error (con receiver valore o
+// puntatore) o, per le interfacce, se incorpora i metodi di error. I tipi
+// generici non istanziati sono esclusi.
+func errorType(obj *types.TypeName) *schema.CLDKErrorType {
+	T := obj.Type()
+	if n, ok := T.(*types.Named); ok && n.TypeParams().Len() > 0 {
+		return nil
+	}
+	t := &schema.CLDKErrorType{Type: ids.Type(obj.Pkg().Path(), obj.Name())}
+	switch T.Underlying().(type) {
+	case *types.Interface:
+		if !types.Implements(T, errorIface) {
+			return nil
+		}
+		t.Kind = "interface"
+	case *types.Struct:
+		t.Kind = "struct"
+	default:
+		t.Kind = "other"
+	}
+	if t.Kind != "interface" {
+		switch {
+		case types.Implements(T, errorIface):
+			t.Receiver = "value"
+		case types.Implements(types.NewPointer(T), errorIface):
+			t.Receiver = "pointer"
+		default:
+			return nil
+		}
+	}
+
+	recv := T
+	if t.Kind != "interface" {
+		recv = types.NewPointer(T)
+	}
+	mset := types.NewMethodSet(recv)
+	if sig := method(mset, "Unwrap"); sig != nil && sig.Params().Len() == 0 && sig.Results().Len() == 1 {
+		switch res := sig.Results().At(0).Type(); {
+		case types.Identical(res, errType):
+			t.Unwrap = "single"
+		case isErrorSlice(res):
+			t.Unwrap = "multi"
+		}
+	}
+	if sig := method(mset, "Is"); sig != nil && sig.Params().Len() == 1 && isBool(sig.Results()) &&
+		types.Identical(sig.Params().At(0).Type(), errType) {
+		t.HasIs = true
+	}
+	if sig := method(mset, "As"); sig != nil && sig.Params().Len() == 1 && isBool(sig.Results()) {
+		t.HasAs = true
+	}
+	return t
+}
+
+// sentinel descrive la variabile obj inizializzata con value, nil se il
+// valore è nil.
+func sentinel(obj *types.Var, value ast.Expr, info *types.Info) *schema.CLDKSentinel {
+	value = ast.Unparen(value)
+	if id, ok := value.(*ast.Ident); ok && id.Name == "nil" {
+		if _, isNil := info.Uses[id].(*types.Nil); isNil {
+			return nil
+		}
+	}
+	s := &schema.CLDKSentinel{Variable: ids.Type(obj.Pkg().Path(), obj.Name())}
+	if v := packageVar(value, info); v != nil {
+		s.Alias = ids.Type(v.Pkg().Path(), v.Name())
+	}
+	switch v := value.(type) {
+	case *ast.CallExpr:
+		if tv, ok := info.Types[v.Fun]; ok && tv.IsType() {
+			// conversione, es. ErrCode("x") o MyErr(3)
+			s.Constructor = typeID(tv.Type)
+			if len(v.Args) == 1 {
+				s.Message = stringConst(info, v.Args[0])
+			}
+			break
+		}
+		fn := callee(v, info)
+		if fn == nil {
+			break
+		}
+		s.Constructor = callName(fn)
+		if len(v.Args) > 0 {
+			s.Message = stringConst(info, v.Args[0])
+			for _, i := range wrapVerbs(s.Message) {
+				if 1+i < len(v.Args) {
+					if w := packageVar(v.Args[1+i], info); w != nil {
+						s.Wraps = append(s.Wraps, ids.Type(w.Pkg().Path(), w.Name()))
+					}
+				}
+			}
+		}
+	case *ast.CompositeLit:
+		s.Constructor = typeID(info.TypeOf(v))
+	case *ast.UnaryExpr:
+		if lit, ok := ast.Unparen(v.X).(*ast.CompositeLit); ok && v.Op == token.AND {
+			s.Constructor = "&" + typeID(info.TypeOf(lit))
+		}
+	}
+	// il tipo dinamico è noto solo se l'espressione ha un tipo concreto
+	if n := namedOf(info.TypeOf(value)); n != nil && !types.IsInterface(n) && n.Obj().Pkg() != nil {
+		s.Type = ids.Type(n.Obj().Pkg().Path(), n.Obj().Name())
+	}
+	return s
+}
+
+// checks raccoglie le chiamate a errors.Is ed errors.As in file.
+func (b *builder) checks(pkg *packages.Package, file *ast.File, mod module) {
+	info := pkg.TypesInfo
+	for _, decl := range file.Decls {
+		function := ""
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			if obj, ok := info.Defs[fn.Name].(*types.Func); ok {
+				function = ids.Object(obj)
+			}
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return true
+			}
+			fn := callee(call, info)
+			if fn == nil || fn.Pkg() == nil || !checkPkgs[fn.Pkg().Path()] || (fn.Name() != "Is" && fn.Name() != "As") {
+				return true
+			}
+			if !b.first(call.Pos()) {
+				return true
+			}
+			kind := strings.ToLower(fn.Name())
+			target, external := "", false
+			if kind == "is" {
+				if v := packageVar(call.Args[1], info); v != nil {
+					target, external = ids.Type(v.Pkg().Path(), v.Name()), !b.inProject(v.Pos())
+				} else {
+					target = types.ExprString(call.Args[1])
+				}
+			} else {
+				if p, ok := info.TypeOf(call.Args[1]).(*types.Pointer); ok {
+					target = typeID(p.Elem())
+					if n := namedOf(p.Elem()); n != nil {
+						external = !b.inProject(n.Obj().Pos())
+					}
+				} else {
+					target = types.ExprString(call.Args[1])
+				}
+			}
+
+			key := mod.path + "\x00" + kind + "\x00" + target
+			t := b.targets[key]
+			if t == nil {
+				t = &schema.CLDKErrorTarget{Kind: kind, Target: target, External: external}
+				b.targets[key] = t
+				b.targetMod[key] = mod.path
+				b.module(mod)
+			}
+			t.Sites = append(t.Sites, schema.CLDKErrorSite{
+				Call:     callName(fn),
+				Function: function,
+				Position: loader.Position(b.fset.Position(call.Pos()), b.root),
+			})
+			return true
+		})
+	}
+}
+
+// finish collega sentinelle, tipi e controlli e ordina i moduli.
+func (b *builder) finish() *schema.CLDKErrorTaxonomy {
+	for _, t := range b.targets {
+		switch t.Kind {
+		case "is":
+			if s := b.sentinels[t.Target]; s != nil {
+				s.Checks += len(t.Sites)
+			}
+		case "as":
+			if et := b.types[strings.TrimPrefix(t.Target, "*")]; et != nil {
+				et.Checks += len(t.Sites)
+			}
+		}
+	}
+	for _, s := range b.sentinels {
+		if t := b.types[s.Type]; t != nil {
+			t.Sentinels = append(t.Sentinels, s.Variable)
+		}
+	}
+
+	for id, t := range b.types {
+		m := b.byModule[b.typeMod[id]]
+		sort.Strings(t.Sentinels)
+		m.Types = append(m.Types, *t)
+	}
+	for id, s := range b.sentinels {
+		m := b.byModule[b.typeMod[id]]
+		m.Sentinels = append(m.Sentinels, *s)
+	}
+	for key, t := range b.targets {
+		m := b.byModule[b.targetMod[key]]
+		sort.Slice(t.Sites, func(i, j int) bool { return less(t.Sites[i].Position, t.Sites[j].Position) })
+		m.Targets = append(m.Targets, *t)
+	}
+
+	tax := &schema.CLDKErrorTaxonomy{Modules: []schema.CLDKErrorModule{}}
+	for _, m := range b.byModule {
+		sort.Slice(m.Types, func(i, j int) bool { return m.Types[i].Type < m.Types[j].Type })
+		sort.Slice(m.Sentinels, func(i, j int) bool { return m.Sentinels[i].Variable < m.Sentinels[j].Variable })
+		sort.Slice(m.Targets, func(i, j int) bool {
+			a, b := m.Targets[i], m.Targets[j]
+			if a.Kind != b.Kind {
+				return a.Kind < b.Kind
+			}
+			return a.Target < b.Target
+		})
+		m.Counts.Types, m.Counts.Sentinels = len(m.Types), len(m.Sentinels)
+		for _, t := range m.Targets {
+			if t.Kind == "is" {
+				m.Counts.IsChecks += len(t.Sites)
+			} else {
+				m.Counts.AsChecks += len(t.Sites)
+			}
+		}
+		tax.Totals.Types += m.Counts.Types
+		tax.Totals.Sentinels += m.Counts.Sentinels
+		tax.Totals.IsChecks += m.Counts.IsChecks
+		tax.Totals.AsChecks += m.Counts.AsChecks
+		tax.Modules = append(tax.Modules, *m)
+	}
+	sort.Slice(tax.Modules, func(i, j int) bool { return tax.Modules[i].Module < tax.Modules[j].Module })
+	return tax
+}
+
+// module restituisce la voce del modulo mod, creandola al primo uso.
+func (b *builder) module(mod module) *schema.CLDKErrorModule {
+	m := b.byModule[mod.path]
+	if m == nil {
+		m = &schema.CLDKErrorModule{
+			Module:    mod.path,
+			Dir:       mod.dir,
+			Types:     []schema.CLDKErrorType{},
+			Sentinels: []schema.CLDKSentinel{},
+			Targets:   []schema.CLDKErrorTarget{},
+		}
+		b.byModule[mod.path] = m
+	}
+	return m
+}
+
+// moduleOf restituisce il modulo del go.mod più vicino risalendo da dir.
+func (b *builder) moduleOf(dir string) module {
+	if m, ok := b.modules[dir]; ok {
+		return m
+	}
+	var m module
+	if data, err := loader.ReadFile(b.fsys, b.root, filepath.Join(dir, "go.mod")); err == nil {
+		m.path = modfile.ModulePath(data)
+		if rel, err := filepath.Rel(b.root, dir); err == nil && loader.UnderRoot(dir, b.root) {
+			m.dir = filepath.ToSlash(rel)
+		}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		m = b.moduleOf(parent)
+	}
+	b.modules[dir] = m
+	return m
+}
+
+// first riporta se pos non è già stata vista in un'altra variante del
+// package.
+func (b *builder) first(pos token.Pos) bool {
+	p := b.fset.Position(pos)
+	if b.seen[p] {
+		return false
+	}
+	b.seen[p] = true
+	return true
+}
+
+func (b *builder) inProject(pos token.Pos) bool {
+	return pos.IsValid() && loader.UnderRoot(b.fset.Position(pos).Filename, b.root)
+}
+
+// callee restituisce la funzione o il metodo chiamato staticamente, nil per
+// chiamate dinamiche, builtin e conversioni.
+func callee(call *ast.CallExpr, info *types.Info) *types.Func {
+	var id *ast.Ident
+	switch f := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	case *ast.IndexExpr: // funzione generica istanziata
+		if sel, ok := f.X.(*ast.SelectorExpr); ok {
+			id = sel.Sel
+		} else if x, ok := f.X.(*ast.Ident); ok {
+			id = x
+		}
+	}
+	if id == nil {
+		return nil
+	}
+	fn, _ := info.Uses[id].(*types.Func)
+	return fn
+}
+
+// callName restituisce il nome di fn come "errors.New" o
+// "github.com/pkg/errors.Wrap"; per le funzioni del progetto è l'ID.
+func callName(fn *types.Func) string {
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		return fn.FullName()
+	}
+	if fn.Pkg() == nil {
+		return fn.Name()
+	}
+	return fn.Pkg().Path() + "." + fn.Name()
+}
+
+// packageVar restituisce la variabile package-level a cui si riferisce e.
+func packageVar(e ast.Expr, info *types.Info) *types.Var {
+	var id *ast.Ident
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	}
+	if id == nil {
+		return nil
+	}
+	v, ok := info.Uses[id].(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return nil
+	}
+	return v
+}
+
+// wrapVerbs restituisce gli indici degli argomenti consumati dai verbi %w
+// di format.
+func wrapVerbs(format string) []int {
+	var out []int
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.[]", format[i]) >= 0 {
+			i++
+		}
+		for i < len(format) && format[i] == '*' {
+			arg++
+			i++
+		}
+		if i >= len(format) || format[i] == '%' {
+			continue
+		}
+		if format[i] == 'w' {
+			out = append(out, arg)
+		}
+		arg++
+	}
+	return out
+}
+
+func method(mset *types.MethodSet, name string) *types.Signature {
+	for i := 0; i < mset.Len(); i++ {
+		if fn := mset.At(i).Obj(); fn.Name() == name {
+			sig, _ := fn.Type().(*types.Signature)
+			return sig
+		}
+	}
+	return nil
+}
+
+func isBool(res *types.Tuple) bool {
+	return res.Len() == 1 && types.Identical(res.At(0).Type(), types.Typ[types.Bool])
+}
+
+func isErrorSlice(t types.Type) bool {
+	s, ok := t.(*types.Slice)
+	return ok && types.Identical(s.Elem(), errType)
+}
+
+func stringConst(info *types.Info, e ast.Expr) string {
+	if tv, ok := info.Types[e]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value)
+	}
+	return ""
+}
+
+func namedOf(t types.Type) *types.Named {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, _ := types.Unalias(t).(*types.Named)
+	return n
+}
+
+// typeID restituisce l'ID del tipo nominato t, con "*" per i puntatori, o
+// la sua stringa per i tipi senza nome.
+func typeID(t types.Type) string {
+	n := namedOf(t)
+	if n == nil || n.Obj().Pkg() == nil {
+		return types.TypeString(t, nil)
+	}
+	id := ids.Type(n.Obj().Pkg().Path(), n.Obj().Name())
+	if _, ok := t.(*types.Pointer); ok {
+		return "*" + id
+	}
+	return id
+}
+
+func less(a, b *schema.CLDKPosition) bool {
+	if a.File != b.File {
+		return a.File < b.File
+	}
+	return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartColumn < b.StartColumn)
+}
@@ -0,0 +1,428 @@
+// Package facts scrive un'analisi come entries sul modello di Kythe (vedi
+// schema.KytheEntry): nodi per file, package, tipi e callable, ancore nei
+// sorgenti per dichiarazioni, import e call site, e archi satisfies e
+// overrides per le implementazioni delle interfacce.
+package facts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Write scrive le entries di analysis su w, una per riga. corpus è il
+// corpus di tutti i VName: se vuoto è il module path del progetto (o il
+// nome della sua directory). I sorgenti, letti da fsys (i sorgenti visti dal
+// loader, con radice in Metadata.ProjectPath; nil = dal disco), servono agli
+// offset delle ancore e al testo dei file; le ancore nei file non leggibili
+// sono omesse.
+func Write(w io.Writer, analysis *schema.CLDKAnalysis, corpus string, fsys fs.FS) error {
+	root := analysis.Metadata.ProjectPath
+	if corpus == "" {
+		corpus = defaultCorpus(fsys, root)
+	}
+	e := &emitter{
+		enc:     json.NewEncoder(w),
+		corpus:  corpus,
+		root:    root,
+		fsys:    fsys,
+		files:   make(map[string]*file),
+		emitted: make(map[schema.KytheVName]bool),
+		edges:   make(map[edgeKey]bool),
+	}
+	e.enc.SetEscapeHTML(false)
+
+	st := analysis.SymbolTable
+	callableIDs := make(map[string]bool)
+	if st != nil {
+		e.symbolTable(st, callableIDs)
+	}
+	if cg := analysis.CallGraph; cg != nil && cg.Granularity != "pkg" {
+		e.callGraph(cg, callableIDs)
+	}
+	return e.err
+}
+
+// defaultCorpus restituisce il module path nel go.mod di root o, senza
+// go.mod, il nome della directory.
+func defaultCorpus(fsys fs.FS, root string) string {
+	if data, err := loader.ReadFile(fsys, root, filepath.Join(root, "go.mod")); err == nil {
+		if mp := modfile.ModulePath(data); mp != "" {
+			return mp
+		}
+	}
+	return filepath.Base(root)
+}
+
+// emitter scrive le entries ricordando il primo errore; i fatti di ogni
+// nodo e ogni arco sono scritti una sola volta.
+type emitter struct {
+	enc     *json.Encoder
+	corpus  string
+	root    string
+	fsys    fs.FS
+	files   map[string]*file // file relativo → sorgente (nil = non leggibile)
+	emitted map[schema.KytheVName]bool
+	edges   map[edgeKey]bool
+	err     error
+}
+
+type edgeKey struct {
+	from, to schema.KytheVName
+	kind     string
+}
+
+type file struct {
+	vname      schema.KytheVName
+	text       []byte
+	lineStarts []int // offset dell'inizio di ogni riga
+}
+
+func (e *emitter) write(entry schema.KytheEntry) {
+	if e.err == nil {
+		e.err = e.enc.Encode(entry)
+	}
+}
+
+func (e *emitter) fact(v schema.KytheVName, name, value string) {
+	e.write(schema.KytheEntry{Source: v, FactName: name, FactValue: []byte(value)})
+}
+
+func (e *emitter) edge(from schema.KytheVName, kind string, to schema.KytheVName) {
+	k := edgeKey{from, to, kind}
+	if e.edges[k] {
+		return
+	}
+	e.edges[k] = true
+	e.write(schema.KytheEntry{Source: from, EdgeKind: kind, Target: &to, FactName: schema.KytheFactEdge})
+}
+
+// node scrive il kind (e il subkind, se non vuoto) di v la prima volta e
+// restituisce false le successive.
+func (e *emitter) node(v schema.KytheVName, kind, subkind string) bool {
+	if e.emitted[v] {
+		return false
+	}
+	e.emitted[v] = true
+	e.fact(v, schema.KytheFactNodeKind, kind)
+	if subkind != "" {
+		e.fact(v, schema.KytheFactSubkind, subkind)
+	}
+	return true
+}
+
+// semantic restituisce il VName del nodo con ID CLDK id.
+func (e *emitter) semantic(id string) schema.KytheVName {
+	return schema.KytheVName{Signature: id, Corpus: e.corpus, Language: schema.KytheLanguage}
+}
+
+func (e *emitter) pkg(path string) schema.KytheVName {
+	v := e.semantic(path)
+	e.node(v, "package", "")
+	return v
+}
+
+// file restituisce il sorgente name, scrivendone il nodo (con il testo)
+// alla prima lettura.
+func (e *emitter) file(name string) *file {
+	f, ok := e.files[name]
+	if ok {
+		return f
+	}
+	data, err := loader.ReadFile(e.fsys, e.root, filepath.Join(e.root, filepath.FromSlash(name)))
+	if err == nil {
+		f = &file{vname: schema.KytheVName{Corpus: e.corpus, Path: name}, text: data, lineStarts: []int{0}}
+		for i, b := range data {
+			if b == '\n' {
+				f.lineStarts = append(f.lineStarts, i+1)
+			}
+		}
+		e.node(f.vname, "file", "")
+		e.fact(f.vname, schema.KytheFactText, string(data))
+		e.fact(f.vname, schema.KytheFactTextEncoding, "UTF-8")
+	}
+	e.files[name] = f
+	return f
+}
+
+// offset converte riga e colonna in byte (a base 1) in un offset nel file.
+func (f *file) offset(line, col int) (int, bool) {
+	if line < 1 || line > len(f.lineStarts) || col < 1 {
+		return 0, false
+	}
+	off := f.lineStarts[line-1] + col - 1
+	if off > len(f.text) {
+		return 0, false
+	}
+	return off, true
+}
+
+// line restituisce il testo della riga che contiene off e l'offset del suo
+// inizio.
+func (f *file) line(off int) (string, int) {
+	i := sort.SearchInts(f.lineStarts, off+1) - 1
+	start := f.lineStarts[i]
+	end := bytes.IndexByte(f.text[start:], '\n')
+	if end < 0 {
+		return string(f.text[start:]), start
+	}
+	return string(f.text[start : start+end]), start
+}
+
+// anchor scrive l'ancora [start, end) di f, figlia del file, e la collega
+// a target con un arco kind.
+func (e *emitter) anchor(f *file, start, end int, kind string, target schema.KytheVName) schema.KytheVName {
+	v := schema.KytheVName{
+		Signature: fmt.Sprintf("@%d:%d", start, end),
+		Corpus:    e.corpus,
+		Path:      f.vname.Path,
+		Language:  schema.KytheLanguage,
+	}
+	if e.node(v, "anchor", "") {
+		e.fact(v, schema.KytheFactLocStart, fmt.Sprint(start))
+		e.fact(v, schema.KytheFactLocEnd, fmt.Sprint(end))
+		e.edge(v, schema.KytheEdgeChildOf, f.vname)
+	}
+	e.edge(v, kind, target)
+	return v
+}
+
+// declaration scrive le ancore della dichiarazione target: defines
+// sull'intera dichiarazione (da pos a end) e defines/binding sul nome, che
+// nameAt cerca nella riga a partire da pos.
+func (e *emitter) declaration(pos, end *schema.CLDKPosition, target schema.KytheVName, nameAt func(rest string) int, name string) {
+	if pos == nil {
+		return
+	}
+	f := e.file(pos.File)
+	if f == nil {
+		return
+	}
+	start, ok := f.offset(pos.StartLine, pos.StartColumn)
+	if !ok {
+		return
+	}
+	if end != nil && end.File == pos.File {
+		if stop, ok := f.offset(end.StartLine, end.StartColumn); ok && stop > start {
+			e.anchor(f, start, stop, schema.KytheEdgeDefines, target)
+		}
+	}
+	text, lineStart := f.line(start)
+	if i := nameAt(text[start-lineStart:]); i >= 0 {
+		e.anchor(f, start+i, start+i+len(name), schema.KytheEdgeDefinesBinding, target)
+	}
+}
+
+// symbolTable scrive package, import, tipi e callable della symbol table;
+// callableIDs raccoglie gli ID dei callable scritti.
+func (e *emitter) symbolTable(st *schema.CLDKSymbolTable, callableIDs map[string]bool) {
+	allTypes := make(map[string]*schema.CLDKType)
+	for _, pkg := range st.Packages {
+		for id, t := range pkg.TypeDeclarations {
+			allTypes[id] = t
+		}
+	}
+
+	for _, p := range slices.Sorted(maps.Keys(st.Packages)) {
+		pkg := st.Packages[p]
+		pv := e.pkg(p)
+
+		for _, imp := range pkg.Imports {
+			if imp.Position == nil || imp.Path == "C" {
+				continue
+			}
+			f := e.file(imp.Position.File)
+			if f == nil {
+				continue
+			}
+			off, ok := f.offset(imp.Position.StartLine, imp.Position.StartColumn)
+			if !ok {
+				continue
+			}
+			// Il riferimento è il letterale del path, dopo l'eventuale alias
+			text, lineStart := f.line(off)
+			rest := text[off-lineStart:]
+			i := strings.Index(rest, `"`+imp.Path+`"`)
+			if i < 0 {
+				i = strings.Index(rest, "`"+imp.Path+"`")
+			}
+			if i < 0 {
+				continue
+			}
+			e.anchor(f, off+i, off+i+len(imp.Path)+2, schema.KytheEdgeRefImports, e.pkg(imp.Path))
+		}
+
+		for _, id := range slices.Sorted(maps.Keys(pkg.TypeDeclarations)) {
+			t := pkg.TypeDeclarations[id]
+			tv := e.semantic(id)
+			kind, subkind := typeKind(t.Kind)
+			e.node(tv, kind, subkind)
+			e.edge(tv, schema.KytheEdgeChildOf, pv)
+			e.declaration(t.Position, t.EndPosition, tv, func(rest string) int {
+				if strings.HasPrefix(rest, t.Name) {
+					return 0
+				}
+				return -1
+			}, t.Name)
+			for _, m := range t.InterfaceMethods {
+				mv := e.semantic(id + "." + m.Name)
+				e.node(mv, "function", "")
+				e.fact(mv, schema.KytheFactComplete, "incomplete")
+				e.edge(mv, schema.KytheEdgeChildOf, tv)
+			}
+			for _, iface := range t.Implements {
+				e.edge(tv, schema.KytheEdgeSatisfies, e.semantic(iface))
+			}
+		}
+
+		for _, id := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+			cd := pkg.CallableDeclarations[id]
+			callableIDs[id] = true
+			cv := e.semantic(id)
+			e.node(cv, "function", "")
+			complete := "definition"
+			if cd.Implementation != "" {
+				complete = "incomplete" // senza corpo Go
+			}
+			e.fact(cv, schema.KytheFactComplete, complete)
+			e.declaration(cd.Position, cd.EndPosition, cv, func(rest string) int {
+				return funcNameAt(rest, cd.Name)
+			}, cd.Name)
+
+			recv := allTypes[p+"."+cd.ReceiverType]
+			if cd.ReceiverType == "" || recv == nil {
+				e.edge(cv, schema.KytheEdgeChildOf, pv)
+				continue
+			}
+			e.edge(cv, schema.KytheEdgeChildOf, e.semantic(p+"."+cd.ReceiverType))
+			// Il metodo implementa i metodi omonimi delle interfacce
+			// soddisfatte dal receiver
+			for _, iface := range recv.Implements {
+				it := allTypes[iface]
+				if it == nil {
+					continue
+				}
+				for _, m := range it.InterfaceMethods {
+					if m.Name == cd.Name {
+						e.edge(cv, schema.KytheEdgeOverrides, e.semantic(iface+"."+m.Name))
+					}
+				}
+			}
+		}
+	}
+}
+
+// callGraph scrive i nodi del call graph assenti dalla symbol table e un
+// ancora ref/call, figlia del chiamante, per ogni call site degli archi;
+// gli archi senza call site non hanno un'ancora e sono omessi.
+func (e *emitter) callGraph(cg *schema.CLDKCallGraph, callableIDs map[string]bool) {
+	ids := make(map[string]string, len(cg.Nodes))
+	names := make(map[string]string, len(cg.Nodes))
+	for _, n := range cg.Nodes {
+		id := n.ID
+		if n.SymbolRef != "" && callableIDs[n.SymbolRef] {
+			id = n.SymbolRef
+		}
+		ids[n.ID] = id
+		names[n.ID] = n.Name
+		if callableIDs[id] {
+			continue
+		}
+		callableIDs[id] = true
+		v := e.semantic(id)
+		e.node(v, "function", "")
+		if n.Package != "" {
+			e.edge(v, schema.KytheEdgeChildOf, e.pkg(n.Package))
+		}
+	}
+
+	for _, edge := range cg.Edges {
+		src, ok1 := ids[edge.Source]
+		dst, ok2 := ids[edge.Target]
+		if !ok1 || !ok2 {
+			continue
+		}
+		sites := edge.CallSites
+		if len(sites) == 0 && edge.CallSite != nil {
+			sites = []schema.CLDKPosition{*edge.CallSite}
+		}
+		for _, s := range sites {
+			f := e.file(s.File)
+			if f == nil {
+				continue
+			}
+			paren, ok := f.offset(s.StartLine, s.StartColumn)
+			if !ok {
+				continue
+			}
+			// Il call site punta alla parentesi: l'ancora copre il nome
+			// chiamato che la precede o, se non c'è, la sola parentesi
+			start, end := paren, paren+1
+			text, lineStart := f.line(paren)
+			if callee := names[edge.Target]; callee != "" && strings.HasSuffix(text[:paren-lineStart], callee) {
+				start, end = paren-len(callee), paren
+			}
+			a := e.anchor(f, start, end, schema.KytheEdgeRefCall, e.semantic(dst))
+			e.edge(a, schema.KytheEdgeChildOf, e.semantic(src))
+		}
+	}
+}
+
+// typeKind restituisce kind e subkind Kythe per il kind CLDK di un tipo.
+func typeKind(kind string) (string, string) {
+	switch kind {
+	case "struct":
+		return "record", "struct"
+	case "interface":
+		return "interface", ""
+	case "alias":
+		return "talias", ""
+	default:
+		return "record", "type"
+	}
+}
+
+// funcNameAt restituisce l'indice di name in rest, che inizia con la
+// parola chiave func, dopo l'eventuale receiver; -1 se il nome non è sulla
+// stessa riga.
+func funcNameAt(rest, name string) int {
+	if !strings.HasPrefix(rest, "func") {
+		return -1
+	}
+	i := len("func")
+	for i < len(rest) && (rest[i] == ' ' || rest[i] == '\t') {
+		i++
+	}
+	if i < len(rest) && rest[i] == '(' {
+		depth := 0
+		for ; i < len(rest); i++ {
+			if rest[i] == '(' {
+				depth++
+			} else if rest[i] == ')' {
+				if depth--; depth == 0 {
+					i++
+					break
+				}
+			}
+		}
+		for i < len(rest) && (rest[i] == ' ' || rest[i] == '\t') {
+			i++
+		}
+	}
+	if strings.HasPrefix(rest[i:], name) {
+		return i
+	}
+	return -1
+}
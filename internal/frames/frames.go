@@ -0,0 +1,170 @@
+// Package frames scrive un'analisi come stream di frame NDJSON (vedi
+// schema.CLDKFrame): un oggetto JSON per riga, così i client in subprocess
+// leggono package, nodi e archi uno alla volta invece di un unico documento.
+package frames
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Encoder scrive frame NDJSON e conta quelli emessi per tipo fino al
+// successivo End.
+type Encoder struct {
+	enc    *json.Encoder
+	counts map[string]int
+}
+
+// NewEncoder restituisce un Encoder che scrive su w.
+func NewEncoder(w io.Writer) *Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &Encoder{enc: enc, counts: make(map[string]int)}
+}
+
+// Emit scrive un frame.
+func (e *Encoder) Emit(f schema.CLDKFrame) error {
+	e.counts[f.Frame]++
+	return e.enc.Encode(f)
+}
+
+// Open scrive il frame hello che apre lo stream, escluso dai conteggi;
+// methods elenca i metodi accettati dal bridge (nil per uno stream di
+// output).
+func (e *Encoder) Open(version string, methods []string) error {
+	return e.enc.Encode(Hello(version, methods))
+}
+
+// End chiude lo stream o la risposta id con il conteggio dei frame emessi
+// dall'ultimo End.
+func (e *Encoder) End(id json.RawMessage) error {
+	counts := e.counts
+	e.counts = make(map[string]int)
+	return e.enc.Encode(schema.CLDKFrame{Frame: schema.FrameEnd, ID: id, Data: schema.CLDKFrameEnd{Counts: counts}})
+}
+
+// Fail chiude la risposta id con un errore.
+func (e *Encoder) Fail(id json.RawMessage, code, message string) error {
+	e.counts = make(map[string]int)
+	return e.enc.Encode(schema.CLDKFrame{Frame: schema.FrameError, ID: id, Data: schema.CLDKFrameError{Code: code, Message: message}})
+}
+
+// Hello restituisce il frame hello (vedi Open).
+func Hello(version string, methods []string) schema.CLDKFrame {
+	return schema.CLDKFrame{Frame: schema.FrameHello, Data: schema.CLDKFrameHello{
+		Protocol:        schema.FramesProtocol,
+		ProtocolVersion: schema.FramesVersion,
+		SchemaVersion:   schema.SchemaVersion,
+		Analyzer:        "codeanalyzer-go",
+		Version:         version,
+		Methods:         methods,
+	}}
+}
+
+// Write scrive analysis come stream completo: hello, metadata, package,
+// callgraph, node, edge, section, issue ed end.
+func Write(w io.Writer, analysis *schema.CLDKAnalysis) error {
+	e := NewEncoder(w)
+	if err := e.Open(analysis.Metadata.Version, nil); err != nil {
+		return err
+	}
+	if err := e.Emit(schema.CLDKFrame{Frame: schema.FrameMetadata, Data: analysis.Metadata}); err != nil {
+		return err
+	}
+	if err := Packages(e, nil, analysis.SymbolTable, nil); err != nil {
+		return err
+	}
+	if err := CallGraph(e, nil, analysis.CallGraph); err != nil {
+		return err
+	}
+	if err := Sections(e, nil, analysis); err != nil {
+		return err
+	}
+	if err := Issues(e, nil, analysis.Issues); err != nil {
+		return err
+	}
+	return e.End(nil)
+}
+
+// Packages emette un frame package per ogni package della symbol table in
+// ordine di import path, solo quelli accettati da keep se non è nil.
+func Packages(e *Encoder, id json.RawMessage, st *schema.CLDKSymbolTable, keep func(string) bool) error {
+	if st == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(st.Packages))
+	for p := range st.Packages {
+		if keep == nil || keep(p) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if err := e.Emit(schema.CLDKFrame{Frame: schema.FramePackage, ID: id, Name: p, Data: st.Packages[p]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CallGraph emette il frame callgraph seguito da nodi e archi.
+func CallGraph(e *Encoder, id json.RawMessage, cg *schema.CLDKCallGraph) error {
+	if cg == nil {
+		return nil
+	}
+	err := e.Emit(schema.CLDKFrame{Frame: schema.FrameCallGraph, ID: id, Data: schema.CLDKFrameCallGraph{
+		Algorithm:   cg.Algorithm,
+		Granularity: cg.Granularity,
+		Nodes:       len(cg.Nodes),
+		Edges:       len(cg.Edges),
+	}})
+	if err != nil {
+		return err
+	}
+	for i := range cg.Nodes {
+		if err := e.Emit(schema.CLDKFrame{Frame: schema.FrameNode, ID: id, Data: &cg.Nodes[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range cg.Edges {
+		if err := e.Emit(schema.CLDKFrame{Frame: schema.FrameEdge, ID: id, Data: &cg.Edges[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Issues emette un frame issue per issue.
+func Issues(e *Encoder, id json.RawMessage, issues []schema.Issue) error {
+	for i := range issues {
+		if err := e.Emit(schema.CLDKFrame{Frame: schema.FrameIssue, ID: id, Data: &issues[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamed sono i campi di CLDKAnalysis emessi con frame propri.
+var streamed = map[string]bool{"metadata": true, "symbol_table": true, "call_graph": true, "issues": true}
+
+// Sections emette un frame section per ogni altro campo valorizzato di
+// analysis (pdg, sdg, metrics, cycles, ...), con il nome del campo JSON.
+func Sections(e *Encoder, id json.RawMessage, analysis *schema.CLDKAnalysis) error {
+	v := reflect.ValueOf(analysis).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || streamed[name] || v.Field(i).IsZero() {
+			continue
+		}
+		if err := e.Emit(schema.CLDKFrame{Frame: schema.FrameSection, ID: id, Name: name, Data: v.Field(i).Interface()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
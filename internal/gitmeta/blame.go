@@ -0,0 +1,164 @@
+// Package gitmeta arricchisce la symbol table con metadati di ownership
+// (ultimo commit, autore, età) ricavati da git blame ed elenca i file
+// modificati rispetto a una revisione.
+package gitmeta
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// zeroCommit è lo SHA usato da git blame per le righe non ancora committate.
+const zeroCommit = "0000000000000000000000000000000000000000"
+
+// lineInfo contiene il commit che ha modificato per ultimo una riga.
+type lineInfo struct {
+	Commit      string
+	Author      string
+	AuthorEmail string
+	Time        int64 // unix timestamp (author-time)
+}
+
+// Blamer esegue git blame sui file del progetto con cache per file.
+type Blamer struct {
+	root  string
+	now   time.Time
+	cache map[string][]lineInfo // file relativo → info per riga (indice = riga-1)
+}
+
+// NewBlamer crea un Blamer per il repository che contiene root.
+// Restituisce errore se git non è disponibile o root non è in un work tree.
+func NewBlamer(root string) (*Blamer, error) {
+	cmd := exec.Command("git", "-C", root, "rev-parse", "--is-inside-work-tree")
+	out, err := cmd.Output()
+	if err != nil || strings.TrimSpace(string(out)) != "true" {
+		return nil, fmt.Errorf("%s is not inside a git work tree", root)
+	}
+	return &Blamer{
+		root:  root,
+		now:   time.Now(),
+		cache: make(map[string][]lineInfo),
+	}, nil
+}
+
+// Enrich popola GitMetadata per callable, metodi e tipi della symbol table.
+// I file non tracciati o non leggibili da git vengono ignorati.
+func Enrich(st *schema.CLDKSymbolTable, root string) error {
+	if st == nil {
+		return nil
+	}
+	b, err := NewBlamer(root)
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range st.Packages {
+		for _, cd := range pkg.CallableDeclarations {
+			cd.GitMetadata = b.Span(cd.Position, cd.EndPosition)
+		}
+		for _, td := range pkg.TypeDeclarations {
+			td.GitMetadata = b.Span(td.Position, td.EndPosition)
+			for _, m := range td.Methods {
+				m.GitMetadata = b.Span(m.Position, m.EndPosition)
+			}
+		}
+	}
+	return nil
+}
+
+// Span restituisce il commit più recente tra le righe comprese tra start e end.
+// Se end è nil viene considerata solo la riga iniziale.
+func (b *Blamer) Span(start, end *schema.CLDKPosition) *schema.CLDKGitMetadata {
+	if start == nil || start.File == "" {
+		return nil
+	}
+	lines := b.blame(start.File)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	from := start.StartLine
+	to := from
+	if end != nil && end.File == start.File && end.StartLine >= from {
+		to = end.StartLine
+	}
+
+	var latest *lineInfo
+	for ln := from; ln <= to && ln <= len(lines); ln++ {
+		li := &lines[ln-1]
+		if li.Commit == "" || li.Commit == zeroCommit {
+			continue
+		}
+		if latest == nil || li.Time > latest.Time {
+			latest = li
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+
+	t := time.Unix(latest.Time, 0).UTC()
+	return &schema.CLDKGitMetadata{
+		Commit:      latest.Commit,
+		Author:      latest.Author,
+		AuthorEmail: latest.AuthorEmail,
+		Timestamp:   t.Format(time.RFC3339),
+		AgeDays:     int(b.now.Sub(t).Hours() / 24),
+	}
+}
+
+// blame esegue (una sola volta per file) git blame --line-porcelain.
+func (b *Blamer) blame(file string) []lineInfo {
+	if lines, ok := b.cache[file]; ok {
+		return lines
+	}
+
+	cmd := exec.Command("git", "-C", b.root, "blame", "--line-porcelain", "--", file)
+	out, err := cmd.Output()
+	if err != nil {
+		// File non tracciato o fuori dal repository
+		b.cache[file] = nil
+		return nil
+	}
+
+	lines := parsePorcelain(out)
+	b.cache[file] = lines
+	return lines
+}
+
+// parsePorcelain interpreta l'output di git blame --line-porcelain.
+// Ogni riga del sorgente è preceduta da un header "<sha> <orig> <final> [n]"
+// seguito dagli attributi del commit e dalla riga stessa prefissata da TAB.
+func parsePorcelain(out []byte) []lineInfo {
+	var lines []lineInfo
+	var cur lineInfo
+
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		text := sc.Text()
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			lines = append(lines, cur)
+			cur = lineInfo{}
+		case strings.HasPrefix(text, "author "):
+			cur.Author = strings.TrimPrefix(text, "author ")
+		case strings.HasPrefix(text, "author-mail "):
+			cur.AuthorEmail = strings.Trim(strings.TrimPrefix(text, "author-mail "), "<>")
+		case strings.HasPrefix(text, "author-time "):
+			cur.Time, _ = strconv.ParseInt(strings.TrimPrefix(text, "author-time "), 10, 64)
+		default:
+			if fields := strings.Fields(text); len(fields) >= 3 && len(fields[0]) == 40 && cur.Commit == "" {
+				cur.Commit = fields[0]
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,59 @@
+package gitmeta
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ChangedFiles restituisce i file modificati rispetto alla revisione since
+// (commit, branch o tag), modifiche non committate e file non tracciati
+// compresi. I path sono relativi a root, con separatore '/', ordinati; i
+// file fuori da root sono esclusi.
+func ChangedFiles(root, since string) ([]string, error) {
+	if _, err := NewBlamer(root); err != nil {
+		return nil, err
+	}
+	diff, err := gitLines(root, "diff", "--name-only", "--relative", since, "--")
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %w", since, err)
+	}
+	untracked, err := gitLines(root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var out []string
+	for _, f := range append(diff, untracked...) {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// gitLines esegue git in root e restituisce le righe non vuote dell'output.
+func gitLines(root string, args ...string) ([]string, error) {
+	cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		return nil, err
+	}
+	var lines []string
+	for _, l := range strings.Split(string(out), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,289 @@
+// Package globals costruisce il grafo degli accessi alle variabili
+// package-level del progetto: per ogni variabile, le funzioni che la
+// leggono, la scrivono o ne prendono l'indirizzo, ricavate dagli usi
+// nell'AST tipato.
+package globals
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Kind degli accessi.
+const (
+	Read    = "read"
+	Write   = "write"
+	Address = "address"
+)
+
+type builder struct {
+	fset *token.FileSet
+	root string
+	vars map[*types.Var]*schema.CLDKGlobal
+	byID map[string]*schema.CLDKGlobal // le varianti di test hanno oggetti diversi per la stessa variabile
+	seen map[token.Position]bool
+}
+
+// Inventory restituisce gli accessi alle variabili package-level dichiarate
+// nei file sotto root, nil se non ce ne sono. Le variabili "_" e quelle
+// dichiarate fuori dal progetto non sono incluse.
+func Inventory(pkgs []*packages.Package, fset *token.FileSet, root string) *schema.CLDKGlobals {
+	b := &builder{
+		fset: fset,
+		root: root,
+		vars: make(map[*types.Var]*schema.CLDKGlobal),
+		byID: make(map[string]*schema.CLDKGlobal),
+		seen: make(map[token.Position]bool), // le varianti di test ripetono i file
+	}
+	var files []*ast.File
+	var owners []*packages.Package
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil || !loader.UnderRoot(fset.Position(file.Pos()).Filename, root) {
+				continue
+			}
+			b.declarations(pkg, file)
+			files = append(files, file)
+			owners = append(owners, pkg)
+		}
+	}
+	if len(b.byID) == 0 {
+		return nil
+	}
+	for i, file := range files {
+		b.accesses(owners[i], file)
+	}
+	return b.finish()
+}
+
+// declarations registra le variabili package-level di file.
+func (b *builder) declarations(pkg *packages.Package, file *ast.File) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				obj, ok := pkg.TypesInfo.Defs[name].(*types.Var)
+				if !ok || name.Name == "_" {
+					continue
+				}
+				id := ids.Type(obj.Pkg().Path(), obj.Name())
+				g := b.byID[id]
+				if g == nil {
+					g = &schema.CLDKGlobal{
+						Variable: id,
+						Package:  obj.Pkg().Path(),
+						Type:     types.TypeString(obj.Type(), nil),
+						Exported: obj.Exported(),
+						Position: loader.Position(b.fset.Position(obj.Pos()), b.root),
+					}
+					b.byID[id] = g
+				}
+				b.vars[obj] = g
+			}
+		}
+	}
+}
+
+// accesses registra gli usi delle variabili globali in file. Gli usi negli
+// inizializzatori di package non hanno funzione; quelli nelle closure sono
+// della funzione che le contiene.
+func (b *builder) accesses(pkg *packages.Package, file *ast.File) {
+	info := pkg.TypesInfo
+	for _, decl := range file.Decls {
+		function := ""
+		if fd, ok := decl.(*ast.FuncDecl); ok {
+			if obj, ok := info.Defs[fd.Name].(*types.Func); ok {
+				function = ids.Object(obj)
+			}
+		}
+		kinds := accessKinds(decl, info)
+		ast.Inspect(decl, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			g := b.global(info.Uses[ident])
+			if g == nil {
+				return true
+			}
+			pos := b.fset.Position(ident.Pos())
+			if b.seen[pos] {
+				return true
+			}
+			b.seen[pos] = true
+			kind := kinds[ident]
+			if kind == "" {
+				kind = Read
+			}
+			g.Accesses = append(g.Accesses, schema.CLDKGlobalAccess{
+				Kind:     kind,
+				Function: function,
+				Package:  pkg.PkgPath,
+				Position: loader.Position(b.fset.Position(ident.Pos()), b.root),
+			})
+			return true
+		})
+	}
+}
+
+// global restituisce la voce della variabile package-level obj, nil se obj
+// non è una globale del progetto.
+func (b *builder) global(obj types.Object) *schema.CLDKGlobal {
+	v, ok := obj.(*types.Var)
+	if !ok || v.IsField() || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return nil
+	}
+	if g, ok := b.vars[v]; ok {
+		return g
+	}
+	return b.byID[ids.Type(v.Pkg().Path(), v.Name())]
+}
+
+// accessKinds marca gli identificatori scritti (lato sinistro di un
+// assegnamento, ++/--, variabili di un range con =) e quelli di cui si
+// prende l'indirizzo (&x, metodo con receiver puntatore su un valore
+// indirizzabile). Un campo o un elemento scritto conta come scrittura della
+// variabile che lo contiene; la scrittura attraverso *p no.
+func accessKinds(root ast.Node, info *types.Info) map[*ast.Ident]string {
+	kinds := make(map[*ast.Ident]string)
+	mark := func(e ast.Expr, kind string) {
+		if id := rootIdent(e, info); id != nil {
+			kinds[id] = kind
+		}
+	}
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.AssignStmt:
+			if x.Tok != token.DEFINE {
+				for _, lhs := range x.Lhs {
+					mark(lhs, Write)
+				}
+			}
+		case *ast.IncDecStmt:
+			mark(x.X, Write)
+		case *ast.RangeStmt:
+			if x.Tok == token.ASSIGN {
+				if x.Key != nil {
+					mark(x.Key, Write)
+				}
+				if x.Value != nil {
+					mark(x.Value, Write)
+				}
+			}
+		case *ast.UnaryExpr:
+			if x.Op == token.AND {
+				mark(x.X, Address)
+			}
+		case *ast.SelectorExpr:
+			sel, ok := info.Selections[x]
+			if !ok || sel.Kind() != types.MethodVal {
+				break
+			}
+			if _, ptrRecv := sel.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer); !ptrRecv {
+				break
+			}
+			if _, ptr := sel.Recv().Underlying().(*types.Pointer); !ptr {
+				mark(x.X, Address)
+			}
+		}
+		return true
+	})
+	return kinds
+}
+
+// rootIdent risale selettori di campo, indici e parentesi fino
+// all'identificatore della variabile; nil attraverso una dereferenziazione
+// esplicita o una chiamata.
+func rootIdent(e ast.Expr, info *types.Info) *ast.Ident {
+	for {
+		switch x := e.(type) {
+		case *ast.Ident:
+			return x
+		case *ast.ParenExpr:
+			e = x.X
+		case *ast.IndexExpr:
+			e = x.X
+		case *ast.IndexListExpr:
+			e = x.X
+		case *ast.SelectorExpr:
+			if id, ok := x.X.(*ast.Ident); ok {
+				if _, pkg := info.Uses[id].(*types.PkgName); pkg {
+					return x.Sel
+				}
+			}
+			e = x.X
+		default:
+			return nil
+		}
+	}
+}
+
+func (b *builder) finish() *schema.CLDKGlobals {
+	out := &schema.CLDKGlobals{Variables: make([]schema.CLDKGlobal, 0, len(b.byID))}
+	for _, g := range b.byID {
+		readers := make(map[string]bool)
+		writers := make(map[string]bool)
+		pkgs := make(map[string]bool)
+		for _, a := range g.Accesses {
+			switch a.Kind {
+			case Read:
+				g.Counts.Reads++
+				readers[a.Function] = true
+			case Write:
+				g.Counts.Writes++
+				writers[a.Function] = true
+			case Address:
+				g.Counts.Address++
+				writers[a.Function] = true
+			}
+			pkgs[a.Package] = true
+		}
+		g.Readers, g.Writers, g.Packages = keys(readers), keys(writers), keys(pkgs)
+		if g.Accesses == nil {
+			g.Accesses = []schema.CLDKGlobalAccess{}
+		}
+		sort.Slice(g.Accesses, func(i, j int) bool {
+			a, b := g.Accesses[i].Position, g.Accesses[j].Position
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			if a.StartLine != b.StartLine {
+				return a.StartLine < b.StartLine
+			}
+			return a.StartColumn < b.StartColumn
+		})
+		out.Totals.Reads += g.Counts.Reads
+		out.Totals.Writes += g.Counts.Writes
+		out.Totals.Address += g.Counts.Address
+		out.Variables = append(out.Variables, *g)
+	}
+	sort.Slice(out.Variables, func(i, j int) bool { return out.Variables[i].Variable < out.Variables[j].Variable })
+	return out
+}
+
+// keys restituisce le chiavi ordinate di m, senza la funzione vuota degli
+// inizializzatori di package.
+func keys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		if k != "" {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"sort"
+)
+
+// virtualRoot è la radice sintetica usata quando ci sono più entry point.
+const virtualRoot = "<root>"
+
+// Dominators calcola il dominator tree del grafo a partire dalle radici date
+// (algoritmo iterativo di Cooper, Harvey e Kennedy). Con più radici viene
+// introdotta una radice virtuale; i nodi dominati solo da essa hanno idom "".
+// Restituisce la mappa nodo → immediate dominator per i nodi raggiungibili.
+func (idx *Index) Dominators(roots []string) map[string]string {
+	root := virtualRoot
+	succ := idx.Succ
+	if len(roots) == 1 {
+		root = roots[0]
+	} else {
+		succ = make(map[string][]string, len(idx.Succ)+1)
+		for k, v := range idx.Succ {
+			succ[k] = v
+		}
+		succ[virtualRoot] = roots
+	}
+
+	// Reverse postorder dalla radice (DFS iterativa)
+	var post []string
+	visited := map[string]bool{root: true}
+	type frame struct {
+		node string
+		edge int
+	}
+	stack := []frame{{node: root}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.edge < len(succ[top.node]) {
+			next := succ[top.node][top.edge]
+			top.edge++
+			if !visited[next] {
+				visited[next] = true
+				stack = append(stack, frame{node: next})
+			}
+			continue
+		}
+		post = append(post, top.node)
+		stack = stack[:len(stack)-1]
+	}
+	order := make(map[string]int, len(post)) // postorder number
+	for i, n := range post {
+		order[n] = i
+	}
+
+	// Predecessori ristretti ai nodi raggiungibili
+	pred := make(map[string][]string)
+	for _, n := range post {
+		for _, s := range succ[n] {
+			pred[s] = append(pred[s], n)
+		}
+	}
+
+	idom := map[string]string{root: root}
+	intersect := func(a, b string) string {
+		for a != b {
+			for order[a] < order[b] {
+				a = idom[a]
+			}
+			for order[b] < order[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i := len(post) - 1; i >= 0; i-- {
+			n := post[i]
+			if n == root {
+				continue
+			}
+			newIdom := ""
+			for _, p := range pred[n] {
+				if _, ok := idom[p]; !ok {
+					continue
+				}
+				if newIdom == "" {
+					newIdom = p
+				} else {
+					newIdom = intersect(p, newIdom)
+				}
+			}
+			if newIdom != "" && idom[n] != newIdom {
+				idom[n] = newIdom
+				changed = true
+			}
+		}
+	}
+
+	out := make(map[string]string, len(idom))
+	for n, d := range idom {
+		if n == root || n == virtualRoot {
+			continue
+		}
+		if d == virtualRoot {
+			d = ""
+		}
+		out[n] = d
+	}
+	return out
+}
+
+// EntryPoints restituisce gli ID dei nodi main e init presenti nel grafo, ordinati.
+func (idx *Index) EntryPoints() []string {
+	var roots []string
+	for _, id := range idx.Nodes {
+		n := idx.byID[id]
+		if n.Name == "main" || n.Name == "init" {
+			roots = append(roots, id)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
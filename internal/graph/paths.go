@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Index è una vista del call graph con liste di adiacenza diretta e inversa.
+type Index struct {
+	Nodes []string
+	Succ  map[string][]string
+	Pred  map[string][]string
+	byID  map[string]*schema.CLDKCGNode
+}
+
+// NewIndex costruisce l'indice di adiacenza di un call graph CLDK.
+func NewIndex(cg *schema.CLDKCallGraph) *Index {
+	idx := &Index{
+		Succ: make(map[string][]string),
+		Pred: make(map[string][]string),
+		byID: make(map[string]*schema.CLDKCGNode, len(cg.Nodes)),
+	}
+	for i := range cg.Nodes {
+		n := &cg.Nodes[i]
+		idx.Nodes = append(idx.Nodes, n.ID)
+		idx.byID[n.ID] = n
+	}
+	for _, e := range cg.Edges {
+		idx.Succ[e.Source] = append(idx.Succ[e.Source], e.Target)
+		idx.Pred[e.Target] = append(idx.Pred[e.Target], e.Source)
+	}
+	return idx
+}
+
+// Resolve trova il node ID corrispondente a un nome. Accetta l'ID esatto
+// oppure un suffisso (es. "main.main", "(*Server).Start", "Greet").
+// Restituisce errore se il nome è ambiguo o non presente.
+func (idx *Index) Resolve(name string) (string, error) {
+	if _, ok := idx.byID[name]; ok {
+		return name, nil
+	}
+	var matches []string
+	for _, id := range idx.Nodes {
+		if strings.HasSuffix(id, "."+name) || strings.HasSuffix(id, "/"+name) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("function %q not found in call graph", name)
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(matches)
+		if len(matches) > 5 {
+			matches = append(matches[:5], "...")
+		}
+		return "", fmt.Errorf("function %q is ambiguous: %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// Paths enumera fino a k cammini semplici da from a to, in ordine di
+// lunghezza crescente, con al massimo maxDepth archi ciascuno.
+// Le distanze inverse dal target sono usate per potare i rami che non
+// possono raggiungerlo entro il limite di profondità.
+func (idx *Index) Paths(from, to string, k, maxDepth int) [][]string {
+	if k <= 0 {
+		k = 1
+	}
+	if maxDepth <= 0 {
+		maxDepth = 20
+	}
+
+	// Distanza (in archi) da ogni nodo al target, via BFS sul grafo inverso
+	dist := map[string]int{to: 0}
+	queue := []string{to}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, p := range idx.Pred[cur] {
+			if _, ok := dist[p]; !ok {
+				dist[p] = dist[cur] + 1
+				queue = append(queue, p)
+			}
+		}
+	}
+	if d, ok := dist[from]; !ok || d > maxDepth {
+		return [][]string{}
+	}
+
+	// BFS su cammini parziali: i cammini escono in ordine di lunghezza
+	var result [][]string
+	paths := [][]string{{from}}
+	for len(paths) > 0 && len(result) < k {
+		path := paths[0]
+		paths = paths[1:]
+		last := path[len(path)-1]
+		if last == to {
+			result = append(result, path)
+			continue
+		}
+		for _, next := range idx.Succ[last] {
+			d, ok := dist[next]
+			if !ok || len(path)+d > maxDepth || slices.Contains(path, next) {
+				continue
+			}
+			np := make([]string, len(path)+1)
+			copy(np, path)
+			np[len(path)] = next
+			paths = append(paths, np)
+		}
+	}
+	if result == nil {
+		result = [][]string{}
+	}
+	return result
+}
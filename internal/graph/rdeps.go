@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// ReverseClosure restituisce i nodi da cui uno dei seeds è raggiungibile
+// seguendo adj, con la distanza minima: sul grafo degli import sono i
+// package che importano i seeds, anche transitivamente. I seeds hanno
+// distanza 0 e sono inclusi anche se assenti dal grafo.
+func ReverseClosure(adj map[string][]string, seeds []string) map[string]int {
+	rev := make(map[string][]string)
+	for from, tos := range adj {
+		for _, to := range tos {
+			rev[to] = append(rev[to], from)
+		}
+	}
+
+	dist := make(map[string]int, len(seeds))
+	var queue []string
+	for _, s := range seeds {
+		if _, ok := dist[s]; !ok {
+			dist[s] = 0
+			queue = append(queue, s)
+		}
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, prev := range rev[cur] {
+			if _, ok := dist[prev]; !ok {
+				dist[prev] = dist[cur] + 1
+				queue = append(queue, prev)
+			}
+		}
+	}
+	return dist
+}
+
+// ImportGraph restituisce il grafo degli import tra i package della symbol
+// table (package → package importati); gli import esterni sono esclusi.
+func ImportGraph(st *schema.CLDKSymbolTable) map[string][]string {
+	adj := make(map[string][]string)
+	for pkgPath, pkg := range st.Packages {
+		for _, imp := range pkg.Imports {
+			if _, ok := st.Packages[imp.Path]; ok {
+				adj[pkgPath] = append(adj[pkgPath], imp.Path)
+			}
+		}
+	}
+	return adj
+}
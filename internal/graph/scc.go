@@ -0,0 +1,175 @@
+// Package graph fornisce algoritmi generici sui grafi (SCC, cicli) applicati
+// al call graph e al grafo degli import CLDK.
+package graph
+
+import (
+	"sort"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// SCC calcola le componenti fortemente connesse con l'algoritmo di Tarjan
+// (versione iterativa, per evitare stack overflow su grafi profondi).
+// Le componenti e i loro membri sono ordinati per stabilità dell'output.
+func SCC(nodes []string, adj map[string][]string) [][]string {
+	index := make(map[string]int, len(nodes))
+	low := make(map[string]int, len(nodes))
+	onStack := make(map[string]bool, len(nodes))
+	var stack []string
+	var comps [][]string
+	next := 0
+
+	type frame struct {
+		node string
+		edge int
+	}
+
+	for _, root := range nodes {
+		if _, seen := index[root]; seen {
+			continue
+		}
+
+		work := []frame{{node: root}}
+		index[root], low[root] = next, next
+		next++
+		stack = append(stack, root)
+		onStack[root] = true
+
+		for len(work) > 0 {
+			top := &work[len(work)-1]
+			succs := adj[top.node]
+			if top.edge < len(succs) {
+				w := succs[top.edge]
+				top.edge++
+				if _, seen := index[w]; !seen {
+					index[w], low[w] = next, next
+					next++
+					stack = append(stack, w)
+					onStack[w] = true
+					work = append(work, frame{node: w})
+				} else if onStack[w] && index[w] < low[top.node] {
+					low[top.node] = index[w]
+				}
+				continue
+			}
+
+			// Tutti i successori visitati: chiudi il frame
+			v := top.node
+			work = work[:len(work)-1]
+			if len(work) > 0 {
+				parent := work[len(work)-1].node
+				if low[v] < low[parent] {
+					low[parent] = low[v]
+				}
+			}
+			if low[v] == index[v] {
+				var comp []string
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					comp = append(comp, w)
+					if w == v {
+						break
+					}
+				}
+				sort.Strings(comp)
+				comps = append(comps, comp)
+			}
+		}
+	}
+
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i][0] < comps[j][0]
+	})
+	return comps
+}
+
+// Cycles restituisce solo le SCC che formano un ciclo: componenti con più
+// di un nodo oppure nodi singoli con un self-loop.
+func Cycles(nodes []string, adj map[string][]string) [][]string {
+	var out [][]string
+	for _, comp := range SCC(nodes, adj) {
+		if len(comp) > 1 || hasSelfLoop(comp[0], adj) {
+			out = append(out, comp)
+		}
+	}
+	return out
+}
+
+func hasSelfLoop(n string, adj map[string][]string) bool {
+	for _, s := range adj[n] {
+		if s == n {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// Cycle report
+// ============================================================================
+
+// CycleReport costruisce il report dei cicli: gruppi di ricorsione (funzioni
+// mutuamente ricorsive) dal call graph e cicli di import dalla symbol table.
+// Entrambi gli input sono opzionali.
+func CycleReport(st *schema.CLDKSymbolTable, cg *schema.CLDKCallGraph) *schema.CLDKCycleReport {
+	report := &schema.CLDKCycleReport{
+		RecursionGroups: []schema.CLDKCycle{},
+		ImportCycles:    []schema.CLDKCycle{},
+	}
+
+	if cg != nil {
+		nodes := make([]string, 0, len(cg.Nodes))
+		for _, n := range cg.Nodes {
+			nodes = append(nodes, n.ID)
+		}
+		adj := make(map[string][]string)
+		for _, e := range cg.Edges {
+			adj[e.Source] = append(adj[e.Source], e.Target)
+		}
+		for _, comp := range Cycles(nodes, adj) {
+			report.RecursionGroups = append(report.RecursionGroups, newCycle(comp, adj))
+		}
+	}
+
+	if st != nil {
+		nodes := make([]string, 0, len(st.Packages))
+		for pkgPath := range st.Packages {
+			nodes = append(nodes, pkgPath)
+		}
+		sort.Strings(nodes)
+		adj := ImportGraph(st)
+		for _, comp := range Cycles(nodes, adj) {
+			report.ImportCycles = append(report.ImportCycles, newCycle(comp, adj))
+		}
+	}
+
+	return report
+}
+
+// newCycle costruisce un record di ciclo con gli archi interni alla componente.
+func newCycle(members []string, adj map[string][]string) schema.CLDKCycle {
+	in := make(map[string]bool, len(members))
+	for _, m := range members {
+		in[m] = true
+	}
+	c := schema.CLDKCycle{
+		Members: members,
+		Size:    len(members),
+	}
+	for _, m := range members {
+		for _, s := range adj[m] {
+			if in[s] {
+				c.Edges = append(c.Edges, [2]string{m, s})
+			}
+		}
+	}
+	sort.Slice(c.Edges, func(i, j int) bool {
+		if c.Edges[i][0] == c.Edges[j][0] {
+			return c.Edges[i][1] < c.Edges[j][1]
+		}
+		return c.Edges[i][0] < c.Edges[j][0]
+	})
+	return c
+}
@@ -0,0 +1,29 @@
+// Package ids genera gli identificatori stabili usati per fare join tra
+// symbol table, call graph, PDG, SDG e summaries.
+//
+// Un ID dipende solo dal package path e dai nomi dichiarati nel sorgente,
+// mai da indirizzi, ordine di caricamento o istanziazioni generiche: la
+// stessa funzione produce lo stesso ID sia dall'AST (symbols) sia da SSA
+// (callgraph, pdg, summary).
+//
+// Grammatica:
+//
+//	ID       = FuncID | MethodID | TypeID
+//	FuncID   = PkgPath "." Name
+//	MethodID = PkgPath "." Recv "." Ident
+//	TypeID   = PkgPath "." Ident
+//	Recv     = Ident | "(*" Ident ")"
+//	Name     = Ident { "$" Suffix } | "init#" Digits
+//	Suffix   = Digits | "bound" | "thunk"
+//
+// PkgPath è l'import path completo (può contenere "." e "/"); per questo il
+// confine tra package e nome è sempre l'ultimo "." che precede Name, o il
+// "." che precede "(*" per i receiver pointer. Gli argomenti di tipo dei
+// generici (es. "List[int]") sono rimossi sia dal receiver sia dal nome,
+// quindi tutte le istanziazioni condividono l'ID della dichiarazione
+// generica. Le closure ereditano l'ID della funzione che le contiene con
+// suffisso "$N" (es. "pkg.main$1"); i wrapper sintetici SSA mantengono il
+// suffisso "$bound" o "$thunk". Funzioni senza package (builtin) usano il
+// solo nome. I metodi astratti di un'interfaccia usano MethodID con il nome
+// dell'interfaccia come Recv (es. "io.Reader.Read").
+package ids
@@ -0,0 +1,176 @@
+package ids
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Func restituisce l'ID di una funzione package-level.
+func Func(pkgPath, name string) string {
+	return pkgPath + "." + StripTypeArgs(name)
+}
+
+// Method restituisce l'ID di un metodo con receiver recvType (senza "*").
+func Method(pkgPath, recvType string, ptr bool, name string) string {
+	recv := StripTypeArgs(recvType)
+	if ptr {
+		recv = "(*" + recv + ")"
+	}
+	return pkgPath + "." + recv + "." + name
+}
+
+// Type restituisce l'ID di un tipo (o di una variabile/costante) package-level.
+func Type(pkgPath, name string) string {
+	return pkgPath + "." + name
+}
+
+// InterfaceMethod restituisce l'ID del metodo astratto m nell'interfaccia
+// che lo dichiara (es. "pkg.Greeter.Greet"), anche se la chiamata passa per
+// un'interfaccia che la incorpora. Per interfacce senza nome usa la stringa
+// del metodo (es. "(interface{Greet() string}).Greet").
+func InterfaceMethod(m *types.Func) string {
+	if m == nil {
+		return ""
+	}
+	sig, ok := m.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return m.FullName()
+	}
+	recv := types.Unalias(sig.Recv().Type())
+	if n, ok := recv.(*types.Named); ok && n.Obj().Pkg() != nil {
+		return Method(n.Obj().Pkg().Path(), n.Obj().Name(), false, m.Name())
+	}
+	return "(" + types.TypeString(recv, nil) + ")." + m.Name()
+}
+
+// SSAFunc restituisce l'ID di una funzione SSA secondo la grammatica del
+// package. Restituisce "" per f nil.
+func SSAFunc(f *ssa.Function) string {
+	if f == nil {
+		return ""
+	}
+
+	// Le istanziazioni generiche (e le loro closure) non hanno Pkg:
+	// si risale alla dichiarazione generica
+	if o := f.Origin(); o != nil {
+		f = o
+	}
+	pkgFn := f
+	for pkgFn.Pkg == nil && pkgFn.Parent() != nil {
+		pkgFn = pkgFn.Parent()
+		if o := pkgFn.Origin(); o != nil {
+			pkgFn = o
+		}
+	}
+
+	// Builtins e funzioni senza package
+	if pkgFn.Pkg == nil || pkgFn.Pkg.Pkg == nil {
+		if f.Name() != "" {
+			return StripTypeArgs(f.Name())
+		}
+		return f.String()
+	}
+
+	pkg := pkgFn.Pkg.Pkg.Path()
+	if f.Signature != nil && f.Signature.Recv() != nil {
+		recv, ptr := receiverName(f.Signature.Recv().Type())
+		return Method(pkg, recv, ptr, StripTypeArgs(f.Name()))
+	}
+	return Func(pkg, f.Name())
+}
+
+// Object restituisce l'ID di una funzione o di un metodo concreto dichiarato
+// nel sorgente, a partire dal suo oggetto go/types: coincide con la chiave
+// della symbol table.
+func Object(fn *types.Func) string {
+	fn = fn.Origin()
+	if fn.Pkg() == nil {
+		return fn.Name()
+	}
+	pkg := fn.Pkg().Path()
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		recv, ptr := receiverName(sig.Recv().Type())
+		return Method(pkg, recv, ptr, fn.Name())
+	}
+	return Func(pkg, fn.Name())
+}
+
+// SymbolRef restituisce l'ID della dichiarazione sorgente di f, cioè la sua
+// chiave in callable_declarations: le closure risalgono alla funzione che le
+// contiene, i wrapper sintetici (bound, thunk, metodi promossi da un campo
+// embedded, receiver pointer implicito) al metodo dichiarato. Le funzioni
+// init (SSA "init#N", numerate in ordine di sorgente) seguono le chiavi
+// della symbol table: "init" la prima, "init#N" le successive. Restituisce
+// "" per le funzioni senza dichiarazione, come l'inizializzatore di package
+// o i thunk di metodi d'interfaccia.
+func SymbolRef(f *ssa.Function) string {
+	for f != nil {
+		if o := f.Origin(); o != nil {
+			f = o
+		}
+		if f.Parent() == nil {
+			break
+		}
+		f = f.Parent()
+	}
+	if f == nil {
+		return ""
+	}
+	obj, ok := f.Object().(*types.Func)
+	if !ok || isInterfaceMethod(obj) {
+		return ""
+	}
+	if n, ok := strings.CutPrefix(f.Name(), "init#"); ok && n != "1" {
+		return Object(obj) + "#" + n
+	}
+	return Object(obj)
+}
+
+// isInterfaceMethod indica se fn è un metodo astratto di un'interfaccia.
+func isInterfaceMethod(fn *types.Func) bool {
+	sig, ok := fn.Type().(*types.Signature)
+	return ok && sig.Recv() != nil && types.IsInterface(sig.Recv().Type())
+}
+
+// receiverName estrae il nome del tipo receiver e se è un pointer.
+func receiverName(t types.Type) (string, bool) {
+	ptr := false
+	if p, ok := t.(*types.Pointer); ok {
+		t, ptr = p.Elem(), true
+	}
+	switch n := t.(type) {
+	case *types.Named:
+		return n.Obj().Name(), ptr
+	case *types.Alias:
+		return n.Obj().Name(), ptr
+	}
+	// Fallback: ultimo segmento della stringa del tipo
+	s := StripTypeArgs(t.String())
+	if idx := strings.LastIndex(s, "."); idx >= 0 {
+		s = s[idx+1:]
+	}
+	return s, ptr
+}
+
+// StripTypeArgs rimuove gli argomenti di tipo (anche annidati) da un nome,
+// es. "Map[int,string]$1" → "Map$1".
+func StripTypeArgs(name string) string {
+	if !strings.Contains(name, "[") {
+		return name
+	}
+	var b strings.Builder
+	depth := 0
+	for _, r := range name {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,288 @@
+// Package initeffects elenca gli import vuoti (import _ "pkg") del progetto
+// e cosa fa l'inizializzazione dei package che portano con sé: le chiamate
+// ad altri package (sql.Register, http.HandleFunc, ...) e le variabili
+// globali assegnate dalle funzioni init, seguendo le chiamate a funzioni
+// dello stesso package, e la catena dei package inizializzati per effetto
+// dell'import.
+package initeffects
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+type builder struct {
+	fset    *token.FileSet
+	root    string
+	effects map[string]*schema.CLDKInitPackage // import path → effetti, nil se non ne ha
+	done    map[string]bool
+}
+
+// Inventory restituisce gli import vuoti dei file sotto root e gli effetti
+// dell'inizializzazione dei package che eseguono, nil se il progetto non ha
+// import vuoti. La catena di un import comprende il package importato e i
+// package fuori dalla libreria standard che importa, direttamente o
+// transitivamente, purché abbiano funzioni init, inizializzatori
+// var _ = ... o assegnamenti a globali nelle init.
+func Inventory(pkgs []*packages.Package, fset *token.FileSet, root string) *schema.CLDKInitEffects {
+	b := &builder{
+		fset:    fset,
+		root:    root,
+		effects: make(map[string]*schema.CLDKInitPackage),
+		done:    make(map[string]bool),
+	}
+	out := &schema.CLDKInitEffects{BlankImports: []schema.CLDKBlankImport{}, Packages: []schema.CLDKInitPackage{}}
+	seen := make(map[token.Position]bool) // le varianti di test ripetono i file
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil || !loader.UnderRoot(fset.Position(file.Pos()).Filename, root) {
+				continue
+			}
+			for _, spec := range file.Imports {
+				if spec.Name == nil || spec.Name.Name != "_" {
+					continue
+				}
+				pos := fset.Position(spec.Pos())
+				if seen[pos] {
+					continue
+				}
+				seen[pos] = true
+				path, err := strconv.Unquote(spec.Path.Value)
+				if err != nil {
+					continue
+				}
+				bi := schema.CLDKBlankImport{
+					Importer: strings.TrimSuffix(pkg.PkgPath, "_test"),
+					Import:   path,
+					Chain:    []string{},
+					Position: loader.Position(b.fset.Position(spec.Pos()), b.root),
+				}
+				if dep := pkg.Imports[path]; dep != nil {
+					bi.Chain = b.chain(dep)
+				}
+				out.BlankImports = append(out.BlankImports, bi)
+			}
+		}
+	}
+	if len(out.BlankImports) == 0 {
+		return nil
+	}
+	sort.SliceStable(out.BlankImports, func(i, j int) bool {
+		a, b := out.BlankImports[i].Position, out.BlankImports[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.StartLine < b.StartLine
+	})
+	for _, e := range b.effects {
+		if e != nil {
+			out.Packages = append(out.Packages, *e)
+		}
+	}
+	sort.Slice(out.Packages, func(i, j int) bool { return out.Packages[i].Package < out.Packages[j].Package })
+	return out
+}
+
+// chain restituisce i package con effetti inizializzati per l'import di
+// dep, nell'ordine di Go: prima le dipendenze, in ordine di import path.
+func (b *builder) chain(dep *packages.Package) []string {
+	chain := []string{}
+	visited := make(map[string]bool)
+	var visit func(p *packages.Package)
+	visit = func(p *packages.Package) {
+		if visited[p.PkgPath] {
+			return
+		}
+		visited[p.PkgPath] = true
+		paths := make([]string, 0, len(p.Imports))
+		for path := range p.Imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if imp := p.Imports[path]; imp != nil && !b.standard(imp) {
+				visit(imp)
+			}
+		}
+		if b.analyze(p) != nil {
+			chain = append(chain, p.PkgPath)
+		}
+	}
+	visit(dep)
+	return chain
+}
+
+// analyze calcola, una volta per package, cosa fa la sua inizializzazione:
+// le funzioni init e gli inizializzatori var _ = ... sono le radici, le
+// chiamate a funzioni dello stesso package sono seguite, quelle ad altri
+// package riportate. nil se il package non ha effetti.
+func (b *builder) analyze(p *packages.Package) *schema.CLDKInitPackage {
+	if b.done[p.PkgPath] {
+		return b.effects[p.PkgPath]
+	}
+	b.done[p.PkgPath] = true
+	if p.TypesInfo == nil {
+		return nil
+	}
+	info := p.TypesInfo
+
+	type item struct {
+		node ast.Node
+		via  []string
+	}
+	decls := make(map[*types.Func]*ast.FuncDecl)
+	var queue []item
+	e := &schema.CLDKInitPackage{Package: p.PkgPath, Standard: b.standard(p), Calls: []schema.CLDKInitCall{}, Globals: []string{}}
+	for _, file := range p.Syntax {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if obj, ok := info.Defs[d.Name].(*types.Func); ok {
+					decls[obj] = d
+				}
+				if d.Recv == nil && d.Name.Name == "init" && d.Body != nil {
+					e.Inits++
+					queue = append(queue, item{d.Body, []string{ids.Func(p.PkgPath, "init")}})
+				}
+			case *ast.GenDecl:
+				if d.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range d.Specs {
+					vs := spec.(*ast.ValueSpec)
+					if len(vs.Values) > 0 && blank(vs.Names) {
+						for _, v := range vs.Values {
+							queue = append(queue, item{v, []string{ids.Func(p.PkgPath, "_")}})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	visited := make(map[*types.Func]bool)
+	globals := make(map[string]bool)
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+		ast.Inspect(it.node, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.AssignStmt:
+				for _, lhs := range x.Lhs {
+					if v := globalVar(lhs, info); v != nil && x.Tok != token.DEFINE {
+						globals[ids.Type(v.Pkg().Path(), v.Name())] = true
+					}
+				}
+			case *ast.CallExpr:
+				fn, ok := typeutil.Callee(info, x).(*types.Func)
+				if !ok || fn.Pkg() == nil {
+					break
+				}
+				fn = fn.Origin()
+				if fn.Pkg() == p.Types {
+					if d := decls[fn]; d != nil && d.Body != nil && !visited[fn] {
+						visited[fn] = true
+						via := append(append([]string(nil), it.via...), ids.Object(fn))
+						queue = append(queue, item{d.Body, via})
+					}
+					break
+				}
+				call := schema.CLDKInitCall{Call: ids.Object(fn), Args: stringArgs(x, info), Via: it.via}
+				if loader.UnderRoot(b.fset.Position(x.Pos()).Filename, b.root) {
+					call.Position = loader.Position(b.fset.Position(x.Pos()), b.root)
+				}
+				e.Calls = append(e.Calls, call)
+			}
+			return true
+		})
+	}
+	for g := range globals {
+		e.Globals = append(e.Globals, g)
+	}
+	sort.Strings(e.Globals)
+
+	if e.Inits == 0 && len(e.Calls) == 0 && len(e.Globals) == 0 {
+		return nil
+	}
+	b.effects[p.PkgPath] = e
+	return e
+}
+
+// standard riporta se p è della libreria standard: primo elemento
+// dell'import path senza punto e file fuori dalla root.
+func (b *builder) standard(p *packages.Package) bool {
+	first, _, _ := strings.Cut(p.PkgPath, "/")
+	if strings.Contains(first, ".") {
+		return false
+	}
+	for _, f := range p.GoFiles {
+		if loader.UnderRoot(f, b.root) {
+			return false
+		}
+	}
+	return true
+}
+
+// globalVar restituisce la variabile package-level scritta da lhs: risale
+// selettori di campo, indici e parentesi; nil attraverso *p.
+func globalVar(lhs ast.Expr, info *types.Info) *types.Var {
+	for {
+		switch x := lhs.(type) {
+		case *ast.Ident:
+			v, ok := info.Uses[x].(*types.Var)
+			if !ok || v.IsField() || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+				return nil
+			}
+			return v
+		case *ast.ParenExpr:
+			lhs = x.X
+		case *ast.IndexExpr:
+			lhs = x.X
+		case *ast.SelectorExpr:
+			if id, ok := x.X.(*ast.Ident); ok {
+				if _, pkg := info.Uses[id].(*types.PkgName); pkg {
+					lhs = x.Sel
+					continue
+				}
+			}
+			lhs = x.X
+		default:
+			return nil
+		}
+	}
+}
+
+// stringArgs restituisce i valori degli argomenti stringa costanti di call.
+func stringArgs(call *ast.CallExpr, info *types.Info) []string {
+	var out []string
+	for _, arg := range call.Args {
+		if tv, ok := info.Types[arg]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			out = append(out, constant.StringVal(tv.Value))
+		}
+	}
+	return out
+}
+
+func blank(names []*ast.Ident) bool {
+	for _, n := range names {
+		if n.Name != "_" {
+			return false
+		}
+	}
+	return true
+}
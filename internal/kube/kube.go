@@ -0,0 +1,403 @@
+// Package kube inventaria i tipi delle API Kubernetes dichiarati nel
+// progetto: oggetti radice e liste (tipi che implementano runtime.Object o
+// incorporano metav1.TypeMeta), i loro Spec e Status e i tipi annotati con
+// marker di controller-gen o code-generator ("+kubebuilder:", "+k8s:",
+// "+genclient"). Group e version vengono dai marker del package
+// (+groupName, +versionName), dalle variabili schema.GroupVersion o dal
+// nome del package (es. v1alpha1). Non serve k8s.io/apimachinery tra le
+// dipendenze: runtime.Object è riconosciuta dal method set.
+package kube
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Import path di apimachinery.
+const (
+	pathMetaV1 = "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pathSchema = "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Ruoli dei tipi.
+const (
+	RoleRoot   = "root"
+	RoleList   = "list"
+	RoleSpec   = "spec"
+	RoleStatus = "status"
+	RoleType   = "type"
+)
+
+// versionName riconosce i nomi di package che sono versioni di API.
+var versionName = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+
+// marker riconosce una riga di commento "+nome[:...][=...]".
+var marker = regexp.MustCompile(`^\+[A-Za-z][\w.-]*([:=].*)?$`)
+
+// candidate è un tipo del package con i dati per classificarlo.
+type candidate struct {
+	t       schema.CLDKKubeType
+	object  bool   // runtime.Object, TypeMeta o +kubebuilder:object:root
+	items   string // kind degli elementi del campo Items
+	spec    string
+	status  string
+	tracked bool // marker di controller-gen o code-generator sul tipo o sui campi
+}
+
+// Inventory restituisce i tipi API dei package, nil se il progetto non ne
+// dichiara.
+func Inventory(pkgs []*packages.Package, fset *token.FileSet, root string) *schema.CLDKKubeAPI {
+	api := &schema.CLDKKubeAPI{GroupVersions: []schema.CLDKKubeGroupVersion{}, Types: []schema.CLDKKubeType{}}
+	seen := make(map[string]bool) // le varianti di test ripetono i package
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil || seen[pkg.PkgPath] {
+			continue
+		}
+		var files []*ast.File
+		for _, file := range pkg.Syntax {
+			if file != nil && loader.UnderRoot(fset.Position(file.Pos()).Filename, root) {
+				files = append(files, file)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		seen[pkg.PkgPath] = true
+		inventoryPackage(api, pkg, files, fset, root)
+	}
+	if len(api.Types) == 0 {
+		return nil
+	}
+	sort.Slice(api.GroupVersions, func(i, j int) bool {
+		a, b := api.GroupVersions[i], api.GroupVersions[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Package < b.Package
+	})
+	sort.Slice(api.Types, func(i, j int) bool { return api.Types[i].Type < api.Types[j].Type })
+	return api
+}
+
+func inventoryPackage(api *schema.CLDKKubeAPI, pkg *packages.Package, files []*ast.File, fset *token.FileSet, root string) {
+	gv := schema.CLDKKubeGroupVersion{Package: pkg.PkgPath, Kinds: []string{}}
+	for _, file := range files {
+		for _, g := range file.Comments {
+			if g.End() < file.Package {
+				gv.Markers = append(gv.Markers, markers(g)...)
+			}
+		}
+	}
+	for _, m := range gv.Markers {
+		if v, ok := strings.CutPrefix(m, "+groupName="); ok {
+			gv.Group = v
+		}
+		if v, ok := strings.CutPrefix(m, "+versionName="); ok {
+			gv.Version = v
+		}
+	}
+	if gv.Group == "" || gv.Version == "" {
+		group, version := groupVersion(files, pkg.TypesInfo)
+		if gv.Group == "" {
+			gv.Group = group
+		}
+		if gv.Version == "" {
+			gv.Version = version
+		}
+	}
+	if gv.Version == "" && versionName.MatchString(pkg.Name) {
+		gv.Version = pkg.Name
+	}
+
+	byID := make(map[string]*candidate)
+	var order []*candidate
+	for _, file := range files {
+		prev := file.Name.End()
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if ok && gd.Tok == token.TYPE {
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					var doc []string
+					if gd.Lparen.IsValid() {
+						doc = markers(ts.Doc)
+					} else {
+						// controller-gen legge anche i marker separati dalla
+						// documentazione da una riga vuota
+						for _, g := range file.Comments {
+							if g.Pos() >= prev && g.End() <= gd.Pos() {
+								doc = append(doc, markers(g)...)
+							}
+						}
+					}
+					if c := newCandidate(pkg, ts, doc); c != nil {
+						c.t.Position = loader.Position(fset.Position(ts.Name.Pos()), root)
+						byID[c.t.Type] = c
+						order = append(order, c)
+					}
+				}
+			}
+			prev = decl.End()
+		}
+	}
+
+	for _, c := range order {
+		if !c.object {
+			continue
+		}
+		if c.items != "" {
+			c.t.Role, c.t.Kind, c.t.ListOf = RoleList, lastName(c.t.Type), c.items
+			continue
+		}
+		c.t.Role, c.t.Kind = RoleRoot, lastName(c.t.Type)
+		c.t.Spec, c.t.Status = c.spec, c.status
+		c.t.Scope = "Namespaced"
+		resource(&c.t)
+		gv.Kinds = append(gv.Kinds, c.t.Kind)
+		if s := byID[c.spec]; s != nil && s.t.Role == "" {
+			s.t.Role = RoleSpec
+		}
+		if s := byID[c.status]; s != nil && s.t.Role == "" {
+			s.t.Role = RoleStatus
+		}
+	}
+	for _, c := range order {
+		if c.t.Role == "" {
+			if !c.tracked {
+				continue
+			}
+			c.t.Role = RoleType
+		}
+		c.t.Group, c.t.Version = gv.Group, gv.Version
+		api.Types = append(api.Types, c.t)
+	}
+	if len(gv.Kinds) > 0 {
+		sort.Strings(gv.Kinds)
+		api.GroupVersions = append(api.GroupVersions, gv)
+	}
+}
+
+// newCandidate raccoglie i dati del tipo dichiarato da ts; i campi sono
+// esaminati solo per le struct.
+func newCandidate(pkg *packages.Package, ts *ast.TypeSpec, doc []string) *candidate {
+	obj, ok := pkg.TypesInfo.Defs[ts.Name].(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil
+	}
+	c := &candidate{t: schema.CLDKKubeType{Type: ids.Type(pkg.PkgPath, obj.Name()), Markers: doc}}
+	for _, m := range doc {
+		c.tracked = c.tracked || kubeMarker(m)
+		if m == "+kubebuilder:object:root" || m == "+kubebuilder:object:root=true" {
+			c.object = true
+		}
+	}
+
+	ms := types.NewMethodSet(types.NewPointer(named))
+	c.t.RuntimeObject = ms.Lookup(nil, "DeepCopyObject") != nil && ms.Lookup(nil, "GetObjectKind") != nil
+	c.object = c.object || c.t.RuntimeObject
+
+	st, _ := named.Underlying().(*types.Struct)
+	for i := 0; st != nil && i < st.NumFields(); i++ {
+		field := st.Field(i)
+		switch {
+		case field.Embedded() && isNamed(field.Type(), pathMetaV1, "TypeMeta"):
+			c.object = true
+		case field.Name() == "Items":
+			if sl, ok := field.Type().Underlying().(*types.Slice); ok {
+				if n := namedOf(sl.Elem()); n != nil {
+					c.items = n.Obj().Name()
+				}
+			}
+		case field.Name() == "Spec":
+			c.spec = typeID(field.Type())
+		case field.Name() == "Status":
+			c.status = typeID(field.Type())
+		}
+	}
+
+	if sx, ok := ts.Type.(*ast.StructType); ok {
+		for _, f := range sx.Fields.List {
+			ms := markers(f.Doc)
+			if len(ms) == 0 {
+				continue
+			}
+			name := jsonName(f)
+			if c.t.FieldMarkers == nil {
+				c.t.FieldMarkers = make(map[string][]string)
+			}
+			c.t.FieldMarkers[name] = append(c.t.FieldMarkers[name], ms...)
+			for _, m := range ms {
+				c.tracked = c.tracked || kubeMarker(m)
+			}
+		}
+	}
+	return c
+}
+
+// resource applica al tipo radice t i marker di risorsa di controller-gen
+// e code-generator.
+func resource(t *schema.CLDKKubeType) {
+	for _, m := range t.Markers {
+		switch {
+		case strings.HasPrefix(m, "+kubebuilder:resource:"):
+			for _, arg := range strings.Split(strings.TrimPrefix(m, "+kubebuilder:resource:"), ",") {
+				key, value, _ := strings.Cut(arg, "=")
+				switch key {
+				case "path":
+					t.Plural = value
+				case "scope":
+					t.Scope = value
+				case "shortName":
+					t.ShortNames = append(t.ShortNames, strings.Split(value, ";")...)
+				case "categories":
+					t.Categories = append(t.Categories, strings.Split(value, ";")...)
+				}
+			}
+		case strings.HasPrefix(m, "+kubebuilder:subresource:"):
+			name, _, _ := strings.Cut(strings.TrimPrefix(m, "+kubebuilder:subresource:"), ":")
+			t.Subresources = append(t.Subresources, name)
+		case m == "+kubebuilder:storageversion":
+			t.StorageVersion = true
+		case m == "+genclient:nonNamespaced":
+			t.Scope = "Cluster"
+		}
+	}
+}
+
+// groupVersion cerca una variabile di tipo schema.GroupVersion inizializzata
+// con un literal e ne restituisce Group e Version se sono costanti.
+func groupVersion(files []*ast.File, info *types.Info) (group, version string) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				for _, value := range spec.(*ast.ValueSpec).Values {
+					lit, ok := value.(*ast.CompositeLit)
+					if !ok || !isNamed(info.TypeOf(lit), pathSchema, "GroupVersion") {
+						continue
+					}
+					for i, el := range lit.Elts {
+						key, v := "", el
+						if kv, ok := el.(*ast.KeyValueExpr); ok {
+							if id, ok := kv.Key.(*ast.Ident); ok {
+								key = id.Name
+							}
+							v = kv.Value
+						} else if i < 2 {
+							key = [...]string{"Group", "Version"}[i]
+						}
+						s := stringConst(info, v)
+						switch key {
+						case "Group":
+							group = s
+						case "Version":
+							version = s
+						}
+					}
+					if group != "" || version != "" {
+						return group, version
+					}
+				}
+			}
+		}
+	}
+	return "", ""
+}
+
+// markers restituisce le righe marker del gruppo di commenti g.
+func markers(g *ast.CommentGroup) []string {
+	if g == nil {
+		return nil
+	}
+	var out []string
+	for _, c := range g.List {
+		line, ok := strings.CutPrefix(c.Text, "//")
+		if !ok {
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if marker.MatchString(line) {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// kubeMarker riconosce i marker di controller-gen e code-generator: un
+// commento come "+optional" da solo non rende un tipo parte delle API.
+func kubeMarker(m string) bool {
+	for _, prefix := range []string{"+kubebuilder:", "+k8s:", "+genclient", "+operator-sdk:"} {
+		if strings.HasPrefix(m, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonName restituisce il nome json del campo f, il nome Go senza tag o il
+// tipo per gli embedded.
+func jsonName(f *ast.Field) string {
+	if f.Tag != nil {
+		tag := strings.Trim(f.Tag.Value, "`")
+		if name, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	if len(f.Names) > 0 {
+		return f.Names[0].Name
+	}
+	return types.ExprString(f.Type)
+}
+
+func stringConst(info *types.Info, e ast.Expr) string {
+	if tv, ok := info.Types[e]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value)
+	}
+	return ""
+}
+
+func namedOf(t types.Type) *types.Named {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, _ := types.Unalias(t).(*types.Named)
+	return n
+}
+
+func isNamed(t types.Type, pkgPath, name string) bool {
+	n := namedOf(t)
+	return n != nil && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == pkgPath && n.Obj().Name() == name
+}
+
+func typeID(t types.Type) string {
+	if n := namedOf(t); n != nil && n.Obj().Pkg() != nil {
+		return ids.Type(n.Obj().Pkg().Path(), n.Obj().Name())
+	}
+	return types.TypeString(t, nil)
+}
+
+func lastName(id string) string {
+	return id[strings.LastIndex(id, ".")+1:]
+}
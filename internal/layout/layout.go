@@ -0,0 +1,122 @@
+// Package layout calcola il layout in memoria delle struct (offset, allineamento,
+// dimensione, padding) usando types.Sizes del pacchetto caricato.
+package layout
+
+import (
+	"fmt"
+	"go/types"
+	"runtime"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// DefaultMinSavings è il risparmio minimo (in byte) che genera un issue.
+const DefaultMinSavings = 8
+
+// Annotate popola il layout di ogni struct dichiarata nel package e l'offset
+// di ciascun campo. Restituisce un issue info per ogni struct in cui
+// riordinare i campi farebbe risparmiare almeno minSavings byte.
+func Annotate(pkg *packages.Package, cldkPkg *schema.CLDKPackage, minSavings int) []schema.Issue {
+	if pkg == nil || pkg.Types == nil || cldkPkg == nil {
+		return nil
+	}
+	if minSavings <= 0 {
+		minSavings = DefaultMinSavings
+	}
+
+	sizes := pkg.TypesSizes
+	if sizes == nil {
+		sizes = types.SizesFor("gc", runtime.GOARCH)
+	}
+
+	var issues []schema.Issue
+	scope := pkg.Types.Scope()
+
+	for _, td := range cldkPkg.TypeDeclarations {
+		if td.Kind != "struct" || len(td.TypeParameters) > 0 {
+			continue
+		}
+		obj, ok := scope.Lookup(td.Name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+
+		l := compute(st, sizes)
+		td.Layout = l
+
+		// I CLDKField seguono lo stesso ordine dei campi della struct
+		if len(td.Fields) == st.NumFields() {
+			offsets := sizes.Offsetsof(fields(st))
+			for i := range td.Fields {
+				f := st.Field(i)
+				off := offsets[i]
+				td.Fields[i].Offset = &off
+				td.Fields[i].Size = sizes.Sizeof(f.Type())
+				td.Fields[i].Align = sizes.Alignof(f.Type())
+			}
+		}
+
+		if saved := l.Size - l.OptimalSize; saved >= int64(minSavings) {
+			issues = append(issues, schema.Issue{
+				Severity: "info",
+				Code:     "STRUCT_PADDING",
+				Message: fmt.Sprintf("%s: reordering fields would shrink the struct from %d to %d bytes (%d bytes of padding)",
+					td.QualifiedName, l.Size, l.OptimalSize, l.Padding),
+				Position: td.Position,
+			})
+		}
+	}
+
+	// TypeDeclarations è una mappa: issue in ordine di nome qualificato
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Message < issues[j].Message })
+	return issues
+}
+
+// compute calcola dimensione, allineamento, padding e dimensione ottimale.
+func compute(st *types.Struct, sizes types.Sizes) *schema.CLDKStructLayout {
+	fs := fields(st)
+	l := &schema.CLDKStructLayout{
+		Size:  sizes.Sizeof(st),
+		Align: sizes.Alignof(st),
+	}
+
+	var used int64
+	for _, f := range fs {
+		used += sizes.Sizeof(f.Type())
+	}
+	l.Padding = l.Size - used
+
+	// Layout ottimale: campi ordinati per allineamento decrescente,
+	// a parità di allineamento per dimensione decrescente
+	sorted := make([]*types.Var, len(fs))
+	copy(sorted, fs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ai, aj := sizes.Alignof(sorted[i].Type()), sizes.Alignof(sorted[j].Type())
+		if ai != aj {
+			return ai > aj
+		}
+		return sizes.Sizeof(sorted[i].Type()) > sizes.Sizeof(sorted[j].Type())
+	})
+	l.OptimalSize = sizes.Sizeof(types.NewStruct(sorted, nil))
+	if l.OptimalSize > l.Size {
+		// Un campo zero-size in coda può aggiungere padding: il layout originale resta il migliore
+		l.OptimalSize = l.Size
+	}
+
+	return l
+}
+
+func fields(st *types.Struct) []*types.Var {
+	out := make([]*types.Var, st.NumFields())
+	for i := range out {
+		out[i] = st.Field(i)
+	}
+	return out
+}
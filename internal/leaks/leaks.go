@@ -0,0 +1,445 @@
+// Package leaks rileva sull'SSA le risorse aperte e non chiuse su tutti i
+// percorsi: file di os.Open, body delle risposte HTTP e sql.Rows.
+package leaks
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// CodeNotClosed segnala una risorsa non chiusa su almeno un percorso.
+const CodeNotClosed = "RESOURCE_NOT_CLOSED"
+
+// Config configura la ricerca delle risorse non chiuse.
+type Config struct {
+	Packages *pkgfilter.Filter // filtra i package (--only-pkg, --match-pkg, --exclude-pkg)
+}
+
+// Tipi di risorsa: cosa va chiuso.
+const (
+	resFile = "file"
+	resBody = "response body"
+	resRows = "rows"
+)
+
+// openers sono le funzioni che restituiscono una risorsa da chiudere come
+// primo risultato e un error come ultimo.
+var openers = map[string]string{
+	"os.Open":                           resFile,
+	"os.OpenFile":                       resFile,
+	"os.Create":                         resFile,
+	"os.CreateTemp":                     resFile,
+	"os.OpenInRoot":                     resFile,
+	"net/http.Get":                      resBody,
+	"net/http.Head":                     resBody,
+	"net/http.Post":                     resBody,
+	"net/http.PostForm":                 resBody,
+	"net/http.(*Client).Do":             resBody,
+	"net/http.(*Client).Get":            resBody,
+	"net/http.(*Client).Head":           resBody,
+	"net/http.(*Client).Post":           resBody,
+	"net/http.(*Client).PostForm":       resBody,
+	"database/sql.(*DB).Query":          resRows,
+	"database/sql.(*DB).QueryContext":   resRows,
+	"database/sql.(*Tx).Query":          resRows,
+	"database/sql.(*Tx).QueryContext":   resRows,
+	"database/sql.(*Stmt).Query":        resRows,
+	"database/sql.(*Stmt).QueryContext": resRows,
+	"database/sql.(*Conn).QueryContext": resRows,
+}
+
+// Check analizza le funzioni dei package caricati e restituisce un warning
+// per ogni risorsa aperta che, su almeno un percorso verso un return, non è
+// chiusa (direttamente o con defer) né ceduta: restituita, memorizzata,
+// catturata da una closure, inviata su un canale o passata a una funzione
+// del progetto, a una chiamata dinamica o a una funzione il cui nome
+// contiene "close". I rami in cui l'error della stessa chiamata è non nil
+// sono esclusi, come i percorsi che terminano con panic, os.Exit,
+// log.Fatal* o t.Fatal*.
+func Check(result *loader.LoadResult, cfg Config) ([]schema.Issue, error) {
+	if result.SSAProgram == nil {
+		return nil, fmt.Errorf("SSAProgram is nil, call loader.Load with NeedSSA=true")
+	}
+
+	c := &checker{result: result, project: result.ProjectPackages(), seen: make(map[token.Position]bool)}
+	visited := make(map[*ssa.Function]bool)
+	var visit func(fn *ssa.Function)
+	visit = func(fn *ssa.Function) {
+		if fn == nil || visited[fn] || len(fn.Blocks) == 0 || fn.Synthetic != "" {
+			return
+		}
+		visited[fn] = true
+		c.function(fn)
+		for _, anon := range fn.AnonFuncs {
+			visit(anon)
+		}
+	}
+
+	for _, ssaPkg := range result.SSAPackages {
+		if ssaPkg == nil || ssaPkg.Pkg == nil || !cfg.Packages.Allows(ssaPkg.Pkg.Path()) {
+			continue
+		}
+		for _, member := range ssaPkg.Members {
+			switch m := member.(type) {
+			case *ssa.Function:
+				visit(m)
+			case *ssa.Type:
+				for _, t := range []types.Type{m.Type(), types.NewPointer(m.Type())} {
+					mset := result.SSAProgram.MethodSets.MethodSet(t)
+					for i := 0; i < mset.Len(); i++ {
+						if fn := result.SSAProgram.MethodValue(mset.At(i)); fn != nil && fn.Pkg == ssaPkg {
+							visit(fn)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	issues := c.issues
+	sort.SliceStable(issues, func(i, j int) bool {
+		pi, pj := issues[i].Position, issues[j].Position
+		if pi == nil || pj == nil {
+			return pj != nil
+		}
+		if pi.File != pj.File {
+			return pi.File < pj.File
+		}
+		if pi.StartLine != pj.StartLine {
+			return pi.StartLine < pj.StartLine
+		}
+		return pi.StartColumn < pj.StartColumn
+	})
+	return issues, nil
+}
+
+type checker struct {
+	result  *loader.LoadResult
+	project map[string]bool
+	seen    map[token.Position]bool // le varianti di test ripetono le funzioni
+	issues  []schema.Issue
+}
+
+// function controlla le risorse aperte in fn.
+func (c *checker) function(fn *ssa.Function) {
+	for _, b := range fn.Blocks {
+		for i, instr := range b.Instrs {
+			call, ok := instr.(*ssa.Call)
+			if !ok {
+				continue
+			}
+			callee := call.Call.StaticCallee()
+			if callee == nil {
+				continue
+			}
+			obj, ok := callee.Object().(*types.Func)
+			if !ok {
+				continue
+			}
+			opener := ids.Object(obj)
+			kind, ok := openers[opener]
+			if !ok {
+				continue
+			}
+			pos := c.result.Fset.Position(call.Pos())
+			if c.seen[pos] {
+				continue
+			}
+			c.seen[pos] = true
+
+			res, errVal := results(call)
+			tracked := aliases(res)
+			if kind == resBody {
+				for v := range tracked {
+					for body := range bodies(v) {
+						for a := range aliases(body) {
+							tracked[a] = true
+						}
+					}
+				}
+			}
+
+			var msg string
+			switch {
+			case !c.coveredSomewhere(fn, tracked):
+				msg = "is never closed"
+			case c.leaks(b, i+1, tracked, errVal):
+				msg = "is not closed on every path"
+			default:
+				continue
+			}
+			c.issues = append(c.issues, schema.Issue{
+				Severity: "warning",
+				Code:     CodeNotClosed,
+				Message:  fmt.Sprintf("%s: %s returned by %s %s", fn.String(), kind, opener, msg),
+				Position: c.result.Position(call.Pos()),
+			})
+		}
+	}
+}
+
+// results restituisce il primo e l'ultimo risultato della chiamata (la
+// risorsa e l'error), nil se non sono usati.
+func results(call *ssa.Call) (res, errVal ssa.Value) {
+	tuple, ok := call.Type().(*types.Tuple)
+	if !ok {
+		return call, nil
+	}
+	for _, ref := range *call.Referrers() {
+		if ex, ok := ref.(*ssa.Extract); ok {
+			switch ex.Index {
+			case 0:
+				res = ex
+			case tuple.Len() - 1:
+				errVal = ex
+			}
+		}
+	}
+	return res, errVal
+}
+
+// aliases restituisce v e i valori che lo rappresentano attraverso phi,
+// conversioni e interfacce.
+func aliases(v ssa.Value) map[ssa.Value]bool {
+	out := make(map[ssa.Value]bool)
+	if v == nil {
+		return out
+	}
+	queue := []ssa.Value{v}
+	out[v] = true
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		refs := cur.Referrers()
+		if refs == nil {
+			continue
+		}
+		for _, ref := range *refs {
+			var next ssa.Value
+			switch x := ref.(type) {
+			case *ssa.Phi:
+				next = x
+			case *ssa.ChangeType:
+				next = x
+			case *ssa.MakeInterface:
+				next = x
+			case *ssa.ChangeInterface:
+				next = x
+			case *ssa.TypeAssert:
+				if !x.CommaOk {
+					next = x
+				}
+			}
+			if next != nil && !out[next] {
+				out[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return out
+}
+
+// bodies restituisce le letture del campo Body di una *http.Response.
+func bodies(resp ssa.Value) map[ssa.Value]bool {
+	out := make(map[ssa.Value]bool)
+	refs := resp.Referrers()
+	if refs == nil {
+		return out
+	}
+	for _, ref := range *refs {
+		fa, ok := ref.(*ssa.FieldAddr)
+		if !ok || fieldName(fa.X.Type(), fa.Field) != "Body" {
+			continue
+		}
+		for _, r := range *fa.Referrers() {
+			if load, ok := r.(*ssa.UnOp); ok && load.Op == token.MUL {
+				out[load] = true
+			}
+		}
+	}
+	return out
+}
+
+func fieldName(t types.Type, i int) string {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || i >= st.NumFields() {
+		return ""
+	}
+	return st.Field(i).Name()
+}
+
+// coveredSomewhere riporta se un'istruzione di fn chiude o cede la risorsa.
+func (c *checker) coveredSomewhere(fn *ssa.Function, tracked map[ssa.Value]bool) bool {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if c.covers(instr, tracked) && !ends(instr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// leaks riporta se da b.Instrs[from] si raggiunge un return senza passare
+// da un'istruzione che chiude o cede la risorsa. Nei blocchi che terminano
+// con "if err != nil" (o "== nil") sull'error della chiamata si segue solo
+// il ramo senza errore.
+func (c *checker) leaks(b *ssa.BasicBlock, from int, tracked map[ssa.Value]bool, errVal ssa.Value) bool {
+	visited := make(map[*ssa.BasicBlock]bool)
+	var walk func(b *ssa.BasicBlock, from int) bool
+	walk = func(b *ssa.BasicBlock, from int) bool {
+		for _, instr := range b.Instrs[from:] {
+			if c.covers(instr, tracked) {
+				return false
+			}
+		}
+		succs := b.Succs
+		switch term := b.Instrs[len(b.Instrs)-1].(type) {
+		case *ssa.Return:
+			return true
+		case *ssa.Panic:
+			return false
+		case *ssa.If:
+			if i := errBranch(term, errVal); i >= 0 {
+				succs = []*ssa.BasicBlock{b.Succs[1-i]}
+			}
+		}
+		for _, s := range succs {
+			if visited[s] {
+				continue
+			}
+			visited[s] = true
+			if walk(s, 0) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(b, from)
+}
+
+// errBranch restituisce l'indice del successore di "if err != nil" (0) o
+// "if err == nil" (1) in cui err non è nil, -1 se la condizione non
+// confronta errVal con nil.
+func errBranch(ifs *ssa.If, errVal ssa.Value) int {
+	cond, ok := ifs.Cond.(*ssa.BinOp)
+	if !ok || errVal == nil {
+		return -1
+	}
+	isNil := func(v ssa.Value) bool {
+		k, ok := v.(*ssa.Const)
+		return ok && k.IsNil()
+	}
+	if !(cond.X == errVal && isNil(cond.Y)) && !(cond.Y == errVal && isNil(cond.X)) {
+		return -1
+	}
+	switch cond.Op {
+	case token.NEQ:
+		return 0
+	case token.EQL:
+		return 1
+	}
+	return -1
+}
+
+// covers riporta se instr chiude la risorsa, la cede o termina il percorso
+// senza ritorno.
+func (c *checker) covers(instr ssa.Instruction, tracked map[ssa.Value]bool) bool {
+	switch x := instr.(type) {
+	case ssa.CallInstruction:
+		common := x.Common()
+		if ends(instr) {
+			return true
+		}
+		if common.IsInvoke() {
+			if common.Method.Name() == "Close" && tracked[common.Value] {
+				return true
+			}
+		} else if fn := common.StaticCallee(); fn != nil && fn.Name() == "Close" && fn.Signature.Recv() != nil &&
+			len(common.Args) > 0 && tracked[common.Args[0]] {
+			return true
+		}
+		for i, arg := range common.Args {
+			if tracked[arg] && !(i == 0 && isMethodOf(common, arg)) && c.takesOwnership(common) {
+				return true
+			}
+		}
+	case *ssa.Return:
+		for _, r := range x.Results {
+			if tracked[r] {
+				return true
+			}
+		}
+	case *ssa.Store:
+		return tracked[x.Val]
+	case *ssa.MakeClosure:
+		for _, v := range x.Bindings {
+			if tracked[v] {
+				return true
+			}
+		}
+	case *ssa.Send:
+		return tracked[x.X]
+	case *ssa.MapUpdate:
+		return tracked[x.Value]
+	}
+	return false
+}
+
+// isMethodOf riporta se arg è il receiver di una chiamata statica a un
+// metodo (f.Stat(), rows.Next()).
+func isMethodOf(common *ssa.CallCommon, arg ssa.Value) bool {
+	fn := common.StaticCallee()
+	return fn != nil && fn.Signature.Recv() != nil && len(common.Args) > 0 && common.Args[0] == arg
+}
+
+// takesOwnership riporta se la funzione chiamata può chiudere la risorsa
+// ricevuta: chiamate dinamiche e closure, funzioni del progetto, funzioni
+// il cui nome contiene "close". Le altre dipendenze (io.Copy,
+// bufio.NewScanner, json.NewDecoder) la usano soltanto.
+func (c *checker) takesOwnership(common *ssa.CallCommon) bool {
+	fn := common.StaticCallee()
+	if fn == nil || fn.Parent() != nil {
+		return true
+	}
+	if strings.Contains(strings.ToLower(fn.Name()), "close") {
+		return true
+	}
+	return fn.Pkg != nil && c.project[fn.Pkg.Pkg.Path()]
+}
+
+// ends riconosce le chiamate che non ritornano: os.Exit, log.Fatal*,
+// log.Panic* (anche di un *log.Logger) e Fatal*/FailNow/Skip* di testing.
+func ends(instr ssa.Instruction) bool {
+	call, ok := instr.(*ssa.Call)
+	if !ok {
+		return false
+	}
+	fn := call.Call.StaticCallee()
+	if fn == nil || fn.Pkg == nil {
+		return false
+	}
+	name := fn.Name()
+	switch fn.Pkg.Pkg.Path() {
+	case "os":
+		return name == "Exit"
+	case "log":
+		return strings.HasPrefix(name, "Fatal") || strings.HasPrefix(name, "Panic")
+	case "testing":
+		return strings.HasPrefix(name, "Fatal") || strings.HasPrefix(name, "Skip") || name == "FailNow"
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// CodeDeadSymbol identifica una variabile, costante o tipo package-level mai
+// referenziato nel modulo.
+const CodeDeadSymbol = "GO-DEAD-SYMBOL"
+
+// span è un intervallo di byte in un file; i riferimenti che vi cadono sono
+// del simbolo stesso (la sua dichiarazione, i metodi del tipo).
+type span struct {
+	file       string
+	start, end int
+}
+
+func (s span) contains(p token.Position) bool {
+	return p.Filename == s.file && p.Offset >= s.start && p.Offset < s.end
+}
+
+// deadCandidate è un simbolo package-level che potrebbe essere inutilizzato.
+type deadCandidate struct {
+	kind string // "variable", "constant" o "type"
+	id   string
+	pos  token.Pos
+}
+
+// DeadSymbols restituisce un warning per ogni variabile, costante o tipo
+// package-level dei package del progetto (ristretti a --files se indicato)
+// che nessun file del modulo referenzia, test inclusi se caricati. I
+// riferimenti dalla dichiarazione stessa e, per i tipi, dai loro metodi non
+// contano. I simboli esportati sono considerati solo nei package main e
+// sotto internal/, dove nessun modulo esterno può usarli; quelli con
+// //go:linkname sono esclusi.
+func DeadSymbols(result *loader.LoadResult) []schema.Issue {
+	fset := result.Fset
+	key := func(obj types.Object) string { return obj.Pkg().Path() + "." + obj.Name() }
+
+	// Candidati e intervalli di auto-riferimento, per chiave pkg.Nome: le
+	// varianti di test dichiarano gli stessi simboli con oggetti diversi.
+	cands := make(map[string]deadCandidate)
+	self := make(map[string][]span)
+	for _, pkg := range result.Packages {
+		if pkg == nil || pkg.Types == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil || !loader.UnderRoot(fset.Position(file.Pos()).Filename, result.Root) {
+				continue // es. il main generato dei package ".test"
+			}
+			linked := linknames(file)
+			for _, decl := range file.Decls {
+				switch d := decl.(type) {
+				case *ast.FuncDecl:
+					if d.Recv == nil || len(d.Recv.List) == 0 {
+						continue
+					}
+					if name := recvBase(d.Recv.List[0].Type); name != "" {
+						k := pkg.PkgPath + "." + name
+						self[k] = append(self[k], spanOf(fset, d))
+					}
+				case *ast.GenDecl:
+					for _, spec := range d.Specs {
+						for _, name := range specNames(spec) {
+							obj := pkg.TypesInfo.Defs[name]
+							if obj == nil || obj.Name() == "_" || obj.Parent() != pkg.Types.Scope() {
+								continue
+							}
+							k := key(obj)
+							self[k] = append(self[k], spanOf(fset, spec))
+							if linked[obj.Name()] || !deadCandidateKind(pkg, obj) {
+								continue
+							}
+							if _, ok := cands[k]; !ok {
+								cands[k] = deadCandidate{kind: kindOf(obj), id: ids.Type(pkg.PkgPath, obj.Name()), pos: name.Pos()}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, pkg := range result.Packages {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for id, obj := range pkg.TypesInfo.Uses {
+			if obj.Pkg() == nil || obj.Parent() != obj.Pkg().Scope() {
+				continue
+			}
+			k := key(obj)
+			if _, ok := cands[k]; !ok || used[k] {
+				continue
+			}
+			pos := fset.Position(id.Pos())
+			inside := false
+			for _, s := range self[k] {
+				if s.contains(pos) {
+					inside = true
+					break
+				}
+			}
+			used[k] = !inside
+		}
+	}
+
+	scoped := make(map[string]bool)
+	for _, pkg := range result.ScopedPackages() {
+		if pkg == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file != nil {
+				scoped[fset.Position(file.Pos()).Filename] = true
+			}
+		}
+	}
+
+	var issues []schema.Issue
+	c := &checker{result: result}
+	for k, cand := range cands {
+		if used[k] || !scoped[fset.Position(cand.pos).Filename] {
+			continue
+		}
+		issues = append(issues, schema.Issue{
+			Severity: "warning",
+			Code:     CodeDeadSymbol,
+			Message:  fmt.Sprintf("%s %s is never referenced in the module", cand.kind, cand.id),
+			Position: c.result.Position(cand.pos),
+		})
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		pi, pj := issues[i].Position, issues[j].Position
+		if pi.File != pj.File {
+			return pi.File < pj.File
+		}
+		if pi.StartLine != pj.StartLine {
+			return pi.StartLine < pj.StartLine
+		}
+		return pi.StartColumn < pj.StartColumn
+	})
+	return issues
+}
+
+// deadCandidateKind riporta se obj è una variabile, costante o tipo che
+// solo il modulo può usare.
+func deadCandidateKind(pkg *packages.Package, obj types.Object) bool {
+	switch obj.(type) {
+	case *types.Var, *types.Const, *types.TypeName:
+	default:
+		return false
+	}
+	if !obj.Exported() {
+		return true
+	}
+	path := pkg.PkgPath
+	return pkg.Name == "main" || strings.HasPrefix(path, "internal/") ||
+		strings.Contains(path, "/internal/") || strings.HasSuffix(path, "/internal")
+}
+
+func kindOf(obj types.Object) string {
+	switch obj.(type) {
+	case *types.Var:
+		return "variable"
+	case *types.Const:
+		return "constant"
+	}
+	return "type"
+}
+
+func specNames(spec ast.Spec) []*ast.Ident {
+	switch s := spec.(type) {
+	case *ast.ValueSpec:
+		return s.Names
+	case *ast.TypeSpec:
+		return []*ast.Ident{s.Name}
+	}
+	return nil
+}
+
+// recvBase restituisce il nome del tipo base di un receiver (T, *T, T[P]).
+func recvBase(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func spanOf(fset *token.FileSet, n ast.Node) span {
+	start, end := fset.Position(n.Pos()), fset.Position(n.End())
+	return span{file: start.Filename, start: start.Offset, end: end.Offset}
+}
+
+// linknames restituisce i nomi locali delle direttive //go:linkname del file.
+func linknames(file *ast.File) map[string]bool {
+	out := make(map[string]bool)
+	for _, cg := range file.Comments {
+		for _, c := range cg.List {
+			if f := strings.Fields(c.Text); len(f) >= 2 && f[0] == "//go:linkname" {
+				out[f[1]] = true
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,344 @@
+// Package lint contiene un piccolo insieme di controlli sull'AST tipato,
+// scelti tra quelli che contano per il refactoring assistito: err oscurato
+// in scope annidati, errori ignorati, funzioni confrontate con nil e codice
+// irraggiungibile dopo return/panic. Non è un linter completo.
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Codici degli issue emessi.
+const (
+	CodeShadowedErr    = "SHADOWED_ERR"
+	CodeIgnoredError   = "IGNORED_ERROR"
+	CodeFuncNilCompare = "FUNC_NIL_COMPARE"
+	CodeUnreachable    = "UNREACHABLE_CODE"
+)
+
+// ignoredErrorAllowed sono le funzioni il cui errore è ignorato per
+// convenzione (stampa diagnostica, scritture su buffer in memoria).
+var ignoredErrorAllowed = map[string]bool{
+	"fmt.Print":                        true,
+	"fmt.Printf":                       true,
+	"fmt.Println":                      true,
+	"fmt.Fprint":                       true,
+	"fmt.Fprintf":                      true,
+	"fmt.Fprintln":                     true,
+	"(*bytes.Buffer).Write":            true,
+	"(*bytes.Buffer).WriteByte":        true,
+	"(*bytes.Buffer).WriteRune":        true,
+	"(*bytes.Buffer).WriteString":      true,
+	"(*strings.Builder).Write":         true,
+	"(*strings.Builder).WriteByte":     true,
+	"(*strings.Builder).WriteRune":     true,
+	"(*strings.Builder).WriteString":   true,
+	"(*hash/maphash.Hash).Write":       true,
+	"(*hash/maphash.Hash).WriteString": true,
+}
+
+// Check esegue i controlli sui file dei package del progetto (ristretti a
+// --files se indicato) e restituisce un issue di livello warning per ogni
+// problema, ordinati per posizione.
+func Check(result *loader.LoadResult) []schema.Issue {
+	var issues []schema.Issue
+	for _, pkg := range result.ScopedPackages() {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		c := &checker{pkg: pkg, result: result, errUses: errUses(pkg.TypesInfo)}
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+					c.funcDecl(fn)
+				}
+			}
+		}
+		issues = append(issues, c.issues...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		pi, pj := issues[i].Position, issues[j].Position
+		if pi == nil || pj == nil {
+			return pj != nil
+		}
+		if pi.File != pj.File {
+			return pi.File < pj.File
+		}
+		if pi.StartLine != pj.StartLine {
+			return pi.StartLine < pj.StartLine
+		}
+		return pi.StartColumn < pj.StartColumn
+	})
+	return issues
+}
+
+type checker struct {
+	pkg    *packages.Package
+	result *loader.LoadResult
+	issues []schema.Issue
+	fnName string // ID della funzione corrente, prefisso dei messaggi
+
+	errUses  map[types.Object][]token.Pos // usi delle variabili "err", in ordine
+	initDefs map[*ast.Ident]bool          // identificatori dichiarati nell'init di if/switch
+	litScope map[*types.Scope]bool        // scope di funzione delle closure
+}
+
+// errUses raccoglie gli usi delle variabili di nome err, ordinati per
+// posizione.
+func errUses(info *types.Info) map[types.Object][]token.Pos {
+	uses := make(map[types.Object][]token.Pos)
+	for id, obj := range info.Uses {
+		if v, ok := obj.(*types.Var); ok && v.Name() == "err" {
+			uses[obj] = append(uses[obj], id.Pos())
+		}
+	}
+	for _, ps := range uses {
+		sort.Slice(ps, func(i, j int) bool { return ps[i] < ps[j] })
+	}
+	return uses
+}
+
+func (c *checker) report(code string, pos token.Pos, format string, args ...interface{}) {
+	c.issues = append(c.issues, schema.Issue{
+		Severity: "warning",
+		Code:     code,
+		Message:  c.fnName + ": " + fmt.Sprintf(format, args...),
+		Position: c.result.Position(pos),
+	})
+}
+
+func (c *checker) funcDecl(fn *ast.FuncDecl) {
+	c.fnName = fn.Name.Name
+	if obj, ok := c.pkg.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+		c.fnName = ids.Object(obj)
+	}
+
+	c.initDefs = make(map[*ast.Ident]bool)
+	c.litScope = make(map[*types.Scope]bool)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.FuncLit:
+			c.litScope[c.pkg.TypesInfo.Scopes[x.Type]] = true
+		case *ast.IfStmt:
+			c.markInit(x.Init)
+		case *ast.SwitchStmt:
+			c.markInit(x.Init)
+		case *ast.TypeSwitchStmt:
+			c.markInit(x.Init)
+		case *ast.Ident:
+			c.shadowedErr(x, fn)
+		case *ast.ExprStmt:
+			c.ignoredError(x)
+		case *ast.BinaryExpr:
+			c.funcNilCompare(x)
+		case *ast.BlockStmt:
+			c.unreachable(x.List)
+		case *ast.CaseClause:
+			c.unreachable(x.Body)
+		case *ast.CommClause:
+			c.unreachable(x.Body)
+		}
+		return true
+	})
+}
+
+// markInit registra le variabili dichiarate nell'init di un if o di uno
+// switch: "if err := f(); err != nil" è idiomatico e non perde assegnazioni.
+func (c *checker) markInit(init ast.Stmt) {
+	if as, ok := init.(*ast.AssignStmt); ok && as.Tok == token.DEFINE {
+		for _, l := range as.Lhs {
+			if id, ok := l.(*ast.Ident); ok {
+				c.initDefs[id] = true
+			}
+		}
+	}
+}
+
+// shadowedErr segnala una dichiarazione di err che oscura un err della
+// stessa funzione quando quest'ultimo è usato dopo lo scope interno: le
+// assegnazioni fatte nello scope interno non raggiungono quell'uso. L'err
+// dichiarato al primo livello di una closure è indipendente e non conta.
+func (c *checker) shadowedErr(id *ast.Ident, fn *ast.FuncDecl) {
+	if id.Name != "err" || c.initDefs[id] {
+		return
+	}
+	inner, ok := c.pkg.TypesInfo.Defs[id].(*types.Var)
+	if !ok || inner.Parent() == nil || inner.Parent().Parent() == nil || c.litScope[inner.Parent()] {
+		return
+	}
+	_, obj := inner.Parent().Parent().LookupParent("err", id.Pos())
+	outer, ok := obj.(*types.Var)
+	// Solo err della stessa funzione (parametri e risultati compresi)
+	if !ok || outer.Pos() < fn.Pos() || outer.Pos() >= fn.End() {
+		return
+	}
+	end := inner.Parent().End()
+	for _, use := range c.errUses[outer] {
+		if use > end {
+			c.report(CodeShadowedErr, id.Pos(), "err declared here shadows the err declared at line %d, which is used after this scope ends (line %d)",
+				c.line(outer.Pos()), c.line(use))
+			return
+		}
+	}
+}
+
+// ignoredError segnala una chiamata usata come statement il cui ultimo
+// risultato è un error.
+func (c *checker) ignoredError(stmt *ast.ExprStmt) {
+	call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	info := c.pkg.TypesInfo
+	tv, ok := info.Types[call]
+	if !ok || !tv.IsValue() {
+		return
+	}
+	var last types.Type
+	switch t := tv.Type.(type) {
+	case *types.Tuple:
+		if t.Len() == 0 {
+			return
+		}
+		last = t.At(t.Len() - 1).Type()
+	default:
+		last = t
+	}
+	if !isError(last) {
+		return
+	}
+	name := calleeName(call, info)
+	if ignoredErrorAllowed[name] {
+		return
+	}
+	if name == "" {
+		name = "call"
+	}
+	c.report(CodeIgnoredError, call.Pos(), "error returned by %s is ignored", name)
+}
+
+// funcNilCompare segnala il confronto con nil di una funzione dichiarata
+// (non di una variabile di tipo funzione), sempre falso o sempre vero.
+func (c *checker) funcNilCompare(x *ast.BinaryExpr) {
+	if x.Op != token.EQL && x.Op != token.NEQ {
+		return
+	}
+	info := c.pkg.TypesInfo
+	var other ast.Expr
+	switch {
+	case isNil(x.X, info):
+		other = x.Y
+	case isNil(x.Y, info):
+		other = x.X
+	default:
+		return
+	}
+	var id *ast.Ident
+	switch e := ast.Unparen(other).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	}
+	if id == nil {
+		return
+	}
+	if fn, ok := info.Uses[id].(*types.Func); ok {
+		result := "false"
+		if x.Op == token.NEQ {
+			result = "true"
+		}
+		c.report(CodeFuncNilCompare, x.Pos(), "comparison of function %s with nil is always %s", fn.Name(), result)
+	}
+}
+
+// unreachable segnala il primo statement che segue un return o un panic
+// nella stessa lista, a meno che non sia un'etichetta (raggiungibile con
+// goto).
+func (c *checker) unreachable(list []ast.Stmt) {
+	for i, stmt := range list {
+		if !c.terminates(stmt) || i+1 >= len(list) {
+			continue
+		}
+		next := list[i+1]
+		if _, ok := next.(*ast.LabeledStmt); ok {
+			continue
+		}
+		if _, ok := next.(*ast.EmptyStmt); ok {
+			continue
+		}
+		c.report(CodeUnreachable, next.Pos(), "unreachable code after %s", terminator(stmt))
+		return
+	}
+}
+
+func (c *checker) terminates(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := ast.Unparen(s.X).(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+		if !ok || id.Name != "panic" {
+			return false
+		}
+		_, builtin := c.pkg.TypesInfo.Uses[id].(*types.Builtin)
+		return builtin
+	}
+	return false
+}
+
+func terminator(stmt ast.Stmt) string {
+	if _, ok := stmt.(*ast.ReturnStmt); ok {
+		return "return"
+	}
+	return "panic"
+}
+
+// calleeName restituisce il nome completo della funzione chiamata (es.
+// "os.Remove", "(*os.File).Close"), "" se non è una funzione nota.
+func calleeName(call *ast.CallExpr, info *types.Info) string {
+	var id *ast.Ident
+	switch f := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	}
+	if id == nil {
+		return ""
+	}
+	if fn, ok := info.Uses[id].(*types.Func); ok {
+		return fn.FullName()
+	}
+	return id.Name
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+func isError(t types.Type) bool {
+	return types.Identical(t, errorType)
+}
+
+func isNil(e ast.Expr, info *types.Info) bool {
+	tv, ok := info.Types[e]
+	return ok && tv.IsNil()
+}
+
+func (c *checker) line(p token.Pos) int {
+	return c.result.Fset.Position(p).Line
+}
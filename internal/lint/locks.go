@@ -0,0 +1,484 @@
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Codici degli issue sui lock.
+const (
+	CodeLockCopy        = "LOCK_COPY"         // un valore che contiene un mutex è copiato
+	CodeLockNotReleased = "LOCK_NOT_RELEASED" // un lock rilasciato solo su alcuni percorsi
+	CodeLockOrder       = "LOCK_ORDER"        // due mutex acquisiti in ordini opposti
+)
+
+// lockCall è una chiamata Lock/Unlock/RLock/RUnlock su un sync.Mutex o
+// sync.RWMutex.
+type lockCall struct {
+	call   *ast.CallExpr
+	method string
+	expr   string // espressione del mutex nel sorgente, per l'abbinamento nella funzione
+	id     string // identità del mutex tra funzioni (campo o variabile globale), "" per i locali
+}
+
+// lockPair è l'acquisizione di second mentre first è tenuto.
+type lockPair struct {
+	first, second string
+	fn            string
+	pos           token.Pos
+}
+
+// Locks esegue i controlli sui mutex dei package del progetto: valori che
+// contengono un sync.Mutex o sync.RWMutex copiati (receiver, parametri e
+// risultati per valore, assegnamenti, range), lock rilasciati su alcuni
+// percorsi ma non su tutti, e coppie di mutex acquisite in ordini opposti in
+// funzioni diverse. I mutex sono identificati per campo (T.mu) o variabile
+// globale: l'ordine tra istanze diverse dello stesso campo non è verificato.
+func Locks(result *loader.LoadResult) []schema.Issue {
+	var issues []schema.Issue
+	var pairs []lockPair
+	seen := make(map[token.Position]bool)
+	for _, pkg := range result.ScopedPackages() {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		c := &checker{pkg: pkg, result: result}
+		for _, file := range pkg.Syntax {
+			if file == nil || seen[result.Fset.Position(file.Package)] {
+				continue
+			}
+			seen[result.Fset.Position(file.Package)] = true
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok {
+					continue
+				}
+				c.fnName = fn.Name.Name
+				if obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+					c.fnName = ids.Object(obj)
+				}
+				c.lockCopies(fn)
+				if fn.Body == nil {
+					continue
+				}
+				c.unreleased(fn.Body)
+				pairs = append(pairs, c.lockOrder(fn.Body)...)
+				ast.Inspect(fn.Body, func(n ast.Node) bool {
+					if lit, ok := n.(*ast.FuncLit); ok {
+						c.unreleased(lit.Body)
+					}
+					return true
+				})
+			}
+		}
+		issues = append(issues, c.issues...)
+	}
+	issues = append(issues, orderIssues(result, pairs)...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		pi, pj := issues[i].Position, issues[j].Position
+		if pi == nil || pj == nil {
+			return pj != nil
+		}
+		if pi.File != pj.File {
+			return pi.File < pj.File
+		}
+		if pi.StartLine != pj.StartLine {
+			return pi.StartLine < pj.StartLine
+		}
+		return pi.StartColumn < pj.StartColumn
+	})
+	return issues
+}
+
+// ── Copie ─────────────────────────────────────────────────────────────────
+
+// lockCopies segnala receiver, parametri e risultati per valore che
+// contengono un mutex, e le copie nel corpo.
+func (c *checker) lockCopies(fn *ast.FuncDecl) {
+	info := c.pkg.TypesInfo
+	fields := func(fl *ast.FieldList, what string) {
+		if fl == nil {
+			return
+		}
+		for _, f := range fl.List {
+			if path := lockPath(info.TypeOf(f.Type)); path != "" {
+				c.report(CodeLockCopy, f.Pos(), "%s passes a lock by value: %s", what, path)
+			}
+		}
+	}
+	fields(fn.Recv, "receiver")
+	fields(fn.Type.Params, "parameter")
+	fields(fn.Type.Results, "result")
+	if fn.Body == nil {
+		return
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.FuncLit:
+			fields(x.Type.Params, "closure parameter")
+		case *ast.AssignStmt:
+			for i, rhs := range x.Rhs {
+				if len(x.Lhs) == len(x.Rhs) {
+					if id, ok := x.Lhs[i].(*ast.Ident); ok && id.Name == "_" {
+						continue // _ = x non conserva la copia
+					}
+				}
+				c.copyExpr(rhs, "assignment")
+			}
+		case *ast.ValueSpec:
+			for _, v := range x.Values {
+				c.copyExpr(v, "variable declaration")
+			}
+		case *ast.RangeStmt:
+			if x.Value != nil {
+				if path := lockPath(info.TypeOf(x.Value)); path != "" {
+					c.report(CodeLockCopy, x.Value.Pos(), "range value copies a lock: %s", path)
+				}
+			}
+		case *ast.CallExpr:
+			if tv, ok := info.Types[x.Fun]; ok && tv.IsType() {
+				return true // conversione
+			}
+			for _, arg := range x.Args {
+				c.copyExpr(arg, "call argument")
+			}
+		}
+		return true
+	})
+}
+
+// copyExpr segnala e se legge un valore esistente (variabile, campo,
+// dereferenziazione, elemento) che contiene un mutex: literal e risultati
+// di chiamata sono valori nuovi.
+func (c *checker) copyExpr(e ast.Expr, what string) {
+	switch ast.Unparen(e).(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.StarExpr, *ast.IndexExpr:
+	default:
+		return
+	}
+	tv, ok := c.pkg.TypesInfo.Types[e]
+	if !ok || !tv.IsValue() {
+		return
+	}
+	if path := lockPath(tv.Type); path != "" {
+		c.report(CodeLockCopy, e.Pos(), "%s copies a lock: %s", what, path)
+	}
+}
+
+// lockPath restituisce il percorso del mutex contenuto per valore in t
+// (es. "Cache.mu (sync.Mutex)"), "" se t non ne contiene.
+func lockPath(t types.Type) string {
+	fields, lock := lockFields(t, make(map[types.Type]bool))
+	if lock == nil {
+		return ""
+	}
+	name := types.TypeString(lock, shortQualifier)
+	if len(fields) == 0 {
+		return name
+	}
+	return types.TypeString(t, shortQualifier) + "." + strings.Join(fields, ".") + " (" + name + ")"
+}
+
+// lockFields cerca un sync.Mutex o sync.RWMutex in t attraverso campi
+// struct e array, restituendo i nomi dei campi attraversati.
+func lockFields(t types.Type, seen map[types.Type]bool) ([]string, types.Type) {
+	if t == nil || seen[t] {
+		return nil, nil
+	}
+	seen[t] = true
+	if isLockType(t) {
+		return nil, t
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			f := u.Field(i)
+			if fields, lock := lockFields(f.Type(), seen); lock != nil {
+				return append([]string{f.Name()}, fields...), lock
+			}
+		}
+	case *types.Array:
+		return lockFields(u.Elem(), seen)
+	}
+	return nil, nil
+}
+
+func shortQualifier(p *types.Package) string { return p.Name() }
+
+func isLockType(t types.Type) bool {
+	n, ok := types.Unalias(t).(*types.Named)
+	if !ok || n.Obj().Pkg() == nil || n.Obj().Pkg().Path() != "sync" {
+		return false
+	}
+	return n.Obj().Name() == "Mutex" || n.Obj().Name() == "RWMutex"
+}
+
+// ── Lock non rilasciati ────────────────────────────────────────────────────
+
+// unreleased segnala, per ogni Lock/RLock senza defer dell'unlock
+// corrispondente, il caso in cui un percorso verso l'uscita della funzione
+// non lo rilascia. I lock mai rilasciati nella funzione (helper che
+// restituiscono con il lock tenuto) non sono segnalati.
+func (c *checker) unreleased(body *ast.BlockStmt) {
+	info := c.pkg.TypesInfo
+	deferred := make(map[string]bool)
+	released := make(map[string]bool)
+	inspectShallow(body, func(n ast.Node) {
+		switch x := n.(type) {
+		case *ast.DeferStmt:
+			if lc, ok := c.lockCall(x.Call); ok {
+				deferred[lc.method+" "+lc.expr] = true
+			}
+		case *ast.CallExpr:
+			if lc, ok := c.lockCall(x); ok && (lc.method == "Unlock" || lc.method == "RUnlock") {
+				released[lc.method+" "+lc.expr] = true
+			}
+		}
+	})
+	if len(released) == 0 {
+		return
+	}
+
+	g := cfg.New(body, func(call *ast.CallExpr) bool { return !noReturn(call, info) })
+	for _, b := range g.Blocks {
+		if !b.Live {
+			continue
+		}
+		for i, node := range b.Nodes {
+			lc, ok := c.stmtLockCall(node)
+			if !ok || (lc.method != "Lock" && lc.method != "RLock") {
+				continue
+			}
+			unlock := "Unlock " + lc.expr
+			if lc.method == "RLock" {
+				unlock = "RUnlock " + lc.expr
+			}
+			if deferred[unlock] || !released[unlock] {
+				continue
+			}
+			if c.leaks(b, i+1, unlock, make(map[*cfg.Block]bool)) {
+				c.report(CodeLockNotReleased, lc.call.Pos(), "%s.%s() is not released on every path", lc.expr, lc.method)
+			}
+		}
+	}
+}
+
+// leaks riporta se da b.Nodes[from] si raggiunge l'uscita senza passare da
+// unlock.
+func (c *checker) leaks(b *cfg.Block, from int, unlock string, visited map[*cfg.Block]bool) bool {
+	for _, node := range b.Nodes[from:] {
+		if lc, ok := c.stmtLockCall(node); ok && lc.method+" "+lc.expr == unlock {
+			return false
+		}
+	}
+	if len(b.Succs) == 0 {
+		// uscita, salvo i blocchi che terminano con panic/os.Exit
+		if n := len(b.Nodes); n > 0 {
+			if es, ok := b.Nodes[n-1].(*ast.ExprStmt); ok {
+				if call, ok := ast.Unparen(es.X).(*ast.CallExpr); ok && noReturn(call, c.pkg.TypesInfo) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for _, s := range b.Succs {
+		if visited[s] {
+			continue
+		}
+		visited[s] = true
+		if c.leaks(s, 0, unlock, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// stmtLockCall riconosce uno statement "x.Lock()" (o Unlock, RLock, RUnlock).
+func (c *checker) stmtLockCall(n ast.Node) (lockCall, bool) {
+	es, ok := n.(*ast.ExprStmt)
+	if !ok {
+		return lockCall{}, false
+	}
+	call, ok := ast.Unparen(es.X).(*ast.CallExpr)
+	if !ok {
+		return lockCall{}, false
+	}
+	return c.lockCall(call)
+}
+
+// lockCall riconosce una chiamata a un metodo di lock di sync.Mutex o
+// sync.RWMutex, anche promosso da un campo embedded.
+func (c *checker) lockCall(call *ast.CallExpr) (lockCall, bool) {
+	info := c.pkg.TypesInfo
+	fun, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return lockCall{}, false
+	}
+	sel := info.Selections[fun]
+	if sel == nil || sel.Kind() != types.MethodVal {
+		return lockCall{}, false
+	}
+	fn, ok := sel.Obj().(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "sync" {
+		return lockCall{}, false
+	}
+	switch fn.Name() {
+	case "Lock", "Unlock", "RLock", "RUnlock":
+	default:
+		return lockCall{}, false
+	}
+	recv := fn.Type().(*types.Signature).Recv().Type()
+	if p, ok := recv.(*types.Pointer); ok {
+		recv = p.Elem()
+	}
+	if !isLockType(recv) {
+		return lockCall{}, false
+	}
+	return lockCall{call: call, method: fn.Name(), expr: types.ExprString(fun.X), id: lockID(fun.X, sel, info)}, true
+}
+
+// lockID identifica il mutex tra funzioni diverse: "pkg.T.campo" per un
+// campo (anche embedded), l'ID della variabile per un mutex globale.
+func lockID(x ast.Expr, sel *types.Selection, info *types.Info) string {
+	// Metodo promosso: il mutex è l'ultimo campo embedded del percorso
+	if path := sel.Index(); len(path) > 1 {
+		t := info.TypeOf(x)
+		var owner types.Type
+		var field *types.Var
+		for _, idx := range path[:len(path)-1] {
+			if p, ok := t.Underlying().(*types.Pointer); ok {
+				t = p.Elem()
+			}
+			st, ok := t.Underlying().(*types.Struct)
+			if !ok {
+				return ""
+			}
+			owner, field = t, st.Field(idx)
+			t = field.Type()
+		}
+		return types.TypeString(owner, nil) + "." + field.Name()
+	}
+	switch e := ast.Unparen(x).(type) {
+	case *ast.SelectorExpr:
+		if s := info.Selections[e]; s != nil && s.Kind() == types.FieldVal {
+			t := info.TypeOf(e.X)
+			if p, ok := t.Underlying().(*types.Pointer); ok {
+				t = p.Elem()
+			}
+			return types.TypeString(t, nil) + "." + e.Sel.Name
+		}
+		if v, ok := info.Uses[e.Sel].(*types.Var); ok && v.Pkg() != nil && v.Parent() == v.Pkg().Scope() {
+			return v.Pkg().Path() + "." + v.Name()
+		}
+	case *ast.Ident:
+		if v, ok := info.Uses[e].(*types.Var); ok && v.Pkg() != nil && v.Parent() == v.Pkg().Scope() {
+			return v.Pkg().Path() + "." + v.Name()
+		}
+	}
+	return ""
+}
+
+// noReturn riconosce le chiamate che non ritornano: panic, os.Exit,
+// log.Fatal* e log.Panic*.
+func noReturn(call *ast.CallExpr, info *types.Info) bool {
+	switch name := calleeName(call, info); name {
+	case "panic", "os.Exit", "log.Fatal", "log.Fatalf", "log.Fatalln", "log.Panic", "log.Panicf", "log.Panicln":
+		return true
+	}
+	return false
+}
+
+// inspectShallow visita n senza entrare nelle closure.
+func inspectShallow(n ast.Node, f func(ast.Node)) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if n != nil {
+			f(n)
+		}
+		return true
+	})
+}
+
+// ── Ordine dei lock ────────────────────────────────────────────────────────
+
+// lockOrder percorre il corpo in ordine di sorgente e restituisce le coppie
+// (tenuto, acquisito) dei mutex con identità globale.
+func (c *checker) lockOrder(body *ast.BlockStmt) []lockPair {
+	var held []string
+	var pairs []lockPair
+	deferred := make(map[*ast.CallExpr]bool)
+	inspectShallow(body, func(n ast.Node) {
+		if d, ok := n.(*ast.DeferStmt); ok {
+			// un unlock differito tiene il lock fino al return
+			deferred[d.Call] = true
+			return
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok || deferred[call] {
+			return
+		}
+		lc, ok := c.lockCall(call)
+		if !ok || lc.id == "" {
+			return
+		}
+		switch lc.method {
+		case "Lock", "RLock":
+			for _, h := range held {
+				if h != lc.id {
+					pairs = append(pairs, lockPair{first: h, second: lc.id, fn: c.fnName, pos: call.Pos()})
+				}
+			}
+			held = append(held, lc.id)
+		default:
+			for i := len(held) - 1; i >= 0; i-- {
+				if held[i] == lc.id {
+					held = append(held[:i], held[i+1:]...)
+					break
+				}
+			}
+		}
+	})
+	return pairs
+}
+
+// orderIssues segnala le coppie di mutex acquisite in ordini opposti: ogni
+// acquisizione coinvolta riceve un issue che cita l'altra funzione.
+func orderIssues(result *loader.LoadResult, pairs []lockPair) []schema.Issue {
+	first := make(map[[2]string]lockPair)
+	for _, p := range pairs {
+		k := [2]string{p.first, p.second}
+		if _, ok := first[k]; !ok {
+			first[k] = p
+		}
+	}
+	var issues []schema.Issue
+	c := &checker{result: result}
+	for k, p := range first {
+		q, ok := first[[2]string{k[1], k[0]}]
+		if !ok {
+			continue
+		}
+		issues = append(issues, schema.Issue{
+			Severity: "warning",
+			Code:     CodeLockOrder,
+			Message:  fmt.Sprintf("%s acquires %s while holding %s, but %s acquires them in the opposite order: possible deadlock", p.fn, p.second, p.first, q.fn),
+			Position: c.result.Position(p.pos),
+		})
+	}
+	return issues
+}
@@ -0,0 +1,240 @@
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// CodeUnexportCandidate identifica un identificatore esportato usato solo
+// nel proprio package.
+const CodeUnexportCandidate = "UNEXPORT_CANDIDATE"
+
+// exportedDecl è una dichiarazione package-level esportata.
+type exportedDecl struct {
+	kind string // "function", "type", "variable" o "constant"
+	id   string
+	pos  token.Pos
+	obj  types.Object
+}
+
+// UnexportCandidates restituisce un issue info per ogni funzione, tipo,
+// variabile o costante package-level esportata che nessun altro package
+// del workspace referenzia (test esterni "_test" inclusi, se caricati). Sono
+// considerati solo i moduli che nessun altro modulo del workspace importa;
+// con un solo modulo, tutto il progetto. Sono esclusi i simboli con
+// //export o //go:linkname e i tipi che compaiono nel tipo di un simbolo
+// usato fuori dal package (es. il risultato di un costruttore esportato).
+// Metodi e campi restano fuori: servono a interfacce, reflection ed encoding.
+func UnexportCandidates(result *loader.LoadResult) []schema.Issue {
+	fset := result.Fset
+	key := func(obj types.Object) string { return obj.Pkg().Path() + "." + obj.Name() }
+
+	// Moduli importati da un altro modulo del workspace.
+	modOf := modulesOf(result)
+	imported := make(map[string]bool)
+	for from, tos := range result.ImportGraph() {
+		for _, to := range tos {
+			if mf, mt := modOf[from], modOf[to]; mf != "" && mt != "" && mf != mt {
+				imported[mt] = true
+			}
+		}
+	}
+
+	decls := make(map[string]*exportedDecl)
+	for _, pkg := range result.Packages {
+		if pkg == nil || pkg.Types == nil || pkg.TypesInfo == nil || imported[modOf[pkg.PkgPath]] {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil || !loader.UnderRoot(fset.Position(file.Pos()).Filename, result.Root) {
+				continue
+			}
+			linked := linknames(file)
+			for _, decl := range file.Decls {
+				var names []*ast.Ident
+				switch d := decl.(type) {
+				case *ast.FuncDecl:
+					if d.Recv != nil || cgoExported(d) {
+						continue
+					}
+					names = []*ast.Ident{d.Name}
+				case *ast.GenDecl:
+					for _, spec := range d.Specs {
+						names = append(names, specNames(spec)...)
+					}
+				}
+				for _, name := range names {
+					obj := pkg.TypesInfo.Defs[name]
+					if obj == nil || !obj.Exported() || obj.Parent() != pkg.Types.Scope() || linked[obj.Name()] {
+						continue
+					}
+					k := key(obj)
+					if _, ok := decls[k]; ok {
+						continue
+					}
+					kind := kindOf(obj)
+					if _, ok := obj.(*types.Func); ok {
+						kind = "function"
+					}
+					decls[k] = &exportedDecl{kind: kind, id: ids.Type(pkg.PkgPath, obj.Name()), pos: name.Pos(), obj: obj}
+				}
+			}
+		}
+	}
+
+	// Riferimenti per simbolo, distinguendo quelli da altri package.
+	internal := make(map[string]int)
+	external := make(map[string]bool)
+	for _, pkg := range result.Packages {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for _, obj := range pkg.TypesInfo.Uses {
+			if obj.Pkg() == nil || obj.Parent() != obj.Pkg().Scope() {
+				continue
+			}
+			k := key(obj)
+			d, ok := decls[k]
+			if !ok {
+				continue
+			}
+			if pkg.PkgPath == obj.Pkg().Path() {
+				internal[k]++
+				continue
+			}
+			if !external[k] {
+				external[k] = true
+				exposeTypes(d.obj.Type(), key, external, make(map[types.Type]bool))
+			}
+		}
+	}
+
+	scoped := make(map[string]bool)
+	for _, pkg := range result.ScopedPackages() {
+		if pkg == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file != nil {
+				scoped[fset.Position(file.Pos()).Filename] = true
+			}
+		}
+	}
+
+	var issues []schema.Issue
+	c := &checker{result: result}
+	for k, d := range decls {
+		if external[k] || !scoped[fset.Position(d.pos).Filename] {
+			continue
+		}
+		issues = append(issues, schema.Issue{
+			Severity: "info",
+			Code:     CodeUnexportCandidate,
+			Message:  fmt.Sprintf("exported %s %s is only used in its own package (%d references) and can be unexported", d.kind, d.id, internal[k]),
+			Position: c.result.Position(d.pos),
+		})
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		pi, pj := issues[i].Position, issues[j].Position
+		if pi.File != pj.File {
+			return pi.File < pj.File
+		}
+		if pi.StartLine != pj.StartLine {
+			return pi.StartLine < pj.StartLine
+		}
+		return pi.StartColumn < pj.StartColumn
+	})
+	return issues
+}
+
+// exposeTypes marca come usati all'esterno i tipi nominati che compaiono in
+// t: chi usa il simbolo ne maneggia i valori anche senza nominarli.
+func exposeTypes(t types.Type, key func(types.Object) string, external map[string]bool, seen map[types.Type]bool) {
+	if t == nil || seen[t] {
+		return
+	}
+	seen[t] = true
+	switch x := t.(type) {
+	case *types.Named:
+		if obj := x.Obj(); obj.Pkg() != nil {
+			external[key(obj)] = true
+		}
+		if args := x.TypeArgs(); args != nil {
+			for i := 0; i < args.Len(); i++ {
+				exposeTypes(args.At(i), key, external, seen)
+			}
+		}
+	case *types.Alias:
+		if obj := x.Obj(); obj.Pkg() != nil {
+			external[key(obj)] = true
+		}
+		exposeTypes(types.Unalias(x), key, external, seen)
+	case *types.Pointer:
+		exposeTypes(x.Elem(), key, external, seen)
+	case *types.Slice:
+		exposeTypes(x.Elem(), key, external, seen)
+	case *types.Array:
+		exposeTypes(x.Elem(), key, external, seen)
+	case *types.Chan:
+		exposeTypes(x.Elem(), key, external, seen)
+	case *types.Map:
+		exposeTypes(x.Key(), key, external, seen)
+		exposeTypes(x.Elem(), key, external, seen)
+	case *types.Signature:
+		for _, tuple := range []*types.Tuple{x.Params(), x.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				exposeTypes(tuple.At(i).Type(), key, external, seen)
+			}
+		}
+	}
+}
+
+// cgoExported riporta se la funzione ha una direttiva //export di cgo.
+func cgoExported(fn *ast.FuncDecl) bool {
+	if fn.Doc == nil {
+		return false
+	}
+	for _, c := range fn.Doc.List {
+		if strings.HasPrefix(c.Text, "//export ") {
+			return true
+		}
+	}
+	return false
+}
+
+// modulesOf restituisce il module path di ogni package del progetto, letto
+// dal go.mod più vicino sopra la sua directory (entro la root).
+func modulesOf(result *loader.LoadResult) map[string]string {
+	byDir := make(map[string]string)
+	var lookup func(dir string) string
+	lookup = func(dir string) string {
+		if mod, ok := byDir[dir]; ok {
+			return mod
+		}
+		mod := ""
+		if data, err := result.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			mod = modfile.ModulePath(data)
+		} else if parent := filepath.Dir(dir); parent != dir && loader.UnderRoot(parent, result.Root) {
+			mod = lookup(parent)
+		}
+		byDir[dir] = mod
+		return mod
+	}
+
+	out := make(map[string]string)
+	for pkgPath, dir := range result.PackageDirs() {
+		out[pkgPath] = lookup(filepath.Join(result.Root, filepath.FromSlash(dir)))
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package loader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"testing/fstest"
+)
+
+// OpenArchive apre un archivio di sorgenti (.zip, .tar, .tar.gz/.tgz) come
+// fs.FS da passare in Options.FS, senza estrarlo su disco. La radice è la
+// directory del go.mod meno profondo, così uno zip nel formato GOPROXY
+// (prefisso "modulo@versione/") è analizzato come il modulo stesso; senza
+// go.mod, un'unica directory di primo livello è rimossa. Close rilascia
+// l'archivio.
+func OpenArchive(name string) (fs.FS, io.Closer, error) {
+	var (
+		fsys   fs.FS
+		closer io.Closer = io.NopCloser(nil)
+		err    error
+	)
+	switch lower := strings.ToLower(name); {
+	case strings.HasSuffix(lower, ".zip"):
+		var zr *zip.ReadCloser
+		zr, err = zip.OpenReader(name)
+		if err == nil {
+			fsys, closer = zr, zr
+		}
+	case strings.HasSuffix(lower, ".tar"), strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		fsys, err = readTar(name)
+	default:
+		return nil, nil, fmt.Errorf("unsupported archive format: %s (valid: .zip, .tar, .tar.gz, .tgz)", name)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("open archive: %w", err)
+	}
+
+	root, err := archiveRoot(fsys)
+	if err != nil {
+		closer.Close()
+		return nil, nil, fmt.Errorf("read archive: %w", err)
+	}
+	if root != "." {
+		if fsys, err = fs.Sub(fsys, root); err != nil {
+			closer.Close()
+			return nil, nil, err
+		}
+	}
+	return fsys, closer, nil
+}
+
+// readTar legge in memoria i file regolari di un tar, eventualmente
+// compresso con gzip (il formato tar non consente accesso casuale).
+func readTar(name string) (fs.FS, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if lower := strings.ToLower(name); strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	fsys := fstest.MapFS{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fsys, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		p := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if !fs.ValidPath(p) {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		fsys[p] = &fstest.MapFile{Data: data, Mode: fs.FileMode(hdr.Mode).Perm(), ModTime: hdr.ModTime}
+	}
+}
+
+// archiveRoot trova la radice del modulo nell'archivio: la directory del
+// go.mod meno profondo, altrimenti l'unica directory di primo livello,
+// altrimenti la radice stessa.
+func archiveRoot(fsys fs.FS) (string, error) {
+	var mods []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() == "go.mod" {
+			mods = append(mods, path.Dir(p))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(mods) > 0 {
+		depth := func(p string) int {
+			if p == "." {
+				return 0
+			}
+			return strings.Count(p, "/") + 1
+		}
+		sort.Slice(mods, func(i, j int) bool {
+			if di, dj := depth(mods[i]), depth(mods[j]); di != dj {
+				return di < dj
+			}
+			return mods[i] < mods[j]
+		})
+		return mods[0], nil
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return entries[0].Name(), nil
+	}
+	return ".", nil
+}
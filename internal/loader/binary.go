@@ -0,0 +1,67 @@
+package loader
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// scopeBinaries restringe pkgs ai package main nelle directory indicate
+// (relative a root o assolute) e ai package del progetto che importano,
+// anche transitivamente, comprese le varianti di test. Restituisce anche
+// gli import path dei package main.
+func scopeBinaries(pkgs []*packages.Package, root string, dirs []string) ([]*packages.Package, []string, error) {
+	// le varianti di test hanno lo stesso PkgPath: si seguono gli import
+	// del package vero e proprio
+	byPath := make(map[string]*packages.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		if pkg != nil && (pkg.ID == pkg.PkgPath || byPath[pkg.PkgPath] == nil) {
+			byPath[pkg.PkgPath] = pkg
+		}
+	}
+
+	var mains []string
+	keep := make(map[string]bool)
+	var visit func(pkg *packages.Package)
+	visit = func(pkg *packages.Package) {
+		if keep[pkg.PkgPath] {
+			return
+		}
+		keep[pkg.PkgPath] = true
+		for path := range pkg.Imports {
+			if dep, ok := byPath[path]; ok {
+				visit(dep)
+			}
+		}
+	}
+	for _, dir := range dirs {
+		abs := dir
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(root, dir)
+		}
+		abs = filepath.Clean(abs)
+		var main *packages.Package
+		for _, pkg := range pkgs {
+			if pkg != nil && pkg.ID == pkg.PkgPath && pkg.Name == "main" && len(pkg.GoFiles) > 0 && filepath.Dir(pkg.GoFiles[0]) == abs {
+				main = pkg
+				break
+			}
+		}
+		if main == nil {
+			return nil, nil, fmt.Errorf("binary %s: no main package in that directory", dir)
+		}
+		mains = append(mains, main.PkgPath)
+		visit(main)
+	}
+	sort.Strings(mains)
+
+	out := make([]*packages.Package, 0, len(keep))
+	for _, pkg := range pkgs {
+		if pkg != nil && keep[pkg.PkgPath] {
+			out = append(out, pkg)
+		}
+	}
+	return out, mains, nil
+}
@@ -0,0 +1,60 @@
+package loader
+
+import (
+	"log/slog"
+	"runtime/debug"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// ForEachPackageSSA costruisce l'SSA per un package del progetto alla volta e
+// invoca fn con una vista parziale del LoadResult (un solo package, con il suo
+// SSAProgram). Le dipendenze sono create dai soli tipi, senza body, quindi la
+// memoria occupata è limitata al package corrente. Dopo ogni package la
+// memoria viene restituita al sistema operativo.
+func ForEachPackageSSA(result *LoadResult, fn func(part *LoadResult) error) error {
+	for i, pkg := range result.Packages {
+		if pkg == nil {
+			continue
+		}
+
+		part := SubsetSSA(result, []*packages.Package{pkg})
+		if err := fn(part); err != nil {
+			return err
+		}
+
+		slog.Debug("per-package SSA done", "package", pkg.PkgPath, "index", i+1, "total", len(result.Packages))
+		part = nil
+		debug.FreeOSMemory()
+	}
+	return nil
+}
+
+// SubsetSSA costruisce l'SSA dei soli pkgs e restituisce la vista parziale
+// del LoadResult che li contiene: le loro dipendenze, anche del progetto,
+// sono create dai soli tipi, senza body.
+func SubsetSSA(result *LoadResult, pkgs []*packages.Package) *LoadResult {
+	mode := ssa.SanityCheckFunctions | ssa.InstantiateGenerics
+
+	prog, ssaPkgs := ssautil.Packages(pkgs, mode)
+	prog.Build()
+
+	var valid []*ssa.Package
+	for _, p := range ssaPkgs {
+		if p != nil {
+			valid = append(valid, p)
+		}
+	}
+
+	return &LoadResult{
+		Packages:    pkgs,
+		SSAProgram:  prog,
+		SSAPackages: valid,
+		Fset:        result.Fset,
+		Root:        result.Root,
+		FS:          result.FS,
+		project:     result.ProjectPackages(),
+	}
+}
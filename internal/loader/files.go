@@ -0,0 +1,94 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// filePatterns converte i file richiesti in query "file=" di go/packages
+// (che caricano il package che contiene ciascun file) e nel set dei path
+// assoluti usato per restringere l'analisi.
+func filePatterns(files []string) ([]string, map[string]bool, error) {
+	patterns := make([]string, 0, len(files))
+	set := make(map[string]bool, len(files))
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".go") {
+			return nil, nil, fmt.Errorf("not a Go file: %s", f)
+		}
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid file path %s: %w", f, err)
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return nil, nil, fmt.Errorf("file not found: %s", f)
+		}
+		if !set[abs] {
+			set[abs] = true
+			patterns = append(patterns, "file="+abs)
+		}
+	}
+	return patterns, set, nil
+}
+
+// ScopedPackages restituisce i package del risultato ristretti ai file
+// richiesti (Options.Files): Syntax, GoFiles e CompiledGoFiles contengono solo
+// quei file, mentre Types e TypesInfo restano quelli del package completo.
+// Senza restrizione restituisce Packages. I package senza file richiesti
+// sono omessi.
+func (r *LoadResult) ScopedPackages() []*packages.Package {
+	if r.Files == nil {
+		return r.Packages
+	}
+
+	out := make([]*packages.Package, 0, len(r.Packages))
+	for _, pkg := range r.Packages {
+		if pkg == nil {
+			continue
+		}
+		scoped := *pkg
+		scoped.GoFiles = filterFiles(pkg.GoFiles, r.Files)
+		scoped.CompiledGoFiles = filterFiles(pkg.CompiledGoFiles, r.Files)
+		scoped.Syntax = nil
+		for _, f := range pkg.Syntax {
+			if f != nil && r.Files[r.Fset.File(f.Pos()).Name()] {
+				scoped.Syntax = append(scoped.Syntax, f)
+			}
+		}
+		if len(scoped.Syntax) > 0 {
+			out = append(out, &scoped)
+		}
+	}
+	return out
+}
+
+// ScopedFiles restituisce i file richiesti, relativi alla root e ordinati;
+// nil se l'analisi non è ristretta.
+func (r *LoadResult) ScopedFiles() []string {
+	if r.Files == nil {
+		return nil
+	}
+	out := make([]string, 0, len(r.Files))
+	for f := range r.Files {
+		if rel, err := filepath.Rel(r.Root, f); err == nil {
+			f = rel
+		}
+		out = append(out, filepath.ToSlash(f))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func filterFiles(files []string, keep map[string]bool) []string {
+	var out []string
+	for _, f := range files {
+		if keep[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
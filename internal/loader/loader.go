@@ -2,22 +2,26 @@ package loader
 
 import (
 	"fmt"
+	"go/ast"
+	"go/parser"
 	"go/token"
-	"log"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
-)
 
-// Program is a simple file listing rooted at Root (legacy).
-type Program struct {
-	Root  string
-	Files []string // absolute paths to .go files
-}
+	"github.com/codellm-devkit/codeanalyzer-go/internal/logging"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
+)
 
 // LoadResult contiene il risultato del caricamento con supporto SSA opzionale.
 type LoadResult struct {
@@ -26,140 +30,207 @@ type LoadResult struct {
 	SSAPackages []*ssa.Package // nil se NeedSSA è false
 	Fset        *token.FileSet
 	Root        string
-}
 
-// Options controlla il comportamento del loader.
-type Options struct {
-	IncludeTest bool
-	ExcludeDirs []string // basenames da escludere
-	OnlyPkg     []string // filtra per sottostringa nel path relativo
-	NeedSSA     bool     // se true, costruisce anche SSA
+	// FS sono i sorgenti del progetto con radice in Root: Options.FS se
+	// indicato, altrimenti la directory su disco, con i file di
+	// Options.Overlay al posto degli originali. Chi legge file del progetto
+	// (sorgenti, go.mod, file embeddati, .sql) passa da qui o da ReadFile,
+	// non dal disco, che con --root-archive è vuoto.
+	FS fs.FS
+
+	// Errors raccoglie gli errori di caricamento, parsing e type checking di
+	// tutti i package (dipendenze comprese). L'analisi prosegue sui package
+	// validi: chi consuma il risultato deve riportarli come degradazione.
+	Errors []PackageError
+
+	// Files, se non nil, limita l'analisi ai file indicati (path assoluti):
+	// i package sono caricati e type-checked per intero, ScopedPackages ne
+	// restituisce la vista ristretta.
+	Files map[string]bool
+
+	// Binaries sono gli import path dei package main a cui è ristretta
+	// l'analisi (Options.Binaries), nil senza restrizione.
+	Binaries []string
+
+	// Durate di caricamento e costruzione SSA (SSADuration è zero con
+	// PerPackageSSA: l'SSA è costruito dentro le singole fasi).
+	// TypeCheckDuration è la parte di LoadDuration spesa in parsing e type
+	// checking, dal primo file letto alla fine del caricamento; il resto è
+	// la risoluzione dei package (go list).
+	LoadDuration      time.Duration
+	TypeCheckDuration time.Duration
+	SSADuration       time.Duration
+
+	// PerPackageSSA è true quando l'SSA va costruito un package alla volta
+	// (memory budget attivo): SSAProgram resta nil, usare ForEachPackageSSA.
+	PerPackageSSA bool
+
+	// project sono i package path del progetto; nelle viste parziali di
+	// ForEachPackageSSA resta quello del risultato completo
+	project map[string]bool
 }
 
-// Load walks the root directory and collects .go files, excluding vendor/.git/testdata.
-func Load(root string) (*Program, error) {
-	return LoadWithOptions(root, Options{})
+// ProjectPackages restituisce i package path caricati come parte del
+// progetto (esclude le dipendenze).
+func (r *LoadResult) ProjectPackages() map[string]bool {
+	if r.project == nil {
+		r.project = make(map[string]bool, len(r.Packages))
+		for _, pkg := range r.Packages {
+			if pkg != nil {
+				r.project[pkg.PkgPath] = true
+			}
+		}
+	}
+	return r.project
 }
 
-// LoadWithOptions cammina la directory root e raccoglie i file .go secondo le opzioni.
-func LoadWithOptions(root string, opts Options) (*Program, error) {
-	ex := map[string]struct{}{
-		"vendor":   {},
-		".git":     {},
-		"testdata": {},
-	}
-	for _, d := range opts.ExcludeDirs {
-		d = strings.TrimSpace(d)
-		if d == "" {
+// PackageDirs restituisce la directory di ogni package del progetto,
+// relativa alla root e con separatore '/' ("." per la root).
+func (r *LoadResult) PackageDirs() map[string]string {
+	out := make(map[string]string, len(r.Packages))
+	for _, pkg := range r.Packages {
+		if pkg == nil || len(pkg.GoFiles) == 0 {
 			continue
 		}
-		ex[d] = struct{}{}
-	}
-
-	var files []string
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		rel, err := filepath.Rel(r.Root, filepath.Dir(pkg.GoFiles[0]))
 		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			base := filepath.Base(path)
-			if _, skip := ex[base]; skip || strings.HasPrefix(base, ".") {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		if strings.HasSuffix(path, ".go") {
-			if !opts.IncludeTest && strings.HasSuffix(path, "_test.go") {
-				return nil
-			}
-			// only-pkg filtro su path relativo
-			if len(opts.OnlyPkg) > 0 {
-				rel := path
-				if rp, err := filepath.Rel(root, path); err == nil {
-					rel = rp
-				}
-				keep := false
-				rp := filepath.ToSlash(rel)
-				for _, s := range opts.OnlyPkg {
-					s = strings.TrimSpace(s)
-					if s == "" {
-						continue
-					}
-					if strings.Contains(rp, s) {
-						keep = true
-						break
-					}
-				}
-				if !keep {
-					return nil
-				}
-			}
-			files = append(files, path)
+			continue
 		}
-		return nil
-	})
-	if err != nil {
-		return nil, err
+		out[pkg.PkgPath] = filepath.ToSlash(rel)
 	}
-	return &Program{Root: root, Files: files}, nil
+	return out
 }
 
-// LoadWithSSA carica i pacchetti Go usando go/packages e opzionalmente costruisce SSA.
-func LoadWithSSA(rootPath string, opts Options) (*LoadResult, error) {
-	verbose := false // Could be added to Options if needed
+// PackageError è un errore riportato da go/packages per un package.
+type PackageError struct {
+	Package string // import path del package
+	Kind    string // list|parse|type|unknown
+	File    string // file relativo alla root (vuoto se sconosciuto)
+	Line    int
+	Column  int
+	Message string
+}
+
+// Options controlla il comportamento del loader.
+type Options struct {
+	IncludeTest bool
+	ExcludeDirs []string          // basenames da escludere
+	Packages    *pkgfilter.Filter // filtra i package per import path
+	NeedSyntax  bool              // parsing dei file (Syntax)
+	NeedTypes   bool              // type checking (Types, TypesInfo con NeedSyntax)
+	NeedSSA     bool              // costruisce anche SSA; implica NeedSyntax e NeedTypes
+	MaxMemoryMB int               // se > 0, SSA per-package (memory budget)
+
+	// Files limita l'analisi a questi file .go (assoluti o relativi alla
+	// directory corrente), type-checked nel contesto del loro package
+	Files []string
+
+	// Binaries limita l'analisi ai package main di queste directory
+	// (relative alla root o assolute) e ai package del progetto che
+	// importano, anche transitivamente
+	Binaries []string
+
+	// Overlay sostituisce il contenuto di file (path assoluto → sorgente),
+	// ad es. buffer non salvati di un editor. Vedi ReadOverlay.
+	Overlay map[string][]byte
+
+	// Env aggiunge variabili d'ambiente a quelle del processo per go list
+	// (es. GOOS, GOARCH, CGO_ENABLED, GOFLAGS=-tags=...).
+	Env []string
+
+	// FS, se non nil, fornisce i sorgenti al posto del disco: i suoi file
+	// .go, go.mod e go.sum sono montati come overlay sotto la root, che
+	// deve esistere ma può essere vuota (go list vi risolve i package).
+	// Overlay ha la precedenza sui file di FS.
+	FS fs.FS
+
+	Progress *logging.Progress // progress reporter opzionale (nil = disabilitato)
+}
 
+// Load carica i package Go sotto rootPath con go/packages. È l'unico punto
+// di caricamento della CLI: le capability di Options (NeedSyntax, NeedTypes,
+// NeedSSA) decidono quanto lavoro fare, mentre filtri ed esclusioni si
+// applicano allo stesso modo in ogni caso.
+func Load(rootPath string, opts Options) (*LoadResult, error) {
 	// Convert to absolute path
 	absRoot, err := filepath.Abs(rootPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
-	// Use "./..." pattern to load all packages recursively
-	pattern := "./..."
+	// Use "./..." pattern to load all packages recursively, or only the
+	// packages enclosing the requested files
+	patterns := []string{"./..."}
+	var fileSet map[string]bool
+	if work := workPatterns(absRoot); work != nil && len(opts.Files) == 0 {
+		patterns = work
+	}
+	if len(opts.Files) > 0 {
+		patterns, fileSet, err = filePatterns(opts.Files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	overlay := opts.Overlay
+	if opts.FS != nil {
+		if overlay, err = mountFS(opts.FS, absRoot, opts.Overlay); err != nil {
+			return nil, err
+		}
+	}
 
 	cfg := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedFiles |
-			packages.NeedCompiledGoFiles |
-			packages.NeedImports |
-			packages.NeedDeps |
-			packages.NeedTypes |
-			packages.NeedSyntax |
-			packages.NeedTypesInfo,
-		Dir: absRoot,
+		Mode: loadMode(opts),
+		Dir:  absRoot,
 		// Include test files if requested
 		Tests: opts.IncludeTest,
+
+		Overlay: overlay,
+	}
+	if len(opts.Env) > 0 {
+		cfg.Env = append(os.Environ(), opts.Env...)
+	}
+	// go/packages non espone le proprie fasi: il primo file parsato segna
+	// la fine di go list e l'inizio di parsing e type checking
+	var parseStart atomic.Int64
+	cfg.ParseFile = func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+		parseStart.CompareAndSwap(0, time.Now().UnixNano())
+		return parser.ParseFile(fset, filename, src, parser.AllErrors|parser.ParseComments)
 	}
 
 	// Load all packages matching the pattern
-	pkgs, err := packages.Load(cfg, pattern)
+	opts.Progress.Phase("Loading packages")
+	loadStart := time.Now()
+	pkgs, err := packages.Load(cfg, patterns...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load packages: %w", err)
 	}
+	loadEnd := time.Now()
 
 	if len(pkgs) == 0 {
 		return nil, fmt.Errorf("no packages found in %s", rootPath)
 	}
 
-	// Check for errors in loaded packages (log only, don't fail)
-	for _, pkg := range pkgs {
-		if len(pkg.Errors) > 0 {
-			for _, e := range pkg.Errors {
-				log.Printf("Package error in %s: %v", pkg.PkgPath, e)
-			}
-		}
+	// Raccogli gli errori di tutti i package (non bloccanti)
+	loadErrors := collectErrors(pkgs, absRoot)
+	for _, e := range loadErrors {
+		slog.Warn("package error", "package", e.Package, "kind", e.Kind, "file", e.File, "line", e.Line, "error", e.Message)
 	}
 
 	// Filter out packages with errors and apply user filters
-	validPkgs := filterLoadedPackages(pkgs, opts.ExcludeDirs, opts.OnlyPkg)
+	validPkgs := filterLoadedPackages(pkgs, opts.ExcludeDirs, opts.Packages)
+	var binaries []string
+	if len(opts.Binaries) > 0 {
+		if validPkgs, binaries, err = scopeBinaries(validPkgs, absRoot, opts.Binaries); err != nil {
+			return nil, err
+		}
+	}
 
 	if len(validPkgs) == 0 {
 		return nil, fmt.Errorf("no valid packages found (all had errors or were filtered)")
 	}
 
-	if verbose {
-		log.Printf("Loaded %d valid packages out of %d total", len(validPkgs), len(pkgs))
-	}
+	slog.Debug("packages loaded", "valid", len(validPkgs), "total", len(pkgs))
 
 	// Get FileSet from first package (all packages share the same FileSet)
 	var fset *token.FileSet
@@ -177,18 +248,89 @@ func LoadWithSSA(rootPath string, opts Options) (*LoadResult, error) {
 		Packages: validPkgs,
 		Root:     absRoot,
 		Fset:     fset,
+		Errors:   loadErrors,
+		Files:    fileSet,
+		Binaries: binaries,
+		FS:       newSourceFS(sourceBase(opts.FS, absRoot), absRoot, opts.Overlay),
+
+		LoadDuration: loadEnd.Sub(loadStart),
+	}
+	if ns := parseStart.Load(); ns != 0 {
+		result.TypeCheckDuration = loadEnd.Sub(time.Unix(0, ns))
 	}
 
 	// Build SSA if requested
-	if opts.NeedSSA {
-		result.SSAProgram, result.SSAPackages = buildSSAProgram(validPkgs, verbose)
+	if opts.NeedSSA && opts.MaxMemoryMB > 0 {
+		// Memory budget: SSA costruito on demand un package alla volta (vedi
+		// ForEachPackageSSA); il limite soft per il GC lo imposta il chiamante
+		result.PerPackageSSA = true
+	} else if opts.NeedSSA {
+		opts.Progress.Phase("Building SSA")
+		ssaStart := time.Now()
+		result.SSAProgram, result.SSAPackages = buildSSAProgram(validPkgs)
+		result.SSADuration = time.Since(ssaStart)
 	}
 
 	return result, nil
 }
 
+// sourceBase restituisce fsys, o la directory root su disco se nil.
+func sourceBase(fsys fs.FS, root string) fs.FS {
+	if fsys != nil {
+		return fsys
+	}
+	return os.DirFS(root)
+}
+
+// loadMode traduce le capability richieste nel mode di go/packages. Nomi,
+// file e import sono sempre caricati: bastano per il grafo degli import.
+func loadMode(opts Options) packages.LoadMode {
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports
+	syntax := opts.NeedSyntax || opts.NeedSSA
+	if syntax {
+		mode |= packages.NeedSyntax
+	}
+	if opts.NeedTypes || opts.NeedSSA {
+		mode |= packages.NeedDeps | packages.NeedTypes | packages.NeedTypesSizes
+		if syntax {
+			mode |= packages.NeedTypesInfo
+		}
+	}
+	return mode
+}
+
+// workPatterns restituisce un pattern "./dir/..." per modulo quando root è
+// la radice di un workspace (go.work senza go.mod): lì "./..." non
+// corrisponde a nessun package. Restituisce nil negli altri casi.
+func workPatterns(root string) []string {
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err == nil {
+		return nil
+	}
+	name := filepath.Join(root, "go.work")
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	wf, err := modfile.ParseWork(name, data, nil)
+	if err != nil || len(wf.Use) == 0 {
+		return nil
+	}
+	patterns := make([]string, 0, len(wf.Use))
+	for _, use := range wf.Use {
+		dir := filepath.ToSlash(filepath.Clean(use.Path))
+		if filepath.IsAbs(use.Path) || dir == ".." || strings.HasPrefix(dir, "../") {
+			continue // moduli fuori dalla root: non fanno parte del progetto
+		}
+		patterns = append(patterns, "./"+strings.TrimPrefix(dir, "./")+"/...")
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	return patterns
+}
+
 // buildSSAProgram costruisce il programma SSA dai pacchetti caricati.
-func buildSSAProgram(pkgs []*packages.Package, verbose bool) (*ssa.Program, []*ssa.Package) {
+func buildSSAProgram(pkgs []*packages.Package) (*ssa.Program, []*ssa.Package) {
 	if len(pkgs) == 0 {
 		return nil, nil
 	}
@@ -209,16 +351,14 @@ func buildSSAProgram(pkgs []*packages.Package, verbose bool) (*ssa.Program, []*s
 		}
 	}
 
-	if verbose {
-		log.Printf("Built SSA for %d packages", len(validSSA))
-	}
+	slog.Debug("SSA built", "packages", len(validSSA))
 
 	return prog, validSSA
 }
 
 // filterLoadedPackages applica i filtri di directory e package.
-func filterLoadedPackages(pkgs []*packages.Package, excludeDirs, onlyPkg []string) []*packages.Package {
-	if len(excludeDirs) == 0 && len(onlyPkg) == 0 {
+func filterLoadedPackages(pkgs []*packages.Package, excludeDirs []string, filter *pkgfilter.Filter) []*packages.Package {
+	if len(excludeDirs) == 0 && filter.Empty() {
 		return pkgs
 	}
 
@@ -231,18 +371,8 @@ func filterLoadedPackages(pkgs []*packages.Package, excludeDirs, onlyPkg []strin
 	}
 
 	keep := func(p *packages.Package) bool {
-		if len(onlyPkg) > 0 {
-			ok := false
-			for _, s := range onlyPkg {
-				s = strings.TrimSpace(s)
-				if s != "" && strings.Contains(p.PkgPath, s) {
-					ok = true
-					break
-				}
-			}
-			if !ok {
-				return false
-			}
+		if !filter.Allows(p.PkgPath) {
+			return false
 		}
 		if len(ex) == 0 {
 			return true
@@ -292,6 +422,68 @@ func collectAllPackages(roots []*packages.Package) []*packages.Package {
 	return out
 }
 
+// collectErrors raccoglie gli errori di pkgs e delle loro dipendenze,
+// una volta per package, con posizione relativa a root.
+func collectErrors(pkgs []*packages.Package, root string) []PackageError {
+	var out []PackageError
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		for _, e := range p.Errors {
+			pe := PackageError{
+				Package: p.PkgPath,
+				Kind:    errorKind(e.Kind),
+				Message: e.Msg,
+			}
+			pe.File, pe.Line, pe.Column = splitErrorPos(e.Pos)
+			if pe.File != "" {
+				if rel, err := filepath.Rel(root, pe.File); err == nil && !strings.HasPrefix(rel, "..") {
+					pe.File = filepath.ToSlash(rel)
+				}
+			}
+			out = append(out, pe)
+		}
+	})
+	return out
+}
+
+func errorKind(k packages.ErrorKind) string {
+	switch k {
+	case packages.ListError:
+		return "list"
+	case packages.ParseError:
+		return "parse"
+	case packages.TypeError:
+		return "type"
+	}
+	return "unknown"
+}
+
+// splitErrorPos scompone una posizione "file:line:col" (o "file:line",
+// o "-" se sconosciuta) come riportata da go/packages.
+func splitErrorPos(pos string) (file string, line, col int) {
+	if pos == "" || pos == "-" {
+		return "", 0, 0
+	}
+	parts := strings.Split(pos, ":")
+	// Il path può contenere ":" (es. su Windows): i numeri sono in coda
+	nums := make([]int, 0, 2)
+	for len(parts) > 1 && len(nums) < 2 {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		nums = append([]int{n}, nums...)
+		parts = parts[:len(parts)-1]
+	}
+	file = strings.Join(parts, ":")
+	if len(nums) > 0 {
+		line = nums[0]
+	}
+	if len(nums) > 1 {
+		col = nums[1]
+	}
+	return file, line, col
+}
+
 // dedupPackages rimuove duplicati per PkgPath mantenendo l'ordine di prima occorrenza.
 func dedupPackages(in []*packages.Package) []*packages.Package {
 	seen := make(map[string]struct{}, len(in))
@@ -0,0 +1,56 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// overlayFile è il formato JSON di "go build -overlay" (lo stesso usato da
+// gopls): Replace mappa il path di un file sorgente sul file che ne
+// contiene il contenuto da usare al suo posto.
+type overlayFile struct {
+	Replace map[string]string `json:"Replace"`
+}
+
+// ReadOverlay legge un file di overlay ("-" = stdin) e restituisce la mappa
+// path assoluto → contenuto attesa da Options.Overlay. I path relativi sono
+// risolti rispetto alla directory corrente, come fa il comando go. Un
+// replacement vuoto (file cancellato) non è supportato da go/packages.
+func ReadOverlay(path string) (map[string][]byte, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open overlay: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var of overlayFile
+	if err := json.NewDecoder(r).Decode(&of); err != nil {
+		return nil, fmt.Errorf("decode overlay: %w", err)
+	}
+
+	overlay := make(map[string][]byte, len(of.Replace))
+	for src, repl := range of.Replace {
+		if repl == "" {
+			return nil, fmt.Errorf("overlay: deleting %s is not supported", src)
+		}
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return nil, fmt.Errorf("overlay: %w", err)
+		}
+		content, err := os.ReadFile(repl)
+		if err != nil {
+			return nil, fmt.Errorf("overlay: read replacement for %s: %w", src, err)
+		}
+		overlay[abs] = content
+	}
+	return overlay, nil
+}
@@ -0,0 +1,36 @@
+package loader
+
+import (
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Position converte pos in CLDKPosition con il file relativo a root, con "/"
+// come separatore; i file fuori dalla root restano con percorsi relativi
+// che iniziano con "..".
+func Position(pos token.Position, root string) *schema.CLDKPosition {
+	file := pos.Filename
+	if rel, err := filepath.Rel(root, file); err == nil {
+		file = filepath.ToSlash(rel)
+	}
+	return &schema.CLDKPosition{File: file, StartLine: pos.Line, StartColumn: pos.Column}
+}
+
+// Position converte p, un token.Pos di Fset, in CLDKPosition relativa a
+// Root. Restituisce nil se p non è valido.
+func (r *LoadResult) Position(p token.Pos) *schema.CLDKPosition {
+	if r.Fset == nil || !p.IsValid() {
+		return nil
+	}
+	return Position(r.Fset.Position(p), r.Root)
+}
+
+// UnderRoot indica se file è root o si trova sotto root; esclude, ad
+// esempio, i main generati per i package ".test" nella build cache.
+func UnderRoot(file, root string) bool {
+	rel, err := filepath.Rel(root, file)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
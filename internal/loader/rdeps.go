@@ -0,0 +1,47 @@
+package loader
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/graph"
+)
+
+// ImportGraph restituisce il grafo degli import tra i package caricati
+// (package → package importati), dipendenze esterne comprese come archi.
+func (r *LoadResult) ImportGraph() map[string][]string {
+	adj := make(map[string][]string)
+	for _, pkg := range r.Packages {
+		if pkg == nil {
+			continue
+		}
+		for _, imp := range pkg.Imports {
+			adj[pkg.PkgPath] = append(adj[pkg.PkgPath], importPath(imp))
+		}
+	}
+	return adj
+}
+
+// importPath restituisce l'import path di imp. Senza NeedTypes gli import
+// sono segnaposto con il solo ID, che per le varianti di test ha la forma
+// "p [p.test]".
+func importPath(imp *packages.Package) string {
+	if imp.PkgPath != "" {
+		return imp.PkgPath
+	}
+	id, _, _ := strings.Cut(imp.ID, " [")
+	return id
+}
+
+// Dependents restituisce i package del progetto che importano, anche
+// transitivamente, uno dei package indicati, insieme ai package stessi
+// (anche se non caricati, es. perché rimossi). Usa il grafo degli import
+// già caricato: non servono SSA né un nuovo caricamento.
+func (r *LoadResult) Dependents(pkgPaths []string) map[string]bool {
+	out := make(map[string]bool)
+	for p := range graph.ReverseClosure(r.ImportGraph(), pkgPaths) {
+		out[p] = true
+	}
+	return out
+}
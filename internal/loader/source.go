@@ -0,0 +1,65 @@
+package loader
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+)
+
+// sourceFS espone i sorgenti del progetto con radice in root: i file di
+// overlay (path assoluti) prevalgono su quelli di base. I file presenti solo
+// nell'overlay si aprono ma non compaiono nei listing delle directory.
+type sourceFS struct {
+	base    fs.FS
+	root    string
+	overlay map[string][]byte
+}
+
+func newSourceFS(base fs.FS, root string, overlay map[string][]byte) fs.FS {
+	if len(overlay) == 0 {
+		return base
+	}
+	return &sourceFS{base: base, root: root, overlay: overlay}
+}
+
+func (s *sourceFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if data, ok := s.overlay[filepath.Join(s.root, filepath.FromSlash(name))]; ok {
+		return fstest.MapFS{name: &fstest.MapFile{Data: data, Mode: 0o444}}.Open(name)
+	}
+	return s.base.Open(name)
+}
+
+// ReadFile legge un file come lo ha visto il loader: name è un path
+// assoluto, come nel FileSet. I file sotto Root sono letti da FS (overlay e
+// sorgenti di --root-archive compresi), gli altri dal disco.
+func (r *LoadResult) ReadFile(name string) ([]byte, error) {
+	return ReadFile(r.FS, r.Root, name)
+}
+
+// ReadDir legge la directory name (path assoluto) come ReadFile: da FS se
+// è sotto Root, altrimenti dal disco. I file presenti solo nell'overlay non
+// compaiono.
+func (r *LoadResult) ReadDir(name string) ([]fs.DirEntry, error) {
+	if r.FS != nil {
+		if rel, err := filepath.Rel(r.Root, name); err == nil && (rel == "." || filepath.IsLocal(rel)) {
+			return fs.ReadDir(r.FS, filepath.ToSlash(rel))
+		}
+	}
+	return os.ReadDir(name)
+}
+
+// ReadFile legge il file name (path assoluto) da fsys, i sorgenti con radice
+// in root, se vi si trova sotto, altrimenti dal disco. Con fsys nil legge
+// sempre dal disco.
+func ReadFile(fsys fs.FS, root, name string) ([]byte, error) {
+	if fsys != nil {
+		if rel, err := filepath.Rel(root, name); err == nil && filepath.IsLocal(rel) {
+			return fs.ReadFile(fsys, filepath.ToSlash(rel))
+		}
+	}
+	return os.ReadFile(name)
+}
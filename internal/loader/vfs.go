@@ -0,0 +1,342 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// LoadFS carica i package da un file system virtuale senza go/packages né
+// toolchain (es. in un browser con GOOS=js): ogni directory con file .go è
+// un package, il module path è letto da go.mod se presente. I nomi dei file
+// sono root + path in fsys, così le posizioni relative restano quelle di
+// fsys.
+//
+// Solo i package presenti in fsys sono type-checked: ogni altro import
+// (libreria standard compresa) è un package vuoto, e gli errori di tipo
+// che ne derivano non sono riportati. I build constraint non sono valutati
+// e l'SSA non è supportato: quando la toolchain è disponibile si usa Load
+// con Options.FS. Parsing e type checking sono sempre eseguiti, qualunque
+// siano NeedSyntax e NeedTypes.
+func LoadFS(fsys fs.FS, root string, opts Options) (*LoadResult, error) {
+	if opts.NeedSSA {
+		return nil, fmt.Errorf("SSA is not supported on a virtual file system")
+	}
+	loadStart := time.Now()
+
+	ex := map[string]bool{"vendor": true, "testdata": true}
+	for _, d := range opts.ExcludeDirs {
+		if d = strings.TrimSpace(d); d != "" {
+			ex[d] = true
+		}
+	}
+
+	dirs := make(map[string][]string) // directory → file .go
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && (ex[d.Name()] || strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(p, ".go") && (opts.IncludeTest || !strings.HasSuffix(p, "_test.go")) {
+			dirs[path.Dir(p)] = append(dirs[path.Dir(p)], p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no Go files found")
+	}
+
+	modPath, goVersion := readGoMod(fsys)
+	l := &fsLoader{
+		fset:      token.NewFileSet(),
+		goVersion: goVersion,
+		sizes:     types.SizesFor("gc", runtime.GOARCH),
+		byPath:    make(map[string]*packages.Package),
+		stubs:     make(map[string]*types.Package),
+		checking:  make(map[string]bool),
+	}
+	if l.sizes == nil {
+		l.sizes = types.SizesFor("gc", "amd64")
+	}
+
+	checkStart := time.Now()
+
+	// Parsing: i file di un package esterno di test (nome_test) sono
+	// scartati, come i file con un nome di package diverso dal primo
+	keys := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		keys = append(keys, dir)
+	}
+	sort.Strings(keys)
+	for _, dir := range keys {
+		pkgPath := modPath
+		if dir != "." {
+			pkgPath = path.Join(modPath, dir)
+		}
+		pkg := &packages.Package{ID: pkgPath, PkgPath: pkgPath, Fset: l.fset, TypesSizes: l.sizes}
+		sort.Strings(dirs[dir])
+		for _, p := range dirs[dir] {
+			src, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return nil, err
+			}
+			name := filepath.Join(root, filepath.FromSlash(p))
+			f, err := parser.ParseFile(l.fset, name, src, parser.ParseComments|parser.AllErrors)
+			if err != nil {
+				pkg.Errors = append(pkg.Errors, parseErrors(err)...)
+			}
+			if f == nil {
+				continue
+			}
+			switch {
+			case pkg.Name == "" && !strings.HasSuffix(f.Name.Name, "_test"):
+				pkg.Name = f.Name.Name
+			case f.Name.Name != pkg.Name:
+				continue
+			}
+			pkg.GoFiles = append(pkg.GoFiles, name)
+			pkg.Syntax = append(pkg.Syntax, f)
+		}
+		pkg.CompiledGoFiles = pkg.GoFiles
+		if len(pkg.Syntax) > 0 {
+			l.byPath[pkgPath] = pkg
+			l.pkgs = append(l.pkgs, pkg)
+		}
+	}
+
+	for _, pkg := range l.pkgs {
+		l.check(pkg)
+	}
+	checkDuration := time.Since(checkStart)
+
+	validPkgs := filterLoadedPackages(l.pkgs, opts.ExcludeDirs, opts.Packages)
+	if len(validPkgs) == 0 {
+		return nil, fmt.Errorf("no valid packages found (all had errors or were filtered)")
+	}
+	return &LoadResult{
+		Packages:          validPkgs,
+		Root:              root,
+		FS:                newSourceFS(fsys, root, opts.Overlay),
+		Fset:              l.fset,
+		Errors:            collectErrors(l.pkgs, root),
+		LoadDuration:      time.Since(loadStart),
+		TypeCheckDuration: checkDuration,
+	}, nil
+}
+
+// fsLoader esegue il type checking dei package di LoadFS in ordine di
+// dipendenza, risolvendo gli import tra package di fsys.
+type fsLoader struct {
+	fset      *token.FileSet
+	goVersion string
+	sizes     types.Sizes
+	pkgs      []*packages.Package
+	byPath    map[string]*packages.Package
+	stubs     map[string]*types.Package // import esterni, package vuoti
+	checking  map[string]bool           // guardia contro i cicli di import
+}
+
+func (l *fsLoader) Import(p string) (*types.Package, error) {
+	pkg, ok := l.byPath[p]
+	if !ok {
+		return l.stub(p), nil
+	}
+	if l.checking[p] {
+		return nil, fmt.Errorf("import cycle through %s", p)
+	}
+	l.check(pkg)
+	return pkg.Types, nil
+}
+
+// stub restituisce un package vuoto e completo per un import esterno; il
+// nome è l'ultimo elemento del path senza suffisso di versione.
+func (l *fsLoader) stub(p string) *types.Package {
+	if s, ok := l.stubs[p]; ok {
+		return s
+	}
+	name := path.Base(p)
+	if isMajorVersion(name) && path.Dir(p) != "." {
+		name = path.Base(path.Dir(p))
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexAny(name, ".-"); i > 0 {
+		name = name[:i]
+	}
+	s := types.NewPackage(p, name)
+	s.MarkComplete()
+	l.stubs[p] = s
+	return s
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+func (l *fsLoader) check(pkg *packages.Package) {
+	if pkg.Types != nil {
+		return
+	}
+	l.checking[pkg.PkgPath] = true
+	defer delete(l.checking, pkg.PkgPath)
+
+	pkg.TypesInfo = &types.Info{
+		Types:        make(map[ast.Expr]types.TypeAndValue),
+		Instances:    make(map[*ast.Ident]types.Instance),
+		Defs:         make(map[*ast.Ident]types.Object),
+		Uses:         make(map[*ast.Ident]types.Object),
+		Implicits:    make(map[ast.Node]types.Object),
+		Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:       make(map[ast.Node]*types.Scope),
+		FileVersions: make(map[*ast.File]string),
+	}
+	var typeErrs []types.Error
+	conf := types.Config{
+		Importer:  l,
+		GoVersion: l.goVersion,
+		Sizes:     l.sizes,
+		Error: func(err error) {
+			if te, ok := err.(types.Error); ok {
+				typeErrs = append(typeErrs, te)
+			}
+		},
+	}
+	pkg.Types, _ = conf.Check(pkg.PkgPath, l.fset, pkg.Syntax, pkg.TypesInfo)
+
+	pkg.Imports = make(map[string]*packages.Package)
+	for _, imp := range pkg.Types.Imports() {
+		if dep, ok := l.byPath[imp.Path()]; ok {
+			pkg.Imports[imp.Path()] = dep
+		} else {
+			pkg.Imports[imp.Path()] = &packages.Package{ID: imp.Path(), PkgPath: imp.Path(), Name: imp.Name(), Types: imp, Fset: l.fset}
+		}
+	}
+
+	for _, te := range typeErrs {
+		if l.fromStub(pkg.TypesInfo, te) {
+			continue
+		}
+		pkg.Errors = append(pkg.Errors, packages.Error{
+			Pos:  l.fset.Position(te.Pos).String(),
+			Msg:  te.Msg,
+			Kind: packages.TypeError,
+		})
+	}
+	pkg.IllTyped = len(pkg.Errors) > 0
+}
+
+// fromStub riconosce gli errori dovuti a un import esterno non risolto:
+// riferimenti a membri di un package vuoto ("undefined: fmt.Println").
+func (l *fsLoader) fromStub(info *types.Info, te types.Error) bool {
+	const prefix = "undefined: "
+	if !strings.HasPrefix(te.Msg, prefix) {
+		return false
+	}
+	name, _, ok := strings.Cut(strings.TrimPrefix(te.Msg, prefix), ".")
+	if !ok {
+		return false
+	}
+	for id, obj := range info.Uses {
+		if pn, ok := obj.(*types.PkgName); ok && id.Name == name && id.Pos() <= te.Pos && te.Pos <= id.End()+1 {
+			_, stub := l.stubs[pn.Imported().Path()]
+			return stub
+		}
+	}
+	return false
+}
+
+// parseErrors converte gli errori di go/parser in errori di package.
+func parseErrors(err error) []packages.Error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return []packages.Error{{Pos: "-", Msg: err.Error(), Kind: packages.ParseError}}
+	}
+	out := make([]packages.Error, 0, len(list))
+	for _, e := range list {
+		out = append(out, packages.Error{Pos: e.Pos.String(), Msg: e.Msg, Kind: packages.ParseError})
+	}
+	return out
+}
+
+// readGoMod legge module path e versione di Go da go.mod alla radice di
+// fsys; senza go.mod il module path è "main".
+func readGoMod(fsys fs.FS) (modPath, goVersion string) {
+	modPath = "main"
+	data, err := fs.ReadFile(fsys, "go.mod")
+	if err != nil {
+		return modPath, ""
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			modPath = strings.Trim(fields[1], `"`)
+		case "go":
+			goVersion = "go" + fields[1]
+		}
+	}
+	return modPath, goVersion
+}
+
+// mountFS converte i file di fsys rilevanti per go list (.go, go.mod,
+// go.sum) in un overlay sotto root; le voci di overlay prevalgono.
+func mountFS(fsys fs.FS, root string, overlay map[string][]byte) (map[string][]byte, error) {
+	out := make(map[string][]byte)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if name := d.Name(); !strings.HasSuffix(name, ".go") && name != "go.mod" && name != "go.sum" {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		out[filepath.Join(root, filepath.FromSlash(p))] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read source file system: %w", err)
+	}
+	for name, data := range overlay {
+		out[name] = data
+	}
+	return out, nil
+}
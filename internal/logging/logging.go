@@ -0,0 +1,98 @@
+// Package logging configura il logger strutturato (log/slog) dell'analyzer
+// e il progress reporter mostrato su stderr durante le fasi lunghe.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// ParseLevel converte un livello testuale (debug|info|warn|error) in slog.Level.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level: %s (valid: debug, info, warn, error)", s)
+	}
+}
+
+// New crea un logger che scrive su w nel formato indicato (text|json).
+func New(w io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	switch format {
+	case "", "text":
+		return slog.New(&textHandler{w: w, level: level, mu: &sync.Mutex{}}), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})), nil
+	default:
+		return nil, fmt.Errorf("invalid log format: %s (valid: text, json)", format)
+	}
+}
+
+// textHandler produce righe "[level] messaggio key=value" compatibili con
+// il formato storico dei log dell'analyzer.
+type textHandler struct {
+	w     io.Writer
+	level slog.Level
+	attrs []slog.Attr
+	mu    *sync.Mutex
+}
+
+func (h *textHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString("[")
+	b.WriteString(levelName(r.Level))
+	b.WriteString("] ")
+	b.WriteString(r.Message)
+	writeAttr := func(a slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+		return true
+	}
+	for _, a := range h.attrs {
+		writeAttr(a)
+	}
+	r.Attrs(writeAttr)
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := *h
+	nh.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &nh
+}
+
+func (h *textHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+func levelName(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return "error"
+	case l >= slog.LevelWarn:
+		return "warning"
+	case l >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
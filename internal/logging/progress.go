@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Progress riporta l'avanzamento delle fasi di analisi su stderr.
+// Su un terminale aggiorna una singola riga con una barra; altrimenti
+// scrive una riga per fase. Un Progress nil è valido e non fa nulla.
+type Progress struct {
+	w     io.Writer
+	tty   bool
+	mu    sync.Mutex
+	phase string
+	last  int // ultimo percentuale stampata (per limitare l'output non-TTY)
+}
+
+// NewProgress crea un progress reporter su w (tipicamente os.Stderr).
+func NewProgress(w io.Writer) *Progress {
+	p := &Progress{w: w}
+	if f, ok := w.(*os.File); ok {
+		if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			p.tty = true
+		}
+	}
+	return p
+}
+
+// Phase segnala l'inizio di una nuova fase.
+func (p *Progress) Phase(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finishLine()
+	p.phase = name
+	p.last = -1
+	if p.tty {
+		fmt.Fprintf(p.w, "\r%s...", name)
+	} else {
+		fmt.Fprintf(p.w, "[progress] %s...\n", name)
+	}
+}
+
+// Step aggiorna l'avanzamento della fase corrente (done su total).
+func (p *Progress) Step(done, total int) {
+	if p == nil || total <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pct := done * 100 / total
+	if p.tty {
+		const width = 30
+		filled := width * done / total
+		fmt.Fprintf(p.w, "\r%s [%s%s] %d/%d", p.phase,
+			strings.Repeat("=", filled), strings.Repeat(" ", width-filled), done, total)
+		return
+	}
+	// Fuori da un terminale stampa solo ogni 25%
+	if p.last < 0 || pct/25 > p.last/25 || done == total {
+		p.last = pct
+		fmt.Fprintf(p.w, "[progress] %s %d/%d\n", p.phase, done, total)
+	}
+}
+
+// Done chiude l'ultima fase.
+func (p *Progress) Done() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finishLine()
+	p.phase = ""
+}
+
+func (p *Progress) finishLine() {
+	if p.tty && p.phase != "" {
+		fmt.Fprintln(p.w)
+	}
+}
@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Component è un componente dichiarato con --components: i package la cui
+// directory sta sotto uno dei prefissi (relativi alla root, "." = tutti).
+type Component struct {
+	Name     string
+	Prefixes []string
+}
+
+// ParseComponents interpreta la specifica di --components: "go.work"
+// (un componente per modulo del workspace alla radice di fsys, i sorgenti
+// del progetto, con il module path come nome) oppure voci separate da
+// virgola "nome=prefisso" o "prefisso"; più voci con lo stesso nome si
+// uniscono.
+func ParseComponents(spec string, fsys fs.FS) ([]Component, error) {
+	if strings.TrimSpace(spec) == "go.work" {
+		return workComponents(fsys)
+	}
+
+	var out []Component
+	index := make(map[string]int)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, prefix, ok := strings.Cut(entry, "=")
+		if !ok {
+			prefix = name
+		}
+		name, prefix = strings.TrimSpace(name), cleanPrefix(prefix)
+		if name == "" || prefix == "" {
+			return nil, fmt.Errorf("invalid component %q (want name=dir or dir)", entry)
+		}
+		if !ok {
+			name = prefix
+		}
+		if i, seen := index[name]; seen {
+			out[i].Prefixes = append(out[i].Prefixes, prefix)
+			continue
+		}
+		index[name] = len(out)
+		out = append(out, Component{Name: name, Prefixes: []string{prefix}})
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no components in %q", spec)
+	}
+	return out, nil
+}
+
+// workComponents legge le direttive use del go.work alla radice di fsys.
+func workComponents(fsys fs.FS) ([]Component, error) {
+	const name = "go.work"
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := modfile.ParseWork(name, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []Component
+	for _, use := range wf.Use {
+		dir := cleanPrefix(use.Path)
+		modName := dir
+		if gomod, err := fs.ReadFile(fsys, path.Join(dir, "go.mod")); err == nil {
+			if mp := modfile.ModulePath(gomod); mp != "" {
+				modName = mp
+			}
+		}
+		out = append(out, Component{Name: modName, Prefixes: []string{dir}})
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s has no use directives", name)
+	}
+	return out, nil
+}
+
+// cleanPrefix normalizza un prefisso di directory relativo alla root.
+func cleanPrefix(p string) string {
+	p = strings.TrimSpace(filepath.ToSlash(p))
+	if p == "" {
+		return ""
+	}
+	return path.Clean(strings.TrimPrefix(p, "/"))
+}
+
+// ComputeComponents raggruppa i package nei componenti e aggrega gli archi
+// tra componenti diversi. pkgDirs mappa i package del progetto sulla loro
+// directory relativa alla root, imports è il grafo degli import (package →
+// package importati); cg può essere nil. Un package appartiene al
+// componente con il prefisso più lungo che lo contiene.
+func ComputeComponents(comps []Component, pkgDirs map[string]string, imports map[string][]string, cg *schema.CLDKCallGraph) *schema.CLDKComponentReport {
+	owner := make(map[string]string, len(pkgDirs))
+	byName := make(map[string]*schema.CLDKComponent, len(comps))
+	report := &schema.CLDKComponentReport{
+		Components:   make([]schema.CLDKComponent, 0, len(comps)),
+		Dependencies: []schema.CLDKComponentDependency{},
+	}
+	for _, c := range comps {
+		byName[c.Name] = &schema.CLDKComponent{Name: c.Name, Prefixes: c.Prefixes, Packages: []string{}}
+	}
+
+	for pkgPath, dir := range pkgDirs {
+		best, bestLen := "", -1
+		for _, c := range comps {
+			for _, p := range c.Prefixes {
+				if (p == "." || dir == p || strings.HasPrefix(dir, p+"/")) && len(p) > bestLen {
+					best, bestLen = c.Name, len(p)
+				}
+			}
+		}
+		if best == "" {
+			report.Unassigned = append(report.Unassigned, pkgPath)
+			continue
+		}
+		owner[pkgPath] = best
+		byName[best].Packages = append(byName[best].Packages, pkgPath)
+	}
+	sort.Strings(report.Unassigned)
+
+	type pair struct{ from, to string }
+	deps := make(map[pair]*schema.CLDKComponentDependency)
+	dep := func(from, to string) *schema.CLDKComponentDependency {
+		k := pair{from, to}
+		if deps[k] == nil {
+			deps[k] = &schema.CLDKComponentDependency{From: from, To: to}
+		}
+		return deps[k]
+	}
+
+	for from, tos := range imports {
+		cf, ok := owner[from]
+		if !ok {
+			continue
+		}
+		for _, to := range tos {
+			if ct, ok := owner[to]; ok && ct != cf {
+				dep(cf, ct).Imports++
+			}
+		}
+	}
+
+	if cg != nil {
+		nodeComp := make(map[string]string, len(cg.Nodes))
+		for _, n := range cg.Nodes {
+			if c, ok := owner[n.Package]; ok {
+				nodeComp[n.ID] = c
+				byName[c].Callables++
+			}
+		}
+		for _, e := range cg.Edges {
+			cf, okf := nodeComp[e.Source]
+			ct, okt := nodeComp[e.Target]
+			if okf && okt && cf != ct {
+				dep(cf, ct).Calls++
+			}
+		}
+	}
+
+	for _, d := range deps {
+		byName[d.From].DependsOn++
+		byName[d.To].UsedBy++
+		report.Dependencies = append(report.Dependencies, *d)
+	}
+	sort.Slice(report.Dependencies, func(i, j int) bool {
+		a, b := report.Dependencies[i], report.Dependencies[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+
+	for _, c := range byName {
+		sort.Strings(c.Packages)
+		report.Components = append(report.Components, *c)
+	}
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Name < report.Components[j].Name
+	})
+	return report
+}
@@ -0,0 +1,79 @@
+// Package metrics calcola metriche architetturali (coupling, instabilità,
+// astrattezza) a partire dalla symbol table CLDK e la vista per componenti
+// dei package.
+package metrics
+
+import (
+	"math"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// ComputeCoupling calcola Ca, Ce, instabilità e astrattezza per ogni package.
+// Il coupling considera solo i package del progetto presenti nella symbol table;
+// gli import esterni sono conteggiati a parte in ExternalImports.
+func ComputeCoupling(st *schema.CLDKSymbolTable) *schema.CLDKMetrics {
+	if st == nil {
+		return nil
+	}
+
+	out := &schema.CLDKMetrics{
+		Packages: make(map[string]*schema.PackageMetrics, len(st.Packages)),
+	}
+
+	// Ce ed Ca dal grafo degli import interni al progetto
+	efferent := make(map[string]map[string]bool)
+	afferent := make(map[string]map[string]bool)
+	external := make(map[string]int)
+	for pkgPath, pkg := range st.Packages {
+		for _, imp := range pkg.Imports {
+			if imp.Path == pkgPath {
+				continue
+			}
+			if _, internal := st.Packages[imp.Path]; !internal {
+				external[pkgPath]++
+				continue
+			}
+			if efferent[pkgPath] == nil {
+				efferent[pkgPath] = make(map[string]bool)
+			}
+			efferent[pkgPath][imp.Path] = true
+			if afferent[imp.Path] == nil {
+				afferent[imp.Path] = make(map[string]bool)
+			}
+			afferent[imp.Path][pkgPath] = true
+		}
+	}
+
+	for pkgPath, pkg := range st.Packages {
+		m := &schema.PackageMetrics{
+			AfferentCoupling: len(afferent[pkgPath]),
+			EfferentCoupling: len(efferent[pkgPath]),
+			ExternalImports:  external[pkgPath],
+			TypeCount:        len(pkg.TypeDeclarations),
+		}
+
+		for _, td := range pkg.TypeDeclarations {
+			if td.Kind == "interface" {
+				m.InterfaceCount++
+			}
+		}
+
+		if total := m.AfferentCoupling + m.EfferentCoupling; total > 0 {
+			m.Instability = round2(float64(m.EfferentCoupling) / float64(total))
+		}
+		if m.TypeCount > 0 {
+			m.Abstractness = round2(float64(m.InterfaceCount) / float64(m.TypeCount))
+		}
+		m.Distance = round2(math.Abs(m.Abstractness + m.Instability - 1))
+
+		out.Packages[pkgPath] = m
+	}
+
+	return out
+}
+
+// round2 arrotonda a due cifre decimali.
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
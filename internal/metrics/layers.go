@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"sort"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/graph"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// InferLayers stratifica i package della symbol table in base agli import
+// interni al progetto e assegna a ogni package il suo Layer. Senza cicli il
+// layer di un package è la lunghezza del cammino più lungo verso un package
+// che non importa nulla del progetto (layer 0). Nei cicli di import
+// (componenti fortemente connesse) gli archi all'indietro sono scelti con
+// l'euristica di Eades, Lin e Smyth per il feedback arc set, così da
+// escluderne pochi; sono le violazioni della stratificazione e i
+// BackEdges del package importatore.
+func InferLayers(st *schema.CLDKSymbolTable) *schema.CLDKLayers {
+	if st == nil {
+		return nil
+	}
+
+	nodes := make([]string, 0, len(st.Packages))
+	for path := range st.Packages {
+		nodes = append(nodes, path)
+	}
+	sort.Strings(nodes)
+	adj := make(map[string][]string, len(nodes))
+	for _, from := range nodes {
+		seen := make(map[string]bool)
+		for _, imp := range st.Packages[from].Imports {
+			if _, internal := st.Packages[imp.Path]; !internal || imp.Path == from || seen[imp.Path] {
+				continue
+			}
+			seen[imp.Path] = true
+			adj[from] = append(adj[from], imp.Path)
+		}
+		sort.Strings(adj[from])
+	}
+
+	// archi all'indietro, solo dentro le componenti con più di un package
+	back := make(map[[2]string]bool)
+	for _, comp := range graph.SCC(nodes, adj) {
+		if len(comp) < 2 {
+			continue
+		}
+		pos := make(map[string]int, len(comp))
+		for i, n := range feedbackOrder(comp, adj) {
+			pos[n] = i
+		}
+		for _, from := range comp {
+			for _, to := range adj[from] {
+				if p, ok := pos[to]; ok && p < pos[from] {
+					back[[2]string{from, to}] = true
+				}
+			}
+		}
+	}
+
+	// layer = cammino più lungo verso il basso, senza gli archi all'indietro
+	layer := make(map[string]int, len(nodes))
+	var visit func(n string) int
+	visit = func(n string) int {
+		if l, ok := layer[n]; ok {
+			return l
+		}
+		layer[n] = 0 // il grafo senza archi all'indietro è aciclico
+		l := 0
+		for _, to := range adj[n] {
+			if !back[[2]string{n, to}] {
+				l = max(l, visit(to)+1)
+			}
+		}
+		layer[n] = l
+		return l
+	}
+
+	out := &schema.CLDKLayers{Layers: []schema.CLDKLayer{}, Violations: []schema.CLDKLayerViolation{}}
+	for _, n := range nodes {
+		l := visit(n)
+		for len(out.Layers) <= l {
+			out.Layers = append(out.Layers, schema.CLDKLayer{Index: len(out.Layers)})
+		}
+		out.Layers[l].Packages = append(out.Layers[l].Packages, n)
+	}
+	for _, from := range nodes {
+		pl := &schema.CLDKPackageLayer{Index: layer[from]}
+		for _, to := range adj[from] {
+			if back[[2]string{from, to}] {
+				pl.BackEdges = append(pl.BackEdges, to)
+				out.Violations = append(out.Violations, schema.CLDKLayerViolation{
+					From: from, To: to, FromLayer: layer[from], ToLayer: layer[to],
+				})
+			}
+		}
+		st.Packages[from].Layer = pl
+	}
+	return out
+}
+
+// feedbackOrder ordina i nodi di una componente fortemente connessa con
+// l'euristica di Eades, Lin e Smyth: i pozzi vanno in fondo, le sorgenti in
+// testa e, quando non ce ne sono, in testa va il nodo con la massima
+// differenza tra archi uscenti ed entranti (a parità, il primo per nome).
+// Gli archi verso un nodo precedente nell'ordine sono quelli da escludere.
+func feedbackOrder(comp []string, adj map[string][]string) []string {
+	in := make(map[string]int, len(comp))
+	out := make(map[string]int, len(comp))
+	preds := make(map[string][]string, len(comp))
+	left := make(map[string]bool, len(comp))
+	for _, n := range comp {
+		left[n] = true
+	}
+	for _, from := range comp {
+		for _, to := range adj[from] {
+			if left[to] {
+				out[from]++
+				in[to]++
+				preds[to] = append(preds[to], from)
+			}
+		}
+	}
+	remove := func(n string) {
+		delete(left, n)
+		for _, to := range adj[n] {
+			if left[to] {
+				in[to]--
+			}
+		}
+		for _, from := range preds[n] {
+			if left[from] {
+				out[from]--
+			}
+		}
+	}
+
+	var head, tail []string
+	for len(left) > 0 {
+		progress := true
+		for progress {
+			progress = false
+			for _, n := range comp {
+				if left[n] && out[n] == 0 {
+					tail = append(tail, n)
+					remove(n)
+					progress = true
+				}
+			}
+			for _, n := range comp {
+				if left[n] && in[n] == 0 {
+					head = append(head, n)
+					remove(n)
+					progress = true
+				}
+			}
+		}
+		if len(left) == 0 {
+			break
+		}
+		best := ""
+		for _, n := range comp {
+			if left[n] && (best == "" || out[n]-in[n] > out[best]-in[best]) {
+				best = n
+			}
+		}
+		head = append(head, best)
+		remove(best)
+	}
+	for i := len(tail) - 1; i >= 0; i-- {
+		head = append(head, tail[i])
+	}
+	return head
+}
@@ -0,0 +1,191 @@
+// Package modproxy scarica gli zip dei moduli da un module proxy nel
+// formato GOPROXY (https://go.dev/ref/mod#goproxy-protocol), per analizzare
+// corpus di moduli senza checkout.
+package modproxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// DefaultProxy è il proxy usato quando GOPROXY non è impostato.
+const DefaultProxy = "https://proxy.golang.org"
+
+// Module identifica una versione di un modulo; Version "latest" è risolta
+// con Client.Resolve.
+type Module struct {
+	Path    string
+	Version string
+}
+
+func (m Module) String() string { return m.Path + "@" + m.Version }
+
+// ParseList legge un modulo per riga nella forma path@version (senza
+// versione vale latest). Righe vuote e commenti (#) sono ignorati.
+func ParseList(r io.Reader) ([]Module, error) {
+	var mods []Module
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		if text == "" {
+			continue
+		}
+		path, version, ok := strings.Cut(text, "@")
+		if !ok || version == "" {
+			version = "latest"
+		}
+		if err := module.CheckPath(path); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		mods = append(mods, Module{Path: path, Version: version})
+	}
+	return mods, sc.Err()
+}
+
+// errNotFound segnala un 404/410: con "," nella lista GOPROXY si passa al
+// proxy successivo solo in questo caso.
+var errNotFound = errors.New("not found")
+
+// proxy è una voce della lista GOPROXY; fallback indica se dopo un errore
+// qualsiasi (separatore "|") si passa alla voce successiva.
+type proxy struct {
+	url      string
+	fallback bool
+}
+
+// Client scarica moduli da una lista di proxy.
+type Client struct {
+	proxies []proxy
+	http    *http.Client
+}
+
+// NewClient interpreta una lista in formato GOPROXY (vuota = DefaultProxy).
+// Sono supportati URL http(s) e file://; "direct" è ignorato (nessun
+// accesso ai VCS), "off" disabilita lo scaricamento.
+func NewClient(goproxy string) (*Client, error) {
+	if strings.TrimSpace(goproxy) == "" {
+		goproxy = DefaultProxy
+	}
+	c := &Client{http: &http.Client{Timeout: 5 * time.Minute}}
+	for goproxy != "" {
+		entry := goproxy
+		fallback := false
+		if i := strings.IndexAny(goproxy, ",|"); i >= 0 {
+			entry, fallback, goproxy = goproxy[:i], goproxy[i] == '|', goproxy[i+1:]
+		} else {
+			goproxy = ""
+		}
+		switch entry = strings.TrimSpace(entry); entry {
+		case "", "direct":
+			continue
+		case "off":
+			return nil, fmt.Errorf("module downloads disabled by GOPROXY=off")
+		}
+		u, err := url.Parse(entry)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file") {
+			return nil, fmt.Errorf("invalid proxy URL: %s", entry)
+		}
+		c.proxies = append(c.proxies, proxy{url: strings.TrimSuffix(entry, "/"), fallback: fallback})
+	}
+	if len(c.proxies) == 0 {
+		return nil, fmt.Errorf("no usable module proxy (only direct/off entries)")
+	}
+	return c, nil
+}
+
+// Resolve risolve "latest" nella versione corrente tramite /@latest.
+func (c *Client) Resolve(ctx context.Context, m Module) (Module, error) {
+	if m.Version != "latest" {
+		return m, nil
+	}
+	var info struct{ Version string }
+	err := c.fetch(ctx, m.Path, "@latest", func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&info)
+	})
+	if err != nil {
+		return m, fmt.Errorf("resolve %s: %w", m, err)
+	}
+	m.Version = info.Version
+	return m, nil
+}
+
+// Download scrive in w lo zip del modulo.
+func (c *Client) Download(ctx context.Context, m Module, w io.Writer) error {
+	ev, err := module.EscapeVersion(m.Version)
+	if err != nil {
+		return err
+	}
+	err = c.fetch(ctx, m.Path, "@v/"+ev+".zip", func(r io.Reader) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("download %s: %w", m, err)
+	}
+	return nil
+}
+
+// fetch prova i proxy in ordine secondo le regole di fallback di GOPROXY.
+func (c *Client) fetch(ctx context.Context, modPath, suffix string, read func(io.Reader) error) error {
+	ep, err := module.EscapePath(modPath)
+	if err != nil {
+		return err
+	}
+	for _, p := range c.proxies {
+		err = c.get(ctx, p.url+"/"+ep+"/"+suffix, read)
+		if err == nil || (!p.fallback && !errors.Is(err, errNotFound)) {
+			return err
+		}
+	}
+	return err
+}
+
+func (c *Client) get(ctx context.Context, rawURL string, read func(io.Reader) error) error {
+	if strings.HasPrefix(rawURL, "file://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(filepath.FromSlash(u.Path))
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%s: %w", rawURL, errNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return read(f)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return fmt.Errorf("%s: %s: %w", rawURL, resp.Status, errNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s: %s", rawURL, resp.Status)
+	}
+	return read(resp.Body)
+}
@@ -0,0 +1,187 @@
+package openapi
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Document converte gli endpoint di analysis.HTTPAPI in un documento
+// OpenAPI 3.0 con titolo il module path del progetto. Le registrazioni
+// senza metodo sono documentate come post se leggono un corpo, altrimenti
+// come get, con x-any-method. Summary viene dalla documentazione
+// dell'handler nella symbol table. fsys sono i sorgenti del progetto, da
+// cui si legge il go.mod.
+func Document(analysis *schema.CLDKAnalysis, fsys fs.FS) *schema.OpenAPIDocument {
+	doc := &schema.OpenAPIDocument{
+		OpenAPI: schema.OpenAPIVersion,
+		Info:    schema.OpenAPIInfo{Title: title(fsys, analysis.Metadata.ProjectPath), Version: "0.0.0"},
+		Paths:   make(map[string]map[string]*schema.OpenAPIOperation),
+	}
+	api := analysis.HTTPAPI
+	if api == nil {
+		return doc
+	}
+	if len(api.Schemas) > 0 {
+		doc.Components = &schema.OpenAPIComponents{Schemas: api.Schemas}
+	}
+
+	opIDs := make(map[string]bool)
+	for _, ep := range api.Endpoints {
+		method := strings.ToLower(ep.Method)
+		anyMethod := method == ""
+		if anyMethod {
+			method = "get"
+			if ep.RequestBody != nil {
+				method = "post"
+			}
+		}
+		item := doc.Paths[ep.Path]
+		if item == nil {
+			item = make(map[string]*schema.OpenAPIOperation)
+			doc.Paths[ep.Path] = item
+		}
+		if item[method] != nil {
+			continue // registrata più volte: vale la prima
+		}
+
+		op := &schema.OpenAPIOperation{
+			OperationID: operationID(ep, method, opIDs),
+			Summary:     summary(analysis.SymbolTable, ep.Handler),
+			Responses:   make(map[string]*schema.OpenAPIResponse),
+			XHandler:    ep.Handler,
+			XAnyMethod:  anyMethod,
+		}
+		if ep.Handler != "" {
+			op.Tags = []string{path.Base(handlerPackage(analysis.SymbolTable, ep.Handler))}
+		}
+		if ep.Position != nil {
+			op.XSource = fmt.Sprintf("%s:%d", ep.Position.File, ep.Position.StartLine)
+		}
+		for _, p := range ep.Parameters {
+			op.Parameters = append(op.Parameters, schema.OpenAPIParameter(p))
+		}
+		if b := ep.RequestBody; b != nil {
+			op.RequestBody = &schema.OpenAPIRequestBody{
+				Required: true,
+				Content:  map[string]schema.OpenAPIMediaType{b.ContentType: {Schema: b.Schema}},
+			}
+		}
+		for _, r := range ep.Responses {
+			key := "default"
+			if r.Status != 0 {
+				key = strconv.Itoa(r.Status)
+			}
+			resp := op.Responses[key]
+			if resp == nil {
+				resp = &schema.OpenAPIResponse{Description: description(r.Status)}
+				op.Responses[key] = resp
+			}
+			if r.ContentType != "" {
+				if resp.Content == nil {
+					resp.Content = make(map[string]schema.OpenAPIMediaType)
+				}
+				if _, ok := resp.Content[r.ContentType]; !ok {
+					resp.Content[r.ContentType] = schema.OpenAPIMediaType{Schema: r.Schema}
+				}
+			}
+		}
+		if len(op.Responses) == 0 {
+			op.Responses["default"] = &schema.OpenAPIResponse{Description: "Not reconstructed"}
+		}
+		item[method] = op
+	}
+	return doc
+}
+
+// title restituisce il module path nel go.mod alla radice di fsys o, senza
+// go.mod, il nome della directory root.
+func title(fsys fs.FS, root string) string {
+	if data, err := fs.ReadFile(fsys, "go.mod"); err == nil {
+		if mp := modfile.ModulePath(data); mp != "" {
+			return mp
+		}
+	}
+	return filepath.Base(root)
+}
+
+// operationID restituisce un operationId univoco: il nome dell'handler
+// senza package (es. "UserHandler.List") o, per le funzioni letterali,
+// metodo e path (es. "get_users_id").
+func operationID(ep schema.CLDKHTTPEndpoint, method string, used map[string]bool) string {
+	id := ""
+	if ep.Handler != "" {
+		id = ep.Handler[strings.LastIndex(ep.Handler, "/")+1:]
+		if _, rest, ok := strings.Cut(id, "."); ok {
+			id = rest
+		}
+		id = strings.NewReplacer("(*", "", ")", "").Replace(id)
+	} else {
+		words := strings.FieldsFunc(ep.Path, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		})
+		id = strings.Join(append([]string{method}, words...), "_")
+	}
+	base := id
+	if used[id] {
+		base = id + "_" + method
+		id = base
+	}
+	for i := 2; used[id]; i++ {
+		id = base + "_" + strconv.Itoa(i)
+	}
+	used[id] = true
+	return id
+}
+
+// handlerPackage restituisce il package dell'handler id nella symbol
+// table, o il prefisso dell'ID se l'handler non vi compare.
+func handlerPackage(st *schema.CLDKSymbolTable, id string) string {
+	if st != nil {
+		for p, pkg := range st.Packages {
+			if pkg.CallableDeclarations[id] != nil {
+				return p
+			}
+		}
+	}
+	slash := strings.LastIndex(id, "/")
+	if dot := strings.Index(id[slash+1:], "."); dot >= 0 {
+		return id[:slash+1+dot]
+	}
+	return id
+}
+
+// summary restituisce la prima frase della documentazione dell'handler.
+func summary(st *schema.CLDKSymbolTable, id string) string {
+	if st == nil || id == "" {
+		return ""
+	}
+	pkg := st.Packages[handlerPackage(st, id)]
+	if pkg == nil || pkg.CallableDeclarations[id] == nil {
+		return ""
+	}
+	doc := strings.TrimSpace(pkg.CallableDeclarations[id].Documentation)
+	if i := strings.Index(doc, "\n\n"); i >= 0 {
+		doc = doc[:i]
+	}
+	doc = strings.Join(strings.Fields(doc), " ")
+	if i := strings.Index(doc, ". "); i >= 0 {
+		doc = doc[:i+1]
+	}
+	return doc
+}
+
+func description(status int) string {
+	if text := http.StatusText(status); text != "" {
+		return text
+	}
+	return "Response"
+}
@@ -0,0 +1,525 @@
+// Package openapi ricostruisce gli endpoint HTTP registrati con net/http e
+// gin: per ogni registrazione risolve l'handler e ne legge parametri,
+// corpo della richiesta e risposte dalle chiamate tipate nel corpo (es.
+// json.NewDecoder(r.Body).Decode(&req), c.ShouldBindJSON(&req), c.JSON(200,
+// resp)). Document converte il risultato in uno scheletro OpenAPI 3.0.
+// L'analisi è sintattica e non segue gli helper chiamati dagli handler.
+package openapi
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Import path dei framework riconosciuti.
+const (
+	pathHTTP = "net/http"
+	pathGin  = "github.com/gin-gonic/gin"
+)
+
+// Valori di CLDKHTTPEndpoint.Framework.
+const (
+	FrameworkHTTP = "net/http"
+	FrameworkGin  = "gin"
+)
+
+// ginRoutes mappa i metodi di registrazione di gin.RouterGroup al metodo
+// HTTP ("" per Any, che li accetta tutti; Handle lo riceve come argomento).
+var ginRoutes = map[string]string{
+	"GET":     "GET",
+	"POST":    "POST",
+	"PUT":     "PUT",
+	"PATCH":   "PATCH",
+	"DELETE":  "DELETE",
+	"HEAD":    "HEAD",
+	"OPTIONS": "OPTIONS",
+	"Any":     "",
+	"Handle":  "",
+}
+
+type funcDecl struct {
+	decl *ast.FuncDecl
+	info *types.Info
+}
+
+type extractor struct {
+	fset       *token.FileSet
+	root       string
+	decls      map[*types.Func]funcDecl
+	prefixes   map[types.Object]string // gruppi gin → prefisso del path
+	seen       map[token.Position]bool // le varianti di test ripetono i file
+	handlers   map[*ast.BlockStmt]*handlerFacts
+	schemas    *schemaBuilder
+	frameworks map[string]bool
+	endpoints  []schema.CLDKHTTPEndpoint
+}
+
+// Extract restituisce gli endpoint registrati nei package, nil se il
+// progetto non registra handler con net/http o gin.
+func Extract(pkgs []*packages.Package, fset *token.FileSet, root string) *schema.CLDKHTTPAPI {
+	x := &extractor{
+		fset:       fset,
+		root:       root,
+		decls:      make(map[*types.Func]funcDecl),
+		prefixes:   make(map[types.Object]string),
+		seen:       make(map[token.Position]bool),
+		handlers:   make(map[*ast.BlockStmt]*handlerFacts),
+		schemas:    newSchemaBuilder(),
+		frameworks: make(map[string]bool),
+	}
+	var web []*packages.Package
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		x.index(pkg)
+		if pkg.Imports[pathHTTP] != nil || pkg.Imports[pathGin] != nil {
+			web = append(web, pkg)
+		}
+	}
+
+	// I prefissi dei gruppi passano per assegnamenti e argomenti di
+	// funzioni del progetto: si propagano fino a un punto fisso
+	for i := 0; i < 8 && x.collectPrefixes(web); i++ {
+	}
+	for _, pkg := range web {
+		x.forEachFile(pkg, func(file *ast.File) {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					x.registration(call, pkg.TypesInfo)
+				}
+				return true
+			})
+		})
+	}
+	if len(x.endpoints) == 0 {
+		return nil
+	}
+
+	api := &schema.CLDKHTTPAPI{Endpoints: x.endpoints}
+	for fw := range x.frameworks {
+		api.Frameworks = append(api.Frameworks, fw)
+	}
+	sort.Strings(api.Frameworks)
+	sort.SliceStable(api.Endpoints, func(i, j int) bool {
+		a, b := api.Endpoints[i], api.Endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	if len(x.schemas.schemas) > 0 {
+		api.Schemas = x.schemas.schemas
+	}
+	return api
+}
+
+func (x *extractor) forEachFile(pkg *packages.Package, fn func(*ast.File)) {
+	for _, file := range pkg.Syntax {
+		if file != nil && loader.UnderRoot(x.fset.Position(file.Pos()).Filename, x.root) {
+			fn(file)
+		}
+	}
+}
+
+// index registra le dichiarazioni di funzione del package, per risolvere
+// gli handler e i parametri che ricevono i gruppi.
+func (x *extractor) index(pkg *packages.Package) {
+	x.forEachFile(pkg, func(file *ast.File) {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			if fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func); ok {
+				x.decls[fn] = funcDecl{decl: fd, info: pkg.TypesInfo}
+			}
+		}
+	})
+}
+
+// collectPrefixes assegna un prefisso alle variabili inizializzate con
+// Group e ai parametri che ricevono un gruppo; restituisce true se ne ha
+// aggiunti o cambiati.
+func (x *extractor) collectPrefixes(pkgs []*packages.Package) bool {
+	changed := false
+	set := func(obj types.Object, prefix string) {
+		if obj == nil {
+			return
+		}
+		if old, ok := x.prefixes[obj]; !ok || old != prefix {
+			x.prefixes[obj] = prefix
+			changed = true
+		}
+	}
+	for _, pkg := range pkgs {
+		info := pkg.TypesInfo
+		x.forEachFile(pkg, func(file *ast.File) {
+			ast.Inspect(file, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.AssignStmt:
+					if len(n.Lhs) != len(n.Rhs) {
+						return true
+					}
+					for i, rhs := range n.Rhs {
+						if id, ok := n.Lhs[i].(*ast.Ident); ok {
+							if prefix, ok := x.groupPrefix(rhs, info); ok {
+								set(info.ObjectOf(id), prefix)
+							}
+						}
+					}
+				case *ast.ValueSpec:
+					if len(n.Names) != len(n.Values) {
+						return true
+					}
+					for i, v := range n.Values {
+						if prefix, ok := x.groupPrefix(v, info); ok {
+							set(info.ObjectOf(n.Names[i]), prefix)
+						}
+					}
+				case *ast.CallExpr:
+					fn, _ := typeutil.Callee(info, n).(*types.Func)
+					fd, ok := x.decls[fn]
+					if !ok {
+						return true
+					}
+					params := paramObjects(fd)
+					for i, arg := range n.Args {
+						if i < len(params) {
+							if prefix, ok := x.groupPrefix(arg, info); ok {
+								set(params[i], prefix)
+							}
+						}
+					}
+				}
+				return true
+			})
+		})
+	}
+	return changed
+}
+
+// paramObjects restituisce gli oggetti dei parametri di fd in ordine.
+func paramObjects(fd funcDecl) []types.Object {
+	var out []types.Object
+	for _, field := range fd.decl.Type.Params.List {
+		if len(field.Names) == 0 {
+			out = append(out, nil)
+		}
+		for _, name := range field.Names {
+			out = append(out, fd.info.Defs[name])
+		}
+	}
+	return out
+}
+
+// groupPrefix restituisce il prefisso del gruppo gin denotato da e: una
+// variabile o un parametro con prefisso noto, o una chiamata Group (il cui
+// receiver senza prefisso noto vale "").
+func (x *extractor) groupPrefix(e ast.Expr, info *types.Info) (string, bool) {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		prefix, ok := x.prefixes[info.ObjectOf(e)]
+		return prefix, ok
+	case *ast.CallExpr:
+		fn, _ := typeutil.Callee(info, e).(*types.Func)
+		if !isGinRouter(fn) || fn.Name() != "Group" || len(e.Args) == 0 {
+			return "", false
+		}
+		sel, ok := ast.Unparen(e.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return "", false
+		}
+		base, _ := x.groupPrefix(sel.X, info)
+		rel, ok := constString(e.Args[0], info)
+		if !ok {
+			return "", false
+		}
+		return joinPath(base, rel), true
+	}
+	return "", false
+}
+
+// isGinRouter riporta se fn è un metodo di gin.RouterGroup (anche tramite
+// gin.Engine, che lo incorpora).
+func isGinRouter(fn *types.Func) bool {
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != pathGin {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	return recv != nil && namedName(recv.Type()) == "RouterGroup"
+}
+
+// registration aggiunge gli endpoint registrati da call, se è una
+// registrazione di net/http o gin.
+func (x *extractor) registration(call *ast.CallExpr, info *types.Info) {
+	fn, _ := typeutil.Callee(info, call).(*types.Func)
+	if fn == nil || fn.Pkg() == nil {
+		return
+	}
+
+	var (
+		framework, method, pattern, path string
+		handler                          ast.Expr
+	)
+	switch {
+	case fn.Pkg().Path() == pathHTTP && (fn.Name() == "HandleFunc" || fn.Name() == "Handle"):
+		recv := fn.Type().(*types.Signature).Recv()
+		if recv != nil && namedName(recv.Type()) != "ServeMux" || len(call.Args) != 2 {
+			return
+		}
+		p, ok := constString(call.Args[0], info)
+		if !ok {
+			return
+		}
+		framework, pattern, handler = FrameworkHTTP, p, call.Args[1]
+		method, path = splitPattern(p)
+
+	case isGinRouter(fn):
+		m, ok := ginRoutes[fn.Name()]
+		if !ok {
+			return
+		}
+		args := call.Args
+		if fn.Name() == "Handle" {
+			if len(args) == 0 {
+				return
+			}
+			if m, ok = constString(args[0], info); !ok {
+				return
+			}
+			args = args[1:]
+		}
+		if len(args) < 2 {
+			return
+		}
+		p, ok := constString(args[0], info)
+		if !ok {
+			return
+		}
+		prefix := ""
+		if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+			prefix, _ = x.groupPrefix(sel.X, info)
+		}
+		// L'handler è l'ultimo della catena, gli altri sono middleware
+		framework, method, pattern = FrameworkGin, strings.ToUpper(m), joinPath(prefix, p)
+		path = ginPath(pattern)
+		if !call.Ellipsis.IsValid() {
+			handler = args[len(args)-1]
+		}
+
+	default:
+		return
+	}
+
+	pos := x.fset.Position(call.Pos())
+	if x.seen[pos] {
+		return
+	}
+	x.seen[pos] = true
+	x.frameworks[framework] = true
+
+	id, facts := x.handler(handler, info)
+	methods := []string{method}
+	if method == "" && len(facts.methods) > 0 {
+		methods = facts.methods
+	}
+	for _, m := range methods {
+		facts := facts.forMethod(m)
+		ep := schema.CLDKHTTPEndpoint{
+			Method:    m,
+			Path:      path,
+			Pattern:   pattern,
+			Framework: framework,
+			Handler:   id,
+			Position:  loader.Position(pos, x.root),
+		}
+		ep.Parameters = pathParameters(path, facts.params)
+		ep.RequestBody = facts.body
+		ep.Responses = facts.responses
+		x.endpoints = append(x.endpoints, ep)
+	}
+}
+
+// handler risolve l'espressione dell'handler e ne analizza il corpo;
+// l'ID è vuoto per le funzioni letterali e gli handler non risolti.
+func (x *extractor) handler(e ast.Expr, info *types.Info) (string, *handlerFacts) {
+	if e == nil {
+		return "", &handlerFacts{}
+	}
+	switch h := ast.Unparen(e).(type) {
+	case *ast.FuncLit:
+		return "", x.analyze(h.Body, info)
+	case *ast.CallExpr:
+		// Conversione, es. http.HandlerFunc(f)
+		if tv, ok := info.Types[h.Fun]; ok && tv.IsType() && len(h.Args) == 1 {
+			return x.handler(h.Args[0], info)
+		}
+		return "", &handlerFacts{}
+	}
+
+	var fn *types.Func
+	switch h := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		fn, _ = info.Uses[h].(*types.Func)
+	case *ast.SelectorExpr:
+		fn, _ = info.Uses[h.Sel].(*types.Func)
+	}
+	if fn == nil {
+		// Un valore che implementa http.Handler
+		if t := info.TypeOf(e); t != nil {
+			obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "ServeHTTP")
+			fn, _ = obj.(*types.Func)
+		}
+	}
+	if fn == nil {
+		return "", &handlerFacts{}
+	}
+	fd, ok := x.decls[fn.Origin()]
+	if !ok {
+		return ids.Object(fn), &handlerFacts{}
+	}
+	return ids.Object(fn), x.analyze(fd.decl.Body, fd.info)
+}
+
+// pathParameters restituisce i parametri dell'handler con, in testa, i
+// parametri del path non letti dall'handler (obbligatori per OpenAPI).
+func pathParameters(path string, params []schema.CLDKHTTPParameter) []schema.CLDKHTTPParameter {
+	var out []schema.CLDKHTTPParameter
+	for _, name := range templateNames(path) {
+		found := false
+		for _, p := range params {
+			if p.In == "path" && p.Name == name {
+				found = true
+			}
+		}
+		if !found {
+			out = append(out, schema.CLDKHTTPParameter{Name: name, In: "path", Required: true, Schema: &schema.OpenAPISchema{Type: "string"}})
+		}
+	}
+	for _, p := range params {
+		// Un parametro del path letto dall'handler ma assente dal
+		// template (es. gruppo con prefisso non risolto) resta com'è
+		out = append(out, p)
+	}
+	return out
+}
+
+// templateNames restituisce i nomi dei parametri "{nome}" di path.
+func templateNames(path string) []string {
+	var names []string
+	for {
+		i := strings.IndexByte(path, '{')
+		if i < 0 {
+			return names
+		}
+		j := strings.IndexByte(path[i:], '}')
+		if j < 0 {
+			return names
+		}
+		names = append(names, path[i+1:i+j])
+		path = path[i+j+1:]
+	}
+}
+
+// splitPattern separa metodo e path di un pattern di http.ServeMux
+// ("GET example.com/items/{id...}") e porta il path in forma OpenAPI.
+func splitPattern(p string) (string, string) {
+	method := ""
+	if i := strings.IndexAny(p, " \t"); i >= 0 {
+		method, p = strings.ToUpper(p[:i]), strings.TrimLeft(p[i:], " \t")
+	}
+	if i := strings.IndexByte(p, '/'); i > 0 {
+		p = p[i:] // host
+	}
+	p = strings.ReplaceAll(p, "{$}", "")
+	p = strings.ReplaceAll(p, "...}", "}")
+	if p == "" {
+		p = "/"
+	}
+	return method, p
+}
+
+// ginPath converte i parametri ":id" e "*path" di gin in "{id}" e "{path}".
+func ginPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if len(part) > 1 && (part[0] == ':' || part[0] == '*') {
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func joinPath(prefix, p string) string {
+	if prefix == "" {
+		return p
+	}
+	if p == "" || p == "/" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(p, "/")
+}
+
+// constString restituisce il valore di e se è una costante stringa.
+func constString(e ast.Expr, info *types.Info) (string, bool) {
+	tv, ok := info.Types[e]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// constInt restituisce il valore di e se è una costante intera.
+func constInt(e ast.Expr, info *types.Info) int {
+	tv, ok := info.Types[e]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return 0
+	}
+	v, _ := constant.Int64Val(tv.Value)
+	return int(v)
+}
+
+// namedName restituisce il nome del tipo named di t (puntatore compreso).
+func namedName(t types.Type) string {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if n, ok := types.Unalias(t).(*types.Named); ok {
+		return n.Obj().Name()
+	}
+	return ""
+}
+
+// tagName restituisce il nome nel tag key del campo ("" se assente) e se
+// il campo è escluso ("-").
+func tagName(tag, key string) (string, bool) {
+	v := reflect.StructTag(tag).Get(key)
+	name, _, _ := strings.Cut(v, ",")
+	return name, name == "-"
+}
+
+// tagRequired riporta se i tag di validazione di gin (binding) o di
+// go-playground/validator (validate) rendono il campo obbligatorio.
+func tagRequired(tag string) bool {
+	for _, key := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(reflect.StructTag(tag).Get(key), ",") {
+			if rule == "required" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,405 @@
+package openapi
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Content type delle richieste e delle risposte.
+const (
+	contentJSON      = "application/json"
+	contentXML       = "application/xml"
+	contentYAML      = "application/yaml"
+	contentText      = "text/plain"
+	contentHTML      = "text/html"
+	contentForm      = "application/x-www-form-urlencoded"
+	contentMultipart = "multipart/form-data"
+	contentBinary    = "application/octet-stream"
+)
+
+// handlerFacts è ciò che il corpo di un handler rivela dell'endpoint.
+type handlerFacts struct {
+	methods   []string // da confronti su r.Method (solo net/http)
+	params    []schema.CLDKHTTPParameter
+	body      *schema.CLDKHTTPBody
+	responses []schema.CLDKHTTPResponse
+	byMethod  map[string]*handlerFacts // casi di uno switch su r.Method
+}
+
+// ginBodies sono i metodi di binding del corpo di gin.Context.
+var ginBodies = map[string]string{
+	"BindJSON":       contentJSON,
+	"ShouldBindJSON": contentJSON,
+	"Bind":           contentJSON,
+	"ShouldBind":     contentJSON,
+	"ShouldBindWith": contentJSON,
+	"MustBindWith":   contentJSON,
+	"BindXML":        contentXML,
+	"ShouldBindXML":  contentXML,
+	"BindYAML":       contentYAML,
+	"ShouldBindYAML": contentYAML,
+}
+
+// ginBindings sono i metodi di gin.Context che leggono parametri in una
+// struct, con la loro posizione e il tag dei nomi.
+var ginBindings = map[string][2]string{
+	"BindQuery":        {"query", "form"},
+	"ShouldBindQuery":  {"query", "form"},
+	"BindUri":          {"path", "uri"},
+	"ShouldBindUri":    {"path", "uri"},
+	"BindHeader":       {"header", "header"},
+	"ShouldBindHeader": {"header", "header"},
+}
+
+// ginRenders sono i metodi di gin.Context che scrivono una risposta con
+// corpo (status, valore).
+var ginRenders = map[string]string{
+	"JSON":                contentJSON,
+	"IndentedJSON":        contentJSON,
+	"SecureJSON":          contentJSON,
+	"PureJSON":            contentJSON,
+	"AsciiJSON":           contentJSON,
+	"JSONP":               contentJSON,
+	"AbortWithStatusJSON": contentJSON,
+	"XML":                 contentXML,
+	"YAML":                contentYAML,
+}
+
+// analyze legge il corpo di un handler una sola volta.
+func (x *extractor) analyze(body *ast.BlockStmt, info *types.Info) *handlerFacts {
+	if f, ok := x.handlers[body]; ok {
+		return f
+	}
+	f := &handlerFacts{}
+	x.handlers[body] = f
+	status := http.StatusOK // net/http: fino a WriteHeader
+	x.walk(body, f, info, &status)
+	return f
+}
+
+// walk raccoglie in f i fatti di node. I casi di uno switch su r.Method
+// sono raccolti a parte, per metodo.
+func (x *extractor) walk(node ast.Node, f *handlerFacts, info *types.Info, status *int) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BinaryExpr:
+			if n.Op == token.EQL || n.Op == token.NEQ {
+				if isRequestMethod(n.X, info) {
+					f.method(n.Y, info)
+				} else if isRequestMethod(n.Y, info) {
+					f.method(n.X, info)
+				}
+			}
+		case *ast.SwitchStmt:
+			if n.Tag == nil || !isRequestMethod(n.Tag, info) {
+				return true
+			}
+			for _, stmt := range n.Body.List {
+				clause := stmt.(*ast.CaseClause)
+				sub := &handlerFacts{}
+				st := *status
+				for _, s := range clause.Body {
+					x.walk(s, sub, info, &st)
+				}
+				for _, e := range clause.List {
+					if m := f.method(e, info); m != "" {
+						if f.byMethod == nil {
+							f.byMethod = make(map[string]*handlerFacts)
+						}
+						f.byMethod[m] = sub
+					}
+				}
+			}
+			return false
+		case *ast.CallExpr:
+			x.call(f, n, info, status)
+		}
+		return true
+	})
+}
+
+// forMethod restituisce i fatti dell'endpoint per il metodo m: quelli
+// comuni più quelli del caso m di uno switch su r.Method.
+func (f *handlerFacts) forMethod(m string) *handlerFacts {
+	sub := f.byMethod[m]
+	out := &handlerFacts{body: f.body}
+	for _, p := range f.params {
+		out.addParam(p)
+	}
+	for _, r := range f.responses {
+		out.respond(r.Status, r.ContentType, r.Schema)
+	}
+	if sub != nil {
+		for _, p := range sub.params {
+			out.addParam(p)
+		}
+		if sub.body != nil {
+			out.body = sub.body
+		}
+		for _, r := range sub.responses {
+			out.respond(r.Status, r.ContentType, r.Schema)
+		}
+	}
+	sort.SliceStable(out.responses, func(i, j int) bool { return out.responses[i].Status < out.responses[j].Status })
+	return out
+}
+
+func (x *extractor) call(f *handlerFacts, call *ast.CallExpr, info *types.Info, status *int) {
+	fn, _ := typeutil.Callee(info, call).(*types.Func)
+	if fn == nil || fn.Pkg() == nil {
+		return
+	}
+	args := call.Args
+	arg := func(i int) ast.Expr {
+		if i < len(args) {
+			return args[i]
+		}
+		return nil
+	}
+	str := func(i int) string {
+		if e := arg(i); e != nil {
+			s, _ := constString(e, info)
+			return s
+		}
+		return ""
+	}
+	code := func(i int) int {
+		if e := arg(i); e != nil {
+			return constInt(e, info)
+		}
+		return 0
+	}
+
+	if isGinContext(fn) {
+		switch name := fn.Name(); name {
+		case "Param":
+			f.param(str(0), "path", stringSchema())
+		case "Query", "DefaultQuery", "GetQuery":
+			f.param(str(0), "query", stringSchema())
+		case "QueryArray", "GetQueryArray":
+			f.param(str(0), "query", &schema.OpenAPISchema{Type: "array", Items: stringSchema()})
+		case "GetHeader":
+			f.param(str(0), "header", stringSchema())
+		case "PostForm", "DefaultPostForm", "GetPostForm":
+			f.formField(str(0), contentForm, stringSchema())
+		case "FormFile":
+			f.formField(str(0), contentMultipart, binarySchema())
+		case "String":
+			f.respond(code(0), contentText, stringSchema())
+		case "HTML":
+			f.respond(code(0), contentHTML, stringSchema())
+		case "Data":
+			ct := str(1)
+			if ct == "" {
+				ct = contentBinary
+			}
+			f.respond(code(0), ct, binarySchema())
+		case "File", "FileAttachment":
+			f.respond(http.StatusOK, contentBinary, binarySchema())
+		case "Status", "AbortWithStatus", "AbortWithError", "Redirect":
+			f.respond(code(0), "", nil)
+		default:
+			if ct, ok := ginBodies[name]; ok {
+				f.requestBody(ct, x.valueSchema(arg(0), info))
+			} else if b, ok := ginBindings[name]; ok {
+				x.bindParams(f, arg(0), info, b[0], b[1])
+			} else if ct, ok := ginRenders[name]; ok {
+				f.respond(code(0), ct, x.valueSchema(arg(1), info))
+			}
+		}
+		return
+	}
+
+	switch fn.FullName() {
+	case "(*encoding/json.Decoder).Decode":
+		f.requestBody(contentJSON, x.valueSchema(arg(0), info))
+	case "(*encoding/xml.Decoder).Decode":
+		f.requestBody(contentXML, x.valueSchema(arg(0), info))
+	case "(*encoding/json.Encoder).Encode":
+		f.respond(*status, contentJSON, x.valueSchema(arg(0), info))
+	case "(*encoding/xml.Encoder).Encode":
+		f.respond(*status, contentXML, x.valueSchema(arg(0), info))
+	case "(net/http.ResponseWriter).WriteHeader":
+		if c := code(0); c != 0 {
+			*status = c
+			f.respond(c, "", nil)
+		}
+	case "(net/http.ResponseWriter).Write":
+		f.respond(*status, "", nil)
+	case "net/http.Error":
+		f.respond(code(2), contentText, stringSchema())
+	case "net/http.NotFound":
+		f.respond(http.StatusNotFound, contentText, stringSchema())
+	case "net/http.Redirect":
+		f.respond(code(3), "", nil)
+	case "(net/url.Values).Get", "(net/url.Values).Has":
+		f.param(str(0), "query", stringSchema())
+	case "(*net/http.Request).FormValue":
+		f.param(str(0), "query", stringSchema())
+	case "(*net/http.Request).PostFormValue":
+		f.formField(str(0), contentForm, stringSchema())
+	case "(*net/http.Request).FormFile":
+		f.formField(str(0), contentMultipart, binarySchema())
+	case "(*net/http.Request).PathValue":
+		f.param(str(0), "path", stringSchema())
+	case "(net/http.Header).Get", "(net/http.Header).Values":
+		// Solo gli header della richiesta, non quelli della risposta
+		if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok && isRequestField(sel.X, "Header", info) {
+			f.param(str(0), "header", stringSchema())
+		}
+	}
+}
+
+// bindParams aggiunge come parametri in i campi della struct puntata da e,
+// con i nomi del tag key.
+func (x *extractor) bindParams(f *handlerFacts, e ast.Expr, info *types.Info, in, key string) {
+	if e == nil {
+		return
+	}
+	t := info.TypeOf(e)
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return
+	}
+	forEachField(st, key, func(name string, field *types.Var, tag string) {
+		p := schema.CLDKHTTPParameter{Name: name, In: in, Required: in == "path" || tagRequired(tag), Schema: x.schemas.of(field.Type())}
+		f.addParam(p)
+	})
+}
+
+// valueSchema restituisce lo schema del valore e (senza il puntatore di
+// &v).
+func (x *extractor) valueSchema(e ast.Expr, info *types.Info) *schema.OpenAPISchema {
+	if e == nil {
+		return &schema.OpenAPISchema{}
+	}
+	t := info.TypeOf(e)
+	if t == nil {
+		return &schema.OpenAPISchema{}
+	}
+	return x.schemas.of(t)
+}
+
+// method aggiunge il metodo confrontato con r.Method e lo restituisce
+// ("" se e non è una costante).
+func (f *handlerFacts) method(e ast.Expr, info *types.Info) string {
+	m, ok := constString(e, info)
+	if !ok || m == "" {
+		return ""
+	}
+	m = strings.ToUpper(m)
+	for _, old := range f.methods {
+		if old == m {
+			return m
+		}
+	}
+	f.methods = append(f.methods, m)
+	return m
+}
+
+func (f *handlerFacts) param(name, in string, s *schema.OpenAPISchema) {
+	if name == "" {
+		return
+	}
+	f.addParam(schema.CLDKHTTPParameter{Name: name, In: in, Required: in == "path", Schema: s})
+}
+
+func (f *handlerFacts) addParam(p schema.CLDKHTTPParameter) {
+	for _, old := range f.params {
+		if old.Name == p.Name && old.In == p.In {
+			return
+		}
+	}
+	f.params = append(f.params, p)
+}
+
+// requestBody imposta il corpo della richiesta; il primo binding vince.
+func (f *handlerFacts) requestBody(ct string, s *schema.OpenAPISchema) {
+	if f.body == nil {
+		f.body = &schema.CLDKHTTPBody{ContentType: ct, Schema: s}
+	}
+}
+
+// formField aggiunge un campo al corpo di un form.
+func (f *handlerFacts) formField(name, ct string, s *schema.OpenAPISchema) {
+	if name == "" {
+		return
+	}
+	if f.body == nil {
+		f.body = &schema.CLDKHTTPBody{ContentType: ct, Schema: &schema.OpenAPISchema{Type: "object"}}
+	}
+	if f.body.ContentType != contentForm && f.body.ContentType != contentMultipart {
+		return
+	}
+	if ct == contentMultipart {
+		f.body.ContentType = contentMultipart // i file richiedono multipart
+	}
+	if f.body.Schema.Properties == nil {
+		f.body.Schema.Properties = make(map[string]*schema.OpenAPISchema)
+	}
+	f.body.Schema.Properties[name] = s
+}
+
+// respond aggiunge una risposta; per uno stesso status vince la prima con
+// corpo.
+func (f *handlerFacts) respond(status int, ct string, s *schema.OpenAPISchema) {
+	for i, old := range f.responses {
+		if old.Status != status {
+			continue
+		}
+		if old.ContentType == "" && ct != "" {
+			f.responses[i] = schema.CLDKHTTPResponse{Status: status, ContentType: ct, Schema: s}
+		}
+		return
+	}
+	f.responses = append(f.responses, schema.CLDKHTTPResponse{Status: status, ContentType: ct, Schema: s})
+}
+
+// isGinContext riporta se fn è un metodo di *gin.Context.
+func isGinContext(fn *types.Func) bool {
+	if fn.Pkg().Path() != pathGin {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	return recv != nil && namedName(recv.Type()) == "Context"
+}
+
+// isRequestMethod riporta se e è r.Method di una *http.Request.
+func isRequestMethod(e ast.Expr, info *types.Info) bool {
+	sel, ok := ast.Unparen(e).(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Method" && isRequest(sel.X, info)
+}
+
+// isRequestField riporta se e è il campo name di una *http.Request.
+func isRequestField(e ast.Expr, name string, info *types.Info) bool {
+	sel, ok := ast.Unparen(e).(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == name && isRequest(sel.X, info)
+}
+
+func isRequest(e ast.Expr, info *types.Info) bool {
+	t := info.TypeOf(e)
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == pathHTTP && n.Obj().Name() == "Request"
+}
+
+func stringSchema() *schema.OpenAPISchema {
+	return &schema.OpenAPISchema{Type: "string"}
+}
+
+func binarySchema() *schema.OpenAPISchema {
+	return &schema.OpenAPISchema{Type: "string", Format: "binary"}
+}
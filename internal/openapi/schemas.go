@@ -0,0 +1,199 @@
+package openapi
+
+import (
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// wellKnown sono gli schemi dei tipi che non si ricavano dalla struttura
+// (time.Time è una struct ma viaggia come stringa).
+var wellKnown = map[string]schema.OpenAPISchema{
+	"time.Time":                   {Type: "string", Format: "date-time"},
+	"encoding/json.RawMessage":    {},
+	"encoding/json.Number":        {Type: "number"},
+	"net/url.URL":                 {Type: "string", Format: "uri"},
+	"github.com/google/uuid.UUID": {Type: "string", Format: "uuid"},
+	pathGin + ".H":                {Type: "object"},
+}
+
+// schemaBuilder converte i tipi Go in schemi OpenAPI; le struct con nome
+// diventano componenti referenziati con "$ref".
+type schemaBuilder struct {
+	schemas map[string]*schema.OpenAPISchema
+	names   map[string]string // tipo qualificato → nome del componente
+	owners  map[string]string // nome del componente → tipo qualificato
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		schemas: make(map[string]*schema.OpenAPISchema),
+		names:   make(map[string]string),
+		owners:  make(map[string]string),
+	}
+}
+
+func (b *schemaBuilder) of(t types.Type) *schema.OpenAPISchema {
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() != nil {
+			if s, ok := wellKnown[obj.Pkg().Path()+"."+obj.Name()]; ok {
+				return &s
+			}
+		}
+		if st, ok := t.Underlying().(*types.Struct); ok {
+			return b.component(t, st)
+		}
+		if obj.Pkg() == nil && obj.Name() == "error" {
+			return stringSchema()
+		}
+		return b.of(t.Underlying())
+	case *types.Pointer:
+		return b.of(t.Elem())
+	case *types.Basic:
+		return basicSchema(t)
+	case *types.Slice:
+		if el, ok := t.Elem().Underlying().(*types.Basic); ok && el.Kind() == types.Byte {
+			return &schema.OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &schema.OpenAPISchema{Type: "array", Items: b.of(t.Elem())}
+	case *types.Array:
+		return &schema.OpenAPISchema{Type: "array", Items: b.of(t.Elem())}
+	case *types.Map:
+		return &schema.OpenAPISchema{Type: "object", AdditionalProperties: b.of(t.Elem())}
+	case *types.Struct:
+		return b.object(t)
+	}
+	// Interfacce, parametri di tipo, funzioni e canali: qualsiasi valore
+	return &schema.OpenAPISchema{}
+}
+
+// component restituisce il riferimento allo schema della struct n,
+// costruendolo alla prima occorrenza (anche per i tipi ricorsivi). I tipi
+// sono confrontati per nome qualificato: le varianti di test dei package
+// hanno oggetti distinti per lo stesso tipo.
+func (b *schemaBuilder) component(n *types.Named, st *types.Struct) *schema.OpenAPISchema {
+	qualified := types.TypeString(n, nil)
+	name, ok := b.names[qualified]
+	if !ok {
+		name = b.name(n)
+		b.names[qualified] = name
+		b.owners[name] = qualified
+		s := &schema.OpenAPISchema{}
+		b.schemas[name] = s
+		*s = *b.object(st)
+	}
+	return &schema.OpenAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// name restituisce il nome del componente di n: il nome del tipo, con il
+// package se un altro tipo lo usa già e con gli argomenti di tipo per le
+// istanze di tipi generici.
+func (b *schemaBuilder) name(n *types.Named) string {
+	obj := n.Obj()
+	name := obj.Name()
+	if args := n.TypeArgs(); args != nil {
+		for i := 0; i < args.Len(); i++ {
+			name += "_" + sanitize(types.TypeString(args.At(i), func(p *types.Package) string { return p.Name() }))
+		}
+	}
+	if _, taken := b.owners[name]; taken && obj.Pkg() != nil {
+		name = obj.Pkg().Name() + "." + name
+	}
+	base := name
+	for i := 2; ; i++ {
+		if _, taken := b.owners[name]; !taken {
+			return name
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+}
+
+// object restituisce lo schema di una struct secondo encoding/json: i
+// campi esportati con il nome del tag json, gli embedded senza tag
+// appiattiti.
+func (b *schemaBuilder) object(st *types.Struct) *schema.OpenAPISchema {
+	s := &schema.OpenAPISchema{Type: "object", Properties: make(map[string]*schema.OpenAPISchema)}
+	forEachField(st, "json", func(name string, field *types.Var, tag string) {
+		if _, ok := s.Properties[name]; ok {
+			return // il campo meno profondo vince
+		}
+		s.Properties[name] = b.of(field.Type())
+		if tagRequired(tag) {
+			s.Required = append(s.Required, name)
+		}
+	})
+	if len(s.Properties) == 0 {
+		s.Properties = nil
+	}
+	return s
+}
+
+// forEachField chiama fn per i campi serializzati di st con il nome del
+// tag key (o del campo), appiattendo gli embedded struct senza nome nel
+// tag come fanno encoding/json e il binding di gin.
+func forEachField(st *types.Struct, key string, fn func(name string, field *types.Var, tag string)) {
+	var embedded []*types.Struct
+	for i := 0; i < st.NumFields(); i++ {
+		field, tag := st.Field(i), st.Tag(i)
+		name, skip := tagName(tag, key)
+		if skip {
+			continue
+		}
+		if field.Embedded() && name == "" {
+			t := field.Type()
+			if p, ok := t.(*types.Pointer); ok {
+				t = p.Elem()
+			}
+			if inner, ok := t.Underlying().(*types.Struct); ok {
+				embedded = append(embedded, inner)
+				continue
+			}
+		}
+		if !field.Exported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name()
+		}
+		fn(name, field, tag)
+	}
+	for _, inner := range embedded {
+		forEachField(inner, key, fn)
+	}
+}
+
+func basicSchema(t *types.Basic) *schema.OpenAPISchema {
+	switch info := t.Info(); {
+	case info&types.IsBoolean != 0:
+		return &schema.OpenAPISchema{Type: "boolean"}
+	case info&types.IsInteger != 0:
+		switch t.Kind() {
+		case types.Int64, types.Uint64, types.Int, types.Uint, types.Uintptr:
+			return &schema.OpenAPISchema{Type: "integer", Format: "int64"}
+		}
+		return &schema.OpenAPISchema{Type: "integer", Format: "int32"}
+	case info&types.IsFloat != 0:
+		if t.Kind() == types.Float32 {
+			return &schema.OpenAPISchema{Type: "number", Format: "float"}
+		}
+		return &schema.OpenAPISchema{Type: "number", Format: "double"}
+	case info&types.IsString != 0:
+		return stringSchema()
+	}
+	return &schema.OpenAPISchema{}
+}
+
+// sanitize riduce s ai caratteri ammessi nei nomi dei componenti.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,121 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// CacheSuffix è il suffisso della cache binaria scritta accanto a
+// un'analisi JSON ("analysis.json" → "analysis.json.gob").
+const CacheSuffix = ".gob"
+
+// cacheMagic apre ogni file di cache, per riconoscerlo in lettura.
+var cacheMagic = []byte("CLDKGOB1")
+
+// cacheHeader identifica l'analisi JSON da cui la cache è stata scritta: la
+// cache è usata solo se il sorgente non è cambiato e lo schema coincide.
+type cacheHeader struct {
+	SchemaVersion string
+	SourceSize    int64
+	SourceModTime int64 // UnixNano
+}
+
+// CachePath restituisce il percorso della cache di un'analisi JSON.
+func CachePath(path string) string {
+	return path + CacheSuffix
+}
+
+// WriteCache scrive analysis in formato gob in cachePath, legandola al file
+// JSON source da cui è stata letta ("" = cache non legata a un sorgente,
+// letta solo se indicata esplicitamente).
+func WriteCache(analysis *schema.CLDKAnalysis, source, cachePath string) error {
+	hdr := cacheHeader{SchemaVersion: schema.SchemaVersion}
+	if source != "" {
+		info, err := os.Stat(source)
+		if err != nil {
+			return err
+		}
+		hdr.SourceSize, hdr.SourceModTime = info.Size(), info.ModTime().UnixNano()
+	}
+
+	tmp := cachePath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create cache: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	w.Write(cacheMagic)
+	enc := gob.NewEncoder(w)
+	err = enc.Encode(&hdr)
+	if err == nil {
+		err = enc.Encode(analysis)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write cache: %w", err)
+	}
+	return os.Rename(tmp, cachePath)
+}
+
+// isCache indica se r inizia con il magic number della cache.
+func isCache(r *bufio.Reader) bool {
+	head, _ := r.Peek(len(cacheMagic))
+	return bytes.Equal(head, cacheMagic)
+}
+
+// readCache legge una cache, magic number escluso. Se fresh non è nil,
+// l'analisi è decodificata solo quando fresh accetta l'header.
+func readCache(r io.Reader, fresh func(*cacheHeader) bool) (*schema.CLDKAnalysis, error) {
+	dec := gob.NewDecoder(r)
+	var hdr cacheHeader
+	if err := dec.Decode(&hdr); err != nil {
+		return nil, fmt.Errorf("decode cache header: %w", err)
+	}
+	if fresh != nil && !fresh(&hdr) {
+		return nil, nil
+	}
+	var analysis schema.CLDKAnalysis
+	if err := dec.Decode(&analysis); err != nil {
+		return nil, fmt.Errorf("decode cache: %w", err)
+	}
+	return &analysis, nil
+}
+
+// readFreshCache legge la cache di source se esiste ed è aggiornata;
+// altrimenti restituisce nil, e chi chiama legge il JSON.
+func readFreshCache(source string) *schema.CLDKAnalysis {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(CachePath(source))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	if !isCache(r) {
+		return nil
+	}
+	r.Discard(len(cacheMagic))
+	analysis, err := readCache(r, func(hdr *cacheHeader) bool {
+		return hdr.SchemaVersion == schema.SchemaVersion &&
+			hdr.SourceSize == info.Size() && hdr.SourceModTime == info.ModTime().UnixNano()
+	})
+	if err != nil {
+		return nil
+	}
+	return analysis
+}
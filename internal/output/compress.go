@@ -0,0 +1,98 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression è l'algoritmo di compressione dell'output.
+type Compression string
+
+const (
+	CompressNone Compression = ""
+	CompressGzip Compression = "gzip"
+	CompressZstd Compression = "zstd"
+)
+
+// Magic number dei formati compressi, usati per riconoscerli in lettura.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// FileName restituisce il nome del file di output per la compressione c.
+func FileName(c Compression) string {
+	return "analysis.json" + compressExt(c)
+}
+
+// compressExt restituisce l'estensione aggiunta ai file compressi con c.
+func compressExt(c Compression) string {
+	switch c {
+	case CompressGzip:
+		return ".gz"
+	case CompressZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// ParseCompression valida il valore di --compress.
+func ParseCompression(s string) (Compression, error) {
+	switch c := Compression(s); c {
+	case CompressNone, "none":
+		return CompressNone, nil
+	case CompressGzip, CompressZstd:
+		return c, nil
+	default:
+		return "", fmt.Errorf("invalid compression %q (valid: gzip, zstd)", s)
+	}
+}
+
+// nopWriteCloser adatta un io.Writer senza compressione.
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// compressWriter avvolge w con il compressore c. Close chiude solo il
+// compressore (scrivendo il trailer), non w. Con deterministic zstd comprime
+// su una sola goroutine, così l'output non dipende da GOMAXPROCS.
+func compressWriter(w io.Writer, c Compression, deterministic bool) (io.WriteCloser, error) {
+	switch c {
+	case CompressNone:
+		return nopWriteCloser{w}, nil
+	case CompressGzip:
+		return gzip.NewWriter(w), nil
+	case CompressZstd:
+		if deterministic {
+			return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		}
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", c)
+	}
+}
+
+// NewReader restituisce un reader che decomprime r se inizia con il magic
+// number di gzip o zstd, altrimenti lo legge così com'è.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(head, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
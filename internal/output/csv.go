@@ -0,0 +1,195 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// table è una vista tabellare dell'analisi, scritta come un file CSV/TSV.
+type table struct {
+	name   string // nome del file senza estensione
+	header []string
+	rows   [][]string
+}
+
+// writeTables scrive le viste tabellari dell'analisi: con OutputDir un file
+// per vista (callables.csv, edges.csv, metrics.csv, issues.csv), su stdout
+// le viste una dopo l'altra, ognuna preceduta da "# nome" e separate da una
+// riga vuota. Le viste senza dati di origine (es. edges senza call graph)
+// sono omesse; issues è sempre presente.
+func writeTables(analysis *schema.CLDKAnalysis, cfg Config) error {
+	ext, comma := ".csv", ','
+	if cfg.Format == FormatTSV {
+		ext, comma = ".tsv", '\t'
+	}
+	return writeTableSet(buildTables(analysis), cfg, ext, comma)
+}
+
+// writeTableSet scrive tables in OutputDir, un file name+ext per tabella,
+// oppure su stdout in sezioni "# name".
+func writeTableSet(tables []*table, cfg Config, ext string, comma rune) error {
+	if cfg.OutputDir == "" {
+		return writeOutput(cfg, func(w io.Writer) error {
+			for i, t := range tables {
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintf(w, "# %s\n", t.name)
+				if err := writeTable(w, t, comma); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	for _, t := range tables {
+		err := writeNamed(cfg, t.name+ext, func(w io.Writer) error {
+			return writeTable(w, t, comma)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, t *table, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	cw.Write(t.header)
+	cw.WriteAll(t.rows)
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("encode %s: %w", t.name, err)
+	}
+	return nil
+}
+
+func buildTables(analysis *schema.CLDKAnalysis) []*table {
+	var tables []*table
+	if analysis.SymbolTable != nil {
+		tables = append(tables, callablesTable(analysis.SymbolTable, analysis.CallGraph))
+	}
+	if analysis.CallGraph != nil {
+		tables = append(tables, edgesTable(analysis.CallGraph))
+	}
+	if analysis.Metrics != nil {
+		tables = append(tables, metricsTable(analysis.Metrics))
+	}
+	return append(tables, issuesTable(analysis.Issues))
+}
+
+// callablesTable elenca funzioni e metodi ordinati per ID. line_count,
+// complexity e max_nesting richiedono --include-body; fan_in e fan_out il
+// call graph a granularità func.
+func callablesTable(st *schema.CLDKSymbolTable, cg *schema.CLDKCallGraph) *table {
+	t := &table{name: "callables", header: []string{
+		"id", "package", "name", "kind", "receiver", "exported", "signature",
+		"file", "start_line", "end_line", "line_count", "complexity", "max_nesting", "fan_in", "fan_out",
+	}}
+	degree := make(map[string][2]int)
+	if cg != nil {
+		for _, n := range cg.Nodes {
+			if n.Kind == "package" {
+				continue
+			}
+			id := n.ID
+			if n.SymbolRef != "" {
+				id = n.SymbolRef
+			}
+			d := degree[id]
+			degree[id] = [2]int{d[0] + n.FanIn, d[1] + n.FanOut}
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(st.Packages)) {
+		pkg := st.Packages[path]
+		for _, id := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+			cd := pkg.CallableDeclarations[id]
+			row := []string{id, path, cd.Name, cd.Kind, cd.ReceiverType, strconv.FormatBool(cd.Exported), cd.Signature}
+			row = append(row, positionCells(cd.Position)[:2]...)
+			end := ""
+			if cd.EndPosition != nil {
+				end = strconv.Itoa(cd.EndPosition.StartLine)
+			}
+			row = append(row, end)
+			if b := cd.Body; b != nil {
+				row = append(row, strconv.Itoa(b.LineCount), strconv.Itoa(b.Complexity), strconv.Itoa(b.MaxNesting))
+			} else {
+				row = append(row, "", "", "")
+			}
+			if d, ok := degree[id]; ok {
+				row = append(row, strconv.Itoa(d[0]), strconv.Itoa(d[1]))
+			} else {
+				row = append(row, "", "")
+			}
+			t.rows = append(t.rows, row)
+		}
+	}
+	return t
+}
+
+// edgesTable elenca gli archi del call graph nell'ordine dell'analisi.
+func edgesTable(cg *schema.CLDKCallGraph) *table {
+	t := &table{name: "edges", header: []string{
+		"source", "target", "kind", "category", "count", "declared_target", "file", "line", "column",
+	}}
+	for _, e := range cg.Edges {
+		count := e.Count
+		if count == 0 {
+			count = 1
+		}
+		row := []string{e.Source, e.Target, e.Kind, e.Category, strconv.Itoa(count), e.DeclaredTarget}
+		t.rows = append(t.rows, append(row, positionCells(e.CallSite)...))
+	}
+	return t
+}
+
+// metricsTable elenca le metriche di coupling per package.
+func metricsTable(m *schema.CLDKMetrics) *table {
+	t := &table{name: "metrics", header: []string{
+		"package", "afferent_coupling", "efferent_coupling", "instability", "abstractness",
+		"distance", "interface_count", "type_count", "external_imports",
+	}}
+	for _, path := range slices.Sorted(maps.Keys(m.Packages)) {
+		pm := m.Packages[path]
+		t.rows = append(t.rows, []string{
+			path,
+			strconv.Itoa(pm.AfferentCoupling),
+			strconv.Itoa(pm.EfferentCoupling),
+			formatFloat(pm.Instability),
+			formatFloat(pm.Abstractness),
+			formatFloat(pm.Distance),
+			strconv.Itoa(pm.InterfaceCount),
+			strconv.Itoa(pm.TypeCount),
+			strconv.Itoa(pm.ExternalImports),
+		})
+	}
+	return t
+}
+
+// issuesTable elenca gli issue nell'ordine dell'analisi.
+func issuesTable(issues []schema.Issue) *table {
+	t := &table{name: "issues", header: []string{"severity", "code", "message", "file", "line", "column"}}
+	for _, is := range issues {
+		row := []string{is.Severity, is.Code, is.Message}
+		t.rows = append(t.rows, append(row, positionCells(is.Position)...))
+	}
+	return t
+}
+
+// positionCells restituisce file, riga e colonna di p (vuoti se nil).
+func positionCells(p *schema.CLDKPosition) []string {
+	if p == nil {
+		return []string{"", "", ""}
+	}
+	return []string{p.File, strconv.Itoa(p.StartLine), strconv.Itoa(p.StartColumn)}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
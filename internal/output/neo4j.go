@@ -0,0 +1,380 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Modalità di FormatNeo4j.
+const (
+	Neo4jCSV    = "csv"    // file CSV per neo4j-admin database import
+	Neo4jCypher = "cypher" // statement Cypher idempotenti (MERGE) in neo4j.cypher
+)
+
+// neo4jBatch è il numero di righe per statement UNWIND in modalità cypher.
+const neo4jBatch = 500
+
+// graphProp è una proprietà di nodi o relazioni; typ è il tipo
+// neo4j-admin ("" = string, "int", "boolean").
+type graphProp struct {
+	name string
+	typ  string
+}
+
+// graphSet è un insieme di nodi con la stessa label (ID space) o di
+// relazioni dello stesso tipo tra due label, scritto come un file CSV.
+// Le righe dei nodi iniziano con id e label aggiuntive (separate da ";"),
+// quelle delle relazioni con id di inizio e di fine; seguono le proprietà,
+// nil se assenti.
+type graphSet struct {
+	name     string // nome del file senza estensione
+	label    string // label dei nodi o tipo delle relazioni
+	from, to string // label di inizio e fine (solo relazioni)
+	props    []graphProp
+	rows     [][]interface{}
+}
+
+func (s *graphSet) isRel() bool { return s.from != "" }
+
+// writeNeo4j scrive il grafo dell'analisi per Neo4j: package, tipi e
+// callable come nodi; import, dichiarazioni, metodi, chiamate e
+// implementazioni come relazioni. Gli id sono quelli dell'analisi (import
+// path, qualified name dei tipi, ID dei callable), uno spazio di id per
+// label.
+func writeNeo4j(analysis *schema.CLDKAnalysis, cfg Config) error {
+	sets := buildNeo4j(analysis)
+	if cfg.Neo4jMode == Neo4jCypher {
+		return writeNamed(cfg, "neo4j.cypher", func(w io.Writer) error {
+			return writeCypher(w, sets)
+		})
+	}
+	tables := make([]*table, len(sets))
+	for i, s := range sets {
+		tables[i] = s.table()
+	}
+	return writeTableSet(tables, cfg, ".csv", ',')
+}
+
+func buildNeo4j(analysis *schema.CLDKAnalysis) []*graphSet {
+	packages := &graphSet{name: "nodes_packages", label: "Package", props: []graphProp{
+		{"path", ""}, {"name", ""}, {"files", "int"}, {"external", "boolean"},
+	}}
+	typesSet := &graphSet{name: "nodes_types", label: "Type", props: []graphProp{
+		{"name", ""}, {"kind", ""}, {"package", ""}, {"exported", "boolean"}, {"file", ""}, {"line", "int"},
+	}}
+	callables := &graphSet{name: "nodes_callables", label: "Callable", props: []graphProp{
+		{"name", ""}, {"kind", ""}, {"package", ""}, {"receiver", ""}, {"signature", ""},
+		{"exported", "boolean"}, {"external", "boolean"}, {"file", ""}, {"line", "int"},
+	}}
+	imports := &graphSet{name: "rels_imports", label: "IMPORTS", from: "Package", to: "Package", props: []graphProp{{"alias", ""}}}
+	declTypes := &graphSet{name: "rels_declares_types", label: "DECLARES", from: "Package", to: "Type"}
+	declCallables := &graphSet{name: "rels_declares_callables", label: "DECLARES", from: "Package", to: "Callable"}
+	hasMethod := &graphSet{name: "rels_has_method", label: "HAS_METHOD", from: "Type", to: "Callable"}
+	implements := &graphSet{name: "rels_implements", label: "IMPLEMENTS", from: "Type", to: "Type"}
+	calls := &graphSet{name: "rels_calls", label: "CALLS", from: "Callable", to: "Callable", props: []graphProp{
+		{"kind", ""}, {"category", ""}, {"count", "int"}, {"declared_target", ""}, {"file", ""}, {"line", "int"},
+	}}
+
+	// Package fuori dalla symbol table (importati o raggiunti dal call
+	// graph) come nodi propri, così ogni relazione ha entrambi gli estremi;
+	// sono external se nessun loro callable appartiene al progetto
+	pkgIDs := make(map[string]bool)
+	otherPkgs := make(map[string]bool) // import path → external
+	addPkg := func(p string, external bool) {
+		if p == "" || pkgIDs[p] {
+			return
+		}
+		if ext, ok := otherPkgs[p]; !ok || ext {
+			otherPkgs[p] = external
+		}
+	}
+	callableIDs := make(map[string]bool)
+	typeIDs := make(map[string]bool)
+
+	st := analysis.SymbolTable
+	if st != nil {
+		paths := slices.Sorted(maps.Keys(st.Packages))
+		for _, p := range paths {
+			pkgIDs[p] = true
+		}
+		for _, p := range paths {
+			pkg := st.Packages[p]
+			packages.rows = append(packages.rows, []interface{}{p, "", p, pkg.Name, len(pkg.Files), false})
+			seen := make(map[string]bool)
+			for _, imp := range pkg.Imports {
+				if seen[imp.Path] || imp.Path == "C" {
+					continue
+				}
+				seen[imp.Path] = true
+				addPkg(imp.Path, true)
+				imports.rows = append(imports.rows, []interface{}{p, imp.Path, optString(imp.Alias)})
+			}
+			for _, id := range slices.Sorted(maps.Keys(pkg.TypeDeclarations)) {
+				t := pkg.TypeDeclarations[id]
+				typeIDs[id] = true
+				file, line := positionProps(t.Position)
+				typesSet.rows = append(typesSet.rows, []interface{}{id, kindLabel(t.Kind), t.Name, t.Kind, p, token.IsExported(t.Name), file, line})
+				declTypes.rows = append(declTypes.rows, []interface{}{p, id})
+			}
+			for _, id := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+				cd := pkg.CallableDeclarations[id]
+				callableIDs[id] = true
+				file, line := positionProps(cd.Position)
+				callables.rows = append(callables.rows, []interface{}{
+					id, kindLabel(cd.Kind), cd.Name, cd.Kind, p, optString(cd.ReceiverType), cd.Signature, cd.Exported, false, file, line,
+				})
+				declCallables.rows = append(declCallables.rows, []interface{}{p, id})
+			}
+		}
+		for _, p := range paths {
+			pkg := st.Packages[p]
+			// I metodi sono collegati al tipo del receiver dalle
+			// dichiarazioni, che li contengono tutti anche quando il tipo è
+			// dichiarato in un altro file
+			for _, id := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+				cd := pkg.CallableDeclarations[id]
+				if tid := p + "." + cd.ReceiverType; cd.ReceiverType != "" && typeIDs[tid] {
+					hasMethod.rows = append(hasMethod.rows, []interface{}{tid, id})
+				}
+			}
+			for _, id := range slices.Sorted(maps.Keys(pkg.TypeDeclarations)) {
+				t := pkg.TypeDeclarations[id]
+				for _, iface := range t.Implements {
+					if typeIDs[iface] {
+						implements.rows = append(implements.rows, []interface{}{id, iface})
+					}
+				}
+			}
+		}
+	}
+
+	// Con granularità pkg i nodi del call graph sono package: niente CALLS
+	cg := analysis.CallGraph
+	withCalls := cg != nil && cg.Granularity != "pkg"
+	if withCalls {
+		// I nodi del call graph sono i callable della symbol table
+		// (SymbolRef) oppure callable esterni aggiunti qui
+		ids := make(map[string]string, len(cg.Nodes))
+		for _, n := range cg.Nodes {
+			id := n.ID
+			if n.SymbolRef != "" && callableIDs[n.SymbolRef] {
+				id = n.SymbolRef
+			}
+			ids[n.ID] = id
+			if callableIDs[id] {
+				continue
+			}
+			callableIDs[id] = true
+			// Le closure dei package del progetto non sono nella symbol
+			// table ma non sono esterne
+			external := n.SymbolRef == "" && (st == nil || st.Packages[n.Package] == nil)
+			addPkg(n.Package, external)
+			file, line := positionProps(n.Position)
+			callables.rows = append(callables.rows, []interface{}{
+				id, kindLabel(n.Kind), n.Name, n.Kind, n.Package, nil, nil, token.IsExported(n.Name), external, file, line,
+			})
+		}
+		for _, e := range cg.Edges {
+			src, ok1 := ids[e.Source]
+			dst, ok2 := ids[e.Target]
+			if !ok1 || !ok2 {
+				continue
+			}
+			count := e.Count
+			if count == 0 {
+				count = 1
+			}
+			file, line := positionProps(e.CallSite)
+			calls.rows = append(calls.rows, []interface{}{
+				src, dst, optString(e.Kind), optString(e.Category), count, optString(e.DeclaredTarget), file, line,
+			})
+		}
+	}
+	for _, p := range slices.Sorted(maps.Keys(otherPkgs)) {
+		packages.rows = append(packages.rows, []interface{}{p, "", p, path.Base(p), nil, otherPkgs[p]})
+	}
+
+	sets := []*graphSet{packages, typesSet, callables, imports, declTypes, declCallables, hasMethod, implements}
+	if withCalls {
+		sets = append(sets, calls)
+	}
+	return sets
+}
+
+// kindLabel restituisce la label aggiuntiva per il kind di tipi e
+// callable (es. "struct" → "Struct").
+func kindLabel(kind string) string {
+	if kind == "" {
+		return ""
+	}
+	return strings.ToUpper(kind[:1]) + kind[1:]
+}
+
+func positionProps(p *schema.CLDKPosition) (file, line interface{}) {
+	if p == nil {
+		return nil, nil
+	}
+	return p.File, p.StartLine
+}
+
+func optString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// table converte l'insieme nel formato CSV di neo4j-admin import: header
+// id:ID(Label) e :LABEL per i nodi, :START_ID(Label), :END_ID(Label) e
+// :TYPE per le relazioni, poi le proprietà con il loro tipo.
+func (s *graphSet) table() *table {
+	t := &table{name: s.name}
+	if s.isRel() {
+		t.header = []string{":START_ID(" + s.from + ")", ":END_ID(" + s.to + ")", ":TYPE"}
+	} else {
+		t.header = []string{"id:ID(" + s.label + ")", ":LABEL"}
+	}
+	for _, p := range s.props {
+		h := p.name
+		if p.typ != "" {
+			h += ":" + p.typ
+		}
+		t.header = append(t.header, h)
+	}
+	for _, r := range s.rows {
+		var row []string
+		if s.isRel() {
+			row = []string{r[0].(string), r[1].(string), s.label}
+		} else {
+			labels := s.label
+			if extra := r[1].(string); extra != "" {
+				labels += ";" + extra
+			}
+			row = []string{r[0].(string), labels}
+		}
+		for _, v := range r[2:] {
+			row = append(row, csvValue(v))
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t
+}
+
+func csvValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return fmt.Sprint(v)
+}
+
+// writeCypher scrive i vincoli di unicità sugli id e, per ogni insieme,
+// statement UNWIND ... MERGE a blocchi di neo4jBatch righe: rieseguire lo
+// script aggiorna il grafo senza duplicarlo.
+func writeCypher(w io.Writer, sets []*graphSet) error {
+	fmt.Fprintln(w, "// Generated by codeanalyzer-go (schema "+schema.SchemaVersion+")")
+	for _, s := range sets {
+		if !s.isRel() {
+			fmt.Fprintf(w, "CREATE CONSTRAINT cldk_%s_id IF NOT EXISTS FOR (n:%s) REQUIRE n.id IS UNIQUE;\n", strings.ToLower(s.label), s.label)
+		}
+	}
+	for _, s := range sets {
+		// Le label aggiuntive non sono parametrizzabili: un gruppo di
+		// statement per combinazione di label
+		groups := make(map[string][][]interface{})
+		var order []string
+		for _, r := range s.rows {
+			key := ""
+			if !s.isRel() {
+				key = r[1].(string)
+			}
+			if _, ok := groups[key]; !ok {
+				order = append(order, key)
+			}
+			groups[key] = append(groups[key], r)
+		}
+		for _, key := range order {
+			rows := groups[key]
+			for start := 0; start < len(rows); start += neo4jBatch {
+				end := start + neo4jBatch
+				if end > len(rows) {
+					end = len(rows)
+				}
+				if err := writeCypherBatch(w, s, key, rows[start:end]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func writeCypherBatch(w io.Writer, s *graphSet, extra string, rows [][]interface{}) error {
+	var b strings.Builder
+	b.WriteString("UNWIND [")
+	for i, r := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if s.isRel() {
+			fmt.Fprintf(&b, "{from: %s, to: %s, props: ", cypherValue(r[0]), cypherValue(r[1]))
+		} else {
+			fmt.Fprintf(&b, "{id: %s, props: ", cypherValue(r[0]))
+		}
+		b.WriteString("{")
+		n := 0
+		for j, v := range r[2:] {
+			if v == nil {
+				continue
+			}
+			if n > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%s: %s", s.props[j].name, cypherValue(v))
+			n++
+		}
+		b.WriteString("}}")
+	}
+	b.WriteString("] AS row\n")
+	if s.isRel() {
+		fmt.Fprintf(&b, "MATCH (a:%s {id: row.from}) MATCH (b:%s {id: row.to}) MERGE (a)-[r:%s]->(b) SET r += row.props;\n", s.from, s.to, s.label)
+	} else {
+		fmt.Fprintf(&b, "MERGE (n:%s {id: row.id}) SET n += row.props", s.label)
+		if extra != "" {
+			fmt.Fprintf(&b, ", n:%s", extra)
+		}
+		b.WriteString(";\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// cypherValue restituisce il letterale Cypher di v: le stringhe usano gli
+// stessi escape di JSON, accettati da Cypher.
+func cypherValue(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		data, _ := json.Marshal(x)
+		return string(data)
+	case int:
+		return strconv.Itoa(x)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return "null"
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// ReadFile legge un'analisi CLDK precedentemente salvata in formato JSON,
+// eventualmente compressa con gzip o zstd, oppure una cache binaria scritta
+// da WriteCache. Per un file JSON usa la cache accanto (CachePath) se è
+// aggiornata rispetto al file.
+func ReadFile(path string) (*schema.CLDKAnalysis, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open analysis: %w", err)
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if isCache(br) {
+		br.Discard(len(cacheMagic))
+		return readCache(br, nil)
+	}
+	if analysis := readFreshCache(path); analysis != nil {
+		return analysis, nil
+	}
+	return readJSON(br)
+}
+
+// ReadJSONFile legge un'analisi JSON, eventualmente compressa, ignorando la
+// cache binaria.
+func ReadJSONFile(path string) (*schema.CLDKAnalysis, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open analysis: %w", err)
+	}
+	defer f.Close()
+	return readJSON(f)
+}
+
+// readJSON decodifica un'analisi JSON, eventualmente compressa, da r.
+func readJSON(r io.Reader) (*schema.CLDKAnalysis, error) {
+	rc, err := NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompress analysis: %w", err)
+	}
+	defer rc.Close()
+
+	var analysis schema.CLDKAnalysis
+	if err := json.NewDecoder(rc).Decode(&analysis); err != nil {
+		return nil, fmt.Errorf("decode analysis: %w", err)
+	}
+	return &analysis, nil
+}
+
+// ReadBytes legge il contenuto di un file di output, decompresso se gzip o
+// zstd.
+func ReadBytes(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("decompress %s: %w", path, err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
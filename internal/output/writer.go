@@ -2,12 +2,17 @@
 package output
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 
+	"github.com/codellm-devkit/codeanalyzer-go/internal/facts"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/frames"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/report"
 	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
 )
 
@@ -15,15 +20,30 @@ import (
 type Format string
 
 const (
-	FormatJSON    Format = "json"
-	FormatMsgpack Format = "msgpack" // placeholder per futuro supporto
+	FormatJSON          Format = "json"
+	FormatCSV           Format = "csv" // viste tabellari, vedi writeTables
+	FormatTSV           Format = "tsv"
+	FormatHTML          Format = "html"           // report statico, vedi report.Write
+	FormatTreemap       Format = "treemap"        // treemap.json, vedi WriteTreemap
+	FormatFrames        Format = "ndjson-frames"  // stream di frame NDJSON, vedi frames.Write
+	FormatCallHierarchy Format = "call-hierarchy" // call_hierarchy.json, vedi WriteCallHierarchy
+	FormatNeo4j         Format = "neo4j"          // CSV per neo4j-admin o Cypher, vedi writeNeo4j
+	FormatFacts         Format = "facts"          // entries Kythe in facts.ndjson, vedi facts.Write
+	FormatOpenAPI       Format = "openapi"        // openapi.json, vedi WriteOpenAPI
+	FormatMsgpack       Format = "msgpack"        // placeholder per futuro supporto
 )
 
 // Config configura l'output writer.
 type Config struct {
 	OutputDir string // directory output (vuoto = stdout)
-	Format    Format // json|msgpack (default: json)
+	Format    Format // json|csv|tsv|html|treemap|ndjson-frames|call-hierarchy|neo4j|facts|openapi|msgpack (default: json)
 	Indent    bool   // indentazione JSON (default: true)
+	Neo4jMode string // csv|cypher con FormatNeo4j (default: csv)
+	Corpus    string // corpus dei VName con FormatFacts (vuoto = module path)
+	Sources   fs.FS  // sorgenti del progetto (LoadResult.FS) riletti da FormatFacts; nil = dal disco
+
+	Compress      Compression // gzip|zstd (vuoto = nessuna compressione)
+	Deterministic bool        // compressione riproducibile (--deterministic)
 }
 
 // Write scrive l'analisi CLDK nel formato specificato.
@@ -35,6 +55,34 @@ func Write(analysis *schema.CLDKAnalysis, cfg Config) error {
 	switch cfg.Format {
 	case FormatJSON:
 		return writeJSON(analysis, cfg)
+	case FormatCSV, FormatTSV:
+		return writeTables(analysis, cfg)
+	case FormatHTML:
+		return writeNamed(cfg, "report.html", func(w io.Writer) error {
+			bw := bufio.NewWriter(w)
+			if err := report.Write(bw, analysis); err != nil {
+				return fmt.Errorf("render report: %w", err)
+			}
+			return bw.Flush()
+		})
+	case FormatNeo4j:
+		return writeNeo4j(analysis, cfg)
+	case FormatFacts:
+		return writeNamed(cfg, "facts.ndjson", func(w io.Writer) error {
+			bw := bufio.NewWriter(w)
+			if err := facts.Write(bw, analysis, cfg.Corpus, cfg.Sources); err != nil {
+				return fmt.Errorf("encode facts: %w", err)
+			}
+			return bw.Flush()
+		})
+	case FormatFrames:
+		return writeNamed(cfg, "analysis.ndjson", func(w io.Writer) error {
+			bw := bufio.NewWriter(w)
+			if err := frames.Write(bw, analysis); err != nil {
+				return fmt.Errorf("encode frames: %w", err)
+			}
+			return bw.Flush()
+		})
 	case FormatMsgpack:
 		return fmt.Errorf("msgpack format not yet implemented")
 	default:
@@ -42,7 +90,9 @@ func Write(analysis *schema.CLDKAnalysis, cfg Config) error {
 	}
 }
 
-// writeJSON scrive l'output in formato JSON.
+// writeJSON scrive l'output in formato JSON, in streaming: la durata della
+// serializzazione non entra nei metadati (servirebbe il documento intero in
+// memoria), la CLI la riporta nel log.
 func writeJSON(analysis *schema.CLDKAnalysis, cfg Config) error {
 	return writeJSONGeneric(analysis, cfg)
 }
@@ -54,8 +104,67 @@ func WriteCompact(analysis *schema.CompactAnalysis, cfg Config) error {
 	return writeJSONGeneric(analysis, cfg)
 }
 
+// WriteTreemap scrive la treemap in treemap.json (o su stdout).
+func WriteTreemap(tm *schema.CLDKTreemap, cfg Config) error {
+	return writeNamed(cfg, "treemap.json", func(w io.Writer) error {
+		if err := encodeJSON(w, tm, cfg.Indent); err != nil {
+			return fmt.Errorf("encode json: %w", err)
+		}
+		return nil
+	})
+}
+
+// WriteCallHierarchy scrive le gerarchie di chiamate in call_hierarchy.json
+// (o su stdout).
+func WriteCallHierarchy(ch *schema.CLDKCallHierarchy, cfg Config) error {
+	return writeNamed(cfg, "call_hierarchy.json", func(w io.Writer) error {
+		if err := encodeJSON(w, ch, cfg.Indent); err != nil {
+			return fmt.Errorf("encode json: %w", err)
+		}
+		return nil
+	})
+}
+
+// WriteOpenAPI scrive il documento OpenAPI in openapi.json (o su stdout).
+func WriteOpenAPI(doc *schema.OpenAPIDocument, cfg Config) error {
+	return writeNamed(cfg, "openapi.json", func(w io.Writer) error {
+		if err := encodeJSON(w, doc, cfg.Indent); err != nil {
+			return fmt.Errorf("encode json: %w", err)
+		}
+		return nil
+	})
+}
+
 // writeJSONGeneric scrive qualsiasi struttura in formato JSON.
 func writeJSONGeneric(data interface{}, cfg Config) error {
+	return writeOutput(cfg, func(w io.Writer) error {
+		if err := encodeJSON(w, data, cfg.Indent); err != nil {
+			return fmt.Errorf("encode json: %w", err)
+		}
+		return nil
+	})
+}
+
+// encodeJSON codifica data in w.
+func encodeJSON(w io.Writer, data interface{}, indent bool) error {
+	enc := json.NewEncoder(w)
+	if indent {
+		enc.SetIndent("", "  ")
+	}
+	// Assicura che i caratteri speciali non siano escaped
+	enc.SetEscapeHTML(false)
+	return enc.Encode(data)
+}
+
+// writeOutput apre la destinazione (stdout o analysis.json in OutputDir,
+// eventualmente compresso) e vi scrive con write.
+func writeOutput(cfg Config, write func(io.Writer) error) error {
+	return writeNamed(cfg, "analysis.json", write)
+}
+
+// writeNamed è writeOutput per il file name in OutputDir (più l'estensione
+// della compressione).
+func writeNamed(cfg Config, name string, write func(io.Writer) error) error {
 	var w io.Writer
 
 	if cfg.OutputDir == "" {
@@ -67,8 +176,8 @@ func writeJSONGeneric(data interface{}, cfg Config) error {
 			return fmt.Errorf("create output dir: %w", err)
 		}
 
-		// Crea file analysis.json
-		outPath := filepath.Join(cfg.OutputDir, "analysis.json")
+		// Crea il file (.gz/.zst se compresso)
+		outPath := filepath.Join(cfg.OutputDir, name+compressExt(cfg.Compress))
 		f, err := os.Create(outPath)
 		if err != nil {
 			return fmt.Errorf("create output file: %w", err)
@@ -77,15 +186,17 @@ func writeJSONGeneric(data interface{}, cfg Config) error {
 		w = f
 	}
 
-	enc := json.NewEncoder(w)
-	if cfg.Indent {
-		enc.SetIndent("", "  ")
+	cw, err := compressWriter(w, cfg.Compress, cfg.Deterministic)
+	if err != nil {
+		return err
 	}
-	// Assicura che i caratteri speciali non siano escaped
-	enc.SetEscapeHTML(false)
 
-	if err := enc.Encode(data); err != nil {
-		return fmt.Errorf("encode json: %w", err)
+	if err := write(cw); err != nil {
+		cw.Close()
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		return fmt.Errorf("compress output: %w", err)
 	}
 
 	return nil
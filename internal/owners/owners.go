@@ -0,0 +1,269 @@
+// Package owners unisce all'output un file sidecar di ownership: un
+// CODEOWNERS o una mappa YAML pattern → owners/tags. Owners e tag sono
+// attribuiti a package, tipi e callable in base ai loro file.
+package owners
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/yamlsubset"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Rule associa owners e tag ai file che corrispondono a un pattern.
+type Rule struct {
+	Pattern string
+	Owners  []string
+	Tags    []string
+
+	re *regexp.Regexp
+}
+
+// Map è l'insieme ordinato delle regole. Come in CODEOWNERS, gli owners di
+// un file sono quelli dell'ultima regola che corrisponde e ne indica; i tag
+// sono l'unione dei tag di tutte le regole che corrispondono.
+type Map struct {
+	Rules []*Rule
+}
+
+// Load legge un file di ownership. I file .yaml/.yml sono mappe
+// pattern → owners/tags, gli altri sono letti in formato CODEOWNERS.
+func Load(name string) (*Map, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *Map
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		m, err = parseYAML(string(data))
+	default:
+		m, err = parseCodeowners(bufio.NewScanner(bytes.NewReader(data)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return m, nil
+}
+
+// parseCodeowners legge righe "pattern owner...": "#" inizia un commento,
+// le sezioni "[nome]" di GitLab sono ignorate.
+func parseCodeowners(sc *bufio.Scanner) (*Map, error) {
+	m := &Map{}
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		r, err := newRule(fields[0], fields[1:], nil)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		m.Rules = append(m.Rules, r)
+	}
+	return m, sc.Err()
+}
+
+// parseYAML legge la mappa di ownership, nel sottoinsieme YAML di
+// yamlsubset:
+//
+//	internal/payments/:
+//	  owners: [team-payments]
+//	  tags:
+//	    - pci
+//
+// Le chiavi di primo livello sono pattern nell'ordine del file; sotto
+// ognuna sono ammessi solo "owners" e "tags", come valore singolo o lista.
+func parseYAML(text string) (*Map, error) {
+	doc, err := yamlsubset.ParseOrdered(text)
+	if err != nil || doc == nil {
+		return &Map{}, err
+	}
+	top, ok := doc.(*yamlsubset.Map)
+	if !ok {
+		return nil, fmt.Errorf("expected a mapping of patterns")
+	}
+	m := &Map{}
+	for _, pattern := range top.Keys {
+		var owners, tags []string
+		if v := top.Values[pattern]; v != nil {
+			fields, ok := v.(*yamlsubset.Map)
+			if !ok {
+				return nil, fmt.Errorf("%s: expected owners or tags", pattern)
+			}
+			for _, key := range fields.Keys {
+				var list *[]string
+				switch key {
+				case "owners":
+					list = &owners
+				case "tags":
+					list = &tags
+				default:
+					return nil, fmt.Errorf("%s: unknown key %q (want owners or tags)", pattern, key)
+				}
+				if *list, err = stringList(fields.Values[key]); err != nil {
+					return nil, fmt.Errorf("%s: %s: %w", pattern, key, err)
+				}
+			}
+		}
+		r, err := newRule(pattern, owners, tags)
+		if err != nil {
+			return nil, err
+		}
+		m.Rules = append(m.Rules, r)
+	}
+	return m, nil
+}
+
+// stringList converte un valore singolo o una lista di scalari.
+func stringList(v any) ([]string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		var out []string
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a list of strings")
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("expected a string or a list")
+}
+
+// newRule compila il pattern con la semantica di CODEOWNERS (gitignore):
+// "/" iniziale o interno ancora il pattern alla root, altrimenti
+// corrisponde a qualunque profondità; "/" finale corrisponde solo al
+// contenuto di una directory; "*" e "?" non attraversano "/", "**" sì. Un
+// pattern che corrisponde a una directory vale per tutti i file sotto di
+// essa.
+func newRule(pattern string, owners, tags []string) (*Rule, error) {
+	p := pattern
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("empty pattern %q", pattern)
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return &Rule{Pattern: pattern, Owners: owners, Tags: tags, re: re}, nil
+}
+
+// Match restituisce owners e tag del file (relativo alla root, con "/").
+func (m *Map) Match(file string) (owners, tags []string) {
+	file = strings.TrimPrefix(filepath.ToSlash(file), "./")
+	seen := make(map[string]bool)
+	for _, r := range m.Rules {
+		if !r.re.MatchString(file) {
+			continue
+		}
+		if len(r.Owners) > 0 {
+			owners = r.Owners
+		}
+		for _, t := range r.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	return owners, tags
+}
+
+// Apply popola Owners e Tags di package, tipi, metodi e callable della
+// symbol table. Un simbolo riceve owners e tag del proprio file, un package
+// l'unione di quelli dei suoi file.
+func Apply(st *schema.CLDKSymbolTable, m *Map) {
+	if st == nil || m == nil {
+		return
+	}
+	for _, pkg := range st.Packages {
+		var pkgOwners, pkgTags []string
+		for _, f := range pkg.Files {
+			o, t := m.Match(f)
+			pkgOwners = union(pkgOwners, o)
+			pkgTags = union(pkgTags, t)
+		}
+		pkg.Owners, pkg.Tags = pkgOwners, pkgTags
+
+		for _, cd := range pkg.CallableDeclarations {
+			cd.Owners, cd.Tags = m.matchPosition(cd.Position)
+		}
+		for _, td := range pkg.TypeDeclarations {
+			td.Owners, td.Tags = m.matchPosition(td.Position)
+			for _, md := range td.Methods {
+				md.Owners, md.Tags = m.matchPosition(md.Position)
+			}
+		}
+	}
+}
+
+func (m *Map) matchPosition(pos *schema.CLDKPosition) (owners, tags []string) {
+	if pos == nil || pos.File == "" {
+		return nil, nil
+	}
+	return m.Match(pos.File)
+}
+
+// union aggiunge ad a gli elementi di b non presenti, mantenendo a ordinata.
+func union(a, b []string) []string {
+	for _, s := range b {
+		i := sort.SearchStrings(a, s)
+		if i < len(a) && a[i] == s {
+			continue
+		}
+		a = append(a, "")
+		copy(a[i+1:], a[i:])
+		a[i] = s
+	}
+	return a
+}
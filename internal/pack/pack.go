@@ -0,0 +1,474 @@
+// Package pack seleziona il contesto attorno a un simbolo per un LLM: il
+// simbolo focus, il suo intorno nel call graph e i tipi collegati, ordinati
+// per rilevanza ed entro un budget di token.
+package pack
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Valori di default di Config.
+const (
+	DefaultBudget = 8000
+	DefaultDepth  = 2
+)
+
+// Pesi della rilevanza: un callee a distanza d vale calleeWeight/d, un
+// caller callerWeight/d; i tipi ereditano una frazione del simbolo che li usa.
+const (
+	calleeWeight   = 0.6
+	callerWeight   = 0.5
+	methodWeight   = 0.6 // metodi di un tipo focus
+	receiverWeight = 0.9 // tipo receiver di un metodo focus
+	focusTypeScore = 0.7 // tipi nella firma del focus
+	typeFraction   = 0.5 // tipi nella firma di un vicino
+)
+
+// Config configura la selezione.
+type Config struct {
+	Focus  string // ID del simbolo o suffisso univoco (es. "pkg/foo.Bar")
+	Budget int    // token stimati, vedi Tokens
+	Depth  int    // distanza massima nel call graph
+	Root   string // radice del progetto per leggere i sorgenti ("" = solo firme)
+	FS     fs.FS  // sorgenti con radice in Root, come LoadResult.FS (nil = dal disco)
+}
+
+// Tokens stima i token di s: circa quattro byte per token.
+func Tokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// symbol è un callable o un tipo della symbol table.
+type symbol struct {
+	id       string
+	pkg      *schema.CLDKPackage
+	callable *schema.CLDKCallable
+	typ      *schema.CLDKType
+}
+
+// candidate è un simbolo candidato con la sua rilevanza.
+type candidate struct {
+	sym      *symbol
+	relation string
+	distance int
+	score    float64
+}
+
+// Build seleziona il focus e il suo intorno. Gli elementi entrano in ordine
+// di rilevanza con firma e documentazione finché c'è budget; il budget
+// rimasto sostituisce la firma con la dichiarazione completa, a partire dal
+// focus. Il focus è sempre incluso, anche oltre il budget.
+func Build(st *schema.CLDKSymbolTable, cg *schema.CLDKCallGraph, cfg Config) (*schema.CLDKPack, error) {
+	if st == nil {
+		return nil, fmt.Errorf("pack requires a symbol table")
+	}
+	syms := index(st)
+	focus, err := resolve(syms, cfg.Focus)
+	if err != nil {
+		return nil, err
+	}
+
+	cands := map[string]*candidate{focus.id: {sym: focus, relation: "focus", score: 1}}
+	add := func(id, relation string, distance int, score float64) {
+		s := syms[id]
+		if s == nil {
+			return
+		}
+		if c := cands[id]; c != nil && c.score >= score {
+			return
+		}
+		cands[id] = &candidate{sym: s, relation: relation, distance: distance, score: score}
+	}
+
+	// Intorno nel call graph: per un tipo si parte dai suoi metodi
+	seeds := []string{focus.id}
+	if focus.typ != nil {
+		seeds = nil
+		for id := range focus.typ.Methods {
+			seeds = append(seeds, id)
+			add(id, "method", 1, methodWeight)
+		}
+		sort.Strings(seeds)
+	}
+	succ, pred := adjacency(cg, syms)
+	for id, d := range reach(succ, seeds, cfg.Depth) {
+		if d > 0 {
+			add(id, "callee", d, calleeWeight/float64(d))
+		}
+	}
+	for id, d := range reach(pred, seeds, cfg.Depth) {
+		if d > 0 {
+			add(id, "caller", d, callerWeight/float64(d))
+		}
+	}
+
+	// Tipi collegati: receiver, firma del focus o campi del tipo focus
+	if c := focus.callable; c != nil && c.ReceiverType != "" {
+		add(ids.Type(focus.pkg.Path, c.ReceiverType), "receiver", 0, receiverWeight)
+	}
+	for _, t := range relatedTypes(focus, syms) {
+		add(t, "type", 0, focusTypeScore)
+	}
+	var neighbors []*candidate
+	for _, c := range cands {
+		if c.sym.callable != nil && c.relation != "focus" {
+			neighbors = append(neighbors, c)
+		}
+	}
+	for _, c := range neighbors {
+		for _, t := range relatedTypes(c.sym, syms) {
+			add(t, "type", c.distance, c.score*typeFraction)
+		}
+	}
+
+	return pack(focus.id, cands, cfg), nil
+}
+
+// pack dispone i candidati nel budget.
+func pack(focus string, cands map[string]*candidate, cfg Config) *schema.CLDKPack {
+	order := make([]*candidate, 0, len(cands))
+	for _, c := range cands {
+		order = append(order, c)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].score != order[j].score {
+			return order[i].score > order[j].score
+		}
+		return order[i].sym.id < order[j].sym.id
+	})
+
+	// Il budget copre l'intero documento di Text: l'intestazione è stimata
+	// per eccesso (budget pieno, tutti i candidati inclusi e omessi) e ogni
+	// elemento conta anche la riga vuota che lo precede.
+	p := &schema.CLDKPack{Focus: focus, Budget: cfg.Budget}
+	p.Tokens = Tokens(header(focus, cfg.Budget, cfg.Budget, len(cands), len(cands)))
+	var items []*schema.CLDKPackItem
+	var included []*candidate
+	for _, c := range order {
+		it := newItem(c)
+		it.Tokens = itemTokens(it)
+		if c.relation != "focus" && p.Tokens+it.Tokens > cfg.Budget {
+			p.Omitted = append(p.Omitted, it.ID)
+			continue
+		}
+		p.Tokens += it.Tokens
+		items = append(items, it)
+		included = append(included, c)
+	}
+
+	// Il budget rimasto va alle dichiarazioni complete
+	for i, it := range items {
+		src := source(cfg.FS, cfg.Root, included[i].sym)
+		if src == "" {
+			continue
+		}
+		full := *it
+		full.Source = src
+		full.Tokens = itemTokens(&full)
+		if p.Tokens-it.Tokens+full.Tokens <= cfg.Budget {
+			p.Tokens += full.Tokens - it.Tokens
+			items[i] = &full
+		}
+	}
+	p.Items = make([]schema.CLDKPackItem, len(items))
+	for i, it := range items {
+		p.Items[i] = *it
+	}
+	// Tokens riporta la stima del documento effettivo, che dipende dalle
+	// cifre di Tokens stesso: si ricalcola finché non è stabile.
+	for t := Tokens(Text(p)); t != p.Tokens; t = Tokens(Text(p)) {
+		p.Tokens = t
+	}
+	return p
+}
+
+// itemTokens restituisce i token di it nel documento, riga vuota che lo
+// precede compresa.
+func itemTokens(it *schema.CLDKPackItem) int {
+	return Tokens("\n" + Render(it))
+}
+
+func newItem(c *candidate) *schema.CLDKPackItem {
+	it := &schema.CLDKPackItem{
+		ID:       c.sym.id,
+		Relation: c.relation,
+		Distance: c.distance,
+		Score:    math.Round(c.score*1000) / 1000,
+	}
+	if cd := c.sym.callable; cd != nil {
+		it.Kind = cd.Kind
+		it.Signature = cd.Signature
+		it.Doc = cd.Documentation
+		it.Position = cd.Position
+	} else {
+		t := c.sym.typ
+		it.Kind = t.Kind
+		it.Signature = typeSignature(t)
+		it.Doc = t.Documentation
+		it.Position = t.Position
+	}
+	return it
+}
+
+// source legge dal sorgente la dichiarazione di s, senza doc comment: da
+// "func" alla "}" finale per i callable, da "type" alla fine per i tipi.
+func source(fsys fs.FS, root string, s *symbol) string {
+	if root == "" {
+		return ""
+	}
+	if cd := s.callable; cd != nil {
+		return readSpan(fsys, root, cd.Position, cd.EndPosition)
+	}
+	if src := readSpan(fsys, root, s.typ.Position, s.typ.EndPosition); src != "" {
+		return "type " + src
+	}
+	return ""
+}
+
+// Render restituisce il testo di un elemento nel documento: intestazione,
+// documentazione come commento e dichiarazione (o firma).
+func Render(it *schema.CLDKPackItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s %s", it.Relation, it.ID)
+	if it.Distance > 0 {
+		fmt.Fprintf(&b, " (distance %d)", it.Distance)
+	}
+	b.WriteString("\n")
+	if it.Doc != "" {
+		for _, line := range strings.Split(it.Doc, "\n") {
+			b.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+		}
+	}
+	if it.Source != "" {
+		b.WriteString(it.Source)
+	} else {
+		b.WriteString(it.Signature)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Text restituisce il documento del pack: gli elementi in ordine di
+// rilevanza separati da una riga vuota.
+func Text(p *schema.CLDKPack) string {
+	var b strings.Builder
+	b.WriteString(header(p.Focus, p.Tokens, p.Budget, len(p.Items), len(p.Omitted)))
+	for i := range p.Items {
+		b.WriteString("\n")
+		b.WriteString(Render(&p.Items[i]))
+	}
+	return b.String()
+}
+
+// ── Simboli ────────────────────────────────────────────────────────────────
+
+// index raccoglie callable e tipi della symbol table per ID.
+func index(st *schema.CLDKSymbolTable) map[string]*symbol {
+	syms := make(map[string]*symbol)
+	for _, pkg := range st.Packages {
+		for id, cd := range pkg.CallableDeclarations {
+			syms[id] = &symbol{id: id, pkg: pkg, callable: cd}
+		}
+		for id, t := range pkg.TypeDeclarations {
+			syms[id] = &symbol{id: id, pkg: pkg, typ: t}
+		}
+	}
+	return syms
+}
+
+// resolve trova il simbolo per ID esatto o per suffisso univoco dopo "/" o
+// ".".
+func resolve(syms map[string]*symbol, name string) (*symbol, error) {
+	if s, ok := syms[name]; ok {
+		return s, nil
+	}
+	var matches []string
+	for id := range syms {
+		if strings.HasSuffix(id, "."+name) || strings.HasSuffix(id, "/"+name) {
+			matches = append(matches, id)
+		}
+	}
+	sort.Strings(matches)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("symbol %q not found", name)
+	case 1:
+		return syms[matches[0]], nil
+	default:
+		if len(matches) > 5 {
+			matches = append(matches[:5], "...")
+		}
+		return nil, fmt.Errorf("symbol %q is ambiguous: %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// adjacency riduce il call graph ai callable della symbol table: le closure
+// e i wrapper confluiscono nella dichiarazione tramite SymbolRef.
+func adjacency(cg *schema.CLDKCallGraph, syms map[string]*symbol) (succ, pred map[string][]string) {
+	succ, pred = make(map[string][]string), make(map[string][]string)
+	if cg == nil {
+		return succ, pred
+	}
+	ref := make(map[string]string, len(cg.Nodes))
+	for _, n := range cg.Nodes {
+		id := n.SymbolRef
+		if id == "" {
+			id = n.ID
+		}
+		if s := syms[id]; s != nil && s.callable != nil {
+			ref[n.ID] = id
+		}
+	}
+	seen := make(map[[2]string]bool)
+	for _, e := range cg.Edges {
+		src, dst := ref[e.Source], ref[e.Target]
+		if src == "" || dst == "" || src == dst || seen[[2]string{src, dst}] {
+			continue
+		}
+		seen[[2]string{src, dst}] = true
+		succ[src] = append(succ[src], dst)
+		pred[dst] = append(pred[dst], src)
+	}
+	return succ, pred
+}
+
+// reach restituisce la distanza minima dai seed entro depth archi.
+func reach(adj map[string][]string, seeds []string, depth int) map[string]int {
+	dist := make(map[string]int, len(seeds))
+	queue := append([]string(nil), seeds...)
+	for _, s := range seeds {
+		dist[s] = 0
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if dist[n] >= depth {
+			continue
+		}
+		for _, m := range adj[n] {
+			if _, ok := dist[m]; !ok {
+				dist[m] = dist[n] + 1
+				queue = append(queue, m)
+			}
+		}
+	}
+	return dist
+}
+
+var typeIdent = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?`)
+
+// relatedTypes restituisce i tipi del progetto nominati nei parametri e
+// nei risultati di un callable o nei campi di una struct, risolvendo i
+// qualificatori con gli import del package.
+func relatedTypes(s *symbol, syms map[string]*symbol) []string {
+	var exprs []string
+	if cd := s.callable; cd != nil {
+		for _, p := range cd.Parameters {
+			exprs = append(exprs, p.Type)
+		}
+		for _, p := range cd.Results {
+			exprs = append(exprs, p.Type)
+		}
+	} else {
+		for _, f := range s.typ.Fields {
+			exprs = append(exprs, f.Type)
+		}
+	}
+	imports := make(map[string]string)
+	for _, imp := range s.pkg.Imports {
+		name := imp.Alias
+		if name == "" {
+			name = path.Base(imp.Path)
+		}
+		imports[name] = imp.Path
+	}
+	var out []string
+	seen := map[string]bool{s.id: true}
+	for _, e := range exprs {
+		for _, m := range typeIdent.FindAllString(e, -1) {
+			id := ids.Type(s.pkg.Path, m)
+			if pkgName, name, ok := strings.Cut(m, "."); ok {
+				id = ids.Type(imports[pkgName], name)
+			}
+			if t := syms[id]; t != nil && t.typ != nil && !seen[id] {
+				seen[id] = true
+				out = append(out, id)
+			}
+		}
+	}
+	return out
+}
+
+// typeSignature riassume un tipo su una riga: campi delle struct, metodi
+// delle interfacce, tipo sottostante per gli altri.
+func typeSignature(t *schema.CLDKType) string {
+	var parts []string
+	switch t.Kind {
+	case "struct":
+		for _, f := range t.Fields {
+			if f.Embedded {
+				parts = append(parts, f.Type)
+			} else {
+				parts = append(parts, f.Name+" "+f.Type)
+			}
+		}
+		return "type " + t.Name + " struct { " + strings.Join(parts, "; ") + " }"
+	case "interface":
+		parts = append(parts, t.EmbeddedTypes...)
+		for _, m := range t.InterfaceMethods {
+			parts = append(parts, strings.TrimPrefix(m.Signature, "func "))
+		}
+		return "type " + t.Name + " interface { " + strings.Join(parts, "; ") + " }"
+	case "alias":
+		return "type " + t.Name + " = " + t.UnderlyingType
+	}
+	return strings.TrimSpace("type " + t.Name + " " + t.UnderlyingType)
+}
+
+// readSpan legge il testo da start a end (esclusa) di un file sotto root,
+// attraverso fsys.
+func readSpan(fsys fs.FS, root string, start, end *schema.CLDKPosition) string {
+	if start == nil || end == nil || start.File != end.File {
+		return ""
+	}
+	data, err := loader.ReadFile(fsys, root, filepath.Join(root, filepath.FromSlash(start.File)))
+	if err != nil {
+		return ""
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	offset := func(p *schema.CLDKPosition) int {
+		if p.StartLine < 1 || p.StartLine > len(lines) {
+			return -1
+		}
+		n := 0
+		for _, l := range lines[:p.StartLine-1] {
+			n += len(l)
+		}
+		return n + p.StartColumn - 1
+	}
+	from, to := offset(start), offset(end)
+	if from < 0 || to < from || to > len(data) {
+		return ""
+	}
+	return string(data[from:to])
+}
+
+// header restituisce la riga di intestazione del documento.
+func header(focus string, tokens, budget, items, omitted int) string {
+	s := fmt.Sprintf("// pack %s: %d/%d tokens, %d symbols", focus, tokens, budget, items)
+	if omitted > 0 {
+		s += fmt.Sprintf(", %d omitted", omitted)
+	}
+	return s + "\n"
+}
@@ -0,0 +1,169 @@
+// Package passes esegue analyzer standard di golang.org/x/tools/go/analysis
+// (nilness, shadow, unusedresult, ...) sui package caricati e ne converte
+// i diagnostic in issue. Il driver è go/analysis/checker: i package del
+// loader hanno già sintassi e type info per tutte le dipendenze, come
+// richiesto dagli analyzer che usano i fact.
+package passes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/analysis/passes/appends"
+	"golang.org/x/tools/go/analysis/passes/assign"
+	"golang.org/x/tools/go/analysis/passes/atomic"
+	"golang.org/x/tools/go/analysis/passes/bools"
+	"golang.org/x/tools/go/analysis/passes/copylock"
+	"golang.org/x/tools/go/analysis/passes/defers"
+	"golang.org/x/tools/go/analysis/passes/errorsas"
+	"golang.org/x/tools/go/analysis/passes/httpresponse"
+	"golang.org/x/tools/go/analysis/passes/ifaceassert"
+	"golang.org/x/tools/go/analysis/passes/lostcancel"
+	"golang.org/x/tools/go/analysis/passes/nilfunc"
+	"golang.org/x/tools/go/analysis/passes/nilness"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/shadow"
+	"golang.org/x/tools/go/analysis/passes/shift"
+	"golang.org/x/tools/go/analysis/passes/sortslice"
+	"golang.org/x/tools/go/analysis/passes/stringintconv"
+	"golang.org/x/tools/go/analysis/passes/structtag"
+	"golang.org/x/tools/go/analysis/passes/unreachable"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+	"golang.org/x/tools/go/analysis/passes/unusedwrite"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// CodeError è il codice dell'issue emesso quando un analyzer fallisce.
+const CodeError = "PASS_ERROR"
+
+// registry elenca gli analyzer selezionabili con --passes, per nome.
+var registry = map[string]*analysis.Analyzer{}
+
+func init() {
+	for _, a := range []*analysis.Analyzer{
+		appends.Analyzer,
+		assign.Analyzer,
+		atomic.Analyzer,
+		bools.Analyzer,
+		copylock.Analyzer,
+		defers.Analyzer,
+		errorsas.Analyzer,
+		httpresponse.Analyzer,
+		ifaceassert.Analyzer,
+		lostcancel.Analyzer,
+		nilfunc.Analyzer,
+		nilness.Analyzer,
+		printf.Analyzer,
+		shadow.Analyzer,
+		shift.Analyzer,
+		sortslice.Analyzer,
+		stringintconv.Analyzer,
+		structtag.Analyzer,
+		unreachable.Analyzer,
+		unusedresult.Analyzer,
+		unusedwrite.Analyzer,
+	} {
+		registry[a.Name] = a
+	}
+}
+
+// Names restituisce i nomi degli analyzer disponibili, ordinati.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup risolve i nomi indicati negli analyzer corrispondenti, ignorando
+// i duplicati. Un nome sconosciuto è un errore.
+func Lookup(names []string) ([]*analysis.Analyzer, error) {
+	var out []*analysis.Analyzer
+	seen := make(map[string]bool)
+	for _, name := range names {
+		a, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown pass: %s (valid: %s)", name, strings.Join(Names(), ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// Code restituisce il codice degli issue prodotti dall'analyzer indicato,
+// es. VET_NILNESS.
+func Code(name string) string {
+	return "VET_" + strings.ToUpper(name)
+}
+
+// Run esegue gli analyzer sui package del progetto e restituisce un issue
+// di livello warning per ogni diagnostic, ordinati per posizione. Con
+// --files sono riportati solo i diagnostic nei file richiesti. I package
+// con errori di caricamento sono saltati: gli errori sono già riportati
+// dal loader.
+func Run(result *loader.LoadResult, analyzers []*analysis.Analyzer) ([]schema.Issue, error) {
+	var roots []*packages.Package
+	for _, pkg := range result.Packages {
+		if pkg != nil && len(pkg.Errors) == 0 && !pkg.IllTyped {
+			roots = append(roots, pkg)
+		}
+	}
+	if len(roots) == 0 || len(analyzers) == 0 {
+		return nil, nil
+	}
+
+	graph, err := checker.Analyze(analyzers, roots, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []schema.Issue
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			issues = append(issues, schema.Issue{
+				Severity: "warning",
+				Code:     CodeError,
+				Message:  fmt.Sprintf("%s: %s: %v", act.Analyzer.Name, act.Package.PkgPath, act.Err),
+			})
+			continue
+		}
+		for _, d := range act.Diagnostics {
+			pos := result.Fset.Position(d.Pos)
+			if result.Files != nil && !result.Files[pos.Filename] {
+				continue
+			}
+			issues = append(issues, schema.Issue{
+				Severity: "warning",
+				Code:     Code(act.Analyzer.Name),
+				Message:  d.Message,
+				Position: result.Position(d.Pos),
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		pi, pj := issues[i].Position, issues[j].Position
+		if pi == nil || pj == nil {
+			return pj != nil
+		}
+		if pi.File != pj.File {
+			return pi.File < pj.File
+		}
+		if pi.StartLine != pj.StartLine {
+			return pi.StartLine < pj.StartLine
+		}
+		return pi.StartColumn < pj.StartColumn
+	})
+	return issues, nil
+}
@@ -7,24 +7,25 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
-	"strings"
 
 	"golang.org/x/tools/go/ssa"
 
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
 	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
 )
 
 // Config configura la costruzione del PDG.
 type Config struct {
-	EmitPositions string   // detailed|minimal
-	OnlyPkg       []string // filtra per sottostringa nel path
+	EmitPositions string            // detailed|minimal
+	Packages      *pkgfilter.Filter // filtra i package (--only-pkg, --match-pkg, --exclude-pkg)
 }
 
 // Build costruisce il PDG per tutte le funzioni dei pacchetti caricati.
 func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKPDG, error) {
 	if result.SSAProgram == nil {
-		return nil, fmt.Errorf("SSAProgram is nil, call LoadWithSSA with NeedSSA=true")
+		return nil, fmt.Errorf("SSAProgram is nil, call loader.Load with NeedSSA=true")
 	}
 
 	pdg := &schema.CLDKPDG{
@@ -38,18 +39,8 @@ func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKPDG, error) {
 
 		pkgPath := ssaPkg.Pkg.Path()
 
-		// Filtra per onlyPkg
-		if len(cfg.OnlyPkg) > 0 {
-			keep := false
-			for _, s := range cfg.OnlyPkg {
-				if s != "" && strings.Contains(pkgPath, s) {
-					keep = true
-					break
-				}
-			}
-			if !keep {
-				continue
-			}
+		if !cfg.Packages.Allows(pkgPath) {
+			continue
 		}
 
 		// Processa ogni funzione del pacchetto
@@ -83,7 +74,7 @@ func buildFunctionPDG(fn *ssa.Function, pkgPath string, result *loader.LoadResul
 		return // funzioni senza body (es. built-in, abstract)
 	}
 
-	fid := stableFuncID(fn)
+	fid := ids.SSAFunc(fn)
 	if fid == "" {
 		return
 	}
@@ -498,50 +489,6 @@ func instrString(instr ssa.Instruction) string {
 	return s
 }
 
-// stableFuncID genera un ID stabile per una funzione SSA.
-// Formato: pkgpath.Func o pkgpath.(*Type).Method
-func stableFuncID(f *ssa.Function) string {
-	if f == nil {
-		return ""
-	}
-
-	// Builtins e funzioni senza package
-	if f.Pkg == nil || f.Pkg.Pkg == nil {
-		if f.Name() != "" {
-			return f.Name()
-		}
-		return f.String()
-	}
-
-	pkg := f.Pkg.Pkg.Path()
-	name := f.Name()
-
-	// Receiver per metodi
-	if f.Signature != nil && f.Signature.Recv() != nil {
-		r := f.Signature.Recv()
-		t := r.Type().String()
-		t = normalizeReceiverType(t, pkg)
-		return fmt.Sprintf("%s.%s.%s", pkg, t, name)
-	}
-
-	return fmt.Sprintf("%s.%s", pkg, name)
-}
-
-// normalizeReceiverType normalizza il tipo receiver per l'ID.
-func normalizeReceiverType(t, pkg string) string {
-	if strings.HasPrefix(t, "*") {
-		inner := t[1:]
-		if idx := strings.LastIndex(inner, "."); idx >= 0 {
-			inner = inner[idx+1:]
-		}
-		return "(*" + inner + ")"
-	}
-	if idx := strings.LastIndex(t, "."); idx >= 0 {
-		return t[idx+1:]
-	}
-	return t
-}
-
 // extractCallTarget estrae il qualified name del target di una call instruction.
 // Restituisce "" per call non risolvibili (es. closure, interface dispatch).
 func extractCallTarget(instr ssa.Instruction) string {
@@ -564,7 +511,7 @@ func extractCallTarget(instr ssa.Instruction) string {
 
 	// Static call: la funzione è nota a compile time
 	if fn := common.StaticCallee(); fn != nil {
-		return stableFuncID(fn)
+		return ids.SSAFunc(fn)
 	}
 
 	// Dynamic call (interface dispatch, function value): non risolvibile staticamente
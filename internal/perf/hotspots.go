@@ -0,0 +1,175 @@
+// Package perf rileva hotspot di allocazione con euristiche sull'SSA
+// (senza invocare il compilatore con -gcflags=-m).
+package perf
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/graph"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Codici degli issue emessi.
+const (
+	CodeAllocInLoop      = "ALLOC_IN_LOOP"
+	CodeConcatInLoop     = "STRING_CONCAT_IN_LOOP"
+	CodeCapturingClosure = "CAPTURING_CLOSURE"
+)
+
+// Config configura la ricerca degli hotspot.
+type Config struct {
+	Packages *pkgfilter.Filter // filtra i package (--only-pkg, --match-pkg, --exclude-pkg)
+}
+
+// Hotspots analizza tutte le funzioni dei pacchetti caricati e restituisce
+// un issue di livello info per ogni pattern di allocazione rilevato:
+// allocazioni heap (composite literal, new, make) dentro cicli,
+// concatenazione di stringhe dentro cicli e closure che catturano variabili.
+func Hotspots(result *loader.LoadResult, cfg Config) ([]schema.Issue, error) {
+	if result.SSAProgram == nil {
+		return nil, fmt.Errorf("SSAProgram is nil, call loader.Load with NeedSSA=true")
+	}
+
+	var issues []schema.Issue
+	seen := make(map[*ssa.Function]bool)
+
+	var visit func(fn *ssa.Function)
+	visit = func(fn *ssa.Function) {
+		if fn == nil || seen[fn] || len(fn.Blocks) == 0 {
+			return
+		}
+		seen[fn] = true
+		issues = append(issues, analyzeFunction(fn, result)...)
+		for _, anon := range fn.AnonFuncs {
+			visit(anon)
+		}
+	}
+
+	for _, ssaPkg := range result.SSAPackages {
+		if ssaPkg == nil || ssaPkg.Pkg == nil || !cfg.Packages.Allows(ssaPkg.Pkg.Path()) {
+			continue
+		}
+		for _, member := range ssaPkg.Members {
+			switch m := member.(type) {
+			case *ssa.Function:
+				visit(m)
+			case *ssa.Type:
+				for _, t := range []types.Type{m.Type(), types.NewPointer(m.Type())} {
+					mset := result.SSAProgram.MethodSets.MethodSet(t)
+					for i := 0; i < mset.Len(); i++ {
+						if fn := result.SSAProgram.MethodValue(mset.At(i)); fn != nil && fn.Pkg == ssaPkg {
+							visit(fn)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		pi, pj := issues[i].Position, issues[j].Position
+		if pi == nil || pj == nil {
+			return pj != nil
+		}
+		if pi.File != pj.File {
+			return pi.File < pj.File
+		}
+		return pi.StartLine < pj.StartLine
+	})
+	return issues, nil
+}
+
+// analyzeFunction applica le euristiche a una singola funzione.
+func analyzeFunction(fn *ssa.Function, result *loader.LoadResult) []schema.Issue {
+	var issues []schema.Issue
+	inLoop := loopBlocks(fn)
+	name := fn.String()
+
+	report := func(code string, pos token.Pos, msg string) {
+		issues = append(issues, schema.Issue{
+			Severity: "info",
+			Code:     code,
+			Message:  fmt.Sprintf("%s: %s", name, msg),
+			Position: result.Position(pos),
+		})
+	}
+
+	for _, b := range fn.Blocks {
+		loop := inLoop[b]
+		for _, instr := range b.Instrs {
+			switch x := instr.(type) {
+			case *ssa.Alloc:
+				if loop && x.Heap {
+					report(CodeAllocInLoop, x.Pos(), fmt.Sprintf("heap allocation of %s inside loop", types.TypeString(deref(x.Type()), nil)))
+				}
+			case *ssa.MakeSlice:
+				if loop {
+					report(CodeAllocInLoop, x.Pos(), "make(slice) inside loop")
+				}
+			case *ssa.MakeMap:
+				if loop {
+					report(CodeAllocInLoop, x.Pos(), "make(map) inside loop")
+				}
+			case *ssa.BinOp:
+				if loop && x.Op == token.ADD && isString(x.Type()) {
+					report(CodeConcatInLoop, x.Pos(), "string concatenation inside loop (consider strings.Builder)")
+				}
+			case *ssa.MakeClosure:
+				if len(x.Bindings) > 0 {
+					msg := fmt.Sprintf("closure captures %d variable(s)", len(x.Bindings))
+					if loop {
+						msg += " inside loop"
+					}
+					pos := x.Pos()
+					if !pos.IsValid() {
+						pos = x.Fn.Pos()
+					}
+					report(CodeCapturingClosure, pos, msg)
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// loopBlocks restituisce i blocchi che appartengono a un ciclo del CFG
+// (componenti fortemente connesse non banali).
+func loopBlocks(fn *ssa.Function) map[*ssa.BasicBlock]bool {
+	ids := make([]string, len(fn.Blocks))
+	adj := make(map[string][]string, len(fn.Blocks))
+	for i, b := range fn.Blocks {
+		ids[i] = strconv.Itoa(b.Index)
+		for _, s := range b.Succs {
+			adj[ids[i]] = append(adj[ids[i]], strconv.Itoa(s.Index))
+		}
+	}
+
+	out := make(map[*ssa.BasicBlock]bool)
+	for _, comp := range graph.Cycles(ids, adj) {
+		for _, id := range comp {
+			i, _ := strconv.Atoi(id)
+			out[fn.Blocks[i]] = true
+		}
+	}
+	return out
+}
+
+func deref(t types.Type) types.Type {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		return p.Elem()
+	}
+	return t
+}
+
+func isString(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsString != 0
+}
@@ -0,0 +1,125 @@
+// Package pkgfilter seleziona i package per import path. È il filtro unico
+// di --only-pkg, --match-pkg e --exclude-pkg, usato allo stesso modo da
+// loader, call graph, PDG, summary e hotspot.
+package pkgfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Filter decide quali import path includere. Un package è incluso se
+// contiene una delle sottostringhe di only (se presenti), corrisponde a uno
+// dei pattern di match (se presenti) e a nessuno di quelli di exclude. Il
+// Filter nil include tutto.
+type Filter struct {
+	only    []string
+	match   []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// New costruisce il filtro; voci vuote sono ignorate e senza criteri
+// restituisce nil. I pattern di match ed exclude sono glob sull'intero
+// import path ("*" non attraversa "/", "**" e "..." sì), oppure
+// espressioni regolari non ancorate se prefissati da "re:".
+func New(only, match, exclude []string) (*Filter, error) {
+	f := &Filter{}
+	for _, s := range only {
+		if s = strings.TrimSpace(s); s != "" {
+			f.only = append(f.only, s)
+		}
+	}
+	var err error
+	if f.match, err = compileAll(match); err != nil {
+		return nil, fmt.Errorf("invalid --match-pkg pattern: %w", err)
+	}
+	if f.exclude, err = compileAll(exclude); err != nil {
+		return nil, fmt.Errorf("invalid --exclude-pkg pattern: %w", err)
+	}
+	if len(f.only) == 0 && len(f.match) == 0 && len(f.exclude) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// Allows riporta se il package con questo import path è incluso.
+func (f *Filter) Allows(pkgPath string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.only) > 0 && !containsAny(pkgPath, f.only) {
+		return false
+	}
+	if len(f.match) > 0 && !matchesAny(pkgPath, f.match) {
+		return false
+	}
+	return !matchesAny(pkgPath, f.exclude)
+}
+
+// Empty riporta se il filtro non esclude nulla.
+func (f *Filter) Empty() bool {
+	return f == nil
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(s string, res []*regexp.Regexp) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	var out []*regexp.Regexp
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		re, err := Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// Compile traduce un pattern di package in espressione regolare: "re:" la
+// usa così com'è, altrimenti il glob è ancorato all'intero path.
+func Compile(p string) (*regexp.Regexp, error) {
+	if expr, ok := strings.CutPrefix(p, "re:"); ok {
+		return regexp.Compile(expr)
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case strings.HasPrefix(p[i:], "..."):
+			b.WriteString(".*")
+			i += 2
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,506 @@
+// Package purity stabilisce sull'SSA quali funzioni del progetto sono pure
+// (nessuna variabile globale, nessuna scrittura su memoria non allocata
+// dalla funzione, nessun I/O, risultato deterministico) e quali, tra
+// queste, sono piegabili a costante: dipendono solo dai loro argomenti di
+// tipo base.
+package purity
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// DefaultDepth è la profondità di default delle chiamate seguite.
+const DefaultDepth = 3
+
+// maxReasons limita i motivi elencati per funzione.
+const maxReasons = 5
+
+// Config configura l'analisi.
+type Config struct {
+	// Depth è il numero di livelli di chiamata a funzioni del progetto (o
+	// delle dipendenze) seguiti; una chiamata oltre il limite rende la
+	// funzione non pura. Le closure non consumano profondità.
+	Depth    int
+	Packages *pkgfilter.Filter // filtra i package (--only-pkg, --match-pkg, --exclude-pkg)
+}
+
+// purePackages sono i package della libreria standard le cui funzioni
+// package-level non hanno effetti collaterali.
+var purePackages = map[string]bool{
+	"bytes":         true,
+	"cmp":           true,
+	"math":          true,
+	"math/bits":     true,
+	"math/cmplx":    true,
+	"path":          true,
+	"strconv":       true,
+	"strings":       true,
+	"unicode":       true,
+	"unicode/utf16": true,
+	"unicode/utf8":  true,
+}
+
+// pureFuncs sono le altre funzioni pure della libreria standard.
+var pureFuncs = map[string]bool{
+	"errors.New":               true,
+	"errors.Is":                true,
+	"errors.Join":              true,
+	"errors.Unwrap":            true,
+	"fmt.Errorf":               true,
+	"fmt.Sprint":               true,
+	"fmt.Sprintf":              true,
+	"fmt.Sprintln":             true,
+	"path/filepath.Base":       true,
+	"path/filepath.Clean":      true,
+	"path/filepath.Dir":        true,
+	"path/filepath.Ext":        true,
+	"path/filepath.FromSlash":  true,
+	"path/filepath.IsAbs":      true,
+	"path/filepath.Join":       true,
+	"path/filepath.Match":      true,
+	"path/filepath.Rel":        true,
+	"path/filepath.Split":      true,
+	"path/filepath.ToSlash":    true,
+	"path/filepath.VolumeName": true,
+	"slices.Compare":           true,
+	"slices.Contains":          true,
+	"slices.Equal":             true,
+	"slices.Index":             true,
+	"slices.Max":               true,
+	"slices.Min":               true,
+}
+
+// writesArgument sono le funzioni dei purePackages che scrivono nello
+// slice ricevuto come primo argomento.
+var writesArgument = map[string]bool{
+	"strconv.AppendBool":       true,
+	"strconv.AppendFloat":      true,
+	"strconv.AppendInt":        true,
+	"strconv.AppendQuote":      true,
+	"strconv.AppendQuoteRune":  true,
+	"strconv.AppendUint":       true,
+	"unicode/utf8.AppendRune":  true,
+	"unicode/utf8.EncodeRune":  true,
+	"unicode/utf16.AppendRune": true,
+}
+
+// localBuffers sono i tipi della libreria standard i cui metodi (salvo
+// ReadFrom e WriteTo) sono puri quando il receiver è allocato dalla
+// funzione stessa.
+var localBuffers = map[string]bool{
+	"strings.Builder": true,
+	"bytes.Buffer":    true,
+}
+
+// verdict è il risultato dell'analisi di una funzione.
+type verdict struct {
+	reasons []string // perché non è pura
+	reads   bool     // legge memoria non allocata dalla funzione
+	assumed bool     // dipende da una funzione ricorsiva ancora in analisi
+	limited int      // motivi dovuti solo al limite di profondità
+}
+
+func (v *verdict) impure(format string, args ...interface{}) {
+	r := fmt.Sprintf(format, args...)
+	if len(v.reasons) >= maxReasons {
+		return
+	}
+	for _, have := range v.reasons {
+		if have == r {
+			return
+		}
+	}
+	v.reasons = append(v.reasons, r)
+}
+
+// limit registra una chiamata a name il cui effetto non è stato verificato
+// per il limite di profondità.
+func (v *verdict) limit(name string) {
+	n := len(v.reasons)
+	v.impure("calls %s beyond the depth limit", name)
+	if len(v.reasons) > n {
+		v.limited++
+	}
+}
+
+type memoKey struct {
+	fn    *ssa.Function
+	depth int
+}
+
+type analyzer struct {
+	project map[string]bool
+	memo    map[memoKey]*verdict
+	active  map[*ssa.Function]bool
+}
+
+// Analyze classifica le funzioni e i metodi dichiarati nei package del
+// progetto e restituisce la loro purezza indicizzata per ID (la chiave in
+// callable_declarations).
+func Analyze(result *loader.LoadResult, cfg Config) (map[string]*schema.CLDKPurity, error) {
+	if result.SSAProgram == nil {
+		return nil, fmt.Errorf("SSAProgram is nil, call loader.Load with NeedSSA=true")
+	}
+	a := &analyzer{
+		project: result.ProjectPackages(),
+		memo:    make(map[memoKey]*verdict),
+		active:  make(map[*ssa.Function]bool),
+	}
+
+	out := make(map[string]*schema.CLDKPurity)
+	add := func(fn *ssa.Function) {
+		obj, ok := fn.Object().(*types.Func)
+		if !ok || fn.Synthetic != "" || len(fn.Blocks) == 0 {
+			return
+		}
+		v := a.check(fn, cfg.Depth)
+		p := &schema.CLDKPurity{Pure: len(v.reasons) == 0, Reasons: v.reasons}
+		p.ConstantFoldable = p.Pure && !v.reads && basicSignature(fn.Signature)
+		out[ids.Object(obj)] = p
+	}
+	for _, ssaPkg := range result.SSAPackages {
+		if ssaPkg == nil || ssaPkg.Pkg == nil || !cfg.Packages.Allows(ssaPkg.Pkg.Path()) {
+			continue
+		}
+		for _, member := range ssaPkg.Members {
+			switch m := member.(type) {
+			case *ssa.Function:
+				add(m)
+			case *ssa.Type:
+				for _, t := range []types.Type{m.Type(), types.NewPointer(m.Type())} {
+					mset := result.SSAProgram.MethodSets.MethodSet(t)
+					for i := 0; i < mset.Len(); i++ {
+						if fn := result.SSAProgram.MethodValue(mset.At(i)); fn != nil && fn.Pkg == ssaPkg {
+							add(fn)
+						}
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// Apply assegna la purezza calcolata da Analyze ai callable e ai metodi
+// della symbol table.
+func Apply(st *schema.CLDKSymbolTable, purity map[string]*schema.CLDKPurity) {
+	if st == nil {
+		return
+	}
+	for _, pkg := range st.Packages {
+		for id, c := range pkg.CallableDeclarations {
+			c.Purity = purity[id]
+		}
+		for _, t := range pkg.TypeDeclarations {
+			for _, m := range t.Methods {
+				m.Purity = purity[m.QualifiedName]
+			}
+		}
+	}
+}
+
+// check analizza fn seguendo le chiamate fino a depth livelli. Le
+// ricorsioni sono assunte pure finché la funzione è in analisi: il
+// risultato, che dipende dall'assunzione, non è memorizzato.
+func (a *analyzer) check(fn *ssa.Function, depth int) *verdict {
+	if a.active[fn] {
+		return &verdict{assumed: true}
+	}
+	key := memoKey{fn, depth}
+	if v, ok := a.memo[key]; ok {
+		return v
+	}
+	a.active[fn] = true
+	defer delete(a.active, fn)
+
+	v := &verdict{}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			a.instr(instr, depth, v)
+		}
+	}
+	if !v.assumed {
+		a.memo[key] = v
+	}
+	return v
+}
+
+// instr registra gli effetti di un'istruzione.
+func (a *analyzer) instr(instr ssa.Instruction, depth int, v *verdict) {
+	var ops [8]*ssa.Value
+	for _, op := range instr.Operands(ops[:0]) {
+		if op == nil {
+			continue
+		}
+		if g, ok := (*op).(*ssa.Global); ok {
+			v.impure("uses global %s", g.RelString(nil))
+		}
+	}
+
+	switch x := instr.(type) {
+	case *ssa.Store:
+		// le scritture su una globale sono già riportate come uso
+		if d := describe(x.Addr); !local(x.Addr) && !strings.HasPrefix(d, "global ") {
+			v.impure("writes %s", d)
+		}
+	case *ssa.MapUpdate:
+		if !local(x.Map) {
+			v.impure("writes map %s", describe(x.Map))
+		}
+	case *ssa.Go:
+		v.impure("starts a goroutine")
+	case *ssa.Send:
+		v.impure("sends on a channel")
+	case *ssa.Select:
+		v.impure("selects on channels")
+	case *ssa.UnOp:
+		switch x.Op {
+		case token.ARROW:
+			v.impure("receives from a channel")
+		case token.MUL:
+			if !local(x.X) {
+				v.reads = true
+			}
+		}
+	case *ssa.Lookup:
+		if !local(x.X) {
+			v.reads = true
+		}
+	case *ssa.Next:
+		if !x.IsString {
+			v.impure("iterates over a map (random order)")
+		}
+	case *ssa.Field, *ssa.Index:
+		// valori, nessun accesso alla memoria
+	case ssa.CallInstruction:
+		a.call(x.Common(), depth, v)
+	}
+}
+
+// call registra gli effetti di una chiamata.
+func (a *analyzer) call(c *ssa.CallCommon, depth int, v *verdict) {
+	if c.IsInvoke() {
+		v.impure("calls interface method %s", c.Method.Name())
+		return
+	}
+	if b, ok := c.Value.(*ssa.Builtin); ok {
+		switch b.Name() {
+		case "print", "println":
+			v.impure("prints")
+		case "copy", "append", "clear", "delete":
+			if len(c.Args) > 0 && !local(c.Args[0]) {
+				v.impure("%s writes %s", b.Name(), describe(c.Args[0]))
+			}
+		}
+		return
+	}
+	callee := c.StaticCallee()
+	if callee == nil {
+		v.impure("calls a function value")
+		return
+	}
+
+	origin := callee
+	if o := callee.Origin(); o != nil {
+		origin = o
+	}
+	name := calleeName(origin)
+	if pkg := pkgPath(origin); stdlib(pkg) && !a.project[pkg] {
+		if !a.pureStdlib(origin, c) {
+			v.impure("calls %s", name)
+		}
+		return
+	}
+
+	if len(callee.Blocks) == 0 {
+		v.impure("calls %s (no body)", name)
+		return
+	}
+	next := depth
+	if callee.Parent() == nil {
+		if depth == 0 {
+			v.limit(name)
+			return
+		}
+		next = depth - 1
+	}
+	cv := a.check(callee, next)
+	switch {
+	case len(cv.reasons) > 0 && cv.limited == len(cv.reasons):
+		v.limit(name)
+	case len(cv.reasons) > 0:
+		v.impure("calls impure %s", name)
+	}
+	v.reads = v.reads || cv.reads
+	v.assumed = v.assumed || cv.assumed
+}
+
+// pureStdlib riporta se la funzione della libreria standard chiamata da c
+// è pura.
+func (a *analyzer) pureStdlib(fn *ssa.Function, c *ssa.CallCommon) bool {
+	obj, ok := fn.Object().(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := obj.Type().(*types.Signature)
+	if sig.Recv() == nil {
+		name := obj.FullName()
+		if writesArgument[name] {
+			return len(c.Args) > 0 && local(c.Args[0])
+		}
+		return pureFuncs[name] || purePackages[obj.Pkg().Path()]
+	}
+	// metodi di strings.Builder e bytes.Buffer su un receiver locale
+	recv := sig.Recv().Type()
+	if p, ok := recv.(*types.Pointer); ok {
+		recv = p.Elem()
+	}
+	n, ok := types.Unalias(recv).(*types.Named)
+	if !ok || !localBuffers[obj.Pkg().Path()+"."+n.Obj().Name()] || len(c.Args) == 0 {
+		return false
+	}
+	if obj.Name() == "ReadFrom" || obj.Name() == "WriteTo" {
+		return false // leggono o scrivono un io.Reader/io.Writer
+	}
+	return local(c.Args[0])
+}
+
+// local riporta se v è (o deriva da) memoria allocata dalla funzione:
+// variabili locali, composite literal, make e slice ottenuti da essi.
+// Un puntatore letto dalla memoria non è locale.
+func local(v ssa.Value) bool {
+	return localSeen(v, make(map[ssa.Value]bool))
+}
+
+func localSeen(v ssa.Value, seen map[ssa.Value]bool) bool {
+	if seen[v] {
+		return true
+	}
+	seen[v] = true
+	switch x := v.(type) {
+	case *ssa.Alloc, *ssa.MakeSlice, *ssa.MakeMap, *ssa.MakeChan, *ssa.Const:
+		return true
+	case *ssa.FieldAddr:
+		return localSeen(x.X, seen)
+	case *ssa.IndexAddr:
+		return localSeen(x.X, seen)
+	case *ssa.Slice:
+		return localSeen(x.X, seen)
+	case *ssa.Field:
+		return localSeen(x.X, seen)
+	case *ssa.Index:
+		return localSeen(x.X, seen)
+	case *ssa.ChangeType:
+		return localSeen(x.X, seen)
+	case *ssa.Convert:
+		return localSeen(x.X, seen)
+	case *ssa.MakeInterface:
+		return localSeen(x.X, seen)
+	case *ssa.SliceToArrayPointer:
+		return localSeen(x.X, seen)
+	case *ssa.Phi:
+		for _, e := range x.Edges {
+			if !localSeen(e, seen) {
+				return false
+			}
+		}
+		return true
+	case *ssa.Call:
+		// append restituisce lo slice argomento o una copia
+		if b, ok := x.Call.Value.(*ssa.Builtin); ok && b.Name() == "append" {
+			return localSeen(x.Call.Args[0], seen)
+		}
+	}
+	return false
+}
+
+// describe descrive la radice della memoria indirizzata da v.
+func describe(v ssa.Value) string {
+	for {
+		switch x := v.(type) {
+		case *ssa.Parameter:
+			return "through parameter " + x.Name()
+		case *ssa.FreeVar:
+			return "captured variable " + x.Name()
+		case *ssa.Global:
+			return "global " + x.RelString(nil)
+		case *ssa.FieldAddr:
+			v = x.X
+		case *ssa.IndexAddr:
+			v = x.X
+		case *ssa.Slice:
+			v = x.X
+		case *ssa.UnOp:
+			if x.Op != token.MUL {
+				return "shared memory"
+			}
+			v = x.X
+		default:
+			return "shared memory"
+		}
+	}
+}
+
+// basicSignature riporta se receiver, parametri e risultati sono tutti di
+// tipo base (numeri, stringhe, booleani) e c'è almeno un risultato.
+func basicSignature(sig *types.Signature) bool {
+	if sig.Results().Len() == 0 {
+		return false
+	}
+	if r := sig.Recv(); r != nil && !isBasic(r.Type()) {
+		return false
+	}
+	for _, t := range []*types.Tuple{sig.Params(), sig.Results()} {
+		for i := 0; i < t.Len(); i++ {
+			if !isBasic(t.At(i).Type()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isBasic(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&(types.IsBoolean|types.IsNumeric|types.IsString) != 0
+}
+
+func calleeName(fn *ssa.Function) string {
+	if obj, ok := fn.Object().(*types.Func); ok {
+		return ids.Object(obj)
+	}
+	if fn.Parent() != nil {
+		return fn.Parent().Name() + " closure"
+	}
+	return fn.Name()
+}
+
+func pkgPath(fn *ssa.Function) string {
+	if obj := fn.Object(); obj != nil && obj.Pkg() != nil {
+		return obj.Pkg().Path()
+	}
+	if fn.Pkg != nil && fn.Pkg.Pkg != nil {
+		return fn.Pkg.Pkg.Path()
+	}
+	return ""
+}
+
+// stdlib riporta se il package può appartenere alla libreria standard: il
+// primo elemento del path non contiene un punto.
+func stdlib(pkgPath string) bool {
+	if pkgPath == "" {
+		return false
+	}
+	first, _, _ := strings.Cut(pkgPath, "/")
+	return !strings.Contains(first, ".")
+}
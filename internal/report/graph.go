@@ -0,0 +1,151 @@
+package report
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Parametri del layout force-directed calcolato in Go: la pagina si limita
+// a disegnare, spostare e ingrandire.
+const (
+	layoutIterations = 120
+	layoutArea       = 1000.0 // lato del riquadro iniziale
+)
+
+// graphData è il call graph incluso nella pagina.
+type graphData struct {
+	Nodes    []graphNode `json:"nodes"`
+	Edges    [][2]int    `json:"edges"`    // indici in Nodes
+	Packages []string    `json:"packages"` // indice del colore di ogni nodo
+}
+
+type graphNode struct {
+	ID    string  `json:"id"`
+	Label string  `json:"label"`
+	Pkg   int     `json:"pkg"` // indice in graphData.Packages
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	In    int     `json:"in"`
+	Out   int     `json:"out"`
+}
+
+// buildGraph seleziona al più max nodi, i più connessi, con gli archi tra
+// di loro, e ne calcola le posizioni.
+func buildGraph(cg *schema.CLDKCallGraph, max int) *graphData {
+	nodes := make([]schema.CLDKCGNode, len(cg.Nodes))
+	copy(nodes, cg.Nodes)
+	if len(nodes) > max {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].FanIn+nodes[i].FanOut > nodes[j].FanIn+nodes[j].FanOut
+		})
+		nodes = nodes[:max]
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	g := &graphData{Edges: [][2]int{}}
+	index := make(map[string]int, len(nodes))
+	pkgIndex := make(map[string]int)
+	for i, n := range nodes {
+		index[n.ID] = i
+		p, ok := pkgIndex[n.Package]
+		if !ok {
+			p = len(g.Packages)
+			pkgIndex[n.Package] = p
+			g.Packages = append(g.Packages, n.Package)
+		}
+		label := n.Name
+		if n.Kind == "package" {
+			label = n.ID
+		}
+		g.Nodes = append(g.Nodes, graphNode{ID: n.ID, Label: label, Pkg: p, In: n.FanIn, Out: n.FanOut})
+	}
+	seen := make(map[[2]int]bool)
+	for _, e := range cg.Edges {
+		s, ok1 := index[e.Source]
+		t, ok2 := index[e.Target]
+		if !ok1 || !ok2 || s == t || seen[[2]int{s, t}] {
+			continue
+		}
+		seen[[2]int{s, t}] = true
+		g.Edges = append(g.Edges, [2]int{s, t})
+	}
+	layout(g)
+	return g
+}
+
+// layout applica Fruchterman-Reingold partendo da una posizione
+// deterministica: i package su un cerchio e i loro nodi attorno al centro
+// del package, così i package restano raggruppati.
+func layout(g *graphData) {
+	n := len(g.Nodes)
+	if n == 0 {
+		return
+	}
+	k := layoutArea / math.Sqrt(float64(n))
+	for i := range g.Nodes {
+		nd := &g.Nodes[i]
+		a := 2 * math.Pi * float64(nd.Pkg) / float64(len(g.Packages))
+		r := layoutArea / 3
+		if len(g.Packages) == 1 {
+			r = 0
+		}
+		h := fnv.New32a()
+		h.Write([]byte(nd.ID))
+		j := float64(h.Sum32())
+		nd.X = r*math.Cos(a) + k*math.Cos(j)*2
+		nd.Y = r*math.Sin(a) + k*math.Sin(j)*2
+	}
+
+	dx := make([]float64, n)
+	dy := make([]float64, n)
+	temp := layoutArea / 10
+	for it := 0; it < layoutIterations; it++ {
+		for i := range dx {
+			dx[i], dy[i] = 0, 0
+		}
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				ddx := g.Nodes[i].X - g.Nodes[j].X
+				ddy := g.Nodes[i].Y - g.Nodes[j].Y
+				d2 := ddx*ddx + ddy*ddy
+				if d2 < 0.01 {
+					ddx, ddy, d2 = 0.1, 0.1, 0.02
+				}
+				f := k * k / d2 // repulsione k²/d, per unità di distanza
+				dx[i] += ddx * f
+				dy[i] += ddy * f
+				dx[j] -= ddx * f
+				dy[j] -= ddy * f
+			}
+		}
+		for _, e := range g.Edges {
+			a, b := &g.Nodes[e[0]], &g.Nodes[e[1]]
+			ddx, ddy := a.X-b.X, a.Y-b.Y
+			d := math.Sqrt(ddx*ddx + ddy*ddy)
+			f := d / k // attrazione d²/k, per unità di distanza
+			dx[e[0]] -= ddx * f
+			dy[e[0]] -= ddy * f
+			dx[e[1]] += ddx * f
+			dy[e[1]] += ddy * f
+		}
+		for i := range g.Nodes {
+			// Gravità verso il centro per i componenti scollegati
+			dx[i] -= g.Nodes[i].X * 0.01
+			dy[i] -= g.Nodes[i].Y * 0.01
+			d := math.Sqrt(dx[i]*dx[i] + dy[i]*dy[i])
+			if d > 0 {
+				step := math.Min(d, temp)
+				g.Nodes[i].X += dx[i] / d * step
+				g.Nodes[i].Y += dy[i] / d * step
+			}
+		}
+		temp *= 0.96
+	}
+	for i := range g.Nodes {
+		g.Nodes[i].X = math.Round(g.Nodes[i].X*10) / 10
+		g.Nodes[i].Y = math.Round(g.Nodes[i].Y*10) / 10
+	}
+}
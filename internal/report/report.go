@@ -0,0 +1,215 @@
+// Package report genera un report HTML statico e autosufficiente
+// dell'analisi: albero dei package, elenco ricercabile dei simboli, tabelle
+// delle metriche e degli issue e una vista interattiva del call graph, con i
+// dati e il codice JavaScript inclusi nella pagina.
+package report
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Limiti del report, per mantenere la pagina utilizzabile sui progetti grandi.
+const (
+	MaxGraphNodes = 800  // nodi del call graph disegnati (i più connessi)
+	MaxIssues     = 2000 // issue elencati
+)
+
+//go:embed report.html
+var pageSource string
+
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ratio": func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) },
+}).Parse(pageSource))
+
+// data è il modello della pagina.
+type data struct {
+	Meta      *schema.Metadata
+	Title     string
+	Packages  int
+	Types     int
+	Callables int
+	Tree      []*treeNode
+	Symbols   []symbol
+	Metrics   []metricRow
+	Issues    []schema.Issue
+	IssueMore int            // issue oltre MaxIssues
+	Severity  map[string]int // issue per severità
+	Graph     template.JS    // JSON di graphData
+	HasGraph  bool
+	NodesMore int // nodi del call graph non disegnati
+}
+
+// treeNode è un segmento del percorso dei package; Pkg è impostato se il
+// percorso fino al nodo è un package.
+type treeNode struct {
+	Segment  string
+	Pkg      *pkgInfo
+	Children []*treeNode
+}
+
+type pkgInfo struct {
+	Path      string
+	Name      string
+	Doc       string
+	Files     int
+	Types     int
+	Callables int
+}
+
+type symbol struct {
+	Kind      string // function|method|struct|interface|alias|named
+	Name      string
+	ID        string
+	Package   string
+	Signature string
+	Doc       string
+	File      string
+	Line      int
+}
+
+type metricRow struct {
+	Path string
+	*schema.PackageMetrics
+}
+
+// Write scrive in w il report HTML di analysis.
+func Write(w io.Writer, analysis *schema.CLDKAnalysis) error {
+	d := &data{Meta: &analysis.Metadata, Title: analysis.Metadata.ProjectPath, Severity: map[string]int{}}
+	if st := analysis.SymbolTable; st != nil {
+		d.Packages = len(st.Packages)
+		d.Tree = buildTree(st)
+		d.Symbols = collectSymbols(st)
+		for _, s := range d.Symbols {
+			if s.Kind == "function" || s.Kind == "method" {
+				d.Callables++
+			} else {
+				d.Types++
+			}
+		}
+	}
+	if m := analysis.Metrics; m != nil {
+		for _, path := range slices.Sorted(maps.Keys(m.Packages)) {
+			d.Metrics = append(d.Metrics, metricRow{path, m.Packages[path]})
+		}
+	}
+	for _, is := range analysis.Issues {
+		d.Severity[is.Severity]++
+	}
+	d.Issues = analysis.Issues
+	if len(d.Issues) > MaxIssues {
+		d.IssueMore = len(d.Issues) - MaxIssues
+		d.Issues = d.Issues[:MaxIssues]
+	}
+	if cg := analysis.CallGraph; cg != nil && len(cg.Nodes) > 0 {
+		g := buildGraph(cg, MaxGraphNodes)
+		js, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		// Il JSON è incluso in un <script>: "</" non deve chiudere il tag
+		d.Graph = template.JS(strings.ReplaceAll(string(js), "</", `<\/`))
+		d.HasGraph = true
+		d.NodesMore = len(cg.Nodes) - len(g.Nodes)
+	}
+	return page.Execute(w, d)
+}
+
+// buildTree raggruppa i package per segmenti del percorso, comprimendo le
+// catene di segmenti con un solo figlio e senza package.
+func buildTree(st *schema.CLDKSymbolTable) []*treeNode {
+	root := &treeNode{}
+	for _, path := range slices.Sorted(maps.Keys(st.Packages)) {
+		pkg := st.Packages[path]
+		n := root
+		for _, seg := range strings.Split(path, "/") {
+			var child *treeNode
+			for _, c := range n.Children {
+				if c.Segment == seg {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				child = &treeNode{Segment: seg}
+				n.Children = append(n.Children, child)
+			}
+			n = child
+		}
+		n.Pkg = &pkgInfo{
+			Path:      path,
+			Name:      pkg.Name,
+			Doc:       firstLine(pkg.Documentation),
+			Files:     len(pkg.Files),
+			Types:     len(pkg.TypeDeclarations),
+			Callables: len(pkg.CallableDeclarations),
+		}
+	}
+	var compress func(n *treeNode)
+	compress = func(n *treeNode) {
+		for len(n.Children) == 1 && n.Pkg == nil && n.Segment != "" {
+			c := n.Children[0]
+			n.Segment += "/" + c.Segment
+			n.Pkg, n.Children = c.Pkg, c.Children
+		}
+		for _, c := range n.Children {
+			compress(c)
+		}
+	}
+	for _, c := range root.Children {
+		compress(c)
+	}
+	return root.Children
+}
+
+// collectSymbols elenca tipi e callable ordinati per package e ID.
+func collectSymbols(st *schema.CLDKSymbolTable) []symbol {
+	var out []symbol
+	for _, path := range slices.Sorted(maps.Keys(st.Packages)) {
+		pkg := st.Packages[path]
+		for _, id := range slices.Sorted(maps.Keys(pkg.TypeDeclarations)) {
+			t := pkg.TypeDeclarations[id]
+			s := symbol{Kind: t.Kind, Name: t.Name, ID: id, Package: path, Doc: firstLine(t.Documentation)}
+			s.File, s.Line = position(t.Position)
+			out = append(out, s)
+		}
+		for _, id := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+			c := pkg.CallableDeclarations[id]
+			name := c.Name
+			if c.ReceiverType != "" {
+				name = c.ReceiverType + "." + c.Name
+			}
+			s := symbol{Kind: c.Kind, Name: name, ID: id, Package: path, Signature: c.Signature, Doc: firstLine(c.Documentation)}
+			s.File, s.Line = position(c.Position)
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func position(p *schema.CLDKPosition) (string, int) {
+	if p == nil {
+		return "", 0
+	}
+	return p.File, p.StartLine
+}
+
+// firstLine restituisce la prima frase (o riga) di una documentazione.
+func firstLine(doc string) string {
+	doc = strings.TrimSpace(doc)
+	if i := strings.IndexByte(doc, '\n'); i >= 0 {
+		doc = doc[:i]
+	}
+	if i := strings.Index(doc, ". "); i >= 0 {
+		doc = doc[:i+1]
+	}
+	return doc
+}
@@ -0,0 +1,214 @@
+// Package reproducible normalizza un'analisi per --deterministic: a parità
+// di input (sorgenti, flag, toolchain e versione dell'analizzatore) l'output
+// è identico byte per byte, indipendentemente da quando, dove e su quale
+// macchina gira l'analisi, così da poterlo indirizzare per contenuto.
+package reproducible
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Epoch è il timestamp scritto nei metadati.
+const Epoch = "1970-01-01T00:00:00Z"
+
+// Toolchain contiene le directory della toolchain Go che compaiono nelle
+// posizioni dei file esterni al progetto (libreria standard, cache dei
+// moduli, file generati da cgo).
+type Toolchain struct {
+	GOROOT     string
+	GOMODCACHE string
+	GOCACHE    string
+}
+
+// GoEnv legge le directory della toolchain con go env, eseguito in dir con
+// le variabili env aggiunte a quelle del processo.
+func GoEnv(dir string, env []string) (Toolchain, error) {
+	cmd := exec.Command("go", "env", "-json", "GOROOT", "GOMODCACHE", "GOCACHE")
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(cmd.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return Toolchain{}, fmt.Errorf("go env: %s", msg)
+		}
+		return Toolchain{}, err
+	}
+	var tc Toolchain
+	if err := json.Unmarshal(out, &tc); err != nil {
+		return Toolchain{}, err
+	}
+	return tc, nil
+}
+
+// Normalize rende analysis riproducibile:
+//   - timestamp a Epoch, durata a zero, senza tempi per fase né risorse del
+//     processo;
+//   - age_days dei metadati git a zero, perché dipende dalla data
+//     dell'analisi (commit, autore e timestamp restano);
+//   - project_path ".": tutte le posizioni sono relative alla root;
+//   - le posizioni nei file fuori dalla root (nodi esterni del call graph,
+//     call site nella libreria standard) diventano "$GOROOT/...",
+//     "$GOMODCACHE/..." o "$GOCACHE/..." invece di percorsi che dipendono
+//     dalla macchina;
+//   - gli issue sono ordinati per posizione, severità, codice e messaggio
+//     invece che per fase.
+//
+// root è la directory analizzata, a cui sono relative le posizioni.
+func Normalize(analysis *schema.CLDKAnalysis, root string, tc Toolchain) {
+	meta := &analysis.Metadata
+	meta.Timestamp = Epoch
+	meta.AnalysisDurationMs = 0
+	meta.PhaseTimingsMs = nil
+	meta.Resources = nil
+	meta.ProjectPath = "."
+
+	n := &normalizer{root: root, seen: make(map[uintptr]bool)}
+	for _, p := range []struct{ dir, name string }{
+		{tc.GOROOT, "$GOROOT"},
+		{tc.GOMODCACHE, "$GOMODCACHE"},
+		{tc.GOCACHE, "$GOCACHE"},
+	} {
+		if p.dir != "" {
+			n.prefixes = append(n.prefixes, prefix{filepath.Clean(p.dir), p.name})
+		}
+	}
+	n.walk(reflect.ValueOf(analysis).Elem())
+
+	sortIssues(analysis.Issues)
+}
+
+type prefix struct{ dir, name string }
+
+type normalizer struct {
+	root     string
+	prefixes []prefix
+	seen     map[uintptr]bool // puntatori già visitati
+}
+
+var (
+	positionType    = reflect.TypeOf(schema.CLDKPosition{})
+	gitMetadataType = reflect.TypeOf(schema.CLDKGitMetadata{})
+)
+
+// walk visita v, riscrive il file di ogni CLDKPosition raggiungibile e
+// azzera l'età di ogni CLDKGitMetadata.
+func (n *normalizer) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || n.seen[v.Pointer()] {
+			return
+		}
+		n.seen[v.Pointer()] = true
+		n.walk(v.Elem())
+	case reflect.Interface:
+		if !v.IsNil() {
+			// il valore in un'interfaccia non è indirizzabile
+			c := reflect.New(v.Elem().Type()).Elem()
+			c.Set(v.Elem())
+			n.walk(c)
+			if v.CanSet() {
+				v.Set(c)
+			}
+		}
+	case reflect.Struct:
+		if v.Type() == positionType {
+			if f := v.FieldByName("File"); f.CanSet() {
+				f.SetString(n.file(f.String()))
+			}
+			return
+		}
+		if v.Type() == gitMetadataType {
+			if f := v.FieldByName("AgeDays"); f.CanSet() {
+				f.SetInt(0)
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				n.walk(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			n.walk(v.Index(i))
+		}
+	case reflect.Map:
+		// i valori di una mappa non sono indirizzabili: si copiano e si
+		// riscrivono
+		for _, k := range v.MapKeys() {
+			e := v.MapIndex(k)
+			c := reflect.New(e.Type()).Elem()
+			c.Set(e)
+			n.walk(c)
+			v.SetMapIndex(k, c)
+		}
+	}
+}
+
+// file restituisce il percorso riproducibile di file, relativo alla root o
+// assoluto.
+func (n *normalizer) file(file string) string {
+	if file == "" {
+		return file
+	}
+	abs := filepath.FromSlash(file)
+	switch {
+	case filepath.IsAbs(abs):
+	case abs == ".." || strings.HasPrefix(abs, ".."+string(filepath.Separator)):
+		abs = filepath.Join(n.root, abs)
+	default:
+		return file // dentro la root
+	}
+	for _, p := range n.prefixes {
+		if rel, err := filepath.Rel(p.dir, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return p.name + "/" + filepath.ToSlash(rel)
+		}
+	}
+	if rel, err := filepath.Rel(n.root, abs); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
+
+// sortIssues ordina gli issue per posizione (quelli senza posizione in
+// fondo), severità, codice e messaggio.
+func sortIssues(issues []schema.Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if (a.Position == nil) != (b.Position == nil) {
+			return b.Position == nil
+		}
+		if a.Position != nil {
+			pa, pb := a.Position, b.Position
+			if pa.File != pb.File {
+				return pa.File < pb.File
+			}
+			if pa.StartLine != pb.StartLine {
+				return pa.StartLine < pb.StartLine
+			}
+			if pa.StartColumn != pb.StartColumn {
+				return pa.StartColumn < pb.StartColumn
+			}
+		}
+		if a.Severity != b.Severity {
+			return a.Severity < b.Severity
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.Message < b.Message
+	})
+}
@@ -6,6 +6,8 @@
 package sdg
 
 import (
+	"sort"
+
 	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
 )
 
@@ -101,6 +103,14 @@ func Build(pdgResult *schema.CLDKPDG, cgResult *schema.CLDKCallGraph, cfg Config
 		}
 	}
 
+	// Le funzioni sono visitate in ordine di mappa: si ordinano i chiamanti,
+	// lasciando gli edge di ciascuno nell'ordine dei nodi del PDG
+	for _, pkg := range sdg.Packages {
+		sort.SliceStable(pkg.InterEdges, func(i, j int) bool {
+			return pkg.InterEdges[i].CallerFunc < pkg.InterEdges[j].CallerFunc
+		})
+	}
+
 	return sdg, nil
 }
 
@@ -0,0 +1,179 @@
+// Package search cerca simboli nella symbol table di un'analisi CLDK.
+package search
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Query descrive i filtri di una ricerca; i campi vuoti non filtrano.
+type Query struct {
+	// Kind: func|method|type|struct|interface|alias|named|var|const
+	Kind string
+
+	// Name: nome esatto del simbolo oppure, con prefisso "~", una regex
+	// applicata al nome non qualificato
+	Name string
+
+	Exported bool // solo simboli esportati
+
+	// Receiver: tipo del receiver dei metodi, senza package; "*T" richiede
+	// un receiver puntatore
+	Receiver string
+
+	Signature string // sottostringa della firma (o del tipo di var/const)
+}
+
+// kinds mappa i valori di --kind sui kind dei risultati.
+var kinds = map[string][]string{
+	"func":      {"function"},
+	"function":  {"function"},
+	"method":    {"method"},
+	"type":      {"struct", "interface", "alias", "named"},
+	"struct":    {"struct"},
+	"interface": {"interface"},
+	"alias":     {"alias"},
+	"named":     {"named"},
+	"var":       {"variable"},
+	"variable":  {"variable"},
+	"const":     {"constant"},
+	"constant":  {"constant"},
+}
+
+// matcher è la forma compilata di una Query.
+type matcher struct {
+	q     Query
+	kinds map[string]bool
+	name  *regexp.Regexp
+	ptr   bool // Receiver con "*"
+	recv  string
+}
+
+func compile(q Query) (*matcher, error) {
+	m := &matcher{q: q}
+	if q.Kind != "" {
+		ks, ok := kinds[strings.ToLower(q.Kind)]
+		if !ok {
+			return nil, fmt.Errorf("unknown kind %q (valid: func, method, type, struct, interface, alias, named, var, const)", q.Kind)
+		}
+		m.kinds = make(map[string]bool, len(ks))
+		for _, k := range ks {
+			m.kinds[k] = true
+		}
+	}
+	if strings.HasPrefix(q.Name, "~") {
+		re, err := regexp.Compile(q.Name[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern: %w", err)
+		}
+		m.name = re
+	}
+	m.recv = strings.TrimPrefix(q.Receiver, "*")
+	m.ptr = strings.HasPrefix(q.Receiver, "*")
+	return m, nil
+}
+
+func (m *matcher) match(kind, name, signature string) bool {
+	if m.kinds != nil && !m.kinds[kind] {
+		return false
+	}
+	if m.name != nil {
+		if !m.name.MatchString(name) {
+			return false
+		}
+	} else if m.q.Name != "" && name != m.q.Name {
+		return false
+	}
+	if m.q.Exported && !token.IsExported(name) {
+		return false
+	}
+	if m.q.Signature != "" && !strings.Contains(signature, m.q.Signature) {
+		return false
+	}
+	return true
+}
+
+// Run restituisce i simboli di st che soddisfano q, ordinati per package,
+// file e riga. I metodi compaiono una sola volta, come callable.
+func Run(st *schema.CLDKSymbolTable, q Query) ([]schema.CLDKSymbolMatch, error) {
+	m, err := compile(q)
+	if err != nil {
+		return nil, err
+	}
+	matches := []schema.CLDKSymbolMatch{}
+	if st == nil {
+		return matches, nil
+	}
+
+	for pkgPath, pkg := range st.Packages {
+		if pkg == nil {
+			continue
+		}
+		add := func(kind, qn, name, sig string, pos *schema.CLDKPosition) {
+			matches = append(matches, schema.CLDKSymbolMatch{
+				Kind:          kind,
+				QualifiedName: qn,
+				Name:          name,
+				Package:       pkgPath,
+				Signature:     sig,
+				Position:      pos,
+			})
+		}
+
+		for qn, c := range pkg.CallableDeclarations {
+			if m.q.Receiver != "" && (c.Kind != "method" || c.ReceiverType != m.recv || (m.ptr && !c.ReceiverPtr)) {
+				continue
+			}
+			if m.match(c.Kind, c.Name, c.Signature) {
+				add(c.Kind, qn, c.Name, c.Signature, c.Position)
+			}
+		}
+		if m.q.Receiver != "" {
+			continue
+		}
+		for qn, t := range pkg.TypeDeclarations {
+			if m.match(t.Kind, t.Name, "") {
+				add(t.Kind, qn, t.Name, "", t.Position)
+			}
+		}
+		for qn, v := range pkg.Variables {
+			if m.match("variable", v.Name, v.Type) {
+				add("variable", qn, v.Name, v.Type, v.Position)
+			}
+		}
+		for qn, c := range pkg.Constants {
+			if m.match("constant", c.Name, c.Type) {
+				add("constant", qn, c.Name, c.Type, c.Position)
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		af, al := positionKey(a.Position)
+		bf, bl := positionKey(b.Position)
+		if af != bf {
+			return af < bf
+		}
+		if al != bl {
+			return al < bl
+		}
+		return a.QualifiedName < b.QualifiedName
+	})
+	return matches, nil
+}
+
+func positionKey(p *schema.CLDKPosition) (string, int) {
+	if p == nil {
+		return "", 0
+	}
+	return p.File, p.StartLine
+}
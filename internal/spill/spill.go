@@ -0,0 +1,77 @@
+// Package spill esegue analisi basate su SSA un package alla volta, salvando
+// i risultati intermedi su disco per mantenere limitata la memoria residente.
+package spill
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+)
+
+// Run costruisce l'SSA per ogni package (loader.ForEachPackageSSA), applica
+// build e serializza il risultato parziale in una directory temporanea dentro
+// dir (vuoto = directory temporanea di sistema). Terminata la costruzione,
+// rilegge i parziali nell'ordine originale e li passa a merge.
+//
+// Il formato è JSON (non gob) per preservare la distinzione tra slice vuote
+// e nil, che l'output finale espone come [] o null.
+func Run[T any](result *loader.LoadResult, dir string, build func(part *loader.LoadResult) (T, error), merge func(T)) error {
+	tmp, err := os.MkdirTemp(dir, "codeanalyzer-spill-*")
+	if err != nil {
+		return fmt.Errorf("create spill dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	var files []string
+	err = loader.ForEachPackageSSA(result, func(part *loader.LoadResult) error {
+		v, err := build(part)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(tmp, fmt.Sprintf("part-%05d.json", len(files)))
+		if err := write(path, v); err != nil {
+			return err
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		var v T
+		if err := read(path, &v); err != nil {
+			return err
+		}
+		merge(v)
+	}
+	return nil
+}
+
+func write(path string, v interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create spill file: %w", err)
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		return fmt.Errorf("encode spill file: %w", err)
+	}
+	return nil
+}
+
+func read(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open spill file: %w", err)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("decode spill file: %w", err)
+	}
+	return nil
+}
@@ -6,7 +6,6 @@ import (
 	"go/ast"
 	"go/token"
 	"math"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -14,6 +13,7 @@ import (
 
 	"golang.org/x/tools/go/packages"
 
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
 	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
 )
 
@@ -97,15 +97,7 @@ func Extract(pkg *packages.Package, fset *token.FileSet, root string, cfg Config
 			// Posizione
 			pos := fset.Position(lit.Pos())
 			if pos.IsValid() {
-				file := pos.Filename
-				if rel, err := filepath.Rel(root, file); err == nil {
-					file = filepath.ToSlash(rel)
-				}
-				sl.Position = &schema.CLDKPosition{
-					File:        file,
-					StartLine:   pos.Line,
-					StartColumn: pos.Column,
-				}
+				sl.Position = loader.Position(pos, root)
 			}
 
 			result = append(result, sl)
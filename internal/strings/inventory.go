@@ -0,0 +1,237 @@
+package strings
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Tipi di stringa riconosciuti dall'inventario.
+const (
+	KindMessage = "message"  // testo probabilmente mostrato all'utente
+	KindFormat  = "format"   // format string printf-style
+	KindI18nKey = "i18n_key" // chiave passata a una funzione di traduzione
+)
+
+// DefaultInventoryMinLength è la lunghezza minima predefinita (in byte).
+const DefaultInventoryMinLength = 8
+
+// InventoryConfig configura Inventory.
+type InventoryConfig struct {
+	MinLength int    // lunghezza minima delle stringhe (0 = DefaultInventoryMinLength); le chiavi i18n sono sempre incluse
+	Only      string // se non vuoto, solo le stringhe con questo kind
+}
+
+// translateFuncs sono i nomi di funzione il cui primo argomento stringa è
+// una chiave di traduzione (go-i18n, gettext, x/text/message e wrapper "T").
+var translateFuncs = map[string]bool{
+	"T": true, "Tr": true, "Tf": true, "Translate": true, "Translatef": true,
+	"Localize": true, "MustLocalize": true, "Gettext": true, "NGettext": true,
+	"PGettext": true,
+}
+
+// reVerb riconosce un verbo printf.
+var reVerb = regexp.MustCompile(`%[-+# 0]*(\d+|\*)?(\.(\d+|\*)?)?[vTtbcdoOqxXUeEfFgGsp]`)
+
+// Inventory elenca le string literal dei package con la funzione che le
+// contiene, il kind (message, format, i18n_key) e la chiamata a cui sono
+// passate, e raggruppa i valori ripetuti. Import path e struct tag sono
+// esclusi.
+func Inventory(pkgs []*packages.Package, fset *token.FileSet, root string, cfg InventoryConfig) *schema.CLDKStringInventory {
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = DefaultInventoryMinLength
+	}
+	inv := &schema.CLDKStringInventory{Strings: []schema.CLDKStringEntry{}, Duplicates: []schema.CLDKStringDuplicate{}}
+	seen := make(map[token.Position]bool) // le varianti di test ripetono i file del package
+
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil || seen[fset.Position(file.Package)] || !loader.UnderRoot(fset.Position(file.Package).Filename, root) {
+				continue
+			}
+			seen[fset.Position(file.Package)] = true
+			scopes := buildFuncScopes(pkg.PkgPath, file)
+			skip := make(map[*ast.BasicLit]bool)
+			calls := make(map[*ast.BasicLit]*ast.CallExpr)
+			ast.Inspect(file, func(n ast.Node) bool {
+				switch x := n.(type) {
+				case *ast.ImportSpec:
+					skip[x.Path] = true
+				case *ast.Field:
+					if x.Tag != nil {
+						skip[x.Tag] = true
+					}
+				case *ast.CallExpr:
+					for _, arg := range x.Args {
+						if lit, ok := ast.Unparen(arg).(*ast.BasicLit); ok {
+							calls[lit] = x
+						}
+					}
+				}
+				return true
+			})
+
+			ast.Inspect(file, func(n ast.Node) bool {
+				lit, ok := n.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING || skip[lit] {
+					return true
+				}
+				val, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					val = strings.Trim(lit.Value, "`")
+				}
+
+				e := schema.CLDKStringEntry{
+					Value:    truncateString(val, 200),
+					Package:  pkg.PkgPath,
+					Scope:    findScope(fset, lit.Pos(), scopes),
+					Category: classify(val),
+					Position: loader.Position(fset.Position(lit.Pos()), root),
+				}
+				call := calls[lit]
+				if call != nil {
+					e.Call = calleeName(call, pkg.TypesInfo)
+				}
+				vs := verbs(val)
+				switch {
+				case call != nil && isTranslateCall(call, lit):
+					e.Kind = KindI18nKey
+				case len(vs) > 0:
+					e.Kind = KindFormat
+					e.Verbs = vs
+				case isMessage(val):
+					e.Kind = KindMessage
+				}
+				if e.Kind != KindI18nKey && len(val) < cfg.MinLength {
+					return true
+				}
+				if cfg.Only != "" && e.Kind != cfg.Only {
+					return true
+				}
+				inv.Strings = append(inv.Strings, e)
+				return true
+			})
+		}
+	}
+
+	sort.Slice(inv.Strings, func(i, j int) bool {
+		a, b := inv.Strings[i].Position, inv.Strings[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.StartColumn < b.StartColumn
+	})
+
+	byValue := make(map[string][]schema.CLDKPosition)
+	for _, e := range inv.Strings {
+		byValue[e.Value] = append(byValue[e.Value], *e.Position)
+		switch e.Kind {
+		case KindMessage:
+			inv.Messages++
+		case KindFormat:
+			inv.FormatStrings++
+		case KindI18nKey:
+			inv.I18nKeys++
+		}
+	}
+	for v, pos := range byValue {
+		if len(pos) > 1 {
+			inv.Duplicates = append(inv.Duplicates, schema.CLDKStringDuplicate{Value: v, Count: len(pos), Positions: pos})
+		}
+	}
+	sort.Slice(inv.Duplicates, func(i, j int) bool {
+		a, b := inv.Duplicates[i], inv.Duplicates[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Value < b.Value
+	})
+	return inv
+}
+
+// isTranslateCall riporta se lit è il primo argomento di una funzione di
+// traduzione (es. i18n.T("errors.not_found")).
+func isTranslateCall(call *ast.CallExpr, lit *ast.BasicLit) bool {
+	if len(call.Args) == 0 || ast.Unparen(call.Args[0]) != lit {
+		return false
+	}
+	var name string
+	switch fn := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		name = fn.Name
+	case *ast.SelectorExpr:
+		name = fn.Sel.Name
+	}
+	return translateFuncs[name]
+}
+
+// isMessage stima se s è testo in linguaggio naturale: almeno due parole,
+// prevalenza di lettere e nessun carattere tipico di codice, URL o path.
+func isMessage(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(strings.Fields(s)) < 2 || strings.ContainsAny(s, "{}<>=;\\|`") || strings.Contains(s, "://") {
+		return false
+	}
+	letters, other := 0, 0
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+		case unicode.IsSpace(r) || unicode.IsPunct(r):
+		default:
+			other++
+		}
+	}
+	first := []rune(s)[0]
+	return letters > 0 && letters >= 3*other && (unicode.IsLetter(first) || first == '%')
+}
+
+// verbs restituisce i verbi printf di s nell'ordine in cui compaiono.
+func verbs(s string) []string {
+	s = strings.ReplaceAll(s, "%%", "")
+	return reVerb.FindAllString(s, -1)
+}
+
+// calleeName restituisce il nome della funzione chiamata: qualificato col
+// package per le funzioni package-level ("fmt.Errorf"), altrimenti il testo
+// dell'espressione ("log.Printf", "t.Errorf").
+func calleeName(call *ast.CallExpr, info *types.Info) string {
+	switch fn := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		if info != nil {
+			if f, ok := info.Uses[fn].(*types.Func); ok && f.Pkg() != nil {
+				return f.Pkg().Path() + "." + f.Name()
+			}
+		}
+		return fn.Name
+	case *ast.SelectorExpr:
+		if info != nil {
+			if f, ok := info.Uses[fn.Sel].(*types.Func); ok && f.Pkg() != nil {
+				if sig, ok := f.Type().(*types.Signature); ok && sig.Recv() == nil {
+					return f.Pkg().Path() + "." + f.Name()
+				}
+			}
+		}
+		if x, ok := fn.X.(*ast.Ident); ok {
+			return x.Name + "." + fn.Sel.Name
+		}
+		return fn.Sel.Name
+	}
+	return ""
+}
@@ -0,0 +1,376 @@
+// Package summarize aggiunge alla symbol table riassunti in linguaggio
+// naturale generati da un summarizer esterno (comando o endpoint HTTP). Le
+// richieste sono costruite dall'analisi già calcolata (firma, documentazione,
+// sorgente, vicini nel call graph) e le risposte possono essere conservate in
+// una cache su disco indicizzata dal contenuto della richiesta.
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Ambiti dei riassunti.
+const (
+	ScopePackages  = "packages"
+	ScopeCallables = "callables"
+	ScopeAll       = "all"
+)
+
+// Limiti del contesto inviato al summarizer.
+const (
+	maxSourceBytes = 8000
+	maxNeighbors   = 20
+	maxMembers     = 50
+	requestTimeout = 2 * time.Minute
+)
+
+// CodeSummaryFailed segnala le richieste fallite.
+const CodeSummaryFailed = "SUMMARY_FAILED"
+
+// Request è il contesto di un package o di un callable inviato al
+// summarizer come JSON.
+type Request struct {
+	Kind      string   `json:"kind"` // package|callable
+	ID        string   `json:"id"`
+	Package   string   `json:"package"`
+	Name      string   `json:"name"`
+	Signature string   `json:"signature,omitempty"`
+	Doc       string   `json:"doc,omitempty"`
+	Wraps     string   `json:"wraps,omitempty"`     // callable: funzione inoltrata, se è un wrapper sottile
+	Source    string   `json:"source,omitempty"`    // callable, troncato a maxSourceBytes
+	Calls     []string `json:"calls,omitempty"`     // callee nel call graph
+	CalledBy  []string `json:"called_by,omitempty"` // caller nel call graph
+	Imports   []string `json:"imports,omitempty"`   // package
+	Members   []string `json:"members,omitempty"`   // package: firme delle dichiarazioni esportate
+}
+
+// Summarizer genera il riassunto di un package o di un callable.
+type Summarizer interface {
+	Summarize(ctx context.Context, req *Request) (string, error)
+}
+
+// Command esegue un comando esterno per ogni richiesta: la richiesta JSON
+// arriva su stdin, il riassunto è lo stdout (testo o {"summary": "..."}).
+type Command struct {
+	Argv []string
+}
+
+func (c *Command) Summarize(ctx context.Context, req *Request) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, c.Argv[0], c.Argv[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return parseSummary(out), nil
+}
+
+// HTTP invia ogni richiesta in POST come JSON; la risposta è testo o
+// {"summary": "..."}.
+type HTTP struct {
+	URL    string
+	Client *http.Client
+}
+
+func (h *HTTP) Summarize(ctx context.Context, req *Request) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return parseSummary(body), nil
+}
+
+// parseSummary accetta {"summary": "..."} o testo semplice e restituisce un
+// unico paragrafo.
+func parseSummary(out []byte) string {
+	var v struct {
+		Summary string `json:"summary"`
+	}
+	text := string(out)
+	if json.Unmarshal(out, &v) == nil && v.Summary != "" {
+		text = v.Summary
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Config configura Annotate.
+type Config struct {
+	Scope   string // packages|callables|all
+	Root    string // radice del progetto, per il sorgente dei callable
+	FS      fs.FS  // sorgenti con radice in Root, come LoadResult.FS (nil = dal disco)
+	Cache   string // file JSON della cache ("" = nessuna cache)
+	Workers int
+}
+
+// Annotate imposta Summary su package e callable della symbol table secondo
+// cfg.Scope. Le richieste già in cache non sono inviate; le risposte nuove
+// sono aggiunte alla cache. Le richieste fallite producono un unico issue
+// con il loro numero e il primo errore.
+func Annotate(ctx context.Context, s Summarizer, st *schema.CLDKSymbolTable, cg *schema.CLDKCallGraph, cfg Config) ([]schema.Issue, error) {
+	cache, err := loadCache(cfg.Cache)
+	if err != nil {
+		return nil, err
+	}
+
+	type job struct {
+		req *Request
+		key string
+		set func(string)
+	}
+	var jobs []job
+	calls, calledBy := neighbors(cg)
+	for _, path := range slices.Sorted(maps.Keys(st.Packages)) {
+		pkg := st.Packages[path]
+		if cfg.Scope != ScopeCallables {
+			jobs = append(jobs, job{req: packageRequest(pkg), set: func(v string) { pkg.Summary = v }})
+		}
+		if cfg.Scope == ScopePackages {
+			continue
+		}
+		for _, id := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+			cd := pkg.CallableDeclarations[id]
+			req := callableRequest(pkg, id, cd, calls[id], calledBy[id], cfg.FS, cfg.Root)
+			jobs = append(jobs, job{req: req, set: func(v string) {
+				cd.Summary = v
+				if cd.ReceiverType == "" {
+					return
+				}
+				if t := pkg.TypeDeclarations[ids.Type(pkg.Path, cd.ReceiverType)]; t != nil && t.Methods[id] != nil {
+					t.Methods[id].Summary = v
+				}
+			}})
+		}
+	}
+
+	var mu sync.Mutex
+	var failed int
+	var firstErr error
+	work := make(chan job)
+	var wg sync.WaitGroup
+	workers := max(cfg.Workers, 1)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range work {
+				rctx, cancel := context.WithTimeout(ctx, requestTimeout)
+				sum, err := s.Summarize(rctx, j.req)
+				cancel()
+				mu.Lock()
+				if err != nil {
+					failed++
+					if firstErr == nil {
+						firstErr = fmt.Errorf("%s: %w", j.req.ID, err)
+					}
+				} else if sum != "" {
+					cache[j.key] = sum
+					j.set(sum)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, j := range jobs {
+		j.key = cacheKey(j.req)
+		if sum, ok := cache[j.key]; ok {
+			j.set(sum)
+			continue
+		}
+		work <- j
+	}
+	close(work)
+	wg.Wait()
+
+	var issues []schema.Issue
+	if failed > 0 {
+		issues = append(issues, schema.Issue{
+			Severity: "warning",
+			Code:     CodeSummaryFailed,
+			Message:  fmt.Sprintf("%d summary requests failed, first error: %v", failed, firstErr),
+		})
+	}
+	return issues, saveCache(cfg.Cache, cache)
+}
+
+// packageRequest descrive un package: documentazione, import e firme delle
+// dichiarazioni esportate.
+func packageRequest(pkg *schema.CLDKPackage) *Request {
+	req := &Request{Kind: "package", ID: pkg.Path, Package: pkg.Path, Name: pkg.Name, Doc: pkg.Documentation}
+	for _, imp := range pkg.Imports {
+		req.Imports = append(req.Imports, imp.Path)
+	}
+	for _, id := range slices.Sorted(maps.Keys(pkg.TypeDeclarations)) {
+		if t := pkg.TypeDeclarations[id]; token.IsExported(t.Name) {
+			req.Members = append(req.Members, "type "+t.Name+" "+t.Kind)
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+		if cd := pkg.CallableDeclarations[id]; cd.Exported {
+			req.Members = append(req.Members, cd.Signature)
+		}
+	}
+	if len(req.Members) > maxMembers {
+		req.Members = req.Members[:maxMembers]
+	}
+	return req
+}
+
+// callableRequest descrive un callable: firma, documentazione, sorgente
+// (letto da fsys) e vicini nel call graph.
+func callableRequest(pkg *schema.CLDKPackage, id string, cd *schema.CLDKCallable, calls, calledBy []string, fsys fs.FS, root string) *Request {
+	req := &Request{
+		Kind:      "callable",
+		ID:        id,
+		Package:   pkg.Path,
+		Name:      cd.Name,
+		Signature: cd.Signature,
+		Doc:       cd.Documentation,
+		Wraps:     cd.Wraps,
+		Calls:     truncate(calls, maxNeighbors),
+		CalledBy:  truncate(calledBy, maxNeighbors),
+	}
+	if root != "" && cd.Position != nil && cd.EndPosition != nil {
+		req.Source = readLines(fsys, root, filepath.Join(root, filepath.FromSlash(cd.Position.File)), cd.Position.StartLine, cd.EndPosition.StartLine)
+		if len(req.Source) > maxSourceBytes {
+			req.Source = req.Source[:maxSourceBytes] + "\n..."
+		}
+	}
+	return req
+}
+
+// neighbors restituisce callee e caller distinti di ogni dichiarazione,
+// con closure e wrapper ricondotti alla dichiarazione tramite SymbolRef.
+func neighbors(cg *schema.CLDKCallGraph) (calls, calledBy map[string][]string) {
+	calls, calledBy = make(map[string][]string), make(map[string][]string)
+	if cg == nil {
+		return calls, calledBy
+	}
+	ref := make(map[string]string, len(cg.Nodes))
+	for _, n := range cg.Nodes {
+		ref[n.ID] = n.ID
+		if n.SymbolRef != "" {
+			ref[n.ID] = n.SymbolRef
+		}
+	}
+	seen := make(map[[2]string]bool)
+	for _, e := range cg.Edges {
+		src, dst := ref[e.Source], ref[e.Target]
+		if src == "" || dst == "" || src == dst || seen[[2]string{src, dst}] {
+			continue
+		}
+		seen[[2]string{src, dst}] = true
+		calls[src] = append(calls[src], dst)
+		calledBy[dst] = append(calledBy[dst], src)
+	}
+	return calls, calledBy
+}
+
+// cacheKey è l'hash della richiesta: un cambiamento di firma, sorgente o
+// vicini invalida il riassunto.
+func cacheKey(req *Request) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func loadCache(path string) (map[string]string, error) {
+	cache := make(map[string]string)
+	if path == "" {
+		return cache, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read summary cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("parse summary cache %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+func saveCache(path string, cache map[string]string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write summary cache: %w", err)
+	}
+	return nil
+}
+
+func readLines(fsys fs.FS, root, file string, from, to int) string {
+	data, err := loader.ReadFile(fsys, root, file)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+	if from < 1 || to > len(lines) || from > to {
+		return ""
+	}
+	return strings.Join(lines[from-1:to], "\n")
+}
+
+func truncate(ss []string, n int) []string {
+	if len(ss) > n {
+		return ss[:n]
+	}
+	return ss
+}
@@ -0,0 +1,278 @@
+// Package summary costruisce riassunti data-flow per funzione a partire
+// dall'SSA, utili per ragionamenti inter-procedurali economici a valle.
+package summary
+
+import (
+	"fmt"
+	"go/types"
+	"maps"
+	"slices"
+	"sort"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/pkgfilter"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Config configura la costruzione dei riassunti.
+type Config struct {
+	Packages *pkgfilter.Filter // filtra i package (--only-pkg, --match-pkg, --exclude-pkg)
+}
+
+// Build costruisce i riassunti per le funzioni e i metodi dei pacchetti caricati.
+func Build(result *loader.LoadResult, cfg Config) (*schema.CLDKSummaries, error) {
+	if result.SSAProgram == nil {
+		return nil, fmt.Errorf("SSAProgram is nil, call loader.Load with NeedSSA=true")
+	}
+
+	out := &schema.CLDKSummaries{
+		Packages: make(map[string]*schema.CLDKPackageSummaries),
+	}
+
+	for _, ssaPkg := range result.SSAPackages {
+		if ssaPkg == nil || ssaPkg.Pkg == nil {
+			continue
+		}
+		pkgPath := ssaPkg.Pkg.Path()
+		if !cfg.Packages.Allows(pkgPath) {
+			continue
+		}
+
+		pkgSum := &schema.CLDKPackageSummaries{
+			Functions: make(map[string]*schema.CLDKFunctionSummary),
+		}
+
+		for _, member := range ssaPkg.Members {
+			switch m := member.(type) {
+			case *ssa.Function:
+				addSummary(m, pkgPath, pkgSum)
+			case *ssa.Type:
+				// Metodi con receiver valore e puntatore
+				for _, t := range []types.Type{m.Type(), types.NewPointer(m.Type())} {
+					mset := result.SSAProgram.MethodSets.MethodSet(t)
+					for i := 0; i < mset.Len(); i++ {
+						fn := result.SSAProgram.MethodValue(mset.At(i))
+						if fn != nil && fn.Pkg == ssaPkg {
+							addSummary(fn, pkgPath, pkgSum)
+						}
+					}
+				}
+			}
+		}
+
+		if len(pkgSum.Functions) > 0 {
+			out.Packages[pkgPath] = pkgSum
+		}
+	}
+
+	return out, nil
+}
+
+// addSummary calcola e registra il riassunto di fn se ha un body.
+func addSummary(fn *ssa.Function, pkgPath string, pkgSum *schema.CLDKPackageSummaries) {
+	if fn == nil || len(fn.Blocks) == 0 || fn.Synthetic != "" {
+		return
+	}
+	fid := ids.SSAFunc(fn)
+	if _, exists := pkgSum.Functions[fid]; exists {
+		return
+	}
+	s := Summarize(fn)
+	s.QualifiedName = fid
+	s.Package = pkgPath
+	pkgSum.Functions[fid] = s
+}
+
+// Summarize calcola il riassunto data-flow di una singola funzione SSA.
+func Summarize(fn *ssa.Function) *schema.CLDKFunctionSummary {
+	s := &schema.CLDKFunctionSummary{
+		Params: make([]schema.ParamFlow, 0, len(fn.Params)),
+	}
+
+	for i, p := range fn.Params {
+		flow := schema.ParamFlow{Name: p.Name(), Index: i}
+		t := taint(fn, p)
+
+		globals := make(map[string]bool)
+		results := make(map[int]bool)
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch x := instr.(type) {
+				case *ssa.Return:
+					for ri, r := range x.Results {
+						if t[r] {
+							results[ri] = true
+						}
+					}
+				case *ssa.Store:
+					if t[x.Val] {
+						if g := rootGlobal(x.Addr); g != nil {
+							globals[g.Name()] = true
+						}
+					}
+				case *ssa.Go:
+					if usesTainted(x.Common(), t) {
+						flow.ToGoroutine = true
+					}
+				}
+			}
+		}
+
+		for ri := range results {
+			flow.ToResults = append(flow.ToResults, ri)
+		}
+		sort.Ints(flow.ToResults)
+		for g := range globals {
+			flow.ToGlobals = append(flow.ToGlobals, g)
+		}
+		sort.Strings(flow.ToGlobals)
+
+		s.Params = append(s.Params, flow)
+	}
+
+	// Campi del receiver letti/scritti
+	if fn.Signature.Recv() != nil && len(fn.Params) > 0 {
+		s.FieldsRead, s.FieldsWritten = receiverFields(fn, fn.Params[0])
+	}
+
+	return s
+}
+
+// fieldKey identifica una cella di memoria X.f indipendentemente
+// dall'istruzione FieldAddr che la calcola.
+type fieldKey struct {
+	base  ssa.Value
+	field int
+}
+
+// taint propaga in avanti il valore v attraverso i referrer SSA, includendo
+// le celle di memoria in cui viene scritto (così che i load successivi
+// risultino dipendenti). L'analisi è intra-procedurale e conservativa:
+// il risultato di una call dipende da tutti i suoi argomenti.
+func taint(fn *ssa.Function, v ssa.Value) map[ssa.Value]bool {
+	// Tutte le FieldAddr della funzione raggruppate per cella
+	cells := make(map[fieldKey][]ssa.Value)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if fa, ok := instr.(*ssa.FieldAddr); ok {
+				k := fieldKey{fa.X, fa.Field}
+				cells[k] = append(cells[k], fa)
+			}
+		}
+	}
+
+	t := map[ssa.Value]bool{v: true}
+	work := []ssa.Value{v}
+	add := func(x ssa.Value) {
+		if x != nil && !t[x] {
+			t[x] = true
+			work = append(work, x)
+		}
+	}
+
+	for len(work) > 0 {
+		cur := work[len(work)-1]
+		work = work[:len(work)-1]
+		refs := cur.Referrers()
+		if refs == nil {
+			continue
+		}
+		for _, instr := range *refs {
+			switch x := instr.(type) {
+			case *ssa.Store:
+				if x.Val == cur {
+					add(x.Addr)
+					if fa, ok := x.Addr.(*ssa.FieldAddr); ok {
+						for _, other := range cells[fieldKey{fa.X, fa.Field}] {
+							add(other)
+						}
+					}
+				}
+			case *ssa.MapUpdate:
+				add(x.Map)
+			case *ssa.Send:
+				add(x.Chan)
+			case ssa.Value:
+				add(x)
+			}
+		}
+	}
+	return t
+}
+
+// usesTainted verifica se una call usa un valore contaminato (argomenti o closure).
+func usesTainted(c *ssa.CallCommon, t map[ssa.Value]bool) bool {
+	if t[c.Value] {
+		return true
+	}
+	for _, a := range c.Args {
+		if t[a] {
+			return true
+		}
+	}
+	return false
+}
+
+// rootGlobal risale FieldAddr/IndexAddr fino alla variabile globale, se esiste.
+func rootGlobal(addr ssa.Value) *ssa.Global {
+	for {
+		switch a := addr.(type) {
+		case *ssa.Global:
+			return a
+		case *ssa.FieldAddr:
+			addr = a.X
+		case *ssa.IndexAddr:
+			addr = a.X
+		default:
+			return nil
+		}
+	}
+}
+
+// receiverFields raccoglie i campi del receiver letti e scritti nel body.
+func receiverFields(fn *ssa.Function, recv *ssa.Parameter) (read, written []string) {
+	r := make(map[string]bool)
+	w := make(map[string]bool)
+
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			switch x := instr.(type) {
+			case *ssa.FieldAddr:
+				if x.X != recv {
+					continue
+				}
+				name := fieldName(x.X.Type(), x.Field)
+				if x.Referrers() == nil {
+					continue
+				}
+				for _, ref := range *x.Referrers() {
+					if st, ok := ref.(*ssa.Store); ok && st.Addr == x {
+						w[name] = true
+					} else {
+						r[name] = true
+					}
+				}
+			case *ssa.Field:
+				if x.X == recv {
+					r[fieldName(x.X.Type(), x.Field)] = true
+				}
+			}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(r)), slices.Sorted(maps.Keys(w))
+}
+
+// fieldName restituisce il nome del campo i-esimo della struct (o *struct) t.
+func fieldName(t types.Type, i int) string {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if st, ok := t.Underlying().(*types.Struct); ok && i < st.NumFields() {
+		return st.Field(i).Name()
+	}
+	return fmt.Sprintf("field%d", i)
+}
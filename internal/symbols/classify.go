@@ -0,0 +1,217 @@
+package symbols
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Classificazioni strutturali dei callable.
+const (
+	ClassGetter      = "getter"
+	ClassSetter      = "setter"
+	ClassStringer    = "stringer"
+	ClassConstructor = "constructor"
+	ClassBoilerplate = "boilerplate"
+)
+
+// boilerplateMethods sono i metodi di deepcopy di Kubernetes, boilerplate
+// anche quando non sono in un file generato.
+var boilerplateMethods = map[string]bool{
+	"DeepCopy":       true,
+	"DeepCopyInto":   true,
+	"DeepCopyObject": true,
+}
+
+// classifications classifica le funzioni e i metodi del package con
+// euristiche strutturali, nell'ordine:
+//
+//   - stringer: metodo String() string o GoString() string;
+//   - getter: metodo senza parametri che restituisce un campo del receiver
+//     ("return r.f"), anche con il controllo "if r != nil" dei getter di
+//     protobuf, o metodo Get* senza parametri in un file generato;
+//   - setter: metodo con receiver puntatore, un parametro e nessun
+//     risultato, il cui corpo assegna il parametro a un campo del receiver;
+//   - constructor: funzione New/NewX/newX il cui primo risultato è un tipo
+//     (o un puntatore a un tipo) del package;
+//   - boilerplate: metodi di deepcopy, XXX_* di protobuf e
+//     mustEmbedUnimplemented* di gRPC, metodi con corpo vuoto (marker di
+//     interfaccia) e ogni altro callable di un file generato ("Code
+//     generated ... DO NOT EDIT.").
+//
+// I callable senza classificazione non compaiono nella mappa.
+func classifications(pkg *packages.Package) map[*ast.FuncDecl]string {
+	info := pkg.TypesInfo
+	if info == nil {
+		return nil
+	}
+	out := make(map[*ast.FuncDecl]string)
+	for _, file := range pkg.Syntax {
+		if file == nil {
+			continue
+		}
+		generated := ast.IsGenerated(file)
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			obj, ok := info.Defs[fn.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			if class := classify(fn, obj, info, generated); class != "" {
+				out[fn] = class
+			}
+		}
+	}
+	return out
+}
+
+func classify(fn *ast.FuncDecl, obj *types.Func, info *types.Info, generated bool) string {
+	sig := obj.Type().(*types.Signature)
+	name := fn.Name.Name
+	recv := sig.Recv()
+
+	if recv == nil {
+		if isConstructorName(name) && sig.Results().Len() > 0 && localNamed(sig.Results().At(0).Type(), obj.Pkg()) {
+			return ClassConstructor
+		}
+		if generated {
+			return ClassBoilerplate
+		}
+		return ""
+	}
+
+	switch {
+	case (name == "String" || name == "GoString") && sig.Params().Len() == 0 && sig.Results().Len() == 1 && isString(sig.Results().At(0).Type()):
+		return ClassStringer
+	case sig.Params().Len() == 0 && sig.Results().Len() == 1 && (isGetter(fn.Body, recv, info) || generated && strings.HasPrefix(name, "Get")):
+		return ClassGetter
+	case sig.Params().Len() == 1 && sig.Results().Len() == 0 && isPointer(recv.Type()) && isSetter(fn.Body, recv, sig.Params().At(0), info):
+		return ClassSetter
+	case boilerplateMethods[name] || strings.HasPrefix(name, "XXX_") || strings.HasPrefix(name, "mustEmbedUnimplemented"):
+		return ClassBoilerplate
+	case len(fn.Body.List) == 0 || generated:
+		return ClassBoilerplate
+	}
+	return ""
+}
+
+// isGetter riconosce "return r.f" e il getter di protobuf
+// "if r != nil { return r.f }; return <zero>".
+func isGetter(body *ast.BlockStmt, recv *types.Var, info *types.Info) bool {
+	switch len(body.List) {
+	case 1:
+		return returnsField(body.List[0], recv, info)
+	case 2:
+		guard, ok := body.List[0].(*ast.IfStmt)
+		if !ok || guard.Init != nil || guard.Else != nil || len(guard.Body.List) != 1 || !returnsField(guard.Body.List[0], recv, info) {
+			return false
+		}
+		cond, ok := ast.Unparen(guard.Cond).(*ast.BinaryExpr)
+		if !ok || cond.Op != token.NEQ || !isIdentOf(cond.X, recv, info) || !isNilExpr(cond.Y, info) {
+			return false
+		}
+		ret, ok := body.List[1].(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return false
+		}
+		tv, ok := info.Types[ret.Results[0]]
+		return ok && (tv.Value != nil || tv.IsNil() || isEmptyLit(ret.Results[0]))
+	}
+	return false
+}
+
+// returnsField riconosce "return r.f" (anche r.a.b).
+func returnsField(stmt ast.Stmt, recv *types.Var, info *types.Info) bool {
+	ret, ok := stmt.(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return false
+	}
+	return isFieldOf(ret.Results[0], recv, info)
+}
+
+// isSetter riconosce "r.f = p".
+func isSetter(body *ast.BlockStmt, recv, param *types.Var, info *types.Info) bool {
+	if len(body.List) != 1 {
+		return false
+	}
+	assign, ok := body.List[0].(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return false
+	}
+	return isFieldOf(assign.Lhs[0], recv, info) && isIdentOf(assign.Rhs[0], param, info)
+}
+
+// isFieldOf riporta se e è una catena di selettori di campi sul receiver.
+func isFieldOf(e ast.Expr, recv *types.Var, info *types.Info) bool {
+	sel, ok := ast.Unparen(e).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	for {
+		if s := info.Selections[sel]; s == nil || s.Kind() != types.FieldVal {
+			return false
+		}
+		switch x := ast.Unparen(sel.X).(type) {
+		case *ast.SelectorExpr:
+			sel = x
+		default:
+			return isIdentOf(x, recv, info)
+		}
+	}
+}
+
+func isIdentOf(e ast.Expr, v *types.Var, info *types.Info) bool {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	return ok && v != nil && info.Uses[id] == v
+}
+
+func isNilExpr(e ast.Expr, info *types.Info) bool {
+	tv, ok := info.Types[e]
+	return ok && tv.IsNil()
+}
+
+func isEmptyLit(e ast.Expr) bool {
+	lit, ok := ast.Unparen(e).(*ast.CompositeLit)
+	return ok && len(lit.Elts) == 0
+}
+
+// isConstructorName riconosce New, NewX e newX.
+func isConstructorName(name string) bool {
+	for _, prefix := range []string{"New", "new"} {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			if rest == "" {
+				return prefix == "New"
+			}
+			r, _ := utf8.DecodeRuneInString(rest)
+			return unicode.IsUpper(r)
+		}
+	}
+	return false
+}
+
+// localNamed riporta se t è un tipo nominato di pkg o un puntatore a esso.
+func localNamed(t types.Type, pkg *types.Package) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := types.Unalias(t).(*types.Named)
+	return ok && n.Obj().Pkg() == pkg
+}
+
+func isString(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.String
+}
+
+func isPointer(t types.Type) bool {
+	_, ok := t.(*types.Pointer)
+	return ok
+}
@@ -0,0 +1,165 @@
+package symbols
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// stmtList è una sequenza di statement (corpo di un blocco, case o comm
+// clause) con lo statement che la contiene (nil per il corpo della funzione).
+type stmtList struct {
+	list      []ast.Stmt
+	owner     ast.Stmt
+	open, end token.Pos // "{" o ":" di apertura, fine della sequenza
+}
+
+// bodyComments associa ogni commento dentro body allo statement più vicino
+// della sequenza più interna che lo contiene: lo statement che termina
+// sulla stessa riga (trailing), altrimenti il successivo (leading),
+// altrimenti il precedente (after). Un commento sulla riga di apertura di un
+// blocco, o in un blocco vuoto, va allo statement che possiede il blocco.
+func bodyComments(body *ast.BlockStmt, comments []*ast.CommentGroup, fset *token.FileSet, root string) []schema.CLDKBodyComment {
+	var inBody []*ast.CommentGroup
+	for _, cg := range comments {
+		if cg.Pos() > body.Lbrace && cg.End() <= body.Rbrace {
+			inBody = append(inBody, cg)
+		}
+	}
+	if len(inBody) == 0 {
+		return nil
+	}
+
+	lists := []stmtList{{list: body.List, open: body.Lbrace, end: body.Rbrace}}
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		var owner ast.Stmt
+		for i := len(stack) - 1; i >= 0; i-- {
+			if s, ok := stack[i].(ast.Stmt); ok {
+				owner = s
+				break
+			}
+		}
+		switch x := n.(type) {
+		case *ast.BlockStmt:
+			if x != body {
+				lists = append(lists, stmtList{list: x.List, owner: owner, open: x.Lbrace, end: x.Rbrace})
+			}
+		case *ast.CaseClause:
+			lists = append(lists, stmtList{list: x.Body, owner: x, open: x.Colon, end: x.End()})
+		case *ast.CommClause:
+			lists = append(lists, stmtList{list: x.Body, owner: x, open: x.Colon, end: x.End()})
+		}
+		stack = append(stack, n)
+		return true
+	})
+
+	line := func(p token.Pos) int { return fset.Position(p).Line }
+
+	var out []schema.CLDKBodyComment
+	for _, cg := range inBody {
+		// Sequenza più interna che contiene il commento
+		var in *stmtList
+		for i := range lists {
+			l := &lists[i]
+			if l.open < cg.Pos() && cg.End() <= l.end && (in == nil || l.end-l.open < in.end-in.open) {
+				in = l
+			}
+		}
+		if in == nil {
+			continue
+		}
+
+		var stmt ast.Stmt
+		placement := ""
+		for _, s := range in.list {
+			if s.End() <= cg.Pos() && line(s.End()) == line(cg.Pos()) {
+				stmt, placement = s, "trailing"
+			}
+		}
+		if stmt == nil && in.owner != nil && line(in.open) == line(cg.Pos()) {
+			stmt, placement = in.owner, "trailing"
+		}
+		if stmt == nil {
+			for _, s := range in.list {
+				if s.Pos() >= cg.End() {
+					stmt, placement = s, "leading"
+					break
+				}
+			}
+		}
+		if stmt == nil {
+			for _, s := range in.list {
+				if s.End() <= cg.Pos() {
+					stmt, placement = s, "after"
+				}
+			}
+		}
+		if stmt == nil && in.owner != nil {
+			stmt, placement = in.owner, "inside"
+		}
+
+		c := schema.CLDKBodyComment{
+			Text:     cleanDoc(cg.Text()),
+			Position: spanOf(fset, cg.Pos(), cg.End(), root),
+		}
+		if stmt != nil {
+			c.Placement = placement
+			c.Statement = stmtKind(stmt)
+			c.StatementSpan = spanOf(fset, stmt.Pos(), stmt.End(), root)
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// stmtKind restituisce il tipo di statement usato in CLDKBodyComment.
+func stmtKind(s ast.Stmt) string {
+	switch x := s.(type) {
+	case *ast.AssignStmt:
+		return "assign"
+	case *ast.DeclStmt:
+		return "decl"
+	case *ast.ExprStmt:
+		if _, ok := ast.Unparen(x.X).(*ast.CallExpr); ok {
+			return "call"
+		}
+		return "expr"
+	case *ast.IfStmt:
+		return "if"
+	case *ast.ForStmt:
+		return "for"
+	case *ast.RangeStmt:
+		return "range"
+	case *ast.SwitchStmt:
+		return "switch"
+	case *ast.TypeSwitchStmt:
+		return "type_switch"
+	case *ast.SelectStmt:
+		return "select"
+	case *ast.CaseClause, *ast.CommClause:
+		return "case"
+	case *ast.ReturnStmt:
+		return "return"
+	case *ast.GoStmt:
+		return "go"
+	case *ast.DeferStmt:
+		return "defer"
+	case *ast.SendStmt:
+		return "send"
+	case *ast.IncDecStmt:
+		return "incdec"
+	case *ast.BranchStmt:
+		return "branch"
+	case *ast.LabeledStmt:
+		return "labeled"
+	case *ast.BlockStmt:
+		return "block"
+	}
+	return "other"
+}
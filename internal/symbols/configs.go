@@ -0,0 +1,132 @@
+package symbols
+
+import (
+	"sort"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// MergeConfigs unisce le symbol table estratte per più configurazioni di
+// build (tables[i] per names[i]) nella prima, che viene restituita. Ogni
+// package e simbolo riceve in Configs le configurazioni in cui esiste. Lo
+// stesso qualified name in più configurazioni è un solo simbolo: posizione,
+// firma e documentazione sono quelle della prima configurazione che lo
+// dichiara, file e import dei package sono uniti.
+func MergeConfigs(tables []*schema.CLDKSymbolTable, names []string) *schema.CLDKSymbolTable {
+	if len(tables) == 0 {
+		return nil
+	}
+	dst := tables[0]
+	for _, pkg := range dst.Packages {
+		tagPackage(pkg, names[0])
+	}
+	for i := 1; i < len(tables); i++ {
+		for path, pkg := range tables[i].Packages {
+			tagPackage(pkg, names[i])
+			if have, ok := dst.Packages[path]; ok {
+				mergePackage(have, pkg)
+			} else {
+				dst.Packages[path] = pkg
+			}
+		}
+	}
+	return dst
+}
+
+// tagPackage aggiunge name alle configurazioni del package e dei suoi simboli.
+func tagPackage(pkg *schema.CLDKPackage, name string) {
+	pkg.Configs = append(pkg.Configs, name)
+	for _, t := range pkg.TypeDeclarations {
+		t.Configs = append(t.Configs, name)
+		for _, m := range t.Methods {
+			m.Configs = append(m.Configs, name)
+		}
+	}
+	for _, c := range pkg.CallableDeclarations {
+		c.Configs = append(c.Configs, name)
+	}
+	for _, v := range pkg.Variables {
+		v.Configs = append(v.Configs, name)
+	}
+	for _, c := range pkg.Constants {
+		c.Configs = append(c.Configs, name)
+	}
+}
+
+// mergePackage aggiunge a dst i simboli di src (già etichettato) assenti in
+// dst e, per quelli presenti, le configurazioni di src.
+func mergePackage(dst, src *schema.CLDKPackage) {
+	dst.Configs = append(dst.Configs, src.Configs...)
+	dst.Files = unionSorted(dst.Files, src.Files)
+	dst.BuildTags = unionSorted(dst.BuildTags, src.BuildTags)
+	dst.HasInit = dst.HasInit || src.HasInit
+	dst.HasGoroutines = dst.HasGoroutines || src.HasGoroutines
+	dst.ReadsEnv = dst.ReadsEnv || src.ReadsEnv
+	if len(dst.ProtoFiles) == 0 {
+		dst.ProtoFiles = src.ProtoFiles
+	}
+
+	imports := make(map[string]bool, len(dst.Imports))
+	for _, imp := range dst.Imports {
+		imports[imp.Path+":"+imp.Alias] = true
+	}
+	for _, imp := range src.Imports {
+		if !imports[imp.Path+":"+imp.Alias] {
+			dst.Imports = append(dst.Imports, imp)
+		}
+	}
+	sort.SliceStable(dst.Imports, func(i, j int) bool { return dst.Imports[i].Path < dst.Imports[j].Path })
+
+	for qn, t := range src.TypeDeclarations {
+		have, ok := dst.TypeDeclarations[qn]
+		if !ok {
+			dst.TypeDeclarations[qn] = t
+			continue
+		}
+		have.Configs = append(have.Configs, t.Configs...)
+		for mqn, m := range t.Methods {
+			if hm, ok := have.Methods[mqn]; ok {
+				hm.Configs = append(hm.Configs, m.Configs...)
+				continue
+			}
+			if have.Methods == nil {
+				have.Methods = make(map[string]*schema.CLDKMethod)
+			}
+			have.Methods[mqn] = m
+		}
+	}
+	for qn, c := range src.CallableDeclarations {
+		if have, ok := dst.CallableDeclarations[qn]; ok {
+			have.Configs = append(have.Configs, c.Configs...)
+		} else {
+			dst.CallableDeclarations[qn] = c
+		}
+	}
+	for qn, v := range src.Variables {
+		if have, ok := dst.Variables[qn]; ok {
+			have.Configs = append(have.Configs, v.Configs...)
+		} else {
+			dst.Variables[qn] = v
+		}
+	}
+	for qn, c := range src.Constants {
+		if have, ok := dst.Constants[qn]; ok {
+			have.Configs = append(have.Configs, c.Configs...)
+		} else {
+			dst.Constants[qn] = c
+		}
+	}
+}
+
+func unionSorted(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	out := make([]string, 0, len(a)+len(b))
+	for _, s := range append(append([]string{}, a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,81 @@
+package symbols
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// CodeDuplicateSymbol identifica gli issue emessi quando due dichiarazioni
+// distinte producono lo stesso qualified name.
+const CodeDuplicateSymbol = "DUPLICATE_SYMBOL"
+
+// symbolIndex ricorda, per un package path, quale dichiarazione sorgente
+// occupa ogni chiave delle mappe di CLDKPackage. Le varianti dello stesso
+// package (es. "p [p.test]") condividono l'indice: una dichiarazione già vista
+// non viene duplicata, mentre una dichiarazione diversa con lo stesso nome
+// viene conservata sotto una chiave con suffisso "#N". Per le funzioni init
+// N segue l'ordine di sorgente, come i nomi "init#N" di SSA (vedi
+// ids.SymbolRef).
+type symbolIndex struct {
+	fset       *token.FileSet
+	root       string
+	at         map[string]token.Position
+	onConflict func(schema.Issue)
+}
+
+func newSymbolIndex(fset *token.FileSet, root string, onConflict func(schema.Issue)) *symbolIndex {
+	return &symbolIndex{fset: fset, root: root, at: make(map[string]token.Position), onConflict: onConflict}
+}
+
+// claim restituisce la chiave sotto cui registrare la dichiarazione di
+// tipo kind (callable|type|method|variable|constant) nominata key e
+// dichiarata in pos. Restituisce "" se la stessa dichiarazione è già
+// registrata.
+func (x *symbolIndex) claim(kind, key, name string, pos token.Pos) string {
+	p := x.fset.Position(pos)
+	slot := kind + ":" + key
+	prev, taken := x.at[slot]
+	if !taken {
+		x.at[slot] = p
+		return key
+	}
+	if prev == p {
+		return ""
+	}
+
+	n := 2
+	for ; ; n++ {
+		if q, ok := x.at[fmt.Sprintf("%s#%d", slot, n)]; !ok {
+			break
+		} else if q == p {
+			return ""
+		}
+	}
+	alt := fmt.Sprintf("%s#%d", key, n)
+	x.at[kind+":"+alt] = p
+
+	// init multipli e "_" sono legali in Go: non sono conflitti.
+	if x.onConflict != nil && name != "init" && name != "_" {
+		x.onConflict(schema.Issue{
+			Severity: "warning",
+			Code:     CodeDuplicateSymbol,
+			Message:  fmt.Sprintf("%s %s also declared at %s; kept as %s", kind, key, x.location(prev), alt),
+			Position: &schema.CLDKPosition{File: x.rel(p.Filename), StartLine: p.Line, StartColumn: p.Column},
+		})
+	}
+	return alt
+}
+
+func (x *symbolIndex) location(p token.Position) string {
+	return fmt.Sprintf("%s:%d", x.rel(p.Filename), p.Line)
+}
+
+func (x *symbolIndex) rel(file string) string {
+	if rel, err := filepath.Rel(x.root, file); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
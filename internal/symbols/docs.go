@@ -0,0 +1,194 @@
+package symbols
+
+import (
+	"go/ast"
+	"go/doc/comment"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+)
+
+// docRenderer converte i doc comment di un file in Markdown con
+// go/doc/comment: paragrafi, blocchi di codice, elenchi e titoli restano
+// distinti e i doc link ([Name], [pkg.Name], [T.M]) puntano all'ID del
+// simbolo, la stessa chiave della symbol table.
+type docRenderer struct {
+	pkg      *types.Package
+	imports  map[string]string         // nome nel file -> import path
+	packages map[string]*types.Package // import path -> package, per i link esterni
+	parser   comment.Parser
+}
+
+func newDocRenderer(pkg *packages.Package, file *ast.File) *docRenderer {
+	r := &docRenderer{
+		pkg:      pkg.Types,
+		imports:  make(map[string]string),
+		packages: make(map[string]*types.Package),
+	}
+	for _, imp := range file.Imports {
+		if pkg.TypesInfo == nil {
+			break
+		}
+		if pn := pkg.TypesInfo.PkgNameOf(imp); pn != nil && pn.Name() != "_" && pn.Name() != "." {
+			r.imports[pn.Name()] = pn.Imported().Path()
+		}
+	}
+	if r.pkg != nil {
+		r.packages[r.pkg.Path()] = r.pkg
+		for _, p := range r.pkg.Imports() {
+			r.packages[p.Path()] = p
+		}
+	}
+	r.parser = comment.Parser{
+		LookupPackage: r.lookupPackage,
+		LookupSym:     r.lookupSym,
+	}
+	return r
+}
+
+// render restituisce il Markdown della documentazione cg. A differenza di
+// comment.Printer il testo non è escapato, i titoli non hanno ancore e i
+// blocchi di codice sono recintati da ```.
+func (r *docRenderer) render(cg *ast.CommentGroup) string {
+	doc := r.parser.Parse(cg.Text())
+	var b strings.Builder
+	for i, blk := range doc.Content {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		r.block(&b, blk, "")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (r *docRenderer) block(b *strings.Builder, blk comment.Block, indent string) {
+	switch x := blk.(type) {
+	case *comment.Heading:
+		b.WriteString(indent + "### ")
+		r.text(b, x.Text)
+		b.WriteString("\n")
+	case *comment.Paragraph:
+		b.WriteString(indent)
+		r.text(b, x.Text)
+		b.WriteString("\n")
+	case *comment.Code:
+		b.WriteString(indent + "```go\n")
+		for _, line := range strings.Split(strings.TrimSuffix(x.Text, "\n"), "\n") {
+			if line != "" {
+				b.WriteString(indent + line)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "```\n")
+	case *comment.List:
+		for i, item := range x.Items {
+			if i > 0 && x.BlankBetween() {
+				b.WriteString("\n")
+			}
+			marker := "- "
+			if item.Number != "" {
+				marker = item.Number + ". "
+			}
+			for j, c := range item.Content {
+				if j == 0 {
+					b.WriteString(indent + marker)
+					r.block(b, c, "")
+				} else {
+					r.block(b, c, indent+strings.Repeat(" ", len(marker)))
+				}
+			}
+		}
+	}
+}
+
+func (r *docRenderer) text(b *strings.Builder, text []comment.Text) {
+	for _, t := range text {
+		switch x := t.(type) {
+		case comment.Plain:
+			b.WriteString(strings.ReplaceAll(string(x), "\n", " "))
+		case comment.Italic:
+			b.WriteString(strings.ReplaceAll(string(x), "\n", " "))
+		case *comment.Link:
+			if x.Auto {
+				r.text(b, x.Text)
+				continue
+			}
+			b.WriteString("[")
+			r.text(b, x.Text)
+			b.WriteString("](" + x.URL + ")")
+		case *comment.DocLink:
+			b.WriteString("[")
+			r.text(b, x.Text)
+			b.WriteString("](" + r.target(x) + ")")
+		}
+	}
+}
+
+func (r *docRenderer) lookupPackage(name string) (string, bool) {
+	if path, ok := r.imports[name]; ok {
+		return path, true
+	}
+	return comment.DefaultLookupPackage(name)
+}
+
+// lookupSym riconosce i simboli del package corrente e i loro metodi.
+func (r *docRenderer) lookupSym(recv, name string) bool {
+	if r.pkg == nil {
+		return true
+	}
+	if recv == "" {
+		return r.pkg.Scope().Lookup(name) != nil
+	}
+	return r.method(r.pkg, recv, name) != nil
+}
+
+// target restituisce l'ID del simbolo di un doc link, o l'import path per
+// i link a un package.
+func (r *docRenderer) target(link *comment.DocLink) string {
+	path := link.ImportPath
+	if path == "" && r.pkg != nil {
+		path = r.pkg.Path()
+	}
+	switch {
+	case link.Name == "":
+		return path
+	case link.Recv != "":
+		if m := r.method(r.packages[path], link.Recv, link.Name); m != nil {
+			return ids.Object(m)
+		}
+		return ids.Method(path, link.Recv, false, link.Name)
+	default:
+		return ids.Type(path, link.Name)
+	}
+}
+
+// method cerca il metodo name dichiarato sul tipo recv di pkg.
+func (r *docRenderer) method(pkg *types.Package, recv, name string) *types.Func {
+	if pkg == nil {
+		return nil
+	}
+	tn, ok := pkg.Scope().Lookup(recv).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	named, ok := types.Unalias(tn.Type()).(*types.Named)
+	if !ok {
+		return nil
+	}
+	for i := 0; i < named.NumMethods(); i++ {
+		if m := named.Method(i); m.Name() == name {
+			return m
+		}
+	}
+	if iface, ok := named.Underlying().(*types.Interface); ok {
+		for i := 0; i < iface.NumMethods(); i++ {
+			if m := iface.Method(i); m.Name() == name {
+				return m
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package symbols
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+const (
+	maxCallExamples   = 3   // esempi per callable
+	maxExampleExprLen = 300 // testo della chiamata, oltre viene troncato
+	snippetContext    = 2   // righe prima e dopo la chiamata
+	maxSnippetLines   = 12  // righe di una chiamata su più righe incluse nello snippet
+)
+
+// exampleCollector raccoglie le chiamate ai callable del progetto dai corpi
+// di tutti i package, così che gli esempi di un'API esportata includano i
+// chiamanti degli altri package.
+type exampleCollector struct {
+	result   *loader.LoadResult
+	fset     *token.FileSet
+	root     string
+	snippets bool
+
+	candidates map[string]*exampleSet // ID del callee -> candidati
+	files      map[string]bool        // file già visitati (varianti di test)
+	lines      map[string][]string    // cache delle righe per gli snippet
+}
+
+// Classi dei chiamanti, in ordine di preferenza: l'uso reale di un'API viene
+// dagli altri package, i test ne mostrano casi limite e fixture.
+const (
+	classExternal = iota // chiamante in un altro package
+	classInternal        // chiamante nello stesso package
+	classTest            // chiamante in un file _test.go
+	numClasses
+)
+
+// maxCandidates limita i candidati tenuti per classe: bastano a scegliere
+// maxCallExamples esempi con chiamanti diversi.
+const maxCandidates = 4 * maxCallExamples
+
+// exampleSet sono i candidati di un callee, divisi per classe.
+type exampleSet struct {
+	classes [numClasses][]candidate
+	exprs   map[string]bool
+}
+
+// candidate è un call example con il nome del chiamante, per la forma
+// testuale di CallExamples.
+type candidate struct {
+	ex   schema.CLDKCallExample
+	name string
+}
+
+// populateCallExamples popola CallExampleSites di ogni callable con al più
+// maxCallExamples chiamate reali (testo della chiamata con gli argomenti e,
+// con ExampleSnippets, le righe attorno), raccolte da tutti i package:
+// prima i chiamanti degli altri package, poi quelli dello stesso package,
+// per ultimi i test. CallExamples ne riporta la forma testuale
+// "called by Caller() [kind]".
+func populateCallExamples(st *schema.CLDKSymbolTable, pkgs []*packages.Package, result *loader.LoadResult, cfg ExtractConfig) {
+	x := newExampleCollector(result, cfg.ExampleSnippets)
+	for _, pkg := range pkgs {
+		if pkg != nil {
+			x.collect(pkg)
+		}
+	}
+	x.assign(st)
+}
+
+func newExampleCollector(result *loader.LoadResult, snippets bool) *exampleCollector {
+	return &exampleCollector{
+		result:     result,
+		fset:       result.Fset,
+		root:       result.Root,
+		snippets:   snippets,
+		candidates: make(map[string]*exampleSet),
+		files:      make(map[string]bool),
+		lines:      make(map[string][]string),
+	}
+}
+
+// collect visita i corpi delle funzioni del package, closure comprese, e
+// registra le chiamate statiche a funzioni e metodi concreti.
+func (x *exampleCollector) collect(pkg *packages.Package) {
+	if pkg.TypesInfo == nil {
+		return
+	}
+	for _, file := range pkg.Syntax {
+		if file == nil {
+			continue
+		}
+		name := x.fset.Position(file.Package).Filename
+		if x.files[name] {
+			continue
+		}
+		x.files[name] = true
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			caller, name := fn.Name.Name, fn.Name.Name
+			if obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+				caller = ids.Object(obj)
+			}
+			kinds := make(map[*ast.CallExpr]string)
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch s := n.(type) {
+				case *ast.DeferStmt:
+					kinds[s.Call] = "defer"
+				case *ast.GoStmt:
+					kinds[s.Call] = "go"
+				case *ast.CallExpr:
+					x.add(pkg, fn.Body, caller, name, s, kinds[s])
+				}
+				return true
+			})
+		}
+	}
+}
+
+// add registra la chiamata come candidato per il callee: i candidati sono
+// divisi per classe del chiamante e la scelta avviene in assign. body è il
+// corpo della funzione chiamante, a cui è limitato lo snippet.
+func (x *exampleCollector) add(pkg *packages.Package, body *ast.BlockStmt, caller, name string, call *ast.CallExpr, kind string) {
+	callee, ok := typeutil.Callee(pkg.TypesInfo, call).(*types.Func)
+	if !ok || callee.Pkg() == nil {
+		return
+	}
+	if sig, ok := callee.Type().(*types.Signature); ok && sig.Recv() != nil && types.IsInterface(sig.Recv().Type()) {
+		return // metodo astratto: nessuna dichiarazione da annotare
+	}
+	id := ids.Object(callee)
+	set := x.candidates[id]
+	if set == nil {
+		set = &exampleSet{exprs: make(map[string]bool)}
+		x.candidates[id] = set
+	}
+	class := classInternal
+	if strings.HasSuffix(x.fset.Position(call.Pos()).Filename, "_test.go") {
+		class = classTest
+	} else if pkg.Types != nil && callee.Pkg().Path() != pkg.Types.Path() {
+		class = classExternal
+	}
+	if len(set.classes[class]) >= maxCandidates {
+		return
+	}
+	expr := truncateExpr(exprString(call), maxExampleExprLen)
+	if set.exprs[expr] {
+		return
+	}
+	set.exprs[expr] = true
+
+	if kind == "" {
+		kind = "call"
+	}
+	ex := schema.CLDKCallExample{
+		Caller:     caller,
+		Kind:       kind,
+		Expression: expr,
+		Test:       class == classTest,
+		Position:   posOf(x.fset, call.Pos(), x.root),
+	}
+	if x.snippets {
+		ex.Snippet = x.snippet(call, body)
+	}
+	set.classes[class] = append(set.classes[class], candidate{ex, name})
+}
+
+// best sceglie fino a maxCallExamples esempi seguendo l'ordine delle
+// classi; in ogni classe preferisce prima chiamanti non ancora usati.
+func (set *exampleSet) best() []candidate {
+	var out []candidate
+	callers := make(map[string]bool)
+	for _, cands := range set.classes {
+		used := make([]bool, len(cands))
+		for _, distinct := range []bool{true, false} {
+			for i, c := range cands {
+				if len(out) == maxCallExamples {
+					return out
+				}
+				if used[i] || (distinct && callers[c.ex.Caller]) {
+					continue
+				}
+				used[i] = true
+				callers[c.ex.Caller] = true
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// snippet restituisce le righe della chiamata con snippetContext righe di
+// contesto per lato, senza righe vuote ai bordi né l'indentazione comune.
+// Il contesto non esce dal corpo del chiamante: le graffe che lo aprono e
+// lo chiudono, e le dichiarazioni vicine, non fanno parte dell'esempio. Il
+// file è letto attraverso il loader (overlay e --root-archive compresi).
+func (x *exampleCollector) snippet(call *ast.CallExpr, body *ast.BlockStmt) string {
+	start, end := x.fset.Position(call.Pos()), x.fset.Position(call.End())
+	if !start.IsValid() {
+		return ""
+	}
+	lines, ok := x.lines[start.Filename]
+	if !ok {
+		if data, err := x.result.ReadFile(start.Filename); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		x.lines[start.Filename] = lines
+	}
+	last := end.Line
+	if last > start.Line+maxSnippetLines-1 {
+		last = start.Line + maxSnippetLines - 1
+	}
+	from, to := start.Line-snippetContext, last+snippetContext
+	if open := x.fset.Position(body.Lbrace).Line + 1; from < open {
+		from = min(open, start.Line)
+	}
+	if closing := x.fset.Position(body.Rbrace).Line - 1; to > closing {
+		to = max(closing, last)
+	}
+	if from < 1 {
+		from = 1
+	}
+	if to > len(lines) {
+		to = len(lines)
+	}
+	for from <= to && strings.TrimSpace(lines[from-1]) == "" {
+		from++
+	}
+	for to >= from && strings.TrimSpace(lines[to-1]) == "" {
+		to--
+	}
+	if from > to {
+		return ""
+	}
+	return dedent(lines[from-1 : to])
+}
+
+// assign imposta CallExampleSites e CallExamples sui callable dell'intera
+// symbol table, scegliendo i migliori tra i candidati di tutti i package.
+func (x *exampleCollector) assign(st *schema.CLDKSymbolTable) {
+	for _, pkg := range st.Packages {
+		for key, cd := range pkg.CallableDeclarations {
+			set := x.candidates[key]
+			if set == nil {
+				continue
+			}
+			seen := make(map[string]bool)
+			for _, c := range set.best() {
+				cd.CallExampleSites = append(cd.CallExampleSites, c.ex)
+				text := fmt.Sprintf("called by %s() [%s]", c.name, c.ex.Kind)
+				if !seen[text] {
+					seen[text] = true
+					cd.CallExamples = append(cd.CallExamples, text)
+				}
+			}
+		}
+	}
+}
+
+// truncateExpr tronca s a n byte senza spezzare un carattere UTF-8.
+func truncateExpr(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
+// dedent unisce le righe togliendo il prefisso di spazi comune a quelle non
+// vuote.
+func dedent(lines []string) string {
+	prefix := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = strings.TrimRight(strings.TrimPrefix(l, prefix), " \t\r")
+	}
+	return strings.Join(out, "\n")
+}
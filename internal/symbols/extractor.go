@@ -3,11 +3,11 @@ package symbols
 
 import (
 	"bytes"
-	"fmt"
 	"go/ast"
 	"go/build/constraint"
 	"go/printer"
 	"go/token"
+	"go/types"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -15,6 +15,8 @@ import (
 
 	"golang.org/x/tools/go/packages"
 
+	"github.com/codellm-devkit/codeanalyzer-go/internal/clones"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
 	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
 	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
 )
@@ -37,6 +39,25 @@ type ExtractConfig struct {
 	IncludeBody      bool   // include informazioni sul corpo delle funzioni
 	EmitPositions    string // detailed|minimal
 	IncludeCallSites bool   // estrai call sites nel body
+	IncludeComments  bool   // associa i commenti nel body agli statement (richiede IncludeBody)
+	ExampleSnippets  bool   // aggiunge le righe attorno a ogni call example (richiede IncludeBody)
+	FlatDocs         bool   // documentazione su una riga (forma legacy) invece del Markdown
+	Examples         bool   // funzioni ExampleXxx dei file _test.go, associate ai simboli
+	Fingerprints     bool   // impronta strutturale di ogni callable con corpo (vedi clones.Fingerprint)
+
+	docs *docRenderer // renderer del file in estrazione, impostato da extractPackage
+
+	OnPackage  func(done, total int) // callback opzionale di avanzamento
+	OnConflict func(schema.Issue)    // callback opzionale per i qualified name duplicati
+}
+
+// docText restituisce la documentazione di cg: Markdown con i doc link
+// risolti, o la forma appiattita di cleanDoc con FlatDocs.
+func (cfg ExtractConfig) docText(cg *ast.CommentGroup) string {
+	if cfg.FlatDocs || cfg.docs == nil {
+		return cleanDoc(cg.Text())
+	}
+	return cfg.docs.render(cg)
 }
 
 // Extract estrae la symbol table CLDK da un LoadResult.
@@ -45,53 +66,95 @@ func Extract(result *loader.LoadResult, cfg ExtractConfig) *schema.CLDKSymbolTab
 		Packages: make(map[string]*schema.CLDKPackage),
 	}
 
-	for _, pkg := range result.Packages {
-		if pkg == nil {
-			continue
+	// Le varianti di test ("p [p.test]") condividono il PkgPath del package
+	// base: confluiscono nella stessa entry tramite un indice comune.
+	indexes := make(map[string]*symbolIndex)
+	pkgs := result.ScopedPackages()
+	for i, pkg := range pkgs {
+		if pkg != nil {
+			idx := indexes[pkg.PkgPath]
+			if idx == nil {
+				idx = newSymbolIndex(result.Fset, result.Root, cfg.OnConflict)
+				indexes[pkg.PkgPath] = idx
+			}
+			st.Packages[pkg.PkgPath] = extractPackage(pkg, st.Packages[pkg.PkgPath], idx, result.Fset, result.Root, cfg)
+		}
+		if cfg.OnPackage != nil {
+			cfg.OnPackage(i+1, len(pkgs))
 		}
+	}
 
-		cldkPkg := extractPackage(pkg, result.Fset, result.Root, cfg)
-		st.Packages[pkg.PkgPath] = cldkPkg
+	// Call examples: raccolti da tutti i package se il body è incluso
+	if cfg.IncludeBody && cfg.IncludeCallSites {
+		populateCallExamples(st, pkgs, result, cfg)
+	}
+	if cfg.Examples {
+		populateExamples(st, pkgs, result)
 	}
+	populateImplements(st, pkgs)
+	populateProto(st, pkgs, result.Fset, result.Root)
 
 	return st
 }
 
-// extractPackage estrae un singolo pacchetto.
-func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg ExtractConfig) *schema.CLDKPackage {
-	cldkPkg := &schema.CLDKPackage{
-		Path:                 pkg.PkgPath,
-		Name:                 pkg.Name,
-		Files:                make([]string, 0),
-		Imports:              make([]schema.CLDKImport, 0),
-		TypeDeclarations:     make(map[string]*schema.CLDKType),
-		CallableDeclarations: make(map[string]*schema.CLDKCallable),
-		Variables:            make(map[string]*schema.CLDKVariable),
-		Constants:            make(map[string]*schema.CLDKConstant),
+// extractPackage estrae un singolo pacchetto. Se into non è nil (variante
+// di test di un package già estratto) le dichiarazioni vi vengono aggiunte;
+// idx risolve i qualified name duplicati.
+func extractPackage(pkg *packages.Package, into *schema.CLDKPackage, idx *symbolIndex, fset *token.FileSet, root string, cfg ExtractConfig) *schema.CLDKPackage {
+	cldkPkg := into
+	if cldkPkg == nil {
+		cldkPkg = &schema.CLDKPackage{
+			Path:                 pkg.PkgPath,
+			Name:                 pkg.Name,
+			Files:                make([]string, 0),
+			Imports:              make([]schema.CLDKImport, 0),
+			TypeDeclarations:     make(map[string]*schema.CLDKType),
+			CallableDeclarations: make(map[string]*schema.CLDKCallable),
+			Variables:            make(map[string]*schema.CLDKVariable),
+			Constants:            make(map[string]*schema.CLDKConstant),
+		}
 	}
 
 	// Raccogli file
+	fileSet := make(map[string]bool, len(cldkPkg.Files))
+	for _, f := range cldkPkg.Files {
+		fileSet[f] = true
+	}
 	for _, f := range pkg.GoFiles {
 		rel := f
 		if rp, err := filepath.Rel(root, f); err == nil {
 			rel = filepath.ToSlash(rp)
 		}
-		cldkPkg.Files = append(cldkPkg.Files, rel)
+		if !fileSet[rel] {
+			fileSet[rel] = true
+			cldkPkg.Files = append(cldkPkg.Files, rel)
+		}
 	}
 	sort.Strings(cldkPkg.Files)
 
 	// Import set per deduplicazione
 	importSet := make(map[string]schema.CLDKImport)
+	for _, imp := range cldkPkg.Imports {
+		importSet[imp.Path+":"+imp.Alias] = imp
+	}
+
+	stubs := stubKinds(pkg)
+	recvs := receiverMutations(pkg)
+	wraps := wrappers(pkg)
+	classes := classifications(pkg)
 
 	// Processa ogni file di sintassi
 	for _, file := range pkg.Syntax {
 		if file == nil {
 			continue
 		}
+		if !cfg.FlatDocs {
+			cfg.docs = newDocRenderer(pkg, file)
+		}
 
 		// Estrai package documentation dal primo file che ha Doc
 		if cldkPkg.Documentation == "" && file.Doc != nil {
-			cldkPkg.Documentation = cleanDoc(file.Doc.Text())
+			cldkPkg.Documentation = cfg.docText(file.Doc)
 		}
 
 		// Estrai imports
@@ -118,8 +181,18 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 		for _, decl := range file.Decls {
 			switch d := decl.(type) {
 			case *ast.FuncDecl:
-				callable := extractCallable(pkg.PkgPath, d, fset, root, cfg)
-				cldkPkg.CallableDeclarations[callable.QualifiedName] = callable
+				callable := extractCallable(pkg.PkgPath, d, pkg.TypesInfo, fset, root, cfg)
+				callable.Implementation = stubs[d]
+				callable.ReceiverMutation = recvs[d]
+				callable.Wraps = wraps[d]
+				callable.IsWrapper = callable.Wraps != ""
+				callable.Classification = classes[d]
+				if cfg.IncludeComments && callable.Body != nil {
+					callable.Body.Comments = bodyComments(d.Body, file.Comments, fset, root)
+				}
+				if key := idx.claim("callable", callable.QualifiedName, callable.Name, d.Pos()); key != "" {
+					cldkPkg.CallableDeclarations[key] = callable
+				}
 
 			case *ast.GenDecl:
 				switch d.Tok {
@@ -127,7 +200,9 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 					for _, spec := range d.Specs {
 						if ts, ok := spec.(*ast.TypeSpec); ok {
 							t := extractType(pkg.PkgPath, ts, d, fset, root, cfg)
-							cldkPkg.TypeDeclarations[t.QualifiedName] = t
+							if key := idx.claim("type", t.QualifiedName, t.Name, ts.Pos()); key != "" {
+								cldkPkg.TypeDeclarations[key] = t
+							}
 						}
 					}
 
@@ -135,8 +210,10 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 					for _, spec := range d.Specs {
 						if vs, ok := spec.(*ast.ValueSpec); ok {
 							vars := extractVariables(pkg.PkgPath, vs, d, fset, root, cfg)
-							for _, v := range vars {
-								cldkPkg.Variables[v.QualifiedName] = v
+							for i, v := range vars {
+								if key := idx.claim("variable", v.QualifiedName, v.Name, vs.Names[i].Pos()); key != "" {
+									cldkPkg.Variables[key] = v
+								}
 							}
 						}
 					}
@@ -145,8 +222,10 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 					for _, spec := range d.Specs {
 						if vs, ok := spec.(*ast.ValueSpec); ok {
 							consts := extractConstants(pkg.PkgPath, vs, d, fset, root, cfg)
-							for _, c := range consts {
-								cldkPkg.Constants[c.QualifiedName] = c
+							for i, c := range consts {
+								if key := idx.claim("constant", c.QualifiedName, c.Name, vs.Names[i].Pos()); key != "" {
+									cldkPkg.Constants[key] = c
+								}
 							}
 						}
 					}
@@ -159,13 +238,23 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil {
 				recvType := extractReceiverTypeName(fn.Recv)
 				if recvType != "" {
-					typeQN := fmt.Sprintf("%s.%s", pkg.PkgPath, recvType)
+					typeQN := ids.Type(pkg.PkgPath, recvType)
 					if t, exists := cldkPkg.TypeDeclarations[typeQN]; exists {
 						if t.Methods == nil {
 							t.Methods = make(map[string]*schema.CLDKMethod)
 						}
-						method := extractMethod(pkg.PkgPath, fn, fset, root, cfg)
-						t.Methods[method.QualifiedName] = method
+						method := extractMethod(pkg.PkgPath, fn, pkg.TypesInfo, fset, root, cfg)
+						method.Implementation = stubs[fn]
+						method.ReceiverMutation = recvs[fn]
+						method.Wraps = wraps[fn]
+						method.IsWrapper = method.Wraps != ""
+						method.Classification = classes[fn]
+						if cfg.IncludeComments && method.Body != nil {
+							method.Body.Comments = bodyComments(fn.Body, file.Comments, fset, root)
+						}
+						if key := idx.claim("method", method.QualifiedName, method.Name, fn.Pos()); key != "" {
+							t.Methods[key] = method
+						}
 					}
 				}
 			}
@@ -173,6 +262,7 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 	}
 
 	// Converti import set a slice
+	cldkPkg.Imports = cldkPkg.Imports[:0]
 	for _, imp := range importSet {
 		cldkPkg.Imports = append(cldkPkg.Imports, imp)
 	}
@@ -180,11 +270,6 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 		return cldkPkg.Imports[i].Path < cldkPkg.Imports[j].Path
 	})
 
-	// Popola call examples se il body è incluso
-	if cfg.IncludeBody && cfg.IncludeCallSites {
-		populateCallExamples(cldkPkg)
-	}
-
 	// ──────────────────────────────────────────────────────────────────
 	// Package-level metadata for malware/security analysis
 	// ──────────────────────────────────────────────────────────────────
@@ -238,6 +323,9 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 
 	// B1: BuildTags — extract //go:build constraints from file comments
 	tagSet := make(map[string]bool)
+	for _, tag := range cldkPkg.BuildTags {
+		tagSet[tag] = true
+	}
 	for _, file := range pkg.Syntax {
 		if file == nil {
 			continue
@@ -256,6 +344,7 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 		}
 	}
 	if len(tagSet) > 0 {
+		cldkPkg.BuildTags = cldkPkg.BuildTags[:0]
 		for tag := range tagSet {
 			cldkPkg.BuildTags = append(cldkPkg.BuildTags, tag)
 		}
@@ -266,7 +355,7 @@ func extractPackage(pkg *packages.Package, fset *token.FileSet, root string, cfg
 }
 
 // extractCallable estrae una funzione o metodo.
-func extractCallable(pkgPath string, fn *ast.FuncDecl, fset *token.FileSet, root string, cfg ExtractConfig) *schema.CLDKCallable {
+func extractCallable(pkgPath string, fn *ast.FuncDecl, info *types.Info, fset *token.FileSet, root string, cfg ExtractConfig) *schema.CLDKCallable {
 	name := fn.Name.Name
 	var qualifiedName string
 	var kind string
@@ -276,14 +365,10 @@ func extractCallable(pkgPath string, fn *ast.FuncDecl, fset *token.FileSet, root
 	if fn.Recv != nil {
 		kind = "method"
 		recvType, recvPtr = extractReceiverInfo(fn.Recv)
-		if recvPtr {
-			qualifiedName = fmt.Sprintf("%s.(*%s).%s", pkgPath, recvType, name)
-		} else {
-			qualifiedName = fmt.Sprintf("%s.%s.%s", pkgPath, recvType, name)
-		}
+		qualifiedName = ids.Method(pkgPath, recvType, recvPtr, name)
 	} else {
 		kind = "function"
-		qualifiedName = fmt.Sprintf("%s.%s", pkgPath, name)
+		qualifiedName = ids.Func(pkgPath, name)
 	}
 
 	callable := &schema.CLDKCallable{
@@ -306,7 +391,7 @@ func extractCallable(pkgPath string, fn *ast.FuncDecl, fset *token.FileSet, root
 
 	// Documentazione
 	if fn.Doc != nil {
-		callable.Documentation = cleanDoc(fn.Doc.Text())
+		callable.Documentation = cfg.docText(fn.Doc)
 	}
 
 	// Type parameters (generics)
@@ -316,23 +401,21 @@ func extractCallable(pkgPath string, fn *ast.FuncDecl, fset *token.FileSet, root
 
 	// Body info
 	if cfg.IncludeBody && fn.Body != nil {
-		callable.Body = extractFunctionBody(fn.Body, fset, root, cfg)
+		callable.Body = extractFunctionBody(fn.Body, info, fset, root, cfg)
+	}
+	if cfg.Fingerprints {
+		callable.Fingerprint = clones.Fingerprint(fn)
 	}
 
 	return callable
 }
 
 // extractMethod estrae un metodo come CLDKMethod.
-func extractMethod(pkgPath string, fn *ast.FuncDecl, fset *token.FileSet, root string, cfg ExtractConfig) *schema.CLDKMethod {
+func extractMethod(pkgPath string, fn *ast.FuncDecl, info *types.Info, fset *token.FileSet, root string, cfg ExtractConfig) *schema.CLDKMethod {
 	name := fn.Name.Name
 	recvType, recvPtr := extractReceiverInfo(fn.Recv)
 
-	var qualifiedName string
-	if recvPtr {
-		qualifiedName = fmt.Sprintf("%s.(*%s).%s", pkgPath, recvType, name)
-	} else {
-		qualifiedName = fmt.Sprintf("%s.%s.%s", pkgPath, recvType, name)
-	}
+	qualifiedName := ids.Method(pkgPath, recvType, recvPtr, name)
 
 	method := &schema.CLDKMethod{
 		QualifiedName: qualifiedName,
@@ -350,11 +433,14 @@ func extractMethod(pkgPath string, fn *ast.FuncDecl, fset *token.FileSet, root s
 	}
 
 	if fn.Doc != nil {
-		method.Documentation = cleanDoc(fn.Doc.Text())
+		method.Documentation = cfg.docText(fn.Doc)
 	}
 
 	if cfg.IncludeBody && fn.Body != nil {
-		method.Body = extractFunctionBody(fn.Body, fset, root, cfg)
+		method.Body = extractFunctionBody(fn.Body, info, fset, root, cfg)
+	}
+	if cfg.Fingerprints {
+		method.Fingerprint = clones.Fingerprint(fn)
 	}
 
 	return method
@@ -363,7 +449,7 @@ func extractMethod(pkgPath string, fn *ast.FuncDecl, fset *token.FileSet, root s
 // extractType estrae una dichiarazione di tipo.
 func extractType(pkgPath string, ts *ast.TypeSpec, gen *ast.GenDecl, fset *token.FileSet, root string, cfg ExtractConfig) *schema.CLDKType {
 	name := ts.Name.Name
-	qualifiedName := fmt.Sprintf("%s.%s", pkgPath, name)
+	qualifiedName := ids.Type(pkgPath, name)
 
 	t := &schema.CLDKType{
 		QualifiedName: qualifiedName,
@@ -373,13 +459,15 @@ func extractType(pkgPath string, ts *ast.TypeSpec, gen *ast.GenDecl, fset *token
 
 	if cfg.EmitPositions != "minimal" {
 		t.Position = posOf(fset, ts.Pos(), root)
+		t.EndPosition = posOf(fset, ts.End(), root)
+		t.DeclSpan = spanOf(fset, declStart(ts, gen), declEnd(ts, gen), root)
 	}
 
 	// Documentazione
 	if gen.Doc != nil {
-		t.Documentation = cleanDoc(gen.Doc.Text())
+		t.Documentation = cfg.docText(gen.Doc)
 	} else if ts.Doc != nil {
-		t.Documentation = cleanDoc(ts.Doc.Text())
+		t.Documentation = cfg.docText(ts.Doc)
 	}
 
 	// Type parameters (generics)
@@ -401,7 +489,7 @@ func extractType(pkgPath string, ts *ast.TypeSpec, gen *ast.GenDecl, fset *token
 	// Interface methods
 	if it, ok := ts.Type.(*ast.InterfaceType); ok && it.Methods != nil {
 		t.EmbeddedTypes = extractInterfaceEmbedded(it.Methods)
-		t.InterfaceMethods = extractInterfaceMethods(it.Methods)
+		t.InterfaceMethods = extractInterfaceMethods(it.Methods, cfg)
 	}
 
 	return t
@@ -418,14 +506,14 @@ func extractVariables(pkgPath string, vs *ast.ValueSpec, gen *ast.GenDecl, fset
 
 	doc := ""
 	if gen.Doc != nil {
-		doc = cleanDoc(gen.Doc.Text())
+		doc = cfg.docText(gen.Doc)
 	} else if vs.Doc != nil {
-		doc = cleanDoc(vs.Doc.Text())
+		doc = cfg.docText(vs.Doc)
 	}
 
 	for _, ident := range vs.Names {
 		v := &schema.CLDKVariable{
-			QualifiedName: fmt.Sprintf("%s.%s", pkgPath, ident.Name),
+			QualifiedName: ids.Type(pkgPath, ident.Name),
 			Name:          ident.Name,
 			Type:          typeStr,
 			Exported:      isExported(ident.Name),
@@ -458,7 +546,7 @@ func extractConstants(pkgPath string, vs *ast.ValueSpec, gen *ast.GenDecl, fset
 
 	for i, ident := range vs.Names {
 		c := &schema.CLDKConstant{
-			QualifiedName: fmt.Sprintf("%s.%s", pkgPath, ident.Name),
+			QualifiedName: ids.Type(pkgPath, ident.Name),
 			Name:          ident.Name,
 			Type:          typeStr,
 			Exported:      isExported(ident.Name),
@@ -609,7 +697,7 @@ func extractInterfaceEmbedded(fl *ast.FieldList) []string {
 }
 
 // extractInterfaceMethods estrae i metodi dichiarati in un'interfaccia.
-func extractInterfaceMethods(fl *ast.FieldList) []schema.CLDKInterfaceMethod {
+func extractInterfaceMethods(fl *ast.FieldList, cfg ExtractConfig) []schema.CLDKInterfaceMethod {
 	if fl == nil {
 		return nil
 	}
@@ -626,9 +714,9 @@ func extractInterfaceMethods(fl *ast.FieldList) []schema.CLDKInterfaceMethod {
 					Results:    extractParameters(ft.Results),
 				}
 				if f.Doc != nil {
-					im.Documentation = cleanDoc(f.Doc.Text())
+					im.Documentation = cfg.docText(f.Doc)
 				} else if f.Comment != nil {
-					im.Documentation = cleanDoc(f.Comment.Text())
+					im.Documentation = cfg.docText(f.Comment)
 				}
 				methods = append(methods, im)
 			}
@@ -676,113 +764,316 @@ func buildInterfaceMethodSig(name string, ft *ast.FuncType) string {
 	return sig
 }
 
-// populateCallExamples popola CallExamples per ogni callable analizzando i call sites.
-func populateCallExamples(pkg *schema.CLDKPackage) {
-	// Costruisci indice: nome funzione -> qualified name
-	nameIndex := make(map[string][]string)
-	for qn, cd := range pkg.CallableDeclarations {
-		nameIndex[cd.Name] = append(nameIndex[cd.Name], qn)
+// extractFunctionBody estrae informazioni sul corpo della funzione.
+func extractFunctionBody(body *ast.BlockStmt, info *types.Info, fset *token.FileSet, root string, cfg ExtractConfig) *schema.CLDKFunctionBody {
+	startPos := fset.Position(body.Pos())
+	endPos := fset.Position(body.End())
+
+	fb := &schema.CLDKFunctionBody{
+		StartLine: startPos.Line,
+		EndLine:   endPos.Line,
+		LineCount: endPos.Line - startPos.Line + 1,
 	}
 
-	// Per ogni callable, cerca chi lo chiama
-	examples := make(map[string][]string) // qn -> examples
-	for _, caller := range pkg.CallableDeclarations {
-		if caller.Body == nil {
-			continue
+	// Istogramma degli statement e annidamento massimo
+	w := &stmtWalker{counts: &schema.CLDKStatementCounts{}}
+	w.walk(body, 0)
+	fb.Statements = w.counts
+	fb.MaxNesting = w.maxDepth
+	fb.Complexity = cyclomatic(body)
+
+	// Defer/panic/recover
+	fb.Defers = extractDefers(body, info, fset, root)
+	fb.PanicReasons = panicReasons(body, info)
+	fb.MayPanic = len(fb.PanicReasons) > 0
+
+	// Estrai call sites se richiesto
+	if cfg.IncludeCallSites {
+		fb.CallSites = extractCallSites(body, info, fset, root)
+	}
+
+	return fb
+}
+
+// cyclomatic calcola la complessità ciclomatica del corpo, closure comprese:
+// 1 più if, for, range, case e comm clause diversi da default, && e ||.
+func cyclomatic(body *ast.BlockStmt) int {
+	c := 1
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			c++
+		case *ast.CaseClause:
+			if x.List != nil {
+				c++
+			}
+		case *ast.CommClause:
+			if x.Comm != nil {
+				c++
+			}
+		case *ast.BinaryExpr:
+			if x.Op == token.LAND || x.Op == token.LOR {
+				c++
+			}
 		}
-		for _, cs := range caller.Body.CallSites {
-			// Cerca il target tra le callable del package
-			targetName := extractCallTargetName(cs.Target)
-			for _, qn := range nameIndex[targetName] {
-				existing := examples[qn]
-				if len(existing) >= 3 {
-					continue
-				}
-				example := fmt.Sprintf("called by %s() [%s]", caller.Name, cs.Kind)
-				// Evita duplicati
-				duplicate := false
-				for _, e := range existing {
-					if e == example {
-						duplicate = true
-						break
-					}
+		return true
+	})
+	return c
+}
+
+// extractDefers elenca gli statement defer del corpo, escluse le closure:
+// un defer in una closure viene eseguito al suo ritorno, non a quello della
+// funzione. Una closure deferita "recupera" se chiama recover()
+// direttamente nel suo corpo (non in closure annidate), l'unico caso in cui
+// recover ha effetto.
+func extractDefers(body *ast.BlockStmt, info *types.Info, fset *token.FileSet, root string) []schema.CLDKDefer {
+	var defers []schema.CLDKDefer
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		ds, ok := n.(*ast.DeferStmt)
+		if !ok {
+			return true
+		}
+		d := schema.CLDKDefer{Position: posOf(fset, ds.Pos(), root)}
+		lit, isLit := ast.Unparen(ds.Call.Fun).(*ast.FuncLit)
+		if !isLit {
+			d.Target = exprString(ds.Call.Fun)
+			defers = append(defers, d)
+			return true
+		}
+		d.Target = "func literal"
+		ast.Inspect(lit.Body, func(m ast.Node) bool {
+			switch x := m.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.CallExpr:
+				if _, ok := ast.Unparen(x.Fun).(*ast.FuncLit); ok {
+					d.Calls = append(d.Calls, "func literal")
+				} else {
+					d.Calls = append(d.Calls, exprString(x.Fun))
 				}
-				if !duplicate {
-					examples[qn] = append(existing, example)
+				if isBuiltinCall(x, "recover", info) {
+					d.Recovers = true
 				}
 			}
+			return true
+		})
+		defers = append(defers, d)
+		return true
+	})
+	return defers
+}
+
+// panicReasons restituisce le cause di panic possibili nel corpo, escluse le
+// closure: chiamate esplicite a panic, indicizzazioni e slicing (fuori
+// range) e type assertion senza forma "comma ok". Senza type info le
+// indicizzazioni di mappe non sono distinguibili e contano come "index".
+func panicReasons(body *ast.BlockStmt, info *types.Info) []string {
+	// Type assertion in forma v, ok := x.(T): non vanno in panic
+	commaOK := make(map[*ast.TypeAssertExpr]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		var rhs []ast.Expr
+		switch x := n.(type) {
+		case *ast.AssignStmt:
+			if len(x.Lhs) == 2 {
+				rhs = x.Rhs
+			}
+		case *ast.ValueSpec:
+			if len(x.Names) == 2 {
+				rhs = x.Values
+			}
 		}
-	}
+		if len(rhs) == 1 {
+			if ta, ok := ast.Unparen(rhs[0]).(*ast.TypeAssertExpr); ok {
+				commaOK[ta] = true
+			}
+		}
+		return true
+	})
 
-	// Assegna gli esempi
-	for qn, exs := range examples {
-		if cd, ok := pkg.CallableDeclarations[qn]; ok {
-			cd.CallExamples = exs
+	found := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if isBuiltinCall(x, "panic", info) {
+				found["panic"] = true
+			}
+		case *ast.IndexExpr:
+			if mayPanicIndex(x.X, info) {
+				found["index"] = true
+			}
+		case *ast.SliceExpr:
+			found["slice"] = true
+		case *ast.TypeAssertExpr:
+			// x.(type) negli switch ha Type nil
+			if x.Type != nil && !commaOK[x] {
+				found["type_assertion"] = true
+			}
+		}
+		return true
+	})
+
+	var reasons []string
+	for _, r := range []string{"panic", "index", "slice", "type_assertion"} {
+		if found[r] {
+			reasons = append(reasons, r)
 		}
 	}
+	return reasons
 }
 
-// extractCallTargetName estrae il nome della funzione target da una call expression.
-// Gestisce pattern come "pkg.Func", "obj.Method", "Func".
-func extractCallTargetName(target string) string {
-	// Rimuovi prefissi come "pkg." o "obj."
-	if idx := strings.LastIndex(target, "."); idx >= 0 {
-		return target[idx+1:]
+// mayPanicIndex indica se indicizzare x può andare fuori range: sì per
+// slice, array, puntatori ad array e stringhe; no per mappe e per le
+// istanziazioni di generici (F[int]).
+func mayPanicIndex(x ast.Expr, info *types.Info) bool {
+	if info == nil {
+		return true
+	}
+	tv, ok := info.Types[x]
+	if !ok || !tv.IsValue() {
+		return false
 	}
-	return target
+	t := tv.Type.Underlying()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem().Underlying()
+	}
+	switch t := t.(type) {
+	case *types.Slice, *types.Array:
+		return true
+	case *types.Basic:
+		return t.Info()&types.IsString != 0
+	}
+	return false
 }
 
-// extractFunctionBody estrae informazioni sul corpo della funzione.
-func extractFunctionBody(body *ast.BlockStmt, fset *token.FileSet, root string, cfg ExtractConfig) *schema.CLDKFunctionBody {
-	startPos := fset.Position(body.Pos())
-	endPos := fset.Position(body.End())
-
-	fb := &schema.CLDKFunctionBody{
-		StartLine: startPos.Line,
-		EndLine:   endPos.Line,
-		LineCount: endPos.Line - startPos.Line + 1,
+// isBuiltinCall indica se call chiama la funzione builtin name (e non una
+// funzione dell'utente con lo stesso nome, se la type info è disponibile).
+func isBuiltinCall(call *ast.CallExpr, name string, info *types.Info) bool {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || id.Name != name {
+		return false
 	}
+	if info == nil {
+		return true
+	}
+	_, builtin := info.Uses[id].(*types.Builtin)
+	return builtin
+}
 
-	// Estrai call sites se richiesto
-	if cfg.IncludeCallSites {
-		fb.CallSites = extractCallSites(body, fset, root)
+// stmtWalker conta gli statement per tipo e misura l'annidamento dei
+// costrutti di controllo. Le catene else-if restano allo stesso livello
+// dell'if iniziale. I corpi delle closure sono esclusi, come in
+// panicReasons: sono funzioni a sé.
+type stmtWalker struct {
+	counts   *schema.CLDKStatementCounts
+	maxDepth int
+}
+
+// walk visita i discendenti di n, che si trovano a profondità depth.
+func (w *stmtWalker) walk(n ast.Node, depth int) {
+	ast.Inspect(n, func(c ast.Node) bool {
+		if c == n {
+			return true
+		}
+		switch x := c.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.IfStmt:
+			w.ifStmt(x, depth)
+			return false
+		case *ast.ForStmt, *ast.RangeStmt:
+			w.counts.For++
+		case *ast.SwitchStmt:
+			w.counts.Switch++
+		case *ast.TypeSwitchStmt:
+			w.counts.TypeSwitch++
+		case *ast.SelectStmt:
+			w.counts.Select++
+		case *ast.ReturnStmt:
+			w.counts.Return++
+			return true
+		case *ast.GoStmt:
+			w.counts.Go++
+			return true
+		case *ast.DeferStmt:
+			w.counts.Defer++
+			return true
+		default:
+			return true
+		}
+		w.enter(depth + 1)
+		w.walk(c, depth+1)
+		return false
+	})
+}
+
+func (w *stmtWalker) ifStmt(x *ast.IfStmt, depth int) {
+	w.counts.If++
+	w.enter(depth + 1)
+	if x.Init != nil {
+		w.walk(x.Init, depth+1)
+	}
+	w.walk(x.Cond, depth+1)
+	w.walk(x.Body, depth+1)
+	switch e := x.Else.(type) {
+	case *ast.IfStmt:
+		w.ifStmt(e, depth)
+	case *ast.BlockStmt:
+		w.walk(e, depth+1)
 	}
+}
 
-	return fb
+func (w *stmtWalker) enter(depth int) {
+	if depth > w.maxDepth {
+		w.maxDepth = depth
+	}
 }
 
-// extractCallSites estrae le chiamate a funzione nel corpo.
-func extractCallSites(body *ast.BlockStmt, fset *token.FileSet, root string) []schema.CLDKCallSite {
+// extractCallSites estrae le chiamate a funzione nel corpo, con gli argomenti
+// (testo e, se costanti, valore) e le variabili che ricevono i risultati.
+// info può essere nil: in quel caso i valori costanti non sono risolti.
+func extractCallSites(body *ast.BlockStmt, info *types.Info, fset *token.FileSet, root string) []schema.CLDKCallSite {
 	var sites []schema.CLDKCallSite
+	targets := assignTargets(body)
+	chains := callChains(body)
+
+	newSite := func(call *ast.CallExpr, pos token.Pos, kind string) schema.CLDKCallSite {
+		return schema.CLDKCallSite{
+			Target:     exprString(call.Fun),
+			Position:   posOf(fset, pos, root),
+			Kind:       kind,
+			Arguments:  extractArguments(call.Args, info),
+			AssignedTo: targets[call],
+		}
+	}
 
 	ast.Inspect(body, func(n ast.Node) bool {
 		switch x := n.(type) {
 		case *ast.CallExpr:
-			target := exprString(x.Fun)
-			site := schema.CLDKCallSite{
-				Target:   target,
-				Position: posOf(fset, x.Pos(), root),
-				Kind:     "call",
+			chain, chained := chains[x]
+			if !chained || chain.Step == 0 {
+				site := newSite(x, x.Pos(), "call")
+				site.Chain = chain
+				sites = append(sites, site)
+				break
 			}
+			// Passo successivo di una catena: target e posizione sono
+			// quelli del metodo, il receiver è il risultato del passo prima
+			sel := ast.Unparen(x.Fun).(*ast.SelectorExpr)
+			site := newSite(x, sel.Sel.Pos(), "call")
+			site.Target = sel.Sel.Name
+			site.Chain = chain
 			sites = append(sites, site)
 
 		case *ast.GoStmt:
-			target := exprString(x.Call.Fun)
-			site := schema.CLDKCallSite{
-				Target:   target,
-				Position: posOf(fset, x.Pos(), root),
-				Kind:     "go",
-			}
-			sites = append(sites, site)
+			sites = append(sites, newSite(x.Call, x.Pos(), "go"))
 
 		case *ast.DeferStmt:
-			target := exprString(x.Call.Fun)
-			site := schema.CLDKCallSite{
-				Target:   target,
-				Position: posOf(fset, x.Pos(), root),
-				Kind:     "defer",
-			}
-			sites = append(sites, site)
+			sites = append(sites, newSite(x.Call, x.Pos(), "defer"))
 		}
 		return true
 	})
@@ -790,6 +1081,119 @@ func extractCallSites(body *ast.BlockStmt, fset *token.FileSet, root string) []s
 	return sites
 }
 
+// callChains individua le catene di chiamate (es. a.B().C().D()) in cui ogni
+// metodo è invocato direttamente sul risultato della chiamata precedente e
+// numera i passi in ordine di valutazione. Le chiamate fuori da una catena
+// non compaiono nella mappa.
+func callChains(body *ast.BlockStmt) map[*ast.CallExpr]*schema.CLDKCallChain {
+	// receiverCall restituisce la chiamata su cui è invocato il metodo di call
+	receiverCall := func(call *ast.CallExpr) *ast.CallExpr {
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		inner, _ := ast.Unparen(sel.X).(*ast.CallExpr)
+		return inner
+	}
+
+	// Le chiamate interne sono raggiunte dalla più esterna della catena
+	inner := make(map[*ast.CallExpr]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if r := receiverCall(call); r != nil {
+				inner[r] = true
+			}
+		}
+		return true
+	})
+
+	chains := make(map[*ast.CallExpr]*schema.CLDKCallChain)
+	nextID := 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || inner[call] || receiverCall(call) == nil {
+			return true
+		}
+		var steps []*ast.CallExpr
+		for c := call; c != nil; c = receiverCall(c) {
+			steps = append(steps, c)
+		}
+		for i, c := range steps {
+			chains[c] = &schema.CLDKCallChain{
+				ID:     nextID,
+				Step:   len(steps) - 1 - i,
+				Length: len(steps),
+			}
+		}
+		nextID++
+		return true
+	})
+	return chains
+}
+
+// maxArgExprLen limita il testo di un argomento (es. closure passate come
+// callback) per non replicare interi corpi di funzione nell'output.
+const maxArgExprLen = 200
+
+// extractArguments restituisce gli argomenti di una chiamata.
+func extractArguments(args []ast.Expr, info *types.Info) []schema.CLDKArgument {
+	if len(args) == 0 {
+		return nil
+	}
+	out := make([]schema.CLDKArgument, 0, len(args))
+	for _, arg := range args {
+		a := schema.CLDKArgument{Expr: truncateExpr(exprString(arg), maxArgExprLen)}
+		if info != nil {
+			if tv, ok := info.Types[arg]; ok && tv.Value != nil {
+				a.Value = tv.Value.ExactString()
+			}
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// assignTargets associa le chiamate assegnate (":=", "=", "var") alle
+// variabili che ne ricevono i risultati. Con più espressioni a destra ogni
+// chiamata riceve la variabile nella stessa posizione; con una sola chiamata
+// a più valori le riceve tutte.
+func assignTargets(body *ast.BlockStmt) map[*ast.CallExpr][]string {
+	targets := make(map[*ast.CallExpr][]string)
+	record := func(lhs []string, rhs []ast.Expr) {
+		for i, r := range rhs {
+			call, ok := ast.Unparen(r).(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			switch {
+			case len(rhs) == 1:
+				targets[call] = lhs
+			case i < len(lhs):
+				targets[call] = lhs[i : i+1]
+			}
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.AssignStmt:
+			lhs := make([]string, len(x.Lhs))
+			for i, l := range x.Lhs {
+				lhs[i] = exprString(l)
+			}
+			record(lhs, x.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]string, len(x.Names))
+			for i, id := range x.Names {
+				lhs[i] = id.Name
+			}
+			record(lhs, x.Values)
+		}
+		return true
+	})
+	return targets
+}
+
 // extractReceiverTypeName estrae il nome del tipo receiver.
 func extractReceiverTypeName(fl *ast.FieldList) string {
 	if fl == nil || len(fl.List) == 0 {
@@ -834,6 +1238,43 @@ func extractReceiverInfo(fl *ast.FieldList) (typeName string, isPtr bool) {
 // Helper functions
 // ============================================================================
 
+// declStart restituisce l'inizio della dichiarazione di ts comprensiva di
+// doc comment: la keyword "type" (o il suo doc) se la dichiarazione non è
+// raggruppata, altrimenti lo spec stesso (o il suo doc) dentro "type ( ... )".
+func declStart(ts *ast.TypeSpec, gen *ast.GenDecl) token.Pos {
+	if !gen.Lparen.IsValid() {
+		if gen.Doc != nil {
+			return gen.Doc.Pos()
+		}
+		return gen.Pos()
+	}
+	if ts.Doc != nil {
+		return ts.Doc.Pos()
+	}
+	return ts.Pos()
+}
+
+// declEnd restituisce la fine della dichiarazione di ts (vedi declStart).
+func declEnd(ts *ast.TypeSpec, gen *ast.GenDecl) token.Pos {
+	if !gen.Lparen.IsValid() {
+		return gen.End()
+	}
+	return ts.End()
+}
+
+// spanOf restituisce l'intervallo [start, end) come CLDKPosition completa.
+func spanOf(fset *token.FileSet, start, end token.Pos, root string) *schema.CLDKPosition {
+	pos := posOf(fset, start, root)
+	if pos == nil {
+		return nil
+	}
+	if e := fset.Position(end); e.IsValid() {
+		pos.EndLine = e.Line
+		pos.EndColumn = e.Column
+	}
+	return pos
+}
+
 func posOf(fset *token.FileSet, p token.Pos, root string) *schema.CLDKPosition {
 	pos := fset.Position(p)
 	if !pos.IsValid() {
@@ -0,0 +1,77 @@
+package symbols
+
+import (
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// populateImplements valorizza Implements dei tipi non interfaccia del
+// progetto con le interfacce del progetto che il tipo implementa, con
+// receiver valore o puntatore. Sono escluse le interfacce vuote e quelle
+// generiche (che non si possono verificare senza istanziarle).
+func populateImplements(st *schema.CLDKSymbolTable, pkgs []*packages.Package) {
+	var ifaces, concrete []*types.TypeName
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.Types == nil || st.Packages[pkg.PkgPath] == nil {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 {
+				continue
+			}
+			if iface, ok := named.Underlying().(*types.Interface); ok {
+				if iface.NumMethods() > 0 {
+					ifaces = append(ifaces, tn)
+				}
+				continue
+			}
+			concrete = append(concrete, tn)
+		}
+	}
+	if len(ifaces) == 0 {
+		return
+	}
+
+	for _, tn := range concrete {
+		t := st.Packages[tn.Pkg().Path()].TypeDeclarations[qualifiedName(tn)]
+		if t == nil {
+			continue
+		}
+		ptr := types.NewPointer(tn.Type())
+		for _, in := range ifaces {
+			iface := in.Type().Underlying().(*types.Interface)
+			if types.Implements(tn.Type(), iface) || types.Implements(ptr, iface) {
+				t.Implements = append(t.Implements, qualifiedName(in))
+			}
+		}
+		if len(t.Implements) > 1 {
+			// Le varianti di test dello stesso package ripetono i tipi
+			sort.Strings(t.Implements)
+			t.Implements = dedupSorted(t.Implements)
+		}
+	}
+}
+
+func qualifiedName(tn *types.TypeName) string {
+	return tn.Pkg().Path() + "." + tn.Name()
+}
+
+func dedupSorted(s []string) []string {
+	out := s[:0]
+	for _, v := range s {
+		if len(out) == 0 || out[len(out)-1] != v {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,191 @@
+package symbols
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// closerNames sono i metodi che rilasciano le risorse di un'istanza.
+var closerNames = []string{"Close", "Shutdown", "Stop"}
+
+// PopulateLifecycle aggiunge a ogni tipo non interfaccia né alias della
+// symbol table i costruttori convenzionali (funzioni new*/must* del
+// progetto il cui primo risultato è T o *T), i metodi Close/Shutdown/Stop
+// del suo method set (anche promossi da campi embedded) e le istruzioni go
+// che ricevono un'istanza come receiver, argomento o variabile catturata
+// da una closure.
+func PopulateLifecycle(st *schema.CLDKSymbolTable, result *loader.LoadResult) {
+	if st == nil {
+		return
+	}
+	byID := make(map[string]*schema.CLDKType)
+	for _, pkg := range st.Packages {
+		for _, t := range pkg.TypeDeclarations {
+			if t.Kind != "interface" && t.Kind != "alias" {
+				t.Lifecycle = &schema.CLDKTypeLifecycle{}
+				byID[t.QualifiedName] = t
+			}
+		}
+	}
+
+	ctors := make(map[string]bool)
+	closers := make(map[string]bool)
+	sites := make(map[string]map[token.Position]bool)
+	for _, pkg := range result.Packages {
+		if pkg == nil || pkg.Types == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			switch obj := scope.Lookup(name).(type) {
+			case *types.Func:
+				c, id := constructorOf(obj)
+				if t := byID[id]; t != nil && !ctors[c.Function] {
+					ctors[c.Function] = true
+					t.Lifecycle.Constructors = append(t.Lifecycle.Constructors, c)
+				}
+			case *types.TypeName:
+				t := byID[ids.Type(pkg.PkgPath, name)]
+				if t == nil || obj.IsAlias() {
+					continue
+				}
+				ms := types.NewMethodSet(types.NewPointer(obj.Type()))
+				for _, cn := range closerNames {
+					sel := ms.Lookup(nil, cn)
+					if sel == nil {
+						continue
+					}
+					fn := sel.Obj().(*types.Func)
+					key := t.QualifiedName + " " + cn
+					if !closers[key] {
+						closers[key] = true
+						t.Lifecycle.Closers = append(t.Lifecycle.Closers, ids.Object(fn))
+					}
+				}
+			}
+		}
+
+		for _, file := range pkg.Syntax {
+			if file == nil {
+				continue
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				g, ok := n.(*ast.GoStmt)
+				if !ok {
+					return true
+				}
+				pos := result.Fset.Position(g.Pos())
+				for _, id := range goroutineTypes(g.Call, pkg.TypesInfo) {
+					if byID[id] == nil {
+						continue
+					}
+					if sites[id] == nil {
+						sites[id] = make(map[token.Position]bool)
+					}
+					sites[id][pos] = true
+				}
+				return true
+			})
+		}
+	}
+
+	for id, t := range byID {
+		lc := t.Lifecycle
+		sort.Slice(lc.Constructors, func(i, j int) bool { return lc.Constructors[i].Function < lc.Constructors[j].Function })
+		for pos := range sites[id] {
+			lc.GoroutineSites = append(lc.GoroutineSites, *loader.Position(pos, result.Root))
+		}
+		sort.Slice(lc.GoroutineSites, func(i, j int) bool {
+			a, b := lc.GoroutineSites[i], lc.GoroutineSites[j]
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartColumn < b.StartColumn)
+		})
+		lc.EscapesToGoroutine = len(lc.GoroutineSites) > 0
+	}
+}
+
+// constructorOf riconosce un costruttore convenzionale: una funzione
+// package-level il cui nome inizia con New/new (kind "new") o Must/must
+// ("must") e il cui primo risultato è un tipo nominato del progetto o un
+// puntatore a esso. Restituisce anche l'ID del tipo costruito.
+func constructorOf(fn *types.Func) (schema.CLDKConstructor, string) {
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() != nil || sig.Results().Len() == 0 {
+		return schema.CLDKConstructor{}, ""
+	}
+	c := schema.CLDKConstructor{Function: ids.Object(fn)}
+	lower := strings.ToLower(fn.Name())
+	switch {
+	case strings.HasPrefix(lower, "must"):
+		c.Kind = "must"
+	case strings.HasPrefix(lower, "new"):
+		c.Kind = "new"
+	default:
+		return schema.CLDKConstructor{}, ""
+	}
+	res := sig.Results()
+	t := res.At(0).Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t, c.ReturnsPointer = p.Elem(), true
+	}
+	n, ok := types.Unalias(t).(*types.Named)
+	if !ok || n.Obj().Pkg() == nil {
+		return schema.CLDKConstructor{}, ""
+	}
+	last := res.At(res.Len() - 1).Type()
+	c.ReturnsError = res.Len() > 1 && types.Identical(last, types.Universe.Lookup("error").Type())
+	return c, ids.Type(n.Obj().Pkg().Path(), n.Obj().Name())
+}
+
+// goroutineTypes restituisce gli ID dei tipi nominati passati alla
+// goroutine avviata da call: receiver del metodo, argomenti e variabili
+// locali catturate da una closure.
+func goroutineTypes(call *ast.CallExpr, info *types.Info) []string {
+	var out []string
+	add := func(t types.Type) {
+		if t == nil {
+			return
+		}
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		if n, ok := types.Unalias(t).(*types.Named); ok && n.Obj().Pkg() != nil {
+			out = append(out, ids.Type(n.Obj().Pkg().Path(), n.Obj().Name()))
+		}
+	}
+
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.SelectorExpr:
+		if sel := info.Selections[fun]; sel != nil && sel.Kind() == types.MethodVal {
+			add(info.TypeOf(fun.X))
+		}
+	case *ast.FuncLit:
+		ast.Inspect(fun.Body, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			v, ok := info.Uses[id].(*types.Var)
+			if !ok || v.IsField() || v.Pkg() == nil || v.Parent() == v.Pkg().Scope() {
+				return true
+			}
+			if v.Pos() < fun.Pos() || v.Pos() >= fun.End() {
+				add(v.Type())
+			}
+			return true
+		})
+	}
+	for _, arg := range call.Args {
+		add(info.TypeOf(arg))
+	}
+	return out
+}
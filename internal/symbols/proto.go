@@ -0,0 +1,536 @@
+package symbols
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// protoGenerated riconosce l'header dei file scritti da un plugin di protoc
+// (protoc-gen-go, protoc-gen-go-grpc, protoc-gen-connect-go, protoc-gen-gogo).
+var protoGenerated = regexp.MustCompile(`^Code generated by (protoc-gen-[\w-]+)\. DO NOT EDIT\.$`)
+
+// protoHeader è l'header di un file generato.
+type protoHeader struct {
+	generator, version, source string
+}
+
+// protoIndex associa i nomi Go generati per un file .proto agli elementi
+// che li originano, secondo le regole di nomenclatura dei plugin.
+type protoIndex struct {
+	pkg     string
+	source  string
+	file    *schema.CLDKProtoRef
+	prefix  string                          // prefisso delle variabili del descrittore, es. "file_api_v1_user_proto_"
+	types   map[string]*schema.CLDKProtoRef // nome del tipo
+	methods map[string]*schema.CLDKProtoRef // "Tipo.Metodo"
+	values  map[string]*schema.CLDKProtoRef // funzioni, costanti e variabili
+}
+
+// populateProto collega le dichiarazioni dei file generati da protoc al
+// file .proto e all'elemento (messaggio, campo, enum, servizio, rpc) da cui
+// derivano. I nomi sono ricavati dal descrittore serializzato nel file
+// generato da protoc-gen-go (rawDesc, o fileDescriptor compresso con gzip
+// nelle versioni precedenti a APIv2); i file di gRPC e Connect lo trovano
+// tramite il commento "source:", anche in un altro package.
+func populateProto(st *schema.CLDKSymbolTable, pkgs []*packages.Package, fset *token.FileSet, root string) {
+	headers := make(map[string]map[string]*protoHeader) // package → file → header
+	indexes := make(map[string]*protoIndex)             // file .proto → indice
+	for _, pkg := range pkgs {
+		if pkg == nil || st.Packages[pkg.PkgPath] == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			h := parseProtoHeader(file)
+			if h == nil {
+				continue
+			}
+			pos := posOf(fset, file.Pos(), root)
+			if pos == nil {
+				continue
+			}
+			if headers[pkg.PkgPath] == nil {
+				headers[pkg.PkgPath] = make(map[string]*protoHeader)
+			}
+			headers[pkg.PkgPath][pos.File] = h
+			if indexes[h.source] == nil {
+				if desc := fileDescriptor(file, pkg.TypesInfo); desc != nil {
+					indexes[h.source] = newProtoIndex(h.source, desc)
+				}
+			}
+		}
+	}
+
+	for pkgPath, files := range headers {
+		cldkPkg := st.Packages[pkgPath]
+		cldkPkg.ProtoFiles = cldkPkg.ProtoFiles[:0]
+		for name, h := range files {
+			pf := schema.CLDKProtoFile{File: name, Source: h.source, Generator: h.generator, Version: h.version}
+			if x := indexes[h.source]; x != nil {
+				pf.Package = x.pkg
+			}
+			cldkPkg.ProtoFiles = append(cldkPkg.ProtoFiles, pf)
+		}
+		sort.Slice(cldkPkg.ProtoFiles, func(i, j int) bool { return cldkPkg.ProtoFiles[i].File < cldkPkg.ProtoFiles[j].File })
+
+		// Le dichiarazioni aggiunte a mano nello stesso package restano
+		// senza collegamento: conta il file che le contiene.
+		indexOf := func(pos *schema.CLDKPosition) *protoIndex {
+			if pos == nil || files[pos.File] == nil {
+				return nil
+			}
+			return indexes[files[pos.File].source]
+		}
+		for _, t := range cldkPkg.TypeDeclarations {
+			x := indexOf(t.Position)
+			if x == nil {
+				continue
+			}
+			t.Proto = x.types[t.Name]
+			for _, m := range t.Methods {
+				if indexOf(m.Position) != x {
+					continue
+				}
+				if m.Proto = x.methods[t.Name+"."+m.Name]; m.Proto == nil {
+					m.Proto = t.Proto
+				}
+			}
+		}
+		for _, c := range cldkPkg.CallableDeclarations {
+			if x := indexOf(c.Position); x != nil {
+				if c.ReceiverType == "" {
+					c.Proto = x.value(c.Name)
+				} else if c.Proto = x.methods[c.ReceiverType+"."+c.Name]; c.Proto == nil {
+					c.Proto = x.types[c.ReceiverType]
+				}
+			}
+		}
+		for _, c := range cldkPkg.Constants {
+			if x := indexOf(c.Position); x != nil {
+				c.Proto = x.value(c.Name)
+			}
+		}
+		for _, v := range cldkPkg.Variables {
+			if x := indexOf(v.Position); x != nil {
+				v.Proto = x.value(v.Name)
+			}
+		}
+	}
+}
+
+// parseProtoHeader legge generatore, versione e file .proto dai commenti
+// che precedono la clausola package; nil se il file non è generato da
+// protoc.
+func parseProtoHeader(file *ast.File) *protoHeader {
+	if file == nil {
+		return nil
+	}
+	h := &protoHeader{}
+	for _, g := range file.Comments {
+		if g.Pos() >= file.Package {
+			break
+		}
+		for _, c := range g.List {
+			line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+			if m := protoGenerated.FindStringSubmatch(line); m != nil {
+				h.generator = m[1]
+			} else if src, ok := strings.CutPrefix(line, "source: "); ok {
+				h.source = strings.TrimSpace(src)
+			} else if f := strings.Fields(strings.TrimPrefix(line, "- ")); len(f) == 2 && f[0] == h.generator {
+				h.version = f[1]
+			}
+		}
+	}
+	if h.generator == "" || h.source == "" {
+		return nil
+	}
+	return h
+}
+
+// fileDescriptor restituisce il FileDescriptorProto serializzato nel file:
+// la variabile o costante file_*_rawDesc di APIv2 o la variabile
+// fileDescriptor_* compressa con gzip di APIv1 e gogo.
+func fileDescriptor(file *ast.File, info *types.Info) []byte {
+	if info == nil {
+		return nil
+	}
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || (gd.Tok != token.VAR && gd.Tok != token.CONST) {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if i >= len(vs.Values) {
+					break
+				}
+				switch n := name.Name; {
+				case strings.HasPrefix(n, "file_") && strings.HasSuffix(n, "_rawDesc"):
+					if desc := byteLiteral(vs.Values[i], info); desc != nil {
+						return desc
+					}
+				case strings.HasPrefix(n, "fileDescriptor_"):
+					if gz := byteLiteral(vs.Values[i], info); gz != nil {
+						if r, err := gzip.NewReader(bytes.NewReader(gz)); err == nil {
+							if desc, err := io.ReadAll(r); err == nil {
+								return desc
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// byteLiteral restituisce i byte di una costante stringa, di un literal
+// []byte{...} o di una loro conversione.
+func byteLiteral(e ast.Expr, info *types.Info) []byte {
+	if tv, ok := info.Types[e]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return []byte(constant.StringVal(tv.Value))
+	}
+	switch e := e.(type) {
+	case *ast.CallExpr:
+		if len(e.Args) == 1 {
+			return byteLiteral(e.Args[0], info)
+		}
+	case *ast.CompositeLit:
+		out := make([]byte, 0, len(e.Elts))
+		for _, el := range e.Elts {
+			tv, ok := info.Types[el]
+			if !ok || tv.Value == nil {
+				return nil
+			}
+			v, exact := constant.Uint64Val(tv.Value)
+			if !exact || v > 255 {
+				return nil
+			}
+			out = append(out, byte(v))
+		}
+		return out
+	}
+	return nil
+}
+
+// newProtoIndex costruisce l'indice dei nomi Go dal FileDescriptorProto
+// desc (campi: 1 name, 2 package, 4 message_type, 5 enum_type, 6 service).
+func newProtoIndex(source string, desc []byte) *protoIndex {
+	x := &protoIndex{
+		source:  source,
+		types:   make(map[string]*schema.CLDKProtoRef),
+		methods: make(map[string]*schema.CLDKProtoRef),
+		values:  make(map[string]*schema.CLDKProtoRef),
+	}
+	path := source
+	var messages, enums, services [][]byte
+	protoFields(desc, func(num int, data []byte, _ uint64) {
+		switch num {
+		case 1:
+			path = string(data)
+		case 2:
+			x.pkg = string(data)
+		case 4:
+			messages = append(messages, data)
+		case 5:
+			enums = append(enums, data)
+		case 6:
+			services = append(services, data)
+		}
+	})
+	x.file = x.ref(schema.ProtoKindFile, path)
+	x.prefix = "file_" + goSanitized(path) + "_"
+	x.values["File_"+goSanitized(path)] = x.file
+	for _, m := range messages {
+		x.message(m, "")
+	}
+	for _, e := range enums {
+		x.enum(e, "", "")
+	}
+	for _, s := range services {
+		x.service(s)
+	}
+	return x
+}
+
+func (x *protoIndex) ref(kind, name string) *schema.CLDKProtoRef {
+	return &schema.CLDKProtoRef{Source: x.source, Name: name, Kind: kind}
+}
+
+// full restituisce il nome completo dell'elemento con nome relativo rel.
+func (x *protoIndex) full(rel string) string {
+	if x.pkg == "" {
+		return rel
+	}
+	return x.pkg + "." + rel
+}
+
+// value restituisce l'elemento di una funzione, costante o variabile; le
+// variabili di supporto del descrittore appartengono al file.
+func (x *protoIndex) value(name string) *schema.CLDKProtoRef {
+	if ref := x.values[name]; ref != nil {
+		return ref
+	}
+	if strings.HasPrefix(name, x.prefix) {
+		return x.file
+	}
+	return nil
+}
+
+// message indicizza un DescriptorProto (1 name, 2 field, 3 nested_type,
+// 4 enum_type, 8 oneof_decl) annidato in parent: il tipo Go, i getter dei
+// campi e, per i oneof, l'interfaccia is* e i tipi wrapper.
+func (x *protoIndex) message(data []byte, parent string) {
+	var name string
+	var fields, nested, enums [][]byte
+	var oneofs []string
+	protoFields(data, func(num int, data []byte, _ uint64) {
+		switch num {
+		case 1:
+			name = string(data)
+		case 2:
+			fields = append(fields, data)
+		case 3:
+			nested = append(nested, data)
+		case 4:
+			enums = append(enums, data)
+		case 8:
+			protoFields(data, func(num int, data []byte, _ uint64) {
+				if num == 1 {
+					oneofs = append(oneofs, string(data))
+				}
+			})
+		}
+	})
+	rel := joinProto(parent, name)
+	goName := goCamelCase(rel)
+	x.types[goName] = x.ref(schema.ProtoKindMessage, x.full(rel))
+
+	used := make(map[uint64]bool) // oneof non sintetici (esclusi i proto3 optional)
+	for _, f := range fields {
+		var fname string
+		var oneof uint64
+		inOneof, optional := false, false
+		protoFields(f, func(num int, data []byte, v uint64) {
+			switch num {
+			case 1:
+				fname = string(data)
+			case 9:
+				oneof, inOneof = v, true
+			case 17:
+				optional = v != 0
+			}
+		})
+		ref := x.ref(schema.ProtoKindField, x.full(rel)+"."+fname)
+		x.methods[goName+".Get"+goCamelCase(fname)] = ref
+		if inOneof && !optional {
+			used[oneof] = true
+			x.types[goName+"_"+goCamelCase(fname)] = ref
+		}
+	}
+	for i, o := range oneofs {
+		if used[uint64(i)] {
+			ref := x.ref(schema.ProtoKindOneof, x.full(rel)+"."+o)
+			x.methods[goName+".Get"+goCamelCase(o)] = ref
+			x.types["is"+goName+"_"+goCamelCase(o)] = ref
+		}
+	}
+	for _, n := range nested {
+		x.message(n, rel)
+	}
+	for _, e := range enums {
+		x.enum(e, rel, goName)
+	}
+}
+
+// enum indicizza un EnumDescriptorProto (1 name, 2 value): i valori sono
+// costanti con il prefisso del messaggio che contiene l'enum o, al primo
+// livello, dell'enum stesso.
+func (x *protoIndex) enum(data []byte, parent, parentGo string) {
+	var name string
+	var values []string
+	protoFields(data, func(num int, data []byte, _ uint64) {
+		switch num {
+		case 1:
+			name = string(data)
+		case 2:
+			protoFields(data, func(num int, data []byte, _ uint64) {
+				if num == 1 {
+					values = append(values, string(data))
+				}
+			})
+		}
+	})
+	rel := joinProto(parent, name)
+	goName := goCamelCase(rel)
+	ref := x.ref(schema.ProtoKindEnum, x.full(rel))
+	x.types[goName] = ref
+	x.values[goName+"_name"] = ref
+	x.values[goName+"_value"] = ref
+	prefix := goName
+	if parentGo != "" {
+		prefix = parentGo
+	}
+	for _, v := range values {
+		x.values[prefix+"_"+v] = x.ref(schema.ProtoKindEnumValue, x.full(joinProto(parent, v)))
+	}
+}
+
+// service indicizza un ServiceDescriptorProto (1 name, 2 method) con i nomi
+// di protoc-gen-go-grpc e protoc-gen-connect-go.
+func (x *protoIndex) service(data []byte) {
+	var name string
+	var rpcs []string
+	protoFields(data, func(num int, data []byte, _ uint64) {
+		switch num {
+		case 1:
+			name = string(data)
+		case 2:
+			protoFields(data, func(num int, data []byte, _ uint64) {
+				if num == 1 {
+					rpcs = append(rpcs, string(data))
+				}
+			})
+		}
+	})
+	svc := goCamelCase(name)
+	ref := x.ref(schema.ProtoKindService, x.full(name))
+	receivers := []string{
+		svc + "Client", svc + "Server", "Unimplemented" + svc + "Server", unexport(svc) + "Client", // gRPC
+		svc + "Handler", "Unimplemented" + svc + "Handler", // Connect
+	}
+	for _, t := range receivers {
+		x.types[t] = ref
+	}
+	x.types["Unsafe"+svc+"Server"] = ref
+	for _, v := range []string{"New" + svc + "Client", "Register" + svc + "Server", svc + "_ServiceDesc", "New" + svc + "Handler", svc + "Name"} {
+		x.values[v] = ref
+	}
+	for _, rpc := range rpcs {
+		method := goCamelCase(rpc)
+		rr := x.ref(schema.ProtoKindRPC, x.full(name)+"."+rpc)
+		for _, t := range receivers {
+			x.methods[t+"."+method] = rr
+		}
+		// Stream di gRPC, come interfacce o alias generici
+		for _, suffix := range []string{"Client", "Server"} {
+			x.types[svc+"_"+method+suffix] = rr
+			x.types[unexport(svc)+method+suffix] = rr
+		}
+		x.values["_"+svc+"_"+method+"_Handler"] = rr
+		x.values[svc+"_"+method+"_FullMethodName"] = rr
+		x.values[svc+method+"Procedure"] = rr
+	}
+}
+
+// protoFields scorre i campi del messaggio protobuf serializzato b e chiama
+// fn con il numero di campo, il contenuto dei campi length-delimited e il
+// valore dei varint. Si ferma al primo campo non ben formato.
+func protoFields(b []byte, fn func(num int, data []byte, v uint64)) {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return
+		}
+		b = b[n:]
+		num := int(key >> 3)
+		switch key & 7 {
+		case 0: // varint
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return
+			}
+			b = b[n:]
+			fn(num, nil, v)
+		case 1: // fixed64
+			if len(b) < 8 {
+				return
+			}
+			b = b[8:]
+		case 2: // length-delimited
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return
+			}
+			fn(num, b[n:n+int(l)], 0)
+			b = b[n+int(l):]
+		case 5: // fixed32
+			if len(b) < 4 {
+				return
+			}
+			b = b[4:]
+		default: // gruppi, mai usati nei descrittori
+			return
+		}
+	}
+}
+
+func joinProto(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// goCamelCase converte un nome protobuf nell'identificatore Go generato,
+// come strs.GoCamelCase di protogen: "." diventa "_", "_x" diventa "X".
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isLowerASCII(s[i+1]):
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLowerASCII(s[i+1]):
+		case c >= '0' && c <= '9':
+			b = append(b, c)
+		default:
+			if isLowerASCII(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLowerASCII(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+// goSanitized sostituisce i caratteri non ammessi negli identificatori,
+// come per il nome delle variabili del descrittore (es. "File_api_user_proto").
+func goSanitized(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+func unexport(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func isLowerASCII(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
@@ -0,0 +1,321 @@
+package symbols
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"maps"
+	"slices"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// LargeReceiverBytes è la dimensione oltre la quale un receiver per valore
+// è segnalato come copia costosa.
+const LargeReceiverBytes = 80
+
+// Codici degli issue sui receiver.
+const (
+	CodeLostReceiverWrite = "LOST_RECEIVER_WRITE" // un metodo con receiver per valore ne modifica la copia
+	CodeLargeValueRecv    = "LARGE_VALUE_RECEIVER"
+)
+
+// recvState accumula le modifiche al receiver di un metodo.
+type recvState struct {
+	fn      *ast.FuncDecl
+	obj     types.Object // la variabile receiver, nil se anonima
+	ptr     bool
+	fields  map[string]bool
+	calls   map[string]bool
+	lost    bool                 // una scrittura modifica solo la copia locale
+	callees map[*types.Func]bool // metodi chiamati sul receiver stesso
+}
+
+// receiverMutations analizza i metodi con corpo del package: campi del
+// receiver scritti (assegnamenti, ++/--, &r.f, delete/clear), metodi che lo
+// modificano chiamati su di esso (con punto fisso sui metodi del package) e
+// dimensione del receiver per valore. Senza informazioni di tipo restituisce
+// nil.
+func receiverMutations(pkg *packages.Package) map[*ast.FuncDecl]*schema.CLDKReceiverMutation {
+	info := pkg.TypesInfo
+	if info == nil {
+		return nil
+	}
+	states := make(map[*types.Func]*recvState)
+	var order []*recvState
+	for _, file := range pkg.Syntax {
+		if file == nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 || fn.Body == nil {
+				continue
+			}
+			fobj, _ := info.Defs[fn.Name].(*types.Func)
+			if fobj == nil {
+				continue
+			}
+			s := &recvState{fn: fn, fields: map[string]bool{}, calls: map[string]bool{}, callees: map[*types.Func]bool{}}
+			if names := fn.Recv.List[0].Names; len(names) > 0 {
+				s.obj = info.Defs[names[0]]
+			}
+			_, s.ptr = fobj.Type().(*types.Signature).Recv().Type().(*types.Pointer)
+			if s.obj != nil {
+				s.scan(info)
+			}
+			states[fobj] = s
+			order = append(order, s)
+		}
+	}
+
+	// Punto fisso: un metodo che chiama sul receiver un metodo del package
+	// con receiver puntatore che lo modifica lo modifica a sua volta.
+	for changed := true; changed; {
+		changed = false
+		for _, s := range order {
+			for callee := range s.callees {
+				cs := states[callee]
+				name := s.obj.Name() + "." + callee.Name()
+				if cs == nil || !cs.ptr || !cs.mutates() || s.calls[name] {
+					continue
+				}
+				s.calls[name] = true
+				s.lost = s.lost || !s.ptr
+				changed = true
+			}
+		}
+	}
+
+	out := make(map[*ast.FuncDecl]*schema.CLDKReceiverMutation, len(order))
+	for _, s := range order {
+		m := &schema.CLDKReceiverMutation{
+			Mutates:       s.mutates(),
+			WrittenFields: slices.Sorted(maps.Keys(s.fields)),
+			MutatingCalls: slices.Sorted(maps.Keys(s.calls)),
+			LostWrites:    s.lost && !s.ptr,
+		}
+		if !s.ptr && s.obj != nil && pkg.TypesSizes != nil {
+			if size, ok := sizeof(pkg.TypesSizes, s.obj.Type()); ok {
+				m.Size = size
+				m.LargeCopy = size > LargeReceiverBytes
+			}
+		}
+		out[s.fn] = m
+	}
+	return out
+}
+
+func (s *recvState) mutates() bool { return len(s.fields) > 0 || len(s.calls) > 0 }
+
+// scan visita il corpo del metodo cercando scritture attraverso il receiver.
+func (s *recvState) scan(info *types.Info) {
+	ast.Inspect(s.fn.Body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.AssignStmt:
+			if x.Tok == token.DEFINE {
+				return true
+			}
+			for _, lhs := range x.Lhs {
+				s.write(lhs, info)
+			}
+		case *ast.IncDecStmt:
+			s.write(x.X, info)
+		case *ast.UnaryExpr:
+			// &r.f può essere usato per scrivere: è una modifica, ma non
+			// necessariamente persa (spesso è solo letto)
+			if field, _, ok := s.path(x.X, info); ok && x.Op == token.AND {
+				s.fields[field] = true
+			}
+		case *ast.RangeStmt:
+			if x.Tok == token.ASSIGN {
+				s.write(x.Key, info)
+				s.write(x.Value, info)
+			}
+		case *ast.CallExpr:
+			s.call(x, info)
+		}
+		return true
+	})
+}
+
+// write registra una scrittura su e se è radicata nel receiver.
+func (s *recvState) write(e ast.Expr, info *types.Info) {
+	if field, direct, ok := s.path(e, info); ok {
+		s.fields[field] = true
+		s.lost = s.lost || direct
+	}
+}
+
+// path risale e fino al receiver: restituisce il primo campo selezionato,
+// se la scrittura resta nella memoria del receiver (nessun puntatore, slice
+// o map attraversato) e se e è radicata nel receiver. Le scritture sul
+// receiver intero (*r, r[i], r = ... per valore) hanno campo "*".
+func (s *recvState) path(e ast.Expr, info *types.Info) (field string, direct, ok bool) {
+	direct = true
+	for steps := 0; e != nil; steps++ {
+		switch x := ast.Unparen(e).(type) {
+		case *ast.Ident:
+			if info.Uses[x] != s.obj || (steps == 0 && s.ptr) {
+				return "", false, false // r = ... con receiver puntatore cambia solo la variabile locale
+			}
+			if field == "" {
+				field = "*"
+			}
+			return field, direct, true
+		case *ast.SelectorExpr:
+			sel := info.Selections[x]
+			if sel == nil || sel.Kind() != types.FieldVal {
+				return "", false, false
+			}
+			if isIndirect(info.TypeOf(x.X)) {
+				direct = false
+			}
+			field = x.Sel.Name
+			e = x.X
+		case *ast.IndexExpr:
+			if t := info.TypeOf(x.X); t != nil {
+				if _, isArray := t.Underlying().(*types.Array); !isArray {
+					direct = false
+				}
+			}
+			e = x.X
+		case *ast.StarExpr:
+			direct = false
+			e = x.X
+		default:
+			return "", false, false
+		}
+	}
+	return "", false, false
+}
+
+// call registra le chiamate che modificano il receiver: metodi con receiver
+// puntatore su un suo campo (o, per i metodi del package, su di esso) e
+// delete/clear su una sua map o slice.
+func (s *recvState) call(call *ast.CallExpr, info *types.Info) {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		if b, ok := info.Uses[fun].(*types.Builtin); ok && (b.Name() == "delete" || b.Name() == "clear") && len(call.Args) > 0 {
+			if field, _, ok := s.path(call.Args[0], info); ok {
+				s.fields[field] = true
+			}
+		}
+	case *ast.SelectorExpr:
+		sel := info.Selections[fun]
+		if sel == nil || sel.Kind() != types.MethodVal {
+			return
+		}
+		callee, _ := sel.Obj().(*types.Func)
+		if callee == nil {
+			return
+		}
+		// r.M(): dipende da M, risolto nel punto fisso
+		if id, ok := ast.Unparen(fun.X).(*ast.Ident); ok && info.Uses[id] == s.obj {
+			s.callees[callee.Origin()] = true
+			return
+		}
+		recv := callee.Type().(*types.Signature).Recv()
+		if recv == nil {
+			return
+		}
+		if _, ptr := recv.Type().(*types.Pointer); !ptr {
+			return
+		}
+		if field, direct, ok := s.path(fun.X, info); ok {
+			s.fields[field] = true
+			s.calls[exprString(fun.X)+"."+callee.Name()] = true
+			// r.f.M() con f puntatore modifica memoria condivisa
+			s.lost = s.lost || (direct && !isIndirect(info.TypeOf(fun.X)))
+		}
+	}
+}
+
+func isIndirect(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Interface, *types.Signature:
+		return true
+	}
+	return false
+}
+
+// sizeof restituisce la dimensione di t; false per i tipi generici, di cui
+// la dimensione dipende dalle istanze.
+func sizeof(sizes types.Sizes, t types.Type) (int64, bool) {
+	if n, ok := types.Unalias(t).(*types.Named); ok && n.TypeParams().Len() > 0 {
+		return 0, false
+	}
+	return sizes.Sizeof(t), true
+}
+
+// ReceiverIssues restituisce, per i metodi del progetto con receiver per
+// valore, un warning se modificano solo la loro copia del receiver e un
+// info se il receiver supera LargeReceiverBytes: in entrambi i casi il
+// receiver puntatore è il refactoring suggerito.
+func ReceiverIssues(result *loader.LoadResult) []schema.Issue {
+	var issues []schema.Issue
+	seen := make(map[string]bool)
+	for _, pkg := range result.ScopedPackages() {
+		if pkg == nil {
+			continue
+		}
+		for fn, m := range receiverMutations(pkg) {
+			recv, ptr := extractReceiverInfo(fn.Recv)
+			id := ids.Method(pkg.PkgPath, recv, ptr, fn.Name.Name)
+			if ptr || seen[id] {
+				continue
+			}
+			seen[id] = true
+			if m.LostWrites {
+				issues = append(issues, schema.Issue{
+					Severity: "warning",
+					Code:     CodeLostReceiverWrite,
+					Message:  fmt.Sprintf("%s has a value receiver but modifies it (%s): the changes are lost when it returns", id, mutationSummary(m)),
+					Position: posOf(result.Fset, fn.Pos(), result.Root),
+				})
+			}
+			if m.LargeCopy {
+				issues = append(issues, schema.Issue{
+					Severity: "info",
+					Code:     CodeLargeValueRecv,
+					Message:  fmt.Sprintf("%s copies its %d-byte receiver on every call; consider a pointer receiver", id, m.Size),
+					Position: posOf(result.Fset, fn.Pos(), result.Root),
+				})
+			}
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i].Position, issues[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return issues[i].Code < issues[j].Code
+	})
+	return issues
+}
+
+func mutationSummary(m *schema.CLDKReceiverMutation) string {
+	var parts []string
+	if len(m.WrittenFields) > 0 {
+		parts = append(parts, fmt.Sprintf("writes %v", m.WrittenFields))
+	}
+	if len(m.MutatingCalls) > 0 {
+		parts = append(parts, fmt.Sprintf("calls %v", m.MutatingCalls))
+	}
+	if len(parts) == 2 {
+		return parts[0] + ", " + parts[1]
+	}
+	return parts[0]
+}
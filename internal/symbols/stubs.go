@@ -0,0 +1,121 @@
+package symbols
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Implementazioni di una funzione dichiarata senza corpo.
+const (
+	ImplAsm      = "asm"      // corpo in un file .s del package
+	ImplLinkname = "linkname" // corpo altrove, collegato con //go:linkname
+)
+
+// CodeStub identifica l'issue emesso per ogni stub quando si costruisce il
+// call graph: le chiamate fatte dal corpo reale non sono visibili.
+const CodeStub = "CG_UNSOUND_STUB"
+
+// stubKinds restituisce, per ogni FuncDecl senza corpo del package, come è
+// implementata: linkname se il file ha una direttiva //go:linkname sul suo
+// nome, altrimenti asm se il package ha file .s (il compilatore rifiuta le
+// dichiarazioni senza corpo negli altri casi).
+func stubKinds(pkg *packages.Package) map[*ast.FuncDecl]string {
+	asm := false
+	for _, f := range pkg.OtherFiles {
+		asm = asm || strings.HasSuffix(f, ".s")
+	}
+
+	var out map[*ast.FuncDecl]string
+	for _, file := range pkg.Syntax {
+		if file == nil {
+			continue
+		}
+		var linked map[string]bool
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body != nil {
+				continue
+			}
+			if linked == nil {
+				linked = linknames(file)
+			}
+			kind := ""
+			switch {
+			case linked[fn.Name.Name]:
+				kind = ImplLinkname
+			case asm:
+				kind = ImplAsm
+			default:
+				continue
+			}
+			if out == nil {
+				out = make(map[*ast.FuncDecl]string)
+			}
+			out[fn] = kind
+		}
+	}
+	return out
+}
+
+// linknames restituisce i nomi locali delle direttive //go:linkname del file.
+func linknames(file *ast.File) map[string]bool {
+	out := make(map[string]bool)
+	for _, cg := range file.Comments {
+		for _, c := range cg.List {
+			if f := strings.Fields(c.Text); len(f) >= 2 && f[0] == "//go:linkname" {
+				out[f[1]] = true
+			}
+		}
+	}
+	return out
+}
+
+// StubIssues restituisce un warning per ogni funzione del progetto senza
+// corpo Go (assembly o //go:linkname): il call graph contiene le chiamate
+// verso di essa ma non quelle fatte dalla sua implementazione.
+func StubIssues(result *loader.LoadResult) []schema.Issue {
+	var issues []schema.Issue
+	seen := make(map[string]bool)
+	for _, pkg := range result.ScopedPackages() {
+		if pkg == nil {
+			continue
+		}
+		for fn, kind := range stubKinds(pkg) {
+			id := ids.Func(pkg.PkgPath, fn.Name.Name)
+			if fn.Recv != nil {
+				recv, ptr := extractReceiverInfo(fn.Recv)
+				id = ids.Method(pkg.PkgPath, recv, ptr, fn.Name.Name)
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			where := "assembly"
+			if kind == ImplLinkname {
+				where = "a //go:linkname target"
+			}
+			issues = append(issues, schema.Issue{
+				Severity: "warning",
+				Code:     CodeStub,
+				Message:  fmt.Sprintf("%s is implemented in %s: calls made by its implementation are missing from the call graph", id, where),
+				Position: posOf(result.Fset, fn.Pos(), result.Root),
+			})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i].Position, issues[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.StartLine < b.StartLine
+	})
+	return issues
+}
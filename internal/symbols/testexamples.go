@@ -0,0 +1,187 @@
+package symbols
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// populateExamples aggiunge alla symbol table le funzioni ExampleXxx dei
+// file _test.go di ogni package, anche senza --include-tests: i file di test
+// sono letti dalla directory del package attraverso il loader (overlay e
+// --root-archive compresi) e associati ai simboli con
+// go/doc (Example -> package, ExampleF -> F, ExampleT -> T,
+// ExampleT_M -> T.M, con suffisso opzionale "_xxx").
+func populateExamples(st *schema.CLDKSymbolTable, pkgs []*packages.Package, result *loader.LoadResult) {
+	done := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg == nil || done[pkg.PkgPath] || st.Packages[pkg.PkgPath] == nil {
+			continue
+		}
+		files, dir := packageFiles(pkg, result.Fset)
+		if dir == "" {
+			continue
+		}
+		done[pkg.PkgPath] = true
+		tests := parseTestFiles(result, dir)
+		if len(tests) == 0 {
+			continue
+		}
+		// PreserveAST: i file sono condivisi con le altre fasi
+		dp, err := doc.NewFromFiles(result.Fset, append(files, tests...), pkg.PkgPath, doc.AllDecls|doc.PreserveAST)
+		if err != nil {
+			continue
+		}
+		attachExamples(st.Packages[pkg.PkgPath], dp, pkg.PkgPath, result)
+	}
+}
+
+// packageFiles restituisce i file non di test del package e la loro
+// directory.
+func packageFiles(pkg *packages.Package, fset *token.FileSet) ([]*ast.File, string) {
+	var files []*ast.File
+	dir := ""
+	for _, f := range pkg.Syntax {
+		if f == nil {
+			continue
+		}
+		name := fset.Position(f.Package).Filename
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		files = append(files, f)
+		if dir == "" {
+			dir = filepath.Dir(name)
+		}
+	}
+	return files, dir
+}
+
+// parseTestFiles analizza i file _test.go di dir che soddisfano i build
+// constraint correnti.
+func parseTestFiles(result *loader.LoadResult, dir string) []*ast.File {
+	entries, err := result.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	ctxt := build.Default
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		data, err := result.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := ctxt.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		src, err := result.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(result.Fset, path, src, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// attachExamples copia gli esempi di dp sui simboli corrispondenti di cp.
+func attachExamples(cp *schema.CLDKPackage, dp *doc.Package, pkgPath string, result *loader.LoadResult) {
+	sources := make(map[string][]byte)
+	convert := func(exs []*doc.Example) []schema.CLDKExample {
+		var out []schema.CLDKExample
+		for _, ex := range exs {
+			out = append(out, convertExample(ex, result, sources))
+		}
+		return out
+	}
+	setCallable := func(id string, exs []*doc.Example) {
+		if cd := cp.CallableDeclarations[id]; cd != nil && len(exs) > 0 {
+			cd.Examples = convert(exs)
+		}
+	}
+
+	cp.Examples = convert(dp.Examples)
+	for _, f := range dp.Funcs {
+		setCallable(ids.Func(pkgPath, f.Name), f.Examples)
+	}
+	for _, t := range dp.Types {
+		if td := cp.TypeDeclarations[ids.Type(pkgPath, t.Name)]; td != nil && len(t.Examples) > 0 {
+			td.Examples = convert(t.Examples)
+		}
+		for _, f := range t.Funcs {
+			setCallable(ids.Func(pkgPath, f.Name), f.Examples)
+		}
+		for _, m := range t.Methods {
+			if len(m.Examples) == 0 {
+				continue
+			}
+			id := ids.Method(pkgPath, t.Name, strings.HasPrefix(m.Recv, "*"), m.Name)
+			setCallable(id, m.Examples)
+			if td := cp.TypeDeclarations[ids.Type(pkgPath, t.Name)]; td != nil {
+				if cm := td.Methods[id]; cm != nil {
+					cm.Examples = convert(m.Examples)
+				}
+			}
+		}
+	}
+}
+
+// convertExample converte un esempio di go/doc: il codice è il testo del
+// corpo nel sorgente, letto attraverso il loader, senza le graffe e
+// l'indentazione comune.
+func convertExample(ex *doc.Example, result *loader.LoadResult, sources map[string][]byte) schema.CLDKExample {
+	fset := result.Fset
+	out := schema.CLDKExample{
+		Name:        "Example" + ex.Name,
+		Suffix:      ex.Suffix,
+		Doc:         strings.TrimSpace(ex.Doc),
+		Output:      strings.TrimSuffix(ex.Output, "\n"),
+		EmptyOutput: ex.EmptyOutput,
+		Unordered:   ex.Unordered,
+	}
+	body, ok := ex.Code.(*ast.BlockStmt)
+	if !ok {
+		return out
+	}
+	out.Position = posOf(fset, body.Pos(), result.Root)
+	start, end := fset.Position(body.Lbrace), fset.Position(body.Rbrace)
+	src, ok := sources[start.Filename]
+	if !ok {
+		src, _ = result.ReadFile(start.Filename)
+		sources[start.Filename] = src
+	}
+	if start.Offset < end.Offset && end.Offset <= len(src) {
+		lines := strings.Split(string(src[start.Offset+1:end.Offset]), "\n")
+		for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+			lines = lines[1:]
+		}
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		out.Code = dedent(lines)
+	}
+	return out
+}
@@ -0,0 +1,191 @@
+package symbols
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+)
+
+// Wrappers restituisce i wrapper sottili dei package (vedi wrappers),
+// indicizzati per ID del callable, con l'ID della funzione chiamata.
+func Wrappers(pkgs []*packages.Package) map[string]string {
+	out := make(map[string]string)
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for fn, target := range wrappers(pkg) {
+			if obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+				out[ids.Object(obj)] = target
+			}
+		}
+	}
+	return out
+}
+
+// wrappers individua le funzioni e i metodi del package il cui corpo è una
+// sola chiamata che inoltra tutti i parametri, nell'ordine: "return f(a, b)"
+// o "f(a, b)" senza risultati. Sono ammessi argomenti aggiuntivi costanti
+// (es. OpenFile(name, O_RDONLY, 0)), un parametro variadico inoltrato con
+// "..." e, come receiver della chiamata, un parametro, il receiver del
+// metodo o un suo campo (delega, es. s.inner.Get(k)). Il valore è l'ID
+// della funzione chiamata; per le chiamate a metodi d'interfaccia è l'ID
+// del metodo astratto.
+func wrappers(pkg *packages.Package) map[*ast.FuncDecl]string {
+	info := pkg.TypesInfo
+	if info == nil {
+		return nil
+	}
+	out := make(map[*ast.FuncDecl]string)
+	for _, file := range pkg.Syntax {
+		if file == nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || len(fn.Body.List) != 1 {
+				continue
+			}
+			if target := wrapped(fn, info); target != "" {
+				out[fn] = target
+			}
+		}
+	}
+	return out
+}
+
+// wrapped restituisce l'ID della funzione inoltrata da fn, "" se fn non è
+// un wrapper.
+func wrapped(fn *ast.FuncDecl, info *types.Info) string {
+	self, ok := info.Defs[fn.Name].(*types.Func)
+	if !ok {
+		return ""
+	}
+	sig := self.Type().(*types.Signature)
+
+	var call *ast.CallExpr
+	switch s := fn.Body.List[0].(type) {
+	case *ast.ReturnStmt:
+		if len(s.Results) == 1 && sig.Results().Len() > 0 {
+			call, _ = ast.Unparen(s.Results[0]).(*ast.CallExpr)
+		}
+	case *ast.ExprStmt:
+		if sig.Results().Len() == 0 {
+			call, _ = ast.Unparen(s.X).(*ast.CallExpr)
+		}
+	}
+	if call == nil {
+		return ""
+	}
+	target := calledFunc(call, info)
+	if target == nil || target.Origin() == self {
+		return ""
+	}
+
+	// parametri da inoltrare, nell'ordine di dichiarazione
+	params := make([]types.Object, 0, sig.Params().Len())
+	for i := 0; i < sig.Params().Len(); i++ {
+		p := sig.Params().At(i)
+		if p.Name() == "" || p.Name() == "_" {
+			return ""
+		}
+		params = append(params, p)
+	}
+	var recv types.Object
+	if sig.Recv() != nil {
+		recv = sig.Recv()
+	}
+
+	next := 0 // prossimo parametro atteso tra gli argomenti
+	if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+		if s := info.Selections[sel]; s != nil && s.Kind() == types.MethodVal {
+			// receiver della chiamata: un parametro inoltrato per primo,
+			// oppure il receiver del metodo o un suo campo
+			root := rootIdent(sel.X)
+			if root == nil {
+				return ""
+			}
+			switch obj := info.Uses[root]; {
+			case recv != nil && obj == recv:
+			case len(params) > 0 && obj == params[0] && root == ast.Unparen(sel.X):
+				next = 1
+			default:
+				if v, ok := obj.(*types.Var); !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+					return ""
+				}
+			}
+		}
+	}
+	for i, arg := range call.Args {
+		id, _ := ast.Unparen(arg).(*ast.Ident)
+		if next < len(params) && id != nil && info.Uses[id] == params[next] {
+			if sig.Variadic() && next == len(params)-1 && (i != len(call.Args)-1 || !call.Ellipsis.IsValid()) {
+				return ""
+			}
+			next++
+			continue
+		}
+		if tv, ok := info.Types[arg]; !ok || (tv.Value == nil && !tv.IsNil()) {
+			return ""
+		}
+	}
+	if next != len(params) {
+		return ""
+	}
+
+	if recv := target.Type().(*types.Signature).Recv(); recv != nil && types.IsInterface(recv.Type()) {
+		return ids.InterfaceMethod(target)
+	}
+	return ids.Object(target)
+}
+
+// calledFunc restituisce la funzione o il metodo chiamato staticamente, nil
+// per chiamate dinamiche, builtin e conversioni.
+func calledFunc(call *ast.CallExpr, info *types.Info) *types.Func {
+	var id *ast.Ident
+	switch f := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	case *ast.IndexExpr: // funzione generica istanziata
+		id = indexedIdent(f.X)
+	case *ast.IndexListExpr:
+		id = indexedIdent(f.X)
+	}
+	if id == nil {
+		return nil
+	}
+	fn, _ := info.Uses[id].(*types.Func)
+	return fn
+}
+
+func indexedIdent(x ast.Expr) *ast.Ident {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return x
+	case *ast.SelectorExpr:
+		return x.Sel
+	}
+	return nil
+}
+
+// rootIdent restituisce l'identificatore alla base di una catena di
+// selettori (s in s.a.b), nil per espressioni con chiamate o indici.
+func rootIdent(e ast.Expr) *ast.Ident {
+	for {
+		switch x := ast.Unparen(e).(type) {
+		case *ast.Ident:
+			return x
+		case *ast.SelectorExpr:
+			e = x.X
+		case *ast.StarExpr:
+			e = x.X
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package treemap costruisce la gerarchia package → file → callable della
+// symbol table con le dimensioni già aggregate (righe di codice o
+// complessità ciclomatica), pronta per essere disegnata come treemap.
+package treemap
+
+import (
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Metriche usate come value dei nodi.
+const (
+	SizeSLOC       = "sloc"
+	SizeComplexity = "complexity"
+)
+
+// Build costruisce la treemap di analysis. I sorgenti sono letti da fsys (i
+// sorgenti visti dal loader, con radice in Metadata.ProjectPath; nil = dal
+// disco) per contare le righe con codice; se un file non è leggibile conta
+// tutte le righe della dichiarazione. La complessità richiede i body
+// (--include-body).
+func Build(analysis *schema.CLDKAnalysis, sizeBy string, fsys fs.FS) *schema.CLDKTreemap {
+	root := analysis.Metadata.ProjectPath
+	tm := &schema.CLDKTreemap{
+		SchemaVersion: schema.SchemaVersion,
+		Project:       root,
+		SizeBy:        sizeBy,
+		Root:          &schema.TreemapNode{Name: filepath.Base(root), Kind: "project"},
+	}
+	st := analysis.SymbolTable
+	if st == nil {
+		return tm
+	}
+
+	lines := &codeLines{root: root, fsys: fsys, files: make(map[string]map[int]bool)}
+	for _, pkgPath := range slices.Sorted(maps.Keys(st.Packages)) {
+		pkg := st.Packages[pkgPath]
+		pn := &schema.TreemapNode{Name: pkgPath, Kind: "package", ID: pkgPath}
+		files := make(map[string]*schema.TreemapNode)
+		for _, id := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+			cd := pkg.CallableDeclarations[id]
+			if cd.Position == nil || cd.Position.File == "" {
+				continue
+			}
+			fn := files[cd.Position.File]
+			if fn == nil {
+				fn = &schema.TreemapNode{Name: path.Base(cd.Position.File), Kind: "file", ID: cd.Position.File}
+				files[cd.Position.File] = fn
+			}
+			name := cd.Name
+			if cd.ReceiverType != "" {
+				name = cd.ReceiverType + "." + cd.Name
+			}
+			leaf := &schema.TreemapNode{Name: name, Kind: cd.Kind, ID: id}
+			end := cd.Position.StartLine
+			if cd.EndPosition != nil {
+				end = cd.EndPosition.StartLine
+			}
+			leaf.SLOC = lines.count(cd.Position.File, cd.Position.StartLine, end)
+			if cd.Body != nil {
+				leaf.Complexity = cd.Body.Complexity
+			}
+			fn.Children = append(fn.Children, leaf)
+		}
+		for _, file := range slices.Sorted(maps.Keys(files)) {
+			fn := files[file]
+			sort.SliceStable(fn.Children, func(i, j int) bool {
+				return startLine(pkg, fn.Children[i].ID) < startLine(pkg, fn.Children[j].ID)
+			})
+			pn.Children = append(pn.Children, fn)
+		}
+		if len(pn.Children) > 0 {
+			tm.Root.Children = append(tm.Root.Children, pn)
+		}
+	}
+	aggregate(tm.Root, sizeBy)
+	return tm
+}
+
+// aggregate somma sloc e complexity dei figli e imposta value.
+func aggregate(n *schema.TreemapNode, sizeBy string) {
+	if len(n.Children) > 0 {
+		n.SLOC, n.Complexity = 0, 0
+		for _, c := range n.Children {
+			aggregate(c, sizeBy)
+			n.SLOC += c.SLOC
+			n.Complexity += c.Complexity
+		}
+	}
+	n.Value = n.SLOC
+	if sizeBy == SizeComplexity {
+		n.Value = n.Complexity
+	}
+}
+
+func startLine(pkg *schema.CLDKPackage, id string) int {
+	return pkg.CallableDeclarations[id].Position.StartLine
+}
+
+// codeLines conta le righe con almeno un token non commento, leggendo ogni
+// file una sola volta.
+type codeLines struct {
+	root  string
+	fsys  fs.FS
+	files map[string]map[int]bool // file relativo → righe con codice (nil = non leggibile)
+}
+
+func (c *codeLines) count(file string, from, to int) int {
+	code, ok := c.files[file]
+	if !ok {
+		code = c.scan(file)
+		c.files[file] = code
+	}
+	if code == nil {
+		return to - from + 1
+	}
+	n := 0
+	for l := from; l <= to; l++ {
+		if code[l] {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *codeLines) scan(file string) map[int]bool {
+	src, err := loader.ReadFile(c.fsys, c.root, filepath.Join(c.root, filepath.FromSlash(file)))
+	if err != nil {
+		return nil
+	}
+	fset := token.NewFileSet()
+	f := fset.AddFile(file, -1, len(src))
+	var s scanner.Scanner
+	s.Init(f, src, nil, 0) // senza ScanComments i commenti sono saltati
+	code := make(map[int]bool)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue // punto e virgola implicito a fine riga
+		}
+		code[f.Line(pos)] = true
+	}
+	return code
+}
@@ -0,0 +1,256 @@
+// Package typegraph costruisce il grafo dei riferimenti tra tipi: per ogni
+// tipo dichiarato nel progetto, i tipi con nome usati nei suoi campi, negli
+// incorporamenti, nelle firme dei metodi di interfaccia, nei vincoli dei
+// parametri di tipo e nel tipo sottostante.
+package typegraph
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/ids"
+	"github.com/codellm-devkit/codeanalyzer-go/internal/loader"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Kind degli archi.
+const (
+	EdgeField      = "field"
+	EdgeEmbed      = "embed"
+	EdgeMethod     = "method"
+	EdgeConstraint = "constraint"
+	EdgeUnderlying = "underlying"
+	EdgeAlias      = "alias"
+)
+
+type builder struct {
+	fset  *token.FileSet
+	root  string
+	nodes map[string]*schema.CLDKTypeNode
+	edges map[schema.CLDKTypeEdge]bool // senza Position, per non ripetere gli archi
+	out   []schema.CLDKTypeEdge
+	seen  map[token.Position]bool
+}
+
+// Build restituisce il grafo dei tipi dichiarati a livello di package nei
+// file sotto root, nil se non ce ne sono. I tipi predichiarati (int,
+// string, error, ...) e i parametri di tipo non sono nodi.
+func Build(pkgs []*packages.Package, fset *token.FileSet, root string) *schema.CLDKTypeGraph {
+	b := &builder{
+		fset:  fset,
+		root:  root,
+		nodes: make(map[string]*schema.CLDKTypeNode),
+		edges: make(map[schema.CLDKTypeEdge]bool),
+		seen:  make(map[token.Position]bool), // le varianti di test ripetono i file
+	}
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if file == nil || !loader.UnderRoot(fset.Position(file.Pos()).Filename, root) {
+				continue
+			}
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					obj, ok := pkg.TypesInfo.Defs[ts.Name].(*types.TypeName)
+					if !ok {
+						continue
+					}
+					pos := fset.Position(obj.Pos())
+					if b.seen[pos] {
+						continue
+					}
+					b.seen[pos] = true
+					b.declared(obj)
+				}
+			}
+		}
+	}
+	if len(b.nodes) == 0 {
+		return nil
+	}
+
+	g := &schema.CLDKTypeGraph{Nodes: make([]schema.CLDKTypeNode, 0, len(b.nodes)), Edges: b.out}
+	for _, n := range b.nodes {
+		g.Nodes = append(g.Nodes, *n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	if g.Edges == nil {
+		g.Edges = []schema.CLDKTypeEdge{}
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Via < b.Via
+	})
+	return g
+}
+
+// declared aggiunge il nodo di un tipo del progetto e i suoi archi.
+func (b *builder) declared(obj *types.TypeName) {
+	src := b.node(obj)
+	src.External = false
+	src.Position = loader.Position(b.fset.Position(obj.Pos()), b.root)
+
+	if obj.IsAlias() {
+		if alias, ok := obj.Type().(*types.Alias); ok {
+			b.refs(src.ID, alias.Rhs(), EdgeAlias, "", obj.Pos())
+		}
+		return
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return
+	}
+	for i := 0; i < named.TypeParams().Len(); i++ {
+		tp := named.TypeParams().At(i)
+		b.refs(src.ID, tp.Constraint(), EdgeConstraint, tp.Obj().Name(), tp.Obj().Pos())
+	}
+	switch u := named.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			f := u.Field(i)
+			kind := EdgeField
+			if f.Embedded() {
+				kind = EdgeEmbed
+			}
+			b.refs(src.ID, f.Type(), kind, f.Name(), f.Pos())
+		}
+	case *types.Interface:
+		for i := 0; i < u.NumEmbeddeds(); i++ {
+			b.refs(src.ID, u.EmbeddedType(i), EdgeEmbed, "", obj.Pos())
+		}
+		for i := 0; i < u.NumExplicitMethods(); i++ {
+			m := u.ExplicitMethod(i)
+			b.refs(src.ID, m.Type(), EdgeMethod, m.Name(), m.Pos())
+		}
+	default:
+		b.refs(src.ID, u, EdgeUnderlying, "", obj.Pos())
+	}
+}
+
+// refs aggiunge un arco da source a ogni tipo con nome che compare in t.
+func (b *builder) refs(source string, t types.Type, kind, via string, pos token.Pos) {
+	for _, tn := range namedIn(t) {
+		target := b.node(tn)
+		if target.ID == source && kind == EdgeUnderlying {
+			continue
+		}
+		e := schema.CLDKTypeEdge{Source: source, Target: target.ID, Kind: kind, Via: via}
+		if b.edges[e] {
+			continue
+		}
+		b.edges[e] = true
+		if pos.IsValid() {
+			e.Position = loader.Position(b.fset.Position(pos), b.root)
+		}
+		b.out = append(b.out, e)
+	}
+}
+
+// node restituisce il nodo del tipo, creandolo come esterno se manca.
+func (b *builder) node(obj *types.TypeName) *schema.CLDKTypeNode {
+	id := ids.Type(obj.Pkg().Path(), obj.Name())
+	if n, ok := b.nodes[id]; ok {
+		return n
+	}
+	kind := "named"
+	switch {
+	case obj.IsAlias():
+		kind = "alias"
+	case types.IsInterface(obj.Type()):
+		kind = "interface"
+	default:
+		if _, ok := obj.Type().Underlying().(*types.Struct); ok {
+			kind = "struct"
+		}
+	}
+	n := &schema.CLDKTypeNode{ID: id, Package: obj.Pkg().Path(), Name: obj.Name(), Kind: kind, External: true}
+	b.nodes[id] = n
+	return n
+}
+
+// namedIn restituisce i tipi con nome (o alias) che compaiono in t, in
+// ordine di apparizione: dentro puntatori, slice, array, mappe, canali,
+// firme, struct e interfacce anonime e argomenti di tipo. Le istanze
+// generiche contano come il tipo generico.
+func namedIn(t types.Type) []*types.TypeName {
+	var out []*types.TypeName
+	seen := make(map[types.Type]bool)
+	var walk func(t types.Type)
+	walk = func(t types.Type) {
+		if t == nil || seen[t] {
+			return
+		}
+		seen[t] = true
+		switch t := t.(type) {
+		case *types.Alias:
+			if t.Obj().Pkg() != nil {
+				out = append(out, t.Obj())
+			}
+			for i := 0; i < t.TypeArgs().Len(); i++ {
+				walk(t.TypeArgs().At(i))
+			}
+		case *types.Named:
+			if t.Obj().Pkg() != nil {
+				out = append(out, t.Origin().Obj())
+			}
+			for i := 0; i < t.TypeArgs().Len(); i++ {
+				walk(t.TypeArgs().At(i))
+			}
+		case *types.Pointer:
+			walk(t.Elem())
+		case *types.Slice:
+			walk(t.Elem())
+		case *types.Array:
+			walk(t.Elem())
+		case *types.Chan:
+			walk(t.Elem())
+		case *types.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case *types.Signature:
+			for i := 0; i < t.Params().Len(); i++ {
+				walk(t.Params().At(i).Type())
+			}
+			for i := 0; i < t.Results().Len(); i++ {
+				walk(t.Results().At(i).Type())
+			}
+		case *types.Struct:
+			for i := 0; i < t.NumFields(); i++ {
+				walk(t.Field(i).Type())
+			}
+		case *types.Interface:
+			for i := 0; i < t.NumEmbeddeds(); i++ {
+				walk(t.EmbeddedType(i))
+			}
+			for i := 0; i < t.NumExplicitMethods(); i++ {
+				walk(t.ExplicitMethod(i).Type())
+			}
+		case *types.Union:
+			for i := 0; i < t.Len(); i++ {
+				walk(t.Term(i).Type())
+			}
+		}
+	}
+	walk(t)
+	return out
+}
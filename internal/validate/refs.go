@@ -0,0 +1,287 @@
+package validate
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// References verifica l'integrità referenziale: ogni estremo di un arco è un
+// nodo esistente, le chiavi delle mappe coincidono con i qualified name e ogni
+// posizione di un package del progetto cita un file della sua lista files.
+// Con metadata.scoped_files la lista files è ristretta ai file richiesti,
+// quindi le posizioni di call graph e PDG non sono verificate.
+func References(a *schema.CLDKAnalysis) []Problem {
+	r := &refChecker{scoped: len(a.Metadata.ScopedFiles) > 0}
+	r.symbolTable(a.SymbolTable)
+	r.callGraph(a.CallGraph, a.SymbolTable)
+	r.pdg(a.PDG, a.SymbolTable)
+	r.sdg(a.SDG, a.PDG)
+	r.cycles(a.Cycles, a.CallGraph, a.SymbolTable)
+	return r.problems
+}
+
+type refChecker struct {
+	problems []Problem
+	scoped   bool // symbol table ristretta a --files
+}
+
+func (r *refChecker) add(path, format string, args ...interface{}) {
+	r.problems = append(r.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// position verifica che pos citi un file del package.
+func (r *refChecker) position(pos *schema.CLDKPosition, files map[string]bool, path string) {
+	if pos == nil || files == nil {
+		return
+	}
+	if !files[pos.File] {
+		r.add(path+".file", "file %q is not in the package file list; positions must be relative to the analyzed root (re-run with the same --input)", pos.File)
+	}
+}
+
+func (r *refChecker) symbolTable(st *schema.CLDKSymbolTable) {
+	if st == nil {
+		return
+	}
+	for _, pkgPath := range slices.Sorted(maps.Keys(st.Packages)) {
+		pkg := st.Packages[pkgPath]
+		base := fmt.Sprintf("$.symbol_table.packages[%q]", pkgPath)
+		if pkg == nil {
+			r.add(base, "package entry is null")
+			continue
+		}
+		if pkg.Path != pkgPath {
+			r.add(base+".path", "path %q does not match its map key", pkg.Path)
+		}
+		files := fileSet(pkg)
+
+		for i, imp := range pkg.Imports {
+			r.position(imp.Position, files, fmt.Sprintf("%s.imports[%d].position", base, i))
+		}
+		for _, qn := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+			c := pkg.CallableDeclarations[qn]
+			p := fmt.Sprintf("%s.callable_declarations[%q]", base, qn)
+			if c.QualifiedName != qn {
+				r.add(p+".qualified_name", "qualified name %q does not match its map key", c.QualifiedName)
+			}
+			r.position(c.Position, files, p+".position")
+			r.position(c.EndPosition, files, p+".end_position")
+		}
+		for _, qn := range slices.Sorted(maps.Keys(pkg.TypeDeclarations)) {
+			t := pkg.TypeDeclarations[qn]
+			p := fmt.Sprintf("%s.type_declarations[%q]", base, qn)
+			if t.QualifiedName != qn {
+				r.add(p+".qualified_name", "qualified name %q does not match its map key", t.QualifiedName)
+			}
+			r.position(t.Position, files, p+".position")
+			for i := range t.Fields {
+				r.position(t.Fields[i].Position, files, fmt.Sprintf("%s.fields[%d].position", p, i))
+			}
+			for _, mqn := range slices.Sorted(maps.Keys(t.Methods)) {
+				m := t.Methods[mqn]
+				mp := fmt.Sprintf("%s.methods[%q]", p, mqn)
+				if m.QualifiedName != mqn {
+					r.add(mp+".qualified_name", "qualified name %q does not match its map key", m.QualifiedName)
+				}
+				r.position(m.Position, files, mp+".position")
+				r.position(m.EndPosition, files, mp+".end_position")
+			}
+		}
+		for _, qn := range slices.Sorted(maps.Keys(pkg.Variables)) {
+			r.position(pkg.Variables[qn].Position, files, fmt.Sprintf("%s.variables[%q].position", base, qn))
+		}
+		for _, qn := range slices.Sorted(maps.Keys(pkg.Constants)) {
+			r.position(pkg.Constants[qn].Position, files, fmt.Sprintf("%s.constants[%q].position", base, qn))
+		}
+	}
+}
+
+func (r *refChecker) callGraph(cg *schema.CLDKCallGraph, st *schema.CLDKSymbolTable) {
+	if cg == nil {
+		return
+	}
+	callables := callableKeys(st)
+	nodes := make(map[string]bool, len(cg.Nodes))
+	for i, n := range cg.Nodes {
+		p := fmt.Sprintf("$.call_graph.nodes[%d]", i)
+		if nodes[n.ID] {
+			r.add(p+".id", "duplicate node ID %q; node IDs must be unique", n.ID)
+		}
+		nodes[n.ID] = true
+		if n.SymbolRef != "" && callables != nil && !r.scoped && !callables[n.SymbolRef] {
+			r.add(p+".symbol_ref", "%q is not a key of any callable_declarations; symbol table and call graph must come from the same run", n.SymbolRef)
+		}
+		if pkg := lookupPackage(st, n.Package); pkg != nil && !r.scoped {
+			r.position(n.Position, fileSet(pkg), p+".position")
+		}
+	}
+	for i, e := range cg.Edges {
+		p := fmt.Sprintf("$.call_graph.edges[%d]", i)
+		if !nodes[e.Source] {
+			r.add(p+".source", "%q is not a call graph node; the graph was edited or truncated after generation", e.Source)
+		}
+		if !nodes[e.Target] {
+			r.add(p+".target", "%q is not a call graph node; the graph was edited or truncated after generation", e.Target)
+		}
+	}
+}
+
+// callableKeys raccoglie le chiavi di callable_declarations di tutti i
+// package; nil senza symbol table.
+func callableKeys(st *schema.CLDKSymbolTable) map[string]bool {
+	if st == nil {
+		return nil
+	}
+	keys := make(map[string]bool)
+	for _, pkg := range st.Packages {
+		if pkg == nil {
+			continue
+		}
+		for qn := range pkg.CallableDeclarations {
+			keys[qn] = true
+		}
+	}
+	return keys
+}
+
+func (r *refChecker) pdg(pdg *schema.CLDKPDG, st *schema.CLDKSymbolTable) {
+	if pdg == nil {
+		return
+	}
+	for _, pkgPath := range slices.Sorted(maps.Keys(pdg.Packages)) {
+		pkg := pdg.Packages[pkgPath]
+		base := fmt.Sprintf("$.pdg.packages[%q]", pkgPath)
+		if pkg == nil {
+			r.add(base, "package entry is null")
+			continue
+		}
+		var files map[string]bool
+		if stPkg := lookupPackage(st, pkgPath); stPkg != nil && !r.scoped {
+			files = fileSet(stPkg)
+		}
+		for _, qn := range slices.Sorted(maps.Keys(pkg.Functions)) {
+			fn := pkg.Functions[qn]
+			p := fmt.Sprintf("%s.functions[%q]", base, qn)
+			if fn.QualifiedName != qn {
+				r.add(p+".qualified_name", "qualified name %q does not match its map key", fn.QualifiedName)
+			}
+			if fn.Package != pkgPath {
+				r.add(p+".package", "package %q does not match the enclosing package %q", fn.Package, pkgPath)
+			}
+			ids := make(map[int]bool, len(fn.Nodes))
+			for i, n := range fn.Nodes {
+				if ids[n.ID] {
+					r.add(fmt.Sprintf("%s.nodes[%d].id", p, i), "duplicate node ID %d", n.ID)
+				}
+				ids[n.ID] = true
+				r.position(n.Position, files, fmt.Sprintf("%s.nodes[%d].position", p, i))
+			}
+			for i, e := range fn.DataEdges {
+				r.nodeRef(ids, e.From, fmt.Sprintf("%s.data_edges[%d].from", p, i))
+				r.nodeRef(ids, e.To, fmt.Sprintf("%s.data_edges[%d].to", p, i))
+			}
+			for i, e := range fn.ControlEdges {
+				r.nodeRef(ids, e.From, fmt.Sprintf("%s.control_edges[%d].from", p, i))
+				r.nodeRef(ids, e.To, fmt.Sprintf("%s.control_edges[%d].to", p, i))
+			}
+		}
+	}
+}
+
+func (r *refChecker) nodeRef(ids map[int]bool, id int, path string) {
+	if !ids[id] {
+		r.add(path, "node %d does not exist in this function's PDG", id)
+	}
+}
+
+func (r *refChecker) sdg(sdg *schema.CLDKSDG, pdg *schema.CLDKPDG) {
+	if sdg == nil {
+		return
+	}
+	if pdg == nil {
+		r.add("$.sdg", "SDG edges reference PDG nodes but the pdg section is missing; regenerate with --analysis-level sdg or full")
+		return
+	}
+
+	funcs := make(map[string]*schema.CLDKFunctionPDG)
+	for _, pkg := range pdg.Packages {
+		if pkg == nil {
+			continue
+		}
+		for qn, fn := range pkg.Functions {
+			funcs[qn] = fn
+		}
+	}
+
+	for _, pkgPath := range slices.Sorted(maps.Keys(sdg.Packages)) {
+		pkg := sdg.Packages[pkgPath]
+		if pkg == nil {
+			continue
+		}
+		for i, e := range pkg.InterEdges {
+			p := fmt.Sprintf("$.sdg.packages[%q].inter_edges[%d]", pkgPath, i)
+			r.sdgEnd(funcs, e.CallerFunc, e.CallerNode, p+".caller_func", p+".caller_node")
+			r.sdgEnd(funcs, e.CalleeFunc, e.CalleeNode, p+".callee_func", p+".callee_node")
+		}
+	}
+}
+
+func (r *refChecker) sdgEnd(funcs map[string]*schema.CLDKFunctionPDG, fn string, node int, fnPath, nodePath string) {
+	f, ok := funcs[fn]
+	if !ok {
+		r.add(fnPath, "function %q has no PDG; SDG and PDG must come from the same run", fn)
+		return
+	}
+	for _, n := range f.Nodes {
+		if n.ID == node {
+			return
+		}
+	}
+	r.add(nodePath, "node %d does not exist in the PDG of %q", node, fn)
+}
+
+func (r *refChecker) cycles(c *schema.CLDKCycleReport, cg *schema.CLDKCallGraph, st *schema.CLDKSymbolTable) {
+	if c == nil {
+		return
+	}
+	if cg != nil {
+		nodes := make(map[string]bool, len(cg.Nodes))
+		for _, n := range cg.Nodes {
+			nodes[n.ID] = true
+		}
+		for i, cyc := range c.RecursionGroups {
+			for j, m := range cyc.Members {
+				if !nodes[m] {
+					r.add(fmt.Sprintf("$.cycles.recursion_groups[%d].members[%d]", i, j), "%q is not a call graph node", m)
+				}
+			}
+		}
+	}
+	if st != nil {
+		for i, cyc := range c.ImportCycles {
+			for j, m := range cyc.Members {
+				if st.Packages[m] == nil {
+					r.add(fmt.Sprintf("$.cycles.import_cycles[%d].members[%d]", i, j), "%q is not a symbol table package", m)
+				}
+			}
+		}
+	}
+}
+
+func lookupPackage(st *schema.CLDKSymbolTable, path string) *schema.CLDKPackage {
+	if st == nil {
+		return nil
+	}
+	return st.Packages[path]
+}
+
+func fileSet(pkg *schema.CLDKPackage) map[string]bool {
+	files := make(map[string]bool, len(pkg.Files))
+	for _, f := range pkg.Files {
+		files[f] = true
+	}
+	return files
+}
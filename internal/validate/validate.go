@@ -0,0 +1,228 @@
+// Package validate verifica un output dell'analyzer: versione dello schema,
+// struttura rispetto al JSON Schema corrente e integrità referenziale tra
+// symbol table, call graph, PDG e SDG.
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/codellm-devkit/codeanalyzer-go/internal/output"
+	"github.com/codellm-devkit/codeanalyzer-go/pkg/schema"
+)
+
+// Problem è un errore di validazione riferito a un elemento del documento.
+type Problem struct {
+	Path    string `json:"path"`    // percorso JSON dell'elemento (es. $.call_graph.edges[3].target)
+	Message string `json:"message"` // descrizione con l'azione suggerita
+}
+
+func (p Problem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// File legge e valida un analysis.json (anche compresso). L'errore è riservato ai problemi di
+// I/O e di parsing; i problemi di contenuto sono restituiti come []Problem.
+func File(path string) ([]Problem, error) {
+	data, err := output.ReadBytes(path)
+	if err != nil {
+		return nil, fmt.Errorf("read analysis: %w", err)
+	}
+	return Bytes(data)
+}
+
+// Bytes valida un analysis.json già in memoria.
+func Bytes(data []byte) ([]Problem, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse analysis: %w", err)
+	}
+
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return []Problem{{Path: "$", Message: "top-level value must be an object"}}, nil
+	}
+	if _, compact := root["m"]; compact {
+		return []Problem{{Path: "$", Message: "compact output (--compact) cannot be validated; regenerate without --compact"}}, nil
+	}
+
+	problems := Version(root)
+	problems = append(problems, Structure(doc)...)
+	if len(problems) > 0 {
+		// L'integrità referenziale presuppone una struttura corretta
+		return problems, nil
+	}
+
+	var analysis schema.CLDKAnalysis
+	if err := json.Unmarshal(data, &analysis); err != nil {
+		return nil, fmt.Errorf("decode analysis: %w", err)
+	}
+	return References(&analysis), nil
+}
+
+// Version confronta metadata.schema_version con schema.SchemaVersion: una
+// major diversa (o assente) rende il file incompatibile.
+func Version(root map[string]interface{}) []Problem {
+	meta, _ := root["metadata"].(map[string]interface{})
+	v, _ := meta["schema_version"].(string)
+	if v == "" {
+		return []Problem{{
+			Path:    "$.metadata.schema_version",
+			Message: fmt.Sprintf("missing: file was produced by an analyzer older than schema %s; regenerate it with the current codeanalyzer-go", schema.SchemaVersion),
+		}}
+	}
+	if major(v) != major(schema.SchemaVersion) {
+		return []Problem{{
+			Path:    "$.metadata.schema_version",
+			Message: fmt.Sprintf("schema %s is incompatible with %s; regenerate the file or use a matching codeanalyzer-go release", v, schema.SchemaVersion),
+		}}
+	}
+	return nil
+}
+
+func major(v string) string {
+	m, _, _ := strings.Cut(v, ".")
+	return m
+}
+
+// ============================================================================
+// Struttura (JSON Schema)
+// ============================================================================
+
+// Structure valida doc (decodificato con UseNumber) rispetto a
+// schema.JSONSchema(). Supporta il sottoinsieme di keyword che JSONSchema
+// genera: $ref, anyOf, type, properties, required, additionalProperties, items.
+func Structure(doc interface{}) []Problem {
+	s := schema.JSONSchema()
+	v := &structValidator{defs: s["$defs"].(map[string]interface{})}
+	v.check(doc, s, "$")
+	return v.problems
+}
+
+type structValidator struct {
+	defs     map[string]interface{}
+	problems []Problem
+}
+
+func (v *structValidator) add(path, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// check restituisce false se x non è conforme a s.
+func (v *structValidator) check(x interface{}, s map[string]interface{}, path string) bool {
+	if ref, ok := s["$ref"].(string); ok {
+		def := v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		return v.check(x, def, path)
+	}
+
+	if alts, ok := s["anyOf"].([]interface{}); ok {
+		// Gli anyOf generati sono sempre {T, null}: si riporta l'errore di T
+		if x == nil {
+			return true
+		}
+		return v.check(x, alts[0].(map[string]interface{}), path)
+	}
+
+	t, _ := s["type"].(string)
+	if t == "" {
+		return true
+	}
+	if !matchesType(x, t) {
+		v.add(path, "expected %s, got %s", t, typeName(x))
+		return false
+	}
+
+	ok := true
+	switch t {
+	case "object":
+		obj := x.(map[string]interface{})
+		props, hasProps := s["properties"].(map[string]interface{})
+		if req, found := s["required"].([]string); found {
+			for _, r := range req {
+				if _, present := obj[r]; !present {
+					v.add(path, "missing required field %q", r)
+					ok = false
+				}
+			}
+		}
+		for _, k := range slices.Sorted(maps.Keys(obj)) {
+			if hasProps {
+				ps, known := props[k].(map[string]interface{})
+				if !known {
+					if s["additionalProperties"] == false {
+						v.add(path+"."+k, "unknown field; it is not part of schema %s", schema.SchemaVersion)
+						ok = false
+					}
+					continue
+				}
+				ok = v.check(obj[k], ps, path+"."+k) && ok
+				continue
+			}
+			if ap, isSchema := s["additionalProperties"].(map[string]interface{}); isSchema {
+				ok = v.check(obj[k], ap, fmt.Sprintf("%s[%q]", path, k)) && ok
+			}
+		}
+	case "array":
+		items, _ := s["items"].(map[string]interface{})
+		for i, e := range x.([]interface{}) {
+			if items != nil {
+				ok = v.check(e, items, fmt.Sprintf("%s[%d]", path, i)) && ok
+			}
+		}
+	}
+	return ok
+}
+
+func matchesType(x interface{}, t string) bool {
+	switch t {
+	case "object":
+		_, ok := x.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := x.([]interface{})
+		return ok
+	case "string":
+		_, ok := x.(string)
+		return ok
+	case "boolean":
+		_, ok := x.(bool)
+		return ok
+	case "integer":
+		n, ok := x.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := x.(json.Number)
+		return ok
+	case "null":
+		return x == nil
+	}
+	return true
+}
+
+func typeName(x interface{}) string {
+	switch x.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	}
+	return fmt.Sprintf("%T", x)
+}
@@ -0,0 +1,186 @@
+// Package yamlsubset legge il sottoinsieme YAML dei file di configurazione
+// letti dall'analizzatore (configurazioni di sqlc, mappe di ownership):
+// mappe e liste a blocco, anche con le voci "- chiave: valore", liste
+// [a, b] e scalari con o senza virgolette. Anchor, alias, mappe {a: b} e
+// testi multiriga non sono supportati. Gli scalari restano stringhe.
+package yamlsubset
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Map è una mappa YAML con le chiavi nell'ordine del file.
+type Map struct {
+	Keys   []string
+	Values map[string]any
+}
+
+// Parse legge text: le mappe sono map[string]any, le liste []any, gli
+// scalari string; un valore assente è nil.
+func Parse(text string) (any, error) {
+	v, err := ParseOrdered(text)
+	if err != nil {
+		return nil, err
+	}
+	return plain(v), nil
+}
+
+// ParseOrdered è come Parse, ma le mappe sono *Map e conservano l'ordine
+// delle chiavi.
+func ParseOrdered(text string) (any, error) {
+	var lines []line
+	for n, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimRight(stripComment(raw), " \t\r")
+		l := strings.TrimLeft(raw, " ")
+		if l == "" || l == "---" {
+			continue
+		}
+		if strings.HasPrefix(l, "\t") {
+			return nil, fmt.Errorf("line %d: tab indentation", n+1)
+		}
+		lines = append(lines, line{n: n + 1, indent: len(raw) - len(l), text: l})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	v, next, err := block(lines, 0, lines[0].indent)
+	if err == nil && next < len(lines) {
+		err = fmt.Errorf("line %d: unexpected indentation", lines[next].n)
+	}
+	return v, err
+}
+
+// Unquote toglie le virgolette singole o doppie attorno a s.
+func Unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// line è una riga significativa di un documento YAML.
+type line struct {
+	n      int // numero di riga
+	indent int
+	text   string
+}
+
+// block legge la mappa o la lista che inizia in lines[i] con
+// l'indentazione indent e restituisce l'indice della riga successiva.
+func block(lines []line, i, indent int) (any, int, error) {
+	if strings.HasPrefix(lines[i].text, "- ") || lines[i].text == "-" {
+		var list []any
+		for i < len(lines) && lines[i].indent == indent && (strings.HasPrefix(lines[i].text, "- ") || lines[i].text == "-") {
+			item := strings.TrimSpace(strings.TrimPrefix(lines[i].text, "-"))
+			switch {
+			case item == "":
+				if i+1 < len(lines) && lines[i+1].indent > indent {
+					v, next, err := block(lines, i+1, lines[i+1].indent)
+					if err != nil {
+						return nil, 0, err
+					}
+					list, i = append(list, v), next
+					continue
+				}
+				list, i = append(list, nil), i+1
+			case isKey(item):
+				// "- chiave: valore" apre una mappa indentata dopo il trattino
+				inner := indent + len(lines[i].text) - len(item)
+				sub := append([]line{{n: lines[i].n, indent: inner, text: item}}, lines[i+1:]...)
+				v, next, err := block(sub, 0, inner)
+				if err != nil {
+					return nil, 0, err
+				}
+				list, i = append(list, v), i+next
+			default:
+				list, i = append(list, scalar(item)), i+1
+			}
+		}
+		return list, i, nil
+	}
+
+	m := &Map{Values: make(map[string]any)}
+	for i < len(lines) && lines[i].indent == indent {
+		if !isKey(lines[i].text) {
+			return nil, 0, fmt.Errorf("line %d: expected \"key:\"", lines[i].n)
+		}
+		key, val, _ := strings.Cut(lines[i].text, ":")
+		key, val = Unquote(strings.TrimSpace(key)), strings.TrimSpace(val)
+		if _, dup := m.Values[key]; !dup {
+			m.Keys = append(m.Keys, key)
+		}
+		i++
+		if val != "" {
+			m.Values[key] = scalar(val)
+			continue
+		}
+		// Il valore è il blocco più indentato o, per le liste, anche allo
+		// stesso livello della chiave.
+		if i < len(lines) && (lines[i].indent > indent || lines[i].indent == indent && strings.HasPrefix(lines[i].text, "- ")) {
+			v, next, err := block(lines, i, lines[i].indent)
+			if err != nil {
+				return nil, 0, err
+			}
+			m.Values[key], i = v, next
+			continue
+		}
+		m.Values[key] = nil
+	}
+	return m, i, nil
+}
+
+// isKey riconosce "chiave:" e "chiave: valore" (non "a:b" né URL).
+func isKey(s string) bool {
+	key, val, ok := strings.Cut(s, ":")
+	return ok && key != "" && !strings.HasPrefix(key, "[") && !strings.HasPrefix(key, "{") && (val == "" || val[0] == ' ')
+}
+
+// scalar converte uno scalare o una lista [a, b] di scalari.
+func scalar(s string) any {
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		list := []any{}
+		for _, e := range strings.Split(s[1:len(s)-1], ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				list = append(list, Unquote(e))
+			}
+		}
+		return list
+	}
+	return Unquote(s)
+}
+
+// stripComment toglie un commento "#" che non sia tra virgolette.
+func stripComment(s string) string {
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// plain sostituisce ricorsivamente le *Map con map[string]any.
+func plain(v any) any {
+	switch v := v.(type) {
+	case *Map:
+		m := make(map[string]any, len(v.Values))
+		for k, e := range v.Values {
+			m[k] = plain(e)
+		}
+		return m
+	case []any:
+		for i, e := range v {
+			v[i] = plain(e)
+		}
+	}
+	return v
+}
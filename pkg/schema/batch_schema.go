@@ -0,0 +1,26 @@
+package schema
+
+// ============================================================================
+// Batch Schema
+// ============================================================================
+// Manifest scritto da "batch" nella directory di output (batch.json).
+
+// CLDKBatchManifest riassume un'esecuzione di "batch".
+type CLDKBatchManifest struct {
+	Proxy     string           `json:"proxy"`     // lista GOPROXY usata
+	Args      []string         `json:"args"`      // flag passati a ogni "analyze"
+	Succeeded int              `json:"succeeded"` // moduli con status ok
+	Failed    int              `json:"failed"`    // moduli con status fetch_error|failed
+	Modules   []CLDKBatchEntry `json:"modules"`   // nell'ordine della lista
+}
+
+// CLDKBatchEntry è l'esito dell'analisi di un modulo.
+type CLDKBatchEntry struct {
+	Module     string `json:"module"`
+	Version    string `json:"version"`          // risolta se richiesta come latest
+	Status     string `json:"status"`           // ok|fetch_error|failed
+	ExitCode   int    `json:"exit_code"`        // exit code di "analyze" (0 se non eseguito)
+	Output     string `json:"output,omitempty"` // directory dell'output, relativa alla radice del batch
+	Error      string `json:"error,omitempty"`  // ultime righe di stderr o errore di download
+	DurationMs int64  `json:"duration_ms"`      // download compreso
+}
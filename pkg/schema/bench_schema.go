@@ -0,0 +1,49 @@
+package schema
+
+// ============================================================================
+// Bench Schema
+// ============================================================================
+// Report di "bench", usato anche come baseline per i confronti successivi.
+
+// CLDKBenchReport riassume un'esecuzione di "bench".
+type CLDKBenchReport struct {
+	Version     string                `json:"version"`               // versione dell'analyzer misurato
+	Modes       []string              `json:"modes"`                 // profili o livelli di analisi
+	Runs        int                   `json:"runs"`                  // esecuzioni per misura (si riporta la mediana)
+	Args        []string              `json:"args"`                  // flag aggiuntivi passati ad "analyze"
+	Results     []CLDKBenchResult     `json:"results"`               // per repo e modalità, in ordine
+	Baseline    string                `json:"baseline,omitempty"`    // file di baseline confrontato
+	Regressions []CLDKBenchRegression `json:"regressions,omitempty"` // misure oltre le soglie
+}
+
+// CLDKBenchResult è la misura di una modalità su un repo del corpus.
+type CLDKBenchResult struct {
+	Repo           string           `json:"repo"` // nome nel corpus (directory o archivio)
+	Mode           string           `json:"mode"`
+	Status         string           `json:"status"` // ok|failed
+	Error          string           `json:"error,omitempty"`
+	DurationMs     int64            `json:"duration_ms"`                // tempo reale del processo, mediana
+	PeakRSSBytes   int64            `json:"peak_rss_bytes"`             // da metadata.resources, mediana
+	OutputBytes    int64            `json:"output_bytes"`               // dimensione di analysis.json
+	PhaseTimingsMs map[string]int64 `json:"phase_timings_ms,omitempty"` // dell'esecuzione mediana
+}
+
+// CLDKTimings è il file scritto da "analyze --timings-file" dopo l'output:
+// a differenza di metadata contiene anche la serializzazione, che in
+// streaming non può entrare nel documento che misura.
+type CLDKTimings struct {
+	DurationMs     int64            `json:"duration_ms"`      // analisi più scrittura dell'output
+	PhaseTimingsMs map[string]int64 `json:"phase_timings_ms"` // fasi di metadata più "serialize"
+	Resources      *ResourceUsage   `json:"resources"`        // campionate dopo la scrittura
+}
+
+// CLDKBenchRegression segnala una misura peggiorata rispetto alla baseline
+// oltre la soglia della sua metrica.
+type CLDKBenchRegression struct {
+	Repo      string  `json:"repo"`
+	Mode      string  `json:"mode"`
+	Metric    string  `json:"metric"` // duration_ms|peak_rss_bytes|output_bytes
+	Baseline  int64   `json:"baseline"`
+	Current   int64   `json:"current"`
+	ChangePct float64 `json:"change_pct"`
+}
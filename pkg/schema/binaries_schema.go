@@ -0,0 +1,57 @@
+package schema
+
+// ============================================================================
+// Binaries Schema
+// ============================================================================
+// Inventario dei package main del progetto (vedi --binaries): nei monorepo
+// con molti comandi, per ciascun binario i vincoli di build, i flag
+// definiti con il package flag e i package del progetto che importa.
+
+// CLDKBinaries elenca i binari del progetto.
+type CLDKBinaries struct {
+	Binaries []CLDKBinary `json:"binaries"` // ordinati per directory
+}
+
+// CLDKBinary è un package main.
+type CLDKBinary struct {
+	Name             string           `json:"name"`    // nome del binario prodotto da go build (ultimo elemento dell'import path)
+	Package          string           `json:"package"` // import path
+	Dir              string           `json:"dir"`     // directory relativa alla root
+	Main             *CLDKPosition    `json:"main,omitempty"`
+	BuildConstraints []string         `json:"build_constraints,omitempty"` // espressioni //go:build distinte dei suoi file
+	IgnoredFiles     []string         `json:"ignored_files,omitempty"`     // file esclusi dai vincoli sulla piattaforma corrente
+	Flags            []CLDKBinaryFlag `json:"flags"`                       // flag definiti dal package main e dai package del progetto che importa
+	Imports          []string         `json:"imports"`                     // package del progetto importati, anche transitivamente, ordinati
+	Shutdown         *CLDKShutdown    `json:"shutdown,omitempty"`          // con --shutdown
+}
+
+// CLDKBinaryFlag è un flag definito con il package flag.
+type CLDKBinaryFlag struct {
+	Name     string        `json:"name"`
+	Type     string        `json:"type"`              // string|int|bool|duration|float64|int64|uint|uint64|func|bool_func|text|value
+	Default  string        `json:"default,omitempty"` // valore costante o espressione sorgente
+	Usage    string        `json:"usage,omitempty"`
+	FlagSet  string        `json:"flag_set,omitempty"` // espressione del *flag.FlagSet, vuota per flag.CommandLine
+	Package  string        `json:"package"`            // package che lo definisce
+	Position *CLDKPosition `json:"position,omitempty"`
+}
+
+// CLDKShutdown descrive la gestione dei segnali e lo spegnimento di un
+// binario: ricezione di SIGINT/SIGTERM, poi cancellazione dei context,
+// Shutdown dei server e attesa delle goroutine.
+type CLDKShutdown struct {
+	Signals  []string           `json:"signals"`  // segnali passati a signal.Notify/NotifyContext, "all" senza argomenti
+	Server   bool               `json:"server"`   // avvia un server (ListenAndServe, Serve)
+	Graceful bool               `json:"graceful"` // gestisce i segnali e ha almeno un passo di spegnimento
+	Steps    []CLDKShutdownStep `json:"steps"`    // ordinati per posizione
+}
+
+// CLDKShutdownStep è una chiamata rilevante per lo spegnimento.
+type CLDKShutdownStep struct {
+	Kind     string        `json:"kind"`               // signal|serve|cancel|shutdown|wait
+	Call     string        `json:"call"`               // funzione chiamata, es. "net/http.(*Server).Shutdown"
+	Signals  []string      `json:"signals,omitempty"`  // solo per signal
+	Function string        `json:"function,omitempty"` // funzione che la contiene, vuota negli inizializzatori
+	Package  string        `json:"package"`
+	Position *CLDKPosition `json:"position,omitempty"`
+}
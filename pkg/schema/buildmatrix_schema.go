@@ -0,0 +1,32 @@
+package schema
+
+// ============================================================================
+// Build Matrix Schema
+// ============================================================================
+// Vista opzionale (--build-matrix) dei vincoli di build del progetto: per
+// ogni file con //go:build o suffisso _GOOS/_GOARCH, le piattaforme in cui è
+// compilato e i simboli che vi dichiara.
+
+// CLDKBuildMatrix raccoglie i file e i package con vincoli di build.
+type CLDKBuildMatrix struct {
+	Platforms []string           `json:"platforms"` // goos/goarch[+tag...] nell'ordine di --build-matrix
+	Files     []CLDKBuildFile    `json:"files"`     // file con vincoli, ordinati per path
+	Packages  []CLDKBuildPackage `json:"packages"`  // package con almeno un file vincolato
+}
+
+// CLDKBuildFile è un file con vincoli di build.
+type CLDKBuildFile struct {
+	File       string   `json:"file"` // relativo alla root
+	Package    string   `json:"package"`
+	Constraint string   `json:"constraint,omitempty"`  // espressione //go:build normalizzata
+	FileSuffix string   `json:"file_suffix,omitempty"` // vincolo implicito nel nome (es. "windows", "linux_arm64")
+	Platforms  []string `json:"platforms"`             // piattaforme in cui il file è compilato
+	Symbols    []string `json:"symbols,omitempty"`     // ID delle dichiarazioni top-level
+}
+
+// CLDKBuildPackage riporta le piattaforme in cui un package ha almeno un file.
+type CLDKBuildPackage struct {
+	Package          string   `json:"package"`
+	Platforms        []string `json:"platforms"`
+	ConstrainedFiles int      `json:"constrained_files"`
+}
@@ -0,0 +1,77 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+// ============================================================================
+// Call Hierarchy Schema
+// ============================================================================
+// Chiamate entranti e uscenti di ogni funzione nella forma dei tipi
+// CallHierarchy del Language Server Protocol (3.16), scritte con --format
+// call-hierarchy: un plugin IDE le mostra senza un server LSP. I nomi JSON
+// dei tipi LSP seguono la specifica (camelCase) e le posizioni sono a base
+// 0 con colonne in unità UTF-16.
+
+// Valori di LSPCallHierarchyItem.Kind (SymbolKind LSP).
+const (
+	LSPSymbolKindPackage  = 4
+	LSPSymbolKindMethod   = 6
+	LSPSymbolKindFunction = 12
+)
+
+// CLDKCallHierarchy è il documento scritto in call_hierarchy.json.
+type CLDKCallHierarchy struct {
+	SchemaVersion string                         `json:"schema_version"`
+	Project       string                         `json:"project"`
+	RootURI       string                         `json:"root_uri"`  // URI file:// del progetto
+	Functions     map[string]*CallHierarchyEntry `json:"functions"` // per ID del nodo del call graph
+}
+
+// CallHierarchyEntry è la gerarchia di una funzione: le risposte LSP a
+// prepareCallHierarchy, callHierarchy/incomingCalls e outgoingCalls.
+type CallHierarchyEntry struct {
+	Item     LSPCallHierarchyItem           `json:"item"`
+	Incoming []LSPCallHierarchyIncomingCall `json:"incoming"`
+	Outgoing []LSPCallHierarchyOutgoingCall `json:"outgoing"`
+}
+
+// LSPCallHierarchyItem è un CallHierarchyItem LSP.
+type LSPCallHierarchyItem struct {
+	Name           string                `json:"name"`
+	Kind           int                   `json:"kind"`             // LSPSymbolKind*
+	Detail         string                `json:"detail,omitempty"` // "import/path • file.go"
+	URI            string                `json:"uri"`
+	Range          LSPRange              `json:"range"`          // dichiarazione intera (senza symbol table: il nome)
+	SelectionRange LSPRange              `json:"selectionRange"` // nome della funzione
+	Data           *LSPCallHierarchyData `json:"data,omitempty"`
+}
+
+// LSPCallHierarchyData è il campo data dell'item, restituito dal client
+// nelle richieste successive: identifica la voce in Functions.
+type LSPCallHierarchyData struct {
+	ID string `json:"id"`
+}
+
+// LSPCallHierarchyIncomingCall è un chiamante di Item; FromRanges sono i
+// call site nel chiamante.
+type LSPCallHierarchyIncomingCall struct {
+	From       LSPCallHierarchyItem `json:"from"`
+	FromRanges []LSPRange           `json:"fromRanges"`
+}
+
+// LSPCallHierarchyOutgoingCall è un chiamato da Item; FromRanges sono i
+// call site in Item.
+type LSPCallHierarchyOutgoingCall struct {
+	To         LSPCallHierarchyItem `json:"to"`
+	FromRanges []LSPRange           `json:"fromRanges"`
+}
+
+// LSPRange è un Range LSP (fine esclusa).
+type LSPRange struct {
+	Start LSPPosition `json:"start"`
+	End   LSPPosition `json:"end"`
+}
+
+// LSPPosition è una Position LSP: riga e colonna UTF-16 a base 0.
+type LSPPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
@@ -7,24 +7,80 @@ package schema
 
 // CLDKAnalysis è la struttura root dell'output dell'analyzer.
 type CLDKAnalysis struct {
-	Metadata    Metadata         `json:"metadata"`
-	SymbolTable *CLDKSymbolTable `json:"symbol_table,omitempty"`
-	CallGraph   *CLDKCallGraph   `json:"call_graph,omitempty"`
-	PDG         *CLDKPDG         `json:"pdg"`    // Program Dependence Graph (intra-procedural)
-	SDG         *CLDKSDG         `json:"sdg"`    // System Dependence Graph (inter-procedural)
-	Issues      []Issue          `json:"issues"`
+	Metadata    Metadata             `json:"metadata"`
+	SymbolTable *CLDKSymbolTable     `json:"symbol_table,omitempty"`
+	CallGraph   *CLDKCallGraph       `json:"call_graph,omitempty"`
+	PDG         *CLDKPDG             `json:"pdg"`                 // Program Dependence Graph (intra-procedural)
+	SDG         *CLDKSDG             `json:"sdg"`                 // System Dependence Graph (inter-procedural)
+	Summaries   *CLDKSummaries       `json:"summaries,omitempty"` // data-flow summaries per function
+	Metrics     *CLDKMetrics         `json:"metrics,omitempty"`
+	Cycles      *CLDKCycleReport     `json:"cycles,omitempty"`
+	Components  *CLDKComponentReport `json:"components,omitempty"`           // con --components
+	Layers      *CLDKLayers          `json:"layers,omitempty"`               // con --layers
+	BuildMatrix *CLDKBuildMatrix     `json:"build_matrix,omitempty"`         // con --build-matrix
+	Resources   *CLDKResources       `json:"resources,omitempty"`            // direttive //go:embed
+	DI          *CLDKDIGraph         `json:"dependency_injection,omitempty"` // wire, fx, dig
+	Kubernetes  *CLDKKubeAPI         `json:"kubernetes,omitempty"`           // tipi API e CRD
+	Clock       *CLDKClockUsage      `json:"clock_usage,omitempty"`          // con --clock-usage
+	HTTPAPI     *CLDKHTTPAPI         `json:"http_api,omitempty"`             // con --http-api
+	DataAccess  *CLDKDataAccess      `json:"data_access,omitempty"`          // con --data-access
+	Errors      *CLDKErrorTaxonomy   `json:"error_taxonomy,omitempty"`       // con --error-taxonomy
+	TypeGraph   *CLDKTypeGraph       `json:"type_graph,omitempty"`           // con --type-graph
+	Globals     *CLDKGlobals         `json:"globals,omitempty"`              // con --globals
+	InitEffects *CLDKInitEffects     `json:"init_effects,omitempty"`         // con --init-effects
+	Binaries    *CLDKBinaries        `json:"binaries,omitempty"`             // con --binaries
+	Issues      []Issue              `json:"issues"`
 }
 
 // Metadata contiene informazioni sull'analisi eseguita.
 type Metadata struct {
 	Analyzer           string `json:"analyzer"`
 	Version            string `json:"version"`
+	SchemaVersion      string `json:"schema_version,omitempty"` // versione del formato (SchemaVersion)
 	Language           string `json:"language"`
 	AnalysisLevel      string `json:"analysis_level"`
 	Timestamp          string `json:"timestamp"`
 	ProjectPath        string `json:"project_path"`
 	GoVersion          string `json:"go_version"`
 	AnalysisDurationMs int64  `json:"analysis_duration_ms"`
+
+	// Preset --profile usato (fast|standard|deep), vuoto se assente
+	Profile string `json:"profile,omitempty"`
+
+	// File a cui è ristretta la tabella dei simboli (--files); call graph e
+	// PDG coprono comunque i package che li contengono
+	ScopedFiles []string `json:"scoped_files,omitempty"`
+
+	// Package main a cui è ristretta l'analisi (--binary), con i package
+	// del progetto che importano
+	ScopedBinaries []string `json:"scoped_binaries,omitempty"`
+
+	// Configurazioni goos/goarch[+tag...] unite nella symbol table
+	// (--configs); la prima è quella usata dalle altre fasi
+	Configs []string `json:"configs,omitempty"`
+
+	// Durata in millisecondi per fase (load, typecheck, ssa, extract,
+	// callgraph, pdg, ...); la scrittura dell'output non è compresa
+	PhaseTimingsMs map[string]int64 `json:"phase_timings_ms,omitempty"`
+
+	// Risorse del processo, per confrontare le prestazioni tra versioni
+	Resources *ResourceUsage `json:"resources,omitempty"`
+
+	// Riepilogo delle issue: degraded indica un risultato parziale (errori di
+	// caricamento, parsing, type checking o fasi di analisi fallite)
+	Errors   int  `json:"errors,omitempty"`
+	Warnings int  `json:"warnings,omitempty"`
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+// ResourceUsage descrive le risorse usate dal processo di analisi. I valori
+// sono rilevati prima della scrittura dell'output.
+type ResourceUsage struct {
+	GoMaxProcs      int    `json:"gomaxprocs"`
+	NumCPU          int    `json:"num_cpu"`
+	PeakRSSBytes    int64  `json:"peak_rss_bytes,omitempty"` // non disponibile su tutti i sistemi
+	TotalAllocBytes uint64 `json:"total_alloc_bytes"`        // byte allocati in totale sull'heap
+	GCCycles        uint32 `json:"gc_cycles"`
 }
 
 // Issue rappresenta un problema rilevato durante l'analisi.
@@ -49,25 +105,32 @@ type CLDKPackage struct {
 	Path                 string                   `json:"path"`
 	Name                 string                   `json:"name"`
 	Documentation        string                   `json:"documentation,omitempty"`
+	Summary              string                   `json:"summary,omitempty"` // riassunto generato, con --summarize
 	Files                []string                 `json:"files"`
 	Imports              []CLDKImport             `json:"imports"`
 	TypeDeclarations     map[string]*CLDKType     `json:"type_declarations"`
 	CallableDeclarations map[string]*CLDKCallable `json:"callable_declarations"`
 	Variables            map[string]*CLDKVariable `json:"variables"`
 	Constants            map[string]*CLDKConstant `json:"constants"`
+	Examples             []CLDKExample            `json:"examples,omitempty"` // Example() del package, con --examples
 
 	// Package-level metadata for malware/security analysis
-	HasInit          bool     `json:"has_init,omitempty"`            // package contains init() function
-	HasGoroutines    bool     `json:"has_goroutines,omitempty"`      // package starts background goroutines (go statements)
-	ReadsEnv         bool     `json:"reads_env,omitempty"`           // package reads environment variables (os.Getenv, etc.)
-	BuildTags        []string `json:"build_tags,omitempty"`          // build constraints (//go:build directives)
-	UsedByPackages   []string `json:"used_by_packages,omitempty"`    // reverse imports: which project packages import this one
-	ReachableFromMain bool    `json:"reachable_from_main,omitempty"` // reachable from main() or init() via call graph
+	HasInit           bool              `json:"has_init,omitempty"`            // package contains init() function
+	HasGoroutines     bool              `json:"has_goroutines,omitempty"`      // package starts background goroutines (go statements)
+	ReadsEnv          bool              `json:"reads_env,omitempty"`           // package reads environment variables (os.Getenv, etc.)
+	BuildTags         []string          `json:"build_tags,omitempty"`          // build constraints (//go:build directives)
+	UsedByPackages    []string          `json:"used_by_packages,omitempty"`    // reverse imports: which project packages import this one
+	Owners            []string          `json:"owners,omitempty"`              // owners of the package files (--owners)
+	Tags              []string          `json:"tags,omitempty"`                // tags of the package files (--owners)
+	Configs           []string          `json:"configs,omitempty"`             // configurations where the package exists (--configs)
+	ProtoFiles        []CLDKProtoFile   `json:"proto_files,omitempty"`         // files generated by protoc plugins
+	ReachableFromMain bool              `json:"reachable_from_main,omitempty"` // reachable from main() or init() via call graph
+	Layer             *CLDKPackageLayer `json:"layer,omitempty"`               // inferred architectural layer (--layers)
 
 	// Extended security analysis (opt-in via flags)
-	StringLiterals     []CLDKStringLiteral  `json:"string_literals,omitempty"`      // extracted string literals with classification
-	SupplyChainVectors []SupplyChainVector  `json:"supply_chain_vectors,omitempty"` // detected supply chain attack vectors
-	ObfuscationMetrics *ObfuscationMetrics  `json:"obfuscation_metrics,omitempty"`  // code obfuscation indicators
+	StringLiterals     []CLDKStringLiteral `json:"string_literals,omitempty"`      // extracted string literals with classification
+	SupplyChainVectors []SupplyChainVector `json:"supply_chain_vectors,omitempty"` // detected supply chain attack vectors
+	ObfuscationMetrics *ObfuscationMetrics `json:"obfuscation_metrics,omitempty"`  // code obfuscation indicators
 }
 
 // CLDKImport rappresenta un import.
@@ -87,14 +150,24 @@ type CLDKType struct {
 	Name             string                 `json:"name"`
 	Kind             string                 `json:"kind"` // struct|interface|alias|named
 	Position         *CLDKPosition          `json:"position"`
+	EndPosition      *CLDKPosition          `json:"end_position,omitempty"` // fine della dichiarazione (dopo la "}" per struct e interface)
+	DeclSpan         *CLDKPosition          `json:"decl_span,omitempty"`    // intera dichiarazione, dall'inizio del doc comment
 	Documentation    string                 `json:"documentation,omitempty"`
 	Fields           []CLDKField            `json:"fields,omitempty"`
 	Methods          map[string]*CLDKMethod `json:"methods,omitempty"`
-	InterfaceMethods []CLDKInterfaceMethod   `json:"interface_methods,omitempty"`
+	InterfaceMethods []CLDKInterfaceMethod  `json:"interface_methods,omitempty"`
 	EmbeddedTypes    []string               `json:"embedded_types,omitempty"`
 	Implements       []string               `json:"implements,omitempty"`
 	UnderlyingType   string                 `json:"underlying_type,omitempty"`
 	TypeParameters   []CLDKTypeParam        `json:"type_parameters,omitempty"`
+	GitMetadata      *CLDKGitMetadata       `json:"git_metadata,omitempty"`
+	Owners           []string               `json:"owners,omitempty"`    // con --owners
+	Tags             []string               `json:"tags,omitempty"`      // con --owners
+	Configs          []string               `json:"configs,omitempty"`   // con --configs
+	Proto            *CLDKProtoRef          `json:"proto,omitempty"`     // origine .proto del codice generato
+	Layout           *CLDKStructLayout      `json:"layout,omitempty"`    // solo struct, con --struct-layout
+	Lifecycle        *CLDKTypeLifecycle     `json:"lifecycle,omitempty"` // con --lifecycle, esclusi interfacce e alias
+	Examples         []CLDKExample          `json:"examples,omitempty"`  // con --examples
 }
 
 // CLDKInterfaceMethod rappresenta un metodo dichiarato in un'interfaccia.
@@ -108,27 +181,53 @@ type CLDKInterfaceMethod struct {
 
 // CLDKField rappresenta un campo di una struct.
 type CLDKField struct {
-	Name       string        `json:"name"`
-	Type       string        `json:"type"`
-	Tag        string        `json:"tag,omitempty"`
-	Position   *CLDKPosition `json:"position,omitempty"`
-	Exported   bool          `json:"exported"`
-	Embedded   bool          `json:"embedded"`
+	Name     string        `json:"name"`
+	Type     string        `json:"type"`
+	Tag      string        `json:"tag,omitempty"`
+	Position *CLDKPosition `json:"position,omitempty"`
+	Exported bool          `json:"exported"`
+	Embedded bool          `json:"embedded"`
+	Offset   *int64        `json:"offset,omitempty"` // offset in byte (con --struct-layout)
+	Size     int64         `json:"size,omitempty"`   // dimensione in byte (con --struct-layout)
+	Align    int64         `json:"align,omitempty"`  // allineamento in byte (con --struct-layout)
+}
+
+// CLDKStructLayout descrive il layout in memoria di una struct.
+type CLDKStructLayout struct {
+	Size        int64 `json:"size"`         // dimensione totale in byte
+	Align       int64 `json:"align"`        // allineamento della struct
+	Padding     int64 `json:"padding"`      // byte sprecati in padding
+	OptimalSize int64 `json:"optimal_size"` // dimensione con i campi riordinati
 }
 
 // CLDKMethod rappresenta un metodo di un tipo.
 type CLDKMethod struct {
-	QualifiedName string            `json:"qualified_name"`
-	Name          string            `json:"name"`
-	Signature     string            `json:"signature"`
-	ReceiverType  string            `json:"receiver_type"`
-	ReceiverPtr   bool              `json:"receiver_ptr"`
-	Parameters    []CLDKParameter   `json:"parameters"`
-	Results       []CLDKParameter   `json:"results"`
-	Position      *CLDKPosition     `json:"position"`
-	EndPosition   *CLDKPosition     `json:"end_position,omitempty"`
-	Documentation string            `json:"documentation,omitempty"`
-	Body          *CLDKFunctionBody `json:"body,omitempty"`
+	QualifiedName    string                `json:"qualified_name"`
+	Name             string                `json:"name"`
+	Signature        string                `json:"signature"`
+	ReceiverType     string                `json:"receiver_type"`
+	ReceiverPtr      bool                  `json:"receiver_ptr"`
+	Parameters       []CLDKParameter       `json:"parameters"`
+	Results          []CLDKParameter       `json:"results"`
+	Position         *CLDKPosition         `json:"position"`
+	EndPosition      *CLDKPosition         `json:"end_position,omitempty"`
+	Documentation    string                `json:"documentation,omitempty"`
+	Implementation   string                `json:"implementation,omitempty"` // asm|linkname per le dichiarazioni senza corpo Go
+	ReceiverMutation *CLDKReceiverMutation `json:"receiver_mutation,omitempty"`
+	IsWrapper        bool                  `json:"is_wrapper,omitempty"`     // corpo di una sola chiamata che inoltra tutti i parametri
+	Wraps            string                `json:"wraps,omitempty"`          // ID della funzione inoltrata dal wrapper
+	Classification   string                `json:"classification,omitempty"` // getter|setter|stringer|constructor|boilerplate
+	Fingerprint      *CLDKFingerprint      `json:"fingerprint,omitempty"`    // con --fingerprints
+	Effects          []string              `json:"effects,omitempty"`        // reads_fs|writes_fs|network|exec|env, con --effects
+	Purity           *CLDKPurity           `json:"purity,omitempty"`         // con --purity
+	Examples         []CLDKExample         `json:"examples,omitempty"`       // con --examples
+	Summary          string                `json:"summary,omitempty"`        // riassunto generato, con --summarize
+	Body             *CLDKFunctionBody     `json:"body,omitempty"`
+	GitMetadata      *CLDKGitMetadata      `json:"git_metadata,omitempty"`
+	Owners           []string              `json:"owners,omitempty"`  // con --owners
+	Tags             []string              `json:"tags,omitempty"`    // con --owners
+	Configs          []string              `json:"configs,omitempty"` // con --configs
+	Proto            *CLDKProtoRef         `json:"proto,omitempty"`
 }
 
 // CLDKTypeParam rappresenta un parametro di tipo generico.
@@ -143,21 +242,87 @@ type CLDKTypeParam struct {
 
 // CLDKCallable rappresenta una funzione o metodo.
 type CLDKCallable struct {
-	QualifiedName  string            `json:"qualified_name"`
-	Name           string            `json:"name"`
-	Signature      string            `json:"signature"`
-	Kind           string            `json:"kind"` // function|method
-	ReceiverType   string            `json:"receiver_type,omitempty"`
-	ReceiverPtr    bool              `json:"receiver_ptr,omitempty"`
-	Parameters     []CLDKParameter   `json:"parameters"`
-	Results        []CLDKParameter   `json:"results"`
-	Position       *CLDKPosition     `json:"position"`
-	EndPosition    *CLDKPosition     `json:"end_position,omitempty"`
-	Documentation  string            `json:"documentation,omitempty"`
-	Exported       bool              `json:"exported"`
-	TypeParameters []CLDKTypeParam   `json:"type_parameters,omitempty"`
-	Body           *CLDKFunctionBody `json:"body,omitempty"`
-	CallExamples   []string          `json:"call_examples,omitempty"`
+	QualifiedName    string                `json:"qualified_name"`
+	Name             string                `json:"name"`
+	Signature        string                `json:"signature"`
+	Kind             string                `json:"kind"` // function|method
+	ReceiverType     string                `json:"receiver_type,omitempty"`
+	ReceiverPtr      bool                  `json:"receiver_ptr,omitempty"`
+	Parameters       []CLDKParameter       `json:"parameters"`
+	Results          []CLDKParameter       `json:"results"`
+	Position         *CLDKPosition         `json:"position"`
+	EndPosition      *CLDKPosition         `json:"end_position,omitempty"`
+	Documentation    string                `json:"documentation,omitempty"`
+	Exported         bool                  `json:"exported"`
+	Implementation   string                `json:"implementation,omitempty"` // asm|linkname per le dichiarazioni senza corpo Go
+	TypeParameters   []CLDKTypeParam       `json:"type_parameters,omitempty"`
+	ReceiverMutation *CLDKReceiverMutation `json:"receiver_mutation,omitempty"` // solo metodi con corpo
+	IsWrapper        bool                  `json:"is_wrapper,omitempty"`        // corpo di una sola chiamata che inoltra tutti i parametri
+	Wraps            string                `json:"wraps,omitempty"`             // ID della funzione inoltrata dal wrapper
+	Classification   string                `json:"classification,omitempty"`    // getter|setter|stringer|constructor|boilerplate
+	Fingerprint      *CLDKFingerprint      `json:"fingerprint,omitempty"`       // con --fingerprints
+	Effects          []string              `json:"effects,omitempty"`           // reads_fs|writes_fs|network|exec|env, con --effects
+	Purity           *CLDKPurity           `json:"purity,omitempty"`            // con --purity
+	Examples         []CLDKExample         `json:"examples,omitempty"`          // con --examples
+	Summary          string                `json:"summary,omitempty"`           // riassunto generato, con --summarize
+	Body             *CLDKFunctionBody     `json:"body,omitempty"`
+	CallExamples     []string              `json:"call_examples,omitempty"`      // "called by Caller() [kind]", uno per chiamante
+	CallExampleSites []CLDKCallExample     `json:"call_example_sites,omitempty"` // le stesse chiamate con testo e posizione
+	GitMetadata      *CLDKGitMetadata      `json:"git_metadata,omitempty"`
+	Owners           []string              `json:"owners,omitempty"`  // con --owners
+	Tags             []string              `json:"tags,omitempty"`    // con --owners
+	Configs          []string              `json:"configs,omitempty"` // con --configs
+	Proto            *CLDKProtoRef         `json:"proto,omitempty"`
+}
+
+// CLDKCallExample è una chiamata reale a un callable del progetto, raccolta
+// dai corpi di tutti i package.
+type CLDKCallExample struct {
+	Caller     string        `json:"caller"`            // ID del callable chiamante
+	Kind       string        `json:"kind"`              // call|defer|go
+	Expression string        `json:"expression"`        // testo della chiamata, argomenti compresi
+	Snippet    string        `json:"snippet,omitempty"` // righe attorno alla chiamata, con --call-example-snippets
+	Test       bool          `json:"test,omitempty"`    // chiamata da un file _test.go
+	Position   *CLDKPosition `json:"position,omitempty"`
+}
+
+// CLDKExample è una funzione ExampleXxx di un file _test.go, associata al
+// simbolo che documenta secondo le convenzioni di go doc.
+type CLDKExample struct {
+	Name        string        `json:"name"`             // es. "ExampleClient_Run_retry"
+	Suffix      string        `json:"suffix,omitempty"` // "retry"
+	Doc         string        `json:"doc,omitempty"`
+	Code        string        `json:"code"`                   // corpo della funzione, commento Output compreso
+	Output      string        `json:"output,omitempty"`       // output atteso
+	EmptyOutput bool          `json:"empty_output,omitempty"` // "// Output:" senza testo
+	Unordered   bool          `json:"unordered,omitempty"`    // "// Unordered output:"
+	Position    *CLDKPosition `json:"position,omitempty"`
+}
+
+// CLDKReceiverMutation descrive come un metodo usa il proprio receiver.
+type CLDKReceiverMutation struct {
+	Mutates       bool     `json:"mutates"`                  // scrive il receiver o chiama metodi che lo modificano
+	WrittenFields []string `json:"written_fields,omitempty"` // campi scritti, "*" per il receiver intero
+	MutatingCalls []string `json:"mutating_calls,omitempty"` // metodi chiamati che lo modificano (es. "r.Reset", "r.mu.Lock")
+	LostWrites    bool     `json:"lost_writes,omitempty"`    // receiver per valore: le modifiche restano nella copia
+	Size          int64    `json:"size,omitempty"`           // byte copiati a ogni chiamata (solo receiver per valore)
+	LargeCopy     bool     `json:"large_copy,omitempty"`     // Size oltre la soglia di LARGE_VALUE_RECEIVER
+}
+
+// CLDKPurity descrive se una funzione è pura secondo l'analisi SSA.
+type CLDKPurity struct {
+	Pure             bool     `json:"pure"`                        // nessuna globale, scrittura esterna, I/O o non determinismo
+	ConstantFoldable bool     `json:"constant_foldable,omitempty"` // pura, con argomenti e risultati di tipo base e senza letture tramite puntatori
+	Reasons          []string `json:"reasons,omitempty"`           // perché non è pura (al più 5)
+}
+
+// CLDKFingerprint è l'impronta strutturale del corpo di un callable:
+// non dipende da nomi, valori dei letterali, commenti e formattazione, e
+// permette di cercare cloni tra repository senza condividere il sorgente.
+type CLDKFingerprint struct {
+	Hash   string   `json:"hash"`             // hash dell'intero corpo normalizzato (16 cifre esadecimali)
+	Tokens int      `json:"tokens"`           // nodi dell'AST del corpo
+	Winnow []string `json:"winnow,omitempty"` // hash dei k-gram scelti dal winnowing, in ordine di posizione
 }
 
 // CLDKParameter rappresenta un parametro o valore di ritorno.
@@ -169,12 +334,53 @@ type CLDKParameter struct {
 
 // CLDKFunctionBody contiene informazioni sul corpo della funzione.
 type CLDKFunctionBody struct {
-	StartLine   int            `json:"start_line"`
-	EndLine     int            `json:"end_line"`
-	LineCount   int            `json:"line_count"`
-	Complexity  int            `json:"complexity,omitempty"`
-	CallSites   []CLDKCallSite `json:"call_sites,omitempty"`
-	LocalVars   []string       `json:"local_vars,omitempty"`
+	StartLine  int            `json:"start_line"`
+	EndLine    int            `json:"end_line"`
+	LineCount  int            `json:"line_count"`
+	Complexity int            `json:"complexity,omitempty"`
+	CallSites  []CLDKCallSite `json:"call_sites,omitempty"`
+	LocalVars  []string       `json:"local_vars,omitempty"`
+
+	Statements *CLDKStatementCounts `json:"statements"`  // istogramma dei tipi di statement
+	MaxNesting int                  `json:"max_nesting"` // annidamento massimo di if/for/switch/select
+
+	// Defer, panic e recover (i panic dentro le closure non contano)
+	Defers       []CLDKDefer `json:"defers,omitempty"`
+	MayPanic     bool        `json:"may_panic,omitempty"`
+	PanicReasons []string    `json:"panic_reasons,omitempty"` // panic|index|slice|type_assertion
+
+	Comments []CLDKBodyComment `json:"comments,omitempty"` // con --include-comments
+}
+
+// CLDKBodyComment è un commento dentro un corpo di funzione, associato allo
+// statement più vicino.
+type CLDKBodyComment struct {
+	Text          string        `json:"text"`
+	Position      *CLDKPosition `json:"position"`                 // intervallo del commento
+	Placement     string        `json:"placement,omitempty"`      // leading|trailing|after|inside
+	Statement     string        `json:"statement,omitempty"`      // tipo dello statement (assign, call, if, ...)
+	StatementSpan *CLDKPosition `json:"statement_span,omitempty"` // intervallo dello statement
+}
+
+// CLDKDefer descrive uno statement defer.
+type CLDKDefer struct {
+	Target   string        `json:"target"` // funzione deferita ("func literal" per le closure)
+	Position *CLDKPosition `json:"position,omitempty"`
+	Calls    []string      `json:"calls,omitempty"`    // chiamate nel corpo della closure deferita
+	Recovers bool          `json:"recovers,omitempty"` // la closure chiama recover() direttamente
+}
+
+// CLDKStatementCounts conta gli statement di un corpo per tipo, comprese le
+// closure. For include i range.
+type CLDKStatementCounts struct {
+	If         int `json:"if"`
+	For        int `json:"for"`
+	Switch     int `json:"switch"`
+	TypeSwitch int `json:"type_switch"`
+	Select     int `json:"select"`
+	Return     int `json:"return"`
+	Go         int `json:"go"`
+	Defer      int `json:"defer"`
 }
 
 // CLDKCallSite rappresenta una chiamata a funzione nel corpo.
@@ -182,6 +388,26 @@ type CLDKCallSite struct {
 	Target   string        `json:"target"`
 	Position *CLDKPosition `json:"position"`
 	Kind     string        `json:"kind"` // call|defer|go
+
+	Arguments  []CLDKArgument `json:"arguments,omitempty"`
+	AssignedTo []string       `json:"assigned_to,omitempty"` // variabili che ricevono i risultati ("_" compreso)
+
+	// Chain lega le chiamate concatenate (a.B().C()): dal secondo passo in
+	// poi Target è il solo nome del metodo e Position punta al nome
+	Chain *CLDKCallChain `json:"chain,omitempty"`
+}
+
+// CLDKCallChain identifica il passo di una catena di chiamate.
+type CLDKCallChain struct {
+	ID     int `json:"id"`     // progressivo nel corpo, condiviso dai passi della catena
+	Step   int `json:"step"`   // 0 = prima chiamata, poi in ordine di valutazione
+	Length int `json:"length"` // numero di chiamate della catena
+}
+
+// CLDKArgument è un argomento di una chiamata.
+type CLDKArgument struct {
+	Expr  string `json:"expr"`            // testo sorgente (troncato oltre 200 caratteri)
+	Value string `json:"value,omitempty"` // valore costante, se risolvibile (es. "42", "\"GET\"")
 }
 
 // ============================================================================
@@ -196,6 +422,8 @@ type CLDKVariable struct {
 	Position      *CLDKPosition `json:"position"`
 	Exported      bool          `json:"exported"`
 	Documentation string        `json:"documentation,omitempty"`
+	Configs       []string      `json:"configs,omitempty"` // con --configs
+	Proto         *CLDKProtoRef `json:"proto,omitempty"`
 }
 
 // CLDKConstant rappresenta una costante package-level.
@@ -207,6 +435,8 @@ type CLDKConstant struct {
 	Position      *CLDKPosition `json:"position"`
 	Exported      bool          `json:"exported"`
 	Documentation string        `json:"documentation,omitempty"`
+	Configs       []string      `json:"configs,omitempty"` // con --configs
+	Proto         *CLDKProtoRef `json:"proto,omitempty"`
 }
 
 // ============================================================================
@@ -222,25 +452,49 @@ type CLDKPosition struct {
 	EndColumn   int    `json:"end_column,omitempty"`
 }
 
+// ============================================================================
+// Git Metadata
+// ============================================================================
+
+// CLDKGitMetadata contiene l'ultima modifica di un simbolo secondo git blame.
+type CLDKGitMetadata struct {
+	Commit      string `json:"commit"`                 // SHA dell'ultimo commit che ha toccato il simbolo
+	Author      string `json:"author"`                 // autore del commit
+	AuthorEmail string `json:"author_email,omitempty"` // email dell'autore
+	Timestamp   string `json:"timestamp"`              // author time in RFC3339
+	AgeDays     int    `json:"age_days"`               // giorni trascorsi dall'ultima modifica
+}
+
 // ============================================================================
 // Call Graph
 // ============================================================================
 
 // CLDKCallGraph rappresenta il call graph.
 type CLDKCallGraph struct {
-	Algorithm string       `json:"algorithm"`
-	Nodes     []CLDKCGNode `json:"nodes"`
-	Edges     []CLDKCGEdge `json:"edges"`
+	Algorithm string `json:"algorithm"`
+	// Granularity è "pkg" se i nodi sono package (--cg-granularity pkg),
+	// vuota per il grafo tra funzioni
+	Granularity string `json:"granularity,omitempty"`
+	// CollapsedWrappers è il numero di wrapper tolti dal grafo
+	// (--cg-collapse-wrappers)
+	CollapsedWrappers int          `json:"collapsed_wrappers,omitempty"`
+	Nodes             []CLDKCGNode `json:"nodes"`
+	Edges             []CLDKCGEdge `json:"edges"`
 }
 
 // CLDKCGNode rappresenta un nodo del call graph.
 type CLDKCGNode struct {
-	ID            string        `json:"id"`
-	QualifiedName string        `json:"qualified_name"`
-	Package       string        `json:"package"`
-	Name          string        `json:"name"`
-	Kind          string        `json:"kind"` // function|method
-	Position      *CLDKPosition `json:"position,omitempty"`
+	ID              string        `json:"id"`
+	QualifiedName   string        `json:"qualified_name"`
+	Package         string        `json:"package"`
+	Name            string        `json:"name"`
+	Kind            string        `json:"kind"`                 // function|method|package
+	SymbolRef       string        `json:"symbol_ref,omitempty"` // chiave in callable_declarations della dichiarazione sorgente
+	Position        *CLDKPosition `json:"position,omitempty"`
+	FanIn           int           `json:"fan_in"`                     // numero di caller distinti
+	FanOut          int           `json:"fan_out"`                    // numero di callee distinti
+	TransitiveReach int           `json:"transitive_reach,omitempty"` // nodi raggiungibili (con --cg-reach)
+	Effects         []string      `json:"effects,omitempty"`          // effetti raggiunti (con --effects)
 }
 
 // CLDKCGEdge rappresenta un arco del call graph.
@@ -250,6 +504,17 @@ type CLDKCGEdge struct {
 	CallSite *CLDKPosition `json:"call_site,omitempty"`
 	Kind     string        `json:"kind,omitempty"`     // call|defer|go
 	Category string        `json:"category,omitempty"` // execution|network|filesystem|crypto|process|reflection|unsafe|plugin|cgo
+	// Count è il numero di call site distinti della coppia source→target
+	// (con granularità pkg: la somma sugli archi tra funzioni aggregati)
+	Count int `json:"count,omitempty"`
+	// CallSites elenca i call site ordinati per posizione quando sono più
+	// di uno, fino a --cg-max-call-sites; CallSite resta il primo trovato
+	CallSites []CLDKPosition `json:"call_sites,omitempty"`
+
+	// DeclaredTarget è il metodo d'interfaccia invocato staticamente (es.
+	// "pkg.Greeter.Greet") per gli archi da dispatch dinamico; Target è la
+	// sua implementazione concreta. Vuoto per le chiamate dirette.
+	DeclaredTarget string `json:"declared_target,omitempty"`
 }
 
 // ============================================================================
@@ -285,4 +550,3 @@ type ObfuscationMetrics struct {
 	XorOperations      int     `json:"xor_operations"`                 // conteggio operazioni XOR nel package
 	HasGarblePatterns  bool    `json:"has_garble_patterns,omitempty"`  // nomi funzione con pattern tipici di Garble
 }
-
@@ -0,0 +1,38 @@
+package schema
+
+// ============================================================================
+// Clock Schema
+// ============================================================================
+// Chiamate che leggono l'orologio, attendono o generano numeri casuali: il
+// codice che le usa direttamente non è deterministico nei test senza
+// iniettare un clock o una sorgente di casualità.
+
+// CLDKClockUsage raccoglie le chiamate a time e rand del progetto per package.
+type CLDKClockUsage struct {
+	Packages []CLDKClockPackage `json:"packages"` // ordinati per package
+	Totals   CLDKClockCounts    `json:"totals"`
+}
+
+// CLDKClockPackage sono le chiamate di un package.
+type CLDKClockPackage struct {
+	Package string          `json:"package"`
+	Counts  CLDKClockCounts `json:"counts"`
+	Sites   []CLDKClockSite `json:"sites"` // ordinati per posizione
+}
+
+// CLDKClockCounts conta i call site per tipo.
+type CLDKClockCounts struct {
+	Now        int `json:"now"`         // time.Now, time.Since, time.Until
+	Sleep      int `json:"sleep"`       // time.Sleep
+	Timers     int `json:"timers"`      // time.After, AfterFunc, NewTimer, NewTicker, Tick
+	MathRand   int `json:"math_rand"`   // math/rand e math/rand/v2
+	CryptoRand int `json:"crypto_rand"` // crypto/rand
+}
+
+// CLDKClockSite è una chiamata a time o rand.
+type CLDKClockSite struct {
+	Kind     string        `json:"kind"`               // now|sleep|timer|math_rand|crypto_rand
+	Call     string        `json:"call"`               // es. "time.Now", "(*math/rand.Rand).Intn"
+	Function string        `json:"function,omitempty"` // funzione che la contiene, vuota negli inizializzatori
+	Position *CLDKPosition `json:"position,omitempty"`
+}
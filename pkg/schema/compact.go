@@ -54,6 +54,8 @@ type CompactPkg struct {
 	BT     []string `json:"bt,omitempty"`   // build tags/constraints
 	UsedBy []string `json:"ub,omitempty"`   // reverse imports: who imports this package
 	Main   bool     `json:"main,omitempty"` // reachable from main()/init() flow
+	Sum    string   `json:"sum,omitempty"`  // generated summary (--summarize)
+	Skip   int      `json:"sk,omitempty"`   // boilerplate callables left out (--compact-skip-boilerplate)
 
 	// Extended security analysis
 	SL  []CompactStringLit     `json:"sl,omitempty"`  // string literals (classified)
@@ -81,11 +83,14 @@ type CompactType struct {
 
 // CompactFunc rappresenta una funzione o metodo in formato compatto.
 type CompactFunc struct {
-	Sig  string   `json:"s"`            // signature completa
-	Kind string   `json:"k,omitempty"`  // "m" per method, omesso per function
-	Recv string   `json:"r,omitempty"`  // receiver type (solo per method)
-	Doc  string   `json:"d,omitempty"`  // documentation (solo export)
-	Ex   []string `json:"ex,omitempty"` // call examples
+	Sig  string   `json:"s"`             // signature completa
+	Kind string   `json:"k,omitempty"`   // "m" per method, omesso per function
+	Recv string   `json:"r,omitempty"`   // receiver type (solo per method)
+	Doc  string   `json:"d,omitempty"`   // documentation (solo export)
+	Ex   []string `json:"ex,omitempty"`  // call examples
+	Sum  string   `json:"sum,omitempty"` // generated summary (--summarize)
+	W    string   `json:"w,omitempty"`   // wrapped callable (thin wrappers)
+	Cls  string   `json:"cl,omitempty"`  // getter|setter|stringer|constructor|boilerplate
 }
 
 // ============================================================================
@@ -94,8 +99,10 @@ type CompactFunc struct {
 
 // CompactCallGraph rappresenta il call graph in formato compatto.
 type CompactCallGraph struct {
-	Algo  string      `json:"a"` // algorithm (cha|rta)
-	Edges [][2]string `json:"e"` // [[source, target], ...]
+	Algo  string            `json:"a"`           // algorithm (cha|rta)
+	Edges [][2]string       `json:"e"`           // [[source, target], ...]
+	Deg   map[string][2]int `json:"deg,omitempty"` // node → [fan_in, fan_out]
+	Reach map[string]int    `json:"r,omitempty"`   // node → transitive reach (con --cg-reach)
 }
 
 // ============================================================================
@@ -0,0 +1,35 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+// ============================================================================
+// Component Schema
+// ============================================================================
+// Vista opzionale (--components) dei package raggruppati in componenti
+// (servizi di un monorepo, moduli di un go.work) con le dipendenze tra
+// componenti. Pensata per architecture review su progetti con migliaia di
+// package.
+
+// CLDKComponentReport raccoglie componenti e dipendenze tra componenti.
+type CLDKComponentReport struct {
+	Components   []CLDKComponent           `json:"components"`           // ordinati per nome
+	Dependencies []CLDKComponentDependency `json:"dependencies"`         // ordinate per from, to
+	Unassigned   []string                  `json:"unassigned,omitempty"` // package fuori da ogni componente
+}
+
+// CLDKComponent è un gruppo di package identificato da prefissi di directory.
+type CLDKComponent struct {
+	Name      string   `json:"name"`
+	Prefixes  []string `json:"prefixes"` // directory relative alla root
+	Packages  []string `json:"packages"`
+	Callables int      `json:"callables"`  // nodi del call graph nel componente (0 senza call graph)
+	DependsOn int      `json:"depends_on"` // componenti da cui dipende (import o chiamate)
+	UsedBy    int      `json:"used_by"`    // componenti che dipendono da questo
+}
+
+// CLDKComponentDependency aggrega gli archi tra due componenti.
+type CLDKComponentDependency struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Imports int    `json:"imports"` // coppie di package (importatore, importato)
+	Calls   int    `json:"calls"`   // archi del call graph
+}
@@ -3,12 +3,26 @@ package schema
 
 import (
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
+// CompactOptions regola la conversione in formato compatto.
+type CompactOptions struct {
+	// SkipBoilerplate omette i callable classificati come getter, setter,
+	// stringer o boilerplate (i costruttori restano)
+	SkipBoilerplate bool
+}
+
 // ToCompact converte CLDKAnalysis in CompactAnalysis per output LLM.
 func ToCompact(full *CLDKAnalysis) *CompactAnalysis {
+	return ToCompactWith(full, CompactOptions{})
+}
+
+// ToCompactWith converte CLDKAnalysis in CompactAnalysis con le opzioni opts.
+func ToCompactWith(full *CLDKAnalysis, opts CompactOptions) *CompactAnalysis {
 	compact := &CompactAnalysis{
 		Meta: &CompactMeta{
 			Ver:  full.Metadata.Version,
@@ -25,7 +39,7 @@ func ToCompact(full *CLDKAnalysis) *CompactAnalysis {
 	if full.SymbolTable != nil && len(full.SymbolTable.Packages) > 0 {
 		compact.Pkgs = make(map[string]*CompactPkg)
 		for pkgPath, pkg := range full.SymbolTable.Packages {
-			compact.Pkgs[pkgPath] = convertPackage(pkg)
+			compact.Pkgs[pkgPath] = convertPackage(pkg, opts)
 		}
 	}
 
@@ -67,7 +81,7 @@ func convertIssues(issues []Issue) []CompactIssue {
 }
 
 // convertPackage converte un CLDKPackage in CompactPkg.
-func convertPackage(pkg *CLDKPackage) *CompactPkg {
+func convertPackage(pkg *CLDKPackage, opts CompactOptions) *CompactPkg {
 	cp := &CompactPkg{
 		Name: pkg.Name,
 	}
@@ -76,6 +90,7 @@ func convertPackage(pkg *CLDKPackage) *CompactPkg {
 	if pkg.Documentation != "" {
 		cp.Doc = truncateDoc(pkg.Documentation)
 	}
+	cp.Sum = pkg.Summary
 
 	// Files - estrai solo il basename
 	if len(pkg.Files) > 0 {
@@ -112,7 +127,11 @@ func convertPackage(pkg *CLDKPackage) *CompactPkg {
 			// Methods - solo signature
 			if len(td.Methods) > 0 {
 				ct.Methods = make([]string, 0, len(td.Methods))
-				for _, m := range td.Methods {
+				for _, name := range slices.Sorted(maps.Keys(td.Methods)) {
+					m := td.Methods[name]
+					if opts.SkipBoilerplate && isBoilerplate(m.Classification) {
+						continue
+					}
 					ct.Methods = append(ct.Methods, m.Signature)
 				}
 			}
@@ -142,7 +161,14 @@ func convertPackage(pkg *CLDKPackage) *CompactPkg {
 	// Callable declarations (functions/methods)
 	if len(pkg.CallableDeclarations) > 0 {
 		cp.Funcs = make(map[string]*CompactFunc)
-		for _, cd := range pkg.CallableDeclarations {
+		// in ordine di ID: a parità di nome (metodi di tipi diversi) resta
+		// sempre lo stesso callable
+		for _, id := range slices.Sorted(maps.Keys(pkg.CallableDeclarations)) {
+			cd := pkg.CallableDeclarations[id]
+			if opts.SkipBoilerplate && isBoilerplate(cd.Classification) {
+				cp.Skip++
+				continue
+			}
 			cf := &CompactFunc{
 				Sig: cd.Signature,
 			}
@@ -157,10 +183,17 @@ func convertPackage(pkg *CLDKPackage) *CompactPkg {
 			if cd.Exported && cd.Documentation != "" {
 				cf.Doc = truncateDoc(cd.Documentation)
 			}
-
-			// Call examples
-			if len(cd.CallExamples) > 0 {
-				cf.Ex = cd.CallExamples
+			cf.Sum = cd.Summary
+			cf.W = cd.Wraps
+			cf.Cls = cd.Classification
+
+			// Call examples: lo snippet se presente, altrimenti la chiamata
+			for _, ex := range cd.CallExampleSites {
+				if ex.Snippet != "" {
+					cf.Ex = append(cf.Ex, ex.Snippet)
+				} else {
+					cf.Ex = append(cf.Ex, ex.Expression)
+				}
 			}
 
 			cp.Funcs[cd.Name] = cf
@@ -259,6 +292,20 @@ func convertCallGraph(cg *CLDKCallGraph) *CompactCallGraph {
 		ccg.Edges = append(ccg.Edges, [2]string{edge.Source, edge.Target})
 	}
 
+	// Fan-in/fan-out per nodo
+	if len(cg.Nodes) > 0 {
+		ccg.Deg = make(map[string][2]int, len(cg.Nodes))
+		for _, n := range cg.Nodes {
+			ccg.Deg[n.ID] = [2]int{n.FanIn, n.FanOut}
+			if n.TransitiveReach > 0 {
+				if ccg.Reach == nil {
+					ccg.Reach = make(map[string]int)
+				}
+				ccg.Reach[n.ID] = n.TransitiveReach
+			}
+		}
+	}
+
 	return ccg
 }
 
@@ -360,6 +407,16 @@ func convertSDG(s *CLDKSDG) *CompactSDG {
 	return cs
 }
 
+// isBoilerplate riporta se la classificazione di un callable lo esclude
+// con CompactOptions.SkipBoilerplate.
+func isBoilerplate(class string) bool {
+	switch class {
+	case "getter", "setter", "stringer", "boilerplate":
+		return true
+	}
+	return false
+}
+
 // isExported verifica se un nome è esportato (inizia con maiuscola).
 func isExported(name string) bool {
 	if name == "" {
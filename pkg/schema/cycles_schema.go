@@ -0,0 +1,21 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+// ============================================================================
+// Cycle Report Schema
+// ============================================================================
+// Report opzionale (--report-cycles) delle componenti fortemente connesse del
+// call graph (ricorsione) e del grafo degli import del progetto.
+
+// CLDKCycleReport raccoglie i cicli rilevati.
+type CLDKCycleReport struct {
+	RecursionGroups []CLDKCycle `json:"recursion_groups"` // funzioni (mutuamente) ricorsive
+	ImportCycles    []CLDKCycle `json:"import_cycles"`    // cicli nel grafo degli import
+}
+
+// CLDKCycle rappresenta una componente fortemente connessa che forma un ciclo.
+type CLDKCycle struct {
+	Members []string    `json:"members"` // node ID o package path, ordinati
+	Size    int         `json:"size"`
+	Edges   [][2]string `json:"edges"` // archi interni al ciclo [source, target]
+}
@@ -0,0 +1,75 @@
+package schema
+
+// ============================================================================
+// Data Access Schema
+// ============================================================================
+// File .sql del progetto (migrazioni, schemi, query con nome di sqlc),
+// configurazioni di sqlc e simboli Go che contengono o eseguono SQL, con il
+// collegamento tra i due lati (vedi --data-access). Le tabelle sono
+// ricavate con un'analisi lessicale delle istruzioni, senza un parser SQL.
+
+// CLDKDataAccess è l'inventario dell'accesso ai dati del progetto.
+type CLDKDataAccess struct {
+	SqlcConfigs []CLDKSqlcConfig `json:"sqlc_configs,omitempty"`
+	SQLFiles    []CLDKSQLFile    `json:"sql_files"`  // ordinati per path
+	GoQueries   []CLDKGoQuery    `json:"go_queries"` // ordinate per simbolo
+	Tables      []string         `json:"tables"`     // tabelle create o usate, ordinate
+}
+
+// CLDKSqlcConfig è un file sqlc.yaml, sqlc.yml o sqlc.json.
+type CLDKSqlcConfig struct {
+	File     string            `json:"file"`
+	Version  string            `json:"version,omitempty"`
+	Packages []CLDKSqlcPackage `json:"packages"` // voci di "sql" (v2) o "packages" (v1)
+}
+
+// CLDKSqlcPackage è un insieme di query compilato da sqlc in un package Go.
+// I path sono relativi alla root.
+type CLDKSqlcPackage struct {
+	Engine  string   `json:"engine,omitempty"`
+	Schema  []string `json:"schema,omitempty"`
+	Queries []string `json:"queries,omitempty"`
+	Out     string   `json:"out,omitempty"`     // directory del codice generato
+	Package string   `json:"package,omitempty"` // nome del package generato
+}
+
+// CLDKSQLFile è un file .sql.
+type CLDKSQLFile struct {
+	File       string              `json:"file"`
+	Kind       string              `json:"kind"` // migration|schema|queries|script
+	Migration  *CLDKSQLMigration   `json:"migration,omitempty"`
+	Statements int                 `json:"statements"`
+	Creates    []string            `json:"creates,omitempty"`     // tabelle create, modificate o eliminate (DDL)
+	Uses       []string            `json:"uses,omitempty"`        // tabelle lette o scritte (DML)
+	Queries    []CLDKSQLNamedQuery `json:"queries,omitempty"`     // query con "-- name:"
+	EmbeddedBy []string            `json:"embedded_by,omitempty"` // variabili con //go:embed del file
+}
+
+// CLDKSQLMigration descrive un file di migrazione.
+type CLDKSQLMigration struct {
+	Tool      string `json:"tool,omitempty"`      // goose|golang-migrate|dbmate|sql-migrate|flyway
+	Version   string `json:"version,omitempty"`   // prefisso numerico del nome del file
+	Direction string `json:"direction,omitempty"` // up|down|both
+}
+
+// CLDKSQLNamedQuery è una query annotata con "-- name: Nome :comando".
+type CLDKSQLNamedQuery struct {
+	Name      string   `json:"name"`
+	Command   string   `json:"command,omitempty"` // one|many|exec|execrows|execresult|...
+	Statement string   `json:"statement"`         // select|insert|update|delete|...
+	Tables    []string `json:"tables,omitempty"`
+	Line      int      `json:"line"`
+	Go        []string `json:"go,omitempty"` // simboli Go collegati (costante e metodo generati da sqlc)
+}
+
+// CLDKGoQuery è un simbolo Go che contiene o esegue SQL.
+type CLDKGoQuery struct {
+	Symbol    string        `json:"symbol"`              // ID della costante, variabile o funzione
+	Kind      string        `json:"kind"`                // constant|variable|function|embed
+	Statement string        `json:"statement,omitempty"` // select|insert|...
+	Tables    []string      `json:"tables,omitempty"`
+	Generator string        `json:"generator,omitempty"` // "sqlc" per il codice generato
+	Source    string        `json:"source,omitempty"`    // file .sql collegato
+	Query     string        `json:"query,omitempty"`     // query con nome collegata
+	Position  *CLDKPosition `json:"position,omitempty"`
+}
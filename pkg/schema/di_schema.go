@@ -0,0 +1,41 @@
+package schema
+
+// ============================================================================
+// Dependency Injection Schema
+// ============================================================================
+// Grafo dei provider registrati con google/wire, uber fx e dig: i framework
+// chiamano costruttori e funzioni via reflection o codice generato, quindi
+// queste dipendenze non compaiono nel call graph.
+
+// CLDKDIGraph è il grafo dei provider del progetto.
+type CLDKDIGraph struct {
+	Frameworks  []string         `json:"frameworks"` // wire|fx|dig usati dal progetto
+	Providers   []CLDKDIProvider `json:"providers"`  // ordinati per posizione di registrazione
+	Edges       []CLDKDIEdge     `json:"edges"`
+	Unsatisfied []CLDKDIMissing  `json:"unsatisfied,omitempty"` // richieste senza provider nel progetto
+}
+
+// CLDKDIProvider è una registrazione: un costruttore, un valore, un binding
+// o una funzione invocata dal container.
+type CLDKDIProvider struct {
+	Function  string        `json:"function"`           // ID della funzione registrata, o descrizione per valori e literal
+	Framework string        `json:"framework"`          // wire|fx|dig
+	Kind      string        `json:"kind"`               // provide|invoke|value|bind|struct
+	Set       string        `json:"set,omitempty"`      // variabile del wire.NewSet o funzione che registra
+	Provides  []string      `json:"provides,omitempty"` // tipi forniti (errori esclusi, fx.Out/dig.Out espansi)
+	Requires  []string      `json:"requires,omitempty"` // tipi richiesti (fx.In/dig.In espansi)
+	Position  *CLDKPosition `json:"position,omitempty"` // chiamata di registrazione
+}
+
+// CLDKDIEdge collega il provider di un tipo a chi lo richiede.
+type CLDKDIEdge struct {
+	From string `json:"from"` // Function del provider
+	To   string `json:"to"`   // Function del consumatore
+	Type string `json:"type"`
+}
+
+// CLDKDIMissing è un tipo richiesto che nessun provider del progetto fornisce.
+type CLDKDIMissing struct {
+	Consumer string `json:"consumer"`
+	Type     string `json:"type"`
+}
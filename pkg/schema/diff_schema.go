@@ -0,0 +1,21 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+// ============================================================================
+// Diff Schema
+// ============================================================================
+// Risultato del sottocomando "diff" tra due analisi dello stesso progetto.
+
+// CLDKDiff elenca le differenze tra un'analisi "old" e una "new".
+type CLDKDiff struct {
+	Packages  CLDKDiffSet `json:"packages"`   // package path
+	Types     CLDKDiffSet `json:"types"`      // qualified name dei tipi
+	Callables CLDKDiffSet `json:"callables"`  // qualified name di funzioni e metodi
+	CallEdges CLDKDiffSet `json:"call_edges"` // archi del call graph "source -> target"
+}
+
+// CLDKDiffSet contiene gli elementi aggiunti e rimossi, ordinati.
+type CLDKDiffSet struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
@@ -0,0 +1,74 @@
+package schema
+
+// ============================================================================
+// Error Taxonomy Schema
+// ============================================================================
+// Tipi che implementano error, variabili sentinella e target di errors.Is
+// ed errors.As, raggruppati per modulo (vedi --error-taxonomy).
+
+// CLDKErrorTaxonomy è la mappa degli errori del progetto.
+type CLDKErrorTaxonomy struct {
+	Modules []CLDKErrorModule `json:"modules"` // ordinati per module path
+	Totals  CLDKErrorCounts   `json:"totals"`
+}
+
+// CLDKErrorModule raccoglie gli errori dichiarati e controllati in un modulo.
+type CLDKErrorModule struct {
+	Module    string            `json:"module"`        // module path, vuoto fuori da un modulo
+	Dir       string            `json:"dir,omitempty"` // directory del go.mod relativa alla root
+	Counts    CLDKErrorCounts   `json:"counts"`
+	Types     []CLDKErrorType   `json:"types"`     // ordinati per ID
+	Sentinels []CLDKSentinel    `json:"sentinels"` // ordinate per ID
+	Targets   []CLDKErrorTarget `json:"targets"`   // ordinati per kind e target
+}
+
+// CLDKErrorCounts conta le voci della tassonomia.
+type CLDKErrorCounts struct {
+	Types     int `json:"types"`
+	Sentinels int `json:"sentinels"`
+	IsChecks  int `json:"is_checks"` // chiamate a errors.Is
+	AsChecks  int `json:"as_checks"` // chiamate a errors.As
+}
+
+// CLDKErrorType è un tipo che implementa error o un'interfaccia che lo
+// incorpora.
+type CLDKErrorType struct {
+	Type      string        `json:"type"`                // ID del tipo
+	Kind      string        `json:"kind"`                // struct|interface|other
+	Receiver  string        `json:"receiver,omitempty"`  // value|pointer: quale tra T e *T implementa error
+	Unwrap    string        `json:"unwrap,omitempty"`    // single (Unwrap() error)|multi (Unwrap() []error)
+	HasIs     bool          `json:"has_is,omitempty"`    // metodo Is(error) bool
+	HasAs     bool          `json:"has_as,omitempty"`    // metodo As(any) bool
+	Sentinels []string      `json:"sentinels,omitempty"` // sentinelle di questo tipo
+	Checks    int           `json:"checks"`              // errors.As con questo tipo come target, in tutto il progetto
+	Position  *CLDKPosition `json:"position,omitempty"`
+}
+
+// CLDKSentinel è una variabile package-level di tipo error inizializzata con
+// un valore, es. var ErrNotFound = errors.New("not found").
+type CLDKSentinel struct {
+	Variable    string        `json:"variable"`              // ID della variabile
+	Constructor string        `json:"constructor,omitempty"` // es. "errors.New", "fmt.Errorf", o il tipo del letterale
+	Message     string        `json:"message,omitempty"`     // testo costante passato al costruttore
+	Type        string        `json:"type,omitempty"`        // ID del tipo concreto del valore, es. per &MyErr{} o MyErr("x")
+	Alias       string        `json:"alias,omitempty"`       // variabile riassegnata, es. "io.EOF"
+	Wraps       []string      `json:"wraps,omitempty"`       // sentinelle incluse con %w
+	Checks      int           `json:"checks"`                // errors.Is con questa sentinella come target, in tutto il progetto
+	Position    *CLDKPosition `json:"position,omitempty"`
+}
+
+// CLDKErrorTarget è un target di errors.Is (una sentinella) o errors.As (un
+// tipo) con le chiamate del modulo che lo usano.
+type CLDKErrorTarget struct {
+	Kind     string          `json:"kind"`               // is|as
+	Target   string          `json:"target"`             // ID della variabile o del tipo, o l'espressione se non ha nome
+	External bool            `json:"external,omitempty"` // dichiarato fuori dal progetto (es. io.EOF)
+	Sites    []CLDKErrorSite `json:"sites"`              // ordinati per posizione
+}
+
+// CLDKErrorSite è una chiamata a errors.Is o errors.As.
+type CLDKErrorSite struct {
+	Call     string        `json:"call"`               // es. "errors.Is", "github.com/pkg/errors.As"
+	Function string        `json:"function,omitempty"` // funzione che la contiene, vuota negli inizializzatori
+	Position *CLDKPosition `json:"position,omitempty"`
+}
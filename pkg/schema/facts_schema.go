@@ -0,0 +1,58 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+// ============================================================================
+// Facts Schema (Kythe entries)
+// ============================================================================
+// Fatti e archi sul modello del grafo di Kythe, scritti con --format facts
+// come stream NDJSON di KytheEntry: lo stesso formato JSON di entrystream
+// (--read_format=json), così l'output entra nelle pipeline di code
+// intelligence che leggono già le entries di altri linguaggi.
+
+// Linguaggio dei VName dei nodi semantici e delle ancore.
+const KytheLanguage = "go"
+
+// Nomi dei fatti.
+const (
+	KytheFactNodeKind     = "/kythe/node/kind"
+	KytheFactSubkind      = "/kythe/subkind"
+	KytheFactComplete     = "/kythe/complete"
+	KytheFactText         = "/kythe/text"
+	KytheFactTextEncoding = "/kythe/text/encoding"
+	KytheFactLocStart     = "/kythe/loc/start"
+	KytheFactLocEnd       = "/kythe/loc/end"
+	KytheFactEdge         = "/" // fact_name degli archi
+)
+
+// Tipi degli archi.
+const (
+	KytheEdgeChildOf        = "/kythe/edge/childof"
+	KytheEdgeDefines        = "/kythe/edge/defines"
+	KytheEdgeDefinesBinding = "/kythe/edge/defines/binding"
+	KytheEdgeRefCall        = "/kythe/edge/ref/call"
+	KytheEdgeRefImports     = "/kythe/edge/ref/imports"
+	KytheEdgeSatisfies      = "/kythe/edge/satisfies"
+	KytheEdgeOverrides      = "/kythe/edge/overrides"
+)
+
+// KytheVName identifica un nodo. I nodi semantici hanno per Signature l'ID
+// CLDK (import path, nome qualificato o ID del callable), i file il solo
+// Path e le ancore la Signature "@inizio:fine" (offset in byte) nel Path
+// del file.
+type KytheVName struct {
+	Signature string `json:"signature,omitempty"`
+	Corpus    string `json:"corpus,omitempty"`
+	Root      string `json:"root,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Language  string `json:"language,omitempty"`
+}
+
+// KytheEntry è un fatto di Source (senza EdgeKind e Target) o un arco da
+// Source a Target, con FactName "/".
+type KytheEntry struct {
+	Source    KytheVName  `json:"source"`
+	EdgeKind  string      `json:"edge_kind,omitempty"`
+	Target    *KytheVName `json:"target,omitempty"`
+	FactName  string      `json:"fact_name"`
+	FactValue []byte      `json:"fact_value,omitempty"` // base64 in JSON
+}
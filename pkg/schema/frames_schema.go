@@ -0,0 +1,146 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// ============================================================================
+// Frames Schema
+// ============================================================================
+// Protocollo a frame NDJSON per client in subprocess (CLDK Python): ogni
+// riga è un CLDKFrame. Lo stesso formato è scritto da --format
+// ndjson-frames e dal sottocomando "bridge", che risponde a richieste lette
+// da stdin. Le modifiche incompatibili del framing incrementano
+// FramesVersion; quelle dei payload seguono SchemaVersion.
+
+// Identificativo e versione del protocollo, annunciati dal frame hello.
+const (
+	FramesProtocol = "cldk-frames"
+	FramesVersion  = 1
+)
+
+// Tipi di frame.
+const (
+	FrameHello     = "hello"     // primo frame dello stream: versioni e metodi
+	FrameMetadata  = "metadata"  // data: Metadata
+	FramePackage   = "package"   // name: import path, data: CLDKPackage
+	FrameCallGraph = "callgraph" // data: CLDKFrameCallGraph, prima di node ed edge
+	FrameNode      = "node"      // data: CLDKCGNode
+	FrameEdge      = "edge"      // data: CLDKCGEdge
+	FrameSection   = "section"   // name: campo di CLDKAnalysis (es. "pdg"), data: il suo valore
+	FrameIssue     = "issue"     // data: Issue
+	FrameResult    = "result"    // data: risultato di un metodo del bridge
+	FrameError     = "error"     // data: CLDKFrameError, chiude la risposta
+	FrameEnd       = "end"       // data: CLDKFrameEnd, chiude lo stream o la risposta
+)
+
+// CLDKFrame è una riga dello stream.
+type CLDKFrame struct {
+	Frame string          `json:"frame"`
+	ID    json.RawMessage `json:"id,omitempty"`   // id della richiesta (solo bridge)
+	Name  string          `json:"name,omitempty"` // per package e section
+	Data  interface{}     `json:"data,omitempty"`
+}
+
+// CLDKFrameHello apre lo stream.
+type CLDKFrameHello struct {
+	Protocol        string   `json:"protocol"`         // FramesProtocol
+	ProtocolVersion int      `json:"protocol_version"` // FramesVersion
+	SchemaVersion   string   `json:"schema_version"`   // versione dei payload
+	Analyzer        string   `json:"analyzer"`
+	Version         string   `json:"version"`
+	Methods         []string `json:"methods,omitempty"` // metodi accettati (solo bridge)
+}
+
+// CLDKFrameCallGraph descrive il call graph prima dei suoi nodi e archi.
+type CLDKFrameCallGraph struct {
+	Algorithm   string `json:"algorithm"`
+	Granularity string `json:"granularity,omitempty"`
+	Nodes       int    `json:"nodes"`
+	Edges       int    `json:"edges"`
+}
+
+// CLDKFrameEnd chiude uno stream o una risposta con il numero di frame
+// emessi per tipo, per verificarne la completezza.
+type CLDKFrameEnd struct {
+	Counts map[string]int `json:"counts"`
+}
+
+// CLDKFrameError chiude una risposta fallita.
+type CLDKFrameError struct {
+	Code    string `json:"code"` // bad_request|unknown_method|invalid_params|not_found|load_failed|internal
+	Message string `json:"message"`
+}
+
+// CLDKFrameRequest è una richiesta al bridge, una per riga su stdin.
+type CLDKFrameRequest struct {
+	ID     json.RawMessage `json:"id"` // numero o stringa, ripetuto nei frame di risposta
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// FramesJSONSchema genera il JSON Schema del protocollo a frame: un oneOf
+// con un ramo per tipo di frame, ognuno con il payload tipizzato, più la
+// richiesta del bridge in $defs.CLDKFrameRequest.
+func FramesJSONSchema() map[string]interface{} {
+	g := &schemaGen{defs: make(map[string]interface{})}
+	id := map[string]interface{}{"type": []string{"integer", "string", "null"}}
+	any := map[string]interface{}{}
+	branch := func(kind string, data interface{}, named bool) map[string]interface{} {
+		props := map[string]interface{}{
+			"frame": map[string]interface{}{"const": kind},
+			"id":    id,
+			"data":  data,
+		}
+		required := []string{"frame"}
+		if named {
+			props["name"] = map[string]interface{}{"type": "string"}
+			required = append(required, "name")
+		}
+		if kind != FrameResult {
+			required = append(required, "data")
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	}
+	ref := func(v interface{}) map[string]interface{} { return g.ref(reflect.TypeOf(v)) }
+
+	g.defs["CLDKFrameRequest"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":     id,
+			"method": map[string]interface{}{"type": "string"},
+			"params": map[string]interface{}{"type": "object"},
+		},
+		"required":             []string{"id", "method"},
+		"additionalProperties": false,
+	}
+	return map[string]interface{}{
+		"$schema":            "https://json-schema.org/draft/2020-12/schema",
+		"$id":                "https://github.com/codellm-devkit/codeanalyzer-go/frames/" + SchemaVersion,
+		"title":              "codeanalyzer-go CLDK frame",
+		"x-schema-version":   SchemaVersion,
+		"x-protocol":         FramesProtocol,
+		"x-protocol-version": FramesVersion,
+		"oneOf": []interface{}{
+			branch(FrameHello, ref(CLDKFrameHello{}), false),
+			branch(FrameMetadata, ref(Metadata{}), false),
+			branch(FramePackage, ref(CLDKPackage{}), true),
+			branch(FrameCallGraph, ref(CLDKFrameCallGraph{}), false),
+			branch(FrameNode, ref(CLDKCGNode{}), false),
+			branch(FrameEdge, ref(CLDKCGEdge{}), false),
+			branch(FrameSection, any, true),
+			branch(FrameIssue, ref(Issue{}), false),
+			branch(FrameResult, any, false),
+			branch(FrameError, ref(CLDKFrameError{}), false),
+			branch(FrameEnd, ref(CLDKFrameEnd{}), false),
+		},
+		"$defs": g.defs,
+	}
+}
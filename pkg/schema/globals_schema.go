@@ -0,0 +1,44 @@
+package schema
+
+// ============================================================================
+// Globals Schema
+// ============================================================================
+// Grafo degli accessi alle variabili package-level (vedi --globals): per
+// ogni variabile del progetto, le funzioni che la leggono, la scrivono o ne
+// prendono l'indirizzo. Gli audit di concorrenza e di testabilità partono
+// da "chi tocca questa globale".
+
+// CLDKGlobals raccoglie gli accessi alle variabili globali del progetto.
+type CLDKGlobals struct {
+	Variables []CLDKGlobal     `json:"variables"` // ordinate per ID
+	Totals    CLDKGlobalCounts `json:"totals"`
+}
+
+// CLDKGlobal è una variabile package-level con i suoi accessi.
+type CLDKGlobal struct {
+	Variable string             `json:"variable"` // ID, come le chiavi di variables nella symbol table
+	Package  string             `json:"package"`
+	Type     string             `json:"type"`
+	Exported bool               `json:"exported"`
+	Counts   CLDKGlobalCounts   `json:"counts"`
+	Readers  []string           `json:"readers"`  // funzioni che la leggono, ordinate
+	Writers  []string           `json:"writers"`  // funzioni che la scrivono o ne prendono l'indirizzo, ordinate
+	Packages []string           `json:"packages"` // package delle funzioni che vi accedono, ordinati
+	Accesses []CLDKGlobalAccess `json:"accesses"` // ordinati per posizione
+	Position *CLDKPosition      `json:"position,omitempty"`
+}
+
+// CLDKGlobalCounts conta gli accessi per tipo.
+type CLDKGlobalCounts struct {
+	Reads   int `json:"reads"`
+	Writes  int `json:"writes"`
+	Address int `json:"address"` // &g o chiamata di un metodo con receiver puntatore
+}
+
+// CLDKGlobalAccess è un accesso a una variabile globale.
+type CLDKGlobalAccess struct {
+	Kind     string        `json:"kind"`               // read|write|address
+	Function string        `json:"function,omitempty"` // funzione che lo contiene, vuota negli inizializzatori
+	Package  string        `json:"package"`
+	Position *CLDKPosition `json:"position,omitempty"`
+}
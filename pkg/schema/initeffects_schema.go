@@ -0,0 +1,42 @@
+package schema
+
+// ============================================================================
+// Init Effects Schema
+// ============================================================================
+// Import vuoti (import _ "pkg") e cosa fanno le funzioni init dei package
+// che portano con sé (vedi --init-effects): registrazione di driver e
+// handler, assegnamenti a variabili globali, con la catena di init eseguita
+// transitivamente. L'effetto non compare nel codice che importa.
+
+// CLDKInitEffects raccoglie gli import vuoti del progetto e i package la
+// cui inizializzazione eseguono.
+type CLDKInitEffects struct {
+	BlankImports []CLDKBlankImport `json:"blank_imports"` // ordinati per posizione
+	Packages     []CLDKInitPackage `json:"packages"`      // package con effetti nelle catene, ordinati per import path
+}
+
+// CLDKBlankImport è un import _ "pkg" in un file del progetto.
+type CLDKBlankImport struct {
+	Importer string        `json:"importer"` // package che contiene l'import
+	Import   string        `json:"import"`   // import path
+	Chain    []string      `json:"chain"`    // package con effetti inizializzati per questo import, nell'ordine di esecuzione; l'ultimo è Import se ne ha
+	Position *CLDKPosition `json:"position,omitempty"`
+}
+
+// CLDKInitPackage descrive cosa fa l'inizializzazione di un package.
+type CLDKInitPackage struct {
+	Package  string         `json:"package"`
+	Standard bool           `json:"standard,omitempty"` // libreria standard
+	Inits    int            `json:"inits"`              // funzioni init
+	Calls    []CLDKInitCall `json:"calls"`              // chiamate ad altri package, nell'ordine in cui il sorgente le raggiunge
+	Globals  []string       `json:"globals"`            // variabili package-level assegnate, ordinate
+}
+
+// CLDKInitCall è una chiamata a un altro package eseguita
+// dall'inizializzazione.
+type CLDKInitCall struct {
+	Call     string        `json:"call"`               // ID della funzione chiamata, es. "database/sql.Register"
+	Args     []string      `json:"args,omitempty"`     // argomenti stringa costanti, es. il nome del driver o il pattern dell'handler
+	Via      []string      `json:"via"`                // da init (o pkg._ per un inizializzatore var _ = ...) alla funzione del package che chiama
+	Position *CLDKPosition `json:"position,omitempty"` // solo nei file del progetto
+}
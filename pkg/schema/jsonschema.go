@@ -0,0 +1,117 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ============================================================================
+// JSON Schema
+// ============================================================================
+
+// SchemaVersion è la versione del formato di output. Va incrementata ad ogni
+// modifica incompatibile dei tipi esportati in questo package.
+const SchemaVersion = "1.50.0"
+
+// JSONSchema genera il JSON Schema (draft 2020-12) di CLDKAnalysis per
+// riflessione sui tipi del package. I campi senza omitempty sono required;
+// slice, map e puntatori senza omitempty ammettono null, come l'output reale.
+func JSONSchema() map[string]interface{} {
+	g := &schemaGen{defs: make(map[string]interface{})}
+	root := g.ref(reflect.TypeOf(CLDKAnalysis{}))
+
+	return map[string]interface{}{
+		"$schema":          "https://json-schema.org/draft/2020-12/schema",
+		"$id":              "https://github.com/codellm-devkit/codeanalyzer-go/schema/" + SchemaVersion,
+		"title":            "codeanalyzer-go CLDK analysis",
+		"x-schema-version": SchemaVersion,
+		"$ref":             root["$ref"],
+		"$defs":            g.defs,
+	}
+}
+
+type schemaGen struct {
+	defs map[string]interface{}
+}
+
+// ref registra la definizione di uno struct in $defs e ne restituisce il $ref.
+func (g *schemaGen) ref(t reflect.Type) map[string]interface{} {
+	name := t.Name()
+	if _, ok := g.defs[name]; !ok {
+		g.defs[name] = nil // placeholder contro la ricorsione
+		g.defs[name] = g.object(t)
+	}
+	return map[string]interface{}{"$ref": "#/$defs/" + name}
+}
+
+func (g *schemaGen) object(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		omitempty := strings.Contains(opts, "omitempty")
+
+		props[name] = g.typeOf(f.Type, !omitempty)
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	obj := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+	return obj
+}
+
+// typeOf mappa un tipo Go nel frammento di schema corrispondente. nullable
+// indica che il valore zero (nil) viene serializzato come null.
+func (g *schemaGen) typeOf(t reflect.Type, nullable bool) interface{} {
+	var s interface{}
+	switch t.Kind() {
+	case reflect.Ptr:
+		s = g.typeOf(t.Elem(), false)
+	case reflect.Struct:
+		s = g.ref(t)
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Array {
+			nullable = false
+		}
+		s = map[string]interface{}{"type": "array", "items": g.typeOf(t.Elem(), false)}
+	case reflect.Map:
+		s = map[string]interface{}{"type": "object", "additionalProperties": g.typeOf(t.Elem(), false)}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+
+	if nullable && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+		return map[string]interface{}{"anyOf": []interface{}{s, map[string]interface{}{"type": "null"}}}
+	}
+	return s
+}
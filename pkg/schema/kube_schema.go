@@ -0,0 +1,46 @@
+package schema
+
+// ============================================================================
+// Kubernetes API Types Schema
+// ============================================================================
+// Tipi delle API Kubernetes dichiarati nel progetto (CRD e tipi generati con
+// controller-gen o code-generator): oggetti radice, liste e tipi annotati con
+// marker "+kubebuilder", con group/version/kind ricavati dai marker e dalle
+// dichiarazioni schema.GroupVersion del package.
+
+// CLDKKubeAPI è l'inventario dei tipi API del progetto.
+type CLDKKubeAPI struct {
+	GroupVersions []CLDKKubeGroupVersion `json:"group_versions"` // ordinati per group e version
+	Types         []CLDKKubeType         `json:"types"`          // ordinati per package e nome
+}
+
+// CLDKKubeGroupVersion è un package di tipi API.
+type CLDKKubeGroupVersion struct {
+	Package string   `json:"package"`
+	Group   string   `json:"group,omitempty"`   // vuoto se non ricavabile
+	Version string   `json:"version,omitempty"` // vuoto se non ricavabile
+	Kinds   []string `json:"kinds"`             // kind degli oggetti radice, ordinati
+	Markers []string `json:"markers,omitempty"` // marker del package, es. "+groupName=batch.example.com"
+}
+
+// CLDKKubeType è un tipo API.
+type CLDKKubeType struct {
+	Type           string              `json:"type"` // ID del tipo
+	Role           string              `json:"role"` // root|list|spec|status|type
+	Group          string              `json:"group,omitempty"`
+	Version        string              `json:"version,omitempty"`
+	Kind           string              `json:"kind,omitempty"`            // per root e list
+	ListOf         string              `json:"list_of,omitempty"`         // kind degli Items, per le liste
+	RuntimeObject  bool                `json:"runtime_object"`            // DeepCopyObject e GetObjectKind nel method set di *T
+	Scope          string              `json:"scope,omitempty"`           // Namespaced|Cluster, per root
+	Plural         string              `json:"plural,omitempty"`          // da +kubebuilder:resource:path
+	ShortNames     []string            `json:"short_names,omitempty"`     // da +kubebuilder:resource:shortName
+	Categories     []string            `json:"categories,omitempty"`      // da +kubebuilder:resource:categories
+	Subresources   []string            `json:"subresources,omitempty"`    // status|scale
+	StorageVersion bool                `json:"storage_version,omitempty"` // +kubebuilder:storageversion
+	Spec           string              `json:"spec,omitempty"`            // tipo del campo Spec
+	Status         string              `json:"status,omitempty"`          // tipo del campo Status
+	Markers        []string            `json:"markers,omitempty"`         // marker del tipo, nell'ordine del sorgente
+	FieldMarkers   map[string][]string `json:"field_markers,omitempty"`   // nome json del campo → marker
+	Position       *CLDKPosition       `json:"position,omitempty"`
+}
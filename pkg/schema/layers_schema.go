@@ -0,0 +1,37 @@
+package schema
+
+// ============================================================================
+// Layers Schema
+// ============================================================================
+// Layer architetturali inferiti dal grafo degli import tra i package del
+// progetto (vedi --layers): layer 0 sono i package che non importano altri
+// package del progetto, ogni altro package sta un layer sopra il più alto
+// di quelli che importa. Gli import che chiudono un ciclo non rispettano
+// nessuna stratificazione e sono riportati come violazioni.
+
+// CLDKLayers è la stratificazione inferita dei package.
+type CLDKLayers struct {
+	Layers     []CLDKLayer          `json:"layers"`     // dal basso verso l'alto
+	Violations []CLDKLayerViolation `json:"violations"` // ordinate per from e to
+}
+
+// CLDKLayer elenca i package di un layer.
+type CLDKLayer struct {
+	Index    int      `json:"index"`
+	Packages []string `json:"packages"` // ordinati
+}
+
+// CLDKLayerViolation è un import che risale i layer: un arco all'indietro
+// di un ciclo di import, escluso per ottenere la stratificazione.
+type CLDKLayerViolation struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	FromLayer int    `json:"from_layer"`
+	ToLayer   int    `json:"to_layer"`
+}
+
+// CLDKPackageLayer è il layer inferito di un package.
+type CLDKPackageLayer struct {
+	Index     int      `json:"index"`
+	BackEdges []string `json:"back_edges,omitempty"` // import del package che risalgono i layer
+}
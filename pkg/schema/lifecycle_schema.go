@@ -0,0 +1,24 @@
+package schema
+
+// ============================================================================
+// Type Lifecycle Schema
+// ============================================================================
+// Vista opzionale (--lifecycle) sul ciclo di vita dei tipi: come vengono
+// creati (costruttori convenzionali NewX/MustX), come vengono rilasciati
+// (Close/Shutdown/Stop) e se le istanze passano a una goroutine.
+
+// CLDKTypeLifecycle descrive creazione, rilascio e concorrenza di un tipo.
+type CLDKTypeLifecycle struct {
+	Constructors       []CLDKConstructor `json:"constructors,omitempty"`
+	Closers            []string          `json:"closers,omitempty"` // ID dei metodi Close/Shutdown/Stop, anche promossi
+	EscapesToGoroutine bool              `json:"escapes_to_goroutine"`
+	GoroutineSites     []CLDKPosition    `json:"goroutine_sites,omitempty"` // istruzioni go che ricevono un'istanza
+}
+
+// CLDKConstructor è una funzione che crea istanze del tipo.
+type CLDKConstructor struct {
+	Function       string `json:"function"` // ID della funzione
+	Kind           string `json:"kind"`     // new|must
+	ReturnsPointer bool   `json:"returns_pointer,omitempty"`
+	ReturnsError   bool   `json:"returns_error,omitempty"`
+}
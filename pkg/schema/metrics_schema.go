@@ -0,0 +1,25 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+// ============================================================================
+// Metrics Schema
+// ============================================================================
+// Metriche architetturali derivate dalla symbol table (import graph e
+// dichiarazioni di tipo). Pensate per strumenti di architecture review.
+
+// CLDKMetrics raccoglie le metriche calcolate sul progetto.
+type CLDKMetrics struct {
+	Packages map[string]*PackageMetrics `json:"packages"` // package path → metriche
+}
+
+// PackageMetrics contiene le metriche di coupling di un package (Robert C. Martin).
+type PackageMetrics struct {
+	AfferentCoupling int     `json:"afferent_coupling"` // Ca: package del progetto che importano questo
+	EfferentCoupling int     `json:"efferent_coupling"` // Ce: package del progetto importati da questo
+	Instability      float64 `json:"instability"`       // I = Ce / (Ca + Ce)
+	Abstractness     float64 `json:"abstractness"`      // A = interfacce / tipi dichiarati
+	Distance         float64 `json:"distance"`          // D = |A + I - 1| (distanza dalla main sequence)
+	InterfaceCount   int     `json:"interface_count"`   // numero di interfacce dichiarate
+	TypeCount        int     `json:"type_count"`        // numero totale di tipi dichiarati
+	ExternalImports  int     `json:"external_imports"`  // import fuori dal progetto (stdlib e dipendenze)
+}
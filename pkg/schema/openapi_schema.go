@@ -0,0 +1,124 @@
+package schema
+
+// ============================================================================
+// HTTP API Schema
+// ============================================================================
+// Endpoint registrati con net/http o gin e schemi di richiesta e risposta
+// ricostruiti dai tipi usati negli handler (vedi --http-api), e il
+// documento OpenAPI 3.0 scritto con --format openapi. La ricostruzione è
+// sintattica: gli schemi sono uno scheletro da completare a mano.
+
+// OpenAPIVersion è la versione della specifica del documento.
+const OpenAPIVersion = "3.0.3"
+
+// CLDKHTTPAPI raccoglie gli endpoint HTTP del progetto.
+type CLDKHTTPAPI struct {
+	Frameworks []string                  `json:"frameworks"`        // net/http|gin usati dal progetto
+	Endpoints  []CLDKHTTPEndpoint        `json:"endpoints"`         // ordinati per path e metodo
+	Schemas    map[string]*OpenAPISchema `json:"schemas,omitempty"` // tipi referenziati da "$ref", per nome
+}
+
+// CLDKHTTPEndpoint è una registrazione di handler.
+type CLDKHTTPEndpoint struct {
+	Method      string              `json:"method,omitempty"`  // GET, POST, ...; vuoto se la registrazione accetta ogni metodo
+	Path        string              `json:"path"`              // in forma OpenAPI, es. "/users/{id}"
+	Pattern     string              `json:"pattern"`           // come registrato, es. "GET /users/{id}" o "/users/:id"
+	Framework   string              `json:"framework"`         // net/http|gin
+	Handler     string              `json:"handler,omitempty"` // ID della funzione, vuoto se non risolto
+	Parameters  []CLDKHTTPParameter `json:"parameters,omitempty"`
+	RequestBody *CLDKHTTPBody       `json:"request_body,omitempty"`
+	Responses   []CLDKHTTPResponse  `json:"responses,omitempty"` // ordinate per status
+	Position    *CLDKPosition       `json:"position,omitempty"`  // registrazione
+}
+
+// CLDKHTTPParameter è un parametro letto dall'handler.
+type CLDKHTTPParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"` // path|query|header
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+// CLDKHTTPBody è il corpo della richiesta decodificato dall'handler.
+type CLDKHTTPBody struct {
+	ContentType string         `json:"content_type"`
+	Schema      *OpenAPISchema `json:"schema"`
+}
+
+// CLDKHTTPResponse è una risposta scritta dall'handler.
+type CLDKHTTPResponse struct {
+	Status      int            `json:"status,omitempty"`       // 0 se non è una costante
+	ContentType string         `json:"content_type,omitempty"` // vuoto senza corpo
+	Schema      *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPISchema è il sottoinsieme dello Schema Object di OpenAPI 3.0
+// ricostruibile dai tipi Go.
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"` // "#/components/schemas/Nome"
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+}
+
+// OpenAPIDocument è il documento scritto in openapi.json.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"` // path → metodo in minuscolo
+	Components *OpenAPIComponents                      `json:"components,omitempty"`
+}
+
+// OpenAPIInfo è l'Info Object.
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIOperation è un Operation Object; XHandler e XSource sono
+// estensioni con l'handler e la registrazione ("file:riga"), XAnyMethod
+// segna le registrazioni che accettano ogni metodo.
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"` // prima frase della documentazione dell'handler
+	Tags        []string                    `json:"tags,omitempty"`    // package dell'handler
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	XHandler    string                      `json:"x-handler,omitempty"`
+	XSource     string                      `json:"x-source,omitempty"`
+	XAnyMethod  bool                        `json:"x-any-method,omitempty"`
+}
+
+// OpenAPIParameter è un Parameter Object.
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody è un Request Body Object.
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse è un Response Object.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType è un Media Type Object.
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPIComponents contiene gli schemi referenziati.
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+}
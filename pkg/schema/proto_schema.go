@@ -0,0 +1,36 @@
+package schema
+
+// ============================================================================
+// Protobuf Lineage Schema
+// ============================================================================
+// Collegamento tra il codice generato da protoc-gen-go e protoc-gen-go-grpc
+// e le definizioni nei file .proto, ricavato dall'header dei file generati
+// e dal descrittore serializzato che contengono.
+
+// Tipi di elemento .proto.
+const (
+	ProtoKindFile      = "file"
+	ProtoKindMessage   = "message"
+	ProtoKindField     = "field"
+	ProtoKindOneof     = "oneof"
+	ProtoKindEnum      = "enum"
+	ProtoKindEnumValue = "enum_value"
+	ProtoKindService   = "service"
+	ProtoKindRPC       = "rpc"
+)
+
+// CLDKProtoFile è un file Go generato da un plugin di protoc.
+type CLDKProtoFile struct {
+	File      string `json:"file"`              // file Go generato
+	Source    string `json:"source"`            // file .proto, come nel commento "source:"
+	Package   string `json:"package,omitempty"` // package protobuf, se il descrittore è tra i package analizzati
+	Generator string `json:"generator"`         // es. "protoc-gen-go"
+	Version   string `json:"version,omitempty"` // versione del plugin, es. "v1.31.0"
+}
+
+// CLDKProtoRef collega un'entità generata all'elemento .proto da cui deriva.
+type CLDKProtoRef struct {
+	Source string `json:"source"` // file .proto
+	Name   string `json:"name"`   // nome completo, es. "helloworld.Greeter.SayHello"; il path del file per Kind file
+	Kind   string `json:"kind"`   // file|message|field|oneof|enum|enum_value|service|rpc
+}
@@ -0,0 +1,241 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+// ============================================================================
+// Query Schema
+// ============================================================================
+// Risultati dei sottocomandi "query" (cammini e dominatori sul call graph).
+
+// CLDKPathQuery è il risultato di "query path".
+type CLDKPathQuery struct {
+	From     string     `json:"from"`      // node ID risolto della sorgente
+	To       string     `json:"to"`        // node ID risolto della destinazione
+	MaxPaths int        `json:"max_paths"` // limite K richiesto
+	Paths    [][]string `json:"paths"`     // cammini in ordine di lunghezza crescente
+}
+
+// CLDKDominatorTree è il dominator tree del call graph a partire dagli entry point.
+type CLDKDominatorTree struct {
+	Roots []string          `json:"roots"` // entry point usati come radici
+	Idom  map[string]string `json:"idom"`  // node ID → immediate dominator ("" = radice virtuale)
+}
+
+// CLDKSymbolMatch è un risultato di "search".
+type CLDKSymbolMatch struct {
+	Kind          string        `json:"kind"` // function|method|struct|interface|alias|named|variable|constant
+	QualifiedName string        `json:"qualified_name"`
+	Name          string        `json:"name"`
+	Package       string        `json:"package"`
+	Signature     string        `json:"signature,omitempty"` // firma dei callable, tipo di variabili e costanti
+	Position      *CLDKPosition `json:"position"`
+}
+
+// CLDKReverseDeps è il risultato di "rdeps".
+type CLDKReverseDeps struct {
+	Packages   []string               `json:"packages"`   // package richiesti
+	Dependents []CLDKDependentPackage `json:"dependents"` // importatori dei package richiesti, per distanza
+}
+
+// CLDKDependentPackage è un package che importa, anche transitivamente, uno
+// dei package richiesti.
+type CLDKDependentPackage struct {
+	Package  string `json:"package"`
+	Distance int    `json:"distance"` // archi di import dal package richiesto più vicino (1 = import diretto)
+}
+
+// CLDKStringInventory è il risultato di "strings".
+type CLDKStringInventory struct {
+	Messages      int                   `json:"messages"`       // stringhe con kind message
+	FormatStrings int                   `json:"format_strings"` // stringhe con kind format
+	I18nKeys      int                   `json:"i18n_keys"`      // stringhe con kind i18n_key
+	Strings       []CLDKStringEntry     `json:"strings"`        // per file, riga e colonna
+	Duplicates    []CLDKStringDuplicate `json:"duplicates"`     // valori ripetuti, i più frequenti prima
+}
+
+// CLDKStringEntry è una string literal dell'inventario.
+type CLDKStringEntry struct {
+	Value    string        `json:"value"`           // troncato a 200 byte
+	Kind     string        `json:"kind,omitempty"`  // message|format|i18n_key
+	Verbs    []string      `json:"verbs,omitempty"` // verbi printf (kind format)
+	Call     string        `json:"call,omitempty"`  // funzione a cui la stringa è passata come argomento
+	Category string        `json:"category"`        // come CLDKStringLiteral.Category
+	Package  string        `json:"package"`
+	Scope    string        `json:"scope,omitempty"` // funzione contenitrice (vuoto = package-level)
+	Position *CLDKPosition `json:"position"`
+}
+
+// CLDKStringDuplicate è un valore che compare in più string literal.
+type CLDKStringDuplicate struct {
+	Value     string         `json:"value"`
+	Count     int            `json:"count"`
+	Positions []CLDKPosition `json:"positions"`
+}
+
+// CLDKAffectedTests è il risultato di "affected-tests".
+type CLDKAffectedTests struct {
+	Since           string                `json:"since,omitempty"` // revisione git di confronto (vuota con --changed-pkgs)
+	ChangedFiles    []string              `json:"changed_files"`   // relativi alla root
+	ChangedPackages []string              `json:"changed_packages"`
+	Packages        []CLDKAffectedPackage `json:"packages"` // package con test da eseguire, per distanza
+}
+
+// CLDKAffectedPackage è un package con test influenzato dalle modifiche.
+// La selezione è per package: PackageTests elenca tutti i test del package,
+// anche quelli che non raggiungono le modifiche.
+type CLDKAffectedPackage struct {
+	Package      string   `json:"package"`
+	Distance     int      `json:"distance"`      // archi di import dal package modificato più vicino
+	PackageTests []string `json:"package_tests"` // funzioni Test, Benchmark, Fuzz ed Example, interne ed esterne (_test), non filtrate
+}
+
+// CLDKPack è il risultato di "pack": il simbolo focus e il suo intorno,
+// ordinati per rilevanza ed entro il budget di token.
+type CLDKPack struct {
+	Focus   string         `json:"focus"`             // ID risolto del simbolo
+	Budget  int            `json:"budget"`            // token richiesti
+	Tokens  int            `json:"tokens"`            // token stimati dell'intero documento, intestazione compresa
+	Items   []CLDKPackItem `json:"items"`             // in ordine di rilevanza, focus per primo
+	Omitted []string       `json:"omitted,omitempty"` // candidati esclusi per il budget, in ordine di rilevanza
+}
+
+// CLDKPackItem è un simbolo incluso nel pack.
+type CLDKPackItem struct {
+	ID        string        `json:"id"`
+	Kind      string        `json:"kind"`     // function|method|struct|interface|alias|named
+	Relation  string        `json:"relation"` // focus|callee|caller|method|receiver|type
+	Distance  int           `json:"distance"` // archi del call graph dal focus; per i tipi, quelli del simbolo che li nomina
+	Score     float64       `json:"score"`
+	Signature string        `json:"signature,omitempty"`
+	Doc       string        `json:"doc,omitempty"`
+	Source    string        `json:"source,omitempty"` // dichiarazione completa, se entra nel budget
+	Position  *CLDKPosition `json:"position,omitempty"`
+	Tokens    int           `json:"tokens"` // token stimati dell'elemento nel documento
+}
+
+// CLDKGraphCache è il risultato di "graph load": la cache binaria scritta
+// per un'analisi salvata.
+type CLDKGraphCache struct {
+	Source      string `json:"source"`
+	Cache       string `json:"cache"`
+	Packages    int    `json:"packages"`
+	Nodes       int    `json:"nodes"`
+	Edges       int    `json:"edges"`
+	SourceBytes int64  `json:"source_bytes"`
+	CacheBytes  int64  `json:"cache_bytes"`
+	JSONLoadMs  int64  `json:"json_load_ms"`  // lettura del JSON
+	CacheLoadMs int64  `json:"cache_load_ms"` // rilettura della cache appena scritta
+}
+
+// CLDKCloneReport è il risultato di "clones": gruppi di funzioni con corpo
+// uguale o simile dopo la normalizzazione dell'AST.
+type CLDKCloneReport struct {
+	Functions         int              `json:"functions"`          // funzioni confrontate (oltre --min-tokens)
+	IgnoreIdentifiers bool             `json:"ignore_identifiers"` // identificatori e letterali normalizzati
+	Threshold         float64          `json:"threshold"`          // similarità minima dei quasi duplicati
+	DuplicatedLines   int              `json:"duplicated_lines"`   // righe dei membri oltre il primo di ogni gruppo
+	Groups            []CLDKCloneGroup `json:"groups"`             // i gruppi con più righe duplicate prima
+}
+
+// CLDKCloneGroup è un gruppo di cloni.
+type CLDKCloneGroup struct {
+	Kind       string            `json:"kind"`       // exact|near
+	Similarity float64           `json:"similarity"` // minima tra i membri e il primo (1 per exact)
+	Members    []CLDKCloneMember `json:"members"`    // il primo è il riferimento
+}
+
+// CLDKCloneMember è una funzione di un gruppo di cloni.
+type CLDKCloneMember struct {
+	Callable   string        `json:"callable"` // ID della funzione o del metodo
+	Package    string        `json:"package"`
+	Tokens     int           `json:"tokens"`     // nodi del corpo normalizzato
+	Lines      int           `json:"lines"`      // righe della dichiarazione
+	Similarity float64       `json:"similarity"` // rispetto al primo membro
+	Position   *CLDKPosition `json:"position"`
+}
+
+// CLDKAPIUsage conta quanto le altre analisi di un corpus usano l'API
+// esportata di un modulo (comando api-usage).
+type CLDKAPIUsage struct {
+	Target    string            `json:"target"`           // file dell'analisi del modulo
+	Module    string            `json:"module,omitempty"` // prefisso dei package considerati (--module)
+	Consumers []CLDKAPIConsumer `json:"consumers"`        // nell'ordine di lettura
+	Used      int               `json:"used"`             // simboli referenziati almeno una volta
+	Unused    int               `json:"unused"`           // simboli mai referenziati
+	Symbols   []CLDKAPISymbol   `json:"symbols"`          // per References decrescente, poi per ID
+}
+
+// CLDKAPIConsumer è un'analisi del corpus.
+type CLDKAPIConsumer struct {
+	Name       string `json:"name"`       // file dell'analisi (relativo a --corpus se trovato lì)
+	References int    `json:"references"` // riferimenti all'API del modulo
+	Symbols    int    `json:"symbols"`    // simboli distinti referenziati
+}
+
+// CLDKAPISymbol è un simbolo esportato del modulo con i suoi usi.
+type CLDKAPISymbol struct {
+	ID         string   `json:"id"`
+	Kind       string   `json:"kind"` // function|method|type
+	Package    string   `json:"package"`
+	References int      `json:"references"` // Calls + TypeUses
+	Calls      int      `json:"calls"`      // call site negli archi del call graph
+	TypeUses   int      `json:"type_uses"`  // tipi di parametri, risultati, campi e variabili
+	Consumers  []string `json:"consumers,omitempty"`
+}
+
+// CLDKImpact è il risultato di "impact": ciò che tocca la rinomina o la
+// modifica della firma di un simbolo.
+type CLDKImpact struct {
+	Symbol       string              `json:"symbol"`        // ID risolto
+	Kind         string              `json:"kind"`          // function|method|type|variable|constant|field
+	Exported     bool                `json:"exported"`      // nome esportato
+	PublicAPI    bool                `json:"public_api"`    // visibile da altri moduli: esportato, fuori da internal/ e main, con tipo contenitore esportato
+	CrossPackage bool                `json:"cross_package"` // referenziato fuori dal proprio package
+	Declaration  *CLDKPosition       `json:"declaration"`
+	References   []CLDKImpactRef     `json:"references"`       // per file e posizione
+	Packages     []CLDKImpactPackage `json:"packages"`         // package toccati, dichiarazione compresa
+	Files        []string            `json:"files"`            // file toccati, dichiarazione compresa
+	Tests        []CLDKImpactTest    `json:"tests"`            // test che referenziano o raggiungono il simbolo
+	Delete       *CLDKSafeDelete     `json:"delete,omitempty"` // con --delete
+}
+
+// CLDKImpactRef è un riferimento al simbolo.
+type CLDKImpactRef struct {
+	Package  string        `json:"package"`
+	Function string        `json:"function,omitempty"` // ID della funzione che contiene il riferimento, vuoto a livello di package
+	Test     bool          `json:"test,omitempty"`     // in un file _test.go
+	Own      bool          `json:"own,omitempty"`      // dentro la dichiarazione del simbolo stesso
+	Position *CLDKPosition `json:"position"`
+}
+
+// CLDKImpactPackage riassume i riferimenti in un package.
+type CLDKImpactPackage struct {
+	Package    string   `json:"package"`
+	References int      `json:"references"`
+	Files      []string `json:"files"`
+}
+
+// CLDKImpactTest è una funzione di test influenzata.
+type CLDKImpactTest struct {
+	Package  string `json:"package"` // package del file _test.go (p o p_test)
+	Name     string `json:"name"`
+	Distance int    `json:"distance"` // 0 se referenzia il simbolo, altrimenti chiamate fino a una funzione che lo fa
+}
+
+// CLDKSafeDelete è il verdetto di "impact --delete": il simbolo si può
+// rimuovere se nessun riferimento fuori dalla sua dichiarazione e dai test
+// lo usa e se nessun tipo perde, senza il metodo, un'interfaccia che
+// implementa.
+type CLDKSafeDelete struct {
+	Safe           bool                      `json:"safe"`
+	Blocking       []CLDKImpactRef           `json:"blocking"`        // riferimenti che impediscono la rimozione
+	TestReferences int                       `json:"test_references"` // riferimenti nei test, da rimuovere con il simbolo
+	Obligations    []CLDKInterfaceObligation `json:"obligations"`     // interfacce che smetterebbero di essere implementate
+}
+
+// CLDKInterfaceObligation è un'interfaccia implementata da un tipo solo
+// grazie al metodo da rimuovere.
+type CLDKInterfaceObligation struct {
+	Type      string        `json:"type"`               // ID del tipo che la implementa (T o *T)
+	Interface string        `json:"interface"`          // ID dell'interfaccia
+	Position  *CLDKPosition `json:"position,omitempty"` // dichiarazione del tipo
+}
@@ -0,0 +1,31 @@
+package schema
+
+// ============================================================================
+// Resources Schema
+// ============================================================================
+// Risorse incluse nel binario con //go:embed: per ogni variabile, i pattern
+// della direttiva e i file che corrispondono sul disco.
+
+// CLDKResources raccoglie le direttive //go:embed del progetto.
+type CLDKResources struct {
+	Embeds     []CLDKEmbed `json:"embeds"`      // ordinate per variabile
+	TotalFiles int         `json:"total_files"` // file inclusi (con ripetizioni tra variabili)
+	TotalBytes int64       `json:"total_bytes"`
+}
+
+// CLDKEmbed è una variabile inizializzata da una direttiva //go:embed.
+type CLDKEmbed struct {
+	Package    string             `json:"package"`
+	Variable   string             `json:"variable"` // qualified name, come in symbol_table.variables
+	Type       string             `json:"type"`     // "string", "[]byte" o "embed.FS"
+	Patterns   []string           `json:"patterns"` // come scritti nella direttiva, incluso il prefisso "all:"
+	Files      []CLDKEmbeddedFile `json:"files"`    // file corrispondenti, relativi alla root
+	TotalBytes int64              `json:"total_bytes"`
+	Position   *CLDKPosition      `json:"position,omitempty"`
+}
+
+// CLDKEmbeddedFile è un file incluso da una direttiva //go:embed.
+type CLDKEmbeddedFile struct {
+	File string `json:"file"`
+	Size int64  `json:"size"` // in byte
+}
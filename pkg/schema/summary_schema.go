@@ -0,0 +1,38 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+// ============================================================================
+// Function Summaries Schema
+// ============================================================================
+// Riassunti data-flow leggeri per funzione, costruiti dall'SSA: quali
+// parametri fluiscono nei valori di ritorno, quali sfuggono verso variabili
+// globali o goroutine, quali campi del receiver sono letti/scritti.
+// Strutturati per package come il PDG.
+
+// CLDKSummaries contiene i riassunti per tutti i package analizzati.
+type CLDKSummaries struct {
+	Packages map[string]*CLDKPackageSummaries `json:"packages"`
+}
+
+// CLDKPackageSummaries raggruppa i riassunti delle funzioni di un package.
+type CLDKPackageSummaries struct {
+	Functions map[string]*CLDKFunctionSummary `json:"functions"`
+}
+
+// CLDKFunctionSummary è il riassunto data-flow di una funzione.
+type CLDKFunctionSummary struct {
+	QualifiedName string      `json:"qualified_name"`
+	Package       string      `json:"package"`
+	Params        []ParamFlow `json:"params"`
+	FieldsRead    []string    `json:"fields_read,omitempty"`    // campi del receiver letti
+	FieldsWritten []string    `json:"fields_written,omitempty"` // campi del receiver scritti
+}
+
+// ParamFlow descrive dove fluisce un parametro (il receiver ha indice 0 nei metodi).
+type ParamFlow struct {
+	Name        string   `json:"name"`
+	Index       int      `json:"index"`
+	ToResults   []int    `json:"to_results,omitempty"`   // indici dei risultati che dipendono dal parametro
+	ToGlobals   []string `json:"to_globals,omitempty"`   // variabili globali in cui il parametro viene scritto
+	ToGoroutine bool     `json:"to_goroutine,omitempty"` // il parametro raggiunge un go statement
+}
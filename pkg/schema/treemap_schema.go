@@ -0,0 +1,29 @@
+// Package schema definisce i tipi CLDK per l'output dell'analyzer Go.
+package schema
+
+// ============================================================================
+// Treemap Schema
+// ============================================================================
+// Gerarchia package → file → callable già aggregata, scritta con --format
+// treemap per dashboard che disegnano la struttura del codice (ad esempio
+// con d3.hierarchy, che usa name, value e children).
+
+// CLDKTreemap è il documento scritto in treemap.json.
+type CLDKTreemap struct {
+	SchemaVersion string       `json:"schema_version"`
+	Project       string       `json:"project"`
+	SizeBy        string       `json:"size_by"` // sloc|complexity: metrica copiata in value
+	Root          *TreemapNode `json:"root"`
+}
+
+// TreemapNode è un nodo della treemap. Per package e file sloc e complexity
+// sono le somme sui figli.
+type TreemapNode struct {
+	Name       string         `json:"name"`
+	Kind       string         `json:"kind"`         // project|package|file|function|method
+	ID         string         `json:"id,omitempty"` // import path, file relativo o ID del callable
+	Value      int            `json:"value"`        // sloc o complexity secondo size_by
+	SLOC       int            `json:"sloc"`         // righe con codice, esclusi commenti e righe vuote
+	Complexity int            `json:"complexity,omitempty"`
+	Children   []*TreemapNode `json:"children,omitempty"`
+}
@@ -0,0 +1,37 @@
+package schema
+
+// ============================================================================
+// Type Graph Schema
+// ============================================================================
+// Grafo dei riferimenti tra tipi (vedi --type-graph): un arco va da un tipo
+// dichiarato nel progetto a ogni tipo con nome che compare nei suoi campi,
+// nei tipi incorporati, nelle firme dei metodi di interfaccia, nei vincoli
+// dei parametri di tipo e nel tipo sottostante. È il modello dei dati del
+// progetto, complementare al call graph.
+
+// CLDKTypeGraph è il grafo dei tipi.
+type CLDKTypeGraph struct {
+	Nodes []CLDKTypeNode `json:"nodes"` // ordinati per ID
+	Edges []CLDKTypeEdge `json:"edges"` // ordinati per source, target, kind e via
+}
+
+// CLDKTypeNode è un tipo con nome: dichiarato nel progetto o, se esterno,
+// raggiunto da un arco.
+type CLDKTypeNode struct {
+	ID       string        `json:"id"` // ID del tipo, come le chiavi di type_declarations
+	Package  string        `json:"package"`
+	Name     string        `json:"name"`
+	Kind     string        `json:"kind"`               // struct|interface|alias|named
+	External bool          `json:"external,omitempty"` // dichiarato fuori dal progetto
+	Position *CLDKPosition `json:"position,omitempty"` // solo per i tipi del progetto
+}
+
+// CLDKTypeEdge è un riferimento da un tipo a un altro. Le istanze di tipi
+// generici puntano al tipo generico e ai loro argomenti di tipo.
+type CLDKTypeEdge struct {
+	Source   string        `json:"source"`
+	Target   string        `json:"target"`
+	Kind     string        `json:"kind"`          // field|embed|method|constraint|underlying|alias
+	Via      string        `json:"via,omitempty"` // nome del campo, del metodo o del parametro di tipo
+	Position *CLDKPosition `json:"position,omitempty"`
+}
@@ -16,7 +16,11 @@ if (-not (Test-Path $binDir)) {
     New-Item -ItemType Directory -Path $binDir | Out-Null
 }
 
-Write-Host "Building codeanalyzer-go for all platforms..." -ForegroundColor Cyan
+# Version injected into the binary (main.version)
+$version = git describe --tags --always --dirty 2>$null
+if (-not $version) { $version = "dev" }
+
+Write-Host "Building codeanalyzer-go $version for all platforms..." -ForegroundColor Cyan
 
 foreach ($t in $targets) {
     $env:GOOS = $t.GOOS
@@ -25,7 +29,7 @@ foreach ($t in $targets) {
     
     Write-Host "  Building $($t.Name)..." -ForegroundColor Yellow
     
-    go build -ldflags "-s -w" -o $outPath ./cmd/codeanalyzer-go
+    go build -ldflags "-s -w -X main.version=$version" -o $outPath ./cmd/codeanalyzer-go
     
     if ($LASTEXITCODE -eq 0) {
         $size = (Get-Item $outPath).Length / 1MB
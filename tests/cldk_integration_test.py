@@ -233,6 +233,27 @@ class TestCLDKSchema(unittest.TestCase):
                         self.assertIn("type", field)
                         self.assertIn("exported", field)
 
+    def test_call_graph_symbol_refs_resolve(self):
+        """Test every call graph symbol_ref is a callable_declarations key."""
+        for algo in ("cha", "rta", "vta", "static-approx"):
+            with self.subTest(cg=algo):
+                result = run_analyzer(
+                    "--input", str(SAMPLE_APP),
+                    "--analysis-level", "full",
+                    "--cg", algo,
+                )
+                self.assertEqual(result.returncode, 0, f"stderr: {result.stderr}")
+
+                data = json.loads(result.stdout)
+                callables = set()
+                for pkg in data["symbol_table"]["packages"].values():
+                    callables.update(pkg["callable_declarations"])
+
+                refs = [n["symbol_ref"] for n in data["call_graph"]["nodes"] if n.get("symbol_ref")]
+                self.assertTrue(refs, "no call graph node has a symbol_ref")
+                for ref in refs:
+                    self.assertIn(ref, callables)
+
 
 # ============================================================================
 # New features tests (sampleapp)
@@ -327,12 +348,16 @@ class TestCLDKNewFeatures(unittest.TestCase):
         """Test call_examples are populated when --include-body is used."""
         has_examples = False
         for qn, cd in self.pkg["callable_declarations"].items():
-            exs = cd.get("call_examples", [])
+            exs = cd.get("call_example_sites", [])
             if exs:
                 has_examples = True
                 for ex in exs:
-                    self.assertIsInstance(ex, str)
-                    self.assertIn("called by", ex)
+                    self.assertIsInstance(ex, dict)
+                    self.assertIn("caller", ex)
+                    self.assertIn("(", ex["expression"])
+                for text in cd["call_examples"]:
+                    self.assertIsInstance(text, str)
+                    self.assertTrue(text.startswith("called by "))
         self.assertTrue(has_examples, "At least one callable should have call_examples")
 
     def test_call_examples_without_body(self):
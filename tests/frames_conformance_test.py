@@ -0,0 +1,390 @@
+#!/usr/bin/env python3
+"""
+NDJSON frame protocol conformance tests for codeanalyzer-go
+
+Checks the framing rules that subprocess clients (CLDK Python) rely on,
+for both `--format ndjson-frames` and the `bridge` command.
+Run with: python tests/frames_conformance_test.py
+
+Prerequisites:
+- Build the analyzer: .\\scripts\\build.ps1 (or set CODEANALYZER_GO to a binary)
+- Have the test target: sampleapp/
+- Optional: `pip install jsonschema` to also validate every frame against
+  `codeanalyzer-go schema --frames`
+"""
+
+import json
+import os
+import subprocess
+import sys
+import unittest
+from pathlib import Path
+
+# Determine paths
+SCRIPT_DIR = Path(__file__).parent.absolute()
+PROJECT_ROOT = SCRIPT_DIR.parent
+ANALYZER_PATH = Path(os.environ.get(
+    "CODEANALYZER_GO",
+    PROJECT_ROOT / "bin" / "codeanalyzer-go-windows-amd64.exe",
+))
+SAMPLE_APP = PROJECT_ROOT / "sampleapp"
+
+PROTOCOL = "cldk-frames"
+PROTOCOL_VERSION = 1
+
+# Frame kinds and the order in which an analysis is streamed
+STREAM_ORDER = ["hello", "metadata", "package", "callgraph", "node", "edge", "section", "issue", "end"]
+FRAME_KINDS = set(STREAM_ORDER) | {"result", "error"}
+FRAME_KEYS = {"frame", "id", "name", "data"}
+TERMINAL = {"end", "error"}
+
+try:
+    import jsonschema
+except ImportError:  # validation against the schema is optional
+    jsonschema = None
+
+
+def run_analyzer(*args: str, capture_output: bool = True) -> subprocess.CompletedProcess:
+    """Run the analyzer with given arguments."""
+    cmd = [str(ANALYZER_PATH)] + list(args)
+    return subprocess.run(
+        cmd,
+        capture_output=capture_output,
+        text=True,
+        cwd=str(PROJECT_ROOT),
+    )
+
+
+def parse_frames(text: str) -> list:
+    """Parse an NDJSON stream, one frame per non-empty line."""
+    return [json.loads(line) for line in text.splitlines() if line.strip()]
+
+
+def frames_validator():
+    """Return a validator for single frames, or None without jsonschema."""
+    if jsonschema is None:
+        return None
+    result = run_analyzer("schema", "--frames")
+    if result.returncode != 0:
+        raise AssertionError(f"schema --frames failed: {result.stderr}")
+    return jsonschema.Draft202012Validator(json.loads(result.stdout))
+
+
+class FrameAssertions:
+    """Checks shared by the stream and bridge tests."""
+
+    validator = None
+
+    def assert_frame(self, frame: dict):
+        self.assertIsInstance(frame, dict)
+        self.assertIn(frame.get("frame"), FRAME_KINDS, f"unknown frame: {frame}")
+        self.assertLessEqual(set(frame), FRAME_KEYS, f"unexpected keys: {frame}")
+        if frame["frame"] in ("package", "section"):
+            self.assertIsInstance(frame.get("name"), str)
+        if frame["frame"] != "result":
+            self.assertIn("data", frame)
+        if self.validator is not None:
+            self.validator.validate(frame)
+
+    def assert_hello(self, frame: dict):
+        self.assertEqual(frame["frame"], "hello")
+        hello = frame["data"]
+        self.assertEqual(hello["protocol"], PROTOCOL)
+        self.assertEqual(hello["protocol_version"], PROTOCOL_VERSION)
+        self.assertEqual(hello["analyzer"], "codeanalyzer-go")
+        self.assertRegex(hello["schema_version"], r"^\d+\.\d+\.\d+$")
+
+    def assert_counts(self, body: list, end: dict):
+        """The end frame counts the frames of its stream or response."""
+        counts = {}
+        for frame in body:
+            counts[frame["frame"]] = counts.get(frame["frame"], 0) + 1
+        self.assertEqual(end["data"]["counts"], counts)
+
+    def assert_callgraph(self, body: list):
+        """A callgraph frame announces the node and edge frames that follow."""
+        header = [f for f in body if f["frame"] == "callgraph"]
+        self.assertEqual(len(header), 1)
+        nodes = [f["data"] for f in body if f["frame"] == "node"]
+        edges = [f["data"] for f in body if f["frame"] == "edge"]
+        self.assertEqual(header[0]["data"]["nodes"], len(nodes))
+        self.assertEqual(header[0]["data"]["edges"], len(edges))
+        ids = {n["id"] for n in nodes}
+        self.assertEqual(len(ids), len(nodes), "duplicate node ids")
+        for e in edges:
+            self.assertIn(e["source"], ids)
+            self.assertIn(e["target"], ids)
+
+
+# ============================================================================
+# --format ndjson-frames
+# ============================================================================
+
+class TestFrameStream(FrameAssertions, unittest.TestCase):
+    """Test the frame stream written by --format ndjson-frames."""
+
+    @classmethod
+    def setUpClass(cls):
+        cls.validator = frames_validator()
+        result = run_analyzer(
+            "--input", str(SAMPLE_APP),
+            "--analysis-level", "full",
+            "--format", "ndjson-frames",
+        )
+        if result.returncode != 0:
+            raise AssertionError(f"stderr: {result.stderr}")
+        cls.frames = parse_frames(result.stdout)
+
+    def test_every_line_is_a_frame(self):
+        for frame in self.frames:
+            self.assert_frame(frame)
+            self.assertNotIn("id", frame, "stream frames carry no request id")
+
+    def test_opens_with_hello_and_closes_with_end(self):
+        self.assert_hello(self.frames[0])
+        self.assertNotIn("methods", self.frames[0]["data"])
+        self.assertEqual(self.frames[-1]["frame"], "end")
+        kinds = [f["frame"] for f in self.frames]
+        self.assertEqual(kinds.count("hello"), 1)
+        self.assertEqual(kinds.count("end"), 1)
+        self.assertEqual(kinds.count("metadata"), 1)
+
+    def test_frame_order(self):
+        ranks = [STREAM_ORDER.index(f["frame"]) for f in self.frames]
+        self.assertEqual(ranks, sorted(ranks))
+
+    def test_end_counts(self):
+        self.assert_counts(self.frames[1:-1], self.frames[-1])
+
+    def test_metadata_matches_hello(self):
+        hello, metadata = self.frames[0]["data"], self.frames[1]["data"]
+        self.assertEqual(metadata["schema_version"], hello["schema_version"])
+        self.assertEqual(metadata["analysis_level"], "full")
+
+    def test_packages_sorted_and_named(self):
+        names = [f["name"] for f in self.frames if f["frame"] == "package"]
+        self.assertTrue(names, "no package frames")
+        self.assertEqual(names, sorted(names))
+        self.assertEqual(len(names), len(set(names)))
+        for frame in self.frames:
+            if frame["frame"] == "package":
+                self.assertIn("callable_declarations", frame["data"])
+
+    def test_callgraph(self):
+        self.assert_callgraph(self.frames)
+
+    def test_sections_are_analysis_fields(self):
+        names = [f["name"] for f in self.frames if f["frame"] == "section"]
+        self.assertIn("pdg", names)
+        for streamed in ("metadata", "symbol_table", "call_graph", "issues"):
+            self.assertNotIn(streamed, names)
+
+    def test_same_content_as_json(self):
+        """Frames reassemble into the same symbol table and call graph as --format json."""
+        result = run_analyzer("--input", str(SAMPLE_APP), "--analysis-level", "full")
+        self.assertEqual(result.returncode, 0, f"stderr: {result.stderr}")
+        data = json.loads(result.stdout)
+        packages = {f["name"]: f["data"] for f in self.frames if f["frame"] == "package"}
+        self.assertEqual(sorted(packages), sorted(data["symbol_table"]["packages"]))
+        nodes = sorted(f["data"]["id"] for f in self.frames if f["frame"] == "node")
+        self.assertEqual(nodes, sorted(n["id"] for n in data["call_graph"]["nodes"]))
+        edges = sum(1 for f in self.frames if f["frame"] == "edge")
+        self.assertEqual(edges, len(data["call_graph"]["edges"]))
+
+    def test_rejects_compact(self):
+        result = run_analyzer(
+            "--input", str(SAMPLE_APP),
+            "--format", "ndjson-frames",
+            "--compact",
+        )
+        self.assertEqual(result.returncode, 2)
+
+
+# ============================================================================
+# bridge
+# ============================================================================
+
+class Bridge:
+    """A running `codeanalyzer-go bridge` process."""
+
+    def __init__(self, *args: str):
+        self.proc = subprocess.Popen(
+            [str(ANALYZER_PATH), "bridge"] + list(args),
+            stdin=subprocess.PIPE,
+            stdout=subprocess.PIPE,
+            stderr=subprocess.PIPE,
+            text=True,
+            cwd=str(PROJECT_ROOT),
+        )
+        self.hello = self.read()
+
+    def read(self) -> dict:
+        line = self.proc.stdout.readline()
+        if not line:
+            raise AssertionError("bridge closed stdout")
+        return json.loads(line)
+
+    def send_raw(self, line: str):
+        self.proc.stdin.write(line + "\n")
+        self.proc.stdin.flush()
+
+    def call(self, request) -> list:
+        """Send a request and return its frames, terminal frame included."""
+        self.send_raw(request if isinstance(request, str) else json.dumps(request))
+        frames = []
+        while not frames or frames[-1]["frame"] not in TERMINAL:
+            frames.append(self.read())
+        return frames
+
+    def close(self) -> int:
+        self.proc.stdin.close()
+        rest = self.proc.stdout.read()
+        code = self.proc.wait(timeout=60)
+        self.proc.stdout.close()
+        self.proc.stderr.close()
+        if rest.strip():
+            raise AssertionError(f"unexpected output after the last response: {rest!r}")
+        return code
+
+
+class TestBridge(FrameAssertions, unittest.TestCase):
+    """Test request/response framing of the bridge command."""
+
+    @classmethod
+    def setUpClass(cls):
+        cls.validator = frames_validator()
+
+    def setUp(self):
+        self.bridge = Bridge("--input", str(SAMPLE_APP))
+        self.next_id = 0
+
+    def tearDown(self):
+        if self.bridge.proc.poll() is None:
+            self.bridge.proc.kill()
+            self.bridge.proc.wait()
+        for stream in (self.bridge.proc.stdin, self.bridge.proc.stdout, self.bridge.proc.stderr):
+            if not stream.closed:
+                stream.close()
+
+    def call(self, method: str, params=None) -> list:
+        """Call a method and check the framing of its response."""
+        self.next_id += 1
+        request = {"id": self.next_id, "method": method}
+        if params is not None:
+            request["params"] = params
+        frames = self.bridge.call(request)
+        for frame in frames:
+            self.assert_frame(frame)
+            self.assertEqual(frame.get("id"), self.next_id)
+        if frames[-1]["frame"] == "end":
+            self.assert_counts(frames[:-1], frames[-1])
+        else:
+            self.assertEqual(len(frames), 1, "an error frame closes the response alone")
+        return frames
+
+    def assert_error(self, frames: list, code: str):
+        self.assertEqual(frames[-1]["frame"], "error")
+        self.assertEqual(frames[-1]["data"]["code"], code)
+        self.assertTrue(frames[-1]["data"]["message"])
+
+    def test_hello(self):
+        self.assert_hello(self.bridge.hello)
+        self.assertNotIn("id", self.bridge.hello)
+        methods = self.bridge.hello["data"]["methods"]
+        for method in ("symbols", "callgraph", "query.path", "query.dominators", "search", "shutdown"):
+            self.assertIn(method, methods)
+        frames = self.call("hello")
+        self.assertEqual([f["frame"] for f in frames], ["hello", "end"])
+
+    def test_metadata(self):
+        frames = self.call("metadata")
+        self.assertEqual([f["frame"] for f in frames], ["metadata", "end"])
+        self.assertEqual(frames[0]["data"]["schema_version"], self.bridge.hello["data"]["schema_version"])
+
+    def test_symbols(self):
+        frames = self.call("symbols")
+        names = [f["name"] for f in frames if f["frame"] == "package"]
+        self.assertTrue(names)
+        self.assertEqual(names, sorted(names))
+        one = self.call("symbols", {"packages": [names[0]]})
+        self.assertEqual([f["name"] for f in one if f["frame"] == "package"], [names[0]])
+        self.assert_error(self.call("symbols", {"packages": ["no/such/package"]}), "not_found")
+
+    def test_callgraph(self):
+        frames = self.call("callgraph")
+        self.assertEqual(frames[-1]["frame"], "end")
+        self.assert_callgraph(frames)
+
+    def test_analysis(self):
+        frames = self.call("analysis")
+        ranks = [STREAM_ORDER.index(f["frame"]) for f in frames]
+        self.assertEqual(ranks, sorted(ranks))
+        self.assertEqual(frames[0]["frame"], "metadata")
+
+    def test_queries(self):
+        nodes = [f["data"]["id"] for f in self.call("callgraph") if f["frame"] == "node"]
+        self.assertTrue(nodes)
+        frames = self.call("query.path", {"from": nodes[0], "to": nodes[0], "k": 1})
+        self.assertEqual([f["frame"] for f in frames], ["result", "end"])
+        self.assertIn("paths", frames[0]["data"])
+        frames = self.call("query.dominators", {"roots": [nodes[0]]})
+        self.assertEqual([f["frame"] for f in frames], ["result", "end"])
+        self.assert_error(self.call("query.path", {"from": nodes[0]}), "invalid_params")
+        self.assert_error(self.call("query.path", {"from": "no.such.func", "to": nodes[0]}), "not_found")
+
+    def test_search(self):
+        frames = self.call("search", {"kind": "func"})
+        self.assertEqual([f["frame"] for f in frames], ["result", "end"])
+        self.assertIsInstance(frames[0]["data"], list)
+        for match in frames[0]["data"]:
+            self.assertEqual(match["kind"], "function")
+
+    def test_schema(self):
+        frames = self.call("schema", {"frames": True})
+        kinds = {b["properties"]["frame"]["const"] for b in frames[0]["data"]["oneOf"]}
+        self.assertEqual(kinds, FRAME_KINDS)
+
+    def test_errors_keep_the_bridge_alive(self):
+        self.bridge.send_raw("{not json")
+        error = self.bridge.read()
+        self.assertEqual(error["frame"], "error")
+        self.assertNotIn("id", error)
+        self.assertEqual(error["data"]["code"], "bad_request")
+        self.assert_error(self.call("no.such.method"), "unknown_method")
+        self.assert_error(self.call("metadata", {"unknown": 1}), "invalid_params")
+        self.assertEqual(self.call("hello")[-1]["frame"], "end")
+
+    def test_string_ids(self):
+        frames = self.bridge.call({"id": "req-1", "method": "hello"})
+        self.assertTrue(all(f["id"] == "req-1" for f in frames))
+
+    def test_shutdown(self):
+        frames = self.call("shutdown")
+        self.assertEqual([f["frame"] for f in frames], ["end"])
+        self.assertEqual(self.bridge.close(), 0)
+
+    def test_eof(self):
+        self.call("hello")
+        self.assertEqual(self.bridge.close(), 0)
+
+    def test_load_failure(self):
+        bridge = Bridge("--input", "/nonexistent/path/to/project")
+        try:
+            self.assert_hello(bridge.hello)
+            frames = bridge.call({"id": 1, "method": "symbols"})
+            self.assert_error(frames, "load_failed")
+            self.assertEqual(bridge.call({"id": 2, "method": "hello"})[-1]["frame"], "end")
+            self.assertEqual(bridge.close(), 0)
+        finally:
+            if bridge.proc.poll() is None:
+                bridge.proc.kill()
+                bridge.proc.wait()
+
+
+if __name__ == "__main__":
+    # Check if analyzer exists before running tests
+    if not ANALYZER_PATH.exists():
+        print(f"ERROR: Analyzer not found at {ANALYZER_PATH}")
+        print("Run: .\\scripts\\build.ps1 or set CODEANALYZER_GO")
+        sys.exit(1)
+    unittest.main(verbosity=2)